@@ -89,8 +89,70 @@ func init() {
 	// Centrifugo
 	configCmd.Flags().StringVar(&conf.Config.Centrifugo.Secret, "centSecret", "127.0.0.1", "Centrifugo secret")
 	configCmd.Flags().StringVar(&conf.Config.Centrifugo.URL, "centUrl", "127.0.0.1", "Centrifugo URL")
+	configCmd.Flags().BoolVar(&conf.Config.Centrifugo.Cache, "centCache", false, "Publish changes of pages, menus, parameters and languages")
 	viper.BindPFlag("Centrifugo.Secret", configCmd.Flags().Lookup("centSecret"))
 	viper.BindPFlag("Centrifugo.URL", configCmd.Flags().Lookup("centUrl"))
+	viper.BindPFlag("Centrifugo.Cache", configCmd.Flags().Lookup("centCache"))
+
+	// Tracing
+	configCmd.Flags().StringVar(&conf.Config.Tracing.Endpoint, "tracingEndpoint", "", "Zipkin compatible URL for spans of the block processing")
+	configCmd.Flags().StringVar(&conf.Config.Tracing.ServiceName, "tracingService", "go-genesis", "Service name of spans")
+	viper.BindPFlag("Tracing.Endpoint", configCmd.Flags().Lookup("tracingEndpoint"))
+	viper.BindPFlag("Tracing.ServiceName", configCmd.Flags().Lookup("tracingService"))
+
+	// Signer
+	configCmd.Flags().StringVar(&conf.Config.Signer.Type, "signerType", "file", "Signer of blocks: file|remote")
+	configCmd.Flags().StringVar(&conf.Config.Signer.Endpoint, "signerEndpoint", "", "Remote signer https://host:port or unix:///path")
+	configCmd.Flags().StringVar(&conf.Config.Signer.Cert, "signerCert", "", "Filepath of the client certificate for the remote signer")
+	configCmd.Flags().StringVar(&conf.Config.Signer.Key, "signerKey", "", "Filepath of the private key of the client certificate")
+	configCmd.Flags().StringVar(&conf.Config.Signer.CA, "signerCA", "", "Filepath of the CA certificate of the remote signer")
+	configCmd.Flags().Int64Var(&conf.Config.Signer.Timeout, "signerTimeout", 2000, "Timeout of the remote signer in milliseconds")
+	viper.BindPFlag("Signer.Type", configCmd.Flags().Lookup("signerType"))
+	viper.BindPFlag("Signer.Endpoint", configCmd.Flags().Lookup("signerEndpoint"))
+	viper.BindPFlag("Signer.Cert", configCmd.Flags().Lookup("signerCert"))
+	viper.BindPFlag("Signer.Key", configCmd.Flags().Lookup("signerKey"))
+	viper.BindPFlag("Signer.CA", configCmd.Flags().Lookup("signerCA"))
+	viper.BindPFlag("Signer.Timeout", configCmd.Flags().Lookup("signerTimeout"))
+
+	// APIUsage
+	configCmd.Flags().Int64Var(&conf.Config.APIUsage.FlushPeriod, "usageFlush", 60, "Period of writing API usage counters in seconds")
+	configCmd.Flags().Int64Var(&conf.Config.APIUsage.DailyQuota, "usageQuota", 0, "Daily quota of API calls of every ecosystem, 0 is unlimited")
+	viper.BindPFlag("APIUsage.FlushPeriod", configCmd.Flags().Lookup("usageFlush"))
+	viper.BindPFlag("APIUsage.DailyQuota", configCmd.Flags().Lookup("usageQuota"))
+
+	// TxBudget
+	configCmd.Flags().Int64Var(&conf.Config.TxBudget.Blocks, "budgetBlocks", 10, "Number of generated blocks in which failed transactions of keys are counted")
+	configCmd.Flags().Int64Var(&conf.Config.TxBudget.MaxFailures, "budgetFailures", 20, "Max failed transactions of key in these blocks, 0 is unlimited")
+	configCmd.Flags().Int64Var(&conf.Config.TxBudget.Cooldown, "budgetCooldown", 5, "Number of blocks in which transactions of key exceeding budget are deprioritized")
+	viper.BindPFlag("TxBudget.Blocks", configCmd.Flags().Lookup("budgetBlocks"))
+	viper.BindPFlag("TxBudget.MaxFailures", configCmd.Flags().Lookup("budgetFailures"))
+	viper.BindPFlag("TxBudget.Cooldown", configCmd.Flags().Lookup("budgetCooldown"))
+
+	// Export
+	configCmd.Flags().Int64Var(&conf.Config.Export.MaxRows, "exportMaxRows", 1000000, "Max rows of one table export, 0 is unlimited")
+	configCmd.Flags().Int64Var(&conf.Config.Export.Interval, "exportInterval", 60, "Min interval in seconds between table exports of one key")
+	viper.BindPFlag("Export.MaxRows", configCmd.Flags().Lookup("exportMaxRows"))
+	viper.BindPFlag("Export.Interval", configCmd.Flags().Lookup("exportInterval"))
+
+	// Confirmations
+	configCmd.Flags().Int64Var(&conf.Config.Confirmations.PeerTimeout, "confPeerTimeout", consts.WAIT_CONFIRMED_NODES, "Time in seconds to wait for the answer of the node about the block")
+	configCmd.Flags().Int64Var(&conf.Config.Confirmations.ForkWarning, "confForkWarning", 3, "Number of own blocks in a row not confirmed by the quorum before the fork warning")
+	viper.BindPFlag("Confirmations.PeerTimeout", configCmd.Flags().Lookup("confPeerTimeout"))
+	viper.BindPFlag("Confirmations.ForkWarning", configCmd.Flags().Lookup("confForkWarning"))
+
+	// Archive
+	configCmd.Flags().BoolVar(&conf.Config.Archive.Enabled, "archive", false, "Keep the historical states of the rows")
+	configCmd.Flags().Int64Var(&conf.Config.Archive.Chunk, "archiveChunk", 100, "Number of old blocks indexed at once")
+	viper.BindPFlag("Archive.Enabled", configCmd.Flags().Lookup("archive"))
+	viper.BindPFlag("Archive.Chunk", configCmd.Flags().Lookup("archiveChunk"))
+
+	// VDEQuota
+	configCmd.Flags().Int64Var(&conf.Config.VDEQuota.Window, "vdeQuotaWindow", 60, "Sliding window of the quotas of VDE applications in seconds")
+	configCmd.Flags().Int64Var(&conf.Config.VDEQuota.MaxTime, "vdeQuotaTime", 0, "Max execution time of contracts of VDE application in the window in milliseconds, 0 is unlimited")
+	configCmd.Flags().Int64Var(&conf.Config.VDEQuota.MaxFuel, "vdeQuotaFuel", 0, "Max fuel of contracts of VDE application in the window, 0 is unlimited")
+	viper.BindPFlag("VDEQuota.Window", configCmd.Flags().Lookup("vdeQuotaWindow"))
+	viper.BindPFlag("VDEQuota.MaxTime", configCmd.Flags().Lookup("vdeQuotaTime"))
+	viper.BindPFlag("VDEQuota.MaxFuel", configCmd.Flags().Lookup("vdeQuotaFuel"))
 
 	// Log
 	configCmd.Flags().StringVar(&conf.Config.Log.LogTo, "logTo", "stdout", "Send logs to stdout|(filename)|syslog")
@@ -127,6 +189,9 @@ func init() {
 	configCmd.Flags().StringVar(&conf.Config.LockFilePath, "lock", "",
 		fmt.Sprintf("Genesis lock file name (default dataDir/%s)", consts.DefaultLockFilename),
 	)
+	configCmd.Flags().StringVar(&conf.Config.AdminSocket, "adminSocket", "",
+		fmt.Sprintf("Unix socket of the local admin interface (default dataDir/%s)", consts.DefaultAdminSocketFilename),
+	)
 	configCmd.Flags().StringVar(&conf.Config.KeysDir, "keysDir", "", "Keys directory (default dataDir)")
 	configCmd.Flags().StringVar(&conf.Config.DataDir, "dataDir", "", "Data directory (default cwd/genesis-data)")
 	configCmd.Flags().StringVar(&conf.Config.TempDir, "tempDir", "", "Temporary directory (default temporary directory of OS)")
@@ -137,9 +202,12 @@ func init() {
 	configCmd.Flags().Int64Var(&conf.Config.MaxPageGenerationTime, "mpgt", 1000, "Max page generation time in ms")
 	configCmd.Flags().StringSliceVar(&conf.Config.NodesAddr, "nodesAddr", []string{}, "List of addresses for downloading blockchain")
 	configCmd.Flags().StringVar(&conf.Config.RunningMode, "runMode", "PublicBlockchain", "Node running mode")
+	configCmd.Flags().BoolVar(&conf.Config.VDEDebug, "vdeDebug", false, "Enable the debug API of VDE contracts")
+	configCmd.Flags().Int64Var(&conf.Config.IdempotencyTTL, "idempotencyTTL", 86400, "Time in seconds while idempotency keys of transactions are kept")
 
 	viper.BindPFlag("PidFilePath", configCmd.Flags().Lookup("pid"))
 	viper.BindPFlag("LockFilePath", configCmd.Flags().Lookup("lock"))
+	viper.BindPFlag("AdminSocket", configCmd.Flags().Lookup("adminSocket"))
 	viper.BindPFlag("KeysDir", configCmd.Flags().Lookup("keysDir"))
 	viper.BindPFlag("DataDir", configCmd.Flags().Lookup("dataDir"))
 	viper.BindPFlag("FirstBlockPath", configCmd.Flags().Lookup("firstBlock"))
@@ -150,4 +218,6 @@ func init() {
 	viper.BindPFlag("TempDir", configCmd.Flags().Lookup("tempDir"))
 	viper.BindPFlag("NodesAddr", configCmd.Flags().Lookup("nodesAddr"))
 	viper.BindPFlag("RunningMode", configCmd.Flags().Lookup("runMode"))
+	viper.BindPFlag("VDEDebug", configCmd.Flags().Lookup("vdeDebug"))
+	viper.BindPFlag("IdempotencyTTL", configCmd.Flags().Lookup("idempotencyTTL"))
 }
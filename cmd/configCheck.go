@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/signer"
+	"github.com/GenesisKernel/go-genesis/packages/utils"
+
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// The exit codes of config check, the dependencies are checked only if the config is valid
+const (
+	exitInvalidConfig = 1
+	exitUnreachable   = 3
+)
+
+// checkTimeout is the timeout of the connection to the database and to centrifugo
+const checkTimeout = 5 * time.Second
+
+const redacted = "<redacted>"
+
+// checkResult is the result of the checks of the config
+type checkResult struct {
+	invalid     int
+	unreachable int
+}
+
+func (r *checkResult) ok(name string) {
+	fmt.Fprintf(os.Stderr, "ok           %s\n", name)
+}
+
+func (r *checkResult) fail(name string, err error) {
+	r.invalid++
+	fmt.Fprintf(os.Stderr, "invalid      %s: %s\n", name, err)
+}
+
+func (r *checkResult) unreach(name string, err error) {
+	r.unreachable++
+	fmt.Fprintf(os.Stderr, "unreachable  %s: %s\n", name, err)
+}
+
+// configCheckCmd validates the config and prints the effective config with the secrets redacted
+var configCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Validate the config and print the effective config",
+	Long: fmt.Sprintf(`Validate the config, the key files and the ports and check that the database and centrifugo are reachable.
+The results are printed to stderr, the effective config with the secrets redacted is printed to stdout.
+Exit code %d means the invalid config, %d means the valid config with an unreachable dependency.`,
+		exitInvalidConfig, exitUnreachable),
+	Run: func(cmd *cobra.Command, args []string) {
+		res := &checkResult{}
+		if err := conf.LoadConfig(conf.Config.ConfigPath); err != nil {
+			res.fail("config", err)
+			os.Exit(exitInvalidConfig)
+		}
+		if err := conf.FillRuntimePaths(); err != nil {
+			res.fail("config", err)
+			os.Exit(exitInvalidConfig)
+		}
+		res.ok("config " + conf.Config.ConfigPath)
+
+		checkKeys(res)
+		checkPorts(res)
+		centURL := checkCentrifugoURL(res)
+		if res.invalid == 0 {
+			checkDB(res)
+			if centURL != nil {
+				checkCentrifugo(res, centURL)
+			}
+		}
+
+		if err := toml.NewEncoder(os.Stdout).Encode(redactConfig(conf.Config)); err != nil {
+			log.WithError(err).Fatal("printing config")
+		}
+		switch {
+		case res.invalid > 0:
+			os.Exit(exitInvalidConfig)
+		case res.unreachable > 0:
+			os.Exit(exitUnreachable)
+		}
+	},
+}
+
+// redactConfig returns the copy of the config without the secrets
+func redactConfig(c conf.GlobalConfig) conf.GlobalConfig {
+	for _, secret := range []*string{&c.DB.Password, &c.Centrifugo.Secret, &c.TokenMovement.Password} {
+		if len(*secret) > 0 {
+			*secret = redacted
+		}
+	}
+	return c
+}
+
+// checkKeys checks that the key files exist and the private keys match the public keys
+func checkKeys(res *checkResult) {
+	if err := conf.FillRuntimeKey(); err != nil {
+		res.fail("key "+consts.KeyIDFilename, err)
+	} else {
+		res.ok("key " + consts.KeyIDFilename)
+	}
+
+	pub, err := readKeyFile(consts.PublicKeyFilename)
+	if err == nil && crypto.Address(pub) != conf.Config.KeyID {
+		err = fmt.Errorf("public key doesn't match key id %d", conf.Config.KeyID)
+	}
+	checkKeyPair(res, consts.PrivateKeyFilename, consts.PublicKeyFilename, pub, err)
+
+	nodePub, err := readKeyFile(consts.NodePublicKeyFilename)
+	if conf.Config.Signer.Type == signer.TypeRemote {
+		// the node private key is kept by the remote signer
+		if err != nil {
+			res.fail("key "+consts.NodePublicKeyFilename, err)
+		} else {
+			res.ok("key " + consts.NodePublicKeyFilename)
+		}
+		return
+	}
+	checkKeyPair(res, consts.NodePrivateKeyFilename, consts.NodePublicKeyFilename, nodePub, err)
+}
+
+// checkKeyPair checks that the private key file matches the public key
+func checkKeyPair(res *checkResult, privFilename, pubFilename string, pub []byte, errPub error) {
+	name := fmt.Sprintf("keys %s, %s", privFilename, pubFilename)
+	if errPub != nil {
+		res.fail(name, errPub)
+		return
+	}
+	path := filepath.Join(conf.Config.KeysDir, privFilename)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		res.fail(name, err)
+		return
+	}
+	if crypto.IsEncryptedKey(data) {
+		if privFilename != consts.NodePrivateKeyFilename {
+			res.fail(name, fmt.Errorf("%s is encrypted", path))
+			return
+		}
+		if len(conf.Config.KeyPass.Env) == 0 && len(conf.Config.KeyPass.File) == 0 {
+			// the passphrase would be asked in the terminal at the start of the node
+			res.ok(name + " (encrypted)")
+			return
+		}
+		var npub string
+		if _, npub, err = utils.GetNodeKeys(); err == nil {
+			data, err = []byte(npub), nil
+		}
+	} else {
+		data, err = privateToPublic(data)
+	}
+	if err == nil && !bytes.Equal(data, []byte(hex.EncodeToString(pub))) {
+		err = fmt.Errorf("private key doesn't match public key")
+	}
+	if err != nil {
+		res.fail(name, err)
+		return
+	}
+	res.ok(name)
+}
+
+// readKeyFile returns the decoded hex key of the file in the keys directory
+func readKeyFile(filename string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filepath.Join(conf.Config.KeysDir, filename))
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %s", filename, err)
+	}
+	if err = crypto.CheckPublicKey(key); err != nil {
+		return nil, fmt.Errorf("checking %s: %s", filename, err)
+	}
+	return key, nil
+}
+
+// privateToPublic returns the hex public key of the hex private key
+func privateToPublic(data []byte) ([]byte, error) {
+	key, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return nil, err
+	}
+	defer crypto.WipeKey(key)
+	pub, err := crypto.PrivateToPublic(key)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(hex.EncodeToString(pub)), nil
+}
+
+type endpoint struct {
+	name string
+	host string
+	port int
+}
+
+// checkPorts checks the ranges of the ports and that the local ports don't collide
+func checkPorts(res *checkResult) {
+	endpoints := []endpoint{
+		{"TCPServer", conf.Config.TCPServer.Host, conf.Config.TCPServer.Port},
+		{"HTTP", conf.Config.HTTP.Host, conf.Config.HTTP.Port},
+		{"DB", conf.Config.DB.Host, conf.Config.DB.Port},
+	}
+	if u, err := url.Parse(conf.Config.Centrifugo.URL); err == nil && len(u.Host) > 0 {
+		port, err := strconv.Atoi(u.Port())
+		if err != nil {
+			port = 80
+			if u.Scheme == "https" {
+				port = 443
+			}
+		}
+		endpoints = append(endpoints, endpoint{"Centrifugo", u.Hostname(), port})
+	}
+
+	failed := false
+	for i, a := range endpoints {
+		if a.port < 1 || a.port > 65535 {
+			res.fail("ports", fmt.Errorf("%s port %d must be in range 1..65535", a.name, a.port))
+			failed = true
+			continue
+		}
+		for _, b := range endpoints[:i] {
+			if a.port == b.port && hostsOverlap(a.host, b.host) {
+				res.fail("ports", fmt.Errorf("%s and %s use the same port %d", b.name, a.name, a.port))
+				failed = true
+			}
+		}
+	}
+	if !failed {
+		res.ok("ports")
+	}
+}
+
+func isAnyHost(host string) bool {
+	return host == "" || host == "0.0.0.0" || host == "::"
+}
+
+func isLocalHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// hostsOverlap returns true if the same port of both hosts can't be used at once
+func hostsOverlap(a, b string) bool {
+	return a == b || isAnyHost(a) || isAnyHost(b) || (isLocalHost(a) && isLocalHost(b))
+}
+
+// checkCentrifugoURL returns the parsed centrifugo url, it returns nil if centrifugo isn't set
+func checkCentrifugoURL(res *checkResult) *url.URL {
+	if len(conf.Config.Centrifugo.URL) == 0 {
+		return nil
+	}
+	u, err := url.Parse(conf.Config.Centrifugo.URL)
+	if err == nil && ((u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0) {
+		err = fmt.Errorf("%s must be http(s)://host[:port]", conf.Config.Centrifugo.URL)
+	}
+	if err == nil && len(conf.Config.Centrifugo.Secret) == 0 {
+		err = fmt.Errorf("secret is empty")
+	}
+	if err != nil {
+		res.fail("centrifugo", err)
+		return nil
+	}
+	return u
+}
+
+// checkDB connects to the database. The errors returned by postgres mean the invalid settings,
+// the other errors mean the database is unreachable
+func checkDB(res *checkResult) {
+	db := conf.Config.DB
+	name := fmt.Sprintf("db %s:%d/%s", db.Host, db.Port, db.Name)
+	conn, err := sql.Open("postgres", fmt.Sprintf("host=%s port=%d user=%s dbname=%s sslmode=disable password=%s connect_timeout=%d",
+		db.Host, db.Port, db.User, db.Name, db.Password, int(checkTimeout/time.Second)))
+	if err == nil {
+		defer conn.Close()
+		err = conn.Ping()
+	}
+	switch err.(type) {
+	case nil:
+		res.ok(name)
+	case *pq.Error:
+		res.fail(name, err)
+	default:
+		res.unreach(name, err)
+	}
+}
+
+// checkCentrifugo checks that the http server of centrifugo responds
+func checkCentrifugo(res *checkResult, u *url.URL) {
+	name := "centrifugo " + u.String()
+	client := &http.Client{Timeout: checkTimeout}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		res.unreach(name, err)
+		return
+	}
+	resp.Body.Close()
+	res.ok(name)
+}
+
+func init() {
+	configCmd.AddCommand(configCheckCmd)
+}
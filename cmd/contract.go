@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// contractExt is the extension of the contract sources which are checked in the directories
+const contractExt = ".sim"
+
+var (
+	contractVDE  bool
+	contractJSON bool
+)
+
+// contractCmd represents the contract command
+var contractCmd = &cobra.Command{
+	Use:   "contract",
+	Short: "Contract development tools",
+}
+
+// contractCheckCmd compiles the contract sources without the database and the config
+var contractCheckCmd = &cobra.Command{
+	Use:   "check <file-or-dir>...",
+	Short: "Check the contract sources",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sources, err := readContractSources(args)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Fatal("reading contract sources")
+		}
+		vt := script.VMTypeSmart
+		if contractVDE {
+			vt = script.VMTypeVDE
+		}
+		// the compiler logs every error, the issues are printed instead
+		log.SetOutput(ioutil.Discard)
+		vm, err := smart.NewCheckVM(vt)
+		if err != nil {
+			log.SetOutput(os.Stderr)
+			log.WithFields(log.Fields{"type": consts.ParseError, "error": err}).Fatal("creating vm")
+		}
+		issues := smart.CheckContracts(vm, sources)
+		log.SetOutput(os.Stderr)
+
+		if contractJSON {
+			out, err := json.MarshalIndent(issues, "", "  ")
+			if err != nil {
+				log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Fatal("marshalling issues")
+			}
+			fmt.Println(string(out))
+		} else {
+			for _, issue := range issues {
+				fmt.Printf("%s:%d:%d: %s: %s\n", issue.File, issue.Line, issue.Column, issue.Level, issue.Message)
+			}
+		}
+		for _, issue := range issues {
+			if issue.Level == smart.CheckError {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+// readContractSources reads the files and the files with the contract extension in the directories
+func readContractSources(paths []string) (map[string]string, error) {
+	sources := make(map[string]string)
+	read := func(path string) error {
+		data, err := ioutil.ReadFile(path)
+		if err == nil {
+			sources[path] = string(data)
+		}
+		return err
+	}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if err = read(path); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		err = filepath.Walk(path, func(name string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || filepath.Ext(name) != contractExt {
+				return err
+			}
+			return read(name)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sources, nil
+}
+
+func init() {
+	contractCheckCmd.Flags().BoolVar(&contractVDE, "vde", false, "check the contracts for the VDE virtual machine")
+	contractCheckCmd.Flags().BoolVar(&contractJSON, "json", false, "print the issues in JSON")
+	contractCmd.AddCommand(contractCheckCmd)
+}
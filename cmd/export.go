@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/GenesisKernel/go-genesis/packages/chainfile"
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFile string
+	exportFrom int64
+	exportTo   int64
+)
+
+// exportCmd writes the blocks of the local blockchain to the portable file
+var exportCmd = &cobra.Command{
+	Use:    "export",
+	Short:  "Export the blocks to the file",
+	PreRun: loadConfig,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(exportFile) == 0 {
+			log.WithFields(log.Fields{"type": consts.EmptyObject}).Fatal("--file is required")
+		}
+		if err := model.GormInit(
+			conf.Config.DB.Host,
+			conf.Config.DB.Port,
+			conf.Config.DB.User,
+			conf.Config.DB.Password,
+			conf.Config.DB.Name,
+		); err != nil {
+			log.WithError(err).Fatal("init db")
+		}
+
+		// the file is renamed when all blocks are written, so the partial file isn't left
+		tmpFile := exportFile + ".tmp"
+		f, err := os.Create(tmpFile)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err, "file": tmpFile}).Fatal("creating file")
+		}
+		w := bufio.NewWriter(f)
+		m, err := chainfile.Export(w, exportFrom, exportTo, printProgress("exported"))
+		if err == nil {
+			err = w.Flush()
+		}
+		if errClose := f.Close(); err == nil {
+			err = errClose
+		}
+		if err == nil {
+			err = os.Rename(tmpFile, exportFile)
+		}
+		if err != nil {
+			os.Remove(tmpFile)
+			log.WithFields(log.Fields{"error": err, "file": exportFile}).Fatal("exporting blocks")
+		}
+		fmt.Printf("Exported blocks from %d to %d, last hash %s\n", m.FromID, m.ToID, hex.EncodeToString(m.LastHash))
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFile, "file", "", "path of the file")
+	exportCmd.Flags().Int64Var(&exportFrom, "from", 1, "id of the first exported block")
+	exportCmd.Flags().Int64Var(&exportTo, "to", 0, "id of the last exported block, the last block by default")
+}
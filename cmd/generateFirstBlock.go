@@ -2,22 +2,160 @@ package cmd
 
 import (
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"strconv"
 	"time"
 
 	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
 
 	"path/filepath"
 
 	"github.com/GenesisKernel/go-genesis/packages/block"
 	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/model"
 	"github.com/GenesisKernel/go-genesis/packages/utils"
 	log "github.com/sirupsen/logrus"
 )
 
-var stopNetworkBundleFilepath string
+var (
+	stopNetworkBundleFilepath string
+	genesisParamsPath         string
+	genesisSeedPath           string
+)
+
+// genesisOverrides are the system parameters which can be set for the new network
+var genesisOverrides = map[string]int64{
+	syspar.GapsBetweenBlocks: 1,
+	"ecosystem_price":        0,
+	"contract_price":         0,
+	"column_price":           0,
+	"table_price":            0,
+	"menu_price":             0,
+	"page_price":             0,
+}
+
+// genesisParams is the YAML file of the parameters of the new network
+type genesisParams struct {
+	FounderKey string            `yaml:"founder_key"`
+	Amount     int64             `yaml:"amount"`
+	Nodes      []genesisNode     `yaml:"nodes"`
+	Parameters map[string]string `yaml:"parameters"`
+}
+
+// genesisNode is the item of the initial full_nodes
+type genesisNode struct {
+	TCPAddress string `yaml:"tcp_address"`
+	APIAddress string `yaml:"api_address"`
+	KeyID      string `yaml:"key_id"`
+	PublicKey  string `yaml:"public_key"`
+	Title      string `yaml:"title"`
+}
+
+// readGenesisParams reads and validates the parameters of the new network
+func readGenesisParams(path string) (*genesisParams, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	params := &genesisParams{}
+	if err = yaml.UnmarshalStrict(data, params); err != nil {
+		return nil, err
+	}
+	if params.Amount < 0 {
+		return nil, fmt.Errorf("amount can't be negative")
+	}
+	for name, value := range params.Parameters {
+		min, ok := genesisOverrides[name]
+		if !ok {
+			return nil, fmt.Errorf("system parameter %s can't be set", name)
+		}
+		if val, err := strconv.ParseInt(value, 10, 64); err != nil || val < min {
+			return nil, fmt.Errorf("system parameter %s must be an integer not less than %d", name, min)
+		}
+	}
+	return params, nil
+}
+
+// fullNodes returns the full nodes of the parameters, the key id of every node must match its public key
+func (p *genesisParams) fullNodes() ([]*syspar.FullNode, error) {
+	nodes := make([]*syspar.FullNode, 0, len(p.Nodes))
+	keys := make(map[int64]bool)
+	for i, item := range p.Nodes {
+		pub, err := decodePublicKey(item.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("node %d: %s", i+1, err)
+		}
+		keyID := crypto.Address(pub)
+		if len(item.KeyID) > 0 {
+			id, err := converter.ParseAddress(item.KeyID)
+			if err != nil {
+				return nil, fmt.Errorf("node %d: %s", i+1, err)
+			}
+			if id != keyID {
+				return nil, fmt.Errorf("node %d: key id %s doesn't match public key, expected %d", i+1, item.KeyID, keyID)
+			}
+		}
+		if keys[keyID] {
+			return nil, fmt.Errorf("node %d: key id %d is duplicated", i+1, keyID)
+		}
+		keys[keyID] = true
+
+		node := &syspar.FullNode{
+			TCPAddress: item.TCPAddress,
+			APIAddress: item.APIAddress,
+			KeyID:      keyID,
+			PublicKey:  pub,
+			UnbanTime:  time.Unix(0, 0),
+			Title:      item.Title,
+		}
+		if err = node.Validate(); err != nil {
+			return nil, fmt.Errorf("node %d: %s", i+1, err)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// decodePublicKey decodes the hex public key without the leading 04 byte
+func decodePublicKey(value string) ([]byte, error) {
+	pub, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("public key isn't hex: %s", err)
+	}
+	if len(pub) == 65 && pub[0] == 4 {
+		pub = pub[1:]
+	}
+	if len(pub) != consts.PubkeySizeLength {
+		return nil, fmt.Errorf("public key must have %d bytes", consts.PubkeySizeLength)
+	}
+	return pub, nil
+}
+
+// genesisSeed returns the seed of the system parameters which is applied by initDatabase on every node
+func genesisSeed(params *genesisParams, nodes []*syspar.FullNode) (*model.Seed, error) {
+	seed := &model.Seed{SystemParameters: make(map[string]string)}
+	for name, value := range params.Parameters {
+		seed.SystemParameters[name] = value
+	}
+	if params.Amount > 0 {
+		seed.SystemParameters[syspar.FounderAmount] = strconv.FormatInt(params.Amount, 10)
+	}
+	if len(nodes) > 0 {
+		data, err := json.Marshal(nodes)
+		if err != nil {
+			return nil, err
+		}
+		seed.SystemParameters[syspar.FullNodes] = string(data)
+	}
+	return seed, nil
+}
 
 // generateFirstBlockCmd represents the generateFirstBlock command
 var generateFirstBlockCmd = &cobra.Command{
@@ -27,15 +165,6 @@ var generateFirstBlockCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		now := time.Now().Unix()
 
-		header := &utils.BlockData{
-			BlockID:      1,
-			Time:         now,
-			EcosystemID:  0,
-			KeyID:        conf.Config.KeyID,
-			NodePosition: 0,
-			Version:      consts.BLOCK_VERSION,
-		}
-
 		decodeKeyFile := func(kName string) []byte {
 			filepath := filepath.Join(conf.Config.KeysDir, kName)
 			data, err := ioutil.ReadFile(filepath)
@@ -51,6 +180,43 @@ var generateFirstBlockCmd = &cobra.Command{
 			return decodedKey
 		}
 
+		params := &genesisParams{}
+		if len(genesisParamsPath) > 0 {
+			var err error
+			if params, err = readGenesisParams(genesisParamsPath); err != nil {
+				log.WithFields(log.Fields{"error": err, "path": genesisParamsPath}).Fatal("reading parameters of first block")
+			}
+		}
+		nodes, err := params.fullNodes()
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "path": genesisParamsPath}).Fatal("checking full nodes")
+		}
+
+		keyID := conf.Config.KeyID
+		var publicKey, nodePublicKey []byte
+		if len(params.FounderKey) > 0 {
+			if publicKey, err = decodePublicKey(params.FounderKey); err != nil {
+				log.WithFields(log.Fields{"error": err, "path": genesisParamsPath}).Fatal("checking founder key")
+			}
+			keyID = crypto.Address(publicKey)
+		} else {
+			publicKey = decodeKeyFile(consts.PublicKeyFilename)
+		}
+		if len(nodes) > 0 {
+			nodePublicKey = nodes[0].PublicKey
+		} else {
+			nodePublicKey = decodeKeyFile(consts.NodePublicKeyFilename)
+		}
+
+		header := &utils.BlockData{
+			BlockID:      1,
+			Time:         now,
+			EcosystemID:  0,
+			KeyID:        keyID,
+			NodePosition: 0,
+			Version:      consts.BLOCK_VERSION,
+		}
+
 		var stopNetworkCert []byte
 		if len(stopNetworkBundleFilepath) > 0 {
 			var err error
@@ -65,15 +231,15 @@ var generateFirstBlockCmd = &cobra.Command{
 		}
 
 		var tx []byte
-		_, err := converter.BinMarshal(&tx,
+		_, err = converter.BinMarshal(&tx,
 			&consts.FirstBlock{
 				TxHeader: consts.TxHeader{
 					Type:  consts.TxTypeFirstBlock,
 					Time:  uint32(now),
-					KeyID: conf.Config.KeyID,
+					KeyID: keyID,
 				},
-				PublicKey:             decodeKeyFile(consts.PublicKeyFilename),
-				NodePublicKey:         decodeKeyFile(consts.NodePublicKeyFilename),
+				PublicKey:             publicKey,
+				NodePublicKey:         nodePublicKey,
 				StopNetworkCertBundle: stopNetworkCert,
 			},
 		)
@@ -83,17 +249,40 @@ var generateFirstBlockCmd = &cobra.Command{
 			return
 		}
 
-		block, err := block.MarshallBlock(header, [][]byte{tx}, []byte("0"), "")
+		block, err := block.MarshallBlock(header, [][]byte{tx}, []byte("0"), nil)
 		if err != nil {
 			log.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Fatal("first block marshalling")
 			return
 		}
 
-		ioutil.WriteFile(conf.Config.FirstBlockPath, block, 0644)
+		if err = ioutil.WriteFile(conf.Config.FirstBlockPath, block, 0644); err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": conf.Config.FirstBlockPath}).Fatal("writing first block")
+		}
 		log.Info("first block generated")
+
+		seed, err := genesisSeed(params, nodes)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Fatal("marshalling seed")
+		}
+		if len(seed.SystemParameters) == 0 {
+			return
+		}
+		if len(genesisSeedPath) == 0 {
+			genesisSeedPath = conf.Config.FirstBlockPath + ".seed.json"
+		}
+		data, err := json.MarshalIndent(seed, "", "  ")
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Fatal("marshalling seed")
+		}
+		if err = ioutil.WriteFile(genesisSeedPath, data, 0644); err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": genesisSeedPath}).Fatal("writing seed")
+		}
+		log.WithFields(log.Fields{"path": genesisSeedPath}).Info("seed of system parameters generated, apply it on every node with initDatabase --seed")
 	},
 }
 
 func init() {
 	generateFirstBlockCmd.Flags().StringVar(&stopNetworkBundleFilepath, "stopNetworkCert", "", "Filepath to the fullchain of certificates for network stopping")
+	generateFirstBlockCmd.Flags().StringVar(&genesisParamsPath, "params", "", "YAML file of the founder key, the amount, the full nodes and the system parameters of the network")
+	generateFirstBlockCmd.Flags().StringVar(&genesisSeedPath, "seedOutput", "", "Filepath of the generated seed of system parameters (first block path with .seed.json by default)")
 }
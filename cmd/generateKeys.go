@@ -18,12 +18,20 @@ import (
 
 const fileMode = 0600
 
+var keysScheme string
+
+var keySchemes = map[string]byte{
+	"ecdsa":   crypto.SchemeECDSA,
+	"ed25519": crypto.SchemeEd25519,
+}
+
 // generateKeysCmd represents the generateKeys command
 var generateKeysCmd = &cobra.Command{
 	Use:    "generateKeys",
 	Short:  "Keys generation",
 	PreRun: loadConfig,
 	Run: func(cmd *cobra.Command, args []string) {
+		getKeysScheme()
 		_, publicKey, err := createKeyPair(
 			filepath.Join(conf.Config.KeysDir, consts.PrivateKeyFilename),
 			filepath.Join(conf.Config.KeysDir, consts.PublicKeyFilename),
@@ -51,6 +59,10 @@ var generateKeysCmd = &cobra.Command{
 	},
 }
 
+func init() {
+	generateKeysCmd.Flags().StringVar(&keysScheme, "scheme", "ecdsa", "Signature scheme of the keys (ecdsa, ed25519)")
+}
+
 func createFile(filename string, data []byte) error {
 	dir := filepath.Dir(filename)
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
@@ -64,12 +76,16 @@ func createFile(filename string, data []byte) error {
 }
 
 func createKeyPair(privFilename, pubFilename string) (priv, pub []byte, err error) {
-	priv, pub, err = crypto.GenBytesKeys()
+	priv, pub, err = crypto.GenSchemeKeys(keySchemes[keysScheme])
 	if err != nil {
 		log.WithFields(log.Fields{"error": err}).Error("generate keys")
 		return
 	}
+	err = writeKeyPair(privFilename, pubFilename, priv, pub)
+	return
+}
 
+func writeKeyPair(privFilename, pubFilename string, priv, pub []byte) (err error) {
 	err = createFile(privFilename, []byte(hex.EncodeToString(priv)))
 	if err != nil {
 		log.WithFields(log.Fields{"error": err, "path": privFilename}).Error("creating private key")
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/GenesisKernel/go-genesis/packages/chainfile"
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var importFile string
+
+// importCmd plays the blocks of the exported file while the node is stopped. The blocks are
+// committed one by one, so the interrupted import continues from the last played block
+var importCmd = &cobra.Command{
+	Use:    "import",
+	Short:  "Import the blocks from the file",
+	PreRun: loadConfig,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(importFile) == 0 {
+			log.WithFields(log.Fields{"type": consts.EmptyObject}).Fatal("--file is required")
+		}
+		lock := lockStoppedNode()
+		defer lock.Unlock()
+
+		if err := model.GormInit(
+			conf.Config.DB.Host,
+			conf.Config.DB.Port,
+			conf.Config.DB.User,
+			conf.Config.DB.Password,
+			conf.Config.DB.Name,
+		); err != nil {
+			log.WithError(err).Fatal("init db")
+		}
+
+		f, err := os.Open(importFile)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err, "file": importFile}).Fatal("opening file")
+		}
+		defer f.Close()
+
+		m, played, err := chainfile.Import(&readSeeker{f, bufio.NewReader(f)}, printProgress("imported"))
+		if err != nil {
+			fmt.Printf("Imported %d blocks\n", played)
+			log.WithFields(log.Fields{"error": err, "file": importFile}).Fatal("importing blocks")
+		}
+		fmt.Printf("Imported %d blocks, blocks from %d to %d, last hash %s\n", played, m.FromID, m.ToID,
+			hex.EncodeToString(m.LastHash))
+	},
+}
+
+// readSeeker buffers the reading of the file, the buffer is reset by Seek
+type readSeeker struct {
+	f *os.File
+	*bufio.Reader
+}
+
+func (r *readSeeker) Seek(offset int64, whence int) (int64, error) {
+	ret, err := r.f.Seek(offset, whence)
+	r.Reader.Reset(r.f)
+	return ret, err
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importFile, "file", "", "path of the exported file")
+}
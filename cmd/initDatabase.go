@@ -1,21 +1,123 @@
 package cmd
 
 import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
 
 	"github.com/GenesisKernel/go-genesis/packages/conf"
 	"github.com/GenesisKernel/go-genesis/packages/model"
 )
 
+// exitIncompleteSchema is the exit code of initDatabase --check if the schema is missing or incomplete
+const exitIncompleteSchema = 2
+
+var (
+	initForce bool
+	initYes   bool
+	initCheck bool
+	initSeed  string
+)
+
 // initDatabaseCmd represents the initDatabase command
 var initDatabaseCmd = &cobra.Command{
 	Use:    "initDatabase",
 	Short:  "Initializing database",
 	PreRun: loadConfigWKey,
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := model.InitDB(conf.Config.DB); err != nil {
+		if initCheck {
+			checkSchema()
+			return
+		}
+
+		var seed *model.Seed
+		if len(initSeed) > 0 {
+			f, err := os.Open(initSeed)
+			if err != nil {
+				log.WithError(err).Fatal("opening seed")
+			}
+			seed, err = model.ReadSeed(f)
+			f.Close()
+			if err != nil {
+				log.WithFields(log.Fields{"error": err, "path": initSeed}).Fatal("reading seed")
+			}
+		}
+		if initForce && !initYes && !confirmDrop(conf.Config.DB.Name) {
+			log.Fatal("initialization is cancelled")
+		}
+
+		err := model.InitDB(conf.Config.DB, initForce)
+		if err == model.ErrSchemaExists {
+			log.WithFields(log.Fields{"db": conf.Config.DB.Name}).Fatal("database isn't empty, use --force to drop all tables")
+		}
+		if err != nil {
 			log.WithError(err).Fatal("init db")
 		}
+		if seed != nil {
+			if err = model.ApplySeed(seed); err != nil {
+				log.WithFields(log.Fields{"error": err, "path": initSeed}).Fatal("applying seed")
+			}
+		}
 	},
 }
+
+// confirmDrop asks the confirmation of dropping the tables in the terminal
+func confirmDrop(name string) bool {
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		log.Fatal("stdin isn't a terminal, use --yes to confirm dropping all tables")
+	}
+	fmt.Fprintf(os.Stderr, "All tables of database %s will be dropped. Continue? [y/N]: ", name)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// checkSchema prints the state of the schema and exits with exitIncompleteSchema if it isn't complete
+func checkSchema() {
+	if err := model.GormInit(
+		conf.Config.DB.Host,
+		conf.Config.DB.Port,
+		conf.Config.DB.User,
+		conf.Config.DB.Password,
+		conf.Config.DB.Name,
+	); err != nil {
+		log.WithError(err).Fatal("init db")
+	}
+	status, err := model.CheckSchema()
+	if err != nil {
+		log.WithError(err).Fatal("checking schema")
+	}
+	model.GormClose()
+
+	switch {
+	case !status.Present():
+		fmt.Println("Schema: absent")
+	case status.Complete():
+		fmt.Printf("Schema: complete, version %s, %d tables\n", status.Version, len(status.Tables))
+		return
+	default:
+		fmt.Printf("Schema: incomplete, version %s, %d tables\n", status.Version, len(status.Tables))
+		if len(status.Missing) > 0 {
+			fmt.Println("Missing tables:", strings.Join(status.Missing, ", "))
+		}
+		if !status.Actual {
+			fmt.Println("Migrations aren't applied")
+		}
+		if !status.Installed {
+			fmt.Println("Installation isn't complete")
+		}
+	}
+	os.Exit(exitIncompleteSchema)
+}
+
+func init() {
+	initDatabaseCmd.Flags().BoolVar(&initForce, "force", false, "Drop all tables and create the schema again")
+	initDatabaseCmd.Flags().BoolVar(&initYes, "yes", false, "Don't ask the confirmation of --force")
+	initDatabaseCmd.Flags().BoolVar(&initCheck, "check", false, "Report whether the schema is present and complete without changing it")
+	initDatabaseCmd.Flags().StringVar(&initSeed, "seed", "", "JSON file of the system parameters and the first ecosystem data applied after the schema is created")
+}
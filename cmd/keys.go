@@ -0,0 +1,347 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	keyFilename     string
+	keysMnemonic    bool
+	keysNode        bool
+	keysAccount     uint32
+	keysIndex       uint32
+	keysFile        string
+	keysJSON        bool
+	keysShowPrivate bool
+	keysPublic      bool
+)
+
+// keysCmd represents the keys command
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Keys management",
+}
+
+// keysEncryptCmd encrypts the existing plaintext key file with the passphrase
+var keysEncryptCmd = &cobra.Command{
+	Use:    "encrypt",
+	Short:  "Encrypting the key file with the passphrase",
+	PreRun: loadConfig,
+	Run: func(cmd *cobra.Command, args []string) {
+		path := filepath.Join(conf.Config.KeysDir, keyFilename)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "path": path}).Fatal("reading key file")
+			return
+		}
+		if crypto.IsEncryptedKey(data) {
+			log.WithFields(log.Fields{"path": path}).Fatal("key file is already encrypted")
+			return
+		}
+		passphrase, err := utils.GetKeyPassphrase(true)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err}).Fatal("getting passphrase")
+			return
+		}
+		encrypted, err := crypto.EncryptKey(data, passphrase)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err}).Fatal("encrypting key")
+			return
+		}
+		tmp := path + ".tmp"
+		if err = ioutil.WriteFile(tmp, encrypted, fileMode); err != nil {
+			log.WithFields(log.Fields{"error": err, "path": tmp}).Fatal("writing encrypted key")
+			return
+		}
+		if err = os.Rename(tmp, path); err != nil {
+			os.Remove(tmp)
+			log.WithFields(log.Fields{"error": err, "path": path}).Fatal("replacing key file")
+			return
+		}
+		log.WithFields(log.Fields{"path": path}).Info("key file encrypted")
+	},
+}
+
+// keysGenerateCmd writes the new private key file and prints the public key and the address,
+// the key is derived from the new mnemonic with --mnemonic flag
+var keysGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generating the private key file",
+	Run: func(cmd *cobra.Command, args []string) {
+		version := getKeysScheme()
+		var (
+			mnemonic string
+			priv     []byte
+			err      error
+		)
+		if keysMnemonic {
+			if mnemonic, err = crypto.NewMnemonic(crypto.MnemonicEntropyDefault); err != nil {
+				log.WithFields(log.Fields{"error": err}).Fatal("generating mnemonic")
+				return
+			}
+			priv = deriveMnemonicKey(mnemonic, version)
+		} else if priv, _, err = crypto.GenSchemeKeys(version); err != nil {
+			log.WithFields(log.Fields{"error": err}).Fatal("generating keys")
+			return
+		}
+		defer crypto.WipeKey(priv)
+		pub, err := crypto.PrivateToPublic(priv)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err}).Fatal("converting private key to public")
+			return
+		}
+		if err = writePrivateKey(keysFile, []byte(hex.EncodeToString(priv))); err != nil {
+			log.WithFields(log.Fields{"error": err, "path": keysFile}).Fatal("writing private key")
+			return
+		}
+
+		info := newKeyInfo(pub)
+		info.File = keysFile
+		if keysMnemonic {
+			info.Mnemonic = mnemonic
+			info.Path = crypto.DerivationPath(keysAccount, keysIndex)
+		}
+		if keysShowPrivate {
+			info.PrivateKey = hex.EncodeToString(priv)
+		}
+		info.print()
+	},
+}
+
+// keysInspectCmd prints the public key and the address of the key file
+var keysInspectCmd = &cobra.Command{
+	Use:   "inspect <file>",
+	Short: "Printing the public key and the address of the key file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "path": args[0]}).Fatal("reading key file")
+			return
+		}
+		if crypto.IsEncryptedKey(data) {
+			info := &keyInfo{File: args[0], Encrypted: true}
+			info.print()
+			return
+		}
+		key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "path": args[0]}).Fatal("decoding key file")
+			return
+		}
+		defer crypto.WipeKey(key)
+
+		// the stored ed25519 keys have the same size, so the public key file is detected only by the size of ECDSA key
+		pub := key
+		isPublic := keysPublic || len(key) == consts.PubkeySizeLength
+		if !isPublic {
+			if pub, err = crypto.PrivateToPublic(key); err != nil {
+				log.WithFields(log.Fields{"error": err, "path": args[0]}).Fatal("converting private key to public")
+				return
+			}
+		} else if err = crypto.CheckPublicKey(pub); err != nil {
+			log.WithFields(log.Fields{"error": err, "path": args[0]}).Fatal("checking public key")
+			return
+		}
+		info := newKeyInfo(pub)
+		info.File = args[0]
+		if keysShowPrivate && !isPublic {
+			info.PrivateKey = hex.EncodeToString(key)
+		}
+		info.print()
+	},
+}
+
+// keysAddressCmd prints the address of the hex public key
+var keysAddressCmd = &cobra.Command{
+	Use:   "address <public-key-hex>",
+	Short: "Converting the public key to the address",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pub, err := hex.DecodeString(strings.TrimSpace(args[0]))
+		if err != nil {
+			log.WithFields(log.Fields{"error": err}).Fatal("decoding public key")
+			return
+		}
+		if err = crypto.CheckPublicKey(pub); err != nil {
+			log.WithFields(log.Fields{"error": err}).Fatal("checking public key")
+			return
+		}
+		newKeyInfo(pub).print()
+	},
+}
+
+// keyInfo is the output of the keys commands
+type keyInfo struct {
+	File       string `json:"file,omitempty"`
+	Encrypted  bool   `json:"encrypted"`
+	PublicKey  string `json:"public_key,omitempty"`
+	KeyID      string `json:"key_id,omitempty"`
+	Address    string `json:"address,omitempty"`
+	PrivateKey string `json:"private_key,omitempty"`
+	Mnemonic   string `json:"mnemonic,omitempty"`
+	Path       string `json:"path,omitempty"`
+}
+
+func newKeyInfo(pub []byte) *keyInfo {
+	return &keyInfo{
+		PublicKey: hex.EncodeToString(pub),
+		KeyID:     strconv.FormatInt(crypto.Address(pub), 10),
+		Address:   crypto.KeyToAddress(pub),
+	}
+}
+
+// print prints the info as text or as JSON with --json flag
+func (info *keyInfo) print() {
+	if keysJSON {
+		out, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			log.WithFields(log.Fields{"error": err}).Fatal("marshalling key info")
+			return
+		}
+		fmt.Println(string(out))
+		return
+	}
+	for _, item := range []struct{ name, value string }{
+		{"File", info.File},
+		{"Mnemonic", info.Mnemonic},
+		{"Path", info.Path},
+		{"Private key", info.PrivateKey},
+		{"Public key", info.PublicKey},
+		{"Key ID", info.KeyID},
+		{"Address", info.Address},
+	} {
+		if len(item.value) > 0 {
+			fmt.Printf("%s: %s\n", item.name, item.value)
+		}
+	}
+	if info.Encrypted {
+		fmt.Println("Encrypted: the key file is encrypted with the passphrase")
+	}
+}
+
+// writePrivateKey creates the new key file and checks that it's readable only by the owner
+func writePrivateKey(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileMode)
+	if err != nil {
+		return err
+	}
+	// the permissions of the new file are restricted by umask
+	if err = f.Chmod(fileMode); err == nil {
+		_, err = f.Write(data)
+	}
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	if err == nil {
+		var fi os.FileInfo
+		if fi, err = os.Stat(path); err == nil && fi.Mode().Perm() != fileMode {
+			err = fmt.Errorf("permissions of %s are %o instead of %o", path, fi.Mode().Perm(), fileMode)
+		}
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+// keysRecoverCmd rebuilds the key files from the mnemonic which is read from stdin
+var keysRecoverCmd = &cobra.Command{
+	Use:    "recover",
+	Short:  "Recovering the key files from the mnemonic",
+	PreRun: loadConfig,
+	Run: func(cmd *cobra.Command, args []string) {
+		version := getKeysScheme()
+		privFilename, pubFilename := consts.PrivateKeyFilename, consts.PublicKeyFilename
+		if keysNode {
+			privFilename, pubFilename = consts.NodePrivateKeyFilename, consts.NodePublicKeyFilename
+		}
+		privPath := filepath.Join(conf.Config.KeysDir, privFilename)
+		if _, err := os.Stat(privPath); err == nil {
+			log.WithFields(log.Fields{"path": privPath}).Fatal("key file already exists")
+			return
+		}
+
+		fmt.Fprint(os.Stderr, "Mnemonic: ")
+		mnemonic, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && len(mnemonic) == 0 {
+			log.WithFields(log.Fields{"error": err}).Fatal("reading mnemonic")
+			return
+		}
+		priv := deriveMnemonicKey(strings.TrimSpace(mnemonic), version)
+		pub, err := crypto.PrivateToPublic(priv)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err}).Fatal("converting private key to public")
+			return
+		}
+		if err = writeKeyPair(privPath, filepath.Join(conf.Config.KeysDir, pubFilename), priv, pub); err != nil {
+			log.WithFields(log.Fields{"error": err}).Fatal("writing keys")
+			return
+		}
+		if !keysNode {
+			keyIDPath := filepath.Join(conf.Config.KeysDir, consts.KeyIDFilename)
+			if err = createFile(keyIDPath, []byte(strconv.FormatInt(crypto.Address(pub), 10))); err != nil {
+				log.WithFields(log.Fields{"error": err, "path": keyIDPath}).Fatal("writing key id")
+				return
+			}
+		}
+		log.WithFields(log.Fields{"address": crypto.KeyToAddress(pub)}).Info("keys recovered")
+	},
+}
+
+func getKeysScheme() byte {
+	version, ok := keySchemes[keysScheme]
+	if !ok {
+		log.WithFields(log.Fields{"scheme": keysScheme}).Fatal("unknown signature scheme")
+	}
+	return version
+}
+
+func deriveMnemonicKey(mnemonic string, version byte) []byte {
+	seed, err := crypto.MnemonicToSeed(mnemonic, ``)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Fatal("checking mnemonic")
+	}
+	priv, err := crypto.DeriveKey(seed, version, crypto.DerivationPath(keysAccount, keysIndex))
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Fatal("deriving key")
+	}
+	return priv
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{keysGenerateCmd, keysRecoverCmd} {
+		cmd.Flags().StringVar(&keysScheme, "scheme", "ecdsa", "Signature scheme of the keys (ecdsa, ed25519)")
+		cmd.Flags().Uint32Var(&keysAccount, "account", 0, "Account of the derivation path")
+		cmd.Flags().Uint32Var(&keysIndex, "index", 0, "Index of the key in the derivation path")
+	}
+	keysGenerateCmd.Flags().BoolVar(&keysMnemonic, "mnemonic", false, "Derive the key from the new mnemonic")
+	keysGenerateCmd.Flags().StringVar(&keysFile, "file", consts.PrivateKeyFilename, "Path of the new private key file")
+	keysInspectCmd.Flags().BoolVar(&keysPublic, "public", false, "The file is the public key file")
+	for _, cmd := range []*cobra.Command{keysGenerateCmd, keysInspectCmd, keysAddressCmd} {
+		cmd.Flags().BoolVar(&keysJSON, "json", false, "Print the output as JSON")
+	}
+	for _, cmd := range []*cobra.Command{keysGenerateCmd, keysInspectCmd} {
+		cmd.Flags().BoolVar(&keysShowPrivate, "show-private", false, "Print the private key")
+	}
+	keysRecoverCmd.Flags().BoolVar(&keysNode, "node", false, "Recover the node keys instead of the wallet keys")
+	keysCmd.AddCommand(keysGenerateCmd, keysRecoverCmd, keysInspectCmd, keysAddressCmd)
+
+	keysEncryptCmd.Flags().StringVar(&keyFilename, "key", consts.NodePrivateKeyFilename, "Name of the key file in the keys directory")
+	keysCmd.AddCommand(keysEncryptCmd)
+}
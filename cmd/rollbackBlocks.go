@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/rollback"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/theckman/go-flock"
+)
+
+var (
+	rollbackToHeight int64
+	rollbackCount    int64
+	rollbackDryRun   bool
+)
+
+// rollbackBlocksCmd rollbacks the last blocks of the local blockchain while the node is stopped
+var rollbackBlocksCmd = &cobra.Command{
+	Use:    "rollbackBlocks",
+	Short:  "Rollback the last blocks to the height",
+	PreRun: loadConfigWKey,
+	Run: func(cmd *cobra.Command, args []string) {
+		f := lockStoppedNode()
+		defer f.Unlock()
+
+		if err := model.GormInit(
+			conf.Config.DB.Host,
+			conf.Config.DB.Port,
+			conf.Config.DB.User,
+			conf.Config.DB.Password,
+			conf.Config.DB.Name,
+		); err != nil {
+			log.WithError(err).Fatal("init db")
+		}
+		if err := syspar.SysUpdate(nil); err != nil {
+			log.WithError(err).Fatal("can't read system parameters")
+		}
+
+		last := &model.Block{}
+		if _, err := last.GetMaxBlock(); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Fatal("getting max block")
+		}
+		target, err := rollbackHeight(last.ID, rollbackToHeight, rollbackCount, syspar.GetRbBlocks1())
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.ParameterExceeded, "error": err}).Fatal("checking rollback height")
+		}
+		if rollbackDryRun {
+			if err = printRollbackBlocks(target); err != nil {
+				log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Fatal("getting blocks")
+			}
+			return
+		}
+
+		if err = smart.LoadContracts(nil); err != nil {
+			log.WithError(err).Fatal("loading contracts")
+		}
+		total := last.ID - target
+		fmt.Printf("Rolling back %d blocks from %d to %d\n", total, last.ID, target)
+		reached, err := rollback.Blocks(target, func(blockID int64) {
+			fmt.Printf("Block %d rolled back (%d/%d)\n", blockID+1, last.ID-blockID, total)
+		}, log.WithFields(log.Fields{"target": target}))
+		if err != nil {
+			fmt.Printf("Rollback stopped at height %d\n", reached)
+			log.WithFields(log.Fields{"error": err, "reached": reached}).Fatal("rollback blocks")
+		}
+
+		head := &model.InfoBlock{}
+		if _, err = head.Get(); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Fatal("getting info block")
+		}
+		fmt.Printf("Head block %d %s\n", head.BlockID, hex.EncodeToString(head.Hash))
+	},
+}
+
+// rollbackHeight returns the height of the blockchain after the rollback. Either the height or the count
+// of the blocks is defined, the node doesn't rollback more than depth blocks
+func rollbackHeight(last, height, count, depth int64) (int64, error) {
+	switch {
+	case height > 0 && count > 0:
+		return 0, fmt.Errorf("either --to-height or --count must be specified")
+	case count > 0:
+		height = last - count
+	case height <= 0:
+		return 0, fmt.Errorf("--to-height or --count is required")
+	}
+	if height < 1 || height >= last {
+		return 0, fmt.Errorf("height %d must be from 1 to %d", height, last-1)
+	}
+	if last-height > depth {
+		return 0, fmt.Errorf("%d blocks exceed the rollback depth %d (%s)", last-height, depth, syspar.RbBlocks1)
+	}
+	return height, nil
+}
+
+// printRollbackBlocks prints the blocks which would be rolled back to the height
+func printRollbackBlocks(height int64) error {
+	blocks, err := (&model.Block{}).GetBlocksFrom(height, "desc", 0)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d blocks would be rolled back to height %d\n", len(blocks), height)
+	for _, block := range blocks {
+		fmt.Printf("%d %s %s txs=%d\n", block.ID, hex.EncodeToString(block.Hash),
+			time.Unix(block.Time, 0).UTC().Format(time.RFC3339), block.Tx)
+	}
+	return nil
+}
+
+// lockStoppedNode takes the lock of the node, the rollback isn't allowed while the node daemons are running
+func lockStoppedNode() *flock.Flock {
+	f := flock.NewFlock(conf.Config.LockFilePath)
+	locked, err := f.TryLock()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Fatal("locking go-genesis")
+	}
+	if !locked {
+		fields := log.Fields{"type": consts.InvalidObject, "lock": conf.Config.LockFilePath}
+		if data, err := ioutil.ReadFile(conf.Config.GetPidPath()); err == nil {
+			var pid map[string]string
+			if json.Unmarshal(data, &pid) == nil {
+				fields["pid"] = pid["pid"]
+			}
+		}
+		log.WithFields(fields).Fatal("node is running, stop it before the rollback")
+	}
+	return f
+}
+
+func init() {
+	rollbackBlocksCmd.Flags().Int64Var(&rollbackToHeight, "to-height", 0, "height of the blockchain after the rollback")
+	rollbackBlocksCmd.Flags().Int64Var(&rollbackCount, "count", 0, "number of the last blocks to rollback")
+	rollbackBlocksCmd.Flags().BoolVar(&rollbackDryRun, "dry-run", false, "print the blocks which would be rolled back")
+}
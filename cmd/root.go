@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 
 	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/signer"
+	"github.com/GenesisKernel/go-genesis/packages/utils"
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -22,9 +24,21 @@ func init() {
 		generateKeysCmd,
 		initDatabaseCmd,
 		rollbackCmd,
+		rollbackBlocksCmd,
 		startCmd,
 		configCmd,
 		stopNetworkCmd,
+		keysCmd,
+		verifyCmd,
+		exportCmd,
+		importCmd,
+		contractCmd,
+		statusCmd,
+		queueCmd,
+		peersCmd,
+		pauseCmd,
+		resumeCmd,
+		orphansCmd,
 	)
 
 	// This flags are visible for all child commands
@@ -67,3 +81,15 @@ func loadConfigWKey(cmd *cobra.Command, args []string) {
 		log.WithError(err).Fatal("Filling keys")
 	}
 }
+
+// loadConfigWNodeKey loads the config and decrypts the node private key before the node is started
+func loadConfigWNodeKey(cmd *cobra.Command, args []string) {
+	loadConfigWKey(cmd, args)
+
+	if err := utils.LoadNodeKeys(); err != nil {
+		log.WithError(err).Fatal("Loading node private key")
+	}
+	if _, err := signer.Get(); err != nil {
+		log.WithError(err).Fatal("Creating block signer")
+	}
+}
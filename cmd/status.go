@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/admin"
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+
+	"github.com/spf13/cobra"
+)
+
+var adminJSON bool
+
+// statusCmd prints the state of the running node
+var statusCmd = &cobra.Command{
+	Use:    "status",
+	Short:  "Show the state of the running node",
+	PreRun: loadConfig,
+	Run: func(cmd *cobra.Command, args []string) {
+		status, err := adminClient().Status()
+		if err != nil {
+			adminFail(err)
+		}
+		if adminJSON {
+			printAdminJSON(status)
+			return
+		}
+		fmt.Printf("Version: %s\nMode: %s\nPid: %d\nStarted: %s\n", status.Version, status.Mode, status.Pid,
+			time.Unix(status.Started, 0).Format(time.RFC3339))
+		fmt.Printf("Block: %d %s at %s\n", status.BlockID, status.BlockHash,
+			time.Unix(status.BlockTime, 0).Format(time.RFC3339))
+		fmt.Printf("Full nodes: %d\nGeneration paused: %v\nFast sync: %v\n", status.FullNodes,
+			status.GenerationPaused, status.FastSync)
+		if len(status.NodePaused) > 0 {
+			fmt.Printf("Node paused: %s\n", status.NodePaused)
+		}
+		if status.NetworkStopped {
+			fmt.Printf("Network stopped: %s at %s\n", status.StopReason, time.Unix(status.StopTime, 0).Format(time.RFC3339))
+		}
+		if conf := status.Confirmations; conf != nil {
+			fmt.Printf("Confirmations: block %d, good %d, bad %d, quorum %d at %s\n", conf.BlockID, conf.Good,
+				conf.Bad, conf.Quorum, time.Unix(conf.Time, 0).Format(time.RFC3339))
+			for _, peer := range conf.Peers {
+				fmt.Printf("\t%s\t%s\n", peer.Host, peer.State)
+			}
+		}
+	},
+}
+
+// queueCmd prints the sizes of the queues of the running node
+var queueCmd = &cobra.Command{
+	Use:    "queue",
+	Short:  "Show the queues of the running node",
+	PreRun: loadConfig,
+	Run: func(cmd *cobra.Command, args []string) {
+		queue, err := adminClient().Queue()
+		if err != nil {
+			adminFail(err)
+		}
+		if adminJSON {
+			printAdminJSON(queue)
+			return
+		}
+		fmt.Printf("Queued transactions: %d\nQueued blocks: %d\nTransactions: %d\nTransactions waiting for block: %d\n",
+			queue.QueueTx, queue.QueueBlocks, queue.Transactions, queue.UnusedTransactions)
+		for _, budget := range queue.KeyBudgets {
+			state := ""
+			if budget.CooldownUntil > 0 {
+				state = fmt.Sprintf(" deprioritized until block %d", budget.CooldownUntil)
+			}
+			fmt.Printf("Key %s: included %d, failed %d%s\n", budget.KeyID, budget.Included, budget.Failed, state)
+		}
+	},
+}
+
+// peersCmd prints the full nodes which are known to the running node
+var peersCmd = &cobra.Command{
+	Use:    "peers",
+	Short:  "Show the full nodes known to the running node",
+	PreRun: loadConfig,
+	Run: func(cmd *cobra.Command, args []string) {
+		peers, err := adminClient().Peers()
+		if err != nil {
+			adminFail(err)
+		}
+		if adminJSON {
+			printAdminJSON(peers)
+			return
+		}
+		for _, peer := range peers {
+			state := ""
+			if peer.Self {
+				state += " self"
+			}
+			if peer.Banned {
+				state += " banned"
+			}
+			fmt.Printf("%s\t%s\t%s\t%s%s\n", peer.KeyID, peer.TCPAddress, peer.APIAddress, peer.Title, state)
+		}
+	},
+}
+
+// pauseCmd pauses the block generation of the running node
+var pauseCmd = &cobra.Command{
+	Use:    "pause",
+	Short:  "Pause the block generation of the running node",
+	PreRun: loadConfig,
+	Run: func(cmd *cobra.Command, args []string) {
+		printGeneration(adminClient().Pause())
+	},
+}
+
+// resumeCmd resumes the block generation of the running node
+var resumeCmd = &cobra.Command{
+	Use:    "resume",
+	Short:  "Resume the block generation of the running node",
+	PreRun: loadConfig,
+	Run: func(cmd *cobra.Command, args []string) {
+		printGeneration(adminClient().Resume())
+	},
+}
+
+var (
+	orphansApply bool
+	orphansGrace time.Duration
+)
+
+// orphansCmd prints the binaries which aren't referenced and deletes them with --apply
+var orphansCmd = &cobra.Command{
+	Use:    "orphans",
+	Short:  "Show the unused binaries of the running node, delete them on VDE with --apply",
+	PreRun: loadConfig,
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			orphans *admin.Orphans
+			err     error
+		)
+		if orphansApply {
+			orphans, err = adminClient().DeleteOrphans(orphansGrace)
+		} else {
+			orphans, err = adminClient().Orphans()
+		}
+		if err != nil {
+			adminFail(err)
+		}
+		if adminJSON {
+			printAdminJSON(orphans)
+			return
+		}
+		for _, item := range orphans.List {
+			state := ""
+			if item.Deleted {
+				state = " deleted"
+			}
+			fmt.Printf("%d_binaries %d\t%s\tapp %d, member %d, %d bytes, unused since %s%s\n", item.Ecosystem,
+				item.ID, item.Name, item.AppID, item.MemberID, item.Size,
+				time.Unix(item.FoundAt, 0).Format(time.RFC3339), state)
+		}
+		fmt.Printf("Unused binaries: %d\nDeleted: %d\n", len(orphans.List), orphans.Deleted)
+	},
+}
+
+func adminClient() *admin.Client {
+	return admin.NewClient(conf.Config.GetAdminSocketPath())
+}
+
+// adminFail prints the error of the admin socket and exits
+func adminFail(err error) {
+	if err == admin.ErrNotRunning {
+		fmt.Fprintf(os.Stderr, "Node isn't running: nobody listens the admin socket %s\n", conf.Config.GetAdminSocketPath())
+	} else {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+	os.Exit(1)
+}
+
+func printAdminJSON(v interface{}) {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		adminFail(err)
+	}
+	fmt.Println(string(out))
+}
+
+func printGeneration(gen *admin.Generation, err error) {
+	if err != nil {
+		adminFail(err)
+	}
+	if adminJSON {
+		printAdminJSON(gen)
+		return
+	}
+	fmt.Printf("Generation paused: %v\n", gen.Paused)
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{statusCmd, queueCmd, peersCmd, pauseCmd, resumeCmd, orphansCmd} {
+		cmd.Flags().BoolVar(&adminJSON, "json", false, "Print the output as JSON")
+	}
+	orphansCmd.Flags().BoolVar(&orphansApply, "apply", false, "Delete the binaries which are unused longer than the grace period")
+	orphansCmd.Flags().DurationVar(&orphansGrace, "grace", 24*time.Hour, "The time since the binary has been found unused")
+}
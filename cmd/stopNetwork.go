@@ -1,12 +1,19 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/GenesisKernel/go-genesis/packages/block"
 	"github.com/GenesisKernel/go-genesis/packages/conf"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
 	"github.com/GenesisKernel/go-genesis/packages/tcpserver"
 	"github.com/GenesisKernel/go-genesis/packages/utils"
 
@@ -17,15 +24,22 @@ import (
 var (
 	addrsForStopping        []string
 	stopNetworkCertFilepath string
+	stopCertReason          string
+	stopCertRoot            bool
+	stopCertCA              string
+	stopCertNode            bool
+	stopCertValidity        time.Duration
+	stopCertOut             string
 
 	errNotAccepted = errors.New("Not accepted")
 )
 
 // stopNetworkCmd represents the stopNetworkCmd command
 var stopNetworkCmd = &cobra.Command{
-	Use:    "stopNetwork",
-	Short:  "Sending a special transaction to stop the network",
-	PreRun: loadConfigWKey,
+	Use:     "stopnetwork",
+	Aliases: []string{"stopNetwork"},
+	Short:   "Sending a special transaction to stop the network",
+	PreRun:  loadConfigWKey,
 	Run: func(cmd *cobra.Command, args []string) {
 		fp := filepath.Join(conf.Config.KeysDir, stopNetworkCertFilepath)
 		stopNetworkCert, err := ioutil.ReadFile(fp)
@@ -33,28 +47,170 @@ var stopNetworkCmd = &cobra.Command{
 			log.WithFields(log.Fields{"error": err, "type": consts.IOError, "filepath": fp}).Fatal("Reading cert data")
 		}
 
-		req := &tcpserver.StopNetworkRequest{
-			Data: stopNetworkCert,
-		}
+		sendStopNetwork(stopNetworkCert)
+	},
+}
 
-		errCount := 0
-		for _, addr := range addrsForStopping {
-			if err := sendStopNetworkCert(addr, req); err != nil {
-				log.WithFields(log.Fields{"error": err, "type": consts.NetworkError, "addr": addr}).Errorf("Sending request")
-				errCount++
-				continue
+// stopNetworkGenCertCmd writes the stop certificate with the reason of the network stopping
+// which is signed by the founder key, the root certificate of the key is written with --root flag
+var stopNetworkGenCertCmd = &cobra.Command{
+	Use:    "gencert",
+	Short:  "Generating the certificate for the network stopping",
+	PreRun: loadConfig,
+	Run: func(cmd *cobra.Command, args []string) {
+		key := readStopCertKey()
+		defer crypto.WipeKey(key)
+
+		var (
+			cert []byte
+			err  error
+		)
+		if stopCertRoot {
+			cert, err = utils.CreateRootCert(key, "go-genesis stop network", stopCertValidity)
+		} else {
+			if len(stopCertReason) == 0 {
+				log.Fatal("reason of the network stopping isn't specified, use --reason")
+			}
+			if len(stopCertCA) == 0 {
+				log.Fatal("root certificate isn't specified, use --ca")
+			}
+			fp := filepath.Join(conf.Config.KeysDir, stopCertCA)
+			root, errRead := ioutil.ReadFile(fp)
+			if errRead != nil {
+				log.WithFields(log.Fields{"error": errRead, "type": consts.IOError, "filepath": fp}).Fatal("Reading root cert")
 			}
+			cert, err = utils.CreateStopCert(root, key, stopCertReason, stopCertValidity)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "type": consts.CryptoError}).Fatal("creating cert")
+		}
+
+		if len(stopCertOut) == 0 {
+			os.Stdout.Write(cert)
+			return
+		}
+		if err = ioutil.WriteFile(stopCertOut, cert, fileMode); err != nil {
+			log.WithFields(log.Fields{"error": err, "type": consts.IOError, "filepath": stopCertOut}).Fatal("writing cert")
+		}
+		log.WithFields(log.Fields{"filepath": stopCertOut}).Info("cert generated")
+	},
+}
+
+// stopNetworkVerifyCmd checks the stop certificate against the bundle of the first block
+var stopNetworkVerifyCmd = &cobra.Command{
+	Use:    "verify <file>",
+	Short:  "Verifying the certificate for the network stopping",
+	Args:   cobra.ExactArgs(1),
+	PreRun: loadConfig,
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "type": consts.IOError, "filepath": args[0]}).Fatal("Reading cert data")
+		}
+		cert, err := utils.ParseCert(data)
+		if err != nil {
+			stopCertFail(err)
+		}
 
-			log.WithFields(log.Fields{"addr": addr}).Info("Sending request")
+		firstBlock, err := ioutil.ReadFile(conf.Config.FirstBlockPath)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "type": consts.IOError, "filepath": conf.Config.FirstBlockPath}).Fatal("Reading first block")
+		}
+		fbdata, err := block.ParseFirstBlockData(firstBlock)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "filepath": conf.Config.FirstBlockPath}).Fatal("Parsing first block")
+		}
+		if len(fbdata.StopNetworkCertBundle) == 0 {
+			stopCertFail(errors.New("first block doesn't contain the certificates for the network stopping"))
 		}
+		if err = cert.Validate(fbdata.StopNetworkCertBundle); err != nil {
+			stopCertFail(err)
+		}
+		if cert.EqualBytes(consts.UsedStopNetworkCerts...) {
+			stopCertFail(errors.New("certificate is already used"))
+		}
+
+		info := cert.StopInfo()
+		fmt.Printf("Certificate is valid\nReason: %s\nTime: %s\n", info.Reason, info.Time.Format(time.RFC3339))
+	},
+}
 
-		log.WithFields(log.Fields{
-			"successful": len(addrsForStopping) - errCount,
-			"failed":     errCount,
-		}).Info("Complete")
+// stopNetworkSendCmd sends the stop certificate to the nodes
+var stopNetworkSendCmd = &cobra.Command{
+	Use:    "send <file>",
+	Short:  "Sending the certificate for the network stopping to the nodes",
+	Args:   cobra.ExactArgs(1),
+	PreRun: loadConfig,
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "type": consts.IOError, "filepath": args[0]}).Fatal("Reading cert data")
+		}
+		if _, err = utils.ParseCert(data); err != nil {
+			log.WithFields(log.Fields{"error": err, "type": consts.ParseError, "filepath": args[0]}).Fatal("Parsing cert")
+		}
+		sendStopNetwork(data)
 	},
 }
 
+// readStopCertKey returns the founder private key or the node private key with --node flag
+func readStopCertKey() []byte {
+	var (
+		data []byte
+		err  error
+	)
+	if stopCertNode {
+		var priv string
+		priv, _, err = utils.GetNodeKeys()
+		data = []byte(priv)
+	} else {
+		fp := filepath.Join(conf.Config.KeysDir, consts.PrivateKeyFilename)
+		if data, err = ioutil.ReadFile(fp); err == nil && crypto.IsEncryptedKey(data) {
+			err = fmt.Errorf("%s is encrypted", fp)
+		}
+	}
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Fatal("Reading private key")
+	}
+	key, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "type": consts.ConversionError}).Fatal("Decoding private key")
+	}
+	if len(key) != consts.PrivkeyLength {
+		log.Fatal("Only ECDSA keys can sign the certificates")
+	}
+	return key
+}
+
+// stopCertFail prints the reason why the certificate isn't valid and exits
+func stopCertFail(err error) {
+	fmt.Fprintln(os.Stderr, "Certificate isn't valid:", err)
+	os.Exit(1)
+}
+
+// sendStopNetwork sends the stop certificate to the nodes of --addr flags
+func sendStopNetwork(cert []byte) {
+	req := &tcpserver.StopNetworkRequest{
+		Data: cert,
+	}
+
+	errCount := 0
+	for _, addr := range addrsForStopping {
+		if err := sendStopNetworkCert(addr, req); err != nil {
+			log.WithFields(log.Fields{"error": err, "type": consts.NetworkError, "addr": addr}).Errorf("Sending request")
+			errCount++
+			continue
+		}
+
+		log.WithFields(log.Fields{"addr": addr}).Info("Sending request")
+	}
+
+	log.WithFields(log.Fields{
+		"successful": len(addrsForStopping) - errCount,
+		"failed":     errCount,
+	}).Info("Complete")
+}
+
 func sendStopNetworkCert(addr string, req *tcpserver.StopNetworkRequest) error {
 	conn, err := utils.TCPConn(addr)
 	if err != nil {
@@ -87,4 +243,16 @@ func init() {
 	stopNetworkCmd.Flags().StringArrayVar(&addrsForStopping, "addr", []string{}, "Node address")
 	stopNetworkCmd.MarkFlagRequired("stopNetworkCert")
 	stopNetworkCmd.MarkFlagRequired("addr")
+
+	stopNetworkGenCertCmd.Flags().StringVar(&stopCertReason, "reason", "", "Reason of the network stopping which is embedded in the certificate")
+	stopNetworkGenCertCmd.Flags().BoolVar(&stopCertRoot, "root", false, "Generate the root certificate of the key for the first block instead of the stop certificate")
+	stopNetworkGenCertCmd.Flags().StringVar(&stopCertCA, "ca", "", "Filepath to the root certificate of the key")
+	stopNetworkGenCertCmd.Flags().BoolVar(&stopCertNode, "node", false, "Sign the certificate by the node key instead of the founder key")
+	stopNetworkGenCertCmd.Flags().DurationVar(&stopCertValidity, "validity", 10*365*24*time.Hour, "Validity period of the certificate")
+	stopNetworkGenCertCmd.Flags().StringVar(&stopCertOut, "out", "", "Filepath of the certificate (default stdout)")
+
+	stopNetworkSendCmd.Flags().StringArrayVar(&addrsForStopping, "addr", []string{}, "Node address")
+	stopNetworkSendCmd.MarkFlagRequired("addr")
+
+	stopNetworkCmd.AddCommand(stopNetworkGenCertCmd, stopNetworkVerifyCmd, stopNetworkSendCmd)
 }
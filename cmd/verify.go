@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GenesisKernel/go-genesis/packages/block"
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
+	"github.com/GenesisKernel/go-genesis/packages/verify"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// exitDivergent is the exit code if the blockchain doesn't pass the verification,
+// the other errors exit with 1
+const exitDivergent = 2
+
+// progressStep is the number of the blocks between the progress lines
+const progressStep = 1000
+
+var verifyDeep int64
+
+// verifyCmd checks the integrity of the blockchain in the database while the node is stopped
+var verifyCmd = &cobra.Command{
+	Use:    "verify",
+	Short:  "Verify the integrity of the blockchain in the database",
+	PreRun: loadConfig,
+	Run: func(cmd *cobra.Command, args []string) {
+		f := lockStoppedNode()
+		defer f.Unlock()
+
+		if err := model.GormInit(
+			conf.Config.DB.Host,
+			conf.Config.DB.Port,
+			conf.Config.DB.User,
+			conf.Config.DB.Password,
+			conf.Config.DB.Name,
+		); err != nil {
+			log.WithError(err).Fatal("init db")
+		}
+		if err := syspar.SysUpdate(nil); err != nil {
+			log.WithError(err).Fatal("can't read system parameters")
+		}
+		if err := block.SetSysparBlockID(); err != nil {
+			log.WithError(err).Fatal("can't set block id of system parameters")
+		}
+		if err := smart.LoadContracts(nil); err != nil {
+			log.WithError(err).Fatal("loading contracts")
+		}
+
+		result, err := verify.Chain(printProgress("checked"))
+		if err != nil {
+			log.WithError(err).Fatal("verifying blockchain")
+		}
+		fmt.Printf("Checked %d of %d blocks\n", result.Checked, result.Last)
+		if result.Divergence != nil {
+			f.Unlock()
+			fmt.Printf("First divergent block %d: %s\n", result.Divergence.BlockID, result.Divergence.Reason)
+			os.Exit(exitDivergent)
+		}
+		if verifyDeep <= 0 {
+			return
+		}
+
+		deep, err := verify.Deep(conf.Config.DB, verifyDeep, printProgress("replayed"))
+		if err != nil {
+			log.WithError(err).Fatal("replaying blocks")
+		}
+		fmt.Printf("Replayed blocks from %d to %d\n", deep.From, deep.To)
+		switch {
+		case deep.Divergence != nil:
+			fmt.Printf("First divergent block %d: %s\n", deep.Divergence.BlockID, deep.Divergence.Reason)
+		case len(deep.Tables) > 0:
+			fmt.Printf("Tables differ after the replay: %s\n", strings.Join(deep.Tables, ", "))
+		default:
+			return
+		}
+		f.Unlock()
+		os.Exit(exitDivergent)
+	},
+}
+
+// printProgress returns the function which prints the progress to stderr
+func printProgress(action string) func(blockID, last int64) {
+	return func(blockID, last int64) {
+		if blockID%progressStep == 0 || blockID == last {
+			fmt.Fprintf(os.Stderr, "%s %d/%d blocks\n", action, blockID, last)
+		}
+	}
+}
+
+func init() {
+	verifyCmd.Flags().Int64Var(&verifyDeep, "deep", 0, "replay the last N blocks in the copy of the database and compare the tables")
+}
@@ -0,0 +1,277 @@
+// Package admin serves the local admin interface of the node over the unix socket, so the operator
+// of the host can inspect and control the running node without the API port and the login key
+package admin
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/service"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// socketMode allows the connections only from the user of the node
+const socketMode = 0600
+
+// Status is the state of the node
+type Status struct {
+	Version          string `json:"version"`
+	Mode             string `json:"mode"`
+	Pid              int    `json:"pid"`
+	Started          int64  `json:"started"`
+	KeyID            string `json:"key_id"`
+	BlockID          int64  `json:"block_id"`
+	BlockHash        string `json:"block_hash"`
+	BlockTime        int64  `json:"block_time"`
+	FullNodes        int64  `json:"full_nodes"`
+	GenerationPaused bool   `json:"generation_paused"`
+	NodePaused       string `json:"node_paused,omitempty"`
+	FastSync         bool   `json:"fast_sync"`
+	NetworkStopped   bool   `json:"network_stopped"`
+	StopReason       string `json:"stop_reason,omitempty"`
+	StopTime         int64  `json:"stop_time,omitempty"`
+
+	Confirmations *Confirmations `json:"confirmations,omitempty"`
+}
+
+// Confirmations is the latest check of the block by the remote nodes
+type Confirmations struct {
+	BlockID int64              `json:"block_id"`
+	Good    int32              `json:"good"`
+	Bad     int32              `json:"bad"`
+	Quorum  int32              `json:"quorum"`
+	Time    int64              `json:"time"`
+	Peers   []ConfirmationPeer `json:"peers"`
+}
+
+// ConfirmationPeer is the answer of the remote node, the state is have, missing or timeout
+type ConfirmationPeer struct {
+	Host  string `json:"host"`
+	State string `json:"state"`
+	Hash  string `json:"hash,omitempty"`
+}
+
+// Queue is the number of the items which wait for the processing and the budgets of the keys
+// which have the transactions in the last generated blocks
+type Queue struct {
+	QueueTx            int64                 `json:"queue_tx"`
+	QueueBlocks        int64                 `json:"queue_blocks"`
+	Transactions       int64                 `json:"transactions"`
+	UnusedTransactions int64                 `json:"unused_transactions"`
+	KeyBudgets         []service.KeyTxBudget `json:"key_budgets"`
+}
+
+// Peer is the full node of the blockchain
+type Peer struct {
+	KeyID      string `json:"key_id"`
+	TCPAddress string `json:"tcp_address"`
+	APIAddress string `json:"api_address"`
+	Title      string `json:"title,omitempty"`
+	Banned     bool   `json:"banned"`
+	Self       bool   `json:"self"`
+}
+
+// Generation is the state of the block generation
+type Generation struct {
+	Paused bool `json:"paused"`
+}
+
+type errorResult struct {
+	Error string `json:"error"`
+}
+
+var started time.Time
+
+// Start listens the unix socket at path and serves the requests in the background.
+// The socket file which is left by the previous run is replaced
+func Start(path string) (net.Listener, error) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": path}).Error("removing admin socket")
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err, "path": path}).Error("listening admin socket")
+		return nil, err
+	}
+	if err = os.Chmod(path, socketMode); err != nil {
+		l.Close()
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": path}).Error("changing mode of admin socket")
+		return nil, err
+	}
+	started = time.Now()
+
+	go func() {
+		srv := &http.Server{Handler: Handler()}
+		if err := srv.Serve(l); err != nil {
+			log.WithFields(log.Fields{"type": consts.NetworkError, "error": err, "path": path}).Error("serving admin socket")
+		}
+	}()
+	log.WithFields(log.Fields{"path": path}).Info("admin socket is listening")
+	return l, nil
+}
+
+// Handler returns the handler of the requests of the admin interface
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", method(http.MethodGet, statusHandler))
+	mux.HandleFunc("/queue", method(http.MethodGet, queueHandler))
+	mux.HandleFunc("/peers", method(http.MethodGet, peersHandler))
+	mux.HandleFunc("/pause", method(http.MethodPost, generationHandler(service.PauseGeneration)))
+	mux.HandleFunc("/resume", method(http.MethodPost, generationHandler(service.ResumeGeneration)))
+	mux.HandleFunc("/orphans", method(http.MethodGet, orphansHandler))
+	mux.HandleFunc("/orphans/delete", requestMethod(http.MethodPost, deleteOrphansHandler))
+	return mux
+}
+
+// method returns the handler which writes the result of handle as JSON
+func method(name string, handle func() (interface{}, error)) http.HandlerFunc {
+	return requestMethod(name, func(*http.Request) (interface{}, error) {
+		return handle()
+	})
+}
+
+// requestMethod is method for the handlers which read the parameters of the request
+func requestMethod(name string, handle func(*http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != name {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(&errorResult{Error: "method " + r.Method + " isn't allowed"})
+			return
+		}
+		result, err := handle(r)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			result = &errorResult{Error: err.Error()}
+		}
+		if err = json.NewEncoder(w).Encode(result); err != nil {
+			log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("writing admin response")
+		}
+	}
+}
+
+var pauseTypes = map[service.PauseType]string{
+	service.PauseTypeUpdatingBlockchain: "updating blockchain",
+	service.PauseTypeStopingNetwork:     "stopping network",
+}
+
+func statusHandler() (interface{}, error) {
+	infoBlock := &model.InfoBlock{}
+	if _, err := infoBlock.Get(); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting info block")
+		return nil, err
+	}
+	status := &Status{
+		Version:          consts.VERSION,
+		Mode:             conf.Config.RunningMode,
+		Pid:              os.Getpid(),
+		Started:          started.Unix(),
+		KeyID:            strconv.FormatInt(conf.Config.KeyID, 10),
+		BlockID:          infoBlock.BlockID,
+		BlockHash:        hex.EncodeToString(infoBlock.Hash),
+		BlockTime:        infoBlock.Time,
+		FullNodes:        syspar.GetNumberOfNodes(),
+		GenerationPaused: service.IsGenerationPaused(),
+		NodePaused:       pauseTypes[service.NodePauseType()],
+		FastSync:         service.IsFastSyncBlock(infoBlock.BlockID + 1),
+	}
+	if reason, at, ok := service.NetworkStopped(); ok {
+		status.NetworkStopped, status.StopReason, status.StopTime = true, reason, at.Unix()
+	}
+	var err error
+	if status.Confirmations, err = lastConfirmations(); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func lastConfirmations() (*Confirmations, error) {
+	confirmation := &model.Confirmation{}
+	found, err := confirmation.GetLast()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting last confirmation")
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	peers, err := model.GetConfirmationPeers(confirmation.BlockID)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting confirmation peers")
+		return nil, err
+	}
+	result := &Confirmations{
+		BlockID: confirmation.BlockID,
+		Good:    confirmation.Good,
+		Bad:     confirmation.Bad,
+		Quorum:  confirmation.Quorum,
+		Time:    int64(confirmation.Time),
+		Peers:   make([]ConfirmationPeer, len(peers)),
+	}
+	for i, peer := range peers {
+		result.Peers[i] = ConfirmationPeer{Host: peer.Host, State: peer.State, Hash: hex.EncodeToString(peer.Hash)}
+	}
+	return result, nil
+}
+
+func queueHandler() (interface{}, error) {
+	var (
+		queue = &Queue{}
+		err   error
+	)
+	infoBlock := &model.InfoBlock{}
+	if _, err = infoBlock.Get(); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting info block")
+		return nil, err
+	}
+	queue.KeyBudgets = service.GetTxBudget().State(infoBlock.BlockID + 1)
+	if queue.QueueTx, err = model.GetRecordsCountTx(nil, (&model.QueueTx{}).TableName()); err == nil {
+		if queue.QueueBlocks, err = model.GetRecordsCountTx(nil, "queue_blocks"); err == nil {
+			if queue.Transactions, err = model.GetTransactionCountAll(); err == nil {
+				queue.UnusedTransactions, err = model.GetUnusedTransactionsCount()
+			}
+		}
+	}
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting queue sizes")
+		return nil, err
+	}
+	return queue, nil
+}
+
+func peersHandler() (interface{}, error) {
+	bans := service.GetNodesBanService()
+	nodes := syspar.GetNodes()
+	peers := make([]Peer, len(nodes))
+	for i, node := range nodes {
+		peers[i] = Peer{
+			KeyID:      strconv.FormatInt(node.KeyID, 10),
+			TCPAddress: node.TCPAddress,
+			APIAddress: node.APIAddress,
+			Title:      node.Title,
+			Banned:     bans != nil && bans.IsBanned(node),
+			Self:       node.KeyID == conf.Config.KeyID,
+		}
+	}
+	return peers, nil
+}
+
+func generationHandler(action func() error) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		if err := action(); err != nil {
+			return nil, err
+		}
+		return &Generation{Paused: service.IsGenerationPaused()}, nil
+	}
+}
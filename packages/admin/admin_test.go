@@ -0,0 +1,70 @@
+package admin
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "admin")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	conf.Config.DataDir = dir
+
+	path := filepath.Join(dir, "admin.sock")
+	client := NewClient(path)
+	_, err = client.Status()
+	assert.Equal(t, ErrNotRunning, err)
+
+	// the socket of the previous run is replaced
+	require.NoError(t, ioutil.WriteFile(path, nil, 0644))
+	l, err := Start(path)
+	require.NoError(t, err)
+	defer l.Close()
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(socketMode), fi.Mode().Perm())
+
+	gen, err := client.Pause()
+	require.NoError(t, err)
+	assert.True(t, gen.Paused)
+	gen, err = client.Resume()
+	require.NoError(t, err)
+	assert.False(t, gen.Paused)
+
+	assert.EqualError(t, client.do(http.MethodGet, "pause", &Generation{}), "method GET isn't allowed")
+}
+
+func TestDeleteOrphans(t *testing.T) {
+	dir, err := ioutil.TempDir("", "admin")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "admin.sock")
+	l, err := Start(path)
+	require.NoError(t, err)
+	defer l.Close()
+
+	// the binaries of the blockchain are the same on all nodes, so they aren't deleted locally
+	mode := conf.Config.RunningMode
+	defer func() { conf.Config.RunningMode = mode }()
+	for _, runMode := range []string{"", "PublicBlockchain", "PrivateBlockchain"} {
+		conf.Config.RunningMode = runMode
+		_, err = NewClient(path).DeleteOrphans(time.Hour)
+		assert.EqualError(t, err, ErrConsensusData.Error())
+	}
+	for _, runMode := range []string{"VDE", "VDEMaster"} {
+		conf.Config.RunningMode = runMode
+		assert.NoError(t, checkLocalData())
+	}
+}
@@ -0,0 +1,106 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// clientTimeout is the timeout of the requests to the admin socket
+const clientTimeout = 10 * time.Second
+
+// ErrNotRunning is returned by the client if nobody listens the admin socket
+var ErrNotRunning = errors.New("node isn't running")
+
+// Client sends the requests to the admin socket of the running node
+type Client struct {
+	path string
+	http *http.Client
+}
+
+// NewClient returns the client of the admin socket at path
+func NewClient(path string) *Client {
+	return &Client{
+		path: path,
+		http: &http.Client{
+			Timeout: clientTimeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, "unix", path)
+				},
+			},
+		},
+	}
+}
+
+// Status returns the state of the node
+func (c *Client) Status() (*Status, error) {
+	status := &Status{}
+	return status, c.do(http.MethodGet, "status", status)
+}
+
+// Queue returns the sizes of the queues of the node
+func (c *Client) Queue() (*Queue, error) {
+	queue := &Queue{}
+	return queue, c.do(http.MethodGet, "queue", queue)
+}
+
+// Peers returns the full nodes of the blockchain
+func (c *Client) Peers() ([]Peer, error) {
+	var peers []Peer
+	return peers, c.do(http.MethodGet, "peers", &peers)
+}
+
+// Pause pauses the block generation of the node
+func (c *Client) Pause() (*Generation, error) {
+	gen := &Generation{}
+	return gen, c.do(http.MethodPost, "pause", gen)
+}
+
+// Resume resumes the block generation of the node
+func (c *Client) Resume() (*Generation, error) {
+	gen := &Generation{}
+	return gen, c.do(http.MethodPost, "resume", gen)
+}
+
+// Orphans returns the binaries which aren't referenced by the members, the pages and the reference columns
+func (c *Client) Orphans() (*Orphans, error) {
+	orphans := &Orphans{}
+	return orphans, c.do(http.MethodGet, "orphans", orphans)
+}
+
+// DeleteOrphans deletes the binaries which have been unused longer than grace, it's allowed only on VDE
+func (c *Client) DeleteOrphans(grace time.Duration) (*Orphans, error) {
+	orphans := &Orphans{}
+	return orphans, c.do(http.MethodPost, "orphans/delete?grace="+strconv.FormatInt(int64(grace/time.Second), 10), orphans)
+}
+
+func (c *Client) do(method, name string, result interface{}) error {
+	req, err := http.NewRequest(method, "http://admin/"+name, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.ECONNREFUSED) {
+			return ErrNotRunning
+		}
+		return fmt.Errorf("admin socket %s: %s", c.path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		errResult := &errorResult{}
+		if err = json.NewDecoder(resp.Body).Decode(errResult); err != nil || len(errResult.Error) == 0 {
+			return fmt.Errorf("admin socket %s: %s", c.path, resp.Status)
+		}
+		return errors.New(errResult.Error)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,110 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrConsensusData is returned when the binaries are deleted on the node of the blockchain. The tables
+// of the ecosystems are the same on all nodes, so only the local data of VDE can be deleted
+var ErrConsensusData = errors.New("binaries of the blockchain can't be deleted by the node, only the data of VDE can be")
+
+// Orphan is the binary which isn't referenced by the members, the pages and the reference columns
+type Orphan struct {
+	Ecosystem int64  `json:"ecosystem"`
+	ID        int64  `json:"id"`
+	AppID     int64  `json:"app_id"`
+	MemberID  int64  `json:"member_id"`
+	Name      string `json:"name"`
+	Hash      string `json:"hash"`
+	Size      int64  `json:"size"`
+	FoundAt   int64  `json:"found_at"`
+	Deleted   bool   `json:"deleted,omitempty"`
+}
+
+// Orphans is the list of the unused binaries of all ecosystems
+type Orphans struct {
+	List    []Orphan `json:"list"`
+	Deleted int      `json:"deleted"`
+}
+
+// checkLocalData returns ErrConsensusData if the ecosystem tables of the node are in the blockchain
+func checkLocalData() error {
+	if !conf.Config.IsSupportingVDE() {
+		return ErrConsensusData
+	}
+	return nil
+}
+
+// scanOrphans finds the unused binaries and saves them, so the time of the first finding is known
+func scanOrphans(now time.Time) (*Orphans, error) {
+	ecosystems, err := model.GetBinaryEcosystems()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting ecosystems of binaries")
+		return nil, err
+	}
+	orphans := &Orphans{List: make([]Orphan, 0)}
+	for _, ecosystem := range ecosystems {
+		refs, err := model.GetBinaryRefs(ecosystem)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "ecosystem": ecosystem}).Error("getting references to binaries")
+			return nil, err
+		}
+		list, err := model.GetUnusedBinaries(ecosystem, refs)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "ecosystem": ecosystem}).Error("getting unused binaries")
+			return nil, err
+		}
+		found, err := model.TrackOrphanedBinaries(ecosystem, list, now.Unix())
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "ecosystem": ecosystem}).Error("saving orphaned binaries")
+			return nil, err
+		}
+		for _, item := range list {
+			orphans.List = append(orphans.List, Orphan{Ecosystem: ecosystem, ID: item.ID, AppID: item.AppID,
+				MemberID: item.MemberID, Name: item.Name, Hash: item.Hash, Size: item.Size, FoundAt: found[item.ID]})
+		}
+	}
+	return orphans, nil
+}
+
+func orphansHandler() (interface{}, error) {
+	return scanOrphans(time.Now())
+}
+
+// deleteOrphansHandler deletes the binaries which have been unused longer than the grace period in seconds
+func deleteOrphansHandler(r *http.Request) (interface{}, error) {
+	if err := checkLocalData(); err != nil {
+		return nil, err
+	}
+	grace, err := strconv.ParseInt(r.URL.Query().Get("grace"), 10, 64)
+	if err != nil || grace < 0 {
+		return nil, errors.New("grace must be the number of seconds")
+	}
+	now := time.Now()
+	orphans, err := scanOrphans(now)
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range orphans.List {
+		if item.FoundAt > now.Unix()-grace {
+			continue
+		}
+		if err = model.DeleteOrphanedBinary(item.Ecosystem, item.ID, item.Hash); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "ecosystem": item.Ecosystem, "id": item.ID}).Error("deleting orphaned binary")
+			return nil, err
+		}
+		orphans.List[i].Deleted = true
+		orphans.Deleted++
+	}
+	log.WithFields(log.Fields{"deleted": orphans.Deleted}).Info("orphaned binaries are deleted")
+	return orphans, nil
+}
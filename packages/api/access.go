@@ -0,0 +1,141 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type writeAccess struct {
+	smart.TableWrite
+	Allowed bool `json:"allowed"`
+}
+
+type contractAccessResult struct {
+	Allowed bool           `json:"allowed"`
+	Message *txstatusError `json:"errmsg,omitempty"`
+	Writes  []writeAccess  `json:"writes"`
+}
+
+// accessError returns the error of the contract in the format of txstatus
+func accessError(err error) *txstatusError {
+	var message txstatusError
+	if json.Unmarshal([]byte(script.ToVMError(err).Error()), &message) != nil {
+		return &txstatusError{Type: "panic", Error: err.Error()}
+	}
+	return &message
+}
+
+// getContractAccess checks whether the key of the request can call the contract with the parameters
+// of the query. The init and conditions sections are executed in the read-only mode, then the permissions
+// of the tables which the contract writes to are checked if they are known from the source.
+// All changes are rolled back
+func getContractAccess(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	name := data.params[`name`].(string)
+	contract := smart.VMGetContract(data.vm, name, uint32(data.ecosystemId))
+	if contract == nil {
+		logger.WithFields(log.Fields{"type": consts.ContractError, "contract_name": name}).Error("contract name")
+		return errorAPI(w, `E_CONTRACT`, http.StatusBadRequest, name)
+	}
+	info := (*contract).Block.Info.(*script.ContractInfo)
+
+	key := &model.Key{}
+	key.SetTablePrefix(data.ecosystemId)
+	if _, err := key.Get(data.keyId); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("selecting public key from keys")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	if key.Deleted == 1 {
+		return errorAPI(w, `E_DELETEDKEY`, http.StatusForbidden)
+	}
+
+	idata := make([]byte, 0)
+	if info.Tx != nil {
+		values := make(map[string]string)
+		for name, list := range r.Form {
+			if len(list) > 0 {
+				values[name] = list[0]
+			}
+		}
+		var err error
+		if idata, err = tx.EncodeParams(*info.Tx, values, nil); err != nil {
+			return errorAPI(w, err, http.StatusBadRequest)
+		}
+	}
+	smartTx := tx.SmartContract{
+		Header: tx.Header{
+			Type:        int(info.ID),
+			Time:        time.Now().Unix(),
+			EcosystemID: data.ecosystemId,
+			KeyID:       data.keyId,
+			RoleID:      data.roleId,
+			NetworkID:   consts.NETWORK_ID,
+		},
+		Data: idata,
+	}
+	serializedData, err := smartTx.Marshal()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling smart contract")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	hash, err := crypto.Hash(serializedData)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("getting hash of contract data")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	sc := smart.SmartContract{VDE: data.vde, ReadOnly: true, TxHash: hash, VerifiedKey: key.PublicKey}
+	if err = InitSmartContract(&sc, serializedData); err != nil {
+		return errorAPI(w, err, http.StatusBadRequest)
+	}
+	if sc.DbTransaction, err = model.StartTransaction(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("starting transaction")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	defer sc.DbTransaction.Rollback()
+
+	result := &contractAccessResult{Writes: make([]writeAccess, 0)}
+	data.result = result
+	if _, err = sc.CallContract(smart.CallInit | smart.CallCondition); err != nil {
+		result.Message = accessError(err)
+		return nil
+	}
+	writes, err := sc.ContractWrites()
+	if err != nil {
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	result.Allowed = true
+	for _, write := range writes {
+		err := sc.AccessTable(write.Table, write.Action)
+		result.Writes = append(result.Writes, writeAccess{TableWrite: write, Allowed: err == nil})
+		if err != nil && result.Allowed {
+			result.Allowed = false
+			result.Message = accessError(err)
+		}
+	}
+	return nil
+}
@@ -47,6 +47,8 @@ const (
 	multipartBuf = 100000 // the buffer size for ParseMultipartForm
 )
 
+var errTokenClaims = newError(`E_TOKEN`)
+
 type apiData struct {
 	status        int
 	result        interface{}
@@ -99,27 +101,20 @@ const (
 type apiHandle func(http.ResponseWriter, *http.Request, *apiData, *log.Entry) error
 
 func errorAPI(w http.ResponseWriter, err interface{}, code int, params ...interface{}) error {
-	var (
-		msg, errCode, errParams string
-	)
+	var msg, errCode string
 
 	switch v := err.(type) {
 	case string:
 		errCode = v
-		if val, ok := apiErrors[v]; ok {
-			if len(params) > 0 {
-				list := make([]string, 0)
-				msg = fmt.Sprintf(val, params...)
-				for _, item := range params {
-					list = append(list, fmt.Sprintf(`"%v"`, item))
-				}
-				errParams = fmt.Sprintf(`, "params": [%s]`, strings.Join(list, `,`))
-			} else {
-				msg = val
-			}
+		if _, ok := apiErrors[v]; ok {
+			msg = errorText(w, v, params)
 		} else {
 			msg = v
 		}
+	case *apiError:
+		errCode = v.code
+		params = append(v.params, params...)
+		msg = errorText(w, v.code, params)
 	case interface{}:
 		errCode = `E_SERVER`
 		if reflect.TypeOf(v).Implements(reflect.TypeOf((*error)(nil)).Elem()) {
@@ -128,7 +123,7 @@ func errorAPI(w http.ResponseWriter, err interface{}, code int, params ...interf
 	}
 	w.Header().Set("X-Content-Type-Options", "nosniff")
 	w.WriteHeader(code)
-	fmt.Fprintln(w, fmt.Sprintf(`{"error": %q, "msg": %q %s}`, errCode, msg, errParams))
+	fmt.Fprintln(w, fmt.Sprintf(`{"error": %q, "msg": %q , "params": %s}`, errCode, msg, errorParams(params)))
 	return fmt.Errorf(msg)
 }
 
@@ -154,9 +149,14 @@ func getHeader(txName string, data *apiData) (tx.Header, error) {
 	signature := data.params[`signature`].([]byte)
 	if len(signature) == 0 {
 		log.WithFields(log.Fields{"type": consts.EmptyObject, "params": data.params}).Error("signature is empty")
-		return tx.Header{}, fmt.Errorf("signature is empty")
+		return tx.Header{}, newError(`E_EMPTYSIGN`)
+	}
+	txTime, err := converter.StrToInt64E(data.params[`time`].(string))
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting time")
+		return tx.Header{}, err
 	}
-	return tx.Header{Type: int(utils.TypeInt(txName)), Time: converter.StrToInt64(data.params[`time`].(string)),
+	return tx.Header{Type: int(utils.TypeInt(txName)), Time: txTime,
 		EcosystemID: data.ecosystemId, KeyID: data.keyId, PublicKey: publicKey,
 		BinSignatures: converter.EncodeLengthPlusData(signature), NetworkID: consts.NETWORK_ID}, nil
 }
@@ -172,6 +172,7 @@ func DefaultHandler(method, pattern string, params map[string]int, handlers ...a
 			err  error
 			data = &apiData{ecosystemId: 1}
 		)
+		w = &localeWriter{ResponseWriter: w, accept: r.Header.Get(`Accept-Language`), data: data}
 		requestLogger := log.WithFields(log.Fields{"headers": r.Header, "path": r.URL.Path, "protocol": r.Proto, "remote": r.RemoteAddr})
 		requestLogger.Info("received http request")
 
@@ -196,6 +197,7 @@ func DefaultHandler(method, pattern string, params map[string]int, handlers ...a
 		ihandlers := append([]apiHandle{
 			fillToken,
 			fillParams(params),
+			countUsage(pattern),
 		}, handlers...)
 
 		for _, handler := range ihandlers {
@@ -231,6 +233,9 @@ func fillToken(w http.ResponseWriter, r *http.Request, data *apiData, logger *lo
 	if token != nil && token.Valid {
 		if claims, ok := token.Claims.(*JWTClaims); ok && len(claims.KeyID) > 0 {
 			if err := fillTokenData(data, claims, logger); err != nil {
+				if err == errTokenClaims {
+					return errorAPI(w, err, http.StatusBadRequest)
+				}
 				return errorAPI(w, "E_SERVER", http.StatusNotFound, err)
 			}
 		}
@@ -255,7 +260,12 @@ func fillParams(params map[string]int) apiHandle {
 			}
 			switch par & 0xff {
 			case pInt64:
-				data.params[key] = converter.StrToInt64(val)
+				ival, err := optionalInt(val)
+				if err != nil {
+					logger.WithFields(log.Fields{"type": consts.ConversionError, "value": val, "error": err}).Error("converting http parameter to int64")
+					return errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, key)
+				}
+				data.params[key] = ival
 			case pHex:
 				bin, err := hex.DecodeString(val)
 				if err != nil {
@@ -297,11 +307,39 @@ func checkEcosystem(w http.ResponseWriter, data *apiData, logger *log.Entry) (in
 	return ecosystemID, prefix, nil
 }
 
+// optionalInt converts the optional value to int64, the empty value is zero
+func optionalInt(val string) (int64, error) {
+	if len(val) == 0 {
+		return 0, nil
+	}
+	return converter.StrToInt64E(val)
+}
+
+// claimsToInt converts the values of the token claims to the integers
+func claimsToInt(values ...string) ([]int64, error) {
+	ret := make([]int64, len(values))
+	for i, val := range values {
+		var err error
+		if ret[i], err = converter.StrToInt64E(val); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
 func fillTokenData(data *apiData, claims *JWTClaims, logger *log.Entry) error {
-	data.ecosystemId = converter.StrToInt64(claims.EcosystemID)
-	data.keyId = converter.StrToInt64(claims.KeyID)
+	role := claims.RoleID
+	if len(role) == 0 {
+		// the tokens without the role have the zero role
+		role = `0`
+	}
+	ids, err := claimsToInt(claims.EcosystemID, claims.KeyID, role)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.JWTError, "error": err}).Error("converting jwt claims")
+		return errTokenClaims
+	}
+	data.ecosystemId, data.keyId, data.roleId = ids[0], ids[1], ids[2]
 	data.isMobile = claims.IsMobile
-	data.roleId = converter.StrToInt64(claims.RoleID)
 	if !conf.Config.IsSupportingVDE() {
 		ecosystem := &model.Ecosystem{}
 		found, err := ecosystem.Get(data.ecosystemId)
@@ -311,7 +349,7 @@ func fillTokenData(data *apiData, claims *JWTClaims, logger *log.Entry) error {
 		}
 
 		if !found {
-			err := fmt.Errorf("ecosystem not found")
+			err := newError(`E_ECOSYSTEM`, data.ecosystemId)
 			logger.WithFields(log.Fields{"type": consts.NotFound, "id": data.ecosystemId, "error": err}).Error("ecosystem not found")
 		}
 
@@ -45,6 +45,7 @@ var (
 	gAddress          string
 	gPrivate, gPublic string
 	gMobile           bool
+	gLogin            loginResult
 )
 
 type global struct {
@@ -116,9 +117,7 @@ func sendPost(url string, form *url.Values, v interface{}) error {
 }
 
 func keyLogin(state int64) (err error) {
-	var (
-		key, sign []byte
-	)
+	var key []byte
 
 	key, err = ioutil.ReadFile(`key`)
 	if err != nil {
@@ -127,6 +126,13 @@ func keyLogin(state int64) (err error) {
 	if len(key) > 64 {
 		key = key[:64]
 	}
+	return privateLogin(state, key)
+}
+
+// privateLogin logs in the ecosystem with the private key
+func privateLogin(state int64, key []byte) (err error) {
+	var sign []byte
+
 	var ret getUIDResult
 	err = sendGet(`getuid`, nil, &ret)
 	if err != nil {
@@ -157,6 +163,7 @@ func keyLogin(state int64) (err error) {
 	if err != nil {
 		return
 	}
+	gLogin = logret
 	gAddress = logret.Address
 	gPrivate = string(key)
 	gPublic, err = PrivateToPublicHex(gPrivate)
@@ -198,23 +205,21 @@ func appendSign(ret map[string]interface{}, form *url.Values) error {
 }
 
 func waitTx(hash string) (int64, error) {
-	for i := 0; i < 15; i++ {
-		var ret txstatusResult
-		err := sendGet(`txstatus/`+hash, nil, &ret)
+	var ret txstatusResult
+	err := sendGet(`txstatus/`+hash+`/wait?timeout=15`, nil, &ret)
+	if err != nil {
+		return 0, err
+	}
+	if len(ret.BlockID) > 0 {
+		return converter.StrToInt64(ret.BlockID), fmt.Errorf(ret.Result)
+	}
+	if ret.Message != nil {
+		// the tests compare the messages only, the position of the error in the contract is omitted
+		errtext, err := json.Marshal(&txstatusError{Type: ret.Message.Type, Error: ret.Message.Error})
 		if err != nil {
 			return 0, err
 		}
-		if len(ret.BlockID) > 0 {
-			return converter.StrToInt64(ret.BlockID), fmt.Errorf(ret.Result)
-		}
-		if ret.Message != nil {
-			errtext, err := json.Marshal(ret.Message)
-			if err != nil {
-				return 0, err
-			}
-			return 0, errors.New(string(errtext))
-		}
-		time.Sleep(time.Second)
+		return 0, errors.New(string(errtext))
 	}
 	return 0, fmt.Errorf(`TxStatus timeout`)
 }
@@ -223,14 +228,26 @@ func randName(prefix string) string {
 	return fmt.Sprintf(`%s%d`, prefix, time.Now().Unix())
 }
 
+// txReplay returns the expiration time and the unique nonce of the test transaction
+func txReplay() (string, string) {
+	now := time.Now()
+	return converter.Int64ToStr(now.Add(time.Hour).Unix()), converter.Int64ToStr(now.UnixNano())
+}
+
 func postTxResult(txname string, form *url.Values) (id int64, msg string, err error) {
+	if form == nil {
+		form = &url.Values{}
+	}
+	expiration, nonce := txReplay()
+	form.Set(`tx_expiration`, expiration)
+	form.Set(`nonce`, nonce)
 	ret := make(map[string]interface{})
 	err = sendPost(`prepare/`+txname, form, &ret)
 	if err != nil {
 		return
 	}
 
-	form = &url.Values{}
+	form = &url.Values{`tx_expiration`: {expiration}, `nonce`: {nonce}}
 	if err = appendSign(ret, form); err != nil {
 		return
 	}
@@ -301,12 +318,17 @@ func TestGetAvatar(t *testing.T) {
 }
 
 func postTxMultipart(txname string, params map[string]string, files map[string][]byte) (id int64, msg string, err error) {
+	if params == nil {
+		params = make(map[string]string)
+	}
+	expiration, nonce := txReplay()
+	params[`tx_expiration`], params[`nonce`] = expiration, nonce
 	ret := make(map[string]interface{})
 	if err = sendMultipart("/prepare/"+txname, params, files, &ret); err != nil {
 		return
 	}
 
-	form := url.Values{}
+	form := url.Values{`tx_expiration`: {expiration}, `nonce`: {nonce}}
 	if err = appendSign(ret, &form); err != nil {
 		return
 	}
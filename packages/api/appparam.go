@@ -34,7 +34,12 @@ func appParam(w http.ResponseWriter, r *http.Request, data *apiData, logger *log
 	ap := &model.AppParam{}
 	ap.SetTablePrefix(prefix)
 	name := data.params[`name`].(string)
-	found, err := ap.Get(nil, converter.StrToInt64(data.params[`appid`].(string)), name)
+	appID, err := converter.StrToInt64E(data.params[`appid`].(string))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting application id")
+		return errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, "appid")
+	}
+	found, err := ap.Get(nil, appID, name)
 	if err != nil {
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("Getting app parameter by name")
 		return errorAPI(w, err, http.StatusInternalServerError)
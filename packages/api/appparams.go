@@ -43,7 +43,12 @@ func appParams(w http.ResponseWriter, r *http.Request, data *apiData, logger *lo
 	}
 	ap := &model.AppParam{}
 	ap.SetTablePrefix(prefix)
-	list, err := ap.GetAllAppParameters(converter.StrToInt64(data.params[`appid`].(string)))
+	appID, err := converter.StrToInt64E(data.params[`appid`].(string))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting application id")
+		return errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, "appid")
+	}
+	list, err := ap.GetAllAppParameters(appID)
 	if err != nil {
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("Getting all app parameters")
 	}
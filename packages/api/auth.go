@@ -50,7 +50,7 @@ func jwtToken(r *http.Request) (*jwt.Token, error) {
 	if strings.HasPrefix(auth, jwtPrefix) {
 		auth = auth[len(jwtPrefix):]
 	} else {
-		return nil, fmt.Errorf(`wrong authorization value`)
+		return nil, newError(`E_WRONGAUTH`)
 	}
 	return jwt.ParseWithClaims(auth, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
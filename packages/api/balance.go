@@ -36,9 +36,9 @@ func balance(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.
 	if err != nil {
 		return err
 	}
-	keyID := converter.StringToAddress(data.params[`wallet`].(string))
-	if keyID == 0 {
-		logger.WithFields(log.Fields{"type": consts.ConversionError, "value": data.params["wallet"].(string)}).Error("converting wallet to address")
+	keyID, err := converter.ParseAddress(data.params[`wallet`].(string))
+	if err != nil || keyID == 0 {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "value": data.params["wallet"].(string), "error": err}).Error("converting wallet to address")
 		return errorAPI(w, `E_INVALIDWALLET`, http.StatusBadRequest, data.params[`wallet`].(string))
 	}
 
@@ -49,6 +49,11 @@ func balance(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting Key for wallet")
 		return errorAPI(w, err, http.StatusInternalServerError)
 	}
-	data.result = &balanceResult{Amount: key.Amount, Money: converter.EGSMoney(key.Amount)}
+	decimals, err := model.GetTokenDecimals(ecosystemId)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting token decimals")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	data.result = &balanceResult{Amount: key.Amount, Money: converter.FormatMoney(key.Amount, decimals)}
 	return nil
 }
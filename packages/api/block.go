@@ -41,7 +41,11 @@ type getBlockInfoResult struct {
 }
 
 func getBlockInfo(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) (err error) {
-	blockID := converter.StrToInt64(data.params["id"].(string))
+	blockID, err := converter.StrToInt64E(data.params["id"].(string))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting block id")
+		return errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, "id")
+	}
 	block := model.Block{}
 	found, err := block.Get(blockID)
 	if err != nil {
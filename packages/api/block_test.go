@@ -1,9 +1,11 @@
 package api
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetMaxBlockID(t *testing.T) {
@@ -17,3 +19,20 @@ func TestGetBlockInfo(t *testing.T) {
 	err := sendGet(`block/1`, nil, &ret)
 	assert.NoError(t, err)
 }
+
+func TestGetRawBlock(t *testing.T) {
+	raw, err := sendRawRequest(`GET`, `rawblock/1`, nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, raw)
+	described, err := sendRawRequest(`GET`, `rawblock/1?format=described`, nil)
+	require.NoError(t, err)
+	// the first field is the header of the block
+	assert.Equal(t, byte(0x0a), described[0])
+
+	_, err = sendRawRequest(`GET`, `rawblock/1?format=json`, nil)
+	assert.EqualError(t, err, `400 {"error": "E_BLOCKFORMAT", "msg": "Block format json is not supported" , "params": ["json"]}`)
+
+	schema, err := sendRawRequest(`GET`, `blockschema`, nil)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(schema), `syntax = "proto3";`))
+}
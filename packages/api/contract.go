@@ -17,21 +17,23 @@
 package api
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
-	"gopkg.in/vmihailenco/msgpack.v2"
 
+	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
 	"github.com/GenesisKernel/go-genesis/packages/model"
 	"github.com/GenesisKernel/go-genesis/packages/script"
 	"github.com/GenesisKernel/go-genesis/packages/service"
 	"github.com/GenesisKernel/go-genesis/packages/smart"
+	"github.com/GenesisKernel/go-genesis/packages/transaction"
+	"github.com/GenesisKernel/go-genesis/packages/utils"
 	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
 )
 
@@ -66,120 +68,10 @@ func getPublicKey(signID int64, ecosystemID int64, pubkey []byte, w http.Respons
 	return publicKey, nil
 }
 
-func getDataMultiRequestParams(fields []*script.FieldInfo, params map[string]string, w http.ResponseWriter, logger *log.Entry) ([]byte, error) {
-	idata := []byte{}
-	var err error
-	for _, fitem := range fields {
-		val := strings.TrimSpace(params[fitem.Name])
-		if strings.Contains(fitem.Tags, `address`) {
-			val = converter.Int64ToStr(converter.StringToAddress(val))
-		}
-		switch fitem.Type.String() {
-		case `[]interface {}`:
-			var list []string
-			for key, value := range params {
-				if key == fitem.Name+`[]` && len(value) > 0 {
-					count := converter.StrToInt(value)
-					for i := 0; i < count; i++ {
-						list = append(list, params[fmt.Sprintf(`%s[%d]`, fitem.Name, i)])
-					}
-				}
-			}
-			if len(list) == 0 && len(val) > 0 {
-				list = append(list, val)
-			}
-			idata = append(idata, converter.EncodeLength(int64(len(list)))...)
-			for _, ilist := range list {
-				blist := []byte(ilist)
-				idata = append(append(idata, converter.EncodeLength(int64(len(blist)))...), blist...)
-			}
-		case `uint64`:
-			converter.BinMarshal(&idata, converter.StrToUint64(val))
-		case `int64`:
-			converter.EncodeLenInt64(&idata, converter.StrToInt64(val))
-		case `float64`:
-			converter.BinMarshal(&idata, converter.StrToFloat64(val))
-		case `string`, script.Decimal:
-			idata = append(append(idata, converter.EncodeLength(int64(len(val)))...), []byte(val)...)
-		case `[]uint8`:
-			var bytes []byte
-			bytes, err = hex.DecodeString(val)
-			if err != nil {
-				logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": val}).Error("decoding value from hex")
-				return idata, err
-			}
-			idata = append(append(idata, converter.EncodeLength(int64(len(bytes)))...), bytes...)
-		}
-	}
-	return idata, nil
-}
-
-func getData(fields []*script.FieldInfo, req *tx.Request, w http.ResponseWriter, logger *log.Entry) ([]byte, error) {
-	idata := []byte{}
-	var err error
-	for _, fitem := range fields {
-		if fitem.ContainsTag(script.TagFile) {
-			file, err := req.ReadFile(fitem.Name)
-			if err != nil {
-				return idata, errorAPI(w, err.Error(), http.StatusInternalServerError)
-			}
-
-			serialFile, err := msgpack.Marshal(file)
-			if err != nil {
-				logger.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling file to msgpack")
-				return idata, errorAPI(w, err, http.StatusInternalServerError)
-			}
-
-			idata = append(append(idata, converter.EncodeLength(int64(len(serialFile)))...), serialFile...)
-			continue
-		}
-
-		val := strings.TrimSpace(req.GetValue(fitem.Name))
-		if strings.Contains(fitem.Tags, `address`) {
-			val = converter.Int64ToStr(converter.StringToAddress(val))
-		}
-		switch fitem.Type.String() {
-		case `[]interface {}`:
-			var list []string
-			for key, value := range req.AllValues() {
-				if key == fitem.Name+`[]` && len(value) > 0 {
-					count := converter.StrToInt(value)
-					for i := 0; i < count; i++ {
-						list = append(list, req.GetValue(fmt.Sprintf(`%s[%d]`, fitem.Name, i)))
-					}
-				}
-			}
-			if len(list) == 0 && len(val) > 0 {
-				list = append(list, val)
-			}
-			idata = append(idata, converter.EncodeLength(int64(len(list)))...)
-			for _, ilist := range list {
-				blist := []byte(ilist)
-				idata = append(append(idata, converter.EncodeLength(int64(len(blist)))...), blist...)
-			}
-		case `uint64`:
-			converter.BinMarshal(&idata, converter.StrToUint64(val))
-		case `int64`:
-			converter.EncodeLenInt64(&idata, converter.StrToInt64(val))
-		case `float64`:
-			converter.BinMarshal(&idata, converter.StrToFloat64(val))
-		case `string`, script.Decimal:
-			idata = append(append(idata, converter.EncodeLength(int64(len(val)))...), []byte(val)...)
-		case `[]uint8`:
-			var bytes []byte
-			bytes, err = hex.DecodeString(val)
-			if err != nil {
-				logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": val}).Error("decoding value from hex")
-				return idata, err
-			}
-			idata = append(append(idata, converter.EncodeLength(int64(len(bytes)))...), bytes...)
-		}
-	}
-	return idata, nil
-}
-
 type contractResult struct {
 	Hash string `json:"hash"`
+	// Status is the current status of the resubmitted transaction which has been already sent
+	Status *txstatusResult `json:"status,omitempty"`
 	// These fields are used for VDE
 	Message *txstatusError `json:"errmsg,omitempty"`
 	Result  string         `json:"result,omitempty"`
@@ -193,12 +85,64 @@ type contractMultiRequest struct {
 	SignedBy       string   `json:"signed_by"`
 	Signatures     []string `json:"signatures"`
 	Time           string   `json:"time"`
+	TxExpiration   string   `json:"tx_expiration"`
+	Nonce          string   `json:"nonce"`
 }
 
 type contractMultiResult struct {
 	Hashes []string `json:"hashes"`
 }
 
+// joinSigns returns the signatures of the co-signers from the comma separated hex signatures.
+// The empty item means that the co-signer hasn't signed the transaction
+func joinSigns(signatures string, count int) ([]byte, error) {
+	list := strings.Split(signatures, `,`)
+	if len(list) != count {
+		return nil, newError(`E_SIGNREQUIRED`, count)
+	}
+	ret := make([]byte, 0)
+	for _, item := range list {
+		sign, err := hex.DecodeString(strings.TrimSpace(item))
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, converter.EncodeLengthPlusData(sign)...)
+	}
+	return ret, nil
+}
+
+// checkTxSigns checks the signatures of the transactions by utils.CheckSignBatch.
+// The checked transactions are kept in the cache, so their signatures aren't checked again
+func checkTxSigns(txs [][]byte, logger *log.Entry) error {
+	items := make([]utils.SignItem, 0, len(txs))
+	parsed := make([]*transaction.Transaction, 0, len(txs))
+	for _, txData := range txs {
+		t, err := transaction.UnmarshallTransaction(bytes.NewBuffer(txData))
+		if err != nil {
+			return err
+		}
+		item, err := t.GetSignItem()
+		if err != nil {
+			return err
+		}
+		if item != nil {
+			items = append(items, *item)
+			parsed = append(parsed, t)
+		}
+	}
+	results, err := utils.CheckSignBatch(items)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("checking signatures of transactions")
+		return err
+	}
+	for i, t := range parsed {
+		if results[i] {
+			t.VerifiedKey = items[i].PublicKeys[0]
+		}
+	}
+	return nil
+}
+
 func (c *contractHandlers) contractMulti(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
 	requestID := data.ParamString("request_id")
 	var publicKey []byte
@@ -212,11 +156,14 @@ func (c *contractHandlers) contractMulti(w http.ResponseWriter, r *http.Request,
 	}
 	var signedBy int64
 	signID := data.keyId
+	var err error
 	if multiRequest.SignedBy != "" {
-		signedBy = converter.StrToInt64(multiRequest.SignedBy)
+		if signedBy, err = converter.StrToInt64E(multiRequest.SignedBy); err != nil {
+			logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting signed_by")
+			return errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, "signed_by")
+		}
 		signID = signedBy
 	}
-	var err error
 	pubkey := []byte{}
 	if multiRequest.Pubkey != "" {
 		pubkey, err = hex.DecodeString(multiRequest.Pubkey)
@@ -234,10 +181,27 @@ func (c *contractHandlers) contractMulti(w http.ResponseWriter, r *http.Request,
 		logger.WithFields(log.Fields{"type": consts.EmptyObject}).Error("signatures is empty")
 		return errorAPI(w, `E_EMPTYSIGN`, http.StatusBadRequest)
 	}
-	tokenEcosystem := converter.StrToInt64(multiRequest.TokenEcosystem)
+	tokenEcosystem, err := optionalInt(multiRequest.TokenEcosystem)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting token_ecosystem")
+		return errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, "token_ecosystem")
+	}
+	txTime, err := converter.StrToInt64E(multiRequest.Time)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting time")
+		return errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, "time")
+	}
+	expiration, nonce, err := multiReplayParams(w, multiRequest.TxExpiration, multiRequest.Nonce, logger)
+	if err != nil {
+		return err
+	}
 	maxSum := multiRequest.MaxSum
 	payover := multiRequest.Payover
-	hashes := []string{}
+	if err = checkAmounts(w, maxSum, payover, logger); err != nil {
+		return err
+	}
+	txs := make([][]byte, 0, len(req.Contracts))
+	txTypes := make([]int64, 0, len(req.Contracts))
 	for i, c := range req.Contracts {
 		contract := smart.VMGetContract(data.vm, c.Contract, uint32(data.ecosystemId))
 		if contract == nil {
@@ -247,9 +211,8 @@ func (c *contractHandlers) contractMulti(w http.ResponseWriter, r *http.Request,
 
 		idata := make([]byte, 0)
 		if info.Tx != nil {
-			idata, err = getDataMultiRequestParams(*info.Tx, c.Params, w, logger)
-			if err != nil {
-				return err
+			if idata, err = tx.EncodeParams(*info.Tx, c.Params, nil); err != nil {
+				return errorAPI(w, err, http.StatusBadRequest)
 			}
 		}
 		signatureBytes, err := hex.DecodeString(signatures[i])
@@ -260,32 +223,50 @@ func (c *contractHandlers) contractMulti(w http.ResponseWriter, r *http.Request,
 		toSerialize := tx.SmartContract{
 			Header: tx.Header{
 				Type:          int(info.ID),
-				Time:          converter.StrToInt64(multiRequest.Time),
+				Time:          txTime,
 				EcosystemID:   data.ecosystemId,
 				KeyID:         data.keyId,
 				RoleID:        data.roleId,
 				PublicKey:     publicKey,
 				NetworkID:     consts.NETWORK_ID,
 				BinSignatures: converter.EncodeLengthPlusData(signatureBytes),
+				SignVersion:   tx.ForSignVersion,
 			},
 			RequestID:      req.ID,
 			TokenEcosystem: tokenEcosystem,
 			MaxSum:         maxSum,
 			PayOver:        payover,
 			SignedBy:       signedBy,
+			Expiration:     expiration,
+			Nonce:          multiNonce(nonce, i),
 			Data:           idata,
 		}
-		serializedData, err := msgpack.Marshal(toSerialize)
+		serializedData, err := toSerialize.Marshal()
 		if err != nil {
-			logger.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling smart contract to msgpack")
+			logger.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling smart contract")
 			return errorAPI(w, err, http.StatusInternalServerError)
 		}
-		if hash, err := model.SendTx(int64(info.ID), data.keyId,
-			append([]byte{128}, serializedData...)); err != nil {
+		txData := append([]byte{128}, serializedData...)
+		if err = checkTxSize(w, txData, logger); err != nil {
+			return err
+		}
+		txs = append(txs, txData)
+		txTypes = append(txTypes, int64(info.ID))
+	}
+	// the transactions aren't sent if any signature is incorrect
+	if err = checkTxSigns(txs, logger); err != nil {
+		if _, ok := err.(*utils.SignBatchError); ok {
+			return errorAPI(w, `E_SIGNATURE`, http.StatusBadRequest)
+		}
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	hashes := make([]string, 0, len(txs))
+	for i, txData := range txs {
+		hash, err := model.SendTx(txTypes[i], data.keyId, txData)
+		if err != nil && err != model.ErrTxExists {
 			return errorAPI(w, err, http.StatusInternalServerError)
-		} else {
-			hashes = append(hashes, hex.EncodeToString(hash))
 		}
+		hashes = append(hashes, hex.EncodeToString(hash))
 	}
 	data.result = &contractMultiResult{Hashes: hashes}
 	return nil
@@ -294,7 +275,7 @@ func (c *contractHandlers) contractMulti(w http.ResponseWriter, r *http.Request,
 func (c *contractHandlers) contract(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
 	var (
 		hash, publicKey []byte
-		toSerialize     interface{}
+		toSerialize     tx.SmartContract
 		requestID       = data.ParamString("request_id")
 	)
 
@@ -309,6 +290,12 @@ func (c *contractHandlers) contract(w http.ResponseWriter, r *http.Request, data
 
 	info := (*contract).Block.Info.(*script.ContractInfo)
 
+	txTime, err := converter.StrToInt64E(data.params[`time`].(string))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting time")
+		return errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, "time")
+	}
+
 	var signedBy int64
 	signID := data.keyId
 	if data.params[`signed_by`] != nil {
@@ -316,38 +303,21 @@ func (c *contractHandlers) contract(w http.ResponseWriter, r *http.Request, data
 		signID = signedBy
 	}
 
-	pubkey := []byte{}
-	if _, ok := data.params["pubkey"]; ok {
-		pubkey = data.params["pubkey"].([]byte)
-	}
-	var err error
-	publicKey, err = getPublicKey(signID, data.ecosystemId, pubkey, w, logger)
-	if err != nil {
-		return err
-	}
-
-	signature := data.params[`signature`].([]byte)
-	if len(signature) == 0 {
-		logger.WithFields(log.Fields{"type": consts.EmptyObject}).Error("signature is empty")
-		return errorAPI(w, `E_EMPTYSIGN`, http.StatusBadRequest)
-	}
 	idata := make([]byte, 0)
 	if info.Tx != nil {
-		idata, err = getData(*info.Tx, req, w, logger)
-		if err != nil {
-			return err
+		if idata, err = tx.EncodeParams(*info.Tx, req.AllValues(), req.ReadFile); err != nil {
+			return errorAPI(w, err, http.StatusBadRequest)
 		}
 	}
 	toSerialize = tx.SmartContract{
 		Header: tx.Header{
-			Type:          int(info.ID),
-			Time:          converter.StrToInt64(data.params[`time`].(string)),
-			EcosystemID:   data.ecosystemId,
-			KeyID:         data.keyId,
-			RoleID:        data.roleId,
-			PublicKey:     publicKey,
-			NetworkID:     consts.NETWORK_ID,
-			BinSignatures: converter.EncodeLengthPlusData(signature),
+			Type:        int(info.ID),
+			Time:        txTime,
+			EcosystemID: data.ecosystemId,
+			KeyID:       data.keyId,
+			RoleID:      data.roleId,
+			NetworkID:   consts.NETWORK_ID,
+			SignVersion: signVersion(data),
 		},
 		RequestID:      req.ID,
 		TokenEcosystem: data.params[`token_ecosystem`].(int64),
@@ -356,9 +326,56 @@ func (c *contractHandlers) contract(w http.ResponseWriter, r *http.Request, data
 		SignedBy:       signedBy,
 		Data:           idata,
 	}
-	serializedData, err := msgpack.Marshal(toSerialize)
+	toSerialize.Expiration, toSerialize.Nonce = replayParams(data)
+	if err = checkAmounts(w, toSerialize.MaxSum, toSerialize.PayOver, logger); err != nil {
+		return err
+	}
+
+	// the duplicate of the sent transaction is found before the signature is checked
+	var fingerprint []byte
+	idempotencyKey := data.ParamString(`idempotency_key`)
+	if len(idempotencyKey) > 0 && !data.vde {
+		if fingerprint, err = txFingerprint(&toSerialize); err != nil {
+			logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("getting fingerprint of transaction")
+			return errorAPI(w, err, http.StatusInternalServerError)
+		}
+		if hash, err = sentTx(w, data.keyId, idempotencyKey, fingerprint, logger); err != nil {
+			return err
+		}
+		if hash != nil {
+			data.result = &contractResult{Hash: hex.EncodeToString(hash)}
+			return nil
+		}
+	}
+	if err = setSigners(&toSerialize, data); err != nil {
+		logger.WithFields(log.Fields{"type": consts.InvalidObject, "error": err}).Error("setting signers")
+		return errorAPI(w, `E_SIGNERS`, http.StatusBadRequest, err)
+	}
+	if len(toSerialize.Signers) > 0 {
+		if toSerialize.BinSignatures, err = joinSigns(data.ParamString(`signatures`), len(toSerialize.Signers)); err != nil {
+			logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting signatures of signers")
+			return errorAPI(w, `E_SIGNATURE`, http.StatusBadRequest)
+		}
+	} else {
+		pubkey := []byte{}
+		if _, ok := data.params["pubkey"]; ok {
+			pubkey = data.params["pubkey"].([]byte)
+		}
+		publicKey, err = getPublicKey(signID, data.ecosystemId, pubkey, w, logger)
+		if err != nil {
+			return err
+		}
+		signature, _ := data.params[`signature`].([]byte)
+		if len(signature) == 0 {
+			logger.WithFields(log.Fields{"type": consts.EmptyObject}).Error("signature is empty")
+			return errorAPI(w, `E_EMPTYSIGN`, http.StatusBadRequest)
+		}
+		toSerialize.PublicKey = publicKey
+		toSerialize.BinSignatures = converter.EncodeLengthPlusData(signature)
+	}
+	serializedData, err := toSerialize.Marshal()
 	if err != nil {
-		logger.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling smart contract to msgpack")
+		logger.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling smart contract")
 		return errorAPI(w, err, http.StatusInternalServerError)
 	}
 	if data.vde {
@@ -369,11 +386,49 @@ func (c *contractHandlers) contract(w http.ResponseWriter, r *http.Request, data
 		data.result = ret
 		return nil
 	}
-	if hash, err = model.SendTx(int64(info.ID), data.keyId,
-		append([]byte{128}, serializedData...)); err != nil {
+	txData := append([]byte{128}, serializedData...)
+	if err = checkTxSize(w, txData, logger); err != nil {
+		return err
+	}
+	result := &contractResult{}
+	if hash, err = model.SendTx(int64(info.ID), data.keyId, txData); err == model.ErrTxExists {
+		// the client has resubmitted the transaction after the timeout, it isn't an error
+		logger.WithFields(log.Fields{"type": consts.DuplicateObject, "tx_hash": hash}).Info("transaction has been already sent")
+		if result.Status, err = sentTxStatus(w, hash, logger); err != nil {
+			return err
+		}
+	} else if err != nil {
 		return errorAPI(w, err, http.StatusInternalServerError)
 	}
-	data.result = &contractResult{Hash: hex.EncodeToString(hash)}
+	if fingerprint != nil {
+		saveIdempotencyKey(data.keyId, idempotencyKey, fingerprint, hash, logger)
+	}
+	result.Hash = hex.EncodeToString(hash)
+	data.result = result
+	return nil
+}
+
+// sentTxStatus returns the status of the transaction which has been already sent, the transaction
+// which has been received from the other node and isn't processed yet has the empty status
+func sentTxStatus(w http.ResponseWriter, hash []byte, logger *log.Entry) (*txstatusResult, error) {
+	found, err := (&model.TransactionStatus{}).Get(hash)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting transaction status by hash")
+		return nil, errorAPI(w, err, http.StatusInternalServerError)
+	}
+	if !found {
+		return &txstatusResult{}, nil
+	}
+	return getTxStatus(hex.EncodeToString(hash), w, logger)
+}
+
+// checkTxSize rejects the transaction which is larger than max_tx_size like the nodes do,
+// the error has the actual and the allowed sizes
+func checkTxSize(w http.ResponseWriter, txData []byte, logger *log.Entry) error {
+	if size, limit := int64(len(txData)), syspar.GetMaxTxSize(); size > limit {
+		logger.WithFields(log.Fields{"type": consts.ParameterExceeded, "size": size, "max_size": limit}).Error("transaction size exceeds max size")
+		return errorAPI(w, `E_LIMITTXSIZE`, http.StatusBadRequest, size, limit)
+	}
 	return nil
 }
 
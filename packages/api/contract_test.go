@@ -28,6 +28,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
 )
 
 func TestHardContract(t *testing.T) {
@@ -526,7 +527,7 @@ func TestNewTableWithEmptyName(t *testing.T) {
 	}
 
 	if err := postTx("NewTable", &form); err == nil || err.Error() !=
-		`400 {"error": "E_SERVER", "msg": "Name is empty" }` {
+		`400 {"error": "E_EMPTYFIELD", "msg": "Name is empty" , "params": ["Name"]}` {
 		t.Error(`wrong error`, err)
 	}
 
@@ -1284,3 +1285,71 @@ func TestBlockTransactions(t *testing.T) {
 
 	fmt.Printf("%+v", result)
 }
+
+func TestContractAccess(t *testing.T) {
+	require.NoError(t, keyLogin(1))
+
+	table := randName(`tbl`)
+	require.NoError(t, postTx("NewTable", &url.Values{
+		"Name":          {table},
+		"Columns":       {`[{"name":"name","type":"varchar","index":"0","conditions":{"update":"true","read":"true"}}]`},
+		"ApplicationId": {"1"},
+		"Permissions":   {`{"insert": "true", "update": "false", "new_column": "true"}`},
+	}))
+	name := randName(`access`)
+	require.NoError(t, postTx("NewContract", &url.Values{
+		"Value": {`contract ` + name + ` {
+			data {
+				Name string
+			}
+			conditions {
+				if $Name == "" {
+					warning "Name is empty"
+				}
+				DBInsert("` + table + `", "name", "conditions")
+			}
+			action {
+				DBInsert("` + table + `", "name", $Name)
+				DBUpdate("` + table + `", 1, "name", $Name)
+			}
+		}`},
+		"Conditions":    {"true"},
+		"ApplicationId": {"1"},
+	}))
+
+	var ret contractAccessResult
+	require.NoError(t, sendGet(`contract/`+name+`/access`, nil, &ret))
+	assert.False(t, ret.Allowed)
+	assert.Equal(t, "Name is empty", ret.Message.Error)
+
+	// DBInsert of the conditions is disabled in the read-only mode
+	require.NoError(t, sendGet(`contract/`+name+`/access?Name=test`, nil, &ret))
+	assert.False(t, ret.Allowed)
+	assert.Contains(t, ret.Message.Error, "read-only")
+
+	var contract getContractResult
+	require.NoError(t, sendGet(`contract/`+name, nil, &contract))
+	require.NoError(t, postTx("EditContract", &url.Values{
+		"Id": {contract.TableID},
+		"Value": {`contract ` + name + ` {
+			data {
+				Name string
+			}
+			action {
+				DBInsert("` + table + `", "name", $Name)
+				DBUpdate("` + table + `", 1, "name", $Name)
+			}
+		}`},
+		"Conditions": {"true"},
+	}))
+	require.NoError(t, sendGet(`contract/`+name+`/access?Name=test`, nil, &ret))
+	assert.False(t, ret.Allowed)
+	assert.Equal(t, []writeAccess{
+		{TableWrite: smart.TableWrite{Table: "1_" + table, Action: "insert"}, Allowed: true},
+		{TableWrite: smart.TableWrite{Table: "1_" + table, Action: "update"}, Allowed: false},
+	}, ret.Writes)
+
+	var list listResult
+	require.NoError(t, sendGet(`list/`+table, nil, &list))
+	assert.Equal(t, "0", list.Count)
+}
@@ -18,7 +18,6 @@ package api
 
 import (
 	"crypto/md5"
-	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -33,7 +32,7 @@ import (
 
 const binaryColumn = "data"
 
-var errWrongHash = errors.New("Wrong hash")
+var errWrongHash = newError(`E_HASHWRONG`)
 
 func dataHandler() hr.Handle {
 	return hr.Handle(func(w http.ResponseWriter, r *http.Request, ps hr.Params) {
@@ -71,7 +70,13 @@ func binary(w http.ResponseWriter, r *http.Request, ps hr.Params) {
 	bin := model.Binary{}
 	bin.SetTableName(ps.ByName("table"))
 
-	found, err := bin.GetByID(converter.StrToInt64(ps.ByName("id")))
+	id, err := converter.StrToInt64E(ps.ByName("id"))
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting binary id")
+		errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, "id")
+		return
+	}
+	found, err := bin.GetByID(id)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Errorf("getting binary by id")
 		errorAPI(w, "E_SERVER", http.StatusInternalServerError)
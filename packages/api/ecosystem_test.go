@@ -23,6 +23,7 @@ import (
 
 	"github.com/GenesisKernel/go-genesis/packages/converter"
 	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -247,3 +248,51 @@ func TestAppParams(t *testing.T) {
 	assert.EqualError(t, sendGet(`appparam/1/myval`, nil, &ret2), `400 {"error": "E_PARAMNOTFOUND", "msg": "Parameter myval has not been found" , "params": ["myval"]}`)
 	assert.Len(t, ret2.Value, 0)
 }
+
+func TestEmission(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	name := randName(`emission`)
+	assert.NoError(t, postTx("NewContract", &url.Values{"Value": {`contract ` + name + ` {
+		data {
+			Amount money
+			Burn bool "optional"
+		}
+		action {
+			if $Burn {
+				Burn($key_id, $Amount)
+			} else {
+				Issue($key_id, $Amount)
+			}
+			$result = TotalSupply()
+		}
+	}`}, "ApplicationId": {"1"}, "Conditions": {"true"}}))
+
+	err := postTx(name, &url.Values{"Amount": {"100"}})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `Tokens can be only issued or burnt by the emission contract`)
+	}
+
+	assert.NoError(t, postTx(`EditParameter`, &url.Values{"Id": {`16`}, "Value": {name},
+		"Conditions": {`ContractConditions("MainCondition")`}}))
+
+	var before ecosystemsResult
+	assert.NoError(t, sendGet(`ecosystems`, nil, &before))
+	supply, err := decimal.NewFromString(before.TotalSupply)
+	assert.NoError(t, err)
+
+	_, msg, err := postTxResult(name, &url.Values{"Amount": {"100"}})
+	assert.NoError(t, err)
+	assert.Equal(t, supply.Add(decimal.New(100, 0)).String(), msg)
+
+	_, msg, err = postTxResult(name, &url.Values{"Amount": {"40"}, "Burn": {"true"}})
+	assert.NoError(t, err)
+	assert.Equal(t, supply.Add(decimal.New(60, 0)).String(), msg)
+
+	var after ecosystemsResult
+	assert.NoError(t, sendGet(`ecosystems`, nil, &after))
+	assert.Equal(t, msg, after.TotalSupply)
+
+	err = postTx(name, &url.Values{"Amount": {supply.Add(decimal.New(61, 0)).String()}, "Burn": {"true"}})
+	assert.Error(t, err)
+}
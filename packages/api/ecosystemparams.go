@@ -28,10 +28,12 @@ import (
 )
 
 type paramValue struct {
-	ID         string `json:"id"`
-	Name       string `json:"name"`
-	Value      string `json:"value"`
-	Conditions string `json:"conditions"`
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Value           string `json:"value"`
+	Conditions      string `json:"conditions"`
+	PendingValue    string `json:"pending_value,omitempty"`
+	ActivationBlock int64  `json:"activation_block,omitempty"`
 }
 
 type ecosystemParamsResult struct {
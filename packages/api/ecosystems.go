@@ -26,7 +26,8 @@ import (
 )
 
 type ecosystemsResult struct {
-	Number uint32 `json:"number"`
+	Number      uint32 `json:"number"`
+	TotalSupply string `json:"total_supply"`
 }
 
 func ecosystems(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) (err error) {
@@ -36,6 +37,16 @@ func ecosystems(w http.ResponseWriter, r *http.Request, data *apiData, logger *l
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("Error getting next ecosystem id")
 		return err
 	}
-	data.result = &ecosystemsResult{Number: uint32(number - 1)}
+
+	ecosystem := &model.Ecosystem{}
+	if _, err = ecosystem.Get(data.ecosystemId); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "ecosystem": data.ecosystemId}).Error("getting ecosystem")
+		return err
+	}
+	supply := ecosystem.TotalSupply
+	if len(supply) == 0 {
+		supply = `0`
+	}
+	data.result = &ecosystemsResult{Number: uint32(number - 1), TotalSupply: supply}
 	return
 }
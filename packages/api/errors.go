@@ -16,42 +16,150 @@
 
 package api
 
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/GenesisKernel/go-genesis/packages/language"
+)
+
+// errorsAppID is the application whose language resources translate error messages,
+// the name of a resource is the code of the error
+const errorsAppID = 1
+
 var (
 	apiErrors = map[string]string{
+		`E_ARCHIVE`:         `Archive mode is disabled`,
+		`E_BLOCKFORMAT`:     `Block format %s is not supported`,
 		`E_CONTRACT`:        `There is not %s contract`,
 		`E_DBNIL`:           `DB is nil`,
 		`E_DELETEDKEY`:      `The key is deleted`,
 		`E_ECOSYSTEM`:       `Ecosystem %d doesn't exist`,
+		`E_EMPTYFIELD`:      `%s is empty`,
 		`E_EMPTYPUBLIC`:     `Public key is undefined`,
 		`E_EMPTYSIGN`:       `Signature is undefined`,
+		`E_EXPORTFORMAT`:    `Export format %s is not supported`,
+		`E_EXPORTLIMIT`:     `Export is rate limited, try again in %d seconds`,
+		`E_FORMAT`:          `Source can't be formatted: %s`,
 		`E_HASHWRONG`:       `Hash is incorrect`,
 		`E_HASHNOTFOUND`:    `Hash has not been found`,
 		`E_HEAVYPAGE`:       `This page is heavy`,
+		`E_IDEMPOTENCY`:     `Idempotency key %s has been used for another transaction`,
+		`E_IDEMPOTENCYKEY`:  `Idempotency key is longer than %d characters`,
 		`E_INSTALLED`:       `Apla is already installed`,
+		`E_IMPORT`:          `Import file is not valid: %s`,
 		`E_INVALIDWALLET`:   `Wallet %s is not valid`,
+		`E_INVALIDADDRESS`:  `Address %s is not valid`,
+		`E_INVALIDINT`:      `Value of %s is not a valid integer`,
+		`E_INVALIDMONEY`:    `The value of money %s is not valid`,
+		`E_INVALIDAMOUNT`:   `%s`,
+		`E_PAYMENTURI`:      `Payment URI %s is not valid`,
+		`E_QRTOOLONG`:       `Payment request is too long for QR code`,
+		`E_IMPORTSIZE`:      `%s %s is larger than %d bytes`,
 		`E_LIMITFORSIGN`:    `Length of forsign is too big (%d)`,
-		`E_LIMITTXSIZE`:     `The size of tx is too big (%d)`,
+		`E_LIMITTXSIZE`:     `The size of tx is too big (%d > %d)`,
 		`E_NOTFOUND`:        `Page not found`,
+		`E_NOTINDEXED`:      `Block %d is not indexed`,
 		`E_NOTINSTALLED`:    `Apla is not installed`,
 		`E_PARAMNOTFOUND`:   `Parameter %s has not been found`,
 		`E_PERMISSION`:      `Permission denied`,
 		`E_QUERY`:           `DB query is wrong`,
+		`E_QUOTA`:           `Daily quota of API calls is exceeded until %d`,
 		`E_RECOVERED`:       `API recovered`,
 		`E_REFRESHTOKEN`:    `Refresh token is not valid`,
 		`E_SERVER`:          `Server error`,
 		`E_SIGNATURE`:       `Signature is incorrect`,
+		`E_SIGNEXPIRED`:     `Signature is expired`,
+		`E_SIGNERS`:         `Signers are wrong: %v`,
+		`E_SIGNREQUIRED`:    `%d signatures are required`,
 		`E_UNKNOWNSIGN`:     `Unknown signature`,
 		`E_STATELOGIN`:      `%s is not a membership of ecosystem %s`,
 		`E_TABLENOTFOUND`:   `Table %s has not been found`,
 		`E_TOKEN`:           `Token is not valid`,
 		`E_TOKENEXPIRED`:    `Token is expired by %s`,
+		`E_TXCOMPILE`:       `%s`,
+		`E_TXERROR`:         `%s`,
+		`E_TXFAILED`:        `%s`,
+		`E_TXINFO`:          `%s`,
+		`E_TXPANIC`:         `%s`,
+		`E_TXWARNING`:       `%s`,
 		`E_UNAUTHORIZED`:    `Unauthorized`,
 		`E_UNDEFINEVAL`:     `Value %s is undefined`,
 		`E_UNKNOWNUID`:      `Unknown uid`,
+		`E_UNKNOWNCONTRACT`: `Unknown contract %d`,
 		`E_VDE`:             `Virtual Dedicated Ecosystem %d doesn't exist`,
 		`E_VDECREATED`:      `Virtual Dedicated Ecosystem is already created`,
 		`E_REQUESTNOTFOUND`: `Request %s doesn't exist`,
 		`E_UPDATING`:        `Node is updating blockchain`,
+		`E_WRONGAUTH`:       `Wrong authorization value`,
 		`E_STOPPING`:        `Network is stopping`,
 	}
+
+	// txstatusCodes maps the types of the transaction status errors to the error codes
+	txstatusCodes = map[string]string{
+		`compile`: `E_TXCOMPILE`,
+		`error`:   `E_TXERROR`,
+		`info`:    `E_TXINFO`,
+		`panic`:   `E_TXPANIC`,
+		`txError`: `E_TXFAILED`,
+		`warning`: `E_TXWARNING`,
+	}
 )
+
+// apiError is the error of the catalog, it keeps the code and the raw parameters of the message
+type apiError struct {
+	code   string
+	params []interface{}
+}
+
+func newError(code string, params ...interface{}) error {
+	return &apiError{code: code, params: params}
+}
+
+func (e *apiError) Error() string {
+	return formatError(apiErrors[e.code], e.params)
+}
+
+// localeWriter keeps the request data which are required for the translation of error messages
+type localeWriter struct {
+	http.ResponseWriter
+	accept string
+	data   *apiData
+}
+
+// Flush sends the buffered data to the client, it is used by the handlers which stream the response
+func (w *localeWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func formatError(template string, params []interface{}) string {
+	if len(params) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, params...)
+}
+
+// errorText returns the message of the error in the language of the request. The message is taken
+// from the language resource with the name of the code, it falls back to the English template
+func errorText(w http.ResponseWriter, code string, params []interface{}) string {
+	template := apiErrors[code]
+	if lw, ok := w.(*localeWriter); ok && lw.data.ecosystemId > 0 {
+		if text, ok := language.LangText(code, int(lw.data.ecosystemId), errorsAppID,
+			lw.accept, lw.data.vde); ok {
+			template = text
+		}
+	}
+	return formatError(template, params)
+}
+
+func errorParams(params []interface{}) string {
+	list := make([]string, 0, len(params))
+	for _, item := range params {
+		list = append(list, fmt.Sprint(item))
+	}
+	out, _ := json.Marshal(list)
+	return string(out)
+}
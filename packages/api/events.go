@@ -0,0 +1,84 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const maxEventsLimit = 1000
+
+type eventItem struct {
+	ID       string          `json:"id"`
+	BlockID  string          `json:"block_id"`
+	TxHash   string          `json:"tx_hash"`
+	Contract string          `json:"contract"`
+	Name     string          `json:"name"`
+	Data     json.RawMessage `json:"data"`
+}
+
+type eventsResult struct {
+	Count string      `json:"count"`
+	List  []eventItem `json:"list"`
+}
+
+func getEvents(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	ecosystemID, err := converter.StrToInt64E(data.params["ecosystem"].(string))
+	if err != nil || ecosystemID <= 0 {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting ecosystem id")
+		return errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, "ecosystem")
+	}
+	limit := 25
+	if data.params[`limit`].(int64) > 0 {
+		limit = int(data.params[`limit`].(int64))
+		if limit > maxEventsLimit {
+			limit = maxEventsLimit
+		}
+	}
+
+	event := &model.Event{}
+	if !model.IsTable(event.SetTablePrefix(ecosystemID).TableName()) {
+		return errorAPI(w, `E_ECOSYSTEM`, http.StatusBadRequest, ecosystemID)
+	}
+	events, err := event.GetEvents(data.params[`from_block`].(int64),
+		data.params[`name`].(string), limit)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "ecosystem": ecosystemID}).Error("getting events")
+		return errorAPI(w, `E_SERVER`, http.StatusInternalServerError)
+	}
+
+	result := &eventsResult{Count: converter.IntToStr(len(events)), List: make([]eventItem, 0, len(events))}
+	for _, item := range events {
+		result.List = append(result.List, eventItem{
+			ID:       converter.Int64ToStr(item.ID),
+			BlockID:  converter.Int64ToStr(item.BlockID),
+			TxHash:   item.TxHash,
+			Contract: item.Contract,
+			Name:     item.Name,
+			Data:     json.RawMessage(item.Data),
+		})
+	}
+	data.result = result
+	return nil
+}
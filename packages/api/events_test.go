@@ -0,0 +1,57 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvents(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	name := randName(`emit`)
+	event := randName(`Event`)
+	assert.NoError(t, postTx("NewContract", &url.Values{"Value": {`contract ` + name + ` {
+		data {
+			Value string
+		}
+		action {
+			var data map
+			data["value"] = $Value
+			EmitEvent("` + event + `", data)
+		}
+	}`}, "ApplicationId": {"1"}, "Conditions": {"true"}}))
+
+	assert.NoError(t, postTx(name, &url.Values{"Value": {"first"}}))
+	assert.NoError(t, postTx(name, &url.Values{"Value": {"second"}}))
+
+	var ret eventsResult
+	assert.NoError(t, sendGet(`events/1?name=`+event, nil, &ret))
+	if assert.Len(t, ret.List, 2) {
+		assert.Equal(t, `@1`+name, ret.List[0].Contract)
+		assert.JSONEq(t, `{"value":"first"}`, string(ret.List[0].Data))
+		assert.JSONEq(t, `{"value":"second"}`, string(ret.List[1].Data))
+
+		assert.NoError(t, sendGet(`events/1?name=`+event+`&from_block=`+ret.List[1].BlockID, nil, &ret))
+		assert.Len(t, ret.List, 1)
+	}
+
+	assert.Error(t, sendGet(`events/100000`, nil, &ret))
+}
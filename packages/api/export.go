@@ -0,0 +1,328 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// exportChunk is the number of the rows which are fetched from the cursor and flushed at once
+const exportChunk = 500
+
+var (
+	// errStreamed stops the handlers of the request whose response has been written by the handler
+	errStreamed = errors.New(`response has been streamed`)
+	errFiltered = errors.New(`rows are rejected by the filter of the table`)
+)
+
+// exportWriter writes the rows of the exported table in the format of the export
+type exportWriter interface {
+	Header(columns []string) error
+	Row(columns []string, row map[string]string) error
+	Flush() error
+}
+
+type exportFormat struct {
+	contentType string
+	newWriter   func(io.Writer) exportWriter
+}
+
+var exportFormats = map[string]exportFormat{
+	`csv`:   {`text/csv; charset=utf-8`, newCSVExport},
+	`jsonl`: {`application/x-ndjson`, newJSONLExport},
+}
+
+// csvExport writes the header row and the rows as the CSV records
+type csvExport struct {
+	w *csv.Writer
+}
+
+func newCSVExport(w io.Writer) exportWriter {
+	return &csvExport{w: csv.NewWriter(w)}
+}
+
+func (e *csvExport) Header(columns []string) error {
+	return e.w.Write(columns)
+}
+
+func (e *csvExport) Row(columns []string, row map[string]string) error {
+	record := make([]string, len(columns))
+	for i, col := range columns {
+		record[i] = row[col]
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvExport) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonlExport writes every row as the JSON object on the separate line,
+// the keys of the object keep the order of the columns
+type jsonlExport struct {
+	w *bufio.Writer
+}
+
+func newJSONLExport(w io.Writer) exportWriter {
+	return &jsonlExport{w: bufio.NewWriter(w)}
+}
+
+func (e *jsonlExport) Header(columns []string) error {
+	return nil
+}
+
+func (e *jsonlExport) Row(columns []string, row map[string]string) error {
+	e.w.WriteByte('{')
+	for i, col := range columns {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+		name, err := json.Marshal(col)
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(row[col])
+		if err != nil {
+			return err
+		}
+		e.w.Write(name)
+		e.w.WriteByte(':')
+		e.w.Write(value)
+	}
+	_, err := e.w.WriteString("}\n")
+	return err
+}
+
+func (e *jsonlExport) Flush() error {
+	return e.w.Flush()
+}
+
+// exportLimiter allows one running export for the key and the interval between the starts of its exports
+type exportLimiter struct {
+	mutex   sync.Mutex
+	started map[int64]time.Time
+	running map[int64]bool
+}
+
+var exports = &exportLimiter{
+	started: make(map[int64]time.Time),
+	running: make(map[int64]bool),
+}
+
+// acquire starts the export of the key, it returns the seconds to wait if the export isn't allowed now
+func (l *exportLimiter) acquire(keyID int64, now time.Time, interval time.Duration) int64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.running[keyID] {
+		return int64(interval/time.Second) + 1
+	}
+	if last, ok := l.started[keyID]; ok {
+		if wait := last.Add(interval).Sub(now); wait > 0 {
+			return int64((wait + time.Second - 1) / time.Second)
+		}
+	}
+	for key, last := range l.started {
+		if !l.running[key] && now.Sub(last) >= interval {
+			delete(l.started, key)
+		}
+	}
+	l.started[keyID] = now
+	l.running[keyID] = true
+	return 0
+}
+
+func (l *exportLimiter) release(keyID int64) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	delete(l.running, keyID)
+}
+
+// exportFilter evaluates the filter permission of the table for the fetched rows as DBFind does it
+func exportFilter(sc *smart.SmartContract, filter string, rows []map[string]string) (bool, error) {
+	data := make([]interface{}, len(rows))
+	for i, row := range rows {
+		data[i] = row
+	}
+	return smart.VMEvalIf(sc.VM, filter, uint32(sc.TxSmart.EcosystemID),
+		&map[string]interface{}{
+			`data`: data, `original_contract`: ``, `this_contract`: ``,
+			`ecosystem_id`: sc.TxSmart.EcosystemID,
+			`key_id`:       sc.TxSmart.KeyID, `sc`: sc,
+			`block_time`: 0, `time`: sc.TxSmart.Time})
+}
+
+// exportTable streams the rows of the table of the ecosystem with the readable columns.
+// The rows are fetched from the server-side cursor and flushed by the chunks, the number
+// of the rows is limited by Export.MaxRows and the number of the rows is sent in the trailer
+func exportTable(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	format := strings.ToLower(data.params[`format`].(string))
+	if len(format) == 0 {
+		format = `csv`
+	}
+	exportFmt, ok := exportFormats[format]
+	if !ok {
+		return errorAPI(w, `E_EXPORTFORMAT`, http.StatusBadRequest, format)
+	}
+
+	name := strings.ToLower(data.params[`name`].(string))
+	tblname := getPrefix(data) + `_` + name
+	colTypes, err := model.GetAllColumnTypes(tblname)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": tblname}).Error("getting table columns")
+		return errorAPI(w, `E_SERVER`, http.StatusInternalServerError)
+	}
+	if len(colTypes) == 0 {
+		return errorAPI(w, `E_TABLENOTFOUND`, http.StatusBadRequest, name)
+	}
+	var hasID bool
+	columns := make([]string, len(colTypes))
+	for i, col := range colTypes {
+		columns[i] = col[`column_name`]
+		hasID = hasID || columns[i] == `id`
+	}
+
+	interval := time.Duration(conf.Config.Export.Interval) * time.Second
+	if wait := exports.acquire(data.keyId, time.Now(), interval); wait > 0 {
+		logger.WithFields(log.Fields{"type": consts.AccessDenied, "key_id": data.keyId}).Warning("export is rate limited")
+		w.Header().Set("Retry-After", strconv.FormatInt(wait, 10))
+		return errorAPI(w, `E_EXPORTLIMIT`, http.StatusTooManyRequests, wait)
+	}
+	defer exports.release(data.keyId)
+
+	dbTx, err := model.StartTransaction()
+	if err != nil {
+		return errorAPI(w, `E_SERVER`, http.StatusInternalServerError)
+	}
+	defer dbTx.Rollback()
+
+	sc := requestContract(data, dbTx)
+	perm, err := sc.AccessTablePerm(tblname, `read`)
+	if err == nil {
+		err = sc.AccessColumns(tblname, &columns, false)
+	}
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.AccessDenied, "error": err, "table": tblname}).Error("exporting table")
+		return errorAPI(w, `E_PERMISSION`, http.StatusUnauthorized)
+	}
+
+	query := make([]string, len(columns))
+	for i, col := range columns {
+		query[i] = converter.EscapeName(col)
+	}
+	cursor := `DECLARE export_cursor NO SCROLL CURSOR FOR SELECT ` + strings.Join(query, `,`) +
+		` FROM ` + converter.EscapeName(tblname)
+	if hasID {
+		cursor += ` ORDER BY id`
+	}
+	if err = model.GetDB(dbTx).Exec(cursor).Error; err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": tblname}).Error("declaring export cursor")
+		return errorAPI(w, `E_SERVER`, http.StatusInternalServerError)
+	}
+
+	var (
+		out     exportWriter
+		count   int64
+		maxRows = conf.Config.Export.MaxRows
+	)
+	start := func() error {
+		w.Header().Set("Content-Type", exportFmt.contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+		w.Header().Set("Trailer", "X-Export-Rows")
+		out = exportFmt.newWriter(w)
+		return out.Header(columns)
+	}
+	for {
+		limit := int64(exportChunk)
+		if maxRows > 0 && maxRows-count < limit {
+			limit = maxRows - count
+		}
+		if limit == 0 {
+			break
+		}
+		var rows []map[string]string
+		rows, err = model.GetRows(dbTx, fmt.Sprintf(`FETCH %d FROM export_cursor`, limit), -1)
+		if err == nil && len(perm[`filter`]) > 0 && len(rows) > 0 {
+			var allowed bool
+			if allowed, err = exportFilter(sc, perm[`filter`], rows); err == nil && !allowed {
+				err = errFiltered
+			}
+		}
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": tblname}).Error("fetching export rows")
+			if out == nil {
+				if err == errFiltered {
+					return errorAPI(w, `E_PERMISSION`, http.StatusUnauthorized)
+				}
+				return errorAPI(w, `E_SERVER`, http.StatusInternalServerError)
+			}
+			return errStreamed
+		}
+		if out == nil {
+			if err = start(); err != nil {
+				break
+			}
+		}
+		for _, row := range rows {
+			if err = out.Row(columns, row); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = out.Flush()
+		}
+		if err != nil {
+			break
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		count += int64(len(rows))
+		if int64(len(rows)) < limit {
+			break
+		}
+	}
+	if err == nil && out == nil {
+		if err = start(); err == nil {
+			err = out.Flush()
+		}
+	}
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "table": tblname}).Error("writing export rows")
+		return errStreamed
+	}
+	w.Header().Set("X-Export-Rows", strconv.FormatInt(count, 10))
+	return errStreamed
+}
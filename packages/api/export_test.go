@@ -0,0 +1,78 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var exportRows = []map[string]string{
+	{`id`: `1`, `name`: `plain`, `value`: ``},
+	{`id`: `2`, `name`: `comma, "quote"`, `value`: "line\nbreak"},
+}
+
+func writeExport(t *testing.T, format string, columns []string) string {
+	var buf bytes.Buffer
+	out := exportFormats[format].newWriter(&buf)
+	require.NoError(t, out.Header(columns))
+	for _, row := range exportRows {
+		require.NoError(t, out.Row(columns, row))
+	}
+	require.NoError(t, out.Flush())
+	return buf.String()
+}
+
+func TestExportCSV(t *testing.T) {
+	columns := []string{`id`, `name`, `value`}
+	records, err := csv.NewReader(strings.NewReader(writeExport(t, `csv`, columns))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, columns, records[0])
+	assert.Equal(t, []string{`2`, `comma, "quote"`, "line\nbreak"}, records[2])
+}
+
+func TestExportJSONL(t *testing.T) {
+	columns := []string{`name`, `id`}
+	lines := strings.Split(strings.TrimSuffix(writeExport(t, `jsonl`, columns), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, `{"name":"plain","id":"1"}`, lines[0])
+	var row map[string]string
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &row))
+	assert.Equal(t, map[string]string{`id`: `2`, `name`: `comma, "quote"`}, row)
+}
+
+func TestExportLimiter(t *testing.T) {
+	limiter := &exportLimiter{started: make(map[int64]time.Time), running: make(map[int64]bool)}
+	now := time.Unix(1530000000, 0)
+	interval := time.Minute
+
+	assert.Zero(t, limiter.acquire(1, now, interval))
+	assert.NotZero(t, limiter.acquire(1, now.Add(2*interval), interval), "export is running")
+	assert.Zero(t, limiter.acquire(2, now, interval))
+
+	limiter.release(1)
+	assert.EqualValues(t, 30, limiter.acquire(1, now.Add(30*time.Second), interval))
+	assert.Zero(t, limiter.acquire(1, now.Add(interval), interval))
+}
@@ -0,0 +1,41 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type formatResult struct {
+	Source string `json:"source"`
+}
+
+// formatContract returns the source of the contracts in the canonical format
+func formatContract(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	out, err := script.FormatSource(data.params[`source`].(string))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ParseError, "error": err}).Error("formatting contract source")
+		return errorAPI(w, `E_FORMAT`, http.StatusBadRequest, err.Error())
+	}
+	data.result = &formatResult{Source: out}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatContract(t *testing.T) {
+	require.NoError(t, keyLogin(1))
+
+	var ret formatResult
+	form := url.Values{`source`: {"contract Format {action { $result = 1 }\ndata {Value int}}"}}
+	require.NoError(t, sendPost(`contract/format`, &form, &ret))
+	assert.Equal(t, `contract Format {
+    data {
+        Value int
+    }
+    action {
+        $result = 1
+    }
+}`, ret.Source)
+
+	err := sendPost(`contract/format`, &url.Values{`source`: {`contract Format { action { $a = 1 | 2 } }`}}, &ret)
+	assert.Contains(t, err.Error(), `E_FORMAT`)
+}
@@ -0,0 +1,80 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferEcosystemOwnership(t *testing.T) {
+	require.NoError(t, keyLogin(1))
+
+	var founder paramValue
+	require.NoError(t, sendGet(`ecosystemparam/founder_account`, nil, &founder))
+
+	priv, pub, err := crypto.GenHexKeys()
+	require.NoError(t, err)
+	require.NoError(t, postTx(`NewUser`, &url.Values{"NewPubkey": {pub}}))
+	newFounder := strconv.FormatInt(smart.PubToID(pub), 10)
+
+	name := randName(`founder`)
+	require.NoError(t, postTx(`NewParameter`, &url.Values{"Name": {name}, "Value": {`value`},
+		"Conditions": {`ContractConditions("MainCondition")`}}))
+	var param paramValue
+	require.NoError(t, sendGet(`ecosystemparam/`+name, nil, &param))
+	edit := &url.Values{"Id": {param.ID}, "Value": {`changed`},
+		"Conditions": {`ContractConditions("MainCondition")`}}
+
+	err = postTx(`TransferEcosystemOwnership`, &url.Values{"NewFounder": {founder.Value}})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `The key is already the founder of the ecosystem`)
+	}
+	require.NoError(t, postTx(`TransferEcosystemOwnership`, &url.Values{"NewFounder": {newFounder}}))
+
+	// the previous founder loses the access
+	err = postTx(`EditParameter`, edit)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `Sorry, you do not have access to this action.`)
+	}
+	err = postTx(`TransferEcosystemOwnership`, &url.Values{"NewFounder": {founder.Value}})
+	assert.Error(t, err)
+
+	// the new founder gains the access
+	require.NoError(t, privateLogin(1, []byte(priv)))
+	assert.NoError(t, postTx(`EditParameter`, edit))
+	require.NoError(t, postTx(`TransferEcosystemOwnership`, &url.Values{"NewFounder": {founder.Value}}))
+
+	var history listResult
+	assert.NoError(t, sendGet(`list/founder_history?limit=2`, nil, &history))
+	if assert.Len(t, history.List, 2) {
+		assert.Equal(t, newFounder, history.List[0][`old_founder`])
+		assert.Equal(t, founder.Value, history.List[0][`new_founder`])
+		assert.Equal(t, founder.Value, history.List[1][`old_founder`])
+		assert.Equal(t, newFounder, history.List[1][`new_founder`])
+	}
+
+	require.NoError(t, keyLogin(1))
+	assert.NoError(t, postTx(`EditParameter`, edit))
+}
@@ -0,0 +1,57 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/hex"
+	"net/http"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/service"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type fullNodeResult struct {
+	Title      string `json:"title,omitempty"`
+	APIAddress string `json:"api_address"`
+	KeyID      string `json:"key_id"`
+	PublicKey  string `json:"public_key"`
+	Banned     bool   `json:"banned"`
+}
+
+type fullNodesResult struct {
+	List []fullNodeResult `json:"list"`
+}
+
+// getFullNodes returns the list of the full nodes, the TCP addresses of the nodes aren't shown to the clients
+func getFullNodes(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	result := fullNodesResult{List: make([]fullNodeResult, 0)}
+	nbs := service.GetNodesBanService()
+	for _, node := range syspar.GetNodes() {
+		result.List = append(result.List, fullNodeResult{
+			Title:      node.Title,
+			APIAddress: node.APIAddress,
+			KeyID:      converter.Int64ToStr(node.KeyID),
+			PublicKey:  hex.EncodeToString(node.PublicKey),
+			Banned:     nbs != nil && nbs.IsBanned(node),
+		})
+	}
+	data.result = &result
+	return nil
+}
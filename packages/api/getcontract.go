@@ -17,7 +17,9 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 
 	"github.com/GenesisKernel/go-genesis/packages/consts"
@@ -29,10 +31,18 @@ import (
 )
 
 type contractField struct {
-	Name string `json:"name"`
-	HTML string `json:"htmltype"`
-	Type string `json:"txtype"`
-	Tags string `json:"tags"`
+	Name     string `json:"name"`
+	HTML     string `json:"htmltype"`
+	Type     string `json:"txtype"`
+	Tags     string `json:"tags"`
+	Optional bool   `json:"optional"`
+	Default  string `json:"default,omitempty"`
+}
+
+type contractConst struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
 }
 
 type getContractResult struct {
@@ -43,6 +53,7 @@ type getContractResult struct {
 	TokenID  string          `json:"tokenid"`
 	Address  string          `json:"address"`
 	Fields   []contractField `json:"fields"`
+	Consts   []contractConst `json:"consts"`
 	Name     string          `json:"name"`
 }
 
@@ -65,7 +76,9 @@ func getContract(w http.ResponseWriter, r *http.Request, data *apiData, logger *
 
 	if info.Tx != nil {
 		for _, fitem := range *info.Tx {
-			field := contractField{Name: fitem.Name, Type: fitem.Type.String(), Tags: fitem.Tags}
+			field := contractField{Name: fitem.Name, Type: fitem.Type.String(), Tags: fitem.Tags,
+				Optional: fitem.IsOptional()}
+			field.Default, _ = fitem.Default()
 
 			if strings.Contains(fitem.Tags, `hidden`) || strings.Contains(fitem.Tags, `signature`) {
 				field.HTML = `hidden`
@@ -89,6 +102,17 @@ func getContract(w http.ResponseWriter, r *http.Request, data *apiData, logger *
 	}
 	result.Fields = fields
 
+	constList := make([]contractConst, 0)
+	for name, obj := range (*contract).Block.Objects {
+		if obj.Type == script.ObjConst {
+			cinfo := obj.Value.(*script.ConstInfo)
+			constList = append(constList, contractConst{Name: name, Type: cinfo.Type.String(),
+				Value: fmt.Sprint(cinfo.Value)})
+		}
+	}
+	sort.Slice(constList, func(i, j int) bool { return constList[i].Name < constList[j].Name })
+	result.Consts = constList
+
 	data.result = result
 	return nil
 }
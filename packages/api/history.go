@@ -17,10 +17,12 @@
 package api
 
 import (
-	"encoding/json"
 	"net/http"
+	"strings"
 
+	"github.com/GenesisKernel/go-genesis/packages/conf"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
 	"github.com/GenesisKernel/go-genesis/packages/model"
 	log "github.com/sirupsen/logrus"
 )
@@ -41,17 +43,98 @@ func getHistory(w http.ResponseWriter, r *http.Request, data *apiData, logger *l
 		return errorAPI(w, err, http.StatusInternalServerError)
 	}
 	rollbackList := []map[string]string{}
+	if len(*txs) == 0 {
+		data.result = &historyResult{rollbackList}
+		return nil
+	}
+	// the rollback records can contain only the changed columns, so they are merged onto the current row
+	curVal, err := model.GetOneRow(`SELECT * FROM `+converter.EscapeName(table)+` WHERE id = ?`, id).String()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table, "id": id}).Error("getting current row")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
 	for _, tx := range *txs {
 		if tx.Data == "" {
 			continue
 		}
-		rollback := map[string]string{}
-		if err := json.Unmarshal([]byte(tx.Data), &rollback); err != nil {
+		values, err := model.UnmarshalRollbackData(tx.Data)
+		if err != nil {
 			logger.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling rollbackTx.Data from JSON")
 			return errorAPI(w, err, http.StatusInternalServerError)
 		}
+		rollback := make(map[string]string)
+		for k, v := range curVal {
+			rollback[k] = v
+		}
+		for k, v := range values {
+			rollback[k] = v
+		}
 		rollbackList = append(rollbackList, rollback)
+		curVal = rollback
 	}
 	data.result = &historyResult{rollbackList}
 	return nil
 }
+
+type rowHistoryResult struct {
+	Block int64             `json:"block"`
+	Value map[string]string `json:"value"`
+}
+
+// getRowHistory returns the row as of the block. It is available only on the archive nodes
+func getRowHistory(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	if !conf.Config.Archive.Enabled {
+		return errorAPI(w, `E_ARCHIVE`, http.StatusNotFound)
+	}
+	name := strings.ToLower(data.params["table"].(string))
+	table := getPrefix(data) + "_" + name
+	id := data.params["id"].(string)
+	blockID := data.params["block"].(int64)
+	if !model.IsTable(table) {
+		return errorAPI(w, `E_TABLENOTFOUND`, http.StatusBadRequest, name)
+	}
+
+	dbTx, err := model.StartTransaction()
+	if err != nil {
+		return errorAPI(w, `E_SERVER`, http.StatusInternalServerError)
+	}
+	defer dbTx.Rollback()
+
+	status := &model.RowHistoryStatus{}
+	found, err := status.Get(dbTx)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting row history status")
+		return errorAPI(w, `E_SERVER`, http.StatusInternalServerError)
+	}
+	if !found || blockID < status.Low || blockID > status.High {
+		return errorAPI(w, `E_NOTINDEXED`, http.StatusNotFound, blockID)
+	}
+
+	sc := requestContract(data, dbTx)
+	if _, err = sc.AccessTablePerm(table, `read`); err != nil {
+		logger.WithFields(log.Fields{"type": consts.AccessDenied, "error": err, "table": table}).Error("getting row history")
+		return errorAPI(w, `E_PERMISSION`, http.StatusUnauthorized)
+	}
+	value, err := model.RowState(dbTx, table, id, blockID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table, "id": id}).Error("getting row state")
+		return errorAPI(w, `E_SERVER`, http.StatusInternalServerError)
+	}
+	if value == nil {
+		return errorAPI(w, `E_NOTFOUND`, http.StatusNotFound)
+	}
+	columns := make([]string, 0, len(value))
+	for col := range value {
+		columns = append(columns, col)
+	}
+	if err = sc.AccessColumns(table, &columns, false); err != nil {
+		logger.WithFields(log.Fields{"type": consts.AccessDenied, "error": err, "table": table}).Error("getting row history")
+		return errorAPI(w, `E_PERMISSION`, http.StatusUnauthorized)
+	}
+	result := &rowHistoryResult{Block: blockID, Value: make(map[string]string, len(columns))}
+	for _, col := range columns {
+		result.Value[col] = value[col]
+	}
+	data.result = result
+	return nil
+}
@@ -18,7 +18,13 @@ package api
 
 import (
 	stdErrors "errors"
+	"fmt"
+	"net/url"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHistory(t *testing.T) {
@@ -46,3 +52,36 @@ func TestHistory(t *testing.T) {
 		t.Error(stdErrors.New("History should be empty"))
 	}
 }
+
+func TestRowHistory(t *testing.T) {
+	require.NoError(t, keyLogin(1))
+
+	var ret rowHistoryResult
+	err := sendGet(`rowhistory/parameters/1?block=1`, nil, &ret)
+	if err != nil && strings.Contains(err.Error(), `E_ARCHIVE`) {
+		t.Skip(`archive mode is disabled`)
+	}
+
+	name := randName(`archive`)
+	require.NoError(t, postTx(`NewParameter`, &url.Values{"Name": {name}, "Value": {`first`},
+		"Conditions": {`ContractConditions("MainCondition")`}}))
+	var param paramValue
+	require.NoError(t, sendGet(`ecosystemparam/`+name, nil, &param))
+	var first getMaxBlockIDResult
+	require.NoError(t, sendGet(`maxblockid`, nil, &first))
+
+	require.NoError(t, postTx(`EditParameter`, &url.Values{"Id": {param.ID}, "Value": {`second`},
+		"Conditions": {`ContractConditions("MainCondition")`}}))
+	var second getMaxBlockIDResult
+	require.NoError(t, sendGet(`maxblockid`, nil, &second))
+
+	require.NoError(t, sendGet(fmt.Sprintf(`rowhistory/parameters/%s?block=%d`, param.ID, first.MaxBlockID), nil, &ret))
+	assert.Equal(t, `first`, ret.Value[`value`])
+	require.NoError(t, sendGet(fmt.Sprintf(`rowhistory/parameters/%s?block=%d`, param.ID, second.MaxBlockID), nil, &ret))
+	assert.Equal(t, `second`, ret.Value[`value`])
+
+	err = sendGet(fmt.Sprintf(`rowhistory/parameters/%s?block=%d`, param.ID, second.MaxBlockID+1000), nil, &ret)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `E_NOTINDEXED`)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maxIdempotencyKey is the maximum length of the idempotency key of the client
+const maxIdempotencyKey = 255
+
+// txFingerprint returns the hash of the contract call which doesn't depend on the time,
+// the signature and the replay protection of the transaction
+func txFingerprint(smartTx *tx.SmartContract) ([]byte, error) {
+	head := fmt.Sprintf("%d,%d,%d,%s,%s,%d,", smartTx.Type, smartTx.EcosystemID, smartTx.TokenEcosystem,
+		smartTx.MaxSum, smartTx.PayOver, smartTx.SignedBy)
+	return crypto.Hash(append([]byte(head), smartTx.Data...))
+}
+
+// sentTx returns the hash of the transaction which has been sent by the key with the idempotency key
+// in the window or nil. E_IDEMPOTENCY is returned if the idempotency key has been used for another call
+func sentTx(w http.ResponseWriter, keyID int64, key string, fingerprint []byte, logger *log.Entry) ([]byte, error) {
+	if len(key) > maxIdempotencyKey {
+		return nil, errorAPI(w, `E_IDEMPOTENCYKEY`, http.StatusBadRequest, maxIdempotencyKey)
+	}
+	item := &model.IdempotencyKey{}
+	found, err := item.Get(keyID, key, time.Now().Unix()-conf.Config.IdempotencyTTL)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting idempotency key")
+		return nil, errorAPI(w, err, http.StatusInternalServerError)
+	}
+	if !found {
+		return nil, nil
+	}
+	if !bytes.Equal(item.Fingerprint, fingerprint) {
+		logger.WithFields(log.Fields{"type": consts.DuplicateObject, "key": key}).Warning("idempotency key is used for another transaction")
+		return nil, errorAPI(w, `E_IDEMPOTENCY`, http.StatusConflict, key)
+	}
+	return item.TxHash, nil
+}
+
+// saveIdempotencyKey remembers the hash of the sent transaction, the transaction isn't failed
+// if it can't be written
+func saveIdempotencyKey(keyID int64, key string, fingerprint, hash []byte, logger *log.Entry) {
+	now := time.Now().Unix()
+	item := &model.IdempotencyKey{KeyID: keyID, Key: key, Fingerprint: fingerprint, TxHash: hash, CreatedAt: now}
+	if err := item.Save(now - conf.Config.IdempotencyTTL); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("saving idempotency key")
+	}
+}
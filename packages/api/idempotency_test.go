@@ -0,0 +1,115 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxFingerprint(t *testing.T) {
+	smartTx := tx.SmartContract{Header: tx.Header{Type: 5, Time: 1530000000, EcosystemID: 1, KeyID: 10},
+		MaxSum: `100`, Nonce: 1, Data: []byte{1, 2, 3}}
+	first, err := txFingerprint(&smartTx)
+	require.NoError(t, err)
+
+	// the retry has the other time, nonce and signature
+	smartTx.Time++
+	smartTx.Nonce++
+	smartTx.BinSignatures = []byte{4}
+	second, err := txFingerprint(&smartTx)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	smartTx.Data = []byte{1, 2, 4}
+	third, err := txFingerprint(&smartTx)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, third)
+}
+
+// sendIdempotentTx sends the transaction with the idempotency key and returns its hash
+func sendIdempotentTx(txname string, params url.Values, key string) (string, error) {
+	expiration, nonce := txReplay()
+	params.Set(`tx_expiration`, expiration)
+	params.Set(`nonce`, nonce)
+	ret := make(map[string]interface{})
+	if err := sendPost(`prepare/`+txname, &params, &ret); err != nil {
+		return ``, err
+	}
+	form := url.Values{`tx_expiration`: {expiration}, `nonce`: {nonce}, `idempotency_key`: {key}}
+	if err := appendSign(ret, &form); err != nil {
+		return ``, err
+	}
+	var result contractResult
+	if err := sendPost(`contract/`+ret[`request_id`].(string), &form, &result); err != nil {
+		return ``, err
+	}
+	return result.Hash, nil
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	require.NoError(t, keyLogin(1))
+
+	key := randName(`transfer`)
+	params := url.Values{`Amount`: {`1000`}, `Recipient`: {`0005-2070-2000-0006-0200`}}
+	hash, err := sendIdempotentTx(`MoneyTransfer`, params, key)
+	require.NoError(t, err)
+	_, err = waitTx(hash)
+	require.NoError(t, err)
+
+	retry, err := sendIdempotentTx(`MoneyTransfer`, params, key)
+	require.NoError(t, err)
+	assert.Equal(t, hash, retry)
+
+	params.Set(`Amount`, `2000`)
+	_, err = sendIdempotentTx(`MoneyTransfer`, params, key)
+	assert.EqualError(t, err, `409 {"error": "E_IDEMPOTENCY", "msg": "Idempotency key `+key+
+		` has been used for another transaction" , "params": ["`+key+`"]}`)
+}
+
+func TestResubmitTx(t *testing.T) {
+	require.NoError(t, keyLogin(1))
+
+	expiration, nonce := txReplay()
+	params := url.Values{`Amount`: {`1000`}, `Recipient`: {`0005-2070-2000-0006-0200`},
+		`tx_expiration`: {expiration}, `nonce`: {nonce}}
+	ret := make(map[string]interface{})
+	require.NoError(t, sendPost(`prepare/MoneyTransfer`, &params, &ret))
+	form := url.Values{`tx_expiration`: {expiration}, `nonce`: {nonce}}
+	require.NoError(t, appendSign(ret, &form))
+
+	// the client resends the same signed transaction after the timeout
+	var first, second contractResult
+	require.NoError(t, sendPost(`contract/`+ret[`request_id`].(string), &form, &first))
+	assert.Nil(t, first.Status)
+	require.NoError(t, sendPost(`contract/`+ret[`request_id`].(string), &form, &second))
+	assert.Equal(t, first.Hash, second.Hash)
+	require.NotNil(t, second.Status)
+
+	_, err := waitTx(first.Hash)
+	require.NoError(t, err)
+	var third contractResult
+	require.NoError(t, sendPost(`contract/`+ret[`request_id`].(string), &form, &third))
+	assert.Equal(t, first.Hash, third.Hash)
+	require.NotNil(t, third.Status)
+	assert.NotEmpty(t, third.Status.BlockID)
+}
@@ -0,0 +1,218 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	importContract = `@1ImportApply`
+	importOverhead = 4096 // the size of the header, the signature and the other parameters of ImportApply
+	importItemFuel = 4000 // the estimated fuel of the import of one item
+)
+
+type importItem struct {
+	Type string `json:"Type"`
+	Name string `json:"Name"`
+}
+
+type importSplitResult struct {
+	ImportID  string                    `json:"import_id"`
+	AppName   string                    `json:"app_name"`
+	Parts     int64                     `json:"parts"`
+	Items     int64                     `json:"items"`
+	Contracts []multiPrepareRequestItem `json:"contracts"`
+}
+
+type importFailure struct {
+	Part    int64          `json:"part"`
+	Hash    string         `json:"hash"`
+	Message *txstatusError `json:"errmsg"`
+}
+
+type importProgressResult struct {
+	ImportID     string         `json:"import_id"`
+	AppName      string         `json:"app_name"`
+	Parts        int64          `json:"parts"`
+	Items        int64          `json:"items"`
+	AppliedParts int64          `json:"applied_parts"`
+	AppliedItems int64          `json:"applied_items"`
+	Failed       *importFailure `json:"failed,omitempty"`
+}
+
+// splitImport splits the items of the import into the parts, the JSON array of every part is not larger
+// than maxSize and has no more than maxItems items. The contracts are put into the last parts,
+// so the dependent contracts are compiled together if they fit into one part
+func splitImport(items []json.RawMessage, maxSize, maxItems int) ([][]json.RawMessage, error) {
+	var ordered, contracts []json.RawMessage
+	for _, raw := range items {
+		var item importItem
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return nil, err
+		}
+		if item.Type == `contracts` {
+			contracts = append(contracts, raw)
+		} else {
+			ordered = append(ordered, raw)
+		}
+	}
+	ordered = append(ordered, contracts...)
+
+	var (
+		parts [][]json.RawMessage
+		part  []json.RawMessage
+		size  int
+	)
+	for _, raw := range ordered {
+		// the brackets of the array and the comma
+		if len(raw)+2 > maxSize {
+			var item importItem
+			json.Unmarshal(raw, &item)
+			return nil, newError(`E_IMPORTSIZE`, item.Type, item.Name, maxSize)
+		}
+		if len(part) > 0 && (size+len(raw)+1 > maxSize || len(part) >= maxItems) {
+			parts = append(parts, part)
+			part, size = nil, 0
+		}
+		if len(part) == 0 {
+			size = 2
+		} else {
+			size++
+		}
+		part = append(part, raw)
+		size += len(raw)
+	}
+	if len(part) > 0 {
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// importSplit returns the parameters of the ImportApply transactions which import the uploaded file
+// by the parts under the limits of the size and the fuel of the transaction
+func importSplit(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	file, _, err := r.FormFile(`input_file`)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("getting multipart file")
+		return errorAPI(w, err.Error(), http.StatusBadRequest)
+	}
+	defer file.Close()
+	bundle, err := ioutil.ReadAll(file)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading import file")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	var input struct {
+		Name string            `json:"name"`
+		Data []json.RawMessage `json:"data"`
+	}
+	if err = json.Unmarshal(bundle, &input); err != nil {
+		logger.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling import file")
+		return errorAPI(w, `E_IMPORT`, http.StatusBadRequest, err.Error())
+	}
+	if len(input.Name) == 0 || len(input.Data) == 0 {
+		return errorAPI(w, `E_IMPORT`, http.StatusBadRequest, `empty application`)
+	}
+
+	maxSize := syspar.GetMaxTxSize()
+	if limit := syspar.GetMaxForsignSize(); limit < maxSize {
+		maxSize = limit
+	}
+	maxItems := syspar.GetMaxTxFuel() / importItemFuel
+	if maxItems < 1 {
+		maxItems = 1
+	}
+	parts, err := splitImport(input.Data, int(maxSize-importOverhead), int(maxItems))
+	if err != nil {
+		return errorAPI(w, `E_IMPORT`, http.StatusBadRequest, err.Error())
+	}
+	hash, err := crypto.Hash(bundle)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("getting hash of import file")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+
+	result := importSplitResult{ImportID: hex.EncodeToString(hash), AppName: input.Name,
+		Parts: int64(len(parts)), Items: int64(len(input.Data))}
+	for i, part := range parts {
+		partData, err := json.Marshal(part)
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling import part")
+			return errorAPI(w, err, http.StatusInternalServerError)
+		}
+		result.Contracts = append(result.Contracts, multiPrepareRequestItem{
+			Contract: importContract,
+			Params: map[string]string{
+				"ImportId": result.ImportID,
+				"AppName":  result.AppName,
+				"Part":     strconv.Itoa(i),
+				"Parts":    strconv.FormatInt(result.Parts, 10),
+				"Items":    strconv.FormatInt(result.Items, 10),
+				"Data":     string(partData),
+			},
+		})
+	}
+	data.result = &result
+	return nil
+}
+
+// getImportProgress returns the progress of the last import of the key. hashes are the comma separated
+// hashes of the ImportApply transactions in the order of the parts, the status of the next part
+// is checked and it's returned as failed if its transaction has an error
+func getImportProgress(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	value, err := model.GetOneRow(`SELECT value FROM "`+getPrefix(data)+`_buffer_data"
+		WHERE member_id = ? AND key = 'import_progress'`, data.keyId).String()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting import progress")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	result := &importProgressResult{}
+	if len(value[`value`]) > 0 {
+		if err = json.Unmarshal([]byte(value[`value`]), result); err != nil {
+			logger.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling import progress")
+			return errorAPI(w, err, http.StatusInternalServerError)
+		}
+	}
+	if hashes := data.ParamString(`hashes`); len(hashes) > 0 {
+		list := strings.Split(hashes, `,`)
+		if result.AppliedParts < int64(len(list)) {
+			hash := strings.TrimSpace(list[result.AppliedParts])
+			status, err := getTxStatus(hash, w, logger)
+			if err != nil {
+				return err
+			}
+			if status.Message != nil {
+				result.Failed = &importFailure{Part: result.AppliedParts, Hash: hash, Message: status.Message}
+			}
+		}
+	}
+	data.result = result
+	return nil
+}
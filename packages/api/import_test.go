@@ -0,0 +1,84 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportSplit(t *testing.T) {
+	require.NoError(t, keyLogin(1))
+
+	name := randName(`imp`)
+	items := make([]map[string]string, 0)
+	for i := 0; i < 12; i++ {
+		items = append(items, map[string]string{"Type": "pages", "Name": fmt.Sprintf(`%s%d`, name, i),
+			"Value": "Span(import)", "Menu": "default_menu", "Conditions": "true"})
+	}
+	bundle, err := json.Marshal(map[string]interface{}{"name": name, "data": items})
+	require.NoError(t, err)
+
+	var split importSplitResult
+	require.NoError(t, sendMultipart(`import/split`, nil, map[string][]byte{"input_file": bundle}, &split))
+	assert.Equal(t, name, split.AppName)
+	assert.Equal(t, int64(12), split.Items)
+	assert.True(t, split.Parts > 1)
+	require.Len(t, split.Contracts, int(split.Parts))
+
+	expiration, nonce := txReplay()
+	req := &multiPrepareRequest{TxExpiration: expiration, Nonce: nonce, Contracts: split.Contracts}
+	res, err := multiPrepare(req)
+	require.NoError(t, err)
+	hashes, err := multiRequest(req, res)
+	require.NoError(t, err)
+	require.NoError(t, multiWaitTxStatus(hashes))
+
+	var progress importProgressResult
+	require.NoError(t, sendGet(`import/progress`, nil, &progress))
+	assert.Equal(t, split.ImportID, progress.ImportID)
+	assert.Equal(t, split.Parts, progress.AppliedParts)
+	assert.Equal(t, int64(12), progress.AppliedItems)
+	assert.Nil(t, progress.Failed)
+
+	page := map[string]interface{}{}
+	assert.NoError(t, sendGet(`interface/page/`+name+`11`, nil, &page))
+	assert.Equal(t, `Span(import)`, page[`value`])
+
+	// the applied part is skipped on retry
+	params := url.Values{}
+	for key, value := range split.Contracts[0].Params {
+		params.Set(key, value)
+	}
+	assert.NoError(t, postTx(`ImportApply`, &params))
+	require.NoError(t, sendGet(`import/progress`, nil, &progress))
+	assert.Equal(t, split.Parts, progress.AppliedParts)
+
+	// the sequence stops at the part which hasn't been applied
+	params.Set("ImportId", split.ImportID+`1`)
+	params.Set("Part", "1")
+	err = postTx(`ImportApply`, &params)
+	if assert.Error(t, err) {
+		assert.True(t, strings.Contains(err.Error(), `Part 0 of the import must be applied before part 1`))
+	}
+}
@@ -28,7 +28,7 @@ func TestGetInterfaceRow(t *testing.T) {
 		}
 	}
 
-	errUnauthorized := `401 {"error": "E_UNAUTHORIZED", "msg": "Unauthorized" }`
+	errUnauthorized := `401 {"error": "E_UNAUTHORIZED", "msg": "Unauthorized" , "params": []}`
 	for _, c := range cases {
 		assert.EqualError(t, sendGet(c.url+"-", &url.Values{}, nil), errUnauthorized)
 	}
@@ -18,9 +18,14 @@ package api
 
 import (
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -146,3 +151,37 @@ func TestLang(t *testing.T) {
 		assert.Equal(t, v.expect, RawToString(ret.Tree))
 	}
 }
+
+func TestErrorLang(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	// the resource can be already created by the previous run
+	postTx("NewLang", &url.Values{
+		"Name":          {`E_TABLENOTFOUND`},
+		"Trans":         {`{"en": "Table %s has not been found", "fr": "La table %s est introuvable"}`},
+		"ApplicationId": {"1"},
+	})
+
+	cases := []struct {
+		accept string
+		expect string
+	}{
+		{``, `{"error": "E_TABLENOTFOUND", "msg": "Table qwert has not been found" , "params": ["qwert"]}`},
+		{`fr-FR,fr;q=0.8`, `{"error": "E_TABLENOTFOUND", "msg": "La table qwert est introuvable" , "params": ["qwert"]}`},
+		{`de`, `{"error": "E_TABLENOTFOUND", "msg": "Table qwert has not been found" , "params": ["qwert"]}`},
+	}
+	for _, v := range cases {
+		req, err := http.NewRequest("GET", apiAddress+consts.ApiPath+`list/qwert`, nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", jwtPrefix+gAuth)
+		req.Header.Set("Accept-Language", v.accept)
+		resp, err := http.DefaultClient.Do(req)
+		if assert.NoError(t, err) {
+			data, err := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.Equal(t, v.expect, strings.TrimSpace(string(data)))
+		}
+	}
+}
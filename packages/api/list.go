@@ -42,7 +42,16 @@ func list(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Ent
 		cols = `id,` + converter.EscapeName(data.params[`columns`].(string))
 	}
 
-	count, err := model.GetRecordsCountTx(nil, strings.Trim(table, `"`))
+	where, err := deletedFilter(w, data, logger)
+	if err != nil {
+		return err
+	}
+	var count int64
+	if len(where) > 0 {
+		count, err = model.GetRecordsCountWhere(nil, strings.Trim(table, `"`), where)
+	} else {
+		count, err = model.GetRecordsCountTx(nil, strings.Trim(table, `"`))
+	}
 	if err != nil {
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table}).Error("Getting table records count")
 		return errorAPI(w, `E_TABLENOTFOUND`, http.StatusBadRequest, data.params[`name`].(string))
@@ -53,7 +62,10 @@ func list(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Ent
 	} else {
 		limit = 25
 	}
-	list, err := model.GetAll(`select `+cols+` from `+table+` order by id desc`+
+	if len(where) > 0 {
+		where = ` where ` + where
+	}
+	list, err := model.GetRows(nil, `select `+cols+` from `+table+where+` order by id desc`+
 		fmt.Sprintf(` offset %d `, data.params[`offset`].(int64)), limit)
 	if err != nil {
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table}).Error("Getting rows from table")
@@ -64,3 +76,27 @@ func list(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Ent
 	}
 	return
 }
+
+// deletedFilter returns the condition which hides the soft-deleted rows of the requested table.
+// The deleted rows are returned with include_deleted only if the key has read access to the table
+func deletedFilter(w http.ResponseWriter, data *apiData, logger *log.Entry) (string, error) {
+	table := &model.Table{}
+	table.SetTablePrefix(getPrefix(data))
+	perm, err := table.GetPermissions(nil, data.params[`name`].(string), ``)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": data.params[`name`]}).Error("getting table permissions")
+		return ``, errorAPI(w, `E_SERVER`, http.StatusInternalServerError)
+	}
+	if perm[`soft_delete`] != `true` {
+		return ``, nil
+	}
+	if data.params[`include_deleted`].(int64) == 0 {
+		return `deleted = 0`, nil
+	}
+	tblname := getPrefix(data) + `_` + data.params[`name`].(string)
+	if _, err = requestContract(data, nil).AccessTablePerm(tblname, `read`); err != nil {
+		logger.WithFields(log.Fields{"type": consts.AccessDenied, "error": err, "table": tblname}).Error("reading deleted rows")
+		return ``, errorAPI(w, `E_PERMISSION`, http.StatusUnauthorized)
+	}
+	return ``, nil
+}
@@ -17,6 +17,7 @@
 package api
 
 import (
+	"bytes"
 	"net/http"
 	"strings"
 	"time"
@@ -25,7 +26,6 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/notificator"
 	"github.com/GenesisKernel/go-genesis/packages/publisher"
-	msgpack "gopkg.in/vmihailenco/msgpack.v2"
 
 	"github.com/GenesisKernel/go-genesis/packages/converter"
 	"github.com/GenesisKernel/go-genesis/packages/crypto"
@@ -39,6 +39,7 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/utils"
 	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
 	"github.com/dgrijalva/jwt-go"
+	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -57,6 +58,17 @@ type loginResult struct {
 	IsVDE       bool          `json:"vde,omitempty"`
 	Timestamp   string        `json:"timestamp,omitempty"`
 	Roles       []rolesResult `json:"roles,omitempty"`
+
+	EcosystemName string     `json:"ecosystem_name,omitempty"`
+	Key           *keyStatus `json:"key,omitempty"`
+	MemberName    string     `json:"member_name,omitempty"`
+	AvatarID      string     `json:"avatar_id,omitempty"`
+	Notifications string     `json:"notifications,omitempty"`
+}
+
+type keyStatus struct {
+	Exists bool `json:"exists"`
+	Active bool `json:"active"`
 }
 
 type rolesResult struct {
@@ -95,7 +107,10 @@ func login(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.En
 	if len(data.params[`key_id`].(string)) > 0 {
 		wallet = converter.StringToAddress(data.params[`key_id`].(string))
 	} else if len(data.params[`pubkey`].([]byte)) > 0 {
-		wallet = crypto.Address(data.params[`pubkey`].([]byte))
+		if wallet, err = getKeyIDByPublicKey(ecosystemID, data.params[`pubkey`].([]byte)); err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting key id by public key")
+			return errorAPI(w, err, http.StatusInternalServerError)
+		}
 	}
 
 	account := &model.Key{}
@@ -140,6 +155,7 @@ func login(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.En
 				KeyID:       conf.Config.KeyID,
 				NetworkID:   consts.NETWORK_ID,
 				PublicKey:   pubkey,
+				SignVersion: tx.ForSignVersion,
 			},
 			SignedBy: smart.PubToID(NodePublicKey),
 			Data:     params,
@@ -163,9 +179,9 @@ func login(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.En
 				return err
 			}
 
-			serializedContract, err := msgpack.Marshal(sc)
+			serializedContract, err := sc.Marshal()
 			if err != nil {
-				logger.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling smart contract to msgpack")
+				logger.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling smart contract")
 				return errorAPI(w, err, http.StatusInternalServerError)
 			}
 			ret, err := VDEContract(serializedContract, data)
@@ -201,12 +217,15 @@ func login(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.En
 		data.roleId = checkedRole
 	}
 
+	// the signature is checked by the stored key of the wallet, the key of the request is used
+	// only if the key isn't stored yet
+	if pubkey, err = utils.SignerPublicKey(wallet, pubkey, data.params[`pubkey`].([]byte)); err != nil {
+		logger.WithFields(log.Fields{"type": consts.InvalidObject, "key_id": wallet, "error": err}).Error("getting public key of wallet")
+		return errorAPI(w, `E_SIGNATURE`, http.StatusBadRequest)
+	}
 	if len(pubkey) == 0 {
-		pubkey = data.params[`pubkey`].([]byte)
-		if len(pubkey) == 0 {
-			logger.WithFields(log.Fields{"type": consts.EmptyObject}).Error("public key is empty")
-			return errorAPI(w, `E_EMPTYPUBLIC`, http.StatusBadRequest)
-		}
+		logger.WithFields(log.Fields{"type": consts.EmptyObject}).Error("public key is empty")
+		return errorAPI(w, `E_EMPTYPUBLIC`, http.StatusBadRequest)
 	}
 
 	verify, err := crypto.CheckSign(pubkey, nonceSalt+msg, data.params[`signature`].([]byte))
@@ -220,7 +239,8 @@ func login(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.En
 		return errorAPI(w, `E_SIGNATURE`, http.StatusBadRequest)
 	}
 
-	address := crypto.KeyToAddress(pubkey)
+	// the address of the changed key differs from the address of the wallet
+	address := converter.AddressToString(wallet)
 
 	var (
 		sp      model.StateParameter
@@ -232,7 +252,10 @@ func login(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.En
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting founder_account parameter")
 		return errorAPI(w, `E_SERVER`, http.StatusBadRequest)
 	} else if ok {
-		founder = converter.StrToInt64(sp.Value)
+		// the malformed founder account doesn't match any key
+		if founder, err = converter.StrToInt64E(sp.Value); err != nil {
+			log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting founder_account parameter")
+		}
 	}
 
 	result := loginResult{
@@ -286,6 +309,15 @@ func login(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.En
 	notificator.AddUser(wallet, ecosystemID)
 	notificator.UpdateNotifications(ecosystemID, []int64{wallet})
 
+	sc := requestContract(data, nil)
+	sc.TxSmart.EcosystemID, sc.TxSmart.KeyID = ecosystemID, wallet
+	if err = loginDigest(sc, &result); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting login digest")
+	}
+	if len(readableColumns(sc, `roles_participants`, `role`)) == 0 {
+		return nil
+	}
+
 	ra := &model.RolesParticipants{}
 	roles, err := ra.SetTablePrefix(ecosystemID).GetActiveMemberRoles(wallet)
 	if err != nil {
@@ -308,6 +340,75 @@ func login(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.En
 	return nil
 }
 
+// loginDigest fills the ecosystem name, the key status, the member and the notification count.
+// The data of the table is omitted if the key cannot read it by the permissions of the table
+func loginDigest(sc *smart.SmartContract, result *loginResult) error {
+	ecosystemID, wallet := sc.TxSmart.EcosystemID, sc.TxSmart.KeyID
+	digest, err := model.GetLoginDigest(nil, ecosystemID, wallet)
+	if err != nil {
+		return err
+	}
+	result.EcosystemName = digest.EcosystemName
+	if cols := readableColumns(sc, `keys`, `amount`); len(cols) > 0 {
+		amount, err := decimal.NewFromString(digest.Amount)
+		result.Key = &keyStatus{
+			Exists: digest.KeyExists,
+			Active: digest.KeyExists && err == nil && amount.Sign() > 0,
+		}
+	}
+	if digest.MemberExists {
+		cols := readableColumns(sc, `members`, `member_name`, `image_id`)
+		if cols[`member_name`] {
+			result.MemberName = digest.MemberName
+		}
+		if cols[`image_id`] && digest.ImageID > 0 {
+			result.AvatarID = converter.Int64ToStr(digest.ImageID)
+		}
+	}
+	if len(readableColumns(sc, `notifications`, `closed`)) > 0 {
+		result.Notifications = converter.Int64ToStr(digest.Notifications)
+	}
+	return nil
+}
+
+// readableColumns returns the columns of the ecosystem table which the key of the contract can read
+func readableColumns(sc *smart.SmartContract, table string, columns ...string) map[string]bool {
+	tblname := converter.Int64ToStr(sc.TxSmart.EcosystemID) + `_` + table
+	if _, err := sc.AccessTablePerm(tblname, `read`); err != nil {
+		return nil
+	}
+	if err := sc.AccessColumns(tblname, &columns, false); err != nil {
+		return nil
+	}
+	ret := make(map[string]bool)
+	for _, col := range columns {
+		ret[col] = true
+	}
+	return ret
+}
+
+// getKeyIDByPublicKey returns the key id of the public key. It is the address of the key
+// unless the key has replaced the key of another key id by @1ChangeKey
+func getKeyIDByPublicKey(ecosystemID int64, pubkey []byte) (int64, error) {
+	keyID := crypto.Address(pubkey)
+	key := &model.Key{}
+	key.SetTablePrefix(ecosystemID)
+	found, err := key.Get(keyID)
+	if err != nil {
+		return 0, err
+	}
+	if found && (len(key.PublicKey) == 0 || bytes.Equal(key.PublicKey, pubkey)) {
+		return keyID, nil
+	}
+	if found, err = key.GetByPublicKey(nil, pubkey); err != nil {
+		return 0, err
+	}
+	if found {
+		return key.ID, nil
+	}
+	return keyID, nil
+}
+
 func checkRoleFromParam(role, ecosystemID, wallet int64) (int64, error) {
 	if role > 0 {
 		ok, err := model.MemberHasRole(nil, ecosystemID, wallet, role)
@@ -0,0 +1,51 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginDigest(t *testing.T) {
+	require.NoError(t, keyLogin(1))
+
+	assert.NotEmpty(t, gLogin.EcosystemName)
+	assert.True(t, gLogin.IsOwner)
+	assert.NotEmpty(t, gLogin.Notifications)
+	if assert.NotNil(t, gLogin.Key) {
+		assert.True(t, gLogin.Key.Exists)
+		assert.True(t, gLogin.Key.Active)
+	}
+
+	priv, pub, err := crypto.GenHexKeys()
+	require.NoError(t, err)
+	require.NoError(t, postTx(`NewUser`, &url.Values{"NewPubkey": {pub}}))
+	require.NoError(t, privateLogin(1, []byte(priv)))
+
+	assert.False(t, gLogin.IsOwner)
+	assert.Empty(t, gLogin.MemberName)
+	if assert.NotNil(t, gLogin.Key) {
+		assert.True(t, gLogin.Key.Exists)
+		assert.False(t, gLogin.Key.Active)
+	}
+}
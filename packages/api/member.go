@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"strconv"
 
@@ -11,12 +12,59 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+type memberResult struct {
+	ID         string          `json:"id"`
+	MemberName string          `json:"member_name"`
+	ImageID    string          `json:"image_id"`
+	MemberInfo json.RawMessage `json:"member_info"`
+}
+
+func getMember(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	wallet := data.params["wallet"].(string)
+	memberID, err := converter.ParseAddress(wallet)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "wallet": wallet}).Error("converting wallet")
+		return errorAPI(w, `E_INVALIDWALLET`, http.StatusBadRequest, wallet)
+	}
+
+	member := &model.Member{}
+	member.SetTablePrefix(getPrefix(data))
+	found, err := member.Get(memberID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "ecosystem": data.ecosystemId, "member_id": memberID}).Error("getting member")
+		return errorAPI(w, "E_SERVER", http.StatusInternalServerError)
+	}
+	if !found {
+		return errorAPI(w, "E_NOTFOUND", http.StatusNotFound)
+	}
+
+	info := json.RawMessage(member.MemberInfo)
+	if len(info) == 0 {
+		info = json.RawMessage(`{}`)
+	}
+	data.result = &memberResult{
+		ID:         converter.Int64ToStr(member.ID),
+		MemberName: member.MemberName,
+		ImageID:    converter.Int64ToStr(member.ImageID),
+		MemberInfo: info,
+	}
+	return nil
+}
+
 func getAvatar(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
 	parMember := data.params["member"].(string)
 	parEcosystem := data.params["ecosystem"].(string)
 
-	memberID := converter.StrToInt64(parMember)
-	ecosystemID := converter.StrToInt64(parEcosystem)
+	memberID, err := converter.StrToInt64E(parMember)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting member id")
+		return errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, "member")
+	}
+	ecosystemID, err := converter.StrToInt64E(parEcosystem)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting ecosystem id")
+		return errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, "ecosystem")
+	}
 
 	member := &model.Member{}
 	member.SetTablePrefix(converter.Int64ToStr(ecosystemID))
@@ -36,15 +84,15 @@ func getAvatar(w http.ResponseWriter, r *http.Request, data *apiData, logger *lo
 		return errorAPI(w, "E_NOTFOUND", http.StatusNotFound)
 	}
 
-	if member.ImageID == nil {
+	if member.ImageID == 0 {
 		return errorAPI(w, "E_NOTFOUND", http.StatusNotFound)
 	}
 
 	bin := &model.Binary{}
 	bin.SetTablePrefix(converter.Int64ToStr(ecosystemID))
-	found, err = bin.GetByID(*member.ImageID)
+	found, err = bin.GetByID(member.ImageID)
 	if err != nil {
-		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "image_id": *member.ImageID}).Errorf("on getting binary by id")
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "image_id": member.ImageID}).Errorf("on getting binary by id")
 		return errorAPI(w, "E_SERVER", http.StatusInternalServerError)
 	}
 
@@ -53,7 +101,7 @@ func getAvatar(w http.ResponseWriter, r *http.Request, data *apiData, logger *lo
 	}
 
 	if len(bin.Data) == 0 {
-		log.WithFields(log.Fields{"type": consts.EmptyObject, "error": err, "image_id": *member.ImageID}).Errorf("on check avatar size")
+		log.WithFields(log.Fields{"type": consts.EmptyObject, "error": err, "image_id": member.ImageID}).Errorf("on check avatar size")
 		return errorAPI(w, "E_SERVER", http.StatusNotFound)
 	}
 
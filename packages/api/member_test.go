@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateMember(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	name := randName(`member`)
+	assert.NoError(t, postTx("NewContract", &url.Values{"Value": {`contract ` + name + ` {
+		data {
+			Name string
+			Info string
+		}
+		action {
+			UpdateMember($key_id, $Name, 0, $Info)
+		}
+	}`}, "ApplicationId": {"1"}, "Conditions": {"true"}}))
+
+	assert.NoError(t, postTx(name, &url.Values{"Name": {name}, "Info": {`{"city":"Paris"}`}}))
+	var ret memberResult
+	assert.NoError(t, sendGet(`member/`+gAddress, nil, &ret))
+	assert.Equal(t, name, ret.MemberName)
+	assert.JSONEq(t, `{"city":"Paris"}`, string(ret.MemberInfo))
+
+	err := postTx(name, &url.Values{"Name": {name}, "Info": {`[1]`}})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `Member info must be JSON object`)
+	}
+
+	assert.Error(t, sendGet(`member/0000-0000-0000`, nil, &ret))
+}
@@ -19,16 +19,31 @@ package api
 import (
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/service"
 	"github.com/GenesisKernel/go-genesis/packages/smart"
 	"github.com/GenesisKernel/go-genesis/packages/utils"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// node handles the requests of the node operator, the name is either the pause/resume
+// of the block generation or the contract that is signed by the node key
+func (h *contractHandlers) node(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	switch data.params[`name`].(string) {
+	case `pause`:
+		return nodePause(w, r, data, logger)
+	case `resume`:
+		return nodeResume(w, r, data, logger)
+	}
+	return h.nodeContract(w, r, data, logger)
+}
+
 func (h *contractHandlers) nodeContract(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
 	var err error
 
@@ -67,3 +82,67 @@ func (h *contractHandlers) nodeContract(w http.ResponseWriter, r *http.Request,
 	}
 	return nil
 }
+
+// nodeSignExpire is the time in seconds while the signature of the node request is valid
+const nodeSignExpire = 60
+
+type nodeGenerationResult struct {
+	Paused bool `json:"paused"`
+}
+
+// checkNodeSignature checks that the request is signed by the private key of the node.
+// The signed string is "action,time", where time is the unix time of the request
+func checkNodeSignature(w http.ResponseWriter, data *apiData, logger *log.Entry, action string) error {
+	if len(data.params[`signature`].([]byte)) == 0 {
+		return errorAPI(w, `E_EMPTYSIGN`, http.StatusBadRequest)
+	}
+	signTime := data.params[`time`].(int64)
+	if diff := time.Now().Unix() - signTime; diff > nodeSignExpire || diff < -nodeSignExpire {
+		logger.WithFields(log.Fields{"type": consts.ParameterExceeded, "time": signTime}).Error("node request is expired")
+		return errorAPI(w, `E_SIGNEXPIRED`, http.StatusBadRequest)
+	}
+
+	_, NodePublicKey, err := utils.GetNodeKeys()
+	if err != nil {
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	pubkey, err := hex.DecodeString(NodePublicKey)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding public key from hex")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+
+	forSign := fmt.Sprintf("%s,%d", action, signTime)
+	verify, err := crypto.CheckSign(pubkey, forSign, data.params[`signature`].([]byte))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err, "for_sign": forSign}).Error("checking node signature")
+		return errorAPI(w, err, http.StatusBadRequest)
+	}
+	if !verify {
+		logger.WithFields(log.Fields{"type": consts.InvalidObject, "for_sign": forSign}).Error("incorrect node signature")
+		return errorAPI(w, `E_SIGNATURE`, http.StatusUnauthorized)
+	}
+	return nil
+}
+
+func nodePause(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	if err := checkNodeSignature(w, data, logger, `pause`); err != nil {
+		return err
+	}
+	if err := service.PauseGeneration(); err != nil {
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	data.result = &nodeGenerationResult{Paused: service.IsGenerationPaused()}
+	return nil
+}
+
+func nodeResume(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	if err := checkNodeSignature(w, data, logger, `resume`); err != nil {
+		return err
+	}
+	if err := service.ResumeGeneration(); err != nil {
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	data.result = &nodeGenerationResult{Paused: service.IsGenerationPaused()}
+	return nil
+}
@@ -53,6 +53,8 @@ type multiPrepareRequest struct {
 	MaxSum         string `json:"max_sum"`
 	Payover        string `json:"payover"`
 	SignedBy       string `json:"signed_by"`
+	TxExpiration   string `json:"tx_expiration"`
+	Nonce          string `json:"nonce"`
 
 	Contracts []multiPrepareRequestItem `json:"contracts"`
 }
@@ -73,24 +75,36 @@ func (h *contractHandlers) prepareMultipleContract(w http.ResponseWriter, r *htt
 		return errorAPI(w, err, http.StatusBadRequest)
 	}
 
-	tokenEcosystem := converter.StrToInt64(requests.TokenEcosystem)
+	tokenEcosystem, err := optionalInt(requests.TokenEcosystem)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting token_ecosystem")
+		return errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, "token_ecosystem")
+	}
 	maxSum := requests.MaxSum
 	payOver := requests.Payover
+	if err = checkAmounts(w, maxSum, payOver, logger); err != nil {
+		return err
+	}
 	var signedBy int64
 	if requests.SignedBy != "" {
-		signedBy = converter.StrToInt64(requests.SignedBy)
+		if signedBy, err = converter.StrToInt64E(requests.SignedBy); err != nil {
+			logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting signed_by")
+			return errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, "signed_by")
+		}
+	}
+
+	expiration, nonce, err := multiReplayParams(w, requests.TxExpiration, requests.Nonce, logger)
+	if err != nil {
+		return err
 	}
 
 	req := h.multiRequests.NewMultiRequest()
 	forSigns := []string{}
 	limitForsign := syspar.GetMaxForsignSize()
-	for _, c := range requests.Contracts {
+	for i, c := range requests.Contracts {
 		var smartTx tx.SmartContract
-		contract, parerr, err := validateSmartContractJSON(r, data, c.Contract, c.Params)
+		contract, err := validateSmartContractJSON(r, data, c.Contract, c.Params)
 		if err != nil {
-			if strings.HasPrefix(err.Error(), `E_`) {
-				return errorAPI(w, err.Error(), http.StatusBadRequest, parerr)
-			}
 			return errorAPI(w, err, http.StatusBadRequest)
 		}
 		info := (*contract).Block.Info.(*script.ContractInfo)
@@ -100,6 +114,7 @@ func (h *contractHandlers) prepareMultipleContract(w http.ResponseWriter, r *htt
 		if signedBy != 0 {
 			smartTx.SignedBy = signedBy
 		}
+		smartTx.Expiration, smartTx.Nonce = expiration, multiNonce(nonce, i)
 
 		smartTx.RequestID = req.ID
 		smartTx.Header = tx.Header{
@@ -109,6 +124,7 @@ func (h *contractHandlers) prepareMultipleContract(w http.ResponseWriter, r *htt
 			KeyID:       data.keyId,
 			RoleID:      data.roleId,
 			NetworkID:   consts.NETWORK_ID,
+			SignVersion: tx.ForSignVersion,
 		}
 		forsign := []string{smartTx.ForSign()}
 		if info.Tx != nil {
@@ -144,20 +160,21 @@ func (h *contractHandlers) prepareContract(w http.ResponseWriter, r *http.Reques
 		smartTx tx.SmartContract
 	)
 
-	contract, parerr, err := validateSmartContract(r, data, &result, data.params["name"].(string))
+	contract, err := validateSmartContract(r, data, &result, data.params["name"].(string))
 	if err != nil {
-		if strings.HasPrefix(err.Error(), `E_`) {
-			return errorAPI(w, err.Error(), http.StatusBadRequest, parerr)
-		}
 		return errorAPI(w, err, http.StatusBadRequest)
 	}
 	info := (*contract).Block.Info.(*script.ContractInfo)
 	smartTx.TokenEcosystem = data.params[`token_ecosystem`].(int64)
 	smartTx.MaxSum = data.params[`max_sum`].(string)
 	smartTx.PayOver = data.params[`payover`].(string)
+	if err = checkAmounts(w, smartTx.MaxSum, smartTx.PayOver, logger); err != nil {
+		return err
+	}
 	if data.params[`signed_by`] != nil {
 		smartTx.SignedBy = data.params[`signed_by`].(int64)
 	}
+	smartTx.Expiration, smartTx.Nonce = replayParams(data)
 
 	req := h.requests.NewRequest(contract.Name)
 
@@ -169,6 +186,11 @@ func (h *contractHandlers) prepareContract(w http.ResponseWriter, r *http.Reques
 		KeyID:       data.keyId,
 		RoleID:      data.roleId,
 		NetworkID:   consts.NETWORK_ID,
+		SignVersion: signVersion(data),
+	}
+	if err = setSigners(&smartTx, data); err != nil {
+		logger.WithFields(log.Fields{"type": consts.InvalidObject, "error": err}).Error("setting signers")
+		return errorAPI(w, `E_SIGNERS`, http.StatusBadRequest, err)
 	}
 
 	forsign := []string{smartTx.ForSign()}
@@ -225,7 +247,11 @@ func forsignJSONData(w http.ResponseWriter, params map[string]string, logger *lo
 			val = strings.TrimSpace(params[fitem.Name])
 			requestParams[fitem.Name] = val
 			if fitem.ContainsTag(`address`) {
-				val = converter.Int64ToStr(converter.StringToAddress(val))
+				addr, err := addressParam(val, logger)
+				if err != nil {
+					return nil, nil, errorAPI(w, `E_INVALIDWALLET`, http.StatusBadRequest, requestParams[fitem.Name])
+				}
+				val = addr
 			} else if fitem.Type.String() == script.Decimal {
 				val = strings.TrimLeft(val, `0`)
 			} else if fitem.Type.String() == `int64` && len(val) == 0 {
@@ -236,7 +262,7 @@ func forsignJSONData(w http.ResponseWriter, params map[string]string, logger *lo
 		forsign = append(forsign, val)
 	}
 	if curSize > limitSize {
-		return nil, nil, errorAPI(w, `E_LIMITTXSIZE`, http.StatusBadRequest, curSize)
+		return nil, nil, errorAPI(w, `E_LIMITTXSIZE`, http.StatusBadRequest, curSize, limitSize)
 	}
 
 	return forsign, requestParams, nil
@@ -292,6 +318,12 @@ func forsignFormData(w http.ResponseWriter, r *http.Request, data *apiData, logg
 			}
 
 		case script.Decimal:
+			// the default value of money is specified in the minimal units
+			if def, ok := fitem.Default(); ok && len(strings.TrimSpace(r.FormValue(fitem.Name))) == 0 {
+				val = def
+				req.SetValue(fitem.Name, val)
+				break
+			}
 			d, err := decimal.NewFromString(strings.Replace(r.FormValue(fitem.Name), `,`, `.`, 1))
 			if err != nil {
 				logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting to decimal")
@@ -310,10 +342,14 @@ func forsignFormData(w http.ResponseWriter, r *http.Request, data *apiData, logg
 			req.SetValue(fitem.Name, val)
 
 		default:
-			val = strings.TrimSpace(r.FormValue(fitem.Name))
+			val = formValue(r, fitem)
 			req.SetValue(fitem.Name, val)
 			if strings.Contains(fitem.Tags, `address`) {
-				val = converter.Int64ToStr(converter.StringToAddress(val))
+				addr, err := addressParam(val, logger)
+				if err != nil {
+					return nil, errorAPI(w, `E_INVALIDWALLET`, http.StatusBadRequest, req.GetValue(fitem.Name))
+				}
+				val = addr
 			} else if fitem.Type.String() == script.Decimal {
 				val = strings.TrimLeft(val, `0`)
 			} else if fitem.Type.String() == `int64` && len(val) == 0 {
@@ -324,7 +360,90 @@ func forsignFormData(w http.ResponseWriter, r *http.Request, data *apiData, logg
 		forsign = append(forsign, val)
 	}
 	if curSize > limitSize {
-		return nil, errorAPI(w, `E_LIMITTXSIZE`, http.StatusBadRequest, curSize)
+		return nil, errorAPI(w, `E_LIMITTXSIZE`, http.StatusBadRequest, curSize, limitSize)
 	}
 	return forsign, nil
 }
+
+// addressParam converts the value of the address parameter to the key id. The empty value is 0,
+// otherwise the address must be valid, so the mistyped address isn't signed as the wrong key id
+func addressParam(val string, logger *log.Entry) (string, error) {
+	if len(val) == 0 {
+		return `0`, nil
+	}
+	addr, err := converter.ParseAddress(val)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "value": val, "error": err}).Error("converting string to address")
+		return ``, err
+	}
+	return converter.Int64ToStr(addr), nil
+}
+
+// replayParams returns the optional expiration time and nonce of the transaction
+func replayParams(data *apiData) (expiration, nonce int64) {
+	expiration, _ = data.params[`tx_expiration`].(int64)
+	nonce, _ = data.params[`nonce`].(int64)
+	return
+}
+
+// signVersion returns the optional format of the signed data, the new format is used by default.
+// The same version must be passed to prepare and contract
+func signVersion(data *apiData) int64 {
+	if version, _ := data.params[`sign_version`].(int64); version != 0 {
+		return version
+	}
+	return tx.ForSignVersion
+}
+
+// setSigners sets the co-signers and the threshold of the transaction from the optional parameters.
+// The transaction of the co-signers is sent from their address
+func setSigners(smartTx *tx.SmartContract, data *apiData) error {
+	list := data.ParamString(`signers`)
+	if len(list) == 0 {
+		return nil
+	}
+	for _, item := range strings.Split(list, `,`) {
+		id, err := converter.StrToInt64E(strings.TrimSpace(item))
+		if err != nil {
+			return err
+		}
+		smartTx.Signers = append(smartTx.Signers, id)
+	}
+	smartTx.Threshold, _ = data.params[`threshold`].(int64)
+	smartTx.KeyID = tx.SignersAddress(smartTx.Threshold, smartTx.Signers)
+	return smartTx.CheckSigners()
+}
+
+// checkAmounts rejects the transaction with the invalid max_sum or payover before it is signed or sent
+func checkAmounts(w http.ResponseWriter, maxSum, payOver string, logger *log.Entry) error {
+	smartTx := tx.SmartContract{MaxSum: maxSum, PayOver: payOver}
+	if err := smartTx.CheckAmounts(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.InvalidObject, "error": err}).Error("checking amounts of transaction")
+		return errorAPI(w, `E_INVALIDAMOUNT`, http.StatusBadRequest, err.Error())
+	}
+	return nil
+}
+
+// multiReplayParams converts the optional expiration time and nonce of the multiple request
+func multiReplayParams(w http.ResponseWriter, expiration, nonce string, logger *log.Entry) (int64, int64, error) {
+	exp, err := optionalInt(expiration)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting tx_expiration")
+		return 0, 0, errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, "tx_expiration")
+	}
+	n, err := optionalInt(nonce)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting nonce")
+		return 0, 0, errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, "nonce")
+	}
+	return exp, n, nil
+}
+
+// multiNonce returns the nonce of the i-th contract of the multiple request,
+// the contracts get the sequential nonces
+func multiNonce(nonce int64, i int) int64 {
+	if nonce == 0 {
+		return 0
+	}
+	return nonce + int64(i)
+}
@@ -13,20 +13,67 @@ import (
 func TestMultiRequest(t *testing.T) {
 	assert.NoError(t, keyLogin(1))
 
-	res, err := multiPrepare(&multiPrepareRequest{
+	expiration, nonce := txReplay()
+	req := &multiPrepareRequest{
+		TxExpiration: expiration,
+		Nonce:        nonce,
 		Contracts: []multiPrepareRequestItem{
 			{"NewLang", map[string]string{"Name": randName("lang1"), "Trans": `{"en":"test"}`, "ApplicationId": "1"}},
 			{"NewLang", map[string]string{"Name": randName("lang2"), "Trans": `{"en":"test"}`, "ApplicationId": "1"}},
 		},
-	})
+	}
+	res, err := multiPrepare(req)
 	assert.NoError(t, err)
 
-	hashes, err := multiRequest(res)
+	hashes, err := multiRequest(req, res)
 	assert.NoError(t, err)
 
 	assert.NoError(t, multiWaitTxStatus(hashes))
 }
 
+func TestKeyTxOrder(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	name := randName(`order`)
+	assert.NoError(t, postTx("NewTable", &url.Values{"Name": {name}, "ApplicationId": {"1"},
+		"Columns":     {`[{"name":"num","type":"number","index":"0","conditions":{"update":"true","read":"true"}}]`},
+		"Permissions": {`{"insert":"true","update":"true","new_column":"true"}`}}))
+	assert.NoError(t, postTx("NewContract", &url.Values{"Value": {`contract ` + name + ` {
+		data {
+			Step int
+		}
+		conditions {
+			if $Step > 0 && Int(DBFind("` + name + `").Columns("num").WhereId(1).One("num")) != $Step - 1 {
+				error "wrong order"
+			}
+		}
+		action {
+			if $Step == 0 {
+				DBInsert("` + name + `", "num", 0)
+			} else {
+				DBUpdate("` + name + `", 1, "num", $Step)
+			}
+		}
+	}`}, "ApplicationId": {"1"}, "Conditions": {"true"}}))
+	assert.NoError(t, postTx(name, &url.Values{"Step": {"0"}}))
+
+	// the transactions of the key are sent together and have to be executed in the order of the nonces
+	expiration, nonce := txReplay()
+	req := &multiPrepareRequest{TxExpiration: expiration, Nonce: nonce}
+	for i := 1; i <= 10; i++ {
+		req.Contracts = append(req.Contracts, multiPrepareRequestItem{name, map[string]string{"Step": fmt.Sprint(i)}})
+	}
+	res, err := multiPrepare(req)
+	assert.NoError(t, err)
+	hashes, err := multiRequest(req, res)
+	assert.NoError(t, err)
+	assert.NoError(t, multiWaitTxStatus(hashes))
+
+	var ret rowResult
+	assert.NoError(t, sendGet(`row/`+name+`/1`, nil, &ret))
+	assert.Equal(t, `10`, ret.Value[`num`])
+}
+
 func multiPrepare(req *multiPrepareRequest) (*multiPrepareResult, error) {
 	b, err := json.Marshal(req)
 	if err != nil {
@@ -42,10 +89,12 @@ func multiPrepare(req *multiPrepareRequest) (*multiPrepareResult, error) {
 	return prepareRes, nil
 }
 
-func multiRequest(res *multiPrepareResult) (string, error) {
+func multiRequest(prepare *multiPrepareRequest, res *multiPrepareResult) (string, error) {
 	req := contractMultiRequest{
-		Time:       res.Time,
-		Signatures: make([]string, 0, len(res.ForSigns)),
+		Time:         res.Time,
+		TxExpiration: prepare.TxExpiration,
+		Nonce:        prepare.Nonce,
+		Signatures:   make([]string, 0, len(res.ForSigns)),
 	}
 	for _, v := range res.ForSigns {
 		sign, err := getSign(v)
@@ -0,0 +1,155 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/utils/qrcode"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultQRSize = 256
+	maxQRSize     = 1024
+
+	// the image of the payment request doesn't change, so it can be cached for a long time
+	qrCacheControl = "public, max-age=86400"
+)
+
+var errInvalidAmount = errors.New(`amount must be positive`)
+
+// paymentRequest is the request of the payment which is encoded into the URI of the QR code
+type paymentRequest struct {
+	Wallet    string `json:"wallet"`
+	KeyID     string `json:"key_id"`
+	Ecosystem int64  `json:"ecosystem"`
+	Amount    string `json:"amount,omitempty"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// URI returns the canonical URI of the payment request, the parameters are sorted by the names
+func (p *paymentRequest) URI() string {
+	query := url.Values{}
+	query.Set("ecosystem", converter.Int64ToStr(p.Ecosystem))
+	if len(p.Amount) > 0 {
+		query.Set("amount", p.Amount)
+	}
+	if len(p.Comment) > 0 {
+		query.Set("comment", p.Comment)
+	}
+	return (&url.URL{Scheme: consts.PaymentScheme, Opaque: p.Wallet, RawQuery: query.Encode()}).String()
+}
+
+// newPaymentRequest checks the parameters of the payment and converts them to the canonical form
+func newPaymentRequest(w http.ResponseWriter, wallet string, ecosystem int64, amount, comment string,
+	logger *log.Entry) (*paymentRequest, error) {
+	keyID := converter.StringToAddress(wallet)
+	if keyID == 0 {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "value": wallet}).Error("converting wallet to address")
+		return nil, errorAPI(w, `E_INVALIDWALLET`, http.StatusBadRequest, wallet)
+	}
+	sys := &model.Ecosystem{}
+	found, err := sys.Get(ecosystem)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "ecosystem": ecosystem}).Error("getting ecosystem")
+		return nil, errorAPI(w, `E_SERVER`, http.StatusInternalServerError)
+	}
+	if !found {
+		return nil, errorAPI(w, `E_ECOSYSTEM`, http.StatusBadRequest, ecosystem)
+	}
+	p := &paymentRequest{Wallet: converter.AddressToString(keyID), KeyID: converter.Int64ToStr(keyID),
+		Ecosystem: ecosystem, Comment: comment}
+	if len(amount) > 0 {
+		value, err := decimal.NewFromString(amount)
+		// the amount can't be less than the minimal unit of the token
+		if err == nil && value.Sign() > 0 {
+			units := value.Mul(decimal.New(1, int32(sys.TokenDecimals)))
+			if !units.Equal(units.Truncate(0)) {
+				err = errInvalidAmount
+			}
+		} else if err == nil {
+			err = errInvalidAmount
+		}
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": amount}).Error("converting amount of payment")
+			return nil, errorAPI(w, `E_INVALIDMONEY`, http.StatusBadRequest, amount)
+		}
+		p.Amount = value.String()
+	}
+	return p, nil
+}
+
+func getQRCode(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	ecosystem := data.params[`ecosystem`].(int64)
+	if ecosystem == 0 {
+		ecosystem = data.ecosystemId
+	}
+	p, err := newPaymentRequest(w, data.params[`wallet`].(string), ecosystem, data.params[`amount`].(string),
+		data.params[`comment`].(string), logger)
+	if err != nil {
+		return err
+	}
+	code, err := qrcode.Encode([]byte(p.URI()))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ParameterExceeded, "error": err}).Error("encoding payment request to QR code")
+		return errorAPI(w, `E_QRTOOLONG`, http.StatusBadRequest)
+	}
+	size := int(data.params[`size`].(int64))
+	if size <= 0 {
+		size = defaultQRSize
+	} else if size > maxQRSize {
+		size = maxQRSize
+	}
+	out, err := code.PNG(size)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("encoding QR code to PNG")
+		return errorAPI(w, `E_SERVER`, http.StatusInternalServerError)
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", qrCacheControl)
+	w.Write(out)
+	return errStreamed
+}
+
+func parseQRCode(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	uri := data.params[`uri`].(string)
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != consts.PaymentScheme || len(u.Opaque) == 0 {
+		logger.WithFields(log.Fields{"type": consts.ParseError, "value": uri}).Error("parsing payment URI")
+		return errorAPI(w, `E_PAYMENTURI`, http.StatusBadRequest, uri)
+	}
+	query := u.Query()
+	ecosystem, err := converter.StrToInt64E(query.Get("ecosystem"))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting ecosystem of payment URI")
+		return errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, "ecosystem")
+	}
+	p, err := newPaymentRequest(w, u.Opaque, ecosystem, query.Get("amount"), query.Get("comment"), logger)
+	if err != nil {
+		return err
+	}
+	data.result = p
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"bytes"
+	"image/png"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentURI(t *testing.T) {
+	p := &paymentRequest{Wallet: `0000-0000-0000-0000-0001`, Ecosystem: 1, Amount: `1.5`, Comment: `for a & b`}
+	assert.Equal(t, `genesis:0000-0000-0000-0000-0001?amount=1.5&comment=for+a+%26+b&ecosystem=1`, p.URI())
+
+	p.Amount, p.Comment = ``, ``
+	assert.Equal(t, `genesis:0000-0000-0000-0000-0001?ecosystem=1`, p.URI())
+}
+
+func TestQRCode(t *testing.T) {
+	require.NoError(t, keyLogin(1))
+
+	form := url.Values{`wallet`: {gAddress}, `amount`: {`1.50`}, `comment`: {`for a & b`}, `size`: {`100000`}}
+	data, err := sendRawRequest(`GET`, `qrcode?`+form.Encode(), nil)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.True(t, img.Bounds().Dx() <= maxQRSize)
+
+	// the parsed request is in the canonical form
+	p := &paymentRequest{Wallet: gAddress, Ecosystem: 1, Amount: `1.50`, Comment: `for a & b`}
+	var ret paymentRequest
+	require.NoError(t, sendPost(`qrcode/parse`, &url.Values{`uri`: {p.URI()}}, &ret))
+	assert.Equal(t, `1.5`, ret.Amount)
+	assert.Equal(t, `for a & b`, ret.Comment)
+	assert.Equal(t, int64(1), ret.Ecosystem)
+	assert.Equal(t, gAddress, ret.Wallet)
+
+	_, err = sendRawRequest(`GET`, `qrcode?wallet=1234`, nil)
+	assert.Contains(t, err.Error(), `E_INVALIDWALLET`)
+	_, err = sendRawRequest(`GET`, `qrcode?wallet=`+gAddress+`&amount=-1`, nil)
+	assert.Contains(t, err.Error(), `E_INVALIDMONEY`)
+	err = sendPost(`qrcode/parse`, &url.Values{`uri`: {`bitcoin:` + gAddress}}, &ret)
+	assert.Contains(t, err.Error(), `E_PAYMENTURI`)
+}
@@ -0,0 +1,84 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/GenesisKernel/go-genesis/packages/block"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// rawBlockFormat is the binary block of the consensus format
+	rawBlockFormat = `raw`
+	// describedBlockFormat is block.DescribedBlock in the wire format of protobuf
+	describedBlockFormat = `described`
+)
+
+// getRawBlock returns the binary block, the described format can be parsed with the schema of getBlockSchema
+func getRawBlock(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	blockID, err := converter.StrToInt64E(data.params[`id`].(string))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting block id")
+		return errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, `id`)
+	}
+	format := data.params[`format`].(string)
+	if len(format) == 0 {
+		format = rawBlockFormat
+	}
+	if format != rawBlockFormat && format != describedBlockFormat {
+		return errorAPI(w, `E_BLOCKFORMAT`, http.StatusBadRequest, format)
+	}
+	b := model.Block{}
+	found, err := b.Get(blockID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting block")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	if !found {
+		logger.WithFields(log.Fields{"type": consts.NotFound, "id": blockID}).Error("block with id not found")
+		return errorAPI(w, `E_NOTFOUND`, http.StatusNotFound)
+	}
+	out, contentType := b.Data, `application/octet-stream`
+	if format == describedBlockFormat {
+		if out, err = block.DescribeBlock(b.Data); err != nil {
+			logger.WithFields(log.Fields{"type": consts.UnmarshallingError, "id": blockID, "error": err}).Error("describing block")
+			return errorAPI(w, `E_SERVER`, http.StatusInternalServerError)
+		}
+		contentType = `application/x-protobuf`
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(out)
+	return errStreamed
+}
+
+// getBlockSchema returns the protobuf definition of the described blocks
+func getBlockSchema(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	schema, err := block.BlockSchema()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("generating block schema")
+		return errorAPI(w, `E_SERVER`, http.StatusInternalServerError)
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(schema))
+	return errStreamed
+}
@@ -79,7 +79,11 @@ func getRefreshTokenClaims(w http.ResponseWriter, data *apiData, logger *log.Ent
 	}
 
 	claims, ok := data.token.Claims.(*JWTClaims)
-	if !ok || converter.StrToInt64(claims.KeyID) == 0 {
+	if ok {
+		keyID, err := converter.StrToInt64E(claims.KeyID)
+		ok = err == nil && keyID != 0
+	}
+	if !ok {
 		logger.WithFields(log.Fields{"type": consts.JWTError}).Error("getting jwt claims")
 		return nil, errorAPI(w, `E_TOKEN`, http.StatusBadRequest)
 	}
@@ -115,9 +119,14 @@ func getRefreshTokenClaims(w http.ResponseWriter, data *apiData, logger *log.Ent
 }
 
 func checkAccount(w http.ResponseWriter, logger *log.Entry, claims *JWTClaims) error {
+	ids, err := claimsToInt(claims.EcosystemID, claims.KeyID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.JWTError, "error": err}).Error("converting jwt claims")
+		return errorAPI(w, `E_TOKEN`, http.StatusBadRequest)
+	}
 	account := &model.Key{}
-	account.SetTablePrefix(converter.StrToInt64(claims.EcosystemID))
-	isAccount, err := account.Get(converter.StrToInt64(claims.KeyID))
+	account.SetTablePrefix(ids[0])
+	isAccount, err := account.Get(ids[1])
 	if err != nil {
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("selecting record from keys")
 		return errorAPI(w, err, http.StatusBadRequest)
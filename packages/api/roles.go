@@ -0,0 +1,108 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type roleItem struct {
+	ID       string `json:"id"`
+	RoleName string `json:"role_name"`
+	RoleType string `json:"role_type"`
+	ImageID  string `json:"image_id"`
+}
+
+type roleListResult struct {
+	Count string     `json:"count"`
+	List  []roleItem `json:"list"`
+}
+
+type participantItem struct {
+	ID        string          `json:"id"`
+	Member    json.RawMessage `json:"member"`
+	Appointed json.RawMessage `json:"appointed"`
+}
+
+type participantsResult struct {
+	Count string            `json:"count"`
+	List  []participantItem `json:"list"`
+}
+
+func getRoles(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	role := &model.Role{}
+	roles, err := role.SetTablePrefix(data.ecosystemId).GetActiveRoles()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "ecosystem": data.ecosystemId}).Error("getting roles")
+		return errorAPI(w, "E_SERVER", http.StatusInternalServerError)
+	}
+
+	result := &roleListResult{Count: converter.IntToStr(len(roles)), List: make([]roleItem, 0, len(roles))}
+	for _, item := range roles {
+		result.List = append(result.List, roleItem{
+			ID:       converter.Int64ToStr(item.ID),
+			RoleName: item.RoleName,
+			RoleType: converter.Int64ToStr(item.RoleType),
+			ImageID:  converter.Int64ToStr(item.ImageID),
+		})
+	}
+	data.result = result
+	return nil
+}
+
+func getRoleParticipants(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	roleID, err := converter.StrToInt64E(data.params["id"].(string))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting role id")
+		return errorAPI(w, `E_INVALIDINT`, http.StatusBadRequest, "id")
+	}
+	role := &model.Role{}
+	found, err := role.SetTablePrefix(data.ecosystemId).GetActive(nil, roleID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "role_id": roleID}).Error("getting role")
+		return errorAPI(w, "E_SERVER", http.StatusInternalServerError)
+	}
+	if !found {
+		return errorAPI(w, "E_NOTFOUND", http.StatusNotFound)
+	}
+
+	rp := &model.RolesParticipants{}
+	participants, err := rp.SetTablePrefix(data.ecosystemId).GetActiveRoleParticipants(roleID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "role_id": roleID}).Error("getting role participants")
+		return errorAPI(w, "E_SERVER", http.StatusInternalServerError)
+	}
+
+	result := &participantsResult{Count: converter.IntToStr(len(participants)),
+		List: make([]participantItem, 0, len(participants))}
+	for _, item := range participants {
+		result.List = append(result.List, participantItem{
+			ID:        converter.Int64ToStr(item.Id),
+			Member:    json.RawMessage(item.Member),
+			Appointed: json.RawMessage(item.Appointed),
+		})
+	}
+	data.result = result
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoles(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	name := randName(`role`)
+	assert.NoError(t, postTx("NewContract", &url.Values{"Value": {`contract ` + name + ` {
+		data {
+			Name string
+		}
+		action {
+			var id int
+			id = CreateRole($Name, 1)
+			AssignRole(id, $key_id)
+			$result = id
+		}
+	}`}, "ApplicationId": {"1"}, "Conditions": {"true"}}))
+
+	_, roleID, err := postTxResult(name, &url.Values{"Name": {name}})
+	assert.NoError(t, err)
+
+	var roles roleListResult
+	assert.NoError(t, sendGet(`roles`, nil, &roles))
+	var found bool
+	for _, item := range roles.List {
+		if item.RoleName == name {
+			found = item.ID == roleID
+		}
+	}
+	assert.True(t, found)
+
+	var participants participantsResult
+	assert.NoError(t, sendGet(`roles/`+roleID+`/participants`, nil, &participants))
+	assert.Equal(t, `1`, participants.Count)
+
+	err = postTx(name, &url.Values{"Name": {name}})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `Role with the same name already exists`)
+	}
+
+	access := randName(`access`)
+	assert.NoError(t, postTx("NewContract", &url.Values{"Value": {`contract ` + access + ` {
+		data {
+			Role int
+		}
+		conditions {
+			if !RoleAccess($Role) {
+				error "access denied"
+			}
+		}
+		action {
+			RemoveRole($Role, $key_id)
+		}
+	}`}, "ApplicationId": {"1"}, "Conditions": {"true"}}))
+	assert.NoError(t, postTx(access, &url.Values{"Role": {roleID}}))
+
+	err = postTx(access, &url.Values{"Role": {roleID}})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `access denied`)
+	}
+	assert.NoError(t, sendGet(`roles/`+roleID+`/participants`, nil, &participants))
+	assert.Equal(t, `0`, participants.Count)
+}
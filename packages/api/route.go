@@ -17,10 +17,12 @@
 package api
 
 import (
+	"net/http"
 	"strings"
 
 	"github.com/GenesisKernel/go-genesis/packages/conf"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/script"
 	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
 
 	hr "github.com/julienschmidt/httprouter"
@@ -52,51 +54,92 @@ func Route(route *hr.Router) {
 	route.Handle(`GET`, consts.ApiPath+`data/:table/:id/:column/:hash`, dataHandler())
 
 	get(`contract/:name`, ``, authWallet, getContract)
+	get(`contract/:name/access`, ``, authWallet, getContractAccess)
 	get(`contracts`, `?limit ?offset:int64`, authWallet, getContracts)
 	get(`getuid`, ``, getUID)
-	get(`list/:name`, `?limit ?offset:int64,?columns:string`, authWallet, list)
-	get(`row/:name/:id`, `?columns:string`, authWallet, row)
+	get(`list/:name`, `?limit ?offset ?include_deleted:int64,?columns:string`, authWallet, list)
+	get(`row/:name/:id`, `?columns:string,?include_deleted:int64`, authWallet, row)
 	get(`interface/page/:name`, ``, authWallet, getPageRow)
 	get(`interface/menu/:name`, ``, authWallet, getMenuRow)
 	get(`interface/block/:name`, ``, authWallet, getBlockInterfaceRow)
 	// get(`systemparams`, `?names:string`, authWallet, systemParams)
 	get(`table/:name`, ``, authWallet, table)
 	get(`tables`, `?limit ?offset:int64`, authWallet, tables)
+	get(`sync/:name`, `?from ?limit:int64`, authWallet, getSync)
 	get(`test/:name`, ``, getTest)
 	get(`version`, ``, getVersion)
 	get(`avatar/:ecosystem/:member`, ``, getAvatar)
+	get(`member/:wallet`, ``, authWallet, getMember)
+	get(`roles`, ``, authWallet, getRoles)
+	get(`roles/:id/participants`, ``, authWallet, getRoleParticipants)
+	get(`votings`, ``, authWallet, getVotings)
 	get(`config/:option`, ``, getConfigOption)
 	get("ecosystemname", "?id:int64", getEcosystemName)
+	get(`fullnodes`, ``, getFullNodes)
+	get(`txinfo/limits`, ``, getTxLimits)
+	get(`usage`, `?from ?to:int64`, authWallet, getUsage)
+	get(`export/table/:name`, `?format:string`, authWallet, exportTable)
+	get(`qrcode`, `wallet:string,?ecosystem ?size:int64,?amount ?comment:string`, getQRCode)
 	post(`content/source/:name`, ``, authWallet, getSource)
 	post(`content/page/:name`, `?lang:string`, authWallet, getPage)
 	post(`content/menu/:name`, `?lang:string`, authWallet, getMenu)
 	post(`content/hash/:name`, ``, getPageHash)
 	post(`login`, `?pubkey signature:hex,?key_id ?mobile:string,?ecosystem ?expire ?role_id:int64`, login)
-	post(`prepare/:name`, `?token_ecosystem:int64,?max_sum ?payover:string`, authWallet, contractHandlers.prepareContract)
+	post(`prepare/:name`, `?token_ecosystem ?tx_expiration ?nonce ?threshold ?sign_version:int64,?max_sum ?payover ?signers:string`, authWallet, contractHandlers.prepareContract)
 	post(`prepareMultiple`, `data:string`, authWallet, contractHandlers.prepareMultipleContract)
 	post(`txstatusMultiple`, `data:string`, authWallet, txstatusMulti)
-	post(`contract/:request_id`, `?pubkey signature:hex, time:string, ?token_ecosystem:int64,?max_sum ?payover:string`, authWallet, blockchainUpdatingState, contractHandlers.contract)
+	// contract/format is dispatched by the handler of contract/:request_id because the router
+	// doesn't allow the static path and the parameter in the same segment
+	sendContract := DefaultHandler(`POST`, `contract/:request_id`, processParams(`?pubkey ?signature:hex, time ?signers ?signatures:string, ?token_ecosystem ?tx_expiration ?nonce ?threshold ?sign_version:int64,?max_sum ?payover ?idempotency_key:string`),
+		blockchainUpdatingState, authWallet, blockchainUpdatingState, contractHandlers.contract)
+	formatSource := DefaultHandler(`POST`, `contract/format`, processParams(`source:string`),
+		blockchainUpdatingState, authWallet, formatContract)
+	route.Handle(`POST`, consts.ApiPath+`contract/:request_id`, func(w http.ResponseWriter, r *http.Request, ps hr.Params) {
+		if ps.ByName(`request_id`) == `format` {
+			formatSource(w, r, ps)
+			return
+		}
+		sendContract(w, r, ps)
+	})
 	post(`contractMultiple/:request_id`, `data:string`, authWallet, blockchainUpdatingState, contractHandlers.contractMulti)
 	post(`refresh`, `token:string,?expire:int64`, refresh)
 	post(`test/:name`, ``, getTest)
 	post(`content`, `template ?source:string`, jsonContent)
 	post(`updnotificator`, `ids:string`, updateNotificator)
+	post(`qrcode/parse`, `uri:string`, parseQRCode)
 	get(`ecosystemparam/:name`, `?ecosystem:int64`, authWallet, ecosystemParam)
-	methodRoute(route, `POST`, `node/:name`, `?token_ecosystem:int64,?max_sum ?payover:string`, contractHandlers.nodeContract)
+	methodRoute(route, `POST`, `node/:name`, `?token_ecosystem ?tx_expiration ?nonce ?sign_version:int64,?max_sum ?payover:string,?signature:hex,?time:int64`, contractHandlers.node)
 
 	if !conf.Config.IsSupportingVDE() {
 		get(`txstatus/:hash`, ``, authWallet, txstatus)
+		get(`txstatus/:hash/wait`, `?timeout:int64`, authWallet, txstatusWait)
 		get(`txstatusMultiple`, `data:string`, authWallet, txstatusMulti)
 		get(`appparam/:appid/:name`, `?ecosystem:int64`, authWallet, appParam)
 		get(`appparams/:appid`, `?ecosystem:int64,?names:string`, authWallet, appParams)
 		get(`history/:table/:id`, ``, authWallet, getHistory)
+		get(`rowhistory/:table/:id`, `block:int64`, authWallet, getRowHistory)
 		get(`balance/:wallet`, `?ecosystem:int64`, authWallet, balance)
 		get(`block/:id`, ``, getBlockInfo)
 		get(`maxblockid`, ``, getMaxBlockID)
+		get(`rawblock/:id`, `?format:string`, getRawBlock)
+		get(`blockschema`, ``, getBlockSchema)
 		get("blocks", "block_id ?count:int64", getBlocksTxInfo)
 		get(`ecosystemparams`, `?ecosystem:int64,?names:string`, authWallet, ecosystemParams)
 		get(`systemparams`, `?names:string`, authWallet, systemParams)
 		get(`ecosystems`, ``, authWallet, ecosystems)
+		get(`events/:ecosystem`, `?from_block ?limit:int64,?name:string`, getEvents)
+		get(`import/progress`, `?hashes:string`, authWallet, getImportProgress)
+		post(`import/split`, ``, authWallet, importSplit)
+	}
+	if conf.Config.IsSupportingVDE() {
+		get(`vde/usage`, `?app_id:int64`, authWallet, getVDEUsage)
+	}
+	if conf.Config.IsSupportingVDE() && conf.Config.VDEDebug {
+		if script.TraceEnabled {
+			post(`vde/debug/:contract`, ``, authWallet, contractHandlers.debugContract)
+		} else {
+			log.WithFields(log.Fields{"type": consts.ConfigError}).Warn("debug API of VDE requires vmtrace build tag")
+		}
 	}
 }
 
@@ -35,13 +35,24 @@ func row(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entr
 	if len(data.params[`columns`].(string)) > 0 {
 		cols = converter.EscapeName(data.params[`columns`].(string))
 	}
+	where, err := deletedFilter(w, data, logger)
+	if err != nil {
+		return err
+	}
+	if len(where) > 0 {
+		where = ` AND ` + where
+	}
 	table := converter.EscapeName(getPrefix(data) + `_` + data.params[`name`].(string))
-	row, err := model.GetOneRow(`SELECT `+cols+` FROM `+table+` WHERE id = ?`, data.params[`id`].(string)).String()
+	rows, err := model.GetRows(nil, `SELECT `+cols+` FROM `+table+` WHERE id = ?`+where, 1, data.params[`id`].(string))
 	if err != nil {
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": data.params["name"].(string), "id": data.params["id"].(string)}).Error("getting one row")
 		return errorAPI(w, `E_QUERY`, http.StatusInternalServerError)
 	}
 
+	row := make(map[string]string)
+	if len(rows) > 0 {
+		row = rows[0]
+	}
 	data.result = &rowResult{Value: row}
 	return
 }
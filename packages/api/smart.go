@@ -18,7 +18,6 @@ package api
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"regexp"
 	"strings"
@@ -28,6 +27,7 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/model"
 	"github.com/GenesisKernel/go-genesis/packages/script"
 	"github.com/GenesisKernel/go-genesis/packages/smart"
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -55,10 +55,10 @@ type EncryptKey struct {
 	Error     string `json:"error"`
 }
 
-func validateSmartContractJSON(r *http.Request, data *apiData, cntname string, params map[string]string) (contract *smart.Contract, parerr interface{}, err error) {
+func validateSmartContractJSON(r *http.Request, data *apiData, cntname string, params map[string]string) (contract *smart.Contract, err error) {
 	contract = smart.VMGetContract(data.vm, cntname, uint32(data.ecosystemId))
 	if contract == nil {
-		return nil, cntname, fmt.Errorf(`E_CONTRACT`)
+		return nil, newError(`E_CONTRACT`, cntname)
 	}
 	if contract.Block.Info.(*script.ContractInfo).Tx != nil {
 		for _, fitem := range *(*contract).Block.Info.(*script.ContractInfo).Tx {
@@ -67,6 +67,10 @@ func validateSmartContractJSON(r *http.Request, data *apiData, cntname string, p
 			} else {
 				var val string
 				val = strings.TrimSpace(params[fitem.Name])
+				if def, ok := fitem.Default(); ok && len(val) == 0 {
+					val = def
+					params[fitem.Name] = def
+				}
 				if fitem.Type.String() == `[]interface {}` {
 					count := params[fitem.Name+`[]`]
 					if converter.StrToInt(count) > 0 || len(val) > 0 {
@@ -76,14 +80,14 @@ func validateSmartContractJSON(r *http.Request, data *apiData, cntname string, p
 				}
 				if len(val) == 0 && !strings.Contains(fitem.Tags, `optional`) {
 					log.WithFields(log.Fields{"type": consts.EmptyObject, "item_name": fitem.Name}).Error("route item is empty")
-					err = fmt.Errorf(`%s is empty`, fitem.Name)
+					err = newError(`E_EMPTYFIELD`, fitem.Name)
 					break
 				}
 				if strings.Contains(fitem.Tags, `address`) {
 					addr := converter.StringToAddress(val)
 					if addr == 0 {
 						log.WithFields(log.Fields{"type": consts.ConversionError, "value": val}).Error("converting string to address")
-						err = fmt.Errorf(`Address %s is not valid`, val)
+						err = newError(`E_INVALIDADDRESS`, val)
 						break
 					}
 				}
@@ -91,7 +95,7 @@ func validateSmartContractJSON(r *http.Request, data *apiData, cntname string, p
 					re := regexp.MustCompile(`^\d+([\.\,]\d+)?$`)
 					if !re.Match([]byte(val)) {
 						log.WithFields(log.Fields{"type": consts.InvalidObject, "value": val}).Error("The value of money is not valid")
-						err = fmt.Errorf(`The value of money %s is not valid`, val)
+						err = newError(`E_INVALIDMONEY`, val)
 						break
 					}
 				}
@@ -101,10 +105,19 @@ func validateSmartContractJSON(r *http.Request, data *apiData, cntname string, p
 	return
 }
 
-func validateSmartContract(r *http.Request, data *apiData, result *prepareResult, cntname string) (contract *smart.Contract, parerr interface{}, err error) {
+// formValue returns the value of the field or the default value if the field is omitted
+func formValue(r *http.Request, fitem *script.FieldInfo) string {
+	val := strings.TrimSpace(r.FormValue(fitem.Name))
+	if def, ok := fitem.Default(); ok && len(val) == 0 {
+		return def
+	}
+	return val
+}
+
+func validateSmartContract(r *http.Request, data *apiData, result *prepareResult, cntname string) (contract *smart.Contract, err error) {
 	contract = smart.VMGetContract(data.vm, cntname, uint32(data.ecosystemId))
 	if contract == nil {
-		return nil, cntname, fmt.Errorf(`E_CONTRACT`)
+		return nil, newError(`E_CONTRACT`, cntname)
 	}
 
 	if contract.Block.Info.(*script.ContractInfo).Tx != nil {
@@ -123,7 +136,7 @@ func validateSmartContract(r *http.Request, data *apiData, result *prepareResult
 					}
 					if !found {
 						log.WithFields(log.Fields{"type": consts.NotFound, "signature": ret[1]}).Error("unknown signature")
-						return contract, ret[1], fmt.Errorf(apiErrors["E_UNKNOWNSIGN"])
+						return contract, newError(`E_UNKNOWNSIGN`)
 					}
 					var sign TxSignJSON
 					err = json.Unmarshal([]byte(signature.Value), &sign)
@@ -131,17 +144,19 @@ func validateSmartContract(r *http.Request, data *apiData, result *prepareResult
 						log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling sign from json")
 						break
 					}
-					sign.ForSign = fmt.Sprintf(`%s,%d`, (*result).Time, uint64(data.keyId))
-					for _, isign := range sign.Params {
-						sign.ForSign += fmt.Sprintf(`,%v`, strings.TrimSpace(r.FormValue(isign.Param)))
+					names := make([]string, len(sign.Params))
+					values := make([]string, len(sign.Params))
+					for i, isign := range sign.Params {
+						names[i], values[i] = isign.Param, strings.TrimSpace(r.FormValue(isign.Param))
 					}
+					sign.ForSign = tx.ParamsForSign(signVersion(data), (*result).Time, data.keyId, names, values)
 					sign.Field = fitem.Name
 					(*result).Signs = append((*result).Signs, sign)
 				}
 			} else {
 				var val string
 
-				val = strings.TrimSpace(r.FormValue(fitem.Name))
+				val = formValue(r, fitem)
 				if fitem.Type.String() == `[]interface {}` {
 					count := r.FormValue(fitem.Name + `[]`)
 					if converter.StrToInt(count) > 0 || len(val) > 0 {
@@ -152,14 +167,14 @@ func validateSmartContract(r *http.Request, data *apiData, result *prepareResult
 				if len(val) == 0 && !strings.Contains(fitem.Tags, `optional`) &&
 					!strings.Contains(fitem.Tags, `signature`) {
 					log.WithFields(log.Fields{"type": consts.EmptyObject, "item_name": fitem.Name}).Error("route item is empty")
-					err = fmt.Errorf(`%s is empty`, fitem.Name)
+					err = newError(`E_EMPTYFIELD`, fitem.Name)
 					break
 				}
 				if strings.Contains(fitem.Tags, `address`) {
 					addr := converter.StringToAddress(val)
 					if addr == 0 {
 						log.WithFields(log.Fields{"type": consts.ConversionError, "value": val}).Error("converting string to address")
-						err = fmt.Errorf(`Address %s is not valid`, val)
+						err = newError(`E_INVALIDADDRESS`, val)
 						break
 					}
 				}
@@ -167,7 +182,7 @@ func validateSmartContract(r *http.Request, data *apiData, result *prepareResult
 					re := regexp.MustCompile(`^\d+([\.\,]\d+)?$`)
 					if !re.Match([]byte(val)) {
 						log.WithFields(log.Fields{"type": consts.InvalidObject, "value": val}).Error("The value of money is not valid")
-						err = fmt.Errorf(`The value of money %s is not valid`, val)
+						err = newError(`E_INVALIDMONEY`, val)
 						break
 					}
 				}
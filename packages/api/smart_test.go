@@ -165,7 +165,9 @@ func TestPage(t *testing.T) {
 	err = postTx(`EditParameter`, &form)
 	assert.Equal(t, `{"type":"panic","error":"Item 7123 has not been found"}`, cutErr(err))
 
-	form = url.Values{"Id": {`16`}, "Value": {`Changed Param Value`},
+	var param paramValue
+	assert.NoError(t, sendGet(`ecosystemparam/`+name, nil, &param))
+	form = url.Values{"Id": {param.ID}, "Value": {`Changed Param Value`},
 		"Conditions": {`ContractConditions("MainCondition")`}}
 	assert.NoError(t, postTx(`EditParameter`, &form))
 
@@ -534,11 +536,15 @@ func TestDBMetrics(t *testing.T) {
 			contract ` + contract + ` {
 				data {
 					Metric string
+					Aggregate string "optional"
 				}
 				conditions {}
 				action {
+					if Size($Aggregate) == 0 {
+						$Aggregate = "max"
+					}
 					UpdateMetrics()
-					$result = One(DBSelectMetrics($Metric, "1 days", "max"), "value")
+					$result = One(DBSelectMetrics($Metric, "1 days", $Aggregate), "value")
 				}
 			}`},
 		"Conditions": {"true"},
@@ -567,6 +573,11 @@ func TestDBMetrics(t *testing.T) {
 	assert.Equal(t, 1, metricValue("ecosystem_pages")-ecosystemPages)
 	assert.True(t, metricValue("ecosystem_tx") > ecosystemTx)
 
+	// the growth of the transactions in the time bucket isn't negative
+	_, result, err := postTxResult(contract, &url.Values{"Metric": {"ecosystem_tx"}, "Aggregate": {"rate"}})
+	assert.NoError(t, err)
+	assert.True(t, converter.StrToInt(result) > 0)
+
 }
 
 func TestPartitialEdit(t *testing.T) {
@@ -724,55 +735,15 @@ func TestDelayedContracts(t *testing.T) {
 	}
 	err = postTx("EditDelayedContract", &form)
 	assert.NoError(t, err)
-}
 
-func TestJSON(t *testing.T) {
-	assert.NoError(t, keyLogin(1))
-
-	contract := randName("JSONEncode")
-	assert.NoError(t, postTx("NewContract", &url.Values{
-		"Value": {`contract ` + contract + ` {
-			action {
-				var a array, m map
-				m["k1"] = 1
-				m["k2"] = 2
-				a[0] = m
-				a[1] = m
-
-				info JSONEncode(a)
-			}
-		}`}, "ApplicationId": {"1"},
-		"Conditions": {"true"},
-	}))
-	assert.EqualError(t, postTx(contract, &url.Values{}), `{"type":"info","error":"[{\"k1\":1,\"k2\":2},{\"k1\":1,\"k2\":2}]"}`)
-
-	contract = randName("JSONDecode")
-	assert.NoError(t, postTx("NewContract", &url.Values{
-		"Value": {`contract ` + contract + ` {
-			data {
-				Input string
-			}
-			action {
-				info Sprintf("%#v", JSONDecode($Input))
-			}
-		}`}, "ApplicationId": {"1"},
+	form = url.Values{
+		"Contract":   {"MainCondition"},
+		"EveryBlock": {"0"},
+		"Time":       {"1"},
 		"Conditions": {"true"},
-	}))
-
-	cases := []struct {
-		source string
-		result string
-	}{
-		{`"test"`, `{"type":"info","error":"\"test\""}`},
-		{`["test"]`, `{"type":"info","error":"[]interface {}{\"test\"}"}`},
-		{`{"test":1}`, `{"type":"info","error":"map[string]interface {}{\"test\":1}"}`},
-		{`[{"test":1}]`, `{"type":"info","error":"[]interface {}{map[string]interface {}{\"test\":1}}"}`},
-		{`{"test":1`, `{"type":"panic","error":"unexpected end of JSON input"}`},
-	}
-
-	for _, v := range cases {
-		assert.EqualError(t, postTx(contract, &url.Values{"Input": {v.source}}), v.result)
 	}
+	err = postTx("NewDelayedContract", &form)
+	assert.EqualError(t, err, `{"type":"error","error":"The time must be greater than the current time"}`)
 }
 
 func TestBytesToString(t *testing.T) {
@@ -851,40 +822,6 @@ func TestMemoryLimit(t *testing.T) {
 	assert.EqualError(t, postTx(contract, &url.Values{}), `{"type":"panic","error":"Memory limit exceeded"}`)
 }
 
-func TestStack(t *testing.T) {
-	assert.NoError(t, keyLogin(1))
-
-	parent := randName("Parent")
-	child := randName("Child")
-
-	assert.NoError(t, postTx("NewContract", &url.Values{
-		"Value": {`contract ` + child + ` {
-			action {
-				$result = $stack
-			}
-		}`},
-		"ApplicationId": {"1"},
-		"Conditions":    {"true"},
-	}))
-
-	assert.NoError(t, postTx("NewContract", &url.Values{
-		"Value": {`contract ` + parent + ` {
-			action {
-				var arr array
-				arr[0] = $stack
-				arr[1] = ` + child + `()
-				$result = arr
-			}
-		}`},
-		"ApplicationId": {"1"},
-		"Conditions":    {"true"},
-	}))
-
-	_, res, err := postTxResult(parent, &url.Values{})
-	assert.NoError(t, err)
-	assert.Equal(t, fmt.Sprintf("[[@1%s] [@1%[1]s @1%s]]", parent, child), res)
-}
-
 func TestPageHistory(t *testing.T) {
 	assert.NoError(t, keyLogin(1))
 
@@ -0,0 +1,82 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultSyncLimit = 100
+	maxSyncLimit     = 1000
+)
+
+type syncResult struct {
+	VDE    bool                `json:"vde"`
+	Cursor int64               `json:"cursor"`
+	List   []map[string]string `json:"list"`
+}
+
+// getSync returns the rows of the table changed after the cursor. The cursor of VDE is the id of the row,
+// the cursor of the blockchain is the id of the block, so the updated rows are returned too
+func getSync(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	table := getPrefix(data) + `_` + strings.ToLower(data.ParamString(`name`))
+	if strings.Contains(table, `"`) || !model.IsTable(table) {
+		return errorAPI(w, `E_TABLENOTFOUND`, http.StatusBadRequest, data.ParamString(`name`))
+	}
+	limit := int(data.params[`limit`].(int64))
+	if limit <= 0 {
+		limit = defaultSyncLimit
+	} else if limit > maxSyncLimit {
+		limit = maxSyncLimit
+	}
+	from := data.params[`from`].(int64)
+	result := &syncResult{VDE: data.vde, Cursor: from, List: []map[string]string{}}
+
+	var (
+		list []map[string]string
+		err  error
+	)
+	if data.vde {
+		list, err = model.GetAllTransaction(nil, `SELECT * FROM "`+table+`" WHERE id > ? ORDER BY id`, limit, from)
+		if len(list) > 0 {
+			result.Cursor, err = strconv.ParseInt(list[len(list)-1][`id`], 10, 64)
+		}
+	} else {
+		var ids []string
+		ids, result.Cursor, err = model.GetChangedIDs(table, from, limit)
+		if err == nil && len(ids) > 0 {
+			list, err = model.GetAllTransaction(nil, `SELECT * FROM "`+table+`" WHERE id IN (?) ORDER BY id`, -1, ids)
+		}
+	}
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table}).Error("getting changed rows")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	if list != nil {
+		result.List = list
+	}
+	data.result = result
+	return nil
+}
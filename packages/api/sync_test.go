@@ -0,0 +1,75 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSync(t *testing.T) {
+	require.NoError(t, keyLogin(1))
+
+	name := randName(`sync`)
+	require.NoError(t, postTx(`NewTable`, &url.Values{"Name": {name}, "Columns": {`[{"name":"title",
+		"type":"varchar", "index": "0", "conditions":{"update":"true", "read":"true"}}]`}, "ApplicationId": {"1"},
+		"Permissions": {`{"insert": "true", "update" : "true", "new_column": "true"}`}}))
+	require.NoError(t, postTx(`NewContract`, &url.Values{"Value": {`contract ` + name + ` {
+		data {
+			Id int "optional"
+			Title string
+		}
+		action {
+			if $Id == 0 {
+				DBInsert("` + name + `", "title", $Title)
+			} else {
+				DBUpdate("` + name + `", $Id, "title", $Title)
+			}
+		}
+	}`}, "ApplicationId": {"1"}, "Conditions": {"true"}}))
+	require.NoError(t, postTx(name, &url.Values{"Title": {"first"}}))
+	require.NoError(t, postTx(name, &url.Values{"Title": {"second"}}))
+
+	var ret syncResult
+	require.NoError(t, sendGet(`sync/`+name, nil, &ret))
+	assert.False(t, ret.VDE)
+	if assert.Len(t, ret.List, 2) {
+		assert.Equal(t, `first`, ret.List[0][`title`])
+		assert.Equal(t, `second`, ret.List[1][`title`])
+	}
+	cursor := ret.Cursor
+	assert.True(t, cursor > 0)
+
+	require.NoError(t, sendGet(fmt.Sprintf(`sync/%s?from=%d`, name, cursor), nil, &ret))
+	assert.Len(t, ret.List, 0)
+	assert.Equal(t, cursor, ret.Cursor)
+
+	// the updated row is returned after the cursor
+	require.NoError(t, postTx(name, &url.Values{"Id": {`1`}, "Title": {"changed"}}))
+	require.NoError(t, sendGet(fmt.Sprintf(`sync/%s?from=%d`, name, cursor), nil, &ret))
+	if assert.Len(t, ret.List, 1) {
+		assert.Equal(t, `1`, ret.List[0][`id`])
+		assert.Equal(t, `changed`, ret.List[0][`title`])
+	}
+	assert.True(t, ret.Cursor > cursor)
+
+	assert.Error(t, sendGet(`sync/`+randName(`unknown`), nil, &ret))
+}
@@ -20,6 +20,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/model"
 
@@ -48,8 +49,15 @@ func systemParams(w http.ResponseWriter, r *http.Request, data *apiData, logger
 		if names != nil && !names[item.Name] {
 			continue
 		}
-		result.List = append(result.List, paramValue{Name: item.Name, Value: item.Value,
-			Conditions: item.Conditions})
+		param := paramValue{Name: item.Name, Value: item.Value, Conditions: item.Conditions}
+		// the value is the active value, the scheduled value is returned as pending
+		if syspar.HasSys(item.Name) {
+			param.Value = syspar.SysString(item.Name)
+		}
+		if pending, ok := syspar.GetPending(item.Name); ok {
+			param.PendingValue, param.ActivationBlock = pending.Value, pending.ActivationBlock
+		}
+		result.List = append(result.List, param)
 	}
 	data.result = &result
 	return
@@ -20,18 +20,25 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
 	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
 
 	log "github.com/sirupsen/logrus"
 )
 
 type columnInfo struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
-	Perm string `json:"perm"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Perm      string `json:"perm"`
+	Index     bool   `json:"index"`
+	Unique    bool   `json:"unique"`
+	CanUpdate bool   `json:"can_update"`
+	CanRead   bool   `json:"can_read"`
 }
 
 type tableResult struct {
@@ -44,6 +51,9 @@ type tableResult struct {
 	Conditions string       `json:"conditions"`
 	AppID      string       `json:"app_id"`
 	Columns    []columnInfo `json:"columns"`
+	CanInsert  bool         `json:"can_insert"`
+	CanUpdate  bool         `json:"can_update"`
+	Count      string       `json:"count"`
 }
 
 func table(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) (err error) {
@@ -71,15 +81,27 @@ func table(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.En
 			logger.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("Unmarshalling table columns to json")
 			return errorAPI(w, err.Error(), http.StatusInternalServerError)
 		}
+		tblname := prefix + `_` + table.Name
+		indexes, err := model.GetColumnIndexes(tblname)
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting column indexes from db")
+			return errorAPI(w, err.Error(), http.StatusInternalServerError)
+		}
 		columns := make([]columnInfo, 0)
 		for key, value := range cols {
-			colType, err := model.GetColumnType(prefix+`_`+data.params[`name`].(string), key)
+			colType, err := model.GetColumnType(tblname, key)
 			if err != nil {
 				logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting column type from db")
 				return errorAPI(w, err.Error(), http.StatusInternalServerError)
 			}
+			unique, index := indexes[key]
 			columns = append(columns, columnInfo{Name: key, Perm: value,
-				Type: colType})
+				Type: colType, Index: index, Unique: unique})
+		}
+		count, err := model.GetRecordsCountTx(nil, tblname)
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": tblname}).Error("Getting table records count")
+			return errorAPI(w, err.Error(), http.StatusInternalServerError)
 		}
 		result = tableResult{
 			Name:       table.Name,
@@ -91,6 +113,11 @@ func table(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.En
 			Conditions: table.Conditions,
 			AppID:      converter.Int64ToStr(table.AppID),
 			Columns:    columns,
+			Count:      converter.Int64ToStr(count),
+		}
+		if err = tableAccess(data, tblname, &result); err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("checking table access")
+			return errorAPI(w, err.Error(), http.StatusInternalServerError)
 		}
 	} else {
 		return errorAPI(w, `E_TABLENOTFOUND`, http.StatusBadRequest, data.params[`name`].(string))
@@ -98,3 +125,43 @@ func table(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.En
 	data.result = &result
 	return
 }
+
+// tableAccess evaluates the permissions of the table for the key of the request the same way as
+// the transaction does. The changes made by the conditions are rolled back
+func tableAccess(data *apiData, tblname string, result *tableResult) error {
+	dbTx, err := model.StartTransaction()
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+
+	sc := requestContract(data, dbTx)
+	_, err = sc.AccessTablePerm(tblname, `insert`)
+	result.CanInsert = err == nil
+	_, err = sc.AccessTablePerm(tblname, `update`)
+	result.CanUpdate = err == nil
+	for i, col := range result.Columns {
+		columns := []string{col.Name}
+		result.Columns[i].CanUpdate = sc.AccessColumns(tblname, &columns, true) == nil
+		columns = []string{col.Name}
+		result.Columns[i].CanRead = sc.AccessColumns(tblname, &columns, false) == nil
+	}
+	return nil
+}
+
+// requestContract returns the contract which checks the permissions for the key of the request
+func requestContract(data *apiData, dbTx *model.DbTransaction) *smart.SmartContract {
+	return &smart.SmartContract{
+		VM:            data.vm,
+		DbTransaction: dbTx,
+		TxSmart: tx.SmartContract{
+			Header: tx.Header{
+				Time:        time.Now().Unix(),
+				EcosystemID: data.ecosystemId,
+				KeyID:       data.keyId,
+				RoleID:      data.roleId,
+				NetworkID:   consts.NETWORK_ID,
+			},
+		},
+	}
+}
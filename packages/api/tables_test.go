@@ -325,3 +325,89 @@ func TestTableDesc(t *testing.T) {
 		return
 	}
 }
+
+func TestAccessCache(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	name := randName(`acc`)
+	columns := `[{"name":"num","type":"number","index":"0","conditions":{"update":"true","read":"true"}}]`
+	// every evaluation of the insert permission of the table adds the row to the log table
+	assert.NoError(t, postTx("NewTable", &url.Values{"Name": {name + `log`}, "Columns": {columns},
+		"ApplicationId": {"1"}, "Permissions": {`{"insert":"true","update":"true","new_column":"true"}`}}))
+	assert.NoError(t, postTx("NewContract", &url.Values{"Value": {`contract ` + name + `Cond {
+		conditions {
+			DBInsert("` + name + `log", "num", 1)
+		}
+	}`}, "ApplicationId": {"1"}, "Conditions": {"true"}}))
+	assert.NoError(t, postTx("NewTable", &url.Values{"Name": {name}, "Columns": {columns}, "ApplicationId": {"1"},
+		"Permissions": {`{"insert":"ContractConditions(\"` + name + `Cond\")","update":"true","new_column":"true"}`}}))
+
+	assert.NoError(t, postTx("NewContract", &url.Values{"Value": {`contract ` + name + `Loop {
+		action {
+			var i int
+			while i < 500 {
+				DBInsert("` + name + `", "num", i)
+				i = i + 1
+			}
+		}
+	}`}, "ApplicationId": {"1"}, "Conditions": {"true"}}))
+	assert.NoError(t, postTx(name+`Loop`, &url.Values{}))
+
+	var ret listResult
+	assert.NoError(t, sendGet(`list/`+name+`log`, nil, &ret))
+	assert.Equal(t, `1`, ret.Count)
+	assert.NoError(t, sendGet(`list/`+name, nil, &ret))
+	assert.Equal(t, `500`, ret.Count)
+
+	// the permission which is changed by the transaction is checked again
+	assert.NoError(t, postTx("NewContract", &url.Values{"Value": {`contract ` + name + `Change {
+		action {
+			DBInsert("` + name + `", "num", 1)
+			var pars map
+			pars["Name"] = "` + name + `"
+			pars["InsertPerm"] = "false"
+			pars["UpdatePerm"] = "true"
+			pars["NewColumnPerm"] = "true"
+			CallContract("EditTable", pars)
+			DBInsert("` + name + `", "num", 2)
+		}
+	}`}, "ApplicationId": {"1"}, "Conditions": {"true"}}))
+	err := postTx(name+`Change`, &url.Values{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `Access denied`)
+	}
+}
+
+func TestTableSchema(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	name := randName(`tbl`)
+	assert.NoError(t, postTx(`NewTable`, &url.Values{"Name": {name}, "Columns": {`[
+		{"name":"title","type":"varchar", "index": "1", "conditions":{"update":"true", "read":"true"}},
+		{"name":"secret","type":"number", "index": "0", "conditions":{"update":"false", "read":"false"}}]`},
+		"ApplicationId": {"1"},
+		"Permissions":   {`{"insert": "true", "update" : "false", "new_column": "true"}`}}))
+
+	var ret tableResult
+	assert.NoError(t, sendGet(`table/`+name, nil, &ret))
+	assert.True(t, ret.CanInsert)
+	assert.False(t, ret.CanUpdate)
+	assert.Equal(t, `0`, ret.Count)
+	columns := make(map[string]columnInfo)
+	for _, col := range ret.Columns {
+		columns[col.Name] = col
+	}
+	if assert.Contains(t, columns, `title`) {
+		assert.Equal(t, `varchar`, columns[`title`].Type)
+		assert.True(t, columns[`title`].Index)
+		assert.False(t, columns[`title`].Unique)
+		assert.True(t, columns[`title`].CanUpdate)
+		assert.True(t, columns[`title`].CanRead)
+	}
+	if assert.Contains(t, columns, `secret`) {
+		assert.Equal(t, `number`, columns[`secret`].Type)
+		assert.False(t, columns[`secret`].Index)
+		assert.False(t, columns[`secret`].CanUpdate)
+		assert.False(t, columns[`secret`].CanRead)
+	}
+}
@@ -106,7 +106,7 @@ func TestAPI(t *testing.T) {
 		}
 	}
 	err = sendPost(`content/page/mypage`, &url.Values{}, &ret)
-	if err != nil && err.Error() != `404 {"error": "E_NOTFOUND", "msg": "Page not found" }` {
+	if err != nil && err.Error() != `404 {"error": "E_NOTFOUND", "msg": "Page not found" , "params": []}` {
 		t.Error(err)
 		return
 	}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type txLimitsResult struct {
+	MaxTxSize      int64 `json:"max_tx_size"`
+	MaxForsignSize int64 `json:"max_forsign_size"`
+	MaxTxFuel      int64 `json:"max_fuel_tx"`
+}
+
+// getTxLimits returns the limits of the transaction, so the client can check the size of the transaction
+// before it's signed
+func getTxLimits(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	data.result = &txLimitsResult{
+		MaxTxSize:      syspar.GetMaxTxSize(),
+		MaxForsignSize: syspar.GetMaxForsignSize(),
+		MaxTxFuel:      syspar.GetMaxTxFuel(),
+	}
+	return nil
+}
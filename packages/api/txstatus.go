@@ -20,17 +20,34 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
 	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/notificator"
+	"github.com/GenesisKernel/go-genesis/packages/script"
 
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	defaultTxWaitTimeout = 30
+	maxTxWaitTimeout     = 120
+)
+
 type txstatusError struct {
-	Type  string `json:"type,omitempty"`
-	Error string `json:"error,omitempty"`
+	Type     string   `json:"type,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Contract string   `json:"contract,omitempty"`
+	Func     string   `json:"func,omitempty"`
+	Line     uint32   `json:"line,omitempty"`
+	Code     string   `json:"code,omitempty"`
+	Params   []string `json:"params,omitempty"`
+	// Reset is the time when the exceeded quota of the application of VDE is reset
+	Reset int64 `json:"reset,omitempty"`
+	// Compile is the list of the errors with the positions if the source code of the contract can't be compiled
+	Compile []*script.CompileError `json:"compile,omitempty"`
 }
 
 type txstatusResult struct {
@@ -66,6 +83,11 @@ func getTxStatus(hash string, w http.ResponseWriter, logger *log.Entry) (*txstat
 				Error: ts.Error,
 			}
 		}
+		if code, ok := txstatusCodes[status.Message.Type]; ok {
+			status.Message.Code = code
+			status.Message.Params = []string{status.Message.Error}
+			status.Message.Error = errorText(w, code, []interface{}{status.Message.Error})
+		}
 	}
 	return &status, nil
 }
@@ -83,6 +105,41 @@ func txstatus(w http.ResponseWriter, r *http.Request, data *apiData, logger *log
 	return nil
 }
 
+// txstatusWait waits until the transaction is included in the block or fails and returns its status.
+// The last known status is returned after the timeout in seconds, it has the empty blockid and errmsg
+func txstatusWait(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	hash := data.params[`hash`].(string)
+	timeout := data.params[`timeout`].(int64)
+	if timeout <= 0 {
+		timeout = defaultTxWaitTimeout
+	} else if timeout > maxTxWaitTimeout {
+		timeout = maxTxWaitTimeout
+	}
+	// the subscription is made before the status is read, so the change between them isn't lost
+	changed, cancel := notificator.SubscribeTxStatus([]byte(converter.HexToBin(hash)))
+	defer cancel()
+	timer := time.NewTimer(time.Duration(timeout) * time.Second)
+	defer timer.Stop()
+
+	for {
+		status, err := getTxStatus(hash, w, logger)
+		if err != nil {
+			return err
+		}
+		data.result = status
+		if len(status.BlockID) > 0 || status.Message != nil {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return nil
+		case <-r.Context().Done():
+			return nil
+		}
+	}
+}
+
 func txstatusMulti(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
 	result := &multiTxStatusResult{}
 	result.Results = map[string]*txstatusResult{}
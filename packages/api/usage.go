@@ -0,0 +1,137 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/usage"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultUsageDays is the number of the days of the statistics which is returned by default
+const defaultUsageDays = 30
+
+type usageResult struct {
+	Ecosystem int64            `json:"ecosystem"`
+	Quota     int64            `json:"quota"`
+	Used      int64            `json:"used"`
+	Reset     int64            `json:"reset"`
+	List      []model.APIUsage `json:"list"`
+}
+
+// routeClass returns the first part of the route pattern, the calls are counted by it
+func routeClass(pattern string) string {
+	return strings.SplitN(pattern, `/`, 2)[0]
+}
+
+// countUsage counts the call of the route by the ecosystem of the token and rejects the calls
+// of the ecosystems which have exceeded the daily quota. The calls without the token are anonymous
+func countUsage(pattern string) apiHandle {
+	route := routeClass(pattern)
+	return func(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+		var ecosystem int64
+		if data.keyId != 0 {
+			ecosystem = data.ecosystemId
+		}
+		if ok, reset := usage.Count(ecosystem, route, time.Now()); !ok {
+			logger.WithFields(log.Fields{"type": consts.AccessDenied, "ecosystem": ecosystem}).Warning("api quota is exceeded")
+			w.Header().Set("Retry-After", strconv.FormatInt(reset-time.Now().Unix(), 10))
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+			return errorAPI(w, `E_QUOTA`, http.StatusTooManyRequests, reset)
+		}
+		return nil
+	}
+}
+
+// getUsage returns the statistics of the API calls of the ecosystem to its founder.
+// The interval is defined by the unix times, the last 30 days are returned by default
+func getUsage(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	sp := &model.StateParameter{}
+	sp.SetTablePrefix(converter.Int64ToStr(data.ecosystemId))
+	if _, err := sp.Get(nil, `founder_account`); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting founder account")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	if founder, err := strconv.ParseInt(sp.Value, 10, 64); err != nil || founder != data.keyId {
+		logger.WithFields(log.Fields{"type": consts.AccessDenied, "error": fmt.Errorf(`Access denied`)}).Error("getting api usage")
+		return errorAPI(w, `E_PERMISSION`, http.StatusUnauthorized)
+	}
+
+	now := time.Now()
+	today := usage.Day(now)
+	to := today
+	if t := data.params[`to`].(int64); t > 0 {
+		to = usage.Day(time.Unix(t, 0))
+	}
+	from := to - (defaultUsageDays-1)*usage.DaySeconds
+	if f := data.params[`from`].(int64); f > 0 {
+		from = usage.Day(time.Unix(f, 0))
+	}
+	list, err := usage.Get(data.ecosystemId, from, to)
+	if err == nil && (from > today || to < today) {
+		var current []model.APIUsage
+		current, err = usage.Get(data.ecosystemId, today, today)
+		list = append(list, current...)
+	}
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting api usage")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	result := &usageResult{
+		Ecosystem: data.ecosystemId,
+		Quota:     usage.Quota(data.ecosystemId),
+		Reset:     today + usage.DaySeconds,
+		List:      []model.APIUsage{},
+	}
+	for _, item := range list {
+		if item.Day == today {
+			result.Used += item.Calls
+		}
+		if item.Day >= from && item.Day <= to {
+			result.List = append(result.List, item)
+		}
+	}
+	data.result = result
+	return nil
+}
+
+type vdeUsageResult struct {
+	List []usage.AppUsage `json:"list"`
+}
+
+// getVDEUsage returns the execution of the contracts of VDE by the application of app_id in the sliding
+// window and its quotas. The applications which have the executions in the window are returned by default
+func getVDEUsage(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	now := time.Now()
+	result := &vdeUsageResult{}
+	if appID := data.params[`app_id`].(int64); appID > 0 {
+		result.List = []usage.AppUsage{usage.GetApp(appID, now)}
+	} else {
+		result.List = usage.GetApps(now)
+	}
+	data.result = result
+	return nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsage(t *testing.T) {
+	require.NoError(t, keyLogin(1))
+
+	var before usageResult
+	require.NoError(t, sendGet(`usage`, nil, &before))
+	assert.Equal(t, int64(1), before.Ecosystem)
+	assert.True(t, before.Reset > 0)
+
+	var ret usageResult
+	require.NoError(t, sendGet(`usage`, nil, &ret))
+	assert.True(t, ret.Used > before.Used)
+	var found bool
+	for _, item := range ret.List {
+		if item.Route == `usage` && item.Day == ret.Reset-86400 {
+			found = item.Calls > 0
+		}
+	}
+	assert.True(t, found)
+}
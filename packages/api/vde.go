@@ -32,7 +32,6 @@ import (
 
 	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
-	"gopkg.in/vmihailenco/msgpack.v2"
 )
 
 type vdeCreateResult struct {
@@ -49,7 +48,7 @@ func vdeCreate(w http.ResponseWriter, r *http.Request, data *apiData, logger *lo
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("creating vde")
 		return errorAPI(w, err, http.StatusBadRequest)
 	}
-	if converter.StrToInt64(sp.Value) != data.keyId {
+	if founder, err := converter.StrToInt64E(sp.Value); err != nil || founder != data.keyId {
 		logger.WithFields(log.Fields{"type": consts.AccessDenied, "error": fmt.Errorf(`Access denied`)}).Error("creating vde")
 		return errorAPI(w, `E_PERMISSION`, http.StatusUnauthorized)
 	}
@@ -64,13 +63,13 @@ func vdeCreate(w http.ResponseWriter, r *http.Request, data *apiData, logger *lo
 
 // InitSmartContract is initializes smart contract
 func InitSmartContract(sc *smart.SmartContract, data []byte) error {
-	if err := msgpack.Unmarshal(data, &sc.TxSmart); err != nil {
+	if err := sc.TxSmart.Unmarshal(data); err != nil {
 		return err
 	}
 
 	sc.TxContract = smart.VMGetContractByID(smart.GetVM(), int32(sc.TxSmart.Type))
 	if sc.TxContract == nil {
-		return fmt.Errorf(`unknown contract %d`, sc.TxSmart.Type)
+		return newError(`E_UNKNOWNCONTRACT`, sc.TxSmart.Type)
 	}
 	forsign := sc.TxSmart.ForSign()
 
@@ -0,0 +1,227 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	debugTraceLimit = 5000 // the maximum count of the steps, the assignments and the calls of the trace
+	debugValueLimit = 256  // the maximum length of the value in the trace
+)
+
+type debugAssign struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type debugCall struct {
+	Name    string   `json:"name"`
+	Params  []string `json:"params"`
+	Results []string `json:"results"`
+	Error   string   `json:"error,omitempty"`
+}
+
+type debugStep struct {
+	Contract string        `json:"contract"`
+	Func     string        `json:"func"`
+	Line     uint32        `json:"line"`
+	Fuel     int64         `json:"fuel"`
+	Assigns  []debugAssign `json:"assigns,omitempty"`
+	Calls    []debugCall   `json:"calls,omitempty"`
+	cost     int64
+}
+
+type debugResult struct {
+	Result    string         `json:"result,omitempty"`
+	Message   *txstatusError `json:"errmsg,omitempty"`
+	Steps     []*debugStep   `json:"steps"`
+	Truncated bool           `json:"truncated,omitempty"`
+}
+
+// debugTrace implements script.Tracer, the trace is truncated after debugTraceLimit items
+type debugTrace struct {
+	result *debugResult
+	count  int
+}
+
+func (t *debugTrace) add() bool {
+	if t.count >= debugTraceLimit {
+		t.result.Truncated = true
+		return false
+	}
+	t.count++
+	return true
+}
+
+func (t *debugTrace) last() *debugStep {
+	if len(t.result.Steps) == 0 {
+		return nil
+	}
+	return t.result.Steps[len(t.result.Steps)-1]
+}
+
+func (t *debugTrace) Step(contract, function string, line uint32, cost int64) {
+	if t.add() {
+		t.result.Steps = append(t.result.Steps, &debugStep{Contract: contract, Func: function,
+			Line: line, cost: cost})
+	}
+}
+
+func (t *debugTrace) Assign(name string, value interface{}) {
+	if step := t.last(); step != nil && t.add() {
+		step.Assigns = append(step.Assigns, debugAssign{Name: name, Value: debugValue(value)})
+	}
+}
+
+func (t *debugTrace) Call(name string, params, results []interface{}, err error) {
+	step := t.last()
+	if step == nil || !t.add() {
+		return
+	}
+	call := debugCall{Name: name, Params: debugValues(params), Results: debugValues(results)}
+	if err != nil {
+		call.Error = debugValue(err.Error())
+	}
+	step.Calls = append(step.Calls, call)
+}
+
+// finish counts the fuel of the steps, remain is the fuel which is left after the execution
+func (t *debugTrace) finish(remain int64) {
+	for i, step := range t.result.Steps {
+		next := remain
+		if i+1 < len(t.result.Steps) {
+			next = t.result.Steps[i+1].cost
+		}
+		step.Fuel = step.cost - next
+	}
+}
+
+func debugValue(value interface{}) string {
+	out := fmt.Sprint(value)
+	if len(out) > debugValueLimit {
+		out = out[:debugValueLimit] + `...`
+	}
+	return out
+}
+
+func debugValues(values []interface{}) []string {
+	out := make([]string, len(values))
+	for i, value := range values {
+		out[i] = debugValue(value)
+	}
+	return out
+}
+
+// debugContract executes the contract of VDE with the parameters of the form and returns the trace
+// of the execution. The signature isn't required and all changes of the contract are rolled back
+func (h *contractHandlers) debugContract(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	contract, err := validateSmartContract(r, data, nil, data.ParamString(`contract`))
+	if err != nil {
+		return errorAPI(w, err, http.StatusBadRequest)
+	}
+	info := (*contract).Block.Info.(*script.ContractInfo)
+
+	key := &model.Key{}
+	key.SetTablePrefix(data.ecosystemId)
+	if _, err = key.Get(data.keyId); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("selecting public key from keys")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	if key.Deleted == 1 {
+		return errorAPI(w, `E_DELETEDKEY`, http.StatusForbidden)
+	}
+	if len(key.PublicKey) == 0 {
+		return errorAPI(w, `E_EMPTYPUBLIC`, http.StatusBadRequest)
+	}
+
+	req := h.requests.NewRequest(contract.Name)
+	idata := make([]byte, 0)
+	if info.Tx != nil {
+		if _, err = forsignFormData(w, r, data, logger, req, *info.Tx); err != nil {
+			return err
+		}
+		if idata, err = tx.EncodeParams(*info.Tx, req.AllValues(), req.ReadFile); err != nil {
+			return errorAPI(w, err, http.StatusBadRequest)
+		}
+	}
+	smartTx := tx.SmartContract{
+		Header: tx.Header{
+			Type:        int(info.ID),
+			Time:        req.Time.Unix(),
+			EcosystemID: data.ecosystemId,
+			KeyID:       data.keyId,
+			RoleID:      data.roleId,
+			NetworkID:   consts.NETWORK_ID,
+		},
+		RequestID: req.ID,
+		Data:      idata,
+	}
+	serializedData, err := smartTx.Marshal()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling smart contract")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	hash, err := crypto.Hash(serializedData)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("getting hash of contract data")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	// the key of the token is verified, so the signature of the transaction is skipped
+	sc := smart.SmartContract{VDE: true, TxHash: hash, VerifiedKey: key.PublicKey}
+	if err = InitSmartContract(&sc, serializedData); err != nil {
+		return errorAPI(w, err, http.StatusBadRequest)
+	}
+	if data.token != nil && data.token.Valid {
+		if auth, err := data.token.SignedString([]byte(jwtSecret)); err == nil {
+			sc.TxData[`auth_token`] = auth
+		}
+	}
+	if sc.DbTransaction, err = model.StartTransaction(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("starting transaction")
+		return errorAPI(w, err, http.StatusInternalServerError)
+	}
+	defer sc.DbTransaction.Rollback()
+
+	result := &debugResult{Steps: make([]*debugStep, 0)}
+	trace := &debugTrace{result: result}
+	sc.SetTracer(trace)
+	if result.Result, err = sc.CallContract(smart.CallInit | smart.CallCondition | smart.CallAction); err != nil {
+		if errResult := json.Unmarshal([]byte(err.Error()), &result.Message); errResult != nil {
+			result.Message = &txstatusError{Type: "panic", Error: err.Error()}
+		}
+	}
+	var remain int64
+	if sc.TxContract.Extend != nil {
+		remain, _ = (*sc.TxContract.Extend)[`txcost`].(int64)
+	}
+	trace.finish(remain)
+	data.result = result
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVDEDebug requires VDE which is compiled with the vmtrace tag and started with vdeDebug
+func TestVDEDebug(t *testing.T) {
+	require.NoError(t, keyLogin(1))
+
+	name := randName(`debug`)
+	require.NoError(t, postTx(`NewContract`, &url.Values{"Value": {`contract ` + name + ` {
+		data {
+			Par int
+		}
+		action {
+			var i int
+			i = $Par * 2
+			DBInsert("parameters", "name,value,conditions", "` + name + `", i, "true")
+			$result = i
+		}
+	}`}, "Conditions": {"true"}, "vde": {"true"}}))
+
+	var ret debugResult
+	require.NoError(t, sendPost(`vde/debug/`+name, &url.Values{"Par": {"21"}}, &ret))
+	assert.Nil(t, ret.Message)
+	assert.Equal(t, `42`, ret.Result)
+	assert.False(t, ret.Truncated)
+
+	var assigns []debugAssign
+	var calls []debugCall
+	for _, step := range ret.Steps {
+		assert.True(t, step.Line > 0)
+		assigns = append(assigns, step.Assigns...)
+		calls = append(calls, step.Calls...)
+	}
+	assert.Contains(t, assigns, debugAssign{Name: `i`, Value: `42`})
+	found := false
+	for _, call := range calls {
+		if call.Name == `DBInsert` {
+			found = true
+			assert.Contains(t, call.Params, name)
+		}
+	}
+	assert.True(t, found)
+
+	// the changes of the debugged contract are rolled back
+	var param paramValue
+	assert.Error(t, sendGet(`ecosystemparam/`+name+`?vde=true`, nil, &param))
+}
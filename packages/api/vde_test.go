@@ -394,7 +394,7 @@ func TestCron(t *testing.T) {
 		"Conditions": {`ContractConditions("MainCondition")`},
 		"vde":        {"true"},
 	})
-	if err.Error() != `500 {"error": "E_SERVER", "msg": "{\"type\":\"panic\",\"error\":\"End of range (60) above maximum (59): 60\"}" }` {
+	if err.Error() != `500 {"error": "E_SERVER", "msg": "{\"type\":\"panic\",\"error\":\"End of range (60) above maximum (59): 60\"}" , "params": []}` {
 		t.Error(err)
 	}
 
@@ -435,3 +435,25 @@ func TestCron(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestVDEUsage(t *testing.T) {
+	require.NoError(t, keyLogin(1))
+
+	require.NoError(t, postTx("NewContract", &url.Values{
+		"Value": {`contract TestVDEUsage {
+			action {
+				$result = "ok"
+			}
+		}`},
+		"Conditions":    {`ContractConditions("MainCondition")`},
+		"ApplicationId": {"2"},
+		"vde":           {"true"},
+	}))
+	require.NoError(t, postTx("TestVDEUsage", &url.Values{"vde": {"true"}}))
+
+	var ret vdeUsageResult
+	require.NoError(t, sendGet(`vde/usage?app_id=2`, nil, &ret))
+	require.Len(t, ret.List, 1)
+	assert.Equal(t, int64(2), ret.List[0].AppID)
+	assert.True(t, ret.List[0].Calls > 0)
+}
@@ -0,0 +1,68 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type votingItem struct {
+	ID        string          `json:"id"`
+	Subject   json.RawMessage `json:"subject"`
+	Voters    json.RawMessage `json:"voters"`
+	Votes     json.RawMessage `json:"votes"`
+	Threshold string          `json:"threshold"`
+	Deadline  string          `json:"deadline"`
+	Creator   string          `json:"creator"`
+}
+
+type votingsResult struct {
+	Count string       `json:"count"`
+	List  []votingItem `json:"list"`
+}
+
+func getVotings(w http.ResponseWriter, r *http.Request, data *apiData, logger *log.Entry) error {
+	voting := &model.Voting{}
+	votings, err := voting.SetTablePrefix(data.ecosystemId).GetActiveVotings(time.Now().Unix())
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "ecosystem": data.ecosystemId}).Error("getting votings")
+		return errorAPI(w, "E_SERVER", http.StatusInternalServerError)
+	}
+
+	result := &votingsResult{Count: converter.IntToStr(len(votings)), List: make([]votingItem, 0, len(votings))}
+	for _, item := range votings {
+		result.List = append(result.List, votingItem{
+			ID:        converter.Int64ToStr(item.ID),
+			Subject:   json.RawMessage(item.Subject),
+			Voters:    json.RawMessage(item.Voters),
+			Votes:     json.RawMessage(item.Votes),
+			Threshold: converter.Int64ToStr(item.Threshold),
+			Deadline:  converter.Int64ToStr(item.Deadline),
+			Creator:   converter.Int64ToStr(item.Creator),
+		})
+	}
+	data.result = result
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoting(t *testing.T) {
+	assert.NoError(t, keyLogin(1))
+
+	target := randName(`subject`)
+	assert.NoError(t, postTx("NewContract", &url.Values{"Value": {`contract ` + target + ` {
+		data {
+			Value string
+		}
+		action {
+			$result = "accepted " + $Value
+		}
+	}`}, "ApplicationId": {"1"}, "Conditions": {"true"}}))
+
+	start := randName(`start`)
+	assert.NoError(t, postTx("NewContract", &url.Values{"Value": {`contract ` + start + ` {
+		data {
+			Value string
+		}
+		action {
+			var subject, params map
+			var voters array
+			params["Value"] = $Value
+			subject["contract"] = "` + target + `"
+			subject["params"] = params
+			voters[0] = $key_id
+			$result = StartVoting(subject, voters, 1, $block_time + 3600)
+		}
+	}`}, "ApplicationId": {"1"}, "Conditions": {"true"}}))
+
+	vote := randName(`vote`)
+	assert.NoError(t, postTx("NewContract", &url.Values{"Value": {`contract ` + vote + ` {
+		data {
+			Id int
+		}
+		action {
+			Vote($Id, true)
+		}
+	}`}, "ApplicationId": {"1"}, "Conditions": {"true"}}))
+
+	_, votingID, err := postTxResult(start, &url.Values{"Value": {"value"}})
+	assert.NoError(t, err)
+
+	var votings votingsResult
+	isActive := func() bool {
+		assert.NoError(t, sendGet(`votings`, nil, &votings))
+		for _, item := range votings.List {
+			if item.ID == votingID {
+				return true
+			}
+		}
+		return false
+	}
+	assert.True(t, isActive())
+
+	assert.NoError(t, postTx(vote, &url.Values{"Id": {votingID}}))
+	assert.False(t, isActive())
+
+	var ret rowResult
+	assert.NoError(t, sendGet(`row/votings/`+votingID, nil, &ret))
+	assert.Equal(t, `accepted value`, ret.Value[`result`])
+
+	err = postTx(vote, &url.Values{"Id": {votingID}})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `Voting is closed`)
+	}
+}
@@ -2,13 +2,20 @@ package block
 
 import (
 	"bytes"
+	"encoding/hex"
 	"fmt"
 	"time"
 
+	"github.com/GenesisKernel/go-genesis/packages/conf"
 	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
 	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/notificator"
+	"github.com/GenesisKernel/go-genesis/packages/service"
+	"github.com/GenesisKernel/go-genesis/packages/signer"
+	"github.com/GenesisKernel/go-genesis/packages/tracing"
 	"github.com/GenesisKernel/go-genesis/packages/transaction"
 	"github.com/GenesisKernel/go-genesis/packages/transaction/custom"
 	"github.com/GenesisKernel/go-genesis/packages/utils"
@@ -26,6 +33,12 @@ type Block struct {
 	SysUpdate    bool
 	GenBlock     bool // it equals true when we are generating a new block
 	StopCount    int  // The count of good tx in the block
+	FastSync     bool // it equals true when the rollback records aren't written for the block
+	// Notifications are published only after the commit of the block
+	Notifications *notificator.Batch
+
+	trace    *tracing.Span
+	excluded map[int]string // the transactions which have panicked in the generated block and their errors
 }
 
 func (b Block) String() string {
@@ -38,9 +51,22 @@ func (b Block) GetLogger() *log.Entry {
 		"block_state_id": b.Header.EcosystemID, "block_hash": b.Header.Hash, "block_version": b.Header.Version})
 }
 
+// startStage starts the span of the processing stage within the trace of the block
+func (b *Block) startStage(stage string) *tracing.Span {
+	if b.trace == nil {
+		b.trace = tracing.StartSpan(tracing.StageBlock)
+		b.trace.SetTag("block_id", converter.Int64ToStr(b.Header.BlockID))
+	}
+	return b.trace.StartChild(stage)
+}
+
 // PlayBlockSafe is inserting block safely
 func (b *Block) PlaySafe() error {
 	logger := b.GetLogger()
+	defer func() {
+		b.trace.Finish()
+		b.trace = nil
+	}()
 	dbTransaction, err := model.StartTransaction()
 	if err != nil {
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("starting db transaction")
@@ -48,19 +74,22 @@ func (b *Block) PlaySafe() error {
 	}
 
 	err = b.Play(dbTransaction)
-	if b.GenBlock && b.StopCount > 0 {
-		doneTx := b.Transactions[:b.StopCount]
-		trData := make([][]byte, 0, b.StopCount)
-		for _, tr := range doneTx {
-			trData = append(trData, tr.TxFullData)
+	if err == nil && b.GenBlock && (b.StopCount > 0 || len(b.excluded) > 0) {
+		trData := b.includedTxs()
+		if len(trData) == 0 {
+			dbTransaction.Rollback()
+			b.Notifications.Discard()
+			for i, msg := range b.excluded {
+				transaction.MarkTransactionBad(nil, b.Transactions[i].TxHash, msg)
+			}
+			return ErrEmptyBlock
 		}
-		NodePrivateKey, _, err := utils.GetNodeKeys()
-		if err != nil || len(NodePrivateKey) < 1 {
-			log.WithFields(log.Fields{"type": consts.NodePrivateKeyFilename, "error": err}).Error("reading node private key")
+		nodeSigner, err := signer.Get()
+		if err != nil {
 			return err
 		}
 
-		newBlockData, err := MarshallBlock(&b.Header, trData, b.PrevHeader.Hash, NodePrivateKey)
+		newBlockData, err := MarshallBlock(&b.Header, trData, b.PrevHeader.Hash, nodeSigner)
 		if err != nil {
 			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("marshalling new block")
 			return err
@@ -79,6 +108,7 @@ func (b *Block) PlaySafe() error {
 		err = nil
 	} else if err != nil {
 		dbTransaction.Rollback()
+		b.Notifications.Discard()
 		if b.GenBlock && b.StopCount == 0 {
 			if err == ErrLimitStop {
 				err = ErrLimitTime
@@ -90,15 +120,34 @@ func (b *Block) PlaySafe() error {
 
 	if err := UpdBlockInfo(dbTransaction, b); err != nil {
 		dbTransaction.Rollback()
+		b.Notifications.Discard()
 		return err
 	}
 
 	if err := InsertIntoBlockchain(dbTransaction, b); err != nil {
 		dbTransaction.Rollback()
+		b.Notifications.Discard()
 		return err
 	}
 
-	dbTransaction.Commit()
+	if conf.Config.Archive.Enabled {
+		if err := model.IndexRowHistory(dbTransaction, b.Header.BlockID); err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("indexing row history")
+			dbTransaction.Rollback()
+			b.Notifications.Discard()
+			return err
+		}
+	}
+
+	span := b.startStage(tracing.StageCommit)
+	err = dbTransaction.Commit()
+	span.Finish()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("committing db transaction")
+		b.Notifications.Discard()
+		return err
+	}
+	b.Notifications.Send()
 	if b.SysUpdate {
 		b.SysUpdate = false
 		if err = syspar.SysUpdate(nil); err != nil {
@@ -125,12 +174,22 @@ func (b *Block) readPreviousBlockFromBlockchainTable() error {
 
 func (b *Block) Play(dbTransaction *model.DbTransaction) error {
 	logger := b.GetLogger()
+	if b.Notifications == nil {
+		b.Notifications = notificator.NewBatch()
+	}
 	if _, err := model.DeleteUsedTransactions(dbTransaction); err != nil {
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("delete used transactions")
 		return err
 	}
 
+	// the pending system parameters of this block are activated before the limits are read
+	syspar.SetBlockID(b.Header.BlockID)
 	limits := NewLimits(b)
+	b.FastSync = !b.GenBlock && service.FastSyncBlock(b.Header.BlockID)
+	if conf.Config.Centrifugo.Cache && !b.FastSync {
+		// the changed objects are found by the rollback records which aren't written in the fast sync
+		b.Notifications.AddBlock(b.Header.BlockID)
+	}
 
 	txHashes := make([][]byte, 0, len(b.Transactions))
 	for _, btx := range b.Transactions {
@@ -149,8 +208,11 @@ func (b *Block) Play(dbTransaction *model.DbTransaction) error {
 			err error
 		)
 		t.DbTransaction = dbTransaction
+		t.FastSync = b.FastSync
+		t.Notifications = b.Notifications
 
 		model.IncrementTxAttemptCount(dbTransaction, t.TxHash)
+		b.Notifications.AddTxStatus(t.TxHash)
 		err = dbTransaction.Savepoint(curTx)
 		if err != nil {
 			logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "tx_hash": t.TxHash}).Error("using savepoint")
@@ -165,7 +227,12 @@ func (b *Block) Play(dbTransaction *model.DbTransaction) error {
 			}
 		}
 
-		msg, err = t.Play()
+		span := b.startStage(tracing.StageExecute)
+		if t.TxContract != nil {
+			span.SetTag(tracing.TagContract, t.TxContract.Name)
+		}
+		msg, err = t.PlayRecover()
+		span.Finish()
 		if err == nil && t.TxSmart != nil {
 			err = limits.CheckLimit(t)
 		}
@@ -173,6 +240,9 @@ func (b *Block) Play(dbTransaction *model.DbTransaction) error {
 			if err == custom.ErrNetworkStopping {
 				return err
 			}
+			if _, ok := err.(*transaction.PanicError); ok && !b.isolatePanic(curTx, err) {
+				return err
+			}
 
 			if b.GenBlock && err == ErrLimitStop {
 				b.StopCount = curTx
@@ -226,9 +296,42 @@ func (b *Block) Play(dbTransaction *model.DbTransaction) error {
 	return nil
 }
 
+// isolatePanic returns true if the panicking transaction is excluded from the generated block.
+// The block of the other node is rejected, its generator had to exclude the transaction
+func (b *Block) isolatePanic(curTx int, err error) bool {
+	if !b.GenBlock {
+		b.GetLogger().WithFields(log.Fields{"type": consts.BlockError, "error": err,
+			"tx_hash": hex.EncodeToString(b.Transactions[curTx].TxHash)}).Error("transaction of block has panicked")
+		return false
+	}
+	if b.excluded == nil {
+		b.excluded = make(map[int]string)
+	}
+	b.excluded[curTx] = err.Error()
+	return true
+}
+
+// includedTxs returns the transactions of the generated block which have been played
+// without the panicking ones
+func (b *Block) includedTxs() [][]byte {
+	done := b.Transactions
+	if b.StopCount > 0 {
+		done = done[:b.StopCount]
+	}
+	trData := make([][]byte, 0, len(done))
+	for i, tr := range done {
+		if _, ok := b.excluded[i]; !ok {
+			trData = append(trData, tr.TxFullData)
+		}
+	}
+	return trData
+}
+
 // CheckBlock is checking block
 func (b *Block) Check() error {
 	logger := b.GetLogger()
+	span := b.startStage(tracing.StageVerify)
+	defer span.Finish()
 	// exclude blocks from future
 	if b.Header.Time > time.Now().Unix() {
 		logger.WithFields(log.Fields{"type": consts.ParameterExceeded}).Error("block time is larger than now")
@@ -297,7 +400,7 @@ func (b *Block) Check() error {
 
 	}
 
-	result, err := b.CheckHash()
+	result, err := b.checkSigns()
 	if err != nil {
 		return utils.ErrInfo(err)
 	}
@@ -308,36 +411,91 @@ func (b *Block) Check() error {
 	return nil
 }
 
-// CheckHash is checking hash
-func (b *Block) CheckHash() (bool, error) {
-	logger := b.GetLogger()
-	if b.Header.BlockID == 1 {
-		return true, nil
+// checkSigns checks the signature of the block together with the signatures of the transactions.
+// The incorrect signature of the block rejects it. The transactions with the incorrect signatures
+// are checked again and marked as bad when the block is played
+func (b *Block) checkSigns() (bool, error) {
+	item, err := b.signItem()
+	if err != nil {
+		return false, err
 	}
-	// check block signature
-	if b.PrevHeader != nil {
-		nodePublicKey, err := syspar.GetNodePublicKeyByPosition(b.Header.NodePosition)
+	items := make([]utils.SignItem, 0, len(b.Transactions)+1)
+	if item != nil {
+		items = append(items, *item)
+	}
+	txs := make([]*transaction.Transaction, 0, len(b.Transactions))
+	for _, t := range b.Transactions {
+		txItem, err := t.GetSignItem()
 		if err != nil {
-			return false, utils.ErrInfo(err)
+			return false, err
 		}
-		if len(nodePublicKey) == 0 {
-			logger.WithFields(log.Fields{"type": consts.EmptyObject}).Error("node public key is empty")
-			return false, utils.ErrInfo(fmt.Errorf("empty nodePublicKey"))
+		if txItem != nil {
+			items = append(items, *txItem)
+			txs = append(txs, t)
 		}
-		// check the signature
-		forSign := fmt.Sprintf("0,%d,%x,%d,%d,%d,%d,%s", b.Header.BlockID, b.PrevHeader.Hash,
-			b.Header.Time, b.Header.EcosystemID, b.Header.KeyID, b.Header.NodePosition, b.MrklRoot)
-
-		resultCheckSign, err := utils.CheckSign([][]byte{nodePublicKey}, forSign, b.Header.Sign, true)
-		if err != nil {
-			logger.WithFields(log.Fields{"error": err, "type": consts.CryptoError}).Error("checking block header sign")
-			return false, utils.ErrInfo(fmt.Errorf("err: %v / block.PrevHeader.BlockID: %d /  block.PrevHeader.Hash: %x / ", err, b.PrevHeader.BlockID, b.PrevHeader.Hash))
+	}
+	results, err := utils.CheckSignBatch(items)
+	if errBatch, ok := err.(*utils.SignBatchError); ok && item != nil && errBatch.Index == 0 {
+		b.GetLogger().WithFields(log.Fields{"error": errBatch.Err, "type": consts.CryptoError}).Error("checking block header sign")
+		return false, nil
+	}
+	if item != nil {
+		results = results[1:]
+		items = items[1:]
+	}
+	for i, t := range txs {
+		if results[i] {
+			t.VerifiedKey = items[i].PublicKeys[0]
 		}
+	}
+	return true, nil
+}
 
-		return resultCheckSign, nil
+// signItem returns the signature of the block. It returns nil if the signature isn't checked
+func (b *Block) signItem() (*utils.SignItem, error) {
+	logger := b.GetLogger()
+	if b.Header.BlockID == 1 || b.PrevHeader == nil {
+		return nil, nil
 	}
+	nodePublicKey, err := syspar.GetNodePublicKeyByPosition(b.Header.NodePosition)
+	if err != nil {
+		return nil, utils.ErrInfo(err)
+	}
+	if len(nodePublicKey) == 0 {
+		logger.WithFields(log.Fields{"type": consts.EmptyObject}).Error("node public key is empty")
+		return nil, utils.ErrInfo(fmt.Errorf("empty nodePublicKey"))
+	}
+	return &utils.SignItem{PublicKeys: [][]byte{nodePublicKey}, ForSign: b.ForSign(), Signs: b.Header.Sign,
+		NodeKeyOrLogin: true}, nil
+}
 
-	return true, nil
+// ForSign returns the data of the block which are signed by the node
+func (b *Block) ForSign() string {
+	return fmt.Sprintf("0,%d,%x,%d,%d,%d,%d,%s", b.Header.BlockID, b.PrevHeader.Hash,
+		b.Header.Time, b.Header.EcosystemID, b.Header.KeyID, b.Header.NodePosition, b.MrklRoot)
+}
+
+// Hash returns the hash of the block which is chained with the hash of the previous block
+func (b *Block) Hash() ([]byte, error) {
+	return crypto.DoubleHash([]byte(fmt.Sprintf("%d,%x,%s,%d,%d,%d,%d", b.Header.BlockID, b.PrevHeader.Hash,
+		b.MrklRoot, b.Header.Time, b.Header.EcosystemID, b.Header.KeyID, b.Header.NodePosition)))
+}
+
+// CheckHash is checking hash
+func (b *Block) CheckHash() (bool, error) {
+	logger := b.GetLogger()
+	// check block signature
+	item, err := b.signItem()
+	if err != nil || item == nil {
+		return item == nil, err
+	}
+	// check the signature
+	resultCheckSign, err := utils.CheckSign(item.PublicKeys, item.ForSign, item.Signs, item.NodeKeyOrLogin)
+	if err != nil {
+		logger.WithFields(log.Fields{"error": err, "type": consts.CryptoError}).Error("checking block header sign")
+		return false, utils.ErrInfo(fmt.Errorf("err: %v / block.PrevHeader.BlockID: %d /  block.PrevHeader.Hash: %x / ", err, b.PrevHeader.BlockID, b.PrevHeader.Hash))
+	}
+	return resultCheckSign, nil
 }
 
 // InsertBlockWOForks is inserting blocks
@@ -373,11 +531,16 @@ func ProcessBlockWherePrevFromBlockchainTable(data []byte, checkSize bool) (*Blo
 		return nil, fmt.Errorf("empty buffer")
 	}
 
+	trace := tracing.StartSpan(tracing.StageBlock)
+	span := trace.StartChild(tracing.StageParse)
 	block, err := UnmarshallBlock(buf, !checkSize)
+	span.Finish()
 	if err != nil {
 		return nil, err
 	}
 	block.BinData = data
+	block.trace = trace
+	trace.SetTag("block_id", converter.Int64ToStr(block.Header.BlockID))
 
 	if err := block.readPreviousBlockFromBlockchainTable(); err != nil {
 		return nil, err
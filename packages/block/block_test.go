@@ -0,0 +1,36 @@
+package block
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/transaction"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testBlock(genBlock bool) *Block {
+	b := &Block{GenBlock: genBlock}
+	for i := 0; i < 4; i++ {
+		b.Transactions = append(b.Transactions, &transaction.Transaction{TxHash: []byte{byte(i)},
+			TxFullData: []byte{byte(i)}})
+	}
+	return b
+}
+
+func TestIsolatePanic(t *testing.T) {
+	err := &transaction.PanicError{Value: errors.New(`nil map`)}
+
+	// the generator excludes the panicking transactions
+	b := testBlock(true)
+	assert.True(t, b.isolatePanic(1, err))
+	assert.Equal(t, [][]byte{{0}, {2}, {3}}, b.includedTxs())
+	b.StopCount = 3
+	assert.Equal(t, [][]byte{{0}, {2}}, b.includedTxs())
+	assert.Equal(t, map[int]string{1: `transaction panic: nil map`}, b.excluded)
+
+	// the block of the other node is rejected
+	b = testBlock(false)
+	assert.False(t, b.isolatePanic(1, err))
+	assert.Empty(t, b.excluded)
+}
@@ -3,9 +3,11 @@ package block
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
 	"github.com/GenesisKernel/go-genesis/packages/crypto"
@@ -15,14 +17,24 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// errFirstBlockData is returned if the block doesn't contain the first block transaction
+var errFirstBlockData = errors.New("block doesn't contain data of first block")
+
+// SetSysparBlockID activates the pending system parameters of the last block in info_block
+func SetSysparBlockID() error {
+	ib := &model.InfoBlock{}
+	if _, err := ib.Get(); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting info block")
+		return err
+	}
+	syspar.SetBlockID(ib.BlockID)
+	return nil
+}
+
 // UpdBlockInfo updates info_block table
 func UpdBlockInfo(dbTransaction *model.DbTransaction, block *Block) error {
 	blockID := block.Header.BlockID
-	// for the local tests
-	forSha := fmt.Sprintf("%d,%x,%s,%d,%d,%d,%d", blockID, block.PrevHeader.Hash, block.MrklRoot,
-		block.Header.Time, block.Header.EcosystemID, block.Header.KeyID, block.Header.NodePosition)
-
-	hash, err := crypto.DoubleHash([]byte(forSha))
+	hash, err := block.Hash()
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Fatal("double hashing block")
 	}
@@ -74,11 +86,15 @@ func InsertIntoBlockchain(transaction *model.DbTransaction, block *Block) error
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting block by id")
 		return err
 	}
-	rollbackTx := &model.RollbackTx{}
-	blockRollbackTxs, err := rollbackTx.GetBlockRollbackTransactions(transaction, blockID)
-	if err != nil {
-		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting block rollback txs")
-		return err
+	var blockRollbackTxs []model.RollbackTx
+	// there are no rollback records for the blocks applied in the fast sync mode
+	if !block.FastSync {
+		rollbackTx := &model.RollbackTx{}
+		blockRollbackTxs, err = rollbackTx.GetBlockRollbackTransactions(transaction, blockID)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting block rollback txs")
+			return err
+		}
 	}
 	buffer := bytes.Buffer{}
 	for _, rollbackTx := range blockRollbackTxs {
@@ -167,23 +183,29 @@ func GetDataFromFirstBlock() (data *consts.FirstBlock, ok bool) {
 		return
 	}
 
-	pb, err := UnmarshallBlock(bytes.NewBuffer(block.Data), true)
+	data, err = ParseFirstBlockData(block.Data)
+	return data, err == nil
+}
+
+// ParseFirstBlockData returns the data of the first block from the binary block
+func ParseFirstBlockData(b []byte) (*consts.FirstBlock, error) {
+	pb, err := UnmarshallBlock(bytes.NewBuffer(b), true)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.ParserError, "error": err}).Error("parsing data of first block")
-		return
+		return nil, err
 	}
 
 	if len(pb.Transactions) == 0 {
 		log.WithFields(log.Fields{"type": consts.ParserError}).Error("list of parsers is empty")
-		return
+		return nil, errFirstBlockData
 	}
 
 	t := pb.Transactions[0]
-	data, ok = t.TxPtr.(*consts.FirstBlock)
+	data, ok := t.TxPtr.(*consts.FirstBlock)
 	if !ok {
 		log.WithFields(log.Fields{"type": consts.ParserError}).Error("getting data of first block")
-		return
+		return nil, errFirstBlockData
 	}
 
-	return
+	return data, nil
 }
@@ -0,0 +1,95 @@
+package block
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/transaction"
+	"github.com/GenesisKernel/go-genesis/packages/utils"
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DescribedBlock is the self-describing envelope of the binary block. It is encoded with
+// converter.DescribeLayout, so the header and the contracts have the fields of the consensus format
+type DescribedBlock struct {
+	Header       utils.BlockData        `bin:"1,message"`
+	Transactions []DescribedTransaction `bin:"2,message"`
+}
+
+// DescribedTransaction is the transaction of DescribedBlock. Contract is filled for the contract
+// transactions of the known format, Data has the data of the other transactions
+type DescribedTransaction struct {
+	Hash     []byte            `bin:"1,bytes"`
+	Type     int64             `bin:"2,int"`
+	Version  int64             `bin:"3,int"`
+	Contract *tx.SmartContract `bin:"4,message"`
+	Data     []byte            `bin:"5,bytes"`
+}
+
+// LayoutVersion returns the format version of the contract, msgpack of the version 0 has all fields
+func (t DescribedTransaction) LayoutVersion() byte {
+	if t.Version == 0 {
+		return tx.SmartContractVersion
+	}
+	return byte(t.Version)
+}
+
+// DescribeBlock returns the binary block as DescribedBlock in the wire format of protobuf
+func DescribeBlock(data []byte) ([]byte, error) {
+	blockBuffer := bytes.NewBuffer(data)
+	header, err := utils.ParseBlockHeader(blockBuffer, false)
+	if err != nil {
+		return nil, err
+	}
+	logger := log.WithFields(log.Fields{"block_id": header.BlockID, "block_time": header.Time})
+
+	described := DescribedBlock{Header: header}
+	for blockBuffer.Len() > 0 {
+		transactionSize, err := converter.DecodeLengthBuf(blockBuffer)
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err}).Error("transaction size is 0")
+			return nil, fmt.Errorf("bad block format (%s)", err)
+		}
+		if transactionSize == 0 || blockBuffer.Len() < int(transactionSize) {
+			logger.WithFields(log.Fields{"size": blockBuffer.Len(), "match_size": int(transactionSize), "type": consts.SizeDoesNotMatch}).Error("transaction size does not matches encoded length")
+			return nil, fmt.Errorf("bad block format (transaction len is %d)", transactionSize)
+		}
+		t, err := describeTransaction(blockBuffer.Next(int(transactionSize)), logger)
+		if err != nil {
+			return nil, err
+		}
+		described.Transactions = append(described.Transactions, t)
+	}
+	return converter.DescribeLayout(&described, 0)
+}
+
+func describeTransaction(data []byte, logger *log.Entry) (DescribedTransaction, error) {
+	hash, err := crypto.Hash(data)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("hashing transaction")
+		return DescribedTransaction{}, err
+	}
+	t := DescribedTransaction{Hash: hash, Type: int64(data[0]), Data: data[1:]}
+	if !transaction.IsContractTransaction(int(t.Type)) {
+		return t, nil
+	}
+	var smartTx tx.SmartContract
+	if err := smartTx.Unmarshal(t.Data); err != nil {
+		// the transaction is described as the raw data
+		logger.WithFields(log.Fields{"type": consts.UnmarshallingError, "tx_hash": hash, "error": err}).Warn("unmarshalling smart tx")
+		return t, nil
+	}
+	version, _, _ := converter.DecodeVersioned(t.Data)
+	t.Version, t.Contract, t.Data = int64(version), &smartTx, nil
+	return t, nil
+}
+
+// BlockSchema returns the protobuf definition of DescribedBlock
+func BlockSchema() (string, error) {
+	return converter.LayoutSchema(DescribedBlock{})
+}
@@ -0,0 +1,80 @@
+package block
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/utils"
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testHeader = utils.BlockData{Version: 1, BlockID: 20, Time: 1530000000, EcosystemID: 1,
+	KeyID: -7097400992759152070, NodePosition: 2}
+
+func TestMarshallBlockHeader(t *testing.T) {
+	trData := [][]byte{{1, 2, 3}}
+	data, err := MarshallBlock(&testHeader, trData, nil, nil)
+	require.NoError(t, err)
+
+	// the header has the same format as before the bin tags
+	var want []byte
+	for _, item := range [][]byte{converter.DecToBin(1, 2), converter.DecToBin(20, 4),
+		converter.DecToBin(1530000000, 4), converter.DecToBin(1, 4),
+		converter.EncodeLenInt64InPlace(testHeader.KeyID), converter.DecToBin(2, 1),
+		converter.EncodeLengthPlusData([]byte{}), converter.EncodeLengthPlusData(trData[0])} {
+		want = append(want, item...)
+	}
+	assert.Equal(t, want, data)
+
+	buf := bytes.NewBuffer(data)
+	header, err := utils.ParseBlockHeader(buf, false)
+	require.NoError(t, err)
+	assert.Empty(t, header.Sign)
+	header.Sign = nil
+	assert.Equal(t, testHeader, header)
+	assert.Equal(t, converter.EncodeLengthPlusData(trData[0]), buf.Bytes())
+}
+
+func TestDescribeBlock(t *testing.T) {
+	smartTx := tx.SmartContract{Header: tx.Header{Type: 5, Time: 1530000000, EcosystemID: 1, KeyID: 7},
+		RequestID: `request`, Expiration: 1530000600, Nonce: 1, Data: []byte(`data`)}
+	contract, err := smartTx.Marshal()
+	require.NoError(t, err)
+	// the contract of the unknown version is described as the raw data
+	trData := [][]byte{append([]byte{128}, contract...), {1, 2, 3}, {128, 0xc1, 99}}
+	data, err := MarshallBlock(&testHeader, trData, nil, nil)
+	require.NoError(t, err)
+
+	want := DescribedBlock{Header: testHeader}
+	for _, item := range trData {
+		hash, err := crypto.Hash(item)
+		require.NoError(t, err)
+		want.Transactions = append(want.Transactions, DescribedTransaction{Hash: hash,
+			Type: int64(item[0]), Data: item[1:]})
+	}
+	want.Transactions[0].Version, want.Transactions[0].Contract = tx.SmartContractVersion, &smartTx
+	want.Transactions[0].Data = nil
+	wantData, err := converter.DescribeLayout(want, 0)
+	require.NoError(t, err)
+
+	described, err := DescribeBlock(data)
+	require.NoError(t, err)
+	assert.Equal(t, wantData, described)
+
+	_, err = DescribeBlock(data[:len(data)-1])
+	assert.Error(t, err)
+
+	schema, err := BlockSchema()
+	require.NoError(t, err)
+	for _, line := range []string{`message DescribedBlock {`, `  BlockData header = 1;`,
+		`  repeated DescribedTransaction transactions = 2;`, `  int64 block_id = 2; // fixed4`,
+		`  SmartContract contract = 4;`, `  repeated int64 signers = 14; // ints, since v3`,
+		`  string request_id = 17; // bytes`} {
+		assert.Contains(t, schema, line+"\n")
+	}
+}
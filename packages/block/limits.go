@@ -19,6 +19,7 @@ package block
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
@@ -60,10 +61,48 @@ var (
 	ErrLimitSkip = errors.New(`skip tx`)
 	// ErrLimitStop returns when the generation of the block should be stopped
 	ErrLimitStop = errors.New(`stop generating block`)
+	// ErrEmptyBlock returns when all transactions of the generated block have panicked
+	ErrEmptyBlock = errors.New(`all transactions of block have panicked`)
 	// ErrLimitTime returns when the time limit exceeded
 	ErrLimitTime = errors.New(`Time limit exceeded`)
 )
 
+// limitDefaults are the values of the limits which are used if the system parameters are missing or malformed
+var limitDefaults = map[string]int64{
+	syspar.MaxTxCount:             1000,
+	syspar.MaxBlockGenerationTime: 2000,
+	syspar.MaxBlockUserTx:         100,
+	syspar.MaxBlockSize:           67108864,
+	syspar.MaxTxSize:              33554432,
+	syspar.MaxBlockFuel:           100000,
+	syspar.MaxTxFuel:              20000,
+}
+
+// limitValues are the values of the system parameters of the limits, they are updated by syspar.OnChange
+var limitValues = struct {
+	sync.RWMutex
+	values map[string]int64
+}{values: make(map[string]int64)}
+
+func init() {
+	for name, def := range limitDefaults {
+		name, def := name, def
+		limitValues.values[name] = def
+		syspar.OnChange(name, func(_, _ string) {
+			value := syspar.SysInt64Def(name, def)
+			limitValues.Lock()
+			limitValues.values[name] = value
+			limitValues.Unlock()
+		})
+	}
+}
+
+func limitValue(name string) int64 {
+	limitValues.RLock()
+	defer limitValues.RUnlock()
+	return limitValues.values[name]
+}
+
 // NewLimits initializes Limits structure.
 func NewLimits(b *Block) (limits *Limits) {
 	limits = &Limits{Block: b, Limiters: make([]Limiter, 0, 8)}
@@ -115,7 +154,7 @@ type txMaxLimit struct {
 }
 
 func (bl *txMaxLimit) init(b *Block) {
-	bl.Limit = syspar.GetMaxTxCount()
+	bl.Limit = int(limitValue(syspar.MaxTxCount))
 }
 
 func (bl *txMaxLimit) check(t *transaction.Transaction, mode int) error {
@@ -137,7 +176,7 @@ type timeBlockLimit struct {
 
 func (bl *timeBlockLimit) init(b *Block) {
 	bl.Start = time.Now()
-	bl.Limit = time.Millisecond * time.Duration(syspar.GetMaxBlockGenerationTime())
+	bl.Limit = time.Millisecond * time.Duration(limitValue(syspar.MaxBlockGenerationTime))
 }
 
 func (bl *timeBlockLimit) check(t *transaction.Transaction, mode int) error {
@@ -160,7 +199,7 @@ type txUserLimit struct {
 
 func (bl *txUserLimit) init(b *Block) {
 	bl.TxUsers = make(map[int64]int)
-	bl.Limit = syspar.GetMaxBlockUserTx()
+	bl.Limit = int(limitValue(syspar.MaxBlockUserTx))
 }
 
 func (bl *txUserLimit) check(t *transaction.Transaction, mode int) error {
@@ -212,7 +251,7 @@ func (bl *txUserEcosysLimit) check(t *transaction.Transaction, mode int) error {
 			val.TxUsers[keyID] = 1
 		}
 	} else {
-		limit := syspar.GetMaxBlockUserTx()
+		limit := int(limitValue(syspar.MaxBlockUserTx))
 		sp := &model.StateParameter{}
 		sp.SetTablePrefix(converter.Int64ToStr(ecosystemID))
 		found, err := sp.Get(t.DbTransaction, `max_block_user_tx`)
@@ -236,8 +275,8 @@ type txMaxSize struct {
 }
 
 func (bl *txMaxSize) init(b *Block) {
-	bl.LimitBlock = syspar.GetMaxBlockSize()
-	bl.LimitTx = syspar.GetMaxTxSize()
+	bl.LimitBlock = limitValue(syspar.MaxBlockSize)
+	bl.LimitTx = limitValue(syspar.MaxTxSize)
 }
 
 func (bl *txMaxSize) check(t *transaction.Transaction, mode int) error {
@@ -263,8 +302,8 @@ type txMaxFuel struct {
 }
 
 func (bl *txMaxFuel) init(b *Block) {
-	bl.LimitBlock = syspar.GetMaxBlockFuel()
-	bl.LimitTx = syspar.GetMaxTxFuel()
+	bl.LimitBlock = limitValue(syspar.MaxBlockFuel)
+	bl.LimitTx = limitValue(syspar.MaxTxFuel)
 }
 
 func (bl *txMaxFuel) check(t *transaction.Transaction, mode int) error {
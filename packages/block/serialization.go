@@ -7,13 +7,14 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
 	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/signer"
 	"github.com/GenesisKernel/go-genesis/packages/transaction"
 	"github.com/GenesisKernel/go-genesis/packages/utils"
 	log "github.com/sirupsen/logrus"
 )
 
-// MarshallBlock is marshalling block
-func MarshallBlock(header *utils.BlockData, trData [][]byte, prevHash []byte, key string) ([]byte, error) {
+// MarshallBlock is marshalling block, the block is signed if s isn't nil
+func MarshallBlock(header *utils.BlockData, trData [][]byte, prevHash []byte, s signer.BlockSigner) ([]byte, error) {
 	var mrklArray [][]byte
 	var blockDataTx []byte
 	var signed []byte
@@ -29,7 +30,7 @@ func MarshallBlock(header *utils.BlockData, trData [][]byte, prevHash []byte, ke
 		blockDataTx = append(blockDataTx, converter.EncodeLengthPlusData(tr)...)
 	}
 
-	if key != "" {
+	if s != nil {
 		if len(mrklArray) == 0 {
 			mrklArray = append(mrklArray, []byte("0"))
 		}
@@ -39,22 +40,23 @@ func MarshallBlock(header *utils.BlockData, trData [][]byte, prevHash []byte, ke
 			header.BlockID, prevHash, header.Time, header.EcosystemID, header.KeyID, header.NodePosition, mrklRoot)
 
 		var err error
-		signed, err = crypto.Sign(key, forSign)
+		signed, err = signer.SignData(s, forSign)
 		if err != nil {
 			logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("signing blocko")
 			return nil, err
 		}
 	}
 
-	var buf bytes.Buffer
 	// fill header
-	buf.Write(converter.DecToBin(header.Version, 2))
-	buf.Write(converter.DecToBin(header.BlockID, 4))
-	buf.Write(converter.DecToBin(header.Time, 4))
-	buf.Write(converter.DecToBin(header.EcosystemID, 4))
-	buf.Write(converter.EncodeLenInt64InPlace(header.KeyID))
-	buf.Write(converter.DecToBin(header.NodePosition, 1))
-	buf.Write(converter.EncodeLengthPlusData(signed))
+	signedHeader := *header
+	signedHeader.Sign = signed
+	headerData, err := converter.MarshalLayout(&signedHeader, 0)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling block header")
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.Write(headerData)
 	// data
 	buf.Write(blockDataTx)
 
@@ -104,12 +106,10 @@ func UnmarshallBlock(blockBuffer *bytes.Buffer, firstBlock bool) (*Block, error)
 
 		// build merkle tree
 		if len(t.TxFullData) > 0 {
-			dSha256Hash, err := crypto.DoubleHash(t.TxFullData)
+			dSha256Hash, err := mrklHash(t.TxFullData, logger)
 			if err != nil {
-				logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("double hashing tx full data")
 				return nil, err
 			}
-			dSha256Hash = converter.BinToHex(dSha256Hash)
 			mrklSlice = append(mrklSlice, dSha256Hash)
 		}
 	}
@@ -124,3 +124,46 @@ func UnmarshallBlock(blockBuffer *bytes.Buffer, firstBlock bool) (*Block, error)
 		MrklRoot:     utils.MerkleTreeRoot(mrklSlice),
 	}, nil
 }
+
+// UnmarshallHeader parses the header of the block and calculates the merkle root of the transactions
+// without their parsing, so the contracts don't have to be loaded. The block has no transactions
+func UnmarshallHeader(blockBuffer *bytes.Buffer, firstBlock bool) (*Block, error) {
+	header, err := utils.ParseBlockHeader(blockBuffer, !firstBlock)
+	if err != nil {
+		return nil, err
+	}
+	logger := log.WithFields(log.Fields{"block_id": header.BlockID, "block_time": header.Time})
+
+	mrklSlice := make([][]byte, 0)
+	for blockBuffer.Len() > 0 {
+		transactionSize, err := converter.DecodeLengthBuf(blockBuffer)
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err}).Error("transaction size is 0")
+			return nil, fmt.Errorf("bad block format (%s)", err)
+		}
+		if transactionSize == 0 || blockBuffer.Len() < int(transactionSize) {
+			logger.WithFields(log.Fields{"size": blockBuffer.Len(), "match_size": int(transactionSize), "type": consts.SizeDoesNotMatch}).Error("transaction size does not matches encoded length")
+			return nil, fmt.Errorf("bad block format (transaction len is %d)", transactionSize)
+		}
+		dSha256Hash, err := mrklHash(blockBuffer.Next(int(transactionSize)), logger)
+		if err != nil {
+			return nil, err
+		}
+		mrklSlice = append(mrklSlice, dSha256Hash)
+	}
+	if len(mrklSlice) == 0 {
+		mrklSlice = append(mrklSlice, []byte("0"))
+	}
+
+	return &Block{Header: header, MrklRoot: utils.MerkleTreeRoot(mrklSlice)}, nil
+}
+
+// mrklHash returns the leaf of the merkle tree for the transaction
+func mrklHash(data []byte, logger *log.Entry) ([]byte, error) {
+	hash, err := crypto.DoubleHash(data)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("double hashing tx full data")
+		return nil, err
+	}
+	return converter.BinToHex(hash), nil
+}
@@ -0,0 +1,301 @@
+// Package chainfile exports the blocks of the blockchain to the portable file and imports them
+package chainfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/GenesisKernel/go-genesis/packages/block"
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
+	"github.com/GenesisKernel/go-genesis/packages/tcpserver"
+	"github.com/GenesisKernel/go-genesis/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// The file starts with the magic number and the version of the format
+const (
+	Magic   uint32 = 0x47424331 // GBC1
+	Version uint16 = 1
+)
+
+var (
+	// ErrFormat is returned if the file isn't the exported blockchain
+	ErrFormat = errors.New("unknown format of the blockchain file")
+	// ErrNetwork is returned if the file is exported from the other network
+	ErrNetwork = errors.New("blockchain file is exported from the other network")
+	// ErrFirstBlock is returned if the first block of the file doesn't match the local first block
+	ErrFirstBlock = errors.New("first block of the file doesn't match the local first block")
+)
+
+// Manifest is the header of the file, the blocks from FromID to ToID follow it in the format
+// of the tcp server. FirstHash is the hash of the first block of the blockchain,
+// LastHash is the hash of the block ToID
+type Manifest struct {
+	Magic     uint32
+	Version   uint16
+	NetworkID int64
+	FromID    int64
+	ToID      int64
+	FirstHash []byte
+	LastHash  []byte
+}
+
+// ProgressFunc is called after every block with the id of the block and the id of the last block
+type ProgressFunc func(blockID, last int64)
+
+// readManifest reads and checks the manifest
+func readManifest(r io.Reader) (*Manifest, error) {
+	m := &Manifest{}
+	if err := tcpserver.ReadRequest(m, r); err != nil {
+		return nil, ErrFormat
+	}
+	if m.Magic != Magic || m.Version != Version || m.FromID < 1 || m.ToID < m.FromID {
+		return nil, ErrFormat
+	}
+	if m.NetworkID != consts.NETWORK_ID {
+		return nil, ErrNetwork
+	}
+	return m, nil
+}
+
+// readBlock reads the data of the next block
+func readBlock(r io.Reader) ([]byte, error) {
+	resp := &tcpserver.GetBodyResponse{}
+	if err := tcpserver.ReadRequest(resp, r); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// blockHash returns the id and the hash of the block which is linked to prevHash
+func blockHash(data, prevHash []byte) (int64, []byte, error) {
+	b, err := block.UnmarshallHeader(bytes.NewBuffer(data), len(prevHash) == 0)
+	if err != nil {
+		return 0, nil, err
+	}
+	b.PrevHeader = &utils.BlockData{BlockID: b.Header.BlockID - 1, Hash: prevHash}
+	hash, err := b.Hash()
+	return b.Header.BlockID, hash, err
+}
+
+// localFirstHash returns the hash of the first block of the database or of the first block file
+// of the config if the blockchain is empty
+func localFirstHash() ([]byte, error) {
+	first := &model.Block{}
+	found, err := first.Get(1)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting first block")
+		return nil, err
+	}
+	if found {
+		return first.Hash, nil
+	}
+	data, err := ioutil.ReadFile(conf.Config.FirstBlockPath)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": conf.Config.FirstBlockPath}).Error("reading first block from file")
+		return nil, err
+	}
+	_, hash, err := blockHash(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("first block file %s: %s", conf.Config.FirstBlockPath, err)
+	}
+	return hash, nil
+}
+
+// batchSize is the number of the blocks which are read from the database at once
+const batchSize = 1000
+
+// Export writes the blocks from fromID to toID, toID is the last block if it's zero
+func Export(w io.Writer, fromID, toID int64, progress ProgressFunc) (*Manifest, error) {
+	last := &model.Block{}
+	if _, err := last.GetMaxBlock(); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting max block")
+		return nil, err
+	}
+	if toID == 0 {
+		toID = last.ID
+	}
+	if fromID < 1 || toID < fromID || toID > last.ID {
+		return nil, fmt.Errorf("block range %d-%d must be within 1-%d", fromID, toID, last.ID)
+	}
+	first, to := &model.Block{}, &model.Block{}
+	for _, item := range []struct {
+		b  *model.Block
+		id int64
+	}{{first, 1}, {to, toID}} {
+		found, err := item.b.Get(item.id)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": item.id}).Error("getting block")
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("block %d isn't found", item.id)
+		}
+	}
+
+	m := &Manifest{Magic: Magic, Version: Version, NetworkID: consts.NETWORK_ID, FromID: fromID, ToID: toID,
+		FirstHash: first.Hash, LastHash: to.Hash}
+	if err := tcpserver.SendRequest(m, w); err != nil {
+		return nil, err
+	}
+	prevID := fromID - 1
+	for prevID < toID {
+		blocks, err := (&model.Block{}).GetBlocksFrom(prevID, "asc", batchSize)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting blocks")
+			return nil, err
+		}
+		for _, b := range blocks {
+			if b.ID > toID {
+				break
+			}
+			if b.ID != prevID+1 {
+				return nil, fmt.Errorf("block %d isn't found", prevID+1)
+			}
+			if err = tcpserver.SendRequest(&tcpserver.GetBodyResponse{Data: b.Data}, w); err != nil {
+				return nil, err
+			}
+			prevID = b.ID
+			if progress != nil {
+				progress(b.ID, toID)
+			}
+		}
+		if len(blocks) == 0 {
+			return nil, fmt.Errorf("block %d isn't found", prevID+1)
+		}
+	}
+	return m, nil
+}
+
+// Import checks all blocks of the file and plays the blocks which are missing in the local blockchain.
+// The blocks which are already in the blockchain must match it, so the interrupted import can be repeated.
+// It returns the number of the played blocks
+func Import(r io.ReadSeeker, progress ProgressFunc) (*Manifest, int64, error) {
+	head := &model.InfoBlock{}
+	if _, err := head.Get(); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting info block")
+		return nil, 0, err
+	}
+	m, err := check(r, head.BlockID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+	if _, err = readManifest(r); err != nil {
+		return nil, 0, err
+	}
+
+	var played int64
+	prepared := false
+	for id := m.FromID; id <= m.ToID; id++ {
+		data, err := readBlock(r)
+		if err != nil {
+			return m, played, err
+		}
+		if id <= head.BlockID {
+			continue
+		}
+		if id > 1 && !prepared {
+			if err = prepare(); err != nil {
+				return m, played, err
+			}
+			prepared = true
+		}
+		if err = block.InsertBlockWOForks(data, false, id == 1); err != nil {
+			return m, played, fmt.Errorf("block %d: %s", id, err)
+		}
+		played++
+		if progress != nil {
+			progress(id, m.ToID)
+		}
+	}
+	return m, played, nil
+}
+
+// check reads the file and checks the chain of the hashes of the blocks, the blocks up to headID
+// must be the same as the local blocks
+func check(r io.Reader, headID int64) (*Manifest, error) {
+	m, err := readManifest(r)
+	if err != nil {
+		return nil, err
+	}
+	firstHash, err := localFirstHash()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(firstHash, m.FirstHash) {
+		return nil, ErrFirstBlock
+	}
+	if m.FromID > headID+1 {
+		return nil, fmt.Errorf("file starts at block %d, the local blockchain ends at block %d", m.FromID, headID)
+	}
+
+	var prevHash []byte
+	if m.FromID > 1 {
+		prev := &model.Block{}
+		found, err := prev.Get(m.FromID - 1)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting block")
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("block %d isn't found", m.FromID-1)
+		}
+		prevHash = prev.Hash
+	}
+	for id := m.FromID; id <= m.ToID; id++ {
+		data, err := readBlock(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading block %d: %s", id, err)
+		}
+		blockID, hash, err := blockHash(data, prevHash)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %s", id, err)
+		}
+		if blockID != id {
+			return nil, fmt.Errorf("block %d is expected, got block %d", id, blockID)
+		}
+		if id == 1 && !bytes.Equal(hash, m.FirstHash) {
+			return nil, ErrFirstBlock
+		}
+		if id <= headID {
+			local := &model.Block{}
+			if _, err = local.Get(id); err != nil {
+				log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting block")
+				return nil, err
+			}
+			if !bytes.Equal(hash, local.Hash) {
+				return nil, fmt.Errorf("block %d doesn't match the local blockchain", id)
+			}
+		}
+		prevHash = hash
+	}
+	if !bytes.Equal(prevHash, m.LastHash) {
+		return nil, fmt.Errorf("hash of block %d doesn't match the manifest", m.ToID)
+	}
+	return m, nil
+}
+
+// prepare loads the system parameters and the contracts of the local blockchain
+func prepare() error {
+	if err := syspar.SysUpdate(nil); err != nil {
+		return err
+	}
+	if err := block.SetSysparBlockID(); err != nil {
+		return err
+	}
+	if data, ok := block.GetDataFromFirstBlock(); ok {
+		syspar.SetFirstBlockData(data)
+	}
+	return smart.LoadContracts(nil)
+}
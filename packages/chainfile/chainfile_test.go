@@ -0,0 +1,48 @@
+package chainfile
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/tcpserver"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifest(t *testing.T) {
+	m := &Manifest{Magic: Magic, Version: Version, NetworkID: consts.NETWORK_ID, FromID: 5, ToID: 7,
+		FirstHash: []byte{1, 2, 3}, LastHash: []byte{4, 5, 6}}
+	buf := &bytes.Buffer{}
+	require.NoError(t, tcpserver.SendRequest(m, buf))
+	require.NoError(t, tcpserver.SendRequest(&tcpserver.GetBodyResponse{Data: []byte("block")}, buf))
+
+	read, err := readManifest(buf)
+	require.NoError(t, err)
+	assert.Equal(t, m, read)
+	data, err := readBlock(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("block"), data)
+
+	for _, item := range []struct {
+		change func(m *Manifest)
+		err    error
+	}{
+		{func(m *Manifest) { m.Magic++ }, ErrFormat},
+		{func(m *Manifest) { m.Version++ }, ErrFormat},
+		{func(m *Manifest) { m.FromID = 0 }, ErrFormat},
+		{func(m *Manifest) { m.ToID = 4 }, ErrFormat},
+		{func(m *Manifest) { m.NetworkID++ }, ErrNetwork},
+	} {
+		wrong := *m
+		item.change(&wrong)
+		buf.Reset()
+		require.NoError(t, tcpserver.SendRequest(&wrong, buf))
+		_, err = readManifest(buf)
+		assert.Equal(t, item.err, err)
+	}
+
+	_, err = readManifest(bytes.NewBufferString("GB"))
+	assert.Equal(t, ErrFormat, err)
+}
@@ -46,6 +46,32 @@ type StatsDConfig struct {
 type CentrifugoConfig struct {
 	Secret string
 	URL    string
+	Cache  bool // publishes the changes of pages, menus, parameters and languages to the cache channels
+}
+
+// TracingConfig is the endpoint of the collector of the block processing spans
+type TracingConfig struct {
+	Endpoint    string // Zipkin compatible URL, the tracing is off if it's empty
+	ServiceName string
+}
+
+// KeyPassConfig is the source of the passphrase of the encrypted node private key.
+// The passphrase is asked in the terminal if neither of them is specified
+type KeyPassConfig struct {
+	Env  string // the name of the environment variable
+	File string // the path of the file
+}
+
+// SignerConfig is the signer of the blocks of the node. The node private key file is used by default.
+// The remote signer is the service which keeps the node key, for example in HSM. It's called
+// by HTTPS with the client certificate or by HTTP over the unix socket
+type SignerConfig struct {
+	Type     string // file or remote
+	Endpoint string // https://host:port or unix:///path/to/socket
+	Cert     string // the filepath of the client certificate
+	Key      string // the filepath of the private key of the client certificate
+	CA       string // the filepath of the CA certificate of the signer
+	Timeout  int64  // in milliseconds
 }
 
 // Syslog represents parameters of syslog
@@ -73,6 +99,46 @@ type TokenMovementConfig struct {
 	Subject  string
 }
 
+// APIUsageConfig is the statistics and the daily quotas of the API calls of the ecosystems
+type APIUsageConfig struct {
+	FlushPeriod int64            // the period of writing the counters to the database in seconds
+	DailyQuota  int64            // the daily quota of the API calls of every ecosystem, 0 is unlimited
+	Quotas      map[string]int64 // the daily quotas of the ecosystems by id, they override DailyQuota
+}
+
+// TxBudgetConfig is the budget of the failed transactions of the keys in the blocks which are generated
+// by the node. The keys which exceed it are deprioritized for the cooldown blocks, it isn't the consensus rule
+type TxBudgetConfig struct {
+	Blocks      int64 // the number of the last blocks which failures are counted
+	MaxFailures int64 // the maximum number of the failed transactions of the key in these blocks, 0 is unlimited
+	Cooldown    int64 // the number of the blocks in which the key is deprioritized
+}
+
+// ExportConfig limits the export of the tables through the API
+type ExportConfig struct {
+	MaxRows  int64 // the maximum number of the rows of one export, 0 is unlimited
+	Interval int64 // the minimum interval in seconds between the exports started by one key
+}
+
+// ConfirmationsConfig is the check of the blocks by the remote nodes
+type ConfirmationsConfig struct {
+	PeerTimeout int64 // the time in seconds to wait for the answer of the remote node
+	ForkWarning int64 // the number of the own blocks in a row which aren't confirmed by the quorum before the warning
+}
+
+// ArchiveConfig is the index of the historical states of the rows
+type ArchiveConfig struct {
+	Enabled bool  // the node keeps the states of the rows after every block
+	Chunk   int64 // the number of the old blocks which are indexed at once
+}
+
+// VDEQuotaConfig is the quotas of the execution of the contracts of VDE by every application
+type VDEQuotaConfig struct {
+	Window  int64 // the length of the sliding window of the quotas in seconds
+	MaxTime int64 // the maximum execution time of the contracts in the window in milliseconds, 0 is unlimited
+	MaxFuel int64 // the maximum fuel of the contracts in the window, 0 is unlimited
+}
+
 // GlobalConfig is storing all startup config as global struct
 type GlobalConfig struct {
 	KeyID        int64  `toml:"-"`
@@ -82,6 +148,7 @@ type GlobalConfig struct {
 	PrivateBlockchain bool
 	PidFilePath       string
 	LockFilePath      string
+	AdminSocket       string // the unix socket of the local admin interface
 	DataDir           string // application work dir (cwd by default)
 	KeysDir           string // place for private keys files: NodePrivateKey, PrivateKey
 	TempDir           string // temporary dir
@@ -90,8 +157,10 @@ type GlobalConfig struct {
 	TLSCert           string // TLSCert is a filepath of the fullchain of certificate.
 	TLSKey            string // TLSKey is a filepath of the private key.
 	RunningMode       string
+	VDEDebug          bool // VDEDebug enables the debug API of the contracts of VDE
 
 	MaxPageGenerationTime int64 // in milliseconds
+	IdempotencyTTL        int64 // the time in seconds while the idempotency keys of the transactions are kept
 
 	TCPServer HostPort
 	HTTP      HostPort
@@ -101,6 +170,15 @@ type GlobalConfig struct {
 	Centrifugo    CentrifugoConfig
 	Log           LogConfig
 	TokenMovement TokenMovementConfig
+	Tracing       TracingConfig
+	KeyPass       KeyPassConfig
+	Signer        SignerConfig
+	APIUsage      APIUsageConfig
+	TxBudget      TxBudgetConfig
+	Export        ExportConfig
+	Confirmations ConfirmationsConfig
+	Archive       ArchiveConfig
+	VDEQuota      VDEQuotaConfig
 
 	NodesAddr []string
 }
@@ -113,6 +191,14 @@ func (c *GlobalConfig) GetPidPath() string {
 	return c.PidFilePath
 }
 
+// GetAdminSocketPath returns path to the unix socket of the local admin interface
+func (c *GlobalConfig) GetAdminSocketPath() string {
+	if len(c.AdminSocket) == 0 {
+		return filepath.Join(c.DataDir, consts.DefaultAdminSocketFilename)
+	}
+	return c.AdminSocket
+}
+
 // LoadConfig from configFile
 // the function has side effect updating global var Config
 func LoadConfig(path string) error {
@@ -215,6 +301,10 @@ func FillRuntimePaths() error {
 		Config.LockFilePath = filepath.Join(Config.DataDir, consts.DefaultLockFilename)
 	}
 
+	if Config.AdminSocket == "" {
+		Config.AdminSocket = filepath.Join(Config.DataDir, consts.DefaultAdminSocketFilename)
+	}
+
 	return nil
 }
 
@@ -223,13 +313,13 @@ func FillRuntimeKey() error {
 	keyIDFileName := filepath.Join(Config.KeysDir, consts.KeyIDFilename)
 	keyIDBytes, err := ioutil.ReadFile(keyIDFileName)
 	if err != nil {
-		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": keyIDFileName}).Fatal("reading KeyID file")
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": keyIDFileName}).Error("reading KeyID file")
 		return err
 	}
 
 	Config.KeyID, err = strconv.ParseInt(string(keyIDBytes), 10, 64)
 	if err != nil {
-		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": string(keyIDBytes)}).Fatal("converting keyID to int")
+		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": string(keyIDBytes)}).Error("converting keyID to int")
 		return errors.New("converting keyID to int")
 	}
 
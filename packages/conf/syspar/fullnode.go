@@ -1,6 +1,7 @@
 package syspar
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -14,10 +15,14 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-const publicKeyLength = 64
+const (
+	publicKeyLength = 64
+	maxTitleLength  = 255
+)
 
 var (
 	errFullNodeInvalidValues = errors.New("Invalid values of the full_node parameter")
+	errFullNodeTitle         = fmt.Errorf("Title of the full node must be less than %d characters", maxTitleLength)
 )
 
 //because of PublicKey is byte
@@ -27,27 +32,57 @@ type fullNodeJSON struct {
 	KeyID      json.Number `json:"key_id"`
 	PublicKey  string      `json:"public_key"`
 	UnbanTime  json.Number `json:"unban_time,er"`
+	Title      string      `json:"title,omitempty"`
 }
 
-// FullNode is storing full node data
+// FullNode is storing full node data. TCPAddress is used by the nodes for the connections,
+// APIAddress is the address of the node which is shown to the clients
 type FullNode struct {
 	TCPAddress string
 	APIAddress string
 	KeyID      int64
 	PublicKey  []byte
 	UnbanTime  time.Time
+	Title      string
+}
+
+// legacyFullNode returns the node which is stored in the old form of the array
+// [tcp_address, api_address, key_id, public_key]
+func legacyFullNode(b []byte) (data fullNodeJSON, err error) {
+	list := make([]interface{}, 0)
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err = decoder.Decode(&list); err != nil {
+		return
+	}
+	if len(list) < 4 {
+		return data, errFullNodeInvalidValues
+	}
+	values := make([]string, len(list))
+	for i, item := range list {
+		values[i] = fmt.Sprint(item)
+	}
+	data.TCPAddress, data.APIAddress = values[0], values[1]
+	data.KeyID, data.PublicKey = json.Number(values[2]), values[3]
+	return
 }
 
 // UnmarshalJSON is custom json unmarshaller
 func (fn *FullNode) UnmarshalJSON(b []byte) (err error) {
 	data := fullNodeJSON{}
-	if err = json.Unmarshal(b, &data); err != nil {
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '[' {
+		data, err = legacyFullNode(b)
+	} else {
+		err = json.Unmarshal(b, &data)
+	}
+	if err != nil {
 		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err, "value": string(b)}).Error("Unmarshalling full nodes to json")
 		return err
 	}
 
 	fn.TCPAddress = data.TCPAddress
 	fn.APIAddress = data.APIAddress
+	fn.Title = data.Title
 	fn.KeyID = converter.StrToInt64(data.KeyID.String())
 
 	if fn.PublicKey, err = hex.DecodeString(data.PublicKey); err != nil {
@@ -70,6 +105,7 @@ func (fn *FullNode) MarshalJSON() ([]byte, error) {
 		KeyID:      json.Number(strconv.FormatInt(fn.KeyID, 10)),
 		PublicKey:  hex.EncodeToString(fn.PublicKey),
 		UnbanTime:  json.Number(strconv.FormatInt(fn.UnbanTime.Unix(), 10)),
+		Title:      fn.Title,
 	}
 
 	data, err := json.Marshal(jfn)
@@ -105,6 +141,10 @@ func (fn *FullNode) Validate() error {
 		return errFullNodeInvalidValues
 	}
 
+	if len([]rune(fn.Title)) > maxTitleLength {
+		return errFullNodeTitle
+	}
+
 	if err := validateURL(fn.APIAddress); err != nil {
 		return err
 	}
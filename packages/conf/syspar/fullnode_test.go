@@ -1,7 +1,9 @@
 package syspar
 
 import (
+	"encoding/hex"
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -44,3 +46,28 @@ func TestFullNode(t *testing.T) {
 		}
 	}
 }
+
+func TestFullNodeForms(t *testing.T) {
+	const public = `c1a9e7b2fb8cea2a272e183c3e27e2d59a3ebe613f51873a46885c9201160bd263ef43b583b631edd1284ab42483712fd2ccc40864fe9368115ceeee47a7c7d0`
+	var fs []FullNode
+	// the stored parameter of the old form is parsed as the objects
+	require.NoError(t, json.Unmarshal([]byte(`[["10.0.0.1:7078","https://node.example.com", 100, "`+public+`"],
+		{"tcp_address":"10.0.0.2:7078","api_address":"https://node2.example.com","key_id":"200","public_key":"`+public+`","title":"Node 2"}]`), &fs))
+	require.Len(t, fs, 2)
+	assert.Equal(t, `10.0.0.1:7078`, fs[0].TCPAddress)
+	assert.Equal(t, `https://node.example.com`, fs[0].APIAddress)
+	assert.Equal(t, int64(100), fs[0].KeyID)
+	assert.Equal(t, public, hex.EncodeToString(fs[0].PublicKey))
+	assert.Equal(t, `Node 2`, fs[1].Title)
+
+	data, err := json.Marshal(fs)
+	require.NoError(t, err)
+	var unfs []FullNode
+	require.NoError(t, json.Unmarshal(data, &unfs))
+	assert.Equal(t, fs, unfs)
+
+	var wrong []FullNode
+	assert.Error(t, json.Unmarshal([]byte(`[["10.0.0.1:7078","https://node.example.com"]]`), &wrong))
+	fs[1].Title = strings.Repeat(`t`, maxTitleLength+1)
+	assert.Equal(t, errFullNodeTitle, fs[1].Validate())
+}
@@ -0,0 +1,52 @@
+package syspar
+
+import (
+	"sync"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ChangeFunc is called with the previous and the new value of the changed system parameter
+type ChangeFunc func(old, new string)
+
+type change struct {
+	name     string
+	old, new string
+}
+
+var subscribers = struct {
+	sync.RWMutex
+	list map[string][]ChangeFunc
+}{list: make(map[string][]ChangeFunc)}
+
+// OnChange adds the callback which is called by SysUpdate when the value of the system parameter is changed.
+// The callbacks are called after the cache is updated, outside of the database transaction of SysUpdate,
+// so they get only the values and mustn't call SysUpdate
+func OnChange(name string, fn ChangeFunc) {
+	subscribers.Lock()
+	defer subscribers.Unlock()
+	subscribers.list[name] = append(subscribers.list[name], fn)
+}
+
+func notify(changes []change) {
+	for _, item := range changes {
+		subscribers.RLock()
+		list := subscribers.list[item.name]
+		subscribers.RUnlock()
+		for _, fn := range list {
+			callChange(fn, item)
+		}
+	}
+}
+
+// callChange calls the callback, the panic of one callback doesn't stop the others
+func callChange(fn ChangeFunc, item change) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.WithFields(log.Fields{"type": consts.PanicRecoveredError, "error": r, "name": item.name}).Error("recovered panic in syspar change callback")
+		}
+	}()
+	fn(item.old, item.new)
+}
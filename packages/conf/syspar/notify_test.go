@@ -0,0 +1,108 @@
+package syspar
+
+import (
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnChange(t *testing.T) {
+	table := []model.SystemParameter{
+		{Name: MaxTxCount, Value: `1000`},
+		{Name: FuelRate, Value: `[["1","100"]]`},
+		{Name: FullNodes, Value: `[{"tcp_address":"127.0.0.1:7078","api_address":"https://127.0.0.1:7079","key_id":"100","public_key":"c1a9e7b2fb8cea2a272e183c3e27e2d59a3ebe613f51873a46885c9201160bd263ef43b583b631edd1284ab42483712fd2ccc40864fe9368115ceeee47a7c7d0"}]`},
+	}
+	defer func(get func(*model.DbTransaction) ([]model.SystemParameter, error)) {
+		getAllParameters = get
+	}(getAllParameters)
+	getAllParameters = func(*model.DbTransaction) ([]model.SystemParameter, error) {
+		return append([]model.SystemParameter{}, table...), nil
+	}
+
+	var calls [][2]string
+	OnChange(MaxTxCount, func(old, new string) {
+		calls = append(calls, [2]string{old, new})
+	})
+	// the panic of the callback doesn't stop the other callbacks
+	OnChange(MaxTxCount, func(old, new string) {
+		panic(`callback`)
+	})
+	var fuelCalls int
+	OnChange(FuelRate, func(old, new string) {
+		fuelCalls++
+	})
+
+	require.NoError(t, SysUpdate(nil))
+	assert.Equal(t, [][2]string{{``, `1000`}}, calls)
+	assert.Equal(t, 1, fuelCalls)
+	assert.Equal(t, `100`, GetFuelRate(1))
+	assert.Len(t, GetNodes(), 1)
+
+	// the unchanged parameters don't call the callbacks
+	require.NoError(t, SysUpdate(nil))
+	assert.Len(t, calls, 1)
+	assert.Equal(t, 1, fuelCalls)
+
+	table[0].Value = `500`
+	table[1].Value = `[["1","200"]]`
+	require.NoError(t, SysUpdate(nil))
+	assert.Equal(t, [][2]string{{``, `1000`}, {`1000`, `500`}}, calls)
+	assert.Equal(t, 2, fuelCalls)
+	assert.Equal(t, `200`, GetFuelRate(1))
+	assert.Equal(t, 500, GetMaxTxCount())
+}
+
+func TestPendingValue(t *testing.T) {
+	table := []model.SystemParameter{
+		{Name: MaxBlockSize, Value: `1000`, PendingValue: `2000`, ActivationBlock: 10},
+	}
+	defer func(get func(*model.DbTransaction) ([]model.SystemParameter, error)) {
+		getAllParameters = get
+	}(getAllParameters)
+	getAllParameters = func(*model.DbTransaction) ([]model.SystemParameter, error) {
+		return append([]model.SystemParameter{}, table...), nil
+	}
+	defer SetBlockID(0)
+
+	var calls [][2]string
+	OnChange(MaxBlockSize, func(old, new string) {
+		calls = append(calls, [2]string{old, new})
+	})
+
+	SetBlockID(8)
+	require.NoError(t, SysUpdate(nil))
+	assert.Equal(t, int64(1000), GetMaxBlockSize())
+	pending, ok := GetPending(MaxBlockSize)
+	require.True(t, ok)
+	assert.Equal(t, PendingValue{Value: `2000`, ActivationBlock: 10}, pending)
+
+	// the last scheduled value replaces the previous pending value
+	table[0].PendingValue = `3000`
+	require.NoError(t, SysUpdate(nil))
+	assert.Equal(t, int64(1000), GetMaxBlockSize())
+
+	SetBlockID(9)
+	assert.Equal(t, int64(1000), GetMaxBlockSize())
+	SetBlockID(10)
+	assert.Equal(t, int64(3000), GetMaxBlockSize())
+	SetBlockID(11)
+	assert.Equal(t, int64(3000), GetMaxBlockSize())
+
+	// the rollback of the blocks switches the value back
+	SetBlockID(9)
+	assert.Equal(t, int64(1000), GetMaxBlockSize())
+
+	// the rollback of the scheduling transaction removes the pending value
+	SetBlockID(10)
+	table[0].PendingValue, table[0].ActivationBlock = ``, 0
+	require.NoError(t, SysUpdate(nil))
+	assert.Equal(t, int64(1000), GetMaxBlockSize())
+	_, ok = GetPending(MaxBlockSize)
+	assert.False(t, ok)
+
+	assert.Equal(t, [][2]string{{``, `1000`}, {`1000`, `3000`}, {`3000`, `1000`}, {`1000`, `3000`},
+		{`3000`, `1000`}}, calls)
+}
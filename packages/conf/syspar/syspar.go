@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/GenesisKernel/go-genesis/packages/conf"
@@ -54,6 +55,10 @@ const (
 	MaxBlockFuel = `max_fuel_block`
 	// MaxTxFuel is the maximum fuel of the transaction
 	MaxTxFuel = `max_fuel_tx`
+	// MaxTxMemory is the maximum size of memory which can be used by the contract during the execution
+	MaxTxMemory = `max_tx_memory`
+	// MaxCallDepth is the maximum depth of the nested calls of functions and contracts
+	MaxCallDepth = `max_call_depth`
 	// MaxTxCount is the maximum count of the transactions
 	MaxTxCount = `max_tx_count`
 	// MaxBlockGenerationTime is the time limit for block generation (in ms)
@@ -78,77 +83,194 @@ const (
 	NodeBanTime = `node_ban_time`
 	// LocalNodeBanTime is value of local ban time for bad nodes (in ms)
 	LocalNodeBanTime = `local_node_ban_time`
+	// EcosystemOverrides is the list of the parameters which can be overridden by ecosystems
+	EcosystemOverrides = `ecosystem_overrides`
+	// StrictTxReplay requires the expiration and the nonce in every contract transaction
+	StrictTxReplay = `strict_tx_replay`
+	// DisableForSignV1 rejects the transactions which are signed in the positional format of version 1
+	DisableForSignV1 = `disable_forsign_v1`
+	// MaxAlterColumnRows is the maximum number of the rows of the table which column type can be changed
+	MaxAlterColumnRows = `max_alter_column_rows`
+	// ConfirmationQuorum is the number of the remote nodes which must have the same block to confirm it
+	ConfirmationQuorum = `confirmation_quorum`
+	// FounderAmount is the amount of the tokens of the founder which are created by the first block
+	FounderAmount = `founder_amount`
+	// FormatContracts stores the sources of the new and the edited contracts in the canonical format
+	FormatContracts = `format_contracts`
 )
 
+// FuelItem is the item of fuel_rate parameter, the fee of the ecosystem is paid by the tokens
+// of TokenEcosystem. The item is [ecosystem, rate] or [ecosystem, rate, token ecosystem]
+type FuelItem struct {
+	Ecosystem      int64
+	Rate           string
+	TokenEcosystem int64
+}
+
+// PendingValue is the value of the system parameter which becomes active at the activation block
+type PendingValue struct {
+	Value           string
+	ActivationBlock int64
+}
+
 var (
 	cache = map[string]string{
 		BlockchainURL: "https://raw.githubusercontent.com/egaas-blockchain/egaas-blockchain.github.io/master/testnet_blockchain",
 	}
 	nodes           = make(map[int64]*FullNode)
 	nodesByPosition = make([]*FullNode, 0)
-	fuels           = make(map[int64]string)
+	fuels           = make(map[int64]FuelItem)
 	wallets         = make(map[int64]string)
 	mutex           = &sync.RWMutex{}
+	// updateMutex keeps the order of the notifications of the concurrent updates
+	updateMutex = &sync.Mutex{}
+	// getAllParameters reads the table of the system parameters
+	getAllParameters = model.GetAllSystemParameters
+
+	// values are the stored values of the parameters, cache has the values which are active at blockID
+	values  = make(map[string]string)
+	pending = make(map[string]PendingValue)
+	blockID int64
 
 	firstBlockData    *consts.FirstBlock
 	errFirstBlockData = errors.New("Failed to get data of the first block")
 )
 
-// SysUpdate reloads/updates values of system parameters
+func init() {
+	OnChange(FullNodes, func(_, value string) {
+		// the list of nodes isn't cleared, so the node of the first block remains
+		if len(value) > 0 {
+			updateNodes(value)
+		}
+	})
+	OnChange(FuelRate, func(_, value string) {
+		if res, err := getFuelRates(value); err == nil {
+			mutex.Lock()
+			fuels = res
+			mutex.Unlock()
+		}
+	})
+	OnChange(CommissionWallet, func(_, value string) {
+		if res, err := getParams(value); err == nil {
+			list := make(map[int64]string, len(res))
+			for ecosystem, item := range res {
+				list[ecosystem] = item[0]
+			}
+			mutex.Lock()
+			wallets = list
+			mutex.Unlock()
+		}
+	})
+}
+
+// SysUpdate reloads/updates values of system parameters and calls the callbacks of the changed parameters
 func SysUpdate(dbTransaction *model.DbTransaction) error {
-	var err error
-	systemParameters, err := model.GetAllSystemParameters(dbTransaction)
+	updateMutex.Lock()
+	defer updateMutex.Unlock()
+
+	systemParameters, err := getAllParameters(dbTransaction)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting all system parameters")
 		return err
 	}
+	changes := make([]change, 0)
 	mutex.Lock()
-	defer mutex.Unlock()
 	for _, param := range systemParameters {
-		cache[param.Name] = param.Value
+		values[param.Name] = param.Value
+		if param.ActivationBlock > 0 {
+			pending[param.Name] = PendingValue{Value: param.PendingValue, ActivationBlock: param.ActivationBlock}
+		} else {
+			delete(pending, param.Name)
+		}
+		changes = updateValue(changes, param.Name)
 	}
-	if len(cache[FullNodes]) > 0 {
-		if err = updateNodes(); err != nil {
-			return err
+	mutex.Unlock()
+
+	notify(changes)
+	return nil
+}
+
+// SetBlockID sets the id of the processed block and activates the pending values of this block.
+// The values are switched back if the block id is less than the activation block after the rollback
+func SetBlockID(id int64) {
+	updateMutex.Lock()
+	defer updateMutex.Unlock()
+
+	changes := make([]change, 0)
+	mutex.Lock()
+	blockID = id
+	names := make([]string, 0, len(pending))
+	for name := range pending {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		changes = updateValue(changes, name)
+	}
+	mutex.Unlock()
+
+	notify(changes)
+}
+
+// updateValue sets the value of the parameter which is active at blockID and appends the change
+func updateValue(changes []change, name string) []change {
+	value := values[name]
+	if item, ok := pending[name]; ok && item.ActivationBlock <= blockID {
+		value = item.Value
+	}
+	if old := cache[name]; old != value {
+		changes = append(changes, change{name: name, old: old, new: value})
+	}
+	cache[name] = value
+	return changes
+}
+
+// getFuelRates parses the list of the fuel rates, the token ecosystem of the rate is optional
+func getFuelRates(value string) (map[int64]FuelItem, error) {
+	list, err := getParams(value)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]FuelItem, len(list))
+	for ecosystem, item := range list {
+		rate := FuelItem{Ecosystem: ecosystem, Rate: item[0], TokenEcosystem: ecosystem}
+		if len(item) > 1 && converter.StrToInt64(item[1]) > 0 {
+			rate.TokenEcosystem = converter.StrToInt64(item[1])
 		}
+		res[ecosystem] = rate
 	}
-	getParams := func(name string) (map[int64]string, error) {
-		res := make(map[int64]string)
-		if len(cache[name]) > 0 {
-			ifuels := make([][]string, 0)
-			err = json.Unmarshal([]byte(cache[name]), &ifuels)
-			if err != nil {
-				log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling params from json")
-				return res, err
-			}
-			for _, item := range ifuels {
-				if len(item) < 2 {
-					continue
-				}
-				res[converter.StrToInt64(item[0])] = item[1]
+	return res, nil
+}
+
+// getParams parses the list of the values of the ecosystems
+func getParams(value string) (map[int64][]string, error) {
+	res := make(map[int64][]string)
+	if len(value) > 0 {
+		ifuels := make([][]string, 0)
+		if err := json.Unmarshal([]byte(value), &ifuels); err != nil {
+			log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling params from json")
+			return res, err
+		}
+		for _, item := range ifuels {
+			if len(item) < 2 {
+				continue
 			}
+			res[converter.StrToInt64(item[0])] = item[1:]
 		}
-		return res, nil
 	}
-	fuels, err = getParams(FuelRate)
-	wallets, err = getParams(CommissionWallet)
-
-	return err
+	return res, nil
 }
 
-func updateNodes() (err error) {
-	nodes = make(map[int64]*FullNode)
-	nodesByPosition = make([]*FullNode, 0)
-
+func updateNodes(value string) error {
 	items := make([]*FullNode, 0)
-	if len(cache[FullNodes]) > 0 {
-		err = json.Unmarshal([]byte(cache[FullNodes]), &items)
-		if err != nil {
-			log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err, "v": cache[FullNodes]}).Error("unmarshalling full nodes from json")
-			return err
-		}
+	if err := json.Unmarshal([]byte(value), &items); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err, "v": value}).Error("unmarshalling full nodes from json")
+		return err
 	}
 
+	mutex.Lock()
+	defer mutex.Unlock()
+	nodes = make(map[int64]*FullNode)
 	nodesByPosition = items
 	for _, item := range items {
 		nodes[item.KeyID] = item
@@ -207,7 +329,8 @@ func GetNumberOfNodes() int64 {
 func GetNumberOfNodesFromDB(transaction *model.DbTransaction) int64 {
 	sp := &model.SystemParameter{}
 	sp.GetTransaction(transaction, FullNodes)
-	var fullNodes []map[string]interface{}
+	// the nodes can be stored as the objects or as the arrays of the old form
+	var fullNodes []json.RawMessage
 	if len(sp.Value) > 0 {
 		if err := json.Unmarshal([]byte(sp.Value), &fullNodes); err != nil {
 			log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err, "value": sp.Value}).Error("unmarshalling fullnodes from JSON")
@@ -328,14 +451,28 @@ func GetBlockchainURL() string {
 	return SysString(BlockchainURL)
 }
 
-// GetFuelRate is returning fuel rate
-func GetFuelRate(ecosystem int64) string {
+// GetFuel returns the fuel rate of the ecosystem or the fuel rate of the ecosystem 1
+// if the ecosystem isn't in the list
+func GetFuel(ecosystem int64) FuelItem {
 	mutex.RLock()
 	defer mutex.RUnlock()
 	if ret, ok := fuels[ecosystem]; ok {
 		return ret
 	}
-	return ``
+	return fuels[1]
+}
+
+// GetFuelRate is returning fuel rate, the rate of the ecosystem 1 is used if the ecosystem has no rate
+func GetFuelRate(ecosystem int64) string {
+	return GetFuel(ecosystem).Rate
+}
+
+// HasFuelRate returns true if the ecosystem has its own fuel rate
+func HasFuelRate(ecosystem int64) bool {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	_, ok := fuels[ecosystem]
+	return ok
 }
 
 // GetCommissionWallet is returns commission wallet
@@ -363,6 +500,16 @@ func GetMaxTxFuel() int64 {
 	return converter.StrToInt64(SysString(MaxTxFuel))
 }
 
+// GetMaxTxMemory is returns max tx memory
+func GetMaxTxMemory() int64 {
+	return converter.StrToInt64(SysString(MaxTxMemory))
+}
+
+// GetMaxCallDepth is returns max call depth
+func GetMaxCallDepth() int64 {
+	return converter.StrToInt64(SysString(MaxCallDepth))
+}
+
 // GetMaxBlockGenerationTime is returns max block generation time (in ms)
 func GetMaxBlockGenerationTime() int64 {
 	return converter.StrToInt64(SysString(MaxBlockGenerationTime))
@@ -443,6 +590,21 @@ func GetRbBlocks1() int64 {
 	return SysInt64(RbBlocks1)
 }
 
+// GetConfirmationQuorum returns the number of the remote nodes which must have the same block
+// to confirm it, 0 doesn't require the confirmations
+func GetConfirmationQuorum() int64 {
+	return SysInt64Def(ConfirmationQuorum, consts.MIN_CONFIRMED_NODES)
+}
+
+// GetPending returns the scheduled value of the system parameter. The value remains pending
+// after the activation till the next change of the parameter
+func GetPending(name string) (PendingValue, bool) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	item, ok := pending[name]
+	return item, ok
+}
+
 // HasSys returns boolean whether this system parameter exists
 func HasSys(name string) bool {
 	mutex.RLock()
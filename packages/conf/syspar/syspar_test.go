@@ -0,0 +1,32 @@
+package syspar
+
+import (
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuelRate(t *testing.T) {
+	table := []model.SystemParameter{
+		{Name: FuelRate, Value: `[["1","100"],["2","50","3"],["4","70"]]`},
+	}
+	defer func(get func(*model.DbTransaction) ([]model.SystemParameter, error)) {
+		getAllParameters = get
+	}(getAllParameters)
+	getAllParameters = func(*model.DbTransaction) ([]model.SystemParameter, error) {
+		return append([]model.SystemParameter{}, table...), nil
+	}
+
+	require.NoError(t, SysUpdate(nil))
+	assert.Equal(t, FuelItem{Ecosystem: 2, Rate: `50`, TokenEcosystem: 3}, GetFuel(2))
+	assert.Equal(t, FuelItem{Ecosystem: 4, Rate: `70`, TokenEcosystem: 4}, GetFuel(4))
+	assert.True(t, HasFuelRate(4))
+
+	// the ecosystem without the rate pays by the rate of the ecosystem 1
+	assert.False(t, HasFuelRate(5))
+	assert.Equal(t, `100`, GetFuelRate(5))
+	assert.Equal(t, FuelItem{Ecosystem: 1, Rate: `100`, TokenEcosystem: 1}, GetFuel(5))
+}
@@ -0,0 +1,146 @@
+package syspar
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+// unknownValue is the reported value of the unknown parameter
+const unknownValue = "\x00"
+
+// reported has the wrong values which have been logged
+var reported = struct {
+	sync.Mutex
+	list map[string]string
+}{list: make(map[string]string)}
+
+// sysValue returns the value of the parameter, the unknown parameter is logged once
+func sysValue(name string) (string, bool) {
+	mutex.RLock()
+	value, ok := cache[name]
+	mutex.RUnlock()
+	if !ok {
+		report(name, unknownValue, consts.NotFound, "unknown system parameter, using default")
+	}
+	return value, ok
+}
+
+// report logs the wrong value of the parameter once until the value is changed
+func report(name, value, logType, msg string) {
+	reported.Lock()
+	defer reported.Unlock()
+	if prev, ok := reported.list[name]; ok && prev == value {
+		return
+	}
+	reported.list[name] = value
+	fields := log.Fields{"type": logType, "name": name}
+	if value != unknownValue {
+		fields["value"] = value
+	}
+	log.WithFields(fields).Warning(msg)
+}
+
+func malformed(name, value string) {
+	report(name, value, consts.ConversionError, "malformed system parameter, using default")
+}
+
+// SysInt64Def returns the integer value of the system parameter or def if it's unknown or malformed
+func SysInt64Def(name string, def int64) int64 {
+	value, ok := sysValue(name)
+	if !ok {
+		return def
+	}
+	ret, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		malformed(name, value)
+		return def
+	}
+	return ret
+}
+
+// SysMoney returns the non-negative amount of the system parameter or zero if it's unknown or malformed
+func SysMoney(name string) decimal.Decimal {
+	value, ok := sysValue(name)
+	if !ok {
+		return decimal.Zero
+	}
+	ret, err := decimal.NewFromString(strings.TrimSpace(value))
+	if err != nil || ret.Sign() < 0 {
+		malformed(name, value)
+		return decimal.Zero
+	}
+	return ret
+}
+
+// SysBool returns the boolean value of the system parameter or false if it's unknown or malformed.
+// The value can be 1, 0, true or false
+func SysBool(name string) bool {
+	value, ok := sysValue(name)
+	if !ok {
+		return false
+	}
+	ret, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		malformed(name, value)
+		return false
+	}
+	return ret
+}
+
+// SysStringList returns the comma separated values of the system parameter without the empty items
+func SysStringList(name string) []string {
+	value, _ := sysValue(name)
+	ret := make([]string, 0)
+	for _, item := range strings.Split(value, `,`) {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}
+
+// knownParameters are the parameters of the constants of syspar
+var knownParameters = []string{NumberNodes, FuelRate, FullNodes, GapsBetweenBlocks, BlockchainURL,
+	MaxBlockSize, MaxTxSize, MaxForsignSize, MaxBlockFuel, MaxTxFuel, MaxTxMemory, MaxCallDepth, MaxTxCount,
+	MaxBlockGenerationTime, MaxColumns, MaxIndexes, MaxBlockUserTx, SizeFuel, CommissionWallet, RbBlocks1,
+	BlockReward, IncorrectBlocksPerDay, NodeBanTime, LocalNodeBanTime, EcosystemOverrides,
+	StrictTxReplay, DisableForSignV1, MaxAlterColumnRows, ConfirmationQuorum,
+	FounderAmount, FormatContracts}
+
+// CheckParameters logs and returns the parameters which are used by the node but aren't in the table
+// of the system parameters. The names are checked with the constants of syspar
+func CheckParameters(transaction *model.DbTransaction, names ...string) ([]string, error) {
+	list, err := getAllParameters(transaction)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting all system parameters")
+		return nil, err
+	}
+	stored := make(map[string]bool, len(list))
+	for _, item := range list {
+		stored[item.Name] = true
+	}
+	missing := make([]string, 0)
+	checked := make(map[string]bool)
+	for _, name := range append(append([]string{}, knownParameters...), names...) {
+		if checked[name] {
+			continue
+		}
+		checked[name] = true
+		if !stored[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		log.WithFields(log.Fields{"type": consts.NotFound, "name": name}).Warning("system parameter is missing in the table")
+	}
+	return missing, nil
+}
@@ -0,0 +1,70 @@
+package syspar
+
+import (
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// warningHook counts the warnings about the system parameters
+type warningHook map[string]int
+
+func (h warningHook) Levels() []log.Level {
+	return []log.Level{log.WarnLevel}
+}
+
+func (h warningHook) Fire(entry *log.Entry) error {
+	h[entry.Data["name"].(string)]++
+	return nil
+}
+
+func TestTypedGetters(t *testing.T) {
+	table := []model.SystemParameter{
+		{Name: `test_int`, Value: `15`},
+		{Name: `test_bad_int`, Value: `15a`},
+		{Name: `test_money`, Value: `1000000000000000000000`},
+		{Name: `test_bool`, Value: `1`},
+		{Name: `test_list`, Value: `a, b,,c`},
+	}
+	defer func(get func(*model.DbTransaction) ([]model.SystemParameter, error)) {
+		getAllParameters = get
+	}(getAllParameters)
+	getAllParameters = func(*model.DbTransaction) ([]model.SystemParameter, error) {
+		return append([]model.SystemParameter{}, table...), nil
+	}
+	require.NoError(t, SysUpdate(nil))
+
+	hook := make(warningHook)
+	log.AddHook(hook)
+	defer func() { log.StandardLogger().Hooks = make(log.LevelHooks) }()
+
+	assert.Equal(t, int64(15), SysInt64Def(`test_int`, -1))
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, int64(-1), SysInt64Def(`test_bad_int`, -1))
+		assert.Equal(t, int64(-1), SysInt64Def(`test_unknown`, -1))
+	}
+	assert.Equal(t, `1000000000000000000000`, SysMoney(`test_money`).String())
+	assert.Equal(t, 0, SysMoney(`test_list`).Sign())
+	assert.True(t, SysBool(`test_bool`))
+	assert.False(t, SysBool(`test_int`))
+	assert.Equal(t, []string{`a`, `b`, `c`}, SysStringList(`test_list`))
+	assert.Empty(t, SysStringList(`test_unknown_list`))
+
+	// the wrong value is logged once until it's changed
+	assert.Equal(t, warningHook{`test_bad_int`: 1, `test_unknown`: 1, `test_list`: 1, `test_int`: 1,
+		`test_unknown_list`: 1}, hook)
+	table[1].Value = `16b`
+	require.NoError(t, SysUpdate(nil))
+	SysInt64Def(`test_bad_int`, -1)
+	assert.Equal(t, 2, hook[`test_bad_int`])
+
+	missing, err := CheckParameters(nil, `test_int`, `test_missing`)
+	require.NoError(t, err)
+	assert.Contains(t, missing, `test_missing`)
+	assert.Contains(t, missing, MaxBlockSize)
+	assert.NotContains(t, missing, `test_int`)
+}
@@ -21,7 +21,7 @@ import (
 )
 
 // VERSION is current version
-const VERSION = "0.9.6"
+const VERSION = "0.9.15"
 
 // BLOCK_VERSION is block version
 const BLOCK_VERSION = 1
@@ -38,6 +38,9 @@ const FounderAmount = 50000
 // EGS_DIGIT money_digit for EGS 1000000000000000000
 const EGS_DIGIT = 18
 
+// MaxTokenDecimals is the maximum number of the decimals of the ecosystem token
+const MaxTokenDecimals = 18
+
 // WAIT_CONFIRMED_NODES is used in confirmations
 const WAIT_CONFIRMED_NODES = 10
 
@@ -95,6 +98,9 @@ const DefaultNodesConnectDelay = 6
 
 const MaxTXAttempt = 10
 
+// TxErrorSize is the maximum length of the error text of the transaction status
+const TxErrorSize = 2048
+
 const (
 	TxTypeFirstBlock  = 1
 	TxTypeStopNetwork = 2
@@ -109,6 +115,9 @@ var TxTypes = map[int]string{
 	TxTypeStopNetwork: TxTypeParserStopNetwork,
 }
 
+// PaymentScheme is the scheme of the URI of the payment request like genesis:XXXX-...-XXXX?amount=1&ecosystem=1
+const PaymentScheme = "genesis"
+
 // ApiPath is the beginning of the api url
 var ApiPath = `/api/v2/`
 
@@ -124,6 +133,9 @@ const DefaultPidFilename = "go-genesis.pid"
 // DefaultLockFilename is default filename of lock file
 const DefaultLockFilename = "go-genesis.lock"
 
+// DefaultAdminSocketFilename is default filename of the unix socket of the local admin interface
+const DefaultAdminSocketFilename = "go-genesis.sock"
+
 // FirstBlockFilename name of first block binary file
 const FirstBlockFilename = "1block"
 
@@ -145,6 +157,15 @@ const KeyIDFilename = "KeyID"
 // RollbackResultFilename rollback result file
 const RollbackResultFilename = "rollback_result"
 
+// FastSyncFilename is the file with the best known block id
+const FastSyncFilename = "fast_sync"
+
+// GenerationPausedFilename is the flag file of the block generation paused by the operator
+const GenerationPausedFilename = "generation_paused"
+
+// ContractsCacheDirname is the directory of the cached byte-code of contracts
+const ContractsCacheDirname = "contracts_cache"
+
 // FromToPerDayLimit day limit token transfer between accounts
 const FromToPerDayLimit = 10000
 
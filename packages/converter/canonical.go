@@ -0,0 +1,210 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+)
+
+const hexDigits = "0123456789abcdef"
+
+var (
+	decimalType = reflect.TypeOf(decimal.Decimal{})
+	numberType  = reflect.TypeOf(json.Number(""))
+)
+
+// CanonicalJSON returns the canonical JSON encoding of the value. The encoding of the same data is
+// always the same, so it can be hashed and signed. The rules are:
+//   - the keys of the objects are sorted by bytes, there are no spaces between the tokens;
+//   - only '"', '\' and the control characters of the strings are escaped, the control characters
+//     are escaped as \b, \f, \n, \r, \t or \u00xx, the strings must be valid UTF-8;
+//   - the integers are written in the decimal form, the floats are written in the shortest form
+//     without the exponent, NaN and the infinities are errors;
+//   - decimal.Decimal is written as the string, json.Number is written as is.
+//
+// The maps must have the string keys, the structs and the byte slices aren't supported
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() {
+		buf.WriteString(`null`)
+		return nil
+	}
+	switch v.Type() {
+	case decimalType:
+		return writeCanonicalString(buf, v.Interface().(decimal.Decimal).String())
+	case numberType:
+		num := v.String()
+		if _, err := strconv.ParseFloat(num, 64); err != nil {
+			return fmt.Errorf("invalid number %q", num)
+		}
+		buf.WriteString(num)
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			buf.WriteString(`null`)
+			return nil
+		}
+		return writeCanonical(buf, v.Elem())
+	case reflect.Bool:
+		buf.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		buf.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("unsupported float value %v", f)
+		}
+		bits := 64
+		if v.Kind() == reflect.Float32 {
+			bits = 32
+		}
+		buf.WriteString(strconv.FormatFloat(f, 'f', -1, bits))
+	case reflect.String:
+		return writeCanonicalString(buf, v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			buf.WriteString(`null`)
+			return nil
+		}
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		if v.IsNil() {
+			buf.WriteString(`null`)
+			return nil
+		}
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalString(buf, key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func writeCanonicalString(buf *bytes.Buffer, s string) error {
+	if !utf8.ValidString(s) {
+		return fmt.Errorf("invalid UTF-8 string %q", s)
+	}
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[c>>4])
+				buf.WriteByte(hexDigits[c&0xf])
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return nil
+}
+
+// DecodeJSONMap decodes the JSON object to the map of the strings. It accepts the canonical encoding
+// and the records which have been written before it by encoding/json or by hand. The values which
+// aren't strings are returned in the canonical encoding, null is returned as the empty string
+func DecodeJSONMap(data []byte) (map[string]string, error) {
+	var values map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&values); err != nil {
+		return nil, err
+	}
+	ret := make(map[string]string, len(values))
+	for key, val := range values {
+		switch v := val.(type) {
+		case nil:
+			ret[key] = ``
+		case string:
+			ret[key] = v
+		default:
+			out, err := CanonicalJSON(v)
+			if err != nil {
+				return nil, err
+			}
+			ret[key] = string(out)
+		}
+	}
+	return ret, nil
+}
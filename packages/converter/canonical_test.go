@@ -0,0 +1,89 @@
+package converter
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	// the golden outputs mustn't be changed, the records are stored and hashed in this encoding
+	cases := []struct {
+		value  interface{}
+		golden string
+	}{
+		{map[string]string{"Type": "NewTable", "Name": "my_table"}, `{"Name":"my_table","Type":"NewTable"}`},
+		{map[string]string{"Type": "ActivateContract", "Id": "12", "State": "1"},
+			`{"Id":"12","State":"1","Type":"ActivateContract"}`},
+		{map[string]string{"Type": "NewContract", "Value": "contract A {\n\tconditions {\n\t\t$a = \"<b>&</b>\\\"\n\t}\n}"},
+			`{"Type":"NewContract","Value":"contract A {\n\tconditions {\n\t\t$a = \"<b>&</b>\\\"\n\t}\n}"}`},
+		{map[string]string{"$v": "2", "value": "Привет мир", "amount": "1000000000000000000"},
+			`{"$v":"2","amount":"1000000000000000000","value":"Привет` + " " + `мир"}`},
+		{map[string]string{"b": "\x00\x1f\x7f\b\f\r"}, `{"b":"\u0000\u001f` + "\x7f" + `\b\f\r"}`},
+		{map[string]interface{}{
+			"int":     int64(-9007199254740993),
+			"uint":    uint64(18446744073709551615),
+			"float":   0.1,
+			"big":     1e21,
+			"small":   1.5e-7,
+			"decimal": decimal.New(12345, -2),
+			"number":  json.Number("1e3"),
+			"bool":    true,
+			"null":    nil,
+			"list":    []interface{}{"a", int64(1), []string{}, map[string]int{"z": 1, "a": 2}},
+		}, `{"big":1000000000000000000000,"bool":true,"decimal":"123.45","float":0.1,` +
+			`"int":-9007199254740993,"list":["a",1,[],{"a":2,"z":1}],"null":null,"number":1e3,` +
+			`"small":0.00000015,"uint":18446744073709551615}`},
+		{[]string(nil), `null`},
+		{map[string]string{}, `{}`},
+	}
+	for _, v := range cases {
+		out, err := CanonicalJSON(v.value)
+		require.NoError(t, err, v.golden)
+		assert.Equal(t, v.golden, string(out))
+
+		var check interface{}
+		assert.NoError(t, json.Unmarshal(out, &check), v.golden)
+	}
+
+	for _, wrong := range []interface{}{math.NaN(), math.Inf(1), []byte("data"), "\xff",
+		map[int]string{1: "a"}, struct{}{}, json.Number("1a")} {
+		_, err := CanonicalJSON(wrong)
+		assert.Error(t, err, wrong)
+	}
+}
+
+func TestDecodeJSONMap(t *testing.T) {
+	cases := []struct {
+		data   string
+		values map[string]string
+	}{
+		// the canonical records
+		{`{"Name":"my_table","Type":"NewTable"}`, map[string]string{"Type": "NewTable", "Name": "my_table"}},
+		{`{"$v":"2","value":"<b>"}`, map[string]string{"$v": "2", "value": "<b>"}},
+		// the records which have been written by hand and by encoding/json
+		{`{"Type":"NewColumn","TableName": "my_table", "Name": "my_column" }`,
+			map[string]string{"Type": "NewColumn", "TableName": "my_table", "Name": "my_column"}},
+		{`{"Type": "ActivateContract", "Id": "12", "State": "1"}`,
+			map[string]string{"Type": "ActivateContract", "Id": "12", "State": "1"}},
+		{`{"Value":"<b>"}`, map[string]string{"Value": "<b>"}},
+		// the records of jsonb and the values which aren't strings
+		{`{"id": 12, "flag": false, "empty": null, "list": [1, "a"], "map": {"z": 1, "a": 2.50}}`,
+			map[string]string{"id": "12", "flag": "false", "empty": "", "list": `[1,"a"]`,
+				"map": `{"a":2.50,"z":1}`}},
+	}
+	for _, v := range cases {
+		values, err := DecodeJSONMap([]byte(v.data))
+		require.NoError(t, err, v.data)
+		assert.Equal(t, v.values, values)
+	}
+
+	for _, wrong := range []string{``, `[]`, `{"a":}`, `"a"`} {
+		_, err := DecodeJSONMap([]byte(wrong))
+		assert.Error(t, err, wrong)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/crc64"
 	"math"
 	"reflect"
 	"regexp"
@@ -127,6 +129,9 @@ func DecodeLength(buf *[]byte) (ret int64, err error) {
 	length := (*buf)[0]
 	if (length & 0x80) != 0 {
 		length &= 0x7F
+		if length > 8 {
+			return 0, fmt.Errorf(`wrong length %d of encoded length`, length)
+		}
 		if len(*buf) < int(length+1) {
 			log.WithFields(log.Fields{"data_length": len(*buf), "length": int(length + 1)}).Error("length of data is smaller then encoded length")
 			return 0, fmt.Errorf(`input slice has small size`)
@@ -156,6 +161,9 @@ func DecodeLengthBuf(buf *bytes.Buffer) (int, error) {
 	}
 
 	length &= 0x7F
+	if length > 8 {
+		return 0, fmt.Errorf(`wrong length %d of encoded length`, length)
+	}
 	if buf.Len() < int(length) {
 		log.WithFields(log.Fields{"data_length": buf.Len(), "length": int(length), "type": consts.UnmarshallingError}).Error("length of data is smaller then encoded length")
 		return 0, fmt.Errorf(`input slice has small size`)
@@ -646,6 +654,15 @@ func StrToInt64(s string) int64 {
 	return int64
 }
 
+// StrToInt64E converts string to int64, unlike StrToInt64 it returns the error if s isn't an integer
+func StrToInt64E(s string) (int64, error) {
+	ret, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf(`%s is not a valid integer`, s)
+	}
+	return ret, nil
+}
+
 // BytesToInt64 converts []bytes to int64
 func BytesToInt64(s []byte) int64 {
 	int64, _ := strconv.ParseInt(string(s), 10, 64)
@@ -731,21 +748,41 @@ func EncodeLengthPlusData(idata interface{}) []byte {
 	return append(EncodeLength(int64(len(data))), data...)
 }
 
+// ErrAddressChecksum is returned if the check digit of the address is wrong
+var ErrAddressChecksum = errors.New("invalid address checksum")
+
+var addressTable = crc64.MakeTable(crc64.ECMA)
+
+// AddressToChecksumString converts int64 address to the checksummed apla address as XXXX-...-XXXX-C.
+// The additional check digit C is calculated by CRC64 of the digits of the address
+func AddressToChecksumString(address int64) string {
+	ret := AddressToString(address)
+	return ret + `-` + strconv.Itoa(addressCheckDigit([]byte(strings.Replace(ret, `-`, ``, -1))))
+}
+
+func addressCheckDigit(val []byte) int {
+	return int(crc64.Checksum(val, addressTable) % 10)
+}
+
 // StringToAddress converts string apla address to int64 address. The input address can be a positive or negative
-// number, or apla address in XXXX-...-XXXX format. Returns 0 when error occurs.
+// number, apla address in XXXX-...-XXXX format or checksummed one in XXXX-...-XXXX-C format.
+// Returns 0 when error occurs.
 func StringToAddress(address string) (result int64) {
-	var (
-		err error
-		ret uint64
-	)
+	result, _ = ParseAddress(address)
+	return
+}
+
+// ParseAddress converts string apla address to int64 address like StringToAddress but returns the error
+// if the address is wrong. ErrAddressChecksum is returned if any check digit of the address doesn't match
+func ParseAddress(address string) (int64, error) {
 	if len(address) == 0 {
-		return 0
+		return 0, errors.New("address is empty")
 	}
+	source := address
 	if address[0] == '-' {
-		var id int64
-		id, err = strconv.ParseInt(address, 10, 64)
+		id, err := strconv.ParseInt(address, 10, 64)
 		if err != nil {
-			return 0
+			return 0, fmt.Errorf("wrong address %s", source)
 		}
 		address = strconv.FormatUint(uint64(id), 10)
 	}
@@ -754,17 +791,26 @@ func StringToAddress(address string) (result int64) {
 	}
 
 	val := []byte(strings.Replace(address, `-`, ``, -1))
+	if len(val) == 21 {
+		if val[20] < '0' || val[20] > '9' {
+			return 0, fmt.Errorf("wrong address %s", source)
+		}
+		if addressCheckDigit(val[:20]) != int(val[20]-'0') {
+			return 0, ErrAddressChecksum
+		}
+		val = val[:20]
+	}
 	if len(val) != 20 {
-		return
+		return 0, fmt.Errorf("wrong length of address %s", source)
 	}
-	if ret, err = strconv.ParseUint(string(val), 10, 64); err != nil {
-		return 0
+	ret, err := strconv.ParseUint(string(val), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("wrong address %s", source)
 	}
 	if checkSum(val[:len(val)-1]) != int(val[len(val)-1]-'0') {
-		return 0
+		return 0, ErrAddressChecksum
 	}
-	result = int64(ret)
-	return
+	return int64(ret), nil
 }
 
 // CheckSum calculates the 0-9 check sum of []byte
@@ -787,12 +833,24 @@ func checkSum(val []byte) int {
 
 // EGSMoney converts qEGS to EGS. For example, 123455000000000000000 => 123.455
 func EGSMoney(money string) string {
-	digit := consts.EGS_DIGIT
-	if len(money) < digit+1 {
-		money = strings.Repeat(`0`, digit+1-len(money)) + money
+	return FormatMoney(money, consts.EGS_DIGIT)
+}
+
+// FormatMoney converts the amount of the token to the money with the specified number of the decimals.
+// For example, 123455 with 3 decimals => 123.455
+func FormatMoney(money string, decimals int) string {
+	var sign string
+	if strings.HasPrefix(money, `-`) {
+		sign, money = `-`, money[1:]
+	}
+	if decimals <= 0 {
+		return sign + money
+	}
+	if len(money) < decimals+1 {
+		money = strings.Repeat(`0`, decimals+1-len(money)) + money
 	}
-	money = money[:len(money)-digit] + `.` + money[len(money)-digit:]
-	return strings.TrimRight(strings.TrimRight(money, `0`), `.`)
+	money = money[:len(money)-decimals] + `.` + money[len(money)-decimals:]
+	return sign + strings.TrimRight(strings.TrimRight(money, `0`), `.`)
 }
 
 // EscapeForJSON replaces quote to slash and quote
@@ -967,6 +1025,15 @@ func RoundWithoutPrecision(num float64) int64 {
 	return int64(num + math.Copysign(0.5, num))
 }
 
+// ValueToIntE converts interface (string or int64) to int64 like ValueToInt,
+// but the empty string and nil aren't converted to zero
+func ValueToIntE(v interface{}) (int64, error) {
+	if val, ok := v.(string); v == nil || (ok && len(val) == 0) {
+		return 0, errors.New(`empty value is not a valid integer`)
+	}
+	return ValueToInt(v)
+}
+
 // ValueToInt converts interface (string or int64) to int64
 func ValueToInt(v interface{}) (ret int64, err error) {
 	switch val := v.(type) {
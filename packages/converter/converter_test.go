@@ -0,0 +1,68 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAddress(t *testing.T) {
+	const (
+		keyID   = int64(-7097400992759152070)
+		address = `1134-9343-0809-5039-9546`
+	)
+	assert.Equal(t, address, AddressToString(keyID))
+	checksummed := AddressToChecksumString(keyID)
+	require.Len(t, checksummed, len(address)+2)
+	assert.Equal(t, address+`-`, checksummed[:len(address)+1])
+
+	// the legacy and checksummed addresses
+	for _, input := range []string{address, `11349343080950399546`, `-7097400992759152070`, checksummed,
+		checksummed[:len(address)] + checksummed[len(address)+1:]} {
+		id, err := ParseAddress(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, keyID, id, input)
+		assert.Equal(t, keyID, StringToAddress(input), input)
+	}
+
+	// the corrupted addresses
+	last := checksummed[len(checksummed)-1]
+	for _, input := range []string{
+		`1134-9343-0809-5039-9547`,
+		`1134-9343-0809-5093-9546`,
+		checksummed[:len(checksummed)-1] + string('0'+(last-'0'+1)%10),
+		`1134-9343-0809-5039-9547` + checksummed[len(address):],
+	} {
+		_, err := ParseAddress(input)
+		assert.Equal(t, ErrAddressChecksum, err, input)
+		assert.Zero(t, StringToAddress(input), input)
+	}
+	for _, input := range []string{``, `-`, `1134-9343-0809-5039-954a`, `1134-9343-0809-5039-9546-x`,
+		`1134-9343-0809-5039-9546-12`} {
+		_, err := ParseAddress(input)
+		assert.Error(t, err, input)
+		assert.Zero(t, StringToAddress(input), input)
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	for _, item := range []struct {
+		money    string
+		decimals int
+		want     string
+	}{
+		{`123455000000000000000`, consts.EGS_DIGIT, `123.455`},
+		{`5`, consts.EGS_DIGIT, `0.000000000000000005`},
+		{`0`, consts.EGS_DIGIT, `0`},
+		{`12345`, 2, `123.45`},
+		{`12300`, 2, `123`},
+		{`-1500`, 6, `-0.0015`},
+		{`700`, 0, `700`},
+	} {
+		assert.Equal(t, item.want, FormatMoney(item.money, item.decimals), item.money)
+	}
+	assert.Equal(t, FormatMoney(`123455000000000000000`, consts.EGS_DIGIT), EGSMoney(`123455000000000000000`))
+}
@@ -0,0 +1,424 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package converter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// The binary format of the structs is defined by the tags `bin:"number,kind[,vN]"` of their fields.
+// The fields are encoded in the order of the numbers, the kinds are:
+//   - fixedN is the big-endian integer of N bytes like DecToBin;
+//   - int is the integer with the length byte like EncodeLenInt64;
+//   - ints is the number of the integers and the integers which are encoded as int;
+//   - bytes is the byte slice or the string with the length like EncodeLengthPrefixed;
+//   - message is the nested struct, the pointer or the slice of them, it can be only described.
+//
+// vN means that the field is encoded since the version N of the format. The fields without the tag
+// aren't encoded, the fields of the embedded structs are encoded as the fields of the struct.
+// The same fields are used by DescribeLayout, so the described data can't differ from the binary data
+
+// LayoutVersioner is implemented by the nested structs which have their own version of the format
+type LayoutVersioner interface {
+	LayoutVersion() byte
+}
+
+type layoutField struct {
+	name     string
+	number   int
+	kind     string
+	size     int
+	version  byte
+	index    []int
+	repeated bool
+	text     bool
+	message  reflect.Type
+}
+
+var layouts sync.Map
+
+func structLayout(t reflect.Type) ([]layoutField, error) {
+	if cached, ok := layouts.Load(t); ok {
+		return cached.([]layoutField), nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf(`%s isn't a struct`, t)
+	}
+	var fields []layoutField
+	if err := collectFields(t, nil, &fields); err != nil {
+		return nil, err
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].number < fields[j].number })
+	for i := 1; i < len(fields); i++ {
+		if fields[i].number == fields[i-1].number {
+			return nil, fmt.Errorf(`%s has two fields with the number %d`, t, fields[i].number)
+		}
+	}
+	layouts.Store(t, fields)
+	return fields, nil
+}
+
+func collectFields(t reflect.Type, index []int, fields *[]layoutField) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+		tag, ok := sf.Tag.Lookup(`bin`)
+		if !ok {
+			if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+				if err := collectFields(sf.Type, fieldIndex, fields); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		field, err := parseLayoutTag(sf, tag)
+		if err != nil {
+			return fmt.Errorf(`field %s of %s: %v`, sf.Name, t, err)
+		}
+		field.index = fieldIndex
+		*fields = append(*fields, field)
+	}
+	return nil
+}
+
+func parseLayoutTag(sf reflect.StructField, tag string) (field layoutField, err error) {
+	parts := strings.Split(tag, `,`)
+	if len(parts) < 2 || len(parts) > 3 {
+		return field, fmt.Errorf(`wrong tag %q`, tag)
+	}
+	field.name = sf.Name
+	if field.number, err = strconv.Atoi(parts[0]); err != nil || field.number <= 0 {
+		return field, fmt.Errorf(`wrong number %q`, parts[0])
+	}
+	if len(parts) == 3 {
+		version, err := strconv.ParseUint(strings.TrimPrefix(parts[2], `v`), 10, 8)
+		if err != nil || !strings.HasPrefix(parts[2], `v`) {
+			return field, fmt.Errorf(`wrong version %q`, parts[2])
+		}
+		field.version = byte(version)
+	}
+	field.kind = parts[1]
+	t := sf.Type
+	isInt := t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64
+	switch {
+	case strings.HasPrefix(field.kind, `fixed`):
+		field.size, err = strconv.Atoi(strings.TrimPrefix(field.kind, `fixed`))
+		if err != nil || field.size <= 0 || field.size > 8 || !isInt {
+			return field, fmt.Errorf(`wrong kind %s of %s`, field.kind, t)
+		}
+		field.kind = `fixed`
+	case field.kind == `int` && isInt,
+		field.kind == `ints` && t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Int64,
+		field.kind == `bytes` && t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+	case field.kind == `bytes` && t.Kind() == reflect.String:
+		field.text = true
+	case field.kind == `message`:
+		if t.Kind() == reflect.Slice {
+			field.repeated = true
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return field, fmt.Errorf(`wrong kind %s of %s`, field.kind, sf.Type)
+		}
+		field.message = t
+	default:
+		return field, fmt.Errorf(`wrong kind %s of %s`, field.kind, t)
+	}
+	return field, nil
+}
+
+func layoutValue(v interface{}) (reflect.Value, []layoutField, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if !rv.IsValid() {
+		return rv, nil, fmt.Errorf(`%T has no layout`, v)
+	}
+	fields, err := structLayout(rv.Type())
+	return rv, fields, err
+}
+
+// MarshalLayout returns the binary data of the struct in the format of the version
+func MarshalLayout(v interface{}, version byte) ([]byte, error) {
+	rv, fields, err := layoutValue(v)
+	if err != nil {
+		return nil, err
+	}
+	var out []byte
+	for _, field := range fields {
+		if field.version > version {
+			continue
+		}
+		val := rv.FieldByIndex(field.index)
+		switch field.kind {
+		case `fixed`:
+			out = append(out, DecToBin(val.Int(), int64(field.size))...)
+		case `int`:
+			EncodeLenInt64(&out, val.Int())
+		case `ints`:
+			EncodeLenInt64(&out, int64(val.Len()))
+			for i := 0; i < val.Len(); i++ {
+				EncodeLenInt64(&out, val.Index(i).Int())
+			}
+		case `bytes`:
+			out = EncodeLengthPrefixed(out, fieldBytes(val))
+		default:
+			return nil, fmt.Errorf(`field %s of %s can be only described`, field.name, rv.Type())
+		}
+	}
+	return out, nil
+}
+
+// UnmarshalLayout parses the data of the version to the struct which v points to. The data must be
+// in the shortest form and must not have the extra bytes, the fields which aren't in the version are zero
+func UnmarshalLayout(data []byte, v interface{}, version byte) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf(`%T isn't a pointer to the struct`, v)
+	}
+	fields, err := structLayout(rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+	ret := reflect.New(rv.Elem().Type()).Elem()
+	for _, field := range fields {
+		if field.version > version {
+			continue
+		}
+		val := ret.FieldByIndex(field.index)
+		switch field.kind {
+		case `fixed`:
+			if len(data) < field.size {
+				return ErrShortData
+			}
+			val.SetInt(BinToDec(data[:field.size]))
+			data = data[field.size:]
+		case `int`:
+			i, err := DecodeInt64(&data)
+			if err != nil {
+				return err
+			}
+			val.SetInt(i)
+		case `ints`:
+			count, err := DecodeInt64(&data)
+			if err != nil {
+				return err
+			}
+			// every integer has at least one byte
+			if count < 0 || count > int64(len(data)) {
+				return fmt.Errorf(`wrong number %d of %s`, count, field.name)
+			}
+			if count == 0 {
+				continue
+			}
+			list := make([]int64, count)
+			for i := range list {
+				if list[i], err = DecodeInt64(&data); err != nil {
+					return err
+				}
+			}
+			val.Set(reflect.ValueOf(list))
+		case `bytes`:
+			b, err := DecodeLengthPrefixed(&data)
+			if err != nil {
+				return err
+			}
+			if field.text {
+				val.SetString(string(b))
+			} else if len(b) > 0 {
+				// the empty slice is nil like msgpack returns
+				val.SetBytes(append([]byte{}, b...))
+			}
+		default:
+			return fmt.Errorf(`field %s of %s can be only described`, field.name, ret.Type())
+		}
+	}
+	if len(data) > 0 {
+		return fmt.Errorf(`%d extra bytes after %s`, len(data), ret.Type())
+	}
+	rv.Elem().Set(ret)
+	return nil
+}
+
+func fieldBytes(val reflect.Value) []byte {
+	if val.Kind() == reflect.String {
+		return []byte(val.String())
+	}
+	return val.Bytes()
+}
+
+// Wire types of protobuf
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func appendUvarint(out []byte, x uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(out, buf[:binary.PutUvarint(buf[:], x)]...)
+}
+
+func appendKey(out []byte, number, wire int) []byte {
+	return appendUvarint(out, uint64(number)<<3|uint64(wire))
+}
+
+// DescribeLayout returns the data of the struct in the version of the format as the protobuf message
+// which is defined by LayoutSchema. The integers are int64 fields, the fields of the other versions
+// are omitted, so every field of the data is tagged with its number
+func DescribeLayout(v interface{}, version byte) ([]byte, error) {
+	rv, fields, err := layoutValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return describeValue(nil, rv, fields, version)
+}
+
+func describeValue(out []byte, rv reflect.Value, fields []layoutField, version byte) ([]byte, error) {
+	if rv.CanInterface() {
+		if versioner, ok := rv.Interface().(LayoutVersioner); ok {
+			version = versioner.LayoutVersion()
+		}
+	}
+	for _, field := range fields {
+		if field.version > version {
+			continue
+		}
+		val := rv.FieldByIndex(field.index)
+		switch field.kind {
+		case `fixed`, `int`:
+			out = appendUvarint(appendKey(out, field.number, wireVarint), uint64(val.Int()))
+		case `ints`:
+			var packed []byte
+			for i := 0; i < val.Len(); i++ {
+				packed = appendUvarint(packed, uint64(val.Index(i).Int()))
+			}
+			out = append(appendUvarint(appendKey(out, field.number, wireBytes), uint64(len(packed))), packed...)
+		case `bytes`:
+			data := fieldBytes(val)
+			out = append(appendUvarint(appendKey(out, field.number, wireBytes), uint64(len(data))), data...)
+		case `message`:
+			nested, err := structLayout(field.message)
+			if err != nil {
+				return nil, err
+			}
+			items := []reflect.Value{val}
+			if field.repeated {
+				items = items[:0]
+				for i := 0; i < val.Len(); i++ {
+					items = append(items, val.Index(i))
+				}
+			}
+			for _, item := range items {
+				if item.Kind() == reflect.Ptr {
+					if item.IsNil() {
+						continue
+					}
+					item = item.Elem()
+				}
+				message, err := describeValue(nil, item, nested, version)
+				if err != nil {
+					return nil, err
+				}
+				out = append(appendUvarint(appendKey(out, field.number, wireBytes), uint64(len(message))),
+					message...)
+			}
+		}
+	}
+	return out, nil
+}
+
+// LayoutSchema returns the protobuf definition of the messages of DescribeLayout for the structs and
+// their nested structs. The comments of the fields describe their binary format
+func LayoutSchema(values ...interface{}) (string, error) {
+	var (
+		buf   bytes.Buffer
+		queue []reflect.Type
+	)
+	done := make(map[reflect.Type]bool)
+	for _, v := range values {
+		queue = append(queue, reflect.Indirect(reflect.ValueOf(v)).Type())
+	}
+	buf.WriteString("syntax = \"proto3\";\n")
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		if done[t] {
+			continue
+		}
+		done[t] = true
+		fields, err := structLayout(t)
+		if err != nil {
+			return ``, err
+		}
+		fmt.Fprintf(&buf, "\nmessage %s {\n", t.Name())
+		for _, field := range fields {
+			var typ, comment string
+			switch field.kind {
+			case `fixed`:
+				typ, comment = `int64`, fmt.Sprintf(`fixed%d`, field.size)
+			case `int`:
+				typ, comment = `int64`, field.kind
+			case `ints`:
+				typ, comment = `repeated int64`, field.kind
+			case `bytes`:
+				typ, comment = `bytes`, field.kind
+				if field.text {
+					typ = `string`
+				}
+			case `message`:
+				typ = field.message.Name()
+				if field.repeated {
+					typ = `repeated ` + typ
+				}
+				queue = append(queue, field.message)
+			}
+			if field.version > 0 {
+				comment += fmt.Sprintf(`, since v%d`, field.version)
+			}
+			fmt.Fprintf(&buf, "  %s %s = %d;", typ, snakeCase(field.name), field.number)
+			if len(comment) > 0 {
+				buf.WriteString(` // ` + strings.TrimPrefix(comment, `, `))
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString("}\n")
+	}
+	return buf.String(), nil
+}
+
+// snakeCase converts the name of the field like BlockID to block_id
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var out []rune
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) ||
+			i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			out = append(out, '_')
+		}
+		out = append(out, unicode.ToLower(r))
+	}
+	return string(out)
+}
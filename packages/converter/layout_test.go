@@ -0,0 +1,126 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type layoutHeader struct {
+	KeyID   int64  `bin:"2,int"`
+	Comment string `bin:"5,bytes,v2"`
+}
+
+type layoutItem struct {
+	Name string `bin:"1,bytes"`
+}
+
+type layoutData struct {
+	layoutHeader
+	Version int     `bin:"1,fixed2"`
+	List    []int64 `bin:"3,ints"`
+	Data    []byte  `bin:"4,bytes"`
+	Skipped int64
+}
+
+type layoutEnvelope struct {
+	Data  *layoutData  `bin:"1,message"`
+	Items []layoutItem `bin:"2,message"`
+}
+
+// protoData is the protobuf message of layoutData
+type protoData struct {
+	Version int64   `protobuf:"varint,1,opt,name=version"`
+	KeyID   int64   `protobuf:"varint,2,opt,name=key_id"`
+	List    []int64 `protobuf:"varint,3,rep,packed,name=list"`
+	Data    []byte  `protobuf:"bytes,4,opt,name=data"`
+	Comment string  `protobuf:"bytes,5,opt,name=comment"`
+}
+
+func (m *protoData) Reset()         { *m = protoData{} }
+func (m *protoData) String() string { return proto.CompactTextString(m) }
+func (*protoData) ProtoMessage()    {}
+
+var testLayout = layoutData{layoutHeader: layoutHeader{KeyID: -5, Comment: `comment`}, Version: 3,
+	List: []int64{1, 300}, Data: []byte{1, 2}}
+
+func TestLayout(t *testing.T) {
+	data, err := MarshalLayout(&testLayout, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 3, 8, 0xfb, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 1, 2, 1, 1, 2, 0x2c,
+		1, 2, 1, 2, 7, 'c', 'o', 'm', 'm', 'e', 'n', 't'}, data)
+	var ret layoutData
+	require.NoError(t, UnmarshalLayout(data, &ret, 2))
+	assert.Equal(t, testLayout, ret)
+
+	// the fields of the next versions aren't encoded
+	data, err = MarshalLayout(testLayout, 1)
+	require.NoError(t, err)
+	require.NoError(t, UnmarshalLayout(data, &ret, 1))
+	assert.Empty(t, ret.Comment)
+	assert.Equal(t, testLayout.List, ret.List)
+
+	assert.Error(t, UnmarshalLayout(data[:len(data)-1], &ret, 1))
+	assert.Error(t, UnmarshalLayout(append(data, 0), &ret, 1))
+	assert.Error(t, UnmarshalLayout(data, ret, 1))
+	_, err = MarshalLayout(layoutEnvelope{}, 1)
+	assert.Error(t, err)
+	_, err = MarshalLayout(struct {
+		A int64 `bin:"1,int"`
+		B int64 `bin:"1,int"`
+	}{}, 1)
+	assert.Error(t, err)
+	_, err = MarshalLayout(struct {
+		A string `bin:"1,int"`
+	}{}, 1)
+	assert.Error(t, err)
+}
+
+func TestDescribeLayout(t *testing.T) {
+	for _, version := range []byte{1, 2} {
+		data, err := DescribeLayout(&testLayout, version)
+		require.NoError(t, err)
+		var ret protoData
+		require.NoError(t, proto.Unmarshal(data, &ret))
+		want := protoData{Version: 3, KeyID: -5, List: []int64{1, 300}, Data: []byte{1, 2}}
+		if version >= 2 {
+			want.Comment = testLayout.Comment
+		}
+		assert.Equal(t, want, ret)
+	}
+
+	data, err := DescribeLayout(layoutEnvelope{Data: &testLayout, Items: []layoutItem{{`a`}, {`b`}}}, 2)
+	require.NoError(t, err)
+	message, err := DescribeLayout(&testLayout, 2)
+	require.NoError(t, err)
+	assert.Equal(t, append(append([]byte{0x0a, byte(len(message))}, message...),
+		0x12, 3, 0x0a, 1, 'a', 0x12, 3, 0x0a, 1, 'b'), data)
+}
+
+func TestLayoutSchema(t *testing.T) {
+	schema, err := LayoutSchema(layoutEnvelope{})
+	require.NoError(t, err)
+	assert.Equal(t, `syntax = "proto3";
+
+message layoutEnvelope {
+  layoutData data = 1;
+  repeated layoutItem items = 2;
+}
+
+message layoutData {
+  int64 version = 1; // fixed2
+  int64 key_id = 2; // int
+  repeated int64 list = 3; // ints
+  bytes data = 4; // bytes
+  string comment = 5; // bytes, since v2
+}
+
+message layoutItem {
+  string name = 1; // bytes
+}
+`, schema)
+	assert.Equal(t, `block_id`, snakeCase(`BlockID`))
+	assert.Equal(t, `bin_signatures`, snakeCase(`BinSignatures`))
+}
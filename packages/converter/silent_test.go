@@ -0,0 +1,91 @@
+package converter
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// silentFuncs are the conversions which return zero instead of the error
+var silentFuncs = map[string]bool{
+	`StrToInt64`:   true,
+	`StrToInt`:     true,
+	`StrToUint64`:  true,
+	`BytesToInt64`: true,
+	`BytesToInt`:   true,
+}
+
+// allowedSilent is the number of the silent conversions which are left in smart and api.
+// The new code must use StrToInt64E or ValueToIntE, the number can only be decreased
+var allowedSilent = map[string]int{
+	`api/contract.go`:      6,
+	`api/contracts.go`:     1,
+	`api/content.go`:       1,
+	`api/login.go`:         1,
+	`api/notificator.go`:   2,
+	`api/prepare.go`:       3,
+	`api/smart.go`:         2,
+	`smart/selective.go`:   2,
+	`smart/smart.go`:       10,
+	`smart/sysrollback.go`: 12,
+}
+
+func silentCalls(t *testing.T, dir string) map[string]int {
+	files, err := filepath.Glob(filepath.Join(`..`, dir, `*.go`))
+	require.NoError(t, err)
+	ret := make(map[string]int)
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, `_test.go`) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		require.NoError(t, err)
+		ast.Inspect(f, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && silentFuncs[sel.Sel.Name] {
+				if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == `converter` {
+					ret[filepath.ToSlash(filepath.Join(dir, filepath.Base(file)))]++
+				}
+			}
+			return true
+		})
+	}
+	return ret
+}
+
+func TestSilentConversions(t *testing.T) {
+	for _, dir := range []string{`smart`, `api`} {
+		for file, count := range silentCalls(t, dir) {
+			assert.True(t, count <= allowedSilent[file],
+				"%s has %d silent conversions, %d are allowed. Use StrToInt64E or ValueToIntE", file, count, allowedSilent[file])
+		}
+	}
+}
+
+func TestStrToInt64E(t *testing.T) {
+	val, err := StrToInt64E(`-25`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-25), val)
+	for _, s := range []string{``, `1a`, `0x10`, `99999999999999999999`} {
+		_, err = StrToInt64E(s)
+		assert.Error(t, err, s)
+	}
+
+	val, err = ValueToIntE(`17`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(17), val)
+	for _, v := range []interface{}{nil, ``, `abc`, []int{1}} {
+		_, err = ValueToIntE(v)
+		assert.Error(t, err, v)
+	}
+}
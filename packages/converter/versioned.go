@@ -0,0 +1,132 @@
+package converter
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// VersionMarker is the first byte of the versioned data. msgpack doesn't use this byte,
+// so the data without the marker are the data of the version 0
+const VersionMarker = 0xc1
+
+var (
+	// ErrShortData is returned if the data are shorter than the encoded length
+	ErrShortData = errors.New(`data are shorter than the encoded length`)
+	// ErrUnknownVersion is returned if there isn't the decoder of the version of the data
+	ErrUnknownVersion = errors.New(`unknown version of the data`)
+)
+
+// EncodeLengthPrefixed appends the length of data and data to out
+func EncodeLengthPrefixed(out, data []byte) []byte {
+	return append(append(out, EncodeLength(int64(len(data)))...), data...)
+}
+
+// DecodeLengthPrefixed returns the data which have been encoded with EncodeLengthPrefixed and shifts buf
+func DecodeLengthPrefixed(buf *[]byte) ([]byte, error) {
+	length, err := decodeShortestLength(buf)
+	if err != nil {
+		return nil, err
+	}
+	if length > uint64(len(*buf)) {
+		return nil, ErrShortData
+	}
+	ret := (*buf)[:length:length]
+	*buf = (*buf)[length:]
+	return ret, nil
+}
+
+// decodeShortestLength decodes the length which has been encoded with EncodeLength.
+// Unlike DecodeLength it requires the shortest form, so every length has only one encoding
+func decodeShortestLength(buf *[]byte) (uint64, error) {
+	if len(*buf) == 0 {
+		return 0, ErrShortData
+	}
+	first := (*buf)[0]
+	if first&0x80 == 0 {
+		*buf = (*buf)[1:]
+		return uint64(first), nil
+	}
+	size := int(first & 0x7f)
+	if size == 0 || size > 8 {
+		return 0, fmt.Errorf(`wrong size %d of encoded length`, size)
+	}
+	if len(*buf) < size+1 {
+		return 0, ErrShortData
+	}
+	data := make([]byte, 8)
+	copy(data[8-size:], (*buf)[1:size+1])
+	length := binary.BigEndian.Uint64(data)
+	if (*buf)[1] == 0 || length <= 127 {
+		return 0, fmt.Errorf(`length isn't encoded in the shortest form`)
+	}
+	*buf = (*buf)[size+1:]
+	return length, nil
+}
+
+// DecodeInt64 gets int64 which has been encoded with EncodeLenInt64 and shifts buf.
+// Unlike DecodeLenInt64 it requires the shortest form, so every number has only one encoding
+func DecodeInt64(buf *[]byte) (int64, error) {
+	if len(*buf) == 0 {
+		return 0, ErrShortData
+	}
+	length := int((*buf)[0])
+	if length > 8 {
+		return 0, fmt.Errorf(`wrong length %d of int64`, length)
+	}
+	if len(*buf) < length+1 {
+		return 0, ErrShortData
+	}
+	if length > 0 && (*buf)[length] == 0 {
+		return 0, fmt.Errorf(`int64 isn't encoded in the shortest form`)
+	}
+	data := make([]byte, 8)
+	copy(data, (*buf)[1:length+1])
+	*buf = (*buf)[length+1:]
+	return int64(binary.LittleEndian.Uint64(data)), nil
+}
+
+// EncodeVersioned returns the payload with the header of the format version
+func EncodeVersioned(version byte, payload []byte) []byte {
+	return EncodeLengthPrefixed([]byte{VersionMarker, version}, payload)
+}
+
+// DecodeVersioned returns the version and the payload of the data which have been encoded
+// with EncodeVersioned. The data without the header are returned as the payload of the version 0
+func DecodeVersioned(data []byte) (byte, []byte, error) {
+	if len(data) == 0 || data[0] != VersionMarker {
+		return 0, data, nil
+	}
+	if len(data) < 2 {
+		return 0, nil, ErrShortData
+	}
+	version := data[1]
+	if version == 0 {
+		return 0, nil, fmt.Errorf(`version 0 can't have the header`)
+	}
+	buf := data[2:]
+	payload, err := DecodeLengthPrefixed(&buf)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(buf) > 0 {
+		return 0, nil, fmt.Errorf(`%d extra bytes after the payload`, len(buf))
+	}
+	return version, payload, nil
+}
+
+// VersionDecoders are the decoders of the payloads of the format versions
+type VersionDecoders map[byte]func(payload []byte) error
+
+// Decode calls the decoder of the version of the data
+func (d VersionDecoders) Decode(data []byte) error {
+	version, payload, err := DecodeVersioned(data)
+	if err != nil {
+		return err
+	}
+	decode, ok := d[version]
+	if !ok {
+		return ErrUnknownVersion
+	}
+	return decode(payload)
+}
@@ -0,0 +1,84 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersioned(t *testing.T) {
+	payload := make([]byte, 300)
+	payload[299] = 7
+	data := EncodeVersioned(2, payload)
+	version, ret, err := DecodeVersioned(data)
+	require.NoError(t, err)
+	assert.Equal(t, byte(2), version)
+	assert.Equal(t, payload, ret)
+
+	// the data without the header are the data of the version 0
+	version, ret, err = DecodeVersioned([]byte{0x85, 1})
+	require.NoError(t, err)
+	assert.Equal(t, byte(0), version)
+	assert.Equal(t, []byte{0x85, 1}, ret)
+
+	for _, wrong := range [][]byte{{VersionMarker}, {VersionMarker, 0, 0}, {VersionMarker, 1, 5, 1},
+		append(EncodeVersioned(1, []byte{1}), 0), {VersionMarker, 1, 0xff}} {
+		_, _, err = DecodeVersioned(wrong)
+		assert.Error(t, err, wrong)
+	}
+
+	var calls []byte
+	decoders := VersionDecoders{
+		0: func([]byte) error { calls = append(calls, 0); return nil },
+		1: func([]byte) error { calls = append(calls, 1); return nil },
+	}
+	require.NoError(t, decoders.Decode([]byte{0x80}))
+	require.NoError(t, decoders.Decode(EncodeVersioned(1, nil)))
+	assert.Equal(t, []byte{0, 1}, calls)
+	assert.Equal(t, ErrUnknownVersion, decoders.Decode(EncodeVersioned(3, nil)))
+}
+
+func TestDecodeInt64(t *testing.T) {
+	for _, x := range []int64{0, 1, 255, 256, -1, 1 << 62} {
+		var buf []byte
+		EncodeLenInt64(&buf, x)
+		val, err := DecodeInt64(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, x, val)
+		assert.Empty(t, buf)
+	}
+	for _, wrong := range [][]byte{{}, {9, 1, 1, 1, 1, 1, 1, 1, 1, 1}, {2, 1}, {2, 1, 0}} {
+		_, err := DecodeInt64(&wrong)
+		assert.Error(t, err, wrong)
+	}
+}
+
+// FuzzDecodeVersioned checks that the malformed data return the error instead of panic
+func FuzzDecodeVersioned(f *testing.F) {
+	f.Add(EncodeVersioned(1, []byte(`payload`)))
+	f.Add(EncodeVersioned(1, make([]byte, 200)))
+	f.Add([]byte{VersionMarker, 1, 0x88, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	f.Add([]byte{VersionMarker, 1, 0xff})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		version, payload, err := DecodeVersioned(data)
+		if err == nil && version > 0 {
+			assert.Equal(t, data, EncodeVersioned(version, payload))
+		}
+	})
+}
+
+func FuzzDecodeLengthPrefixed(f *testing.F) {
+	f.Add(EncodeLengthPrefixed(nil, []byte(`data`)))
+	f.Add([]byte{0xff, 1})
+	f.Add([]byte{0x89, 0, 0, 0, 0, 0, 0, 0, 0, 1})
+	f.Add([]byte{0x88, 0x80, 0, 0, 0, 0, 0, 0, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for len(data) > 0 {
+			if _, err := DecodeLengthPrefixed(&data); err != nil {
+				break
+			}
+		}
+		DecodeInt64(&data)
+	})
+}
@@ -14,7 +14,8 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/converter"
 )
 
-// Address gets int64 EGGAS address from the public key
+// Address gets int64 EGGAS address from the public key. The key of the scheme except ECDSA
+// is hashed together with the version of the scheme
 func Address(pubKey []byte) int64 {
 	h256 := sha256.Sum256(pubKey)
 	h512 := sha512.Sum512(h256[:])
@@ -26,7 +27,20 @@ func Address(pubKey []byte) int64 {
 }
 
 // PrivateToPublic returns the public key for the specified private key.
+// The public key has the version of the scheme of the private key
 func PrivateToPublic(key []byte) ([]byte, error) {
+	scheme, priv, err := splitPrivate(key)
+	if err != nil {
+		return nil, err
+	}
+	public, err := scheme.PrivateToPublic(priv)
+	if err != nil {
+		return nil, err
+	}
+	return VersionedKey(scheme.Version(), public), nil
+}
+
+func privateToPublicECDSA(key []byte) ([]byte, error) {
 	var pubkeyCurve elliptic.Curve
 	switch ellipticSize {
 	case elliptic256:
@@ -36,6 +50,7 @@ func PrivateToPublic(key []byte) ([]byte, error) {
 	}
 
 	bi := new(big.Int).SetBytes(key)
+	defer wipeInt(bi)
 	priv := new(ecdsa.PrivateKey)
 	priv.PublicKey.Curve = pubkeyCurve
 	priv.D = bi
@@ -66,7 +66,7 @@ var (
 	cryptoProv   = _AESCBC
 	hashProv     = _SHA256
 	ellipticSize = elliptic256
-	signProv     = _ECDSA
+	signProv     = SchemeECDSA
 	checksumProv = _CRC64
 	hmacProv     = _SHA256
 )
@@ -128,6 +128,20 @@ func GenBytesKeys() ([]byte, []byte, error) {
 	return private.D.Bytes(), append(converter.FillLeft(private.PublicKey.X.Bytes()), converter.FillLeft(private.PublicKey.Y.Bytes())...), nil
 }
 
+// GenSchemeKeys generates a random pair of private and public binary keys of the scheme.
+// The keys have the version of the scheme
+func GenSchemeKeys(version byte) ([]byte, []byte, error) {
+	scheme, err := GetScheme(version)
+	if err != nil {
+		return nil, nil, err
+	}
+	priv, pub, err := scheme.GenerateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return VersionedKey(version, priv), VersionedKey(version, pub), nil
+}
+
 // GenHexKeys generates a random pair of ECDSA private and public hex keys.
 func GenHexKeys() (string, string, error) {
 	priv, pub, err := GenBytesKeys()
@@ -217,7 +231,7 @@ func getSharedKey(private, public []byte) (shared []byte, err error) {
 	}
 
 	switch signProv {
-	case _ECDSA:
+	case SchemeECDSA:
 		if len(private) != consts.PubkeySizeLength/2 {
 			return nil, ErrIncorrectPrivKeyLength
 		}
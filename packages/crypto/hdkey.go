@@ -0,0 +1,135 @@
+package crypto
+
+import (
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+)
+
+// The keys are derived from the seed of the mnemonic by SLIP-0010, which is BIP-32 for the curves
+// of the signature schemes: NIST P-256 for ECDSA and Ed25519. The path of the key is
+// m/44'/DerivationCoinType'/account'/0'/index'. All levels are hardened because Ed25519 supports
+// only the hardened derivation
+const (
+	DerivationPurpose  = 44
+	DerivationCoinType = 18245 // 0x4745, it isn't registered in SLIP-0044
+	HardenedKey        = 0x80000000
+)
+
+// the keys of HMAC for the master keys of the curves, SLIP-0010
+var derivationCurveKeys = map[byte]string{
+	SchemeECDSA:   `Nist256p1 seed`,
+	SchemeEd25519: `ed25519 seed`,
+}
+
+// DerivationPath returns the path of the key with the specified account and index
+func DerivationPath(account, index uint32) string {
+	return fmt.Sprintf(`m/%d'/%d'/%d'/0'/%d'`, DerivationPurpose, DerivationCoinType, account, index)
+}
+
+// ParseDerivationPath converts the path like m/44'/0'/1 to the indexes of the levels.
+// The hardened levels are marked by ' or H
+func ParseDerivationPath(path string) ([]uint32, error) {
+	items := strings.Split(strings.TrimSpace(path), `/`)
+	if items[0] != `m` {
+		return nil, fmt.Errorf("derivation path %s must start with m", path)
+	}
+	ret := make([]uint32, 0, len(items)-1)
+	for _, item := range items[1:] {
+		var hardened uint32
+		if strings.HasSuffix(item, `'`) || strings.HasSuffix(item, `H`) {
+			item, hardened = item[:len(item)-1], HardenedKey
+		}
+		index, err := strconv.ParseUint(item, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("wrong level %s of derivation path %s", item, path)
+		}
+		ret = append(ret, uint32(index)|hardened)
+	}
+	return ret, nil
+}
+
+// DeriveKey returns the private key of the scheme derived from the seed by the path.
+// The key has the version of the scheme like the keys of GenSchemeKeys
+func DeriveKey(seed []byte, version byte, path string) ([]byte, error) {
+	curveKey, ok := derivationCurveKeys[version]
+	if !ok {
+		return nil, ErrUnknownScheme
+	}
+	indexes, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha512.New, []byte(curveKey))
+	mac.Write(seed)
+	I := mac.Sum(nil)
+	if version == SchemeECDSA {
+		// the master key must be valid for the curve
+		for !validP256Key(I[:32]) {
+			mac.Reset()
+			mac.Write(I)
+			I = mac.Sum(nil)
+		}
+	}
+	key, chain := I[:32], I[32:]
+	for _, index := range indexes {
+		if version == SchemeEd25519 {
+			if index < HardenedKey {
+				return nil, fmt.Errorf("ed25519 supports only hardened derivation")
+			}
+			key, chain = deriveChild(chain, append([]byte{0}, key...), index)
+			continue
+		}
+		if key, chain, err = deriveP256Child(key, chain, index); err != nil {
+			return nil, err
+		}
+	}
+	return VersionedKey(version, key), nil
+}
+
+func deriveChild(chain, data []byte, index uint32) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, chain)
+	mac.Write(data)
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], index)
+	mac.Write(buf[:])
+	I := mac.Sum(nil)
+	return I[:32], I[32:]
+}
+
+func validP256Key(key []byte) bool {
+	k := new(big.Int).SetBytes(key)
+	return k.Sign() > 0 && k.Cmp(elliptic.P256().Params().N) < 0
+}
+
+func deriveP256Child(key, chain []byte, index uint32) ([]byte, []byte, error) {
+	curve := elliptic.P256()
+	var data []byte
+	if index >= HardenedKey {
+		data = append([]byte{0}, key...)
+	} else {
+		x, y := curve.ScalarBaseMult(key)
+		data = elliptic.MarshalCompressed(curve, x, y)
+	}
+	n := curve.Params().N
+	for {
+		IL, IR := deriveChild(chain, data, index)
+		child := new(big.Int).SetBytes(IL)
+		if child.Cmp(n) < 0 {
+			child.Add(child, new(big.Int).SetBytes(key))
+			child.Mod(child, n)
+			if child.Sign() > 0 {
+				return converter.FillLeft(child.Bytes()), IR, nil
+			}
+		}
+		// the derived key is invalid, so the next candidate is calculated, SLIP-0010
+		data = append([]byte{1}, IR...)
+	}
+}
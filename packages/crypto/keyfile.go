@@ -0,0 +1,119 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// The parameters of the encrypted key files. The key of AES-256-GCM is derived from the passphrase by scrypt
+const (
+	keyFileVersion = 1
+	keyFileKDF     = "scrypt"
+	scryptN        = 1 << 15
+	scryptR        = 8
+	scryptP        = 1
+	scryptMaxN     = 1 << 20
+)
+
+// ErrWrongPassphrase is returned if the encrypted key can't be decrypted with the passphrase
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted key file")
+
+type keyFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// IsEncryptedKey returns true if data is the content of the encrypted key file
+func IsEncryptedKey(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
+
+func keyFileCipher(passphrase string, kf *keyFile) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(kf.Salt)
+	if err != nil {
+		return nil, err
+	}
+	key, err := scryptKey([]byte(passphrase), salt, kf.N, kf.R, kf.P, 32)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptKey encrypts the key with the passphrase and returns the content of the encrypted key file
+func EncryptKey(key []byte, passphrase string) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase is empty")
+	}
+	salt := make([]byte, 32)
+	if _, err := crand.Read(salt); err != nil {
+		return nil, err
+	}
+	kf := keyFile{Version: keyFileVersion, KDF: keyFileKDF, N: scryptN, R: scryptR, P: scryptP,
+		Salt: hex.EncodeToString(salt)}
+	aead, err := keyFileCipher(passphrase, &kf)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = crand.Read(nonce); err != nil {
+		return nil, err
+	}
+	kf.Nonce = hex.EncodeToString(nonce)
+	kf.Ciphertext = hex.EncodeToString(aead.Seal(nil, nonce, key, nil))
+	return json.MarshalIndent(kf, ``, `  `)
+}
+
+// DecryptKey decrypts the content of the encrypted key file with the passphrase
+func DecryptKey(data []byte, passphrase string) ([]byte, error) {
+	var kf keyFile
+	if err := json.Unmarshal(data, &kf); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling encrypted key file")
+		return nil, err
+	}
+	if kf.Version != keyFileVersion || kf.KDF != keyFileKDF {
+		return nil, fmt.Errorf("unsupported key file version %d or kdf %s", kf.Version, kf.KDF)
+	}
+	if kf.N > scryptMaxN {
+		return nil, fmt.Errorf("scrypt parameter N %d is too large", kf.N)
+	}
+	aead, err := keyFileCipher(passphrase, &kf)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(kf.Nonce)
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	ciphertext, err := hex.DecodeString(kf.Ciphertext)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	key, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return key, nil
+}
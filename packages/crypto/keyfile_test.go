@@ -0,0 +1,50 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the vectors are from RFC 7914
+func TestScryptKey(t *testing.T) {
+	vectors := []struct {
+		password, salt string
+		N, r, p        int
+		key            string
+	}{
+		{``, ``, 16, 1, 1,
+			`77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906`},
+		{`password`, `NaCl`, 1024, 8, 16,
+			`fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640`},
+	}
+	for _, item := range vectors {
+		key, err := scryptKey([]byte(item.password), []byte(item.salt), item.N, item.r, item.p, 64)
+		require.NoError(t, err)
+		assert.Equal(t, item.key, hex.EncodeToString(key))
+	}
+	_, err := scryptKey(nil, nil, 15, 1, 1, 64)
+	assert.Error(t, err)
+}
+
+func TestKeyFile(t *testing.T) {
+	const key = `2ca6d8e2a1a8b2ff4b4bd1e54a0f9ff3f23b4c4d25f3d64ba1b2f7e8c9d0a1b2`
+	assert.False(t, IsEncryptedKey([]byte(key)))
+
+	data, err := EncryptKey([]byte(key), `secret`)
+	require.NoError(t, err)
+	assert.True(t, IsEncryptedKey(data))
+	assert.NotContains(t, string(data), key)
+
+	out, err := DecryptKey(data, `secret`)
+	require.NoError(t, err)
+	assert.Equal(t, key, string(out))
+
+	_, err = DecryptKey(data, `wrong`)
+	assert.Equal(t, ErrWrongPassphrase, err)
+
+	_, err = EncryptKey([]byte(key), ``)
+	assert.Error(t, err)
+}
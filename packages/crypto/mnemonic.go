@@ -0,0 +1,100 @@
+package crypto
+
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// The size of the entropy of the mnemonic in bits. The mnemonic has 3 words per 32 bits
+const (
+	MnemonicEntropyMin     = 128
+	MnemonicEntropyMax     = 256
+	MnemonicEntropyDefault = 256
+)
+
+// ErrMnemonicChecksum is returned if the checksum of the mnemonic is wrong
+var ErrMnemonicChecksum = errors.New("invalid mnemonic checksum")
+
+var bip39Index map[string]int
+
+func init() {
+	bip39Index = make(map[string]int, len(bip39Words))
+	for i, word := range bip39Words {
+		bip39Index[word] = i
+	}
+}
+
+// NewMnemonic generates the random BIP-39 mnemonic with the entropy of the specified bits
+func NewMnemonic(bits int) (string, error) {
+	if bits < MnemonicEntropyMin || bits > MnemonicEntropyMax || bits%32 != 0 {
+		return ``, fmt.Errorf("entropy must be from %d to %d bits and a multiple of 32", MnemonicEntropyMin, MnemonicEntropyMax)
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := crand.Read(entropy); err != nil {
+		return ``, err
+	}
+	return EntropyToMnemonic(entropy)
+}
+
+// EntropyToMnemonic returns the BIP-39 mnemonic of the entropy
+func EntropyToMnemonic(entropy []byte) (string, error) {
+	bits := len(entropy) * 8
+	if bits < MnemonicEntropyMin || bits > MnemonicEntropyMax || bits%32 != 0 {
+		return ``, fmt.Errorf("wrong size of entropy %d", len(entropy))
+	}
+	hash := sha256.Sum256(entropy)
+	data := append(append([]byte{}, entropy...), hash[0])
+	words := make([]string, (bits+bits/32)/11)
+	for i := range words {
+		var index int
+		for bit := i * 11; bit < (i+1)*11; bit++ {
+			index = index<<1 | int(data[bit/8]>>uint(7-bit%8)&1)
+		}
+		words[i] = bip39Words[index]
+	}
+	return strings.Join(words, ` `), nil
+}
+
+// MnemonicToEntropy checks the BIP-39 mnemonic and returns its entropy
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, fmt.Errorf("wrong number of words %d", len(words))
+	}
+	data := make([]byte, (len(words)*11+7)/8)
+	for i, word := range words {
+		index, ok := bip39Index[word]
+		if !ok {
+			return nil, fmt.Errorf("unknown mnemonic word %s", word)
+		}
+		for j := 0; j < 11; j++ {
+			if index&(1<<uint(10-j)) != 0 {
+				bit := i*11 + j
+				data[bit/8] |= 1 << uint(7-bit%8)
+			}
+		}
+	}
+	checksumBits := len(words) * 11 / 33
+	entropy := data[:checksumBits*4]
+	hash := sha256.Sum256(entropy)
+	if hash[0]>>uint(8-checksumBits) != data[len(entropy)]>>uint(8-checksumBits) {
+		return nil, ErrMnemonicChecksum
+	}
+	return entropy, nil
+}
+
+// MnemonicToSeed checks the BIP-39 mnemonic and returns the seed of the key derivation
+func MnemonicToSeed(mnemonic, passphrase string) ([]byte, error) {
+	if _, err := MnemonicToEntropy(mnemonic); err != nil {
+		return nil, err
+	}
+	mnemonic = norm.NFKD.String(strings.Join(strings.Fields(mnemonic), ` `))
+	salt := norm.NFKD.String(`mnemonic` + passphrase)
+	return pbkdf2Key(sha512.New, []byte(mnemonic), []byte(salt), 2048, 64), nil
+}
@@ -0,0 +1,96 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the vectors of BIP-39 with the passphrase TREZOR
+func TestMnemonic(t *testing.T) {
+	vectors := []struct {
+		entropy, mnemonic, seed string
+	}{
+		{`00000000000000000000000000000000`,
+			`abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about`,
+			`c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04`},
+		{`7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f`,
+			`legal winner thank year wave sausage worth useful legal winner thank yellow`,
+			`2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607`},
+		{`80808080808080808080808080808080`,
+			`letter advice cage absurd amount doctor acoustic avoid letter advice cage above`,
+			`d71de856f81a8acc65e6fc851a38d4d7ec216fd0796d0a6827a3ad6ed5511a30fa280f12eb2e47ed2ac03b5c462a0358d18d69fe4f985ec81778c1b370b652a8`},
+		{`ffffffffffffffffffffffffffffffff`,
+			`zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong`,
+			`ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069`},
+	}
+	for _, item := range vectors {
+		entropy, err := hex.DecodeString(item.entropy)
+		require.NoError(t, err)
+		mnemonic, err := EntropyToMnemonic(entropy)
+		require.NoError(t, err)
+		assert.Equal(t, item.mnemonic, mnemonic)
+
+		out, err := MnemonicToEntropy(mnemonic)
+		require.NoError(t, err)
+		assert.Equal(t, entropy, out)
+
+		seed, err := MnemonicToSeed(mnemonic, `TREZOR`)
+		require.NoError(t, err)
+		assert.Equal(t, item.seed, hex.EncodeToString(seed))
+	}
+
+	_, err := MnemonicToEntropy(strings.Repeat(`abandon `, 12))
+	assert.Equal(t, ErrMnemonicChecksum, err)
+	_, err = MnemonicToEntropy(`abandon genesis`)
+	assert.Error(t, err)
+
+	mnemonic, err := NewMnemonic(MnemonicEntropyDefault)
+	require.NoError(t, err)
+	assert.Len(t, strings.Fields(mnemonic), 24)
+	_, err = MnemonicToEntropy(mnemonic)
+	assert.NoError(t, err)
+}
+
+// the vectors are the test vector 1 of SLIP-0010
+func TestDeriveKey(t *testing.T) {
+	seed, err := hex.DecodeString(`000102030405060708090a0b0c0d0e0f`)
+	require.NoError(t, err)
+	vectors := []struct {
+		version    byte
+		path, priv string
+	}{
+		{SchemeECDSA, `m`, `612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2`},
+		{SchemeECDSA, `m/0'`, `6939694369114c67917a182c59ddb8cafc3004e63ca5d3b84403ba8613debc0c`},
+		{SchemeEd25519, `m`, `022b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7`},
+		{SchemeEd25519, `m/0H`, `0268e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3`},
+	}
+	for _, item := range vectors {
+		key, err := DeriveKey(seed, item.version, item.path)
+		require.NoError(t, err)
+		assert.Equal(t, item.priv, hex.EncodeToString(key), item.path)
+	}
+
+	_, err = DeriveKey(seed, SchemeEd25519, `m/0`)
+	assert.Error(t, err)
+	_, err = ParseDerivationPath(`44'/0`)
+	assert.Error(t, err)
+	assert.Equal(t, `m/44'/18245'/0'/0'/3'`, DerivationPath(0, 3))
+
+	// the derived key is used as the generated one, the node gets the same address at login
+	for _, version := range []byte{SchemeECDSA, SchemeEd25519} {
+		key, err := DeriveKey(seed, version, DerivationPath(0, 0))
+		require.NoError(t, err)
+		public, err := PrivateToPublic(key)
+		require.NoError(t, err)
+		sign, err := Sign(hex.EncodeToString(key), `login`)
+		require.NoError(t, err)
+		ok, err := CheckSign(public, `login`, sign)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.NotZero(t, Address(public))
+	}
+}
@@ -0,0 +1,200 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// The versions of the signature schemes. The version is the first byte of the stored keys
+// of the scheme. The keys of ECDSA are stored without the version, so the existing keys
+// and addresses remain valid
+const (
+	SchemeECDSA   byte = 1
+	SchemeEd25519 byte = 2
+)
+
+// ErrUnknownScheme is Unknown signature scheme error
+var ErrUnknownScheme = errors.New("Unknown signature scheme")
+
+// SignScheme is the scheme of the digital signature. The methods take and return the keys
+// without the version of the scheme
+type SignScheme interface {
+	Version() byte
+	GenerateKey() (private, public []byte, err error)
+	PrivateToPublic(private []byte) ([]byte, error)
+	Sign(private []byte, data string) ([]byte, error)
+	Verify(public []byte, data string, signature []byte) (bool, error)
+	// Digest returns the message of the data which is signed by SignDigest, so the data
+	// can be signed by the external signer which has only the private key
+	Digest(data string) ([]byte, error)
+	SignDigest(private, digest []byte) ([]byte, error)
+}
+
+var schemes = map[byte]SignScheme{
+	SchemeECDSA:   ecdsaScheme{},
+	SchemeEd25519: ed25519Scheme{},
+}
+
+// GetScheme returns the signature scheme with the specified version
+func GetScheme(version byte) (SignScheme, error) {
+	scheme, ok := schemes[version]
+	if !ok {
+		log.WithFields(log.Fields{"type": consts.CryptoError, "version": version}).Error("unknown signature scheme")
+		return nil, ErrUnknownScheme
+	}
+	return scheme, nil
+}
+
+// VersionedKey returns the key in the stored form of the scheme
+func VersionedKey(version byte, key []byte) []byte {
+	if version == SchemeECDSA {
+		return key
+	}
+	return append([]byte{version}, key...)
+}
+
+// splitPublic returns the scheme of the stored public key and the key without the version
+func splitPublic(public []byte) (SignScheme, []byte, error) {
+	if len(public) == 0 || len(public) == consts.PubkeySizeLength {
+		return schemes[SchemeECDSA], public, nil
+	}
+	scheme, err := GetScheme(public[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return scheme, public[1:], nil
+}
+
+// splitPrivate returns the scheme of the stored private key and the key without the version
+func splitPrivate(private []byte) (SignScheme, []byte, error) {
+	if len(private) <= consts.PrivkeyLength {
+		return schemes[SchemeECDSA], private, nil
+	}
+	scheme, err := GetScheme(private[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return scheme, private[1:], nil
+}
+
+// the sizes of the public keys without the version
+var publicKeySizes = map[byte]int{
+	SchemeECDSA:   consts.PubkeySizeLength,
+	SchemeEd25519: ed25519.PublicKeySize,
+}
+
+// CheckPublicKey checks that the stored public key has the size of its scheme
+func CheckPublicKey(public []byte) error {
+	scheme, key, err := splitPublic(public)
+	if err != nil {
+		return err
+	}
+	if len(key) != publicKeySizes[scheme.Version()] {
+		return fmt.Errorf("invalid parameters len(public) = %d", len(key))
+	}
+	return nil
+}
+
+// KeyScheme returns the version of the scheme of the stored public key
+func KeyScheme(public []byte) (byte, error) {
+	scheme, _, err := splitPublic(public)
+	if err != nil {
+		return 0, err
+	}
+	return scheme.Version(), nil
+}
+
+type ecdsaScheme struct{}
+
+func (ecdsaScheme) Version() byte {
+	return SchemeECDSA
+}
+
+func (ecdsaScheme) GenerateKey() ([]byte, []byte, error) {
+	return GenBytesKeys()
+}
+
+func (ecdsaScheme) PrivateToPublic(private []byte) ([]byte, error) {
+	return privateToPublicECDSA(private)
+}
+
+func (ecdsaScheme) Sign(private []byte, data string) ([]byte, error) {
+	return signECDSA(private, data)
+}
+
+func (ecdsaScheme) Verify(public []byte, data string, signature []byte) (bool, error) {
+	return checkECDSA(public, data, signature)
+}
+
+func (ecdsaScheme) Digest(data string) ([]byte, error) {
+	return Hash([]byte(data))
+}
+
+func (ecdsaScheme) SignDigest(private, digest []byte) ([]byte, error) {
+	return signECDSADigest(private, digest)
+}
+
+// ed25519Scheme signs the data itself, the private key is the seed of 32 bytes
+type ed25519Scheme struct{}
+
+func (ed25519Scheme) Version() byte {
+	return SchemeEd25519
+}
+
+func (ed25519Scheme) GenerateKey() ([]byte, []byte, error) {
+	public, private, err := ed25519.GenerateKey(crand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return private.Seed(), public, nil
+}
+
+func (ed25519Scheme) PrivateToPublic(private []byte) ([]byte, error) {
+	if len(private) != ed25519.SeedSize {
+		return nil, ErrIncorrectPrivKeyLength
+	}
+	// Public returns the copy, so the expanded private key can be wiped
+	priv := ed25519.NewKeyFromSeed(private)
+	defer wipe(priv)
+	return priv.Public().(ed25519.PublicKey), nil
+}
+
+func (ed25519Scheme) Sign(private []byte, data string) ([]byte, error) {
+	if len(private) != ed25519.SeedSize {
+		log.WithFields(log.Fields{"size": len(private), "size_match": ed25519.SeedSize, "type": consts.SizeDoesNotMatch}).Error("invalid private key")
+		return nil, ErrIncorrectPrivKeyLength
+	}
+	priv := ed25519.NewKeyFromSeed(private)
+	defer wipe(priv)
+	return ed25519.Sign(priv, []byte(data)), nil
+}
+
+func (ed25519Scheme) Verify(public []byte, data string, signature []byte) (bool, error) {
+	if len(public) != ed25519.PublicKeySize {
+		log.WithFields(log.Fields{"size": len(public), "size_match": ed25519.PublicKeySize, "type": consts.SizeDoesNotMatch}).Error("invalid public key")
+		return false, fmt.Errorf("invalid parameters len(public) = %d", len(public))
+	}
+	if len(signature) != ed25519.SignatureSize {
+		log.WithFields(log.Fields{"size": len(signature), "size_match": ed25519.SignatureSize, "type": consts.SizeDoesNotMatch}).Error("invalid signature")
+		return false, fmt.Errorf("invalid parameters len(signature) = %d", len(signature))
+	}
+	if !ed25519.Verify(public, []byte(data), signature) {
+		return false, ErrIncorrectSign
+	}
+	return true, nil
+}
+
+// Digest returns the data itself because ed25519 hashes the signed message
+func (ed25519Scheme) Digest(data string) ([]byte, error) {
+	return []byte(data), nil
+}
+
+func (s ed25519Scheme) SignDigest(private, digest []byte) ([]byte, error) {
+	return s.Sign(private, string(digest))
+}
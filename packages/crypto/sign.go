@@ -15,36 +15,53 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-type signProvider int
-
-const (
-	_ECDSA signProvider = iota
-)
-
-// Sign in signing data with private key
+// Sign in signing data with private key. The scheme is defined by the version of the hex private key.
+// The decoded copy of the private key is wiped after signing
 func Sign(privateKey string, data string) ([]byte, error) {
 	if len(data) == 0 {
 		log.WithFields(log.Fields{"type": consts.CryptoError}).Debug(ErrSigningEmpty.Error())
 	}
-	switch signProv {
-	case _ECDSA:
-		return signECDSA(privateKey, data)
-	default:
-		return nil, ErrUnknownProvider
+	b, err := hex.DecodeString(privateKey)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding private key from hex")
+		return nil, err
+	}
+	defer wipe(b)
+	scheme, key, err := splitPrivate(b)
+	if err != nil {
+		return nil, err
+	}
+	return scheme.Sign(key, data)
+}
+
+// Digest returns the message of the data which is signed by the key of the scheme of the public key
+func Digest(public []byte, data string) ([]byte, error) {
+	scheme, _, err := splitPublic(public)
+	if err != nil {
+		return nil, err
 	}
+	return scheme.Digest(data)
 }
 
-// CheckSign is checking sign
+// SignDigest signs the message returned by Digest with the private key
+func SignDigest(privateKey, digest []byte) ([]byte, error) {
+	scheme, key, err := splitPrivate(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return scheme.SignDigest(key, digest)
+}
+
+// CheckSign is checking sign. The scheme is defined by the version of the public key
 func CheckSign(public []byte, data string, signature []byte) (bool, error) {
 	if len(public) == 0 {
 		log.WithFields(log.Fields{"type": consts.CryptoError}).Debug(ErrCheckingSignEmpty.Error())
 	}
-	switch signProv {
-	case _ECDSA:
-		return checkECDSA(public, data, signature)
-	default:
-		return false, ErrUnknownProvider
+	scheme, key, err := splitPublic(public)
+	if err != nil {
+		return false, err
 	}
+	return scheme.Verify(key, data, signature)
 }
 
 // JSSignToBytes converts hex signature which has got from the browser to []byte
@@ -56,7 +73,15 @@ func JSSignToBytes(in string) ([]byte, error) {
 	return append(converter.FillLeft(r.Bytes()), converter.FillLeft(s.Bytes())...), nil
 }
 
-func signECDSA(privateKey string, data string) (ret []byte, err error) {
+func signECDSA(privateKey []byte, data string) ([]byte, error) {
+	signhash, err := Hash([]byte(data))
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.CryptoError}).Fatal(ErrHashing.Error())
+	}
+	return signECDSADigest(privateKey, signhash)
+}
+
+func signECDSADigest(privateKey, signhash []byte) (ret []byte, err error) {
 	var pubkeyCurve elliptic.Curve
 
 	switch ellipticSize {
@@ -66,20 +91,13 @@ func signECDSA(privateKey string, data string) (ret []byte, err error) {
 		log.WithFields(log.Fields{"type": consts.CryptoError}).Fatal(ErrUnsupportedCurveSize.Error())
 	}
 
-	b, err := hex.DecodeString(privateKey)
-	if err != nil {
-		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding private key from hex")
-		return
-	}
-	bi := new(big.Int).SetBytes(b)
+	bi := new(big.Int).SetBytes(privateKey)
+	defer wipeInt(bi)
 	priv := new(ecdsa.PrivateKey)
 	priv.PublicKey.Curve = pubkeyCurve
 	priv.D = bi
+	priv.PublicKey.X, priv.PublicKey.Y = pubkeyCurve.ScalarBaseMult(privateKey)
 
-	signhash, err := Hash([]byte(data))
-	if err != nil {
-		log.WithFields(log.Fields{"type": consts.CryptoError}).Fatal(ErrHashing.Error())
-	}
 	r, s, err := ecdsa.Sign(crand.Reader, priv, signhash)
 	if err != nil {
 		return
@@ -0,0 +1,175 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the signatures of ECDSA are random, so only the signatures of ed25519 are compared.
+// The vector of ed25519 is the test 2 of RFC 8032
+var signVectors = []struct {
+	scheme  byte
+	private string
+	public  string
+	data    string
+	sign    string
+}{
+	{SchemeECDSA,
+		`2ca6d8e2a1a8b2ff4b4bd1e54a0f9ff3f23b4c4d25f3d64ba1b2f7e8c9d0a1b2`,
+		`952ec7b3172e742fc4b2578be7da8c42138ff4c15004405189a37b9f821c470879431d80e3dd7684882a727afc193a112cc75e351d5756afd08a7a63e71163a8`,
+		`r`,
+		`bb995840b6216f1abbb05e8a8220a126a57a52c4869e19d29e73ae6b486afd08faceb82105e42b4a5bdcf8732df158da40bdbce574fb6959c9c0c19c59836430`},
+	{SchemeEd25519,
+		`024ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb`,
+		`023d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c`,
+		`r`,
+		`92a009a9f0d4cab8720e820b5f642540a2b27b5416503f8fb3762223ebdb69da085ac1e43e15996e458f3613d0f11d8c387b2eaeb4302aeeb00d291612bb0c00`},
+}
+
+func TestSignVectors(t *testing.T) {
+	for _, item := range signVectors {
+		private, err := hex.DecodeString(item.private)
+		require.NoError(t, err)
+		public, err := PrivateToPublic(private)
+		require.NoError(t, err)
+		assert.Equal(t, item.public, hex.EncodeToString(public))
+
+		sign, err := hex.DecodeString(item.sign)
+		require.NoError(t, err)
+		ok, err := CheckSign(public, item.data, sign)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		if sign, err = Sign(item.private, item.data); assert.NoError(t, err) && item.scheme == SchemeEd25519 {
+			assert.Equal(t, item.sign, hex.EncodeToString(sign))
+		}
+
+		version, err := KeyScheme(public)
+		require.NoError(t, err)
+		assert.Equal(t, item.scheme, version)
+	}
+}
+
+func TestSignSchemes(t *testing.T) {
+	const data = `forsign`
+	keys := make(map[byte][2][]byte)
+	for _, version := range []byte{SchemeECDSA, SchemeEd25519} {
+		private, public, err := GenSchemeKeys(version)
+		require.NoError(t, err)
+		keys[version] = [2][]byte{private, public}
+
+		pub, err := PrivateToPublic(private)
+		require.NoError(t, err)
+		assert.Equal(t, public, pub)
+
+		sign, err := Sign(hex.EncodeToString(private), data)
+		require.NoError(t, err)
+		ok, err := CheckSign(public, data, sign)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, _ = CheckSign(public, data+`1`, sign)
+		assert.False(t, ok)
+	}
+	// the stored ECDSA keys have no version, so the addresses of the existing keys remain the same
+	assert.Len(t, keys[SchemeECDSA][1], 64)
+	assert.Len(t, keys[SchemeEd25519][1], 33)
+	assert.NotEqual(t, Address(keys[SchemeEd25519][1]), Address(keys[SchemeEd25519][1][1:]))
+
+	// the signature of one scheme is rejected by the key of another one
+	for version, pair := range keys {
+		sign, err := Sign(hex.EncodeToString(pair[0]), data)
+		require.NoError(t, err)
+		for other, otherPair := range keys {
+			if other == version {
+				continue
+			}
+			ok, _ := CheckSign(otherPair[1], data, sign)
+			assert.False(t, ok)
+		}
+	}
+
+	_, err := CheckSign(append([]byte{9}, keys[SchemeEd25519][1][1:]...), data, nil)
+	assert.Equal(t, ErrUnknownScheme, err)
+}
+
+func TestSignDigest(t *testing.T) {
+	const data = `forsign`
+	for _, version := range []byte{SchemeECDSA, SchemeEd25519} {
+		private, public, err := GenSchemeKeys(version)
+		require.NoError(t, err)
+		digest, err := Digest(public, data)
+		require.NoError(t, err)
+		sign, err := SignDigest(private, digest)
+		require.NoError(t, err)
+		ok, err := CheckSign(public, data, sign)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestCheckPublicKey(t *testing.T) {
+	for _, version := range []byte{SchemeECDSA, SchemeEd25519} {
+		_, pub, err := GenSchemeKeys(version)
+		require.NoError(t, err)
+		assert.NoError(t, CheckPublicKey(pub))
+		assert.Error(t, CheckPublicKey(pub[:len(pub)-1]))
+	}
+	assert.Error(t, CheckPublicKey(nil))
+	assert.Equal(t, ErrUnknownScheme, CheckPublicKey([]byte{9, 1, 2}))
+}
+
+func TestWipeKey(t *testing.T) {
+	defer func(orig func([]byte)) { wipe = orig }(wipe)
+	var wiped [][]byte
+	wipe = func(buf []byte) {
+		wiped = append(wiped, buf)
+		for i := range buf {
+			buf[i] = 0
+		}
+	}
+
+	for _, version := range []byte{SchemeECDSA, SchemeEd25519} {
+		priv, pub, err := GenSchemeKeys(version)
+		require.NoError(t, err)
+
+		wiped = nil
+		sign, err := Sign(hex.EncodeToString(priv), `data`)
+		require.NoError(t, err)
+		require.NotEmpty(t, wiped, version)
+		for _, buf := range wiped {
+			assert.Equal(t, make([]byte, len(buf)), buf, version)
+		}
+		ok, err := CheckSign(pub, `data`, sign)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		// the public key doesn't share the memory with the private one
+		key := append([]byte{}, priv...)
+		public, err := PrivateToPublic(key)
+		require.NoError(t, err)
+		WipeKey(key)
+		assert.Equal(t, pub, public)
+	}
+}
+
+// FuzzCheckSign checks that the malformed keys and signatures return the error instead of panic
+func FuzzCheckSign(f *testing.F) {
+	for _, item := range signVectors {
+		public, _ := hex.DecodeString(item.public)
+		sign, _ := hex.DecodeString(item.sign)
+		f.Add(public, item.data, sign)
+	}
+	f.Add([]byte{}, `data`, []byte{})
+	f.Add([]byte{SchemeEd25519}, `data`, make([]byte, 64))
+	f.Add(make([]byte, 64), `data`, append([]byte{0x30, 0xff}, make([]byte, 70)...))
+	f.Fuzz(func(t *testing.T, public []byte, data string, sign []byte) {
+		ok, err := CheckSign(public, data, sign)
+		if ok && err != nil {
+			t.Errorf("sign is correct with error %v", err)
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package crypto
+
+import "math/big"
+
+// wipe overwrites the buffer of the secret with zeros. It's the variable, so the tests
+// can check that the secret buffers are wiped
+var wipe = func(buf []byte) {
+	for i := range buf {
+		buf[i] = 0
+	}
+}
+
+// WipeKey overwrites the buffer of the private key with zeros when the key isn't used anymore
+func WipeKey(key []byte) {
+	wipe(key)
+}
+
+// wipeInt overwrites the words of the secret big number with zeros
+func wipeInt(x *big.Int) {
+	words := x.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+}
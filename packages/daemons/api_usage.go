@@ -0,0 +1,41 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package daemons
+
+import (
+	"context"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/usage"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// APIUsage writes the counters of the API calls to the database
+func APIUsage(ctx context.Context, d *daemon) error {
+	d.sleepTime = time.Minute
+	if conf.Config.APIUsage.FlushPeriod > 0 {
+		d.sleepTime = time.Duration(conf.Config.APIUsage.FlushPeriod) * time.Second
+	}
+	if err := usage.Flush(); err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("writing api usage")
+		return err
+	}
+	return nil
+}
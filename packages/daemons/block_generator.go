@@ -19,6 +19,8 @@ package daemons
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
+	"sort"
 	"time"
 
 	"github.com/GenesisKernel/go-genesis/packages/block"
@@ -28,6 +30,7 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/model"
 	"github.com/GenesisKernel/go-genesis/packages/notificator"
 	"github.com/GenesisKernel/go-genesis/packages/service"
+	"github.com/GenesisKernel/go-genesis/packages/signer"
 	"github.com/GenesisKernel/go-genesis/packages/transaction"
 	"github.com/GenesisKernel/go-genesis/packages/utils"
 
@@ -37,7 +40,7 @@ import (
 // BlockGenerator is daemon that generates blocks
 func BlockGenerator(ctx context.Context, d *daemon) error {
 	d.sleepTime = time.Second
-	if service.IsNodePaused() {
+	if service.IsNodePaused() || service.IsGenerationPaused() {
 		return nil
 	}
 
@@ -85,28 +88,35 @@ func BlockGenerator(ctx context.Context, d *daemon) error {
 		return err
 	}
 
-	NodePrivateKey, NodePublicKey, err := utils.GetNodeKeys()
-	if err != nil || len(NodePrivateKey) < 1 {
-		if err == nil {
-			d.logger.WithFields(log.Fields{"type": consts.EmptyObject}).Error("node private key is empty")
-		}
+	nodeSigner, err := signer.Get()
+	if err != nil {
 		return err
 	}
+	// the block isn't generated if the signer is unavailable, the node waits for the next time slot
+	nodePublicKey, err := nodeSigner.PublicKey()
+	if err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Warning("signer is unavailable, skipping generation")
+		return nil
+	}
 
 	dtx := DelayedTx{
-		privateKey: NodePrivateKey,
-		publicKey:  NodePublicKey,
-		logger:     d.logger,
+		signer:    nodeSigner,
+		publicKey: hex.EncodeToString(nodePublicKey),
+		logger:    d.logger,
 	}
-	dtx.RunForBlockID(prevBlock.BlockID + 1)
+	dtx.RunForBlock(prevBlock.BlockID+1, time.Now().Unix())
 
-	trs, err := processTransactions(d.logger)
+	budget := service.GetTxBudget()
+	trs, failed, err := processTransactions(d.logger, prevBlock.BlockID+1, budget)
 	if err != nil {
 		return err
 	}
 
 	// Block generation will be started only if we have transactions
 	if len(trs) == 0 {
+		if len(failed) > 0 {
+			budget.Register(prevBlock.BlockID+1, nil, failed)
+		}
 		return nil
 	}
 
@@ -130,12 +140,14 @@ func BlockGenerator(ctx context.Context, d *daemon) error {
 		return nil
 	}
 
-	blockBin, err := generateNextBlock(header, trs, NodePrivateKey, prevBlock.Hash)
+	blockBin, err := generateNextBlock(header, trs, nodeSigner, prevBlock.Hash)
 	if err != nil {
-		return err
+		d.logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Warning("signing block failed, skipping generation")
+		return nil
 	}
 
 	err = block.InsertBlockWOForks(blockBin, true, false)
+	registerBudget(d.logger, budget, header.BlockID, trs, failed, err == nil)
 	if err != nil {
 		return err
 	}
@@ -145,47 +157,86 @@ func BlockGenerator(ctx context.Context, d *daemon) error {
 	return nil
 }
 
-func generateNextBlock(blockHeader *utils.BlockData, trs []*model.Transaction, key string, prevBlockHash []byte) ([]byte, error) {
+func generateNextBlock(blockHeader *utils.BlockData, trs []*model.Transaction, s signer.BlockSigner, prevBlockHash []byte) ([]byte, error) {
 	trData := make([][]byte, 0, len(trs))
 	for _, tr := range trs {
 		trData = append(trData, tr.Data)
 	}
 
-	return block.MarshallBlock(blockHeader, trData, prevBlockHash, key)
+	return block.MarshallBlock(blockHeader, trData, prevBlockHash, s)
 }
 
-func processTransactions(logger *log.Entry) ([]*model.Transaction, error) {
+// processTransactions returns the transactions of the block and the number of the transactions
+// of the keys which have been marked as bad
+func processTransactions(logger *log.Entry, blockID int64, budget *service.TxBudget) ([]*model.Transaction,
+	map[int64]int64, error) {
 	p := new(transaction.Transaction)
 
 	// verify transactions
 	err := transaction.ProcessTransactionsQueue(p.DbTransaction)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	trs, err := model.GetAllUnusedTransactions(syspar.GetMaxTxCount())
+	limit := syspar.GetMaxTxCount()
+	trs, err := model.GetAllUnusedTransactions(limit)
 	if err != nil {
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting all unused transactions")
-		return nil, err
+		return nil, nil, err
 	}
 
-	limits := block.NewLimits(nil)
-	// Checks preprocessing count limits
-	txList := make([]*model.Transaction, 0, len(trs))
-	for i, txItem := range trs {
+	failed := make(map[int64]int64)
+	markBad := func(p *transaction.Transaction, err error) {
+		transaction.MarkTransactionBad(p.DbTransaction, p.TxHash, err.Error())
+		if p.TxKeyID != 0 {
+			failed[p.TxKeyID]++
+		}
+	}
+
+	queue := make([]*queuedTx, 0, len(trs))
+	for _, txItem := range trs {
 		bufTransaction := bytes.NewBuffer(txItem.Data)
 		p, err := transaction.UnmarshallTransaction(bufTransaction)
 		if err != nil {
 			if p != nil {
-				transaction.MarkTransactionBad(p.DbTransaction, p.TxHash, err.Error())
+				markBad(p, err)
 			}
 			continue
 		}
 
 		if err := p.Check(time.Now().Unix(), false); err != nil {
-			transaction.MarkTransactionBad(p.DbTransaction, p.TxHash, err.Error())
+			markBad(p, err)
 			continue
 		}
+		queue = append(queue, &queuedTx{tx: txItem, parsed: p})
+	}
+	orderByKey(queue)
+	queue = deprioritize(queue, blockID, budget)
+
+	// the transactions which have not been got because of the limit can be earlier
+	var pending map[int64]*queuedTx
+	if limit > 0 && len(trs) == limit {
+		if pending, err = pendingByKey(trs); err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting pending transactions of the keys")
+			return nil, nil, err
+		}
+	}
+
+	limits := block.NewLimits(nil)
+	// the key is stopped when its transaction can't be included, the later ones have to wait for it
+	stopped := make(map[int64]bool)
+	// Checks preprocessing count limits
+	txList := make([]*model.Transaction, 0, len(queue))
+	for i, item := range queue {
+		p := item.parsed
+		if p.TxKeyID != 0 {
+			if first, ok := pending[p.TxKeyID]; ok && first.before(item) {
+				stopped[p.TxKeyID] = true
+			}
+			if stopped[p.TxKeyID] {
+				continue
+			}
+		}
 
 		if p.TxSmart != nil {
 			err = limits.CheckLimit(p)
@@ -195,14 +246,154 @@ func processTransactions(logger *log.Entry) ([]*model.Transaction, error) {
 			} else if err != nil {
 				if err == block.ErrLimitSkip {
 					model.IncrementTxAttemptCount(nil, p.TxHash)
+					stopped[p.TxKeyID] = true
 				} else {
-					transaction.MarkTransactionBad(p.DbTransaction, p.TxHash, err.Error())
+					markBad(p, err)
 				}
 				continue
 			}
 		}
-		txList = append(txList, trs[i])
+		txList = append(txList, item.tx)
+	}
+
+	return txList, failed, nil
+}
+
+// deprioritize moves the transactions of the keys which have exceeded the budget to the end of the queue,
+// the order of the transactions of every key isn't changed
+func deprioritize(queue []*queuedTx, blockID int64, budget *service.TxBudget) []*queuedTx {
+	ret := make([]*queuedTx, 0, len(queue))
+	var later []*queuedTx
+	for _, item := range queue {
+		if key := item.parsed.TxKeyID; key != 0 && budget.IsDeprioritized(key, blockID) {
+			later = append(later, item)
+		} else {
+			ret = append(ret, item)
+		}
 	}
+	return append(ret, later...)
+}
 
-	return txList, nil
+// registerBudget adds the transactions of the generated block to the budget. The transactions
+// which have failed in the block are marked as bad, the others have been included if the block is inserted
+func registerBudget(logger *log.Entry, budget *service.TxBudget, blockID int64, trs []*model.Transaction,
+	failed map[int64]int64, inserted bool) {
+	hashes := make([][]byte, len(trs))
+	for i, item := range trs {
+		hashes[i] = item.Hash
+	}
+	list, err := model.GetFailedTxHashes(nil, hashes)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting failed transactions")
+		return
+	}
+	bad := make(map[string]bool, len(list))
+	for _, hash := range list {
+		bad[string(hash)] = true
+	}
+	included := make(map[int64]int64)
+	for _, item := range trs {
+		if item.KeyID == 0 {
+			continue
+		}
+		if bad[string(item.Hash)] {
+			failed[item.KeyID]++
+		} else if inserted {
+			included[item.KeyID]++
+		}
+	}
+	budget.Register(blockID, included, failed)
+}
+
+// queuedTx is the unused transaction which can be included in the block
+type queuedTx struct {
+	tx     *model.Transaction
+	parsed *transaction.Transaction
+}
+
+// before returns true if the transaction has to be executed before the other transaction of the same key.
+// The transactions are ordered by the nonce when both of them have it, otherwise by the time
+func (q *queuedTx) before(other *queuedTx) bool {
+	var nonce, otherNonce int64
+	if q.parsed.TxSmart != nil {
+		nonce = q.parsed.TxSmart.Nonce
+	}
+	if other.parsed.TxSmart != nil {
+		otherNonce = other.parsed.TxSmart.Nonce
+	}
+	if nonce != 0 && otherNonce != 0 && nonce != otherNonce {
+		return nonce < otherNonce
+	}
+	return q.parsed.TxTime < other.parsed.TxTime
+}
+
+// orderByKey orders the transactions of every key. The transactions of the key keep the positions
+// of the key in the queue, so the order of the different keys isn't changed
+func orderByKey(queue []*queuedTx) {
+	positions := make(map[int64][]int)
+	for i, item := range queue {
+		if key := item.parsed.TxKeyID; key != 0 {
+			positions[key] = append(positions[key], i)
+		}
+	}
+	for _, list := range positions {
+		if len(list) < 2 {
+			continue
+		}
+		items := make([]*queuedTx, len(list))
+		for i, pos := range list {
+			items[i] = queue[pos]
+		}
+		sort.SliceStable(items, func(i, j int) bool { return items[i].before(items[j]) })
+		for i, pos := range list {
+			queue[pos] = items[i]
+		}
+	}
+}
+
+// pendingByKey returns the earliest unused transaction of every key of the list which isn't in the list.
+// The erroneous transactions are marked as bad, so they don't stop the key
+func pendingByKey(trs []*model.Transaction) (map[int64]*queuedTx, error) {
+	keys := make([]int64, 0)
+	known := make(map[int64]bool)
+	hashes := make(map[string]bool)
+	for _, item := range trs {
+		if item.KeyID != 0 && !known[item.KeyID] {
+			keys = append(keys, item.KeyID)
+			known[item.KeyID] = true
+		}
+		hashes[string(item.Hash)] = true
+	}
+	pending := make(map[int64]*queuedTx)
+	if len(keys) == 0 {
+		return pending, nil
+	}
+	list, err := model.GetUnusedTransactionsByKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range list {
+		if hashes[string(item.Hash)] {
+			continue
+		}
+		p, err := transaction.UnmarshallTransaction(bytes.NewBuffer(item.Data))
+		if err != nil {
+			if p != nil {
+				transaction.MarkTransactionBad(p.DbTransaction, p.TxHash, err.Error())
+			}
+			continue
+		}
+		if err := p.Check(time.Now().Unix(), false); err != nil {
+			transaction.MarkTransactionBad(p.DbTransaction, p.TxHash, err.Error())
+			continue
+		}
+		if p.TxKeyID == 0 {
+			continue
+		}
+		q := &queuedTx{tx: item, parsed: p}
+		if first, ok := pending[p.TxKeyID]; !ok || q.before(first) {
+			pending[p.TxKeyID] = q
+		}
+	}
+	return pending, nil
 }
@@ -10,66 +10,86 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/crypto"
 	"github.com/GenesisKernel/go-genesis/packages/model"
 	"github.com/GenesisKernel/go-genesis/packages/script"
+	"github.com/GenesisKernel/go-genesis/packages/signer"
 	"github.com/GenesisKernel/go-genesis/packages/smart"
 	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
 
 	log "github.com/sirupsen/logrus"
-	msgpack "gopkg.in/vmihailenco/msgpack.v2"
 )
 
 const (
 	callDelayedContract = "CallDelayedContract"
+	callUpgradeContract = "CallContractUpgrade"
 	firstEcosystemID    = 1
 )
 
 // DelayedTx represents struct which works with delayed contracts
 type DelayedTx struct {
-	logger     *log.Entry
-	privateKey string
-	publicKey  string
+	logger    *log.Entry
+	signer    signer.BlockSigner
+	publicKey string
 }
 
-// RunForBlockID creates the transactions that need to be run for blockID
-func (dtx *DelayedTx) RunForBlockID(blockID int64) {
-	contracts, err := model.GetAllDelayedContractsForBlockID(blockID)
+// RunForBlock creates the transactions of the delayed contracts and of the upgrades of the contracts
+// that need to be run in the block with blockID and blockTime
+func (dtx *DelayedTx) RunForBlock(blockID, blockTime int64) {
+	contracts, err := model.GetDelayedContractsToRun(blockID, blockTime)
 	if err != nil {
 		dtx.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting delayed contracts for block")
 		return
 	}
 
 	for _, c := range contracts {
-		if err := dtx.createTx(c.ID, c.KeyID); err != nil {
+		if err := dtx.createTx(callDelayedContract, c.ID, c.KeyID); err != nil {
 			dtx.logger.WithFields(log.Fields{"error": err}).Debug("can't create transaction for delayed contract")
 		}
 	}
+
+	upgrades, err := model.GetContractUpgradesToRun(blockID)
+	if err != nil {
+		dtx.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting contract upgrades for block")
+		return
+	}
+
+	for _, u := range upgrades {
+		if err := dtx.createTx(callUpgradeContract, u.ID, u.KeyID); err != nil {
+			dtx.logger.WithFields(log.Fields{"error": err}).Debug("can't create transaction for contract upgrade")
+		}
+	}
 }
 
-func (dtx *DelayedTx) createTx(delayedContactID, keyID int64) error {
+func (dtx *DelayedTx) createTx(name string, id, keyID int64) error {
 	vm := smart.GetVM()
-	contract := smart.VMGetContract(vm, callDelayedContract, uint32(firstEcosystemID))
+	contract := smart.VMGetContract(vm, name, uint32(firstEcosystemID))
+	if contract == nil {
+		return fmt.Errorf("contract %s has not been found", name)
+	}
 	info := contract.Block.Info.(*script.ContractInfo)
 
 	params := make([]byte, 0)
-	converter.EncodeLenInt64(&params, delayedContactID)
+	converter.EncodeLenInt64(&params, id)
 
+	now := time.Now().Unix()
 	smartTx := tx.SmartContract{
 		Header: tx.Header{
 			Type:        int(info.ID),
-			Time:        time.Now().Unix(),
+			Time:        now,
 			EcosystemID: firstEcosystemID,
 			KeyID:       keyID,
 			NetworkID:   consts.NETWORK_ID,
+			SignVersion: tx.ForSignVersion,
 		},
-		SignedBy: smart.PubToID(dtx.publicKey),
-		Data:     params,
+		SignedBy:   smart.PubToID(dtx.publicKey),
+		Expiration: now + tx.NodeTxExpiration,
+		Data:       params,
 	}
 
-	signature, err := crypto.Sign(
-		dtx.privateKey,
-		fmt.Sprintf("%s,%d", smartTx.ForSign(), delayedContactID),
+	signature, err := signer.SignData(
+		dtx.signer,
+		fmt.Sprintf("%s,%d", smartTx.ForSign(), id),
 	)
 	if err != nil {
-		dtx.logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("signing by node private key")
+		dtx.logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("signing by node key")
 		return err
 	}
 	smartTx.BinSignatures = converter.EncodeLengthPlusData(signature)
@@ -79,9 +99,9 @@ func (dtx *DelayedTx) createTx(delayedContactID, keyID int64) error {
 		return err
 	}
 
-	data, err := msgpack.Marshal(smartTx)
+	data, err := smartTx.Marshal()
 	if err != nil {
-		dtx.logger.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling smart contract to msgpack")
+		dtx.logger.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling smart contract")
 		return err
 	}
 	data = append([]byte{128}, data...)
@@ -17,6 +17,7 @@
 package daemons
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -28,10 +29,11 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
-	"github.com/GenesisKernel/go-genesis/packages/crypto"
 	"github.com/GenesisKernel/go-genesis/packages/model"
 	"github.com/GenesisKernel/go-genesis/packages/rollback"
 	"github.com/GenesisKernel/go-genesis/packages/service"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
+	"github.com/GenesisKernel/go-genesis/packages/statsd"
 	"github.com/GenesisKernel/go-genesis/packages/tcpserver"
 	"github.com/GenesisKernel/go-genesis/packages/transaction"
 	"github.com/GenesisKernel/go-genesis/packages/utils"
@@ -107,6 +109,8 @@ func blocksCollection(ctx context.Context, d *daemon) (err error) {
 		}
 	}
 
+	service.SetBestBlockID(maxBlockID)
+
 	infoBlock := &model.InfoBlock{}
 	found, err := infoBlock.Get()
 	if err != nil {
@@ -167,10 +171,12 @@ func UpdateChain(ctx context.Context, d *daemon, host string, maxBlockID int64)
 			if !hashMatched {
 				transaction.CleanCache()
 				//it should be fork, replace our previous blocks to ones from the host
-				err := GetBlocks(b.Header.BlockID-1, host)
+				err := GetBlocks(b.Header.BlockID-1, host, maxBlockID)
 				if err != nil {
 					d.logger.WithFields(log.Fields{"error": err, "type": consts.ParserError}).Error("processing block")
-					banNode(host, b, err)
+					if err != ErrLocalChainIsBetter {
+						banNode(host, b, err)
+					}
 					return err
 				}
 			}
@@ -199,7 +205,7 @@ func UpdateChain(ctx context.Context, d *daemon, host string, maxBlockID int64)
 	var err error
 	for blockID := curBlock.BlockID + 1; blockID <= maxBlockID; blockID += int64(tcpserver.BlocksPerRequest) {
 		var rawBlocksChan chan []byte
-		rawBlocksChan, err = utils.GetBlocksBody(host, blockID, tcpserver.BlocksPerRequest, consts.DATA_TYPE_BLOCK_BODY, false)
+		rawBlocksChan, err = tcpserver.GetBlocksBody(host, blockID, tcpserver.BlocksPerRequest, false)
 		if err != nil {
 			d.logger.WithFields(log.Fields{"error": err, "type": consts.BlockError}).Error("getting block body")
 			break
@@ -308,14 +314,55 @@ func filterBannedHosts(hosts []string) ([]string, error) {
 	return goodHosts, nil
 }
 
+// ErrLocalChainIsBetter is returned when the chain of the host doesn't outweigh our chain
+var ErrLocalChainIsBetter = errors.New("local chain is better than alternative")
+
+// chainWeight is the weight of the chain from the fork point
+type chainWeight struct {
+	count int64  // the count of blocks after the fork point
+	hash  []byte // the hash of the last block
+}
+
+// outweighs compares the count of blocks, and the lowest hash wins if the counts are equal
+func (w chainWeight) outweighs(other chainWeight) bool {
+	if w.count != other.count {
+		return w.count > other.count
+	}
+	return len(w.hash) > 0 && len(other.hash) > 0 && bytes.Compare(w.hash, other.hash) < 0
+}
+
 // GetBlocks is returning blocks
-func GetBlocks(blockID int64, host string) error {
+func GetBlocks(blockID int64, host string, maxBlockID int64) error {
 	blocks, err := getBlocks(blockID, host)
 	if err != nil {
 		return err
 	}
 	transaction.CleanCache()
 
+	// get starting blockID from slice of blocks
+	if len(blocks) > 0 {
+		blockID = blocks[len(blocks)-1].Header.BlockID
+		if err := hashBlocks(blocks); err != nil {
+			return err
+		}
+	}
+
+	infoBlock := &model.InfoBlock{}
+	if _, err = infoBlock.Get(); err != nil {
+		log.WithFields(log.Fields{"error": err, "type": consts.DBError}).Error("getting info block")
+		return utils.ErrInfo(err)
+	}
+	local := chainWeight{count: infoBlock.BlockID - blockID + 1, hash: infoBlock.Hash}
+	alternative := chainWeight{count: maxBlockID - blockID + 1}
+	if len(blocks) > 0 && blocks[0].Header.BlockID == maxBlockID {
+		alternative.hash = blocks[0].Header.Hash
+	}
+	if !alternative.outweighs(local) {
+		log.WithFields(log.Fields{"host": host, "fork_block_id": blockID, "local_count": local.count,
+			"alternative_count": alternative.count}).Info("alternative chain is rejected")
+		return ErrLocalChainIsBetter
+	}
+
 	// mark all transaction as unverified
 	_, err = model.MarkVerifiedAndNotUsedTransactionsUnverified()
 	if err != nil {
@@ -326,11 +373,6 @@ func GetBlocks(blockID int64, host string) error {
 		return utils.ErrInfo(err)
 	}
 
-	// get starting blockID from slice of blocks
-	if len(blocks) > 0 {
-		blockID = blocks[len(blocks)-1].Header.BlockID
-	}
-
 	// we have the slice of blocks for applying
 	// first of all we should rollback old blocks
 	block := &model.Block{}
@@ -339,14 +381,64 @@ func GetBlocks(blockID int64, host string) error {
 		log.WithFields(log.Fields{"error": err, "type": consts.DBError}).Error("getting rollback blocks from blockID")
 		return utils.ErrInfo(err)
 	}
-	for _, block := range myRollbackBlocks {
-		err := rollback.RollbackBlock(block.Data, false)
-		if err != nil {
-			return utils.ErrInfo(err)
+
+	logger := log.WithFields(log.Fields{"host": host, "fork_block_id": blockID, "depth": len(myRollbackBlocks),
+		"new_blocks": len(blocks)})
+	logger.Info("blockchain reorganization started")
+	if err = reorganize(myRollbackBlocks, blocks); err != nil {
+		logger.WithFields(log.Fields{"error": err, "type": consts.BlockError}).Error("blockchain reorganization failed, original chain is restored")
+		return err
+	}
+	logger.Info("blockchain reorganization finished")
+
+	if statsd.Client != nil {
+		statsd.Client.Inc(statsd.ReorgCounterName+statsd.Count, 1, 1.0)
+		statsd.Client.Gauge(statsd.ReorgCounterName+statsd.Depth, int64(len(myRollbackBlocks)), 1.0)
+	}
+	return nil
+}
+
+// reorganize replaces our blocks with the blocks of the alternative chain in one db transaction.
+// In the case of any error the db transaction is rolled back and the in-memory state is reloaded
+func reorganize(rollbackBlocks []model.Block, blocks []*block.Block) error {
+	dbTransaction, err := model.StartTransaction()
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "type": consts.DBError}).Error("starting transaction")
+		return utils.ErrInfo(err)
+	}
+
+	restore := func(err error) error {
+		dbTransaction.Rollback()
+		transaction.CleanCache()
+		if err := syspar.SysUpdate(nil); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("updating syspar")
+		}
+		block.SetSysparBlockID()
+		if err := smart.LoadContracts(nil); err != nil {
+			log.WithFields(log.Fields{"type": consts.VMError, "error": err}).Error("loading contracts")
+		}
+		return err
+	}
+
+	for _, b := range rollbackBlocks {
+		if err := rollback.RollbackBlockTx(dbTransaction, b.Data, true); err != nil {
+			return restore(utils.ErrInfo(err))
 		}
 	}
 
-	return processBlocks(blocks)
+	if err := processBlocks(dbTransaction, blocks); err != nil {
+		return restore(err)
+	}
+
+	if err := dbTransaction.Commit(); err != nil {
+		log.WithFields(log.Fields{"error": err, "type": consts.DBError}).Error("committing reorganization")
+		return restore(err)
+	}
+
+	for i := len(blocks) - 1; i >= 0; i-- {
+		blocks[i].Notifications.Send()
+	}
+	return nil
 }
 
 func getBlocks(blockID int64, host string) ([]*block.Block, error) {
@@ -358,7 +450,7 @@ func getBlocks(blockID int64, host string) ([]*block.Block, error) {
 	var count int64
 
 	// load the block bodies from the host
-	blocksCh, err := utils.GetBlocksBody(host, blockID, tcpserver.BlocksPerRequest, consts.DATA_TYPE_BLOCK_BODY, true)
+	blocksCh, err := tcpserver.GetBlocksBody(host, blockID, tcpserver.BlocksPerRequest, true)
 	if err != nil {
 		return nil, utils.ErrInfo(err)
 	}
@@ -397,11 +489,7 @@ func getBlocks(blockID int64, host string) ([]*block.Block, error) {
 		}
 
 		// SIGN from 128 bytes to 512 bytes. Signature of TYPE, BLOCK_ID, PREV_BLOCK_HASH, TIME, WALLET_ID, state_id, MRKL_ROOT
-		forSign := fmt.Sprintf("0,%v,%x,%v,%v,%v,%v,%s",
-			block.Header.BlockID, block.PrevHeader.Hash, block.Header.Time,
-			block.Header.EcosystemID, block.Header.KeyID, block.Header.NodePosition,
-			block.MrklRoot,
-		)
+		forSign := block.ForSign()
 
 		// save the block
 		blocks = append(blocks, block)
@@ -418,51 +506,55 @@ func getBlocks(blockID int64, host string) ([]*block.Block, error) {
 	return blocks, nil
 }
 
-func processBlocks(blocks []*block.Block) error {
-	dbTransaction, err := model.StartTransaction()
-	if err != nil {
-		log.WithFields(log.Fields{"error": err, "type": consts.DBError}).Error("starting transaction")
-		return utils.ErrInfo(err)
-	}
-
-	// go through new blocks from the smallest block_id to the largest block_id
-	prevBlocks := make(map[int64]*block.Block, 0)
-
+// hashBlocks calculates the hashes of the blocks of the alternative chain
+// from the smallest block_id to the largest block_id
+func hashBlocks(blocks []*block.Block) error {
 	for i := len(blocks) - 1; i >= 0; i-- {
 		b := blocks[i]
 
-		if prevBlocks[b.Header.BlockID-1] != nil {
-			b.PrevHeader.Hash = prevBlocks[b.Header.BlockID-1].Header.Hash
-			b.PrevHeader.Time = prevBlocks[b.Header.BlockID-1].Header.Time
-			b.PrevHeader.BlockID = prevBlocks[b.Header.BlockID-1].Header.BlockID
-			b.PrevHeader.EcosystemID = prevBlocks[b.Header.BlockID-1].Header.EcosystemID
-			b.PrevHeader.KeyID = prevBlocks[b.Header.BlockID-1].Header.KeyID
-			b.PrevHeader.NodePosition = prevBlocks[b.Header.BlockID-1].Header.NodePosition
+		if i < len(blocks)-1 {
+			prev := blocks[i+1]
+			b.PrevHeader.Hash = prev.Header.Hash
+			b.PrevHeader.Time = prev.Header.Time
+			b.PrevHeader.BlockID = prev.Header.BlockID
+			b.PrevHeader.EcosystemID = prev.Header.EcosystemID
+			b.PrevHeader.KeyID = prev.Header.KeyID
+			b.PrevHeader.NodePosition = prev.Header.NodePosition
 		}
 
-		forSha := fmt.Sprintf("%d,%x,%s,%d,%d,%d,%d", b.Header.BlockID, b.PrevHeader.Hash, b.MrklRoot, b.Header.Time, b.Header.EcosystemID, b.Header.KeyID, b.Header.NodePosition)
-		hash, err := crypto.DoubleHash([]byte(forSha))
+		hash, err := b.Hash()
 		if err != nil {
-			log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Fatal("double hashing block")
+			log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("double hashing block")
+			return err
 		}
 		b.Header.Hash = hash
+	}
+	return nil
+}
+
+func processBlocks(dbTransaction *model.DbTransaction, blocks []*block.Block) error {
+	// go through new blocks from the smallest block_id to the largest block_id
+	for i := len(blocks) - 1; i >= 0; i-- {
+		b := blocks[i]
 
 		if err := b.Check(); err != nil {
-			dbTransaction.Rollback()
 			return err
 		}
 
 		if err := b.Play(dbTransaction); err != nil {
-			dbTransaction.Rollback()
 			return utils.ErrInfo(err)
 		}
-		prevBlocks[b.Header.BlockID] = b
+		if conf.Config.Archive.Enabled {
+			if err := model.IndexRowHistory(dbTransaction, b.Header.BlockID); err != nil {
+				log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("indexing row history")
+				return err
+			}
+		}
 
 		// for last block we should update block info
 		if i == 0 {
 			err := block.UpdBlockInfo(dbTransaction, b)
 			if err != nil {
-				dbTransaction.Rollback()
 				return utils.ErrInfo(err)
 			}
 		}
@@ -479,17 +571,13 @@ func processBlocks(blocks []*block.Block) error {
 		b := blocks[i]
 		// Delete old blocks from blockchain
 		bl := &model.Block{}
-		err = bl.DeleteById(dbTransaction, b.Header.BlockID)
-		if err != nil {
-			dbTransaction.Rollback()
+		if err := bl.DeleteById(dbTransaction, b.Header.BlockID); err != nil {
 			return err
 		}
 		// insert new blocks into blockchain
 		if err := block.InsertIntoBlockchain(dbTransaction, b); err != nil {
-			dbTransaction.Rollback()
 			return err
 		}
 	}
-
-	return dbTransaction.Commit()
+	return nil
 }
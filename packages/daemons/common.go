@@ -51,6 +51,10 @@ var daemonsList = map[string]func(context.Context, *daemon) error{
 	"Confirmations":     Confirmations,
 	"Notificator":       Notificate,
 	"Scheduler":         Scheduler,
+	"VDESync":           VDESync,
+	"APIUsage":          APIUsage,
+	"IdempotencyKeys":   IdempotencyKeys,
+	"RowHistory":        RowHistory,
 }
 
 var serverList = []string{
@@ -60,8 +64,10 @@ var serverList = []string{
 	"QueueParserBlocks",
 	"Disseminator",
 	"Confirmations",
-	"Notificator",
 	"Scheduler",
+	"APIUsage",
+	"IdempotencyKeys",
+	"RowHistory",
 }
 
 var rollbackList = []string{
@@ -162,6 +168,8 @@ func getDaemonsToStart() []string {
 		return []string{
 			"Notificator",
 			"Scheduler",
+			"VDESync",
+			"APIUsage",
 		}
 	}
 
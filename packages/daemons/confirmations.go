@@ -17,16 +17,19 @@
 package daemons
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"net"
 	"strings"
 	"time"
 
+	"github.com/GenesisKernel/go-genesis/packages/conf"
 	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
 	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/statsd"
 	"github.com/GenesisKernel/go-genesis/packages/tcpserver"
 
 	log "github.com/sirupsen/logrus"
@@ -34,6 +37,19 @@ import (
 
 var tick int
 
+const (
+	// confirmationPeersBlocks is the number of the last blocks which answers of the remote nodes are kept
+	confirmationPeersBlocks = 1000
+	// defaultForkWarning is used if the number of the unconfirmed own blocks isn't set in the config
+	defaultForkWarning = 3
+)
+
+var (
+	// ownBlockFailures is the number of the own blocks in a row which aren't confirmed by the quorum
+	ownBlockFailures    int64
+	lastOwnBlockFailure int64
+)
+
 // Confirmations gets and checks blocks from nodes
 // Getting amount of nodes, which has the same hash as we do
 func Confirmations(ctx context.Context, d *daemon) error {
@@ -49,8 +65,9 @@ func Confirmations(ctx context.Context, d *daemon) error {
 	var startBlockID int64
 
 	// check last blocks, but not more than 5
+	quorum := syspar.GetConfirmationQuorum()
 	confirmations := &model.Confirmation{}
-	_, err := confirmations.GetGoodBlock(consts.MIN_CONFIRMED_NODES)
+	_, err := confirmations.GetGoodBlock(int(quorum))
 	if err != nil {
 		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting good block")
 		return err
@@ -78,10 +95,10 @@ func Confirmations(ctx context.Context, d *daemon) error {
 	}
 	d.logger.WithFields(log.Fields{"start_block_id": startBlockID, "last_block_id": lastBlockID}).Info("confirming blocks from to")
 
-	return confirmationsBlocks(ctx, d, lastBlockID, startBlockID)
+	return confirmationsBlocks(ctx, d, lastBlockID, startBlockID, quorum)
 }
 
-func confirmationsBlocks(ctx context.Context, d *daemon, lastBlockID, startBlockID int64) error {
+func confirmationsBlocks(ctx context.Context, d *daemon, lastBlockID, startBlockID, quorum int64) error {
 	for blockID := lastBlockID; blockID >= startBlockID; blockID-- {
 		if err := ctx.Err(); err != nil {
 			d.logger.WithFields(log.Fields{"type": consts.ContextError, "error": err}).Error("error in context")
@@ -106,28 +123,29 @@ func confirmationsBlocks(ctx context.Context, d *daemon, lastBlockID, startBlock
 			return err
 		}
 
-		ch := make(chan string)
+		ch := make(chan model.ConfirmationPeer)
+		var count int
 		for i := 0; i < len(hosts); i++ {
 			host, err := NormalizeHostAddress(hosts[i], consts.DEFAULT_TCP_PORT)
 			if err != nil {
-				d.logger.WithFields(log.Fields{"host": host[i], "type": consts.ParseError, "error": err}).Error("wrong host address")
+				d.logger.WithFields(log.Fields{"host": hosts[i], "type": consts.ParseError, "error": err}).Error("wrong host address")
 				continue
 			}
 
 			d.logger.WithFields(log.Fields{"host": host, "block_id": blockID}).Debug("checking block id confirmed at node")
-			go func() {
-				IsReachable(host, blockID, ch, d.logger)
-			}()
+			count++
+			go IsReachable(host, blockID, block.Hash, ch, d.logger)
 		}
-		var answer string
 		var st0, st1 int64
-		for i := 0; i < len(hosts); i++ {
-			answer = <-ch
-			if answer == hashStr {
+		peers := make([]model.ConfirmationPeer, 0, count)
+		for i := 0; i < count; i++ {
+			peer := <-ch
+			if peer.State == model.ConfirmationHave {
 				st1++
 			} else {
 				st0++
 			}
+			peers = append(peers, peer)
 		}
 		confirmation := &model.Confirmation{}
 		confirmation.GetConfirmation(blockID)
@@ -135,64 +153,97 @@ func confirmationsBlocks(ctx context.Context, d *daemon, lastBlockID, startBlock
 		confirmation.Good = int32(st1)
 		confirmation.Bad = int32(st0)
 		confirmation.Time = int32(time.Now().Unix())
-		if err = confirmation.Save(); err != nil {
+		confirmation.Quorum = int32(quorum)
+		if err = confirmation.SaveWithPeers(peers); err != nil {
 			d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("saving confirmation")
 			return err
 		}
 
-		if blockID > startBlockID && st1 >= consts.MIN_CONFIRMED_NODES {
+		// the last block can be not received by the nodes yet
+		if quorum > 0 && blockID < lastBlockID && block.KeyID == conf.Config.KeyID {
+			checkOwnBlock(blockID, st1 >= quorum, d.logger)
+		}
+
+		if blockID > startBlockID && st1 >= quorum {
 			break
 		}
 	}
 
+	if err := model.DeleteConfirmationPeers(lastBlockID - confirmationPeersBlocks); err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting old confirmation peers")
+		return err
+	}
 	return nil
 }
 
-func checkConf(host string, blockID int64, logger *log.Entry) string {
-	conn, err := net.DialTimeout("tcp", host, 5*time.Second)
-	if err != nil {
-		logger.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "host": host, "block_id": blockID}).Debug("dialing to host")
-		return "0"
+// checkOwnBlock counts the own blocks in a row which aren't confirmed by the quorum,
+// the node is probably forked if there are too many of them
+func checkOwnBlock(blockID int64, confirmed bool, logger *log.Entry) {
+	if confirmed {
+		if blockID > lastOwnBlockFailure {
+			ownBlockFailures = 0
+		}
+		return
 	}
-	defer conn.Close()
-
-	conn.SetReadDeadline(time.Now().Add(consts.READ_TIMEOUT * time.Second))
-	conn.SetWriteDeadline(time.Now().Add(consts.WRITE_TIMEOUT * time.Second))
-
-	if err = tcpserver.SendRequestType(tcpserver.RequestTypeConfirmation, conn); err != nil {
-		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host, "block_id": blockID}).Error("sending request type")
-		return "0"
+	if blockID <= lastOwnBlockFailure {
+		return
 	}
+	lastOwnBlockFailure = blockID
+	ownBlockFailures++
 
-	req := &tcpserver.ConfirmRequest{
-		BlockID: uint32(blockID),
+	limit := conf.Config.Confirmations.ForkWarning
+	if limit <= 0 {
+		limit = defaultForkWarning
+	}
+	if ownBlockFailures < limit {
+		return
 	}
-	if err = tcpserver.SendRequest(req, conn); err != nil {
-		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host, "block_id": blockID}).Error("sending confirmation request")
-		return "0"
+	logger.WithFields(log.Fields{"type": consts.ForkWarning, "block_id": blockID, "blocks": ownBlockFailures}).Warn("own blocks aren't confirmed by quorum, node may be forked")
+	if statsd.Client != nil {
+		statsd.Client.Inc(statsd.ForkCounterName+statsd.Count, 1, 1.0)
+		statsd.Client.Gauge(statsd.ForkCounterName+statsd.Depth, ownBlockFailures, 1.0)
 	}
+}
 
-	resp := &tcpserver.ConfirmResponse{}
-	err = tcpserver.ReadRequest(resp, conn)
+func checkConf(host string, blockID int64, logger *log.Entry) ([]byte, error) {
+	resp, err := tcpserver.GetConfirmation(host, blockID)
 	if err != nil {
-		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host, "block_id": blockID}).Error("receiving confirmation response")
-		return "0"
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host, "block_id": blockID}).Debug("receiving confirmation response")
+		return nil, err
 	}
-	return string(converter.BinToHex(resp.Hash))
+	return resp.Hash, nil
 }
 
-// IsReachable checks if there is blockID on the host
-func IsReachable(host string, blockID int64, ch0 chan string, logger *log.Entry) {
-	ch := make(chan string, 1)
+// IsReachable checks if there is the block with the hash on the host. The state is timeout
+// if the host doesn't answer in time or can't be reached
+func IsReachable(host string, blockID int64, hash []byte, ch0 chan model.ConfirmationPeer, logger *log.Entry) {
+	peer := model.ConfirmationPeer{Host: host, State: model.ConfirmationTimeout}
+	ch := make(chan []byte, 1)
 	go func() {
-		ch <- checkConf(host, blockID, logger)
+		resp, err := checkConf(host, blockID, logger)
+		if err != nil {
+			close(ch)
+			return
+		}
+		ch <- resp
 	}()
+
+	timeout := conf.Config.Confirmations.PeerTimeout
+	if timeout <= 0 {
+		timeout = consts.WAIT_CONFIRMED_NODES
+	}
 	select {
-	case reachable := <-ch:
-		ch0 <- reachable
-	case <-time.After(consts.WAIT_CONFIRMED_NODES * time.Second):
-		ch0 <- "0"
+	case resp, ok := <-ch:
+		if ok {
+			peer.Hash = resp
+			peer.State = model.ConfirmationMissing
+			if bytes.Equal(resp, hash) {
+				peer.State = model.ConfirmationHave
+			}
+		}
+	case <-time.After(time.Duration(timeout) * time.Second):
 	}
+	ch0 <- peer
 }
 
 // NormalizeHostAddress get address. if port not defined returns combined string with ip and defaultPort
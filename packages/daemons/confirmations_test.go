@@ -0,0 +1,83 @@
+package daemons
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/tcpserver"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckOwnBlock(t *testing.T) {
+	defer func() {
+		ownBlockFailures, lastOwnBlockFailure = 0, 0
+	}()
+	logger := log.WithFields(log.Fields{})
+
+	checkOwnBlock(10, false, logger)
+	checkOwnBlock(10, false, logger)
+	checkOwnBlock(12, false, logger)
+	assert.Equal(t, int64(2), ownBlockFailures)
+
+	// the older block doesn't reset the failures of the newer blocks
+	checkOwnBlock(8, true, logger)
+	assert.Equal(t, int64(2), ownBlockFailures)
+
+	checkOwnBlock(13, true, logger)
+	assert.Equal(t, int64(0), ownBlockFailures)
+}
+
+func TestIsReachable(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	hash := bytes.Repeat([]byte{1}, 32)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			dType := &tcpserver.RequestType{}
+			req := &tcpserver.ConfirmRequest{}
+			if tcpserver.ReadRequest(dType, conn) == nil && tcpserver.ReadRequest(req, conn) == nil {
+				resp := &tcpserver.ConfirmResponse{Hash: make([]byte, 32)}
+				if req.BlockID == 1 {
+					resp.Hash = hash
+				}
+				if req.BlockID < 3 {
+					tcpserver.SendRequest(resp, conn)
+				} else {
+					// the node doesn't answer
+					buf := make([]byte, 1)
+					conn.Read(buf)
+				}
+			}
+			conn.Close()
+		}
+	}()
+
+	conf.Config.Confirmations.PeerTimeout = 1
+	defer func() {
+		conf.Config.Confirmations.PeerTimeout = 0
+	}()
+	logger := log.WithFields(log.Fields{})
+	ch := make(chan model.ConfirmationPeer, 1)
+	for blockID, state := range map[int64]string{
+		1: model.ConfirmationHave,
+		2: model.ConfirmationMissing,
+		3: model.ConfirmationTimeout,
+	} {
+		IsReachable(l.Addr().String(), blockID, hash, ch, logger)
+		peer := <-ch
+		assert.Equal(t, state, peer.State, "block %d", blockID)
+		assert.Equal(t, l.Addr().String(), peer.Host)
+	}
+}
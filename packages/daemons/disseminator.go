@@ -19,7 +19,6 @@ package daemons
 import (
 	"bytes"
 	"context"
-	"io"
 	"sync"
 
 	"github.com/GenesisKernel/go-genesis/packages/conf"
@@ -27,6 +26,7 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
 	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/tcpserver"
 	"github.com/GenesisKernel/go-genesis/packages/utils"
 
 	log "github.com/sirupsen/logrus"
@@ -149,45 +149,29 @@ func sendHashes(fullNodeID int64, logger *log.Entry) error {
 	return nil
 }
 
-func sendHashesResp(resp []byte, w io.Writer, logger *log.Entry) error {
+func sendHashesResp(resp []byte, logger *log.Entry) ([]byte, error) {
 	var buf bytes.Buffer
-	lr := len(resp)
-	switch true {
-	// We got response that mean other full node have all of transactions and we don't need to do anything
-	case lr == 0:
-		return nil
-	// We got response that mean that node doesn't know about some transactions. We need to send them
-	case lr >= consts.HashSize:
-		for len(resp) >= consts.HashSize {
-			// Parse the list of requested transactions
-			txHash := converter.BytesShift(&resp, consts.HashSize)
-			tr := &model.Transaction{}
-			_, err := tr.Read(txHash)
-			if err != nil {
-				logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("reading transaction by hash")
-				return err
-			}
-
-			if len(tr.Data) > 0 {
-				buf.Write(converter.EncodeLengthPlusData(tr.Data))
-			}
-		}
+	if int64(len(resp)) > syspar.GetMaxTxSize() {
+		logger.WithFields(log.Fields{"size": len(resp), "max_size": syspar.GetMaxTxSize(), "type": consts.ParameterExceeded}).Warning("response size is larger than max tx size")
+		return nil, nil
+	}
 
-		// write out the requested transactions
-		_, err := w.Write(converter.DecToBin(buf.Len(), 4))
+	// the node doesn't know about some transactions, we need to send them
+	for len(resp) >= consts.HashSize {
+		// Parse the list of requested transactions
+		txHash := converter.BytesShift(&resp, consts.HashSize)
+		tr := &model.Transaction{}
+		_, err := tr.Read(txHash)
 		if err != nil {
-			logger.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("writing tx size")
-			return err
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("reading transaction by hash")
+			return nil, err
 		}
-		_, err = w.Write(buf.Bytes())
-		if err != nil {
-			logger.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("writing tx data")
-			return err
+
+		if len(tr.Data) > 0 {
+			buf.Write(converter.EncodeLengthPlusData(tr.Data))
 		}
-		return nil
 	}
-
-	return nil
+	return buf.Bytes(), nil
 }
 
 func prepareHashReq(block *model.InfoBlock, trs *[]model.Transaction, nodeID int64) []byte {
@@ -230,7 +214,7 @@ func MarshallTrHash(tr model.Transaction) []byte {
 	return tr.Hash
 }
 
-func sendPacketToAll(reqType int, buf []byte, respHand func(resp []byte, w io.Writer, logger *log.Entry) error, logger *log.Entry) error {
+func sendPacketToAll(reqType int, buf []byte, respHand func(resp []byte, logger *log.Entry) ([]byte, error), logger *log.Entry) error {
 
 	hosts, err := filterBannedHosts(syspar.GetRemoteHosts())
 	if err != nil {
@@ -250,74 +234,17 @@ func sendPacketToAll(reqType int, buf []byte, respHand func(resp []byte, w io.Wr
 	return nil
 }
 
-/*
-Packet format:
-type  2 bytes
-len   4 bytes
-data  len bytes
-*/
-
-func sendDRequest(host string, reqType int, buf []byte, respHandler func([]byte, io.Writer, *log.Entry) error, logger *log.Entry) error {
-	conn, err := utils.TCPConn(host)
-	if err != nil {
-		logger.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "host": host}).Debug("tcp connection to host")
-		return err
-	}
-	defer conn.Close()
-
-	// type
-	_, err = conn.Write(converter.DecToBin(reqType, 2))
-	if err != nil {
-		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Error("writing request type to host")
-		return err
-	}
-
-	// data size
-	size := converter.DecToBin(len(buf), 4)
-	_, err = conn.Write(size)
-	if err != nil {
-		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Error("writing data size to host")
-		return err
+func sendDRequest(host string, reqType int, buf []byte, respHandler func([]byte, *log.Entry) ([]byte, error), logger *log.Entry) error {
+	var err error
+	if reqType == I_AM_FULL_NODE {
+		err = tcpserver.SendHashes(host, buf, func(resp []byte) ([]byte, error) {
+			return respHandler(resp, logger)
+		})
+	} else {
+		err = tcpserver.SendTransactions(host, buf)
 	}
-
-	// data
-	_, err = conn.Write(buf)
 	if err != nil {
-		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Error("writing data to host")
-		return err
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Debug("sending data to host")
 	}
-
-	// if response handler exist, read the answer and call handler
-	if respHandler != nil {
-		buf := make([]byte, 4)
-
-		// read data size
-		_, err = io.ReadFull(conn, buf)
-		if err != nil {
-			if err == io.EOF {
-				logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Warn("connection closed unexpectedly")
-			} else {
-				logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Error("reading data size")
-			}
-		}
-
-		respSize := converter.BinToDec(buf)
-		if respSize > syspar.GetMaxTxSize() {
-			logger.WithFields(log.Fields{"size": respSize, "max_size": syspar.GetMaxTxSize(), "type": consts.ParameterExceeded}).Warning("response size is larger than max tx size")
-			return nil
-		}
-		// read the data
-		resp := make([]byte, respSize)
-		_, err = io.ReadFull(conn, resp)
-		if err != nil {
-			logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Error("reading data")
-			return err
-		}
-		err = respHandler(resp, conn, logger)
-		if err != nil {
-			logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Error("reading data")
-			return err
-		}
-	}
-	return nil
+	return err
 }
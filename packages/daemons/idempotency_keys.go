@@ -0,0 +1,42 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package daemons
+
+import (
+	"context"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// IdempotencyKeys deletes the idempotency keys of the transactions which are out of the window
+func IdempotencyKeys(ctx context.Context, d *daemon) error {
+	d.sleepTime = 10 * time.Minute
+	count, err := model.DeleteExpiredIdempotencyKeys(time.Now().Unix() - conf.Config.IdempotencyTTL)
+	if err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting expired idempotency keys")
+		return err
+	}
+	if count > 0 {
+		d.logger.WithFields(log.Fields{"count": count}).Debug("deleted expired idempotency keys")
+	}
+	return nil
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
 	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/service"
+	"github.com/GenesisKernel/go-genesis/packages/tracing"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -31,6 +33,14 @@ func Monitoring(w http.ResponseWriter, r *http.Request) {
 	addKey(&buf, "info_block_ecosystem_id", infoBlock.EcosystemID)
 	addKey(&buf, "info_block_node_position", infoBlock.NodePosition)
 	addKey(&buf, "full_nodes_count", syspar.GetNumberOfNodes())
+	addKey(&buf, "fast_sync", fmt.Sprint(service.IsFastSyncBlock(infoBlock.BlockID+1)))
+	addKey(&buf, "generation_paused", fmt.Sprint(service.IsGenerationPaused()))
+	reason, at, stopped := service.NetworkStopped()
+	addKey(&buf, "network_stopped", fmt.Sprint(stopped))
+	if stopped {
+		addKey(&buf, "network_stop_reason", reason)
+		addKey(&buf, "network_stop_time", at.Unix())
+	}
 
 	block := &model.Block{}
 	_, err = block.GetMaxBlock()
@@ -54,6 +64,12 @@ func Monitoring(w http.ResponseWriter, r *http.Request) {
 	}
 	addKey(&buf, "transactions_count", trCount)
 
+	// timings of the block processing stages are in microseconds
+	stages, _ := tracing.CollectStageMetrics()
+	for _, v := range stages {
+		addKey(&buf, v.Metric+"."+v.Key, v.Value)
+	}
+
 	w.Write(buf.Bytes())
 }
 
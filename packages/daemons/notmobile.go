@@ -93,6 +93,11 @@ func WaitForSignals() {
 				"type": consts.IOError, "error": err, "path": conf.Config.GetPidPath(),
 			}).Error("removing file")
 		}
+		if err = os.Remove(conf.Config.GetAdminSocketPath()); err != nil && !os.IsNotExist(err) {
+			log.WithFields(log.Fields{
+				"type": consts.IOError, "error": err, "path": conf.Config.GetAdminSocketPath(),
+			}).Error("removing file")
+		}
 
 		os.Exit(1)
 
@@ -0,0 +1,75 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package daemons
+
+import (
+	"context"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/statsd"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RowHistory indexes the states of the rows in the blocks which have been played before
+// the archive mode was enabled. The new blocks are indexed when they are played
+func RowHistory(ctx context.Context, d *daemon) error {
+	d.sleepTime = time.Minute
+	if !conf.Config.Archive.Enabled {
+		return nil
+	}
+	low, err := backfillRowHistory()
+	if err != nil {
+		return err
+	}
+	if low > 1 {
+		d.sleepTime = time.Second
+	}
+	size, err := model.RowHistorySize()
+	if err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting row history size")
+		return err
+	}
+	statsd.Client.Gauge(statsd.ArchiveCounterName+statsd.Size, size, 1.0)
+	return nil
+}
+
+func backfillRowHistory() (int64, error) {
+	DBLock()
+	defer DBUnlock()
+
+	infoBlock := &model.InfoBlock{}
+	if _, err := infoBlock.Get(); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting info block")
+		return 0, err
+	}
+	dbTransaction, err := model.StartTransaction()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("starting transaction")
+		return 0, err
+	}
+	low, err := model.BackfillRowHistory(dbTransaction, infoBlock.BlockID, conf.Config.Archive.Chunk)
+	if err != nil {
+		dbTransaction.Rollback()
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("indexing row history")
+		return 0, err
+	}
+	return low, dbTransaction.Commit()
+}
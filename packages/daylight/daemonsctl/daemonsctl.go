@@ -23,6 +23,11 @@ func RunAllDaemons() error {
 			log.Errorf("can't read system parameters: %s", utils.ErrInfo(err))
 			return err
 		}
+		if err = block.SetSysparBlockID(); err != nil {
+			return err
+		}
+		// the missing parameters are only logged, the node uses the defaults for them
+		syspar.CheckParameters(nil, smart.ExtendCostParams()...)
 
 		if data, ok := block.GetDataFromFirstBlock(); ok {
 			syspar.SetFirstBlockData(data)
@@ -26,6 +26,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/GenesisKernel/go-genesis/packages/admin"
 	"github.com/GenesisKernel/go-genesis/packages/api"
 	conf "github.com/GenesisKernel/go-genesis/packages/conf"
 	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
@@ -39,6 +40,7 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/service"
 	"github.com/GenesisKernel/go-genesis/packages/smart"
 	"github.com/GenesisKernel/go-genesis/packages/statsd"
+	"github.com/GenesisKernel/go-genesis/packages/tracing"
 	"github.com/GenesisKernel/go-genesis/packages/utils"
 	"github.com/GenesisKernel/go-genesis/packages/vdemanager"
 
@@ -228,6 +230,7 @@ func Start() {
 
 	publisher.InitCentrifugo(conf.Config.Centrifugo)
 	initStatsd()
+	tracing.Init(conf.Config.Tracing)
 
 	err = initLogs()
 	if err != nil {
@@ -270,6 +273,14 @@ func Start() {
 			if err != nil {
 				log.WithError(err).Fatal("Can't init ban service")
 			}
+
+			if err = service.InitFastSync(); err != nil {
+				log.WithError(err).Error("Can't restore fast sync state")
+			}
+
+			if err = service.InitGenerationPause(); err != nil {
+				log.WithError(err).Error("Can't restore pause of block generation")
+			}
 		}
 
 		if conf.Config.IsSupportingVDE() {
@@ -284,6 +295,10 @@ func Start() {
 		}
 	}
 
+	if _, err = admin.Start(conf.Config.GetAdminSocketPath()); err != nil {
+		log.WithError(err).Error("Can't start admin socket")
+	}
+
 	daemons.WaitForSignals()
 
 	initRoutes(conf.Config.HTTP.Str())
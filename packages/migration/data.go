@@ -149,4 +149,145 @@ var (
 		DROP TABLE IF EXISTS "stop_daemons"; CREATE TABLE "stop_daemons" (
 		"stop_time" int NOT NULL DEFAULT '0'
 		);`
+
+	migrationAPIUsage = `DROP TABLE IF EXISTS "api_usage"; CREATE TABLE "api_usage" (
+		"day" bigint NOT NULL DEFAULT '0',
+		"ecosystem" bigint NOT NULL DEFAULT '0',
+		"route" varchar(255) NOT NULL DEFAULT '',
+		"calls" bigint NOT NULL DEFAULT '0'
+		);
+		ALTER TABLE ONLY "api_usage" ADD CONSTRAINT api_usage_pkey PRIMARY KEY (day, ecosystem, route);`
+
+	migrationNotNullText = `DO $$
+		DECLARE
+			col record;
+		BEGIN
+			FOR col IN SELECT table_name, column_name FROM information_schema.columns
+				WHERE table_schema = 'public' AND table_name ~ '^[0-9]+_' AND is_nullable = 'YES' AND
+				data_type IN ('text', 'character varying')
+			LOOP
+				EXECUTE format('UPDATE %I SET %I = '''' WHERE %I IS NULL', col.table_name, col.column_name, col.column_name);
+				EXECUTE format('ALTER TABLE %I ALTER COLUMN %I SET DEFAULT '''', ALTER COLUMN %I SET NOT NULL',
+					col.table_name, col.column_name, col.column_name);
+			END LOOP;
+		END $$;`
+
+	migrationHistoryLedger = `DO $$
+		DECLARE
+			tbl record;
+		BEGIN
+			FOR tbl IN SELECT table_name FROM information_schema.tables
+				WHERE table_schema = 'public' AND table_name ~ '^[0-9]+_history$'
+			LOOP
+				EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS "commission" decimal(30) NOT NULL DEFAULT ''0'',
+					ADD COLUMN IF NOT EXISTS "type" varchar(32) NOT NULL DEFAULT ''transfer''', tbl.table_name);
+				EXECUTE format('UPDATE %I SET "type" = ''emission'' WHERE sender_id = 0', tbl.table_name);
+				EXECUTE format('UPDATE %I SET columns = columns || ''{"commission": "ContractConditions(\"MainCondition\")",
+					"type": "ContractConditions(\"MainCondition\")"}''::jsonb WHERE name = ''history''',
+					regexp_replace(tbl.table_name, '_history$', '_tables'));
+			END LOOP;
+		END $$;`
+
+	migrationIdempotencyKeys = `DROP TABLE IF EXISTS "idempotency_keys"; CREATE TABLE "idempotency_keys" (
+		"key_id" bigint NOT NULL DEFAULT '0',
+		"idempotency_key" varchar(255) NOT NULL DEFAULT '',
+		"fingerprint" bytea NOT NULL DEFAULT '',
+		"tx_hash" bytea NOT NULL DEFAULT '',
+		"created_at" bigint NOT NULL DEFAULT '0'
+		);
+		ALTER TABLE ONLY "idempotency_keys" ADD CONSTRAINT idempotency_keys_pkey PRIMARY KEY (key_id, idempotency_key);
+		CREATE INDEX "idempotency_keys_index_created" ON "idempotency_keys" (created_at);`
+
+	migrationContractUpgrades = `DO $$
+		DECLARE
+			tbl record;
+		BEGIN
+			FOR tbl IN SELECT table_name FROM information_schema.tables
+				WHERE table_schema = 'public' AND table_name ~ '^[0-9]+_contracts$'
+			LOOP
+				EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS "version" bigint NOT NULL DEFAULT ''1'',
+					ADD COLUMN IF NOT EXISTS "upgrade_error" text NOT NULL DEFAULT ''''', tbl.table_name);
+				EXECUTE format('UPDATE %I SET columns = columns || ''{"version": "false", "upgrade_error": "false"}''::jsonb
+					WHERE name = ''contracts''', regexp_replace(tbl.table_name, '_contracts$', '_tables'));
+			END LOOP;
+			-- the table of the first ecosystem is created by the first block on the new nodes
+			IF to_regclass('"1_tables"') IS NOT NULL AND to_regclass('"1_contract_upgrades"') IS NULL THEN
+				CREATE TABLE IF NOT EXISTS "1_contract_upgrades" (
+					"id" bigint NOT NULL DEFAULT '0',
+					"ecosystem" bigint NOT NULL DEFAULT '0',
+					"contract_id" bigint NOT NULL DEFAULT '0',
+					"contract" varchar(255) NOT NULL DEFAULT '',
+					"key_id" bigint NOT NULL DEFAULT '0',
+					"old_version" bigint NOT NULL DEFAULT '0',
+					"new_version" bigint NOT NULL DEFAULT '0',
+					"block_id" bigint NOT NULL DEFAULT '0',
+					"executed_block" bigint NOT NULL DEFAULT '0',
+					"result" text NOT NULL DEFAULT '',
+					"error" text NOT NULL DEFAULT ''
+				);
+				ALTER TABLE ONLY "1_contract_upgrades" ADD CONSTRAINT "1_contract_upgrades_pkey" PRIMARY KEY ("id");
+				CREATE INDEX "1_contract_upgrades_index_pending" ON "1_contract_upgrades" ("executed_block", "block_id");
+				INSERT INTO "1_tables" ("id", "name", "permissions", "columns", "conditions")
+				SELECT max(id) + 1, 'contract_upgrades',
+					'{"insert": "false", "update": "false", "new_column": "ContractConditions(\"MainCondition\")"}',
+					'{"ecosystem": "false", "contract_id": "false", "contract": "false", "key_id": "false",
+						"old_version": "false", "new_version": "false", "block_id": "false",
+						"executed_block": "false", "result": "false", "error": "false"}',
+					'ContractConditions("MainCondition")'
+				FROM "1_tables";
+			END IF;
+		END $$;`
+
+	migrationConfirmationPeers = `ALTER TABLE "confirmations" ADD COLUMN IF NOT EXISTS "quorum" int NOT NULL DEFAULT '0';
+		DROP TABLE IF EXISTS "confirmation_peers"; CREATE TABLE "confirmation_peers" (
+		"block_id" bigint NOT NULL DEFAULT '0',
+		"host" varchar(255) NOT NULL DEFAULT '',
+		"state" varchar(16) NOT NULL DEFAULT '',
+		"hash" bytea NOT NULL DEFAULT ''
+		);
+		ALTER TABLE ONLY "confirmation_peers" ADD CONSTRAINT confirmation_peers_pkey PRIMARY KEY (block_id, host);`
+
+	migrationRowHistory = `DROP TABLE IF EXISTS "row_history"; CREATE TABLE "row_history" (
+		"table_name" varchar(255) NOT NULL DEFAULT '',
+		"table_id" varchar(255) NOT NULL DEFAULT '',
+		"block_id" bigint NOT NULL DEFAULT '0',
+		"data" jsonb
+		);
+		ALTER TABLE ONLY "row_history" ADD CONSTRAINT row_history_pkey PRIMARY KEY (table_name, table_id, block_id);
+		CREATE INDEX "row_history_index_block" ON "row_history" (block_id);
+
+		DROP TABLE IF EXISTS "row_history_status"; CREATE TABLE "row_history_status" (
+		"id" integer NOT NULL DEFAULT '1',
+		"low" bigint NOT NULL DEFAULT '0',
+		"high" bigint NOT NULL DEFAULT '0'
+		);
+		ALTER TABLE ONLY "row_history_status" ADD CONSTRAINT row_history_status_pkey PRIMARY KEY (id);`
+
+	migrationTxStatusError = `ALTER TABLE "transactions_status" ALTER COLUMN "error" TYPE varchar(2048);`
+
+	migrationMetricDelta = `DO $$
+		BEGIN
+			-- the table of the first ecosystem is created by the first block on the new nodes
+			IF to_regclass('"1_metrics"') IS NOT NULL THEN
+				ALTER TABLE "1_metrics" ADD COLUMN IF NOT EXISTS "delta" bigint NOT NULL DEFAULT '0';
+				UPDATE "1_metrics" SET "delta" = "value";
+				UPDATE "1_tables" SET columns = columns || '{"delta": "ContractConditions(\"MainCondition\")"}'::jsonb
+					WHERE name = 'metrics';
+				-- the customized contract isn't changed
+				UPDATE "1_contracts" SET value = replace(replace(value,
+					'DBUpdate("metrics", id, "value", Int(v["value"]))',
+					'DBUpdate("metrics", id, "value,delta", Int(v["value"]), Int(v["delta"]))'),
+					'DBInsert("metrics", "time,key,metric,value", v["time"], v["key"], v["metric"], Int(v["value"]))',
+					'DBInsert("metrics", "time,key,metric,value,delta", v["time"], v["key"], v["metric"], Int(v["value"]), Int(v["delta"]))')
+					WHERE name = 'UpdateMetrics';
+			END IF;
+		END $$;`
+
+	migrationOrphanedBinaries = `DROP TABLE IF EXISTS "orphaned_binaries"; CREATE TABLE "orphaned_binaries" (
+		"ecosystem" bigint NOT NULL DEFAULT '0',
+		"binary_id" bigint NOT NULL DEFAULT '0',
+		"hash" varchar(32) NOT NULL DEFAULT '',
+		"found_at" bigint NOT NULL DEFAULT '0'
+		);
+		ALTER TABLE ONLY "orphaned_binaries" ADD CONSTRAINT orphaned_binaries_pkey PRIMARY KEY (ecosystem, binary_id, hash);`
 )
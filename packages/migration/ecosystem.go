@@ -47,12 +47,24 @@ var schemaEcosystem = `DROP TABLE IF EXISTS "%[1]d_keys"; CREATE TABLE "%[1]d_ke
 		"blocked" bigint NOT NULL DEFAULT '0'
 		);
 		ALTER TABLE ONLY "%[1]d_keys" ADD CONSTRAINT "%[1]d_keys_pkey" PRIMARY KEY (id);
+
+		DROP TABLE IF EXISTS "%[1]d_keys_history"; CREATE TABLE "%[1]d_keys_history" (
+		"id" bigint NOT NULL DEFAULT '0',
+		"key_id" bigint NOT NULL DEFAULT '0',
+		"pub" bytea NOT NULL DEFAULT '',
+		"block_id" int NOT NULL DEFAULT '0',
+		"txhash" bytea NOT NULL DEFAULT ''
+		);
+		ALTER TABLE ONLY "%[1]d_keys_history" ADD CONSTRAINT "%[1]d_keys_history_pkey" PRIMARY KEY (id);
+		CREATE INDEX "%[1]d_keys_history_index_key" ON "%[1]d_keys_history" (key_id);
 		
 		DROP TABLE IF EXISTS "%[1]d_history"; CREATE TABLE "%[1]d_history" (
 		"id" bigint NOT NULL  DEFAULT '0',
 		"sender_id" bigint NOT NULL DEFAULT '0',
 		"recipient_id" bigint NOT NULL DEFAULT '0',
 		"amount" decimal(30) NOT NULL DEFAULT '0',
+		"commission" decimal(30) NOT NULL DEFAULT '0',
+		"type" varchar(32) NOT NULL DEFAULT 'transfer',
 		"comment" text NOT NULL DEFAULT '',
 		"block_id" int  NOT NULL DEFAULT '0',
 		"txhash" bytea  NOT NULL DEFAULT '',
@@ -136,7 +148,9 @@ var schemaEcosystem = `DROP TABLE IF EXISTS "%[1]d_keys"; CREATE TABLE "%[1]d_ke
 		"token_id" bigint NOT NULL DEFAULT '1',
 		"active" character(1) NOT NULL DEFAULT '0',
 		"conditions" text  NOT NULL DEFAULT '',
-		"app_id" bigint NOT NULL DEFAULT '1'
+		"app_id" bigint NOT NULL DEFAULT '1',
+		"version" bigint NOT NULL DEFAULT '1',
+		"upgrade_error" text NOT NULL DEFAULT ''
 		);
 		ALTER TABLE ONLY "%[1]d_contracts" ADD CONSTRAINT "%[1]d_contracts_pkey" PRIMARY KEY (id);
 		
@@ -223,6 +237,46 @@ var schemaEcosystem = `DROP TABLE IF EXISTS "%[1]d_keys"; CREATE TABLE "%[1]d_ke
 		);
 		ALTER TABLE ONLY "%[1]d_roles_participants" ADD CONSTRAINT "%[1]d_roles_participants_pkey" PRIMARY KEY ("id");
 
+		DROP TABLE IF EXISTS "%[1]d_votings";
+		CREATE TABLE "%[1]d_votings" (
+			"id" bigint NOT NULL DEFAULT '0',
+			"subject" jsonb,
+			"voters" jsonb,
+			"votes" jsonb NOT NULL DEFAULT '{}',
+			"threshold" bigint NOT NULL DEFAULT '0',
+			"deadline" bigint NOT NULL DEFAULT '0',
+			"creator" bigint NOT NULL DEFAULT '0',
+			"status" bigint NOT NULL DEFAULT '0',
+			"result" text NOT NULL DEFAULT '',
+			"date_created" timestamp
+		);
+		ALTER TABLE ONLY "%[1]d_votings" ADD CONSTRAINT "%[1]d_votings_pkey" PRIMARY KEY ("id");
+		CREATE INDEX "%[1]d_votings_index_status" ON "%[1]d_votings" (status);
+
+		DROP TABLE IF EXISTS "%[1]d_events";
+		CREATE TABLE "%[1]d_events" (
+			"id" bigint NOT NULL DEFAULT '0',
+			"block_id" bigint NOT NULL DEFAULT '0',
+			"tx_hash" varchar(128) NOT NULL DEFAULT '',
+			"contract" varchar(255) NOT NULL DEFAULT '',
+			"name" varchar(255) NOT NULL DEFAULT '',
+			"data" jsonb
+		);
+		ALTER TABLE ONLY "%[1]d_events" ADD CONSTRAINT "%[1]d_events_pkey" PRIMARY KEY ("id");
+		CREATE INDEX "%[1]d_events_index_block" ON "%[1]d_events" (block_id);
+		CREATE INDEX "%[1]d_events_index_name" ON "%[1]d_events" (name);
+
+		DROP TABLE IF EXISTS "%[1]d_founder_history";
+		CREATE TABLE "%[1]d_founder_history" (
+			"id" bigint NOT NULL DEFAULT '0',
+			"old_founder" bigint NOT NULL DEFAULT '0',
+			"new_founder" bigint NOT NULL DEFAULT '0',
+			"block_id" bigint NOT NULL DEFAULT '0',
+			"tx_hash" varchar(128) NOT NULL DEFAULT '',
+			"date_created" timestamp
+		);
+		ALTER TABLE ONLY "%[1]d_founder_history" ADD CONSTRAINT "%[1]d_founder_history_pkey" PRIMARY KEY ("id");
+
 
 		DROP TABLE IF EXISTS "%[1]d_members";
 		CREATE TABLE "%[1]d_members" (
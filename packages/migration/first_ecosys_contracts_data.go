@@ -621,7 +621,7 @@ VALUES ('2', 'DelApplication', 'contract DelApplication {
         if !$TokenEcosystem {
             $TokenEcosystem = 1
         } else {
-            if !SysFuel($TokenEcosystem) {
+            if !HasFuelRate($TokenEcosystem) {
                 warning Sprintf("Ecosystem %%d is not system", $TokenEcosystem)
             }
         }
@@ -798,7 +798,7 @@ VALUES ('2', 'DelApplication', 'contract DelApplication {
         creators["contracts"] = "NewContract"
         creators["tables"] = "NewTable"
 
-        var dataImport array
+        var dataImport, contracts array
         dataImport = JSONDecode($Data)
         var i int
         while i<Len(dataImport){
@@ -835,12 +835,18 @@ VALUES ('2', 'DelApplication', 'contract DelApplication {
                     contractName = creators[$Type]
                 }
 
-                if contractName != ""{
+                if contractName == "NewContract" {
+                    // the new contracts are compiled together
+                    contracts = Append(contracts, cdata)
+                } elif contractName != ""{
                     CallContract(contractName, cdata)
                 }
             }
             i=i+1
         }
+        if Len(contracts) > 0 {
+            CreateContracts(contracts)
+        }
         // Println(Sprintf("> time: %%v", $time))
     }
 }', %[1]d, 'ContractConditions("MainCondition")', 1),
@@ -1118,14 +1124,7 @@ VALUES ('2', 'DelApplication', 'contract DelApplication {
 		}
 	}
 	action {
-		DBUpdate("keys", $key_id,"-amount", $amount)
-		if DBFind("keys").Columns("id").WhereId($recipient).One("id") == nil {
-			DBInsert("keys", "id,amount",  $recipient, $amount)
-		} else {
-			DBUpdate("keys", $recipient,"+amount", $amount)
-		}
-		DBInsert("history", "sender_id,recipient_id,amount,comment,block_id,txhash",
-				$key_id, $recipient, $amount, $Comment, $block, $txhash)
+		TransferTokens($recipient, $amount, $Comment)
 	}
 }', %[1]d, 'ContractConditions("MainCondition")', 1),
 ('29','ActivateContract','contract ActivateContract {
@@ -1271,6 +1270,7 @@ VALUES ('2', 'DelApplication', 'contract DelApplication {
         Name string
         Value string
         Conditions string "optional"
+        ActivationBlock int "optional"
     }
 
     conditions {
@@ -1284,7 +1284,7 @@ VALUES ('2', 'DelApplication', 'contract DelApplication {
     }
 
     action {
-        DBUpdateSysParam($Name, $Value, $Conditions)
+        DBScheduleSysParam($Name, $Value, $Conditions, $ActivationBlock)
     }
 }', %[1]d, 'ContractConditions("MainCondition")', 2),
 ('37', 'NewDelayedContract','contract NewDelayedContract {
@@ -1294,6 +1294,8 @@ VALUES ('2', 'DelApplication', 'contract DelApplication {
 		Conditions string
 		BlockID int "optional"
 		Limit int "optional"
+		Time int "optional"
+		EveryTime int "optional"
 	}
 	conditions {
 		ValidateCondition($Conditions, $ecosystem_id)
@@ -1306,16 +1308,23 @@ VALUES ('2', 'DelApplication', 'contract DelApplication {
 			error Sprintf("Unknown contract %%s", $Contract)
 		}
 
-		if $BlockID == 0 {
-			$BlockID = $block + $EveryBlock
-		}
+		if $Time > 0 {
+			if $Time <= $block_time {
+				error "The time must be greater than the current time"
+			}
+			$BlockID = 0
+		} else {
+			if $BlockID == 0 {
+				$BlockID = $block + $EveryBlock
+			}
 
-		if $BlockID <= $block {
-			error "The blockID must be greater than the current blockID"
+			if $BlockID <= $block {
+				error "The blockID must be greater than the current blockID"
+			}
 		}
 	}
 	action {
-		DBInsert("delayed_contracts", "contract,key_id,block_id,every_block,limit,conditions", $Contract, $key_id, $BlockID, $EveryBlock, $Limit, $Conditions)
+		DBInsert("delayed_contracts", "contract,key_id,block_id,every_block,limit,conditions,run_time,every_time", $Contract, $key_id, $BlockID, $EveryBlock, $Limit, $Conditions, $Time, $EveryTime)
 	}
 }', %[1]d, 'ContractConditions("MainCondition")', 1),
 ('38', 'EditDelayedContract','contract EditDelayedContract {
@@ -1327,6 +1336,8 @@ VALUES ('2', 'DelApplication', 'contract DelApplication {
 		BlockID int "optional"
 		Limit int "optional"
 		Deleted int "optional"
+		Time int "optional"
+		EveryTime int "optional"
 	}
 	conditions {
 		ConditionById("delayed_contracts", true)
@@ -1339,16 +1350,23 @@ VALUES ('2', 'DelApplication', 'contract DelApplication {
 			error Sprintf("Unknown contract %%s", $Contract)
 		}
 
-		if $BlockID == 0 {
-			$BlockID = $block + $EveryBlock
-		}
+		if $Time > 0 {
+			if $Time <= $block_time {
+				error "The time must be greater than the current time"
+			}
+			$BlockID = 0
+		} else {
+			if $BlockID == 0 {
+				$BlockID = $block + $EveryBlock
+			}
 
-		if $BlockID <= $block {
-			error "The blockID must be greater than the current blockID"
+			if $BlockID <= $block {
+				error "The blockID must be greater than the current blockID"
+			}
 		}
 	}
 	action {
-		DBUpdate("delayed_contracts", $Id, "contract,key_id,block_id,every_block,counter,limit,deleted,conditions", $Contract, $key_id, $BlockID, $EveryBlock, 0, $Limit, $Deleted, $Conditions)
+		DBUpdate("delayed_contracts", $Id, "contract,key_id,block_id,every_block,counter,limit,deleted,conditions,run_time,every_time", $Contract, $key_id, $BlockID, $EveryBlock, 0, $Limit, $Deleted, $Conditions, $Time, $EveryTime)
 	}
 }', %[1]d, 'ContractConditions("MainCondition")', 1),
 ('39', 'CallDelayedContract','contract CallDelayedContract {
@@ -1367,25 +1385,41 @@ VALUES ('2', 'DelApplication', 'contract DelApplication {
 			error "Access denied"
 		}
 
-		if $block < Int($cur["block_id"]) {
+		if Int($cur["run_time"]) > 0 {
+			if $block_time < Int($cur["run_time"]) {
+				error Sprintf("Delayed contract %%d must run at %%s, current time %%d", $Id, $cur["run_time"], $block_time)
+			}
+		} elif $block < Int($cur["block_id"]) {
 			error Sprintf("Delayed contract %%d must run on block %%s, current block %%d", $Id, $cur["block_id"], $block)
 		}
 	}
 	action {
-		var limit, counter, block_id int
+		var limit, counter, block_id, run_time, deleted int
 
 		limit = Int($cur["limit"])
 		counter = Int($cur["counter"])+1
-		block_id = $block
+		block_id = Int($cur["block_id"])
+		run_time = Int($cur["run_time"])
 
-		if limit == 0 || limit > counter {
-			block_id = block_id + Int($cur["every_block"])
+		if run_time > 0 {
+			run_time = $block_time + Int($cur["every_time"])
+			if Int($cur["every_time"]) == 0 {
+				deleted = 1
+			}
+		} else {
+			block_id = $block + Int($cur["every_block"])
+			if Int($cur["every_block"]) == 0 {
+				deleted = 1
+			}
+		}
+		if limit > 0 && counter >= limit {
+			deleted = 1
 		}
 
-		DBUpdate("delayed_contracts", $Id, "counter,block_id", counter, block_id)
+		var params, ret map
+		ret = TryCallContract($cur["contract"], params)
 
-		var params map
-		CallContract($cur["contract"], params)
+		DBUpdate("delayed_contracts", $Id, "counter,block_id,run_time,deleted,last_block,last_result,last_error", counter, block_id, run_time, deleted, $block, ret["result"], ret["error"])
 	}
 }', %[1]d, 'ContractConditions("MainCondition")', 1),
 ('40', 'NewUser','contract NewUser {
@@ -1438,9 +1472,9 @@ VALUES ('2', 'DelApplication', 'contract DelApplication {
 			v = values[i]
 			id = Int(DBFind("metrics").Columns("id").Where("time = ? AND key = ? AND metric = ?", v["time"], v["key"], v["metric"]).One("id"))
 			if id != 0 {
-				DBUpdate("metrics", id, "value", Int(v["value"]))
+				DBUpdate("metrics", id, "value,delta", Int(v["value"]), Int(v["delta"]))
 			} else {
-				DBInsert("metrics", "time,key,metric,value", v["time"], v["key"], v["metric"], Int(v["value"]))
+				DBInsert("metrics", "time,key,metric,value,delta", v["time"], v["key"], v["metric"], Int(v["value"]), Int(v["delta"]))
 			}
 			i = i + 1
 		}
@@ -2590,5 +2624,234 @@ VALUES ('2', 'DelApplication', 'contract DelApplication {
         warning "Value must be greater than zero"
       }
     }
+}', %[1]d, 'ContractConditions("MainCondition")', 2),
+('114', 'max_tx_memory', 'contract max_tx_memory {
+    data {
+      Value string
+    }
+  
+    conditions {
+      if Size($Value) == 0 {
+        warning "Value was not received"
+      }
+      if Int($Value) <= 0 {
+        warning "Value must be greater than zero"
+      }
+    }
+}', %[1]d, 'ContractConditions("MainCondition")', 2),
+('115', 'max_call_depth', 'contract max_call_depth {
+    data {
+      Value string
+    }
+  
+    conditions {
+      if Size($Value) == 0 {
+        warning "Value was not received"
+      }
+      if Int($Value) <= 0 {
+        warning "Value must be greater than zero"
+      }
+    }
+}', %[1]d, 'ContractConditions("MainCondition")', 2),
+('116', 'ChangeKey', 'contract ChangeKey {
+	data {
+		NewPubkey string
+	}
+	conditions {
+		if Size($NewPubkey) == 0 {
+			error "Wrong pubkey"
+		}
+	}
+	action {
+		UpdateKeyPub($NewPubkey)
+	}
+}', %[1]d, 'ContractConditions("MainCondition")', 1),
+('117', 'ecosystem_overrides', 'contract ecosystem_overrides {
+    data {
+      Value string
+    }
+  
+    conditions {
+      if Size($Value) == 0 {
+        warning "Value was not received"
+      }
+    }
+}', %[1]d, 'ContractConditions("MainCondition")', 2),
+('118', 'EditEcosystemTokenDecimals', 'contract EditEcosystemTokenDecimals {
+	data {
+		Decimals int
+	}
+	conditions {
+		if $Decimals < 0 {
+			error "Decimals must be greater than or equal to 0"
+		}
+	}
+	action {
+		EditEcosysTokenDecimals($Decimals)
+	}
+}', %[1]d, 'ContractConditions("MainCondition")', 1),
+('119', 'strict_tx_replay', 'contract strict_tx_replay {
+    data {
+      Value string
+    }
+  
+    conditions {
+      if $Value != "true" && $Value != "false" {
+        warning "Value must be true or false"
+      }
+    }
+}', %[1]d, 'ContractConditions("MainCondition")', 2),
+('120', 'disable_forsign_v1', 'contract disable_forsign_v1 {
+    data {
+      Value string
+    }
+  
+    conditions {
+      if $Value != "true" && $Value != "false" {
+        warning "Value must be true or false"
+      }
+    }
+}', %[1]d, 'ContractConditions("MainCondition")', 2),
+('121', 'TransferEcosystemOwnership', 'contract TransferEcosystemOwnership {
+	data {
+		NewFounder int
+	}
+	conditions {
+		if $NewFounder == 0 {
+			warning "New founder was not received"
+		}
+	}
+	action {
+		TransferEcosystemOwnership($NewFounder)
+	}
+}', %[1]d, 'ContractConditions("MainCondition")', 1),
+('122', 'ImportApply', 'contract ImportApply {
+    data {
+        ImportId string
+        AppName string
+        Part int
+        Parts int
+        Items int
+        Data string
+    }
+
+    conditions {
+        if $Part < 0 || $Part >= $Parts {
+            warning Sprintf("Part %%d is out of %%d parts of the import", $Part, $Parts)
+        }
+        $progress_id = 0
+        $applied_parts = 0
+        $applied_items = 0
+        var progress map
+        progress = DBFind("buffer_data").Columns("id,value->import_id,value->applied_parts,value->applied_items").Where("member_id=$ and key=$", $key_id, "import_progress").Row()
+        if progress {
+            $progress_id = Int(progress["id"])
+            if progress["value.import_id"] == $ImportId {
+                $applied_parts = Int(progress["value.applied_parts"])
+                $applied_items = Int(progress["value.applied_items"])
+            }
+        }
+        if $Part > $applied_parts {
+            warning Sprintf("Part %%d of the import must be applied before part %%d", $applied_parts, $Part)
+        }
+    }
+
+    action {
+        // the parts which have been already applied are skipped on retry
+        if $Part == $applied_parts {
+            if $Part == 0 {
+                var app_id, info_id int
+                app_id = DBFind("applications").Columns("id").Where("name=$", $AppName).One("id")
+                if !app_id {
+                    DBInsert("applications", "name,conditions", $AppName, "true")
+                }
+                // Import finds the application by the name in import_info
+                var info map
+                info["app_name"] = $AppName
+                info_id = DBFind("buffer_data").Where("member_id=$ and key=$", $key_id, "import_info").One("id")
+                if info_id {
+                    DBUpdate("buffer_data", Int(info_id), "value", info)
+                } else {
+                    DBInsert("buffer_data", "member_id,key,value", $key_id, "import_info", info)
+                }
+            }
+            var params, progress map
+            params["Data"] = $Data
+            CallContract("Import", params)
+
+            var items array
+            items = JSONDecode($Data)
+            progress["import_id"] = $ImportId
+            progress["app_name"] = $AppName
+            progress["parts"] = $Parts
+            progress["items"] = $Items
+            progress["applied_parts"] = $Part + 1
+            progress["applied_items"] = $applied_items + Len(items)
+            if $progress_id {
+                DBUpdate("buffer_data", $progress_id, "value", progress)
+            } else {
+                DBInsert("buffer_data", "member_id,key,value", $key_id, "import_progress", progress)
+            }
+        }
+    }
+}', %[1]d, 'ContractConditions("MainCondition")', 1),
+('123', 'max_alter_column_rows', 'contract max_alter_column_rows {
+    data {
+      Value string
+    }
+  
+    conditions {
+      if Size($Value) == 0 {
+        warning "Value was not received"
+      }
+      if Int($Value) <= 0 {
+        warning "Value must be greater than zero"
+      }
+    }
+}', %[1]d, 'ContractConditions("MainCondition")', 2),
+('124', 'CallContractUpgrade','contract CallContractUpgrade {
+	data {
+		Id int
+	}
+	conditions {
+		$cur = DBFind("contract_upgrades").Where("id = ? and executed_block = 0", $Id).Row()
+		if !$cur {
+			error Sprintf("Contract upgrade %%d does not exist or has been executed", $Id)
+		}
+		if $key_id != Int($cur["key_id"]) {
+			error "Access denied"
+		}
+		if $block < Int($cur["block_id"]) {
+			error Sprintf("Contract upgrade %%d must run on block %%s, current block %%d", $Id, $cur["block_id"], $block)
+		}
+	}
+	action {
+		RunContractUpgrade($Id)
+	}
+}', %[1]d, 'ContractConditions("MainCondition")', 1),
+('125', 'confirmation_quorum', 'contract confirmation_quorum {
+    data {
+      Value string
+    }
+
+    conditions {
+      if Size($Value) == 0 {
+        warning "Value was not received"
+      }
+      if Int($Value) < 0 {
+        warning "Value must be greater than or equal to zero"
+      }
+    }
+}', %[1]d, 'ContractConditions("MainCondition")', 2),
+('126', 'format_contracts', 'contract format_contracts {
+    data {
+      Value string
+    }
+
+    conditions {
+      if $Value != "true" && $Value != "false" {
+        warning "Value must be true or false"
+      }
+    }
 }', %[1]d, 'ContractConditions("MainCondition")', 2);
 `
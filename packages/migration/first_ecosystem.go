@@ -6,7 +6,9 @@ DROP TABLE IF EXISTS "1_ecosystems";
 CREATE TABLE "1_ecosystems" (
 		"id" bigint NOT NULL DEFAULT '0',
 		"name"	varchar(255) NOT NULL DEFAULT '',
-		"is_valued" bigint NOT NULL DEFAULT '0'
+		"is_valued" bigint NOT NULL DEFAULT '0',
+		"token_decimals" bigint NOT NULL DEFAULT '18',
+		"total_supply" decimal(30) NOT NULL DEFAULT '0' CHECK (total_supply >= 0)
 );
 ALTER TABLE ONLY "1_ecosystems" ADD CONSTRAINT "1_ecosystems_pkey" PRIMARY KEY ("id");
 
@@ -16,7 +18,9 @@ DROP TABLE IF EXISTS "1_system_parameters";
 	"id" bigint NOT NULL DEFAULT '0',
 	"name" varchar(255)  NOT NULL DEFAULT '',
 	"value" text NOT NULL DEFAULT '',
-	"conditions" text  NOT NULL DEFAULT ''
+	"conditions" text  NOT NULL DEFAULT '',
+	"pending_value" text NOT NULL DEFAULT '',
+	"activation_block" bigint NOT NULL DEFAULT '0'
 	);
 	ALTER TABLE ONLY "1_system_parameters" ADD CONSTRAINT "1_system_parameters_pkey" PRIMARY KEY (id);
 	CREATE INDEX "1_system_parameters_index_name" ON "1_system_parameters" (name);
@@ -32,10 +36,16 @@ DROP TABLE IF EXISTS "1_system_parameters";
 		"counter" int NOT NULL DEFAULT '0',
 		"limit" int NOT NULL DEFAULT '0',
 		"deleted" boolean NOT NULL DEFAULT 'false',
-		"conditions" text NOT NULL DEFAULT ''
+		"conditions" text NOT NULL DEFAULT '',
+		"run_time" bigint NOT NULL DEFAULT '0',
+		"every_time" bigint NOT NULL DEFAULT '0',
+		"last_block" bigint NOT NULL DEFAULT '0',
+		"last_result" text NOT NULL DEFAULT '',
+		"last_error" text NOT NULL DEFAULT ''
 	);
 	ALTER TABLE ONLY "1_delayed_contracts" ADD CONSTRAINT "1_delayed_contracts_pkey" PRIMARY KEY ("id");
 	CREATE INDEX "1_delayed_contracts_index_block_id" ON "1_delayed_contracts" ("block_id");
+	CREATE INDEX "1_delayed_contracts_index_run_time" ON "1_delayed_contracts" ("run_time");
 
 	DROP TABLE IF EXISTS "1_metrics";
 	CREATE TABLE "1_metrics" (
@@ -43,7 +53,8 @@ DROP TABLE IF EXISTS "1_system_parameters";
 		"time" bigint NOT NULL DEFAULT '0',
 		"metric" varchar(255) NOT NULL,
 		"key" varchar(255) NOT NULL,
-		"value" bigint NOT NULL
+		"value" bigint NOT NULL,
+		"delta" bigint NOT NULL DEFAULT '0'
 	);
 	ALTER TABLE ONLY "1_metrics" ADD CONSTRAINT "1_metrics_pkey" PRIMARY KEY (id);
 	CREATE INDEX "1_metrics_unique_index" ON "1_metrics" (metric, time, "key");
@@ -67,4 +78,26 @@ DROP TABLE IF EXISTS "1_system_parameters";
 		"reason" TEXT NOT NULL DEFAULT ''
 	);
 	ALTER TABLE ONLY "1_node_ban_logs" ADD CONSTRAINT "1_node_ban_logs_pkey" PRIMARY KEY ("id");
+
+	DROP TABLE IF EXISTS "1_tx_nonces"; CREATE TABLE "1_tx_nonces" (
+		"id" bigint NOT NULL DEFAULT '0',
+		"nonce" bigint NOT NULL DEFAULT '0'
+	);
+	ALTER TABLE ONLY "1_tx_nonces" ADD CONSTRAINT "1_tx_nonces_pkey" PRIMARY KEY ("id");
+
+	DROP TABLE IF EXISTS "1_contract_upgrades"; CREATE TABLE "1_contract_upgrades" (
+		"id" bigint NOT NULL DEFAULT '0',
+		"ecosystem" bigint NOT NULL DEFAULT '0',
+		"contract_id" bigint NOT NULL DEFAULT '0',
+		"contract" varchar(255) NOT NULL DEFAULT '',
+		"key_id" bigint NOT NULL DEFAULT '0',
+		"old_version" bigint NOT NULL DEFAULT '0',
+		"new_version" bigint NOT NULL DEFAULT '0',
+		"block_id" bigint NOT NULL DEFAULT '0',
+		"executed_block" bigint NOT NULL DEFAULT '0',
+		"result" text NOT NULL DEFAULT '',
+		"error" text NOT NULL DEFAULT ''
+	);
+	ALTER TABLE ONLY "1_contract_upgrades" ADD CONSTRAINT "1_contract_upgrades_pkey" PRIMARY KEY ("id");
+	CREATE INDEX "1_contract_upgrades_index_pending" ON "1_contract_upgrades" ("executed_block", "block_id");
 `
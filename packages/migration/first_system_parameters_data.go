@@ -92,5 +92,14 @@ INSERT INTO "1_system_parameters" ("id","name", "value", "conditions") VALUES
 	('64','incorrect_blocks_per_day','10','true'),
 	('65','node_ban_time','86400000','true'),
 	('66','local_node_ban_time','1800000','true'),
-	('67','max_forsign_size', '1000000', 'true');
+	('67','max_forsign_size', '1000000', 'true'),
+	('68','max_tx_memory', '134217728', 'true'),
+	('69','max_call_depth', '1000', 'true'),
+	('70','ecosystem_overrides', 'contract_price,column_price,table_price,menu_price,page_price,extend_cost_*', 'true'),
+	('71','strict_tx_replay', 'false', 'true'),
+	('72','disable_forsign_v1', 'false', 'true'),
+	('73','max_alter_column_rows', '100000', 'true'),
+	('74','confirmation_quorum', '0', 'true'),
+	('75','founder_amount', '50000', 'true'),
+	('76','format_contracts', 'false', 'true');
 `
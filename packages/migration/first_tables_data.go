@@ -12,14 +12,20 @@ INSERT INTO "1_tables" ("id", "name", "permissions","columns", "conditions") VAL
 			"counter": "ContractConditions(\"MainCondition\")",
 			"limit": "ContractConditions(\"MainCondition\")",
 			"deleted": "ContractConditions(\"MainCondition\")",
-			"conditions": "ContractConditions(\"MainCondition\")"}',
+			"conditions": "ContractConditions(\"MainCondition\")",
+			"run_time": "ContractConditions(\"MainCondition\")",
+			"every_time": "ContractConditions(\"MainCondition\")",
+			"last_block": "ContractConditions(\"MainCondition\")",
+			"last_result": "ContractConditions(\"MainCondition\")",
+			"last_error": "ContractConditions(\"MainCondition\")"}',
 			'ContractConditions("MainCondition")'
 		),
 		(
 			'21',
 			'ecosystems',
 			'{"insert": "true", "update": "ContractConditions(\"MainCondition\")", "new_column": "ContractConditions(\"MainCondition\")"}',
-			'{"name": "ContractConditions(\"MainCondition\")"}',
+			'{"name": "ContractConditions(\"MainCondition\")", "token_decimals": "ContractConditions(\"MainCondition\")",
+				"total_supply": "false"}',
 			'ContractConditions("MainCondition")'
 		),
 		(
@@ -28,14 +34,16 @@ INSERT INTO "1_tables" ("id", "name", "permissions","columns", "conditions") VAL
 			'{"insert": "ContractConditions(\"MainCondition\")", "update": "ContractConditions(\"MainCondition\")","new_column": "ContractConditions(\"MainCondition\")"}',
 			'{"time": "ContractConditions(\"MainCondition\")",
 				"metric": "ContractConditions(\"MainCondition\")","key": "ContractConditions(\"MainCondition\")",
-				"value": "ContractConditions(\"MainCondition\")"}',
+				"value": "ContractConditions(\"MainCondition\")", "delta": "ContractConditions(\"MainCondition\")"}',
 			'ContractConditions("MainCondition")'
 		),
 		(
 			'23',
 			'system_parameters',
 			'{"insert": "false", "update": "ContractAccess(\"1@UpdateSysParam\")","new_column": "ContractConditions(\"MainCondition\")"}',
-			'{"value": "ContractConditions(\"MainCondition\")"}',
+			'{"value": "ContractConditions(\"MainCondition\")",
+				"pending_value": "ContractConditions(\"MainCondition\")",
+				"activation_block": "ContractConditions(\"MainCondition\")"}',
 			'ContractConditions("MainCondition")'
 		),
 		(
@@ -75,5 +83,21 @@ INSERT INTO "1_tables" ("id", "name", "permissions","columns", "conditions") VAL
 				"reason": "ContractConditions(\"MainCondition\")"
 			}',
 			'ContractConditions(\"MainCondition\")'
+		),
+		(
+			'26',
+			'tx_nonces',
+			'{"insert": "false", "update": "false", "new_column": "ContractConditions(\"MainCondition\")"}',
+			'{"nonce": "false"}',
+			'ContractConditions(\"MainCondition\")'
+		),
+		(
+			'27',
+			'contract_upgrades',
+			'{"insert": "false", "update": "false", "new_column": "ContractConditions(\"MainCondition\")"}',
+			'{"ecosystem": "false", "contract_id": "false", "contract": "false", "key_id": "false",
+				"old_version": "false", "new_version": "false", "block_id": "false",
+				"executed_block": "false", "result": "false", "error": "false"}',
+			'ContractConditions(\"MainCondition\")'
 		);
 `
@@ -1,6 +1,9 @@
 package migration
 
 import (
+	"regexp"
+	"sort"
+
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 
 	version "github.com/hashicorp/go-version"
@@ -13,6 +16,35 @@ var migrations = []*migration{
 
 	// Initial schema
 	&migration{"0.1.6b9", migrationInitialSchema},
+
+	// Statistics of the API calls
+	&migration{"0.9.6", migrationAPIUsage},
+
+	// The text columns of the ecosystem tables are NOT NULL
+	&migration{"0.9.7", migrationNotNullText},
+
+	// The ledger of the transfers of the tokens
+	&migration{"0.9.8", migrationHistoryLedger},
+
+	// The idempotency keys of the sent transactions
+	&migration{"0.9.9", migrationIdempotencyKeys},
+	// The versions of the contracts and their upgrades
+	&migration{"0.9.10", migrationContractUpgrades},
+
+	// The answers of the remote nodes in the checks of the blocks
+	&migration{"0.9.11", migrationConfirmationPeers},
+
+	// The states of the rows after the blocks of the archive node
+	&migration{"0.9.12", migrationRowHistory},
+
+	// The structured errors of the compilation are longer than 255 characters
+	&migration{"0.9.13", migrationTxStatusError},
+
+	// The growth of the metrics in the time buckets
+	&migration{"0.9.14", migrationMetricDelta},
+
+	// The unused binaries which are found by the node
+	&migration{"0.9.15", migrationOrphanedBinaries},
 }
 
 type migration struct {
@@ -75,3 +107,37 @@ func Migrate(db database) error {
 
 	return migrate(db, appVer, migrations)
 }
+
+var createTableRegexp = regexp.MustCompile(`CREATE TABLE "([^"]+)"`)
+
+// Tables returns the sorted names of the tables which are created by the migrations
+func Tables() []string {
+	names := make([]string, 0)
+	for _, m := range migrations {
+		for _, match := range createTableRegexp.FindAllStringSubmatch(m.data, -1) {
+			names = append(names, match[1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsActual returns true if all migrations are applied to the database of the version dbVer
+func IsActual(dbVer string) (bool, error) {
+	ver, err := version.NewVersion(dbVer)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.MigrationError, "err": err}).Errorf("parse version")
+		return false, err
+	}
+	for _, m := range migrations {
+		mgrVer, err := version.NewVersion(m.version)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.MigrationError, "err": err}).Errorf("parse version")
+			return false, err
+		}
+		if ver.LessThan(mgrVer) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
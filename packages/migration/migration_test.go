@@ -62,3 +62,32 @@ func TestMockMigration(t *testing.T) {
 		t.Errorf("current version expected 0.0.2 get %s", v)
 	}
 }
+
+func TestTables(t *testing.T) {
+	tables := Tables()
+	for _, name := range []string{"migration_history", "block_chain", "info_block", "install"} {
+		found := false
+		for _, table := range tables {
+			found = found || table == name
+		}
+		if !found {
+			t.Errorf("table %s isn't found in %v", name, tables)
+		}
+	}
+}
+
+func TestIsActual(t *testing.T) {
+	last := migrations[len(migrations)-1].version
+	for ver, actual := range map[string]bool{"0.0.0": false, "0.0.1": false, last: true, "99.0.0": true} {
+		ok, err := IsActual(ver)
+		if err != nil {
+			t.Error(err)
+		}
+		if ok != actual {
+			t.Errorf("version %s expected actual %v get %v", ver, actual, ok)
+		}
+	}
+	if _, err := IsActual("error version"); err == nil {
+		t.Error("error is expected")
+	}
+}
@@ -18,5 +18,7 @@ INSERT INTO "%[1]d_parameters" ("id","name", "value", "conditions") VALUES
 		('12','max_block_user_tx', '100', 'ContractConditions("MainCondition")'),
 		('13','min_page_validate_count', '1', 'ContractConditions("MainCondition")'),
 		('14','max_page_validate_count', '6', 'ContractConditions("MainCondition")'),
-		('15','changing_blocks', 'ContractConditions("MainCondition")', 'ContractConditions("MainCondition")');
+		('15','changing_blocks', 'ContractConditions("MainCondition")', 'ContractConditions("MainCondition")'),
+		('16','emission_contract', '', 'ContractConditions("MainCondition")'),
+		('17','changing_founder', 'ContractConditions("MainCondition")', 'ContractConditions("MainCondition")');
 `
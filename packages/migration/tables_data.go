@@ -7,7 +7,9 @@ var tablesDataSQL = `INSERT INTO "%[1]d_tables" ("id", "name", "permissions","co
 	  "wallet_id": "ContractConditions(\"MainCondition\")",
 	  "token_id": "ContractConditions(\"MainCondition\")",
 	  "active": "ContractConditions(\"MainCondition\")",
-	  "conditions": "ContractConditions(\"MainCondition\")"}', 'ContractAccess("@1EditTable")'),
+	  "conditions": "ContractConditions(\"MainCondition\")",
+	  "version": "false",
+	  "upgrade_error": "false"}', 'ContractAccess("@1EditTable")'),
 	('2', 'keys', 
 	'{"insert": "true", "update": "true", 
 	  "new_column": "ContractConditions(\"MainCondition\")"}',
@@ -24,6 +26,8 @@ var tablesDataSQL = `INSERT INTO "%[1]d_tables" ("id", "name", "permissions","co
 	'{"sender_id": "ContractConditions(\"MainCondition\")",
 	  "recipient_id": "ContractConditions(\"MainCondition\")",
 	  "amount":  "ContractConditions(\"MainCondition\")",
+	  "commission":  "ContractConditions(\"MainCondition\")",
+	  "type": "ContractConditions(\"MainCondition\")",
 	  "comment": "ContractConditions(\"MainCondition\")",
 	  "block_id":  "ContractConditions(\"MainCondition\")",
 	  "txhash": "ContractConditions(\"MainCondition\")"}', 'ContractAccess("@1EditTable")'),        
@@ -161,5 +165,44 @@ var tablesDataSQL = `INSERT INTO "%[1]d_tables" ("id", "name", "permissions","co
 		'{"key": "false",
 			"value": "true",
 			"member_id": "false"}',
+		'ContractConditions("MainCondition")'),
+	('18', 'keys_history',
+		'{"insert": "false", "update": "false",
+			"new_column": "ContractConditions(\"MainCondition\")"}',
+		'{"key_id": "false",
+			"pub": "false",
+			"block_id": "false",
+			"txhash": "false"}',
+		'ContractAccess("@1EditTable")'),
+	('27', 'votings',
+		'{"insert": "false", "update": "false",
+			"new_column": "ContractConditions(\"MainCondition\")"}',
+		'{"subject": "false",
+			"voters": "false",
+			"votes": "false",
+			"threshold": "false",
+			"deadline": "false",
+			"creator": "false",
+			"status": "false",
+			"result": "false",
+			"date_created": "false"}',
+		'ContractConditions("MainCondition")'),
+	('28', 'events',
+		'{"insert": "false", "update": "false",
+			"new_column": "ContractConditions(\"MainCondition\")"}',
+		'{"block_id": "false",
+			"tx_hash": "false",
+			"contract": "false",
+			"name": "false",
+			"data": "false"}',
+		'ContractConditions("MainCondition")'),
+	('29', 'founder_history',
+		'{"insert": "false", "update": "false",
+			"new_column": "ContractConditions(\"MainCondition\")"}',
+		'{"old_founder": "false",
+			"new_founder": "false",
+			"block_id": "false",
+			"tx_hash": "false",
+			"date_created": "false"}',
 		'ContractConditions("MainCondition")');
 `
@@ -45,7 +45,7 @@ var contractsDataSQL = `INSERT INTO "%[1]d_contracts" ("id", "name", "value", "c
 			if !$TokenEcosystem {
 				$TokenEcosystem = 1
 			} else {
-				if !SysFuel($TokenEcosystem) {
+				if !HasFuelRate($TokenEcosystem) {
 					warning Sprintf("Ecosystem %%d is not system", $TokenEcosystem )
 				}
 			}
@@ -861,4 +861,41 @@ var contractsDataSQL = `INSERT INTO "%[1]d_contracts" ("id", "name", "value", "c
 		action{
 			DeleteVDE($VDEName)
 		}
+	}', 'ContractConditions("MainCondition")'),
+	('30', 'NewSyncSource', 'contract NewSyncSource {
+		data {
+			Name        string
+			Type        string
+			Url         string
+			Ecosystem   int "optional"
+			SourceTable string
+			TargetTable string
+			Columns     string
+			Interval    int "optional"
+			Conflict    string "optional"
+		}
+		conditions {
+			if $Ecosystem == 0 {
+				$Ecosystem = 1
+			}
+			if $Interval == 0 {
+				$Interval = 60
+			}
+			if Size($Conflict) == 0 {
+				$Conflict = "source"
+			}
+		}
+		action {
+			$result = CreateSyncSource($Name, $Type, $Url, $Ecosystem, $SourceTable, $TargetTable,
+				$Columns, $Interval, $Conflict)
+		}
+	}', 'ContractConditions("MainCondition")'),
+	('31', 'PauseSyncSource', 'contract PauseSyncSource {
+		data {
+			Id     int
+			Resume bool "optional"
+		}
+		action {
+			PauseSyncSource($Id, !$Resume)
+		}
 	}', 'ContractConditions("MainCondition")');`
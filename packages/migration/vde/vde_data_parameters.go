@@ -14,5 +14,6 @@ INSERT INTO "%[1]d_parameters" ("id","name", "value", "conditions") VALUES
 	  ('10','stylesheet', 'body { 
 		/* You can define your custom styles here or create custom CSS rules */
 	  }', 'ContractConditions("MainCondition")'),
-	  ('11','changing_blocks', 'ContractConditions("MainCondition")', 'ContractConditions("MainCondition")');
+	  ('11','changing_blocks', 'ContractConditions("MainCondition")', 'ContractConditions("MainCondition")'),
+	  ('12','changing_sync_sources', 'ContractConditions("MainCondition")', 'ContractConditions("MainCondition")');
 `
@@ -72,5 +72,30 @@ INSERT INTO "%[1]d_tables" ("id", "name", "permissions","columns", "conditions")
 			"multi": "ContractConditions(\"MainCondition\")",
 			"deleted": "ContractConditions(\"MainCondition\")",
 			"blocked": "ContractConditions(\"MainCondition\")"}',
+		'ContractConditions("MainCondition")'),
+	('10', 'vde_sync_sources',
+	  '{"insert": "false", "update": "false",
+			"new_column": "ContractConditions(\"MainCondition\")"}',
+		'{"name": "false",
+			"source_type": "false",
+			"url": "false",
+			"source_ecosystem": "false",
+			"source_table": "false",
+			"target_table": "false",
+			"columns": "false",
+			"period": "false",
+			"conflict": "false",
+			"cursor": "false",
+			"status": "false",
+			"last_sync": "false",
+			"last_error": "false"}',
+		'ContractConditions("MainCondition")'),
+	('11', 'vde_sync_rows',
+	  '{"insert": "false", "update": "false",
+			"new_column": "ContractConditions(\"MainCondition\")"}',
+		'{"source_id": "false",
+			"row_id": "false",
+			"hash": "false",
+			"flagged": "false"}',
 		'ContractConditions("MainCondition")');	 
 `
@@ -91,7 +91,8 @@ var schemaVDE = `
 	  "id" bigint NOT NULL  DEFAULT '0',
 	  "name" text NOT NULL DEFAULT '',
 	  "value" text  NOT NULL DEFAULT '',
-	  "conditions" text  NOT NULL DEFAULT ''
+	  "conditions" text  NOT NULL DEFAULT '',
+	  "app_id" bigint NOT NULL DEFAULT '1'
 	  );
 	  ALTER TABLE ONLY "%[1]d_contracts" ADD CONSTRAINT "%[1]d_contracts_pkey" PRIMARY KEY (id);
 	  
@@ -169,4 +170,35 @@ var schemaVDE = `
 		);
 		ALTER TABLE ONLY "%[1]d_roles_participants" ADD CONSTRAINT "%[1]d_roles_participants_pkey" PRIMARY KEY ("id");
 
+		DROP TABLE IF EXISTS "%[1]d_vde_sync_sources";
+		CREATE TABLE "%[1]d_vde_sync_sources" (
+			"id" bigint NOT NULL DEFAULT '0',
+			"name" varchar(255) NOT NULL DEFAULT '',
+			"source_type" varchar(32) NOT NULL DEFAULT '',
+			"url" varchar(1024) NOT NULL DEFAULT '',
+			"source_ecosystem" bigint NOT NULL DEFAULT '1',
+			"source_table" varchar(255) NOT NULL DEFAULT '',
+			"target_table" varchar(255) NOT NULL DEFAULT '',
+			"columns" jsonb,
+			"period" bigint NOT NULL DEFAULT '0',
+			"conflict" varchar(32) NOT NULL DEFAULT '',
+			"cursor" bigint NOT NULL DEFAULT '0',
+			"status" varchar(32) NOT NULL DEFAULT '',
+			"last_sync" timestamp,
+			"last_error" text NOT NULL DEFAULT ''
+		);
+		ALTER TABLE ONLY "%[1]d_vde_sync_sources" ADD CONSTRAINT "%[1]d_vde_sync_sources_pkey" PRIMARY KEY ("id");
+		CREATE UNIQUE INDEX "%[1]d_vde_sync_sources_index_name" ON "%[1]d_vde_sync_sources" (name);
+
+		DROP TABLE IF EXISTS "%[1]d_vde_sync_rows";
+		CREATE TABLE "%[1]d_vde_sync_rows" (
+			"id" bigint NOT NULL DEFAULT '0',
+			"source_id" bigint NOT NULL DEFAULT '0',
+			"row_id" bigint NOT NULL DEFAULT '0',
+			"hash" varchar(64) NOT NULL DEFAULT '',
+			"flagged" bigint NOT NULL DEFAULT '0'
+		);
+		ALTER TABLE ONLY "%[1]d_vde_sync_rows" ADD CONSTRAINT "%[1]d_vde_sync_rows_pkey" PRIMARY KEY ("id");
+		CREATE UNIQUE INDEX "%[1]d_vde_sync_rows_index_row" ON "%[1]d_vde_sync_rows" (source_id, row_id);
+
 	`
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// APIUsage represents record of api_usage table, it's the number of the API calls of the route
+// by the ecosystem in the day
+type APIUsage struct {
+	Day       int64  `gorm:"primary_key;not null" json:"day"`
+	Ecosystem int64  `gorm:"primary_key;not null" json:"ecosystem"`
+	Route     string `gorm:"primary_key;not null" json:"route"`
+	Calls     int64  `gorm:"not null" json:"calls"`
+}
+
+// TableName returns name of table
+func (APIUsage) TableName() string {
+	return "api_usage"
+}
+
+// AddAPIUsage adds the calls to the counters of the table by one query
+func AddAPIUsage(items []APIUsage) error {
+	if len(items) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(items))
+	args := make([]interface{}, 0, len(items)*4)
+	for _, item := range items {
+		values = append(values, `(?,?,?,?)`)
+		args = append(args, item.Day, item.Ecosystem, item.Route, item.Calls)
+	}
+	return DBConn.Exec(fmt.Sprintf(`INSERT INTO "api_usage" (day, ecosystem, route, calls) VALUES %s
+		ON CONFLICT (day, ecosystem, route) DO UPDATE SET calls = api_usage.calls + excluded.calls`,
+		strings.Join(values, `,`)), args...).Error
+}
+
+// GetAPIUsage returns the counters of the ecosystem from the day to the day inclusive
+func GetAPIUsage(ecosystem, from, to int64) ([]APIUsage, error) {
+	var items []APIUsage
+	err := DBConn.Where(`ecosystem = ? AND day >= ? AND day <= ?`, ecosystem, from, to).
+		Order(`day, route`).Find(&items).Error
+	return items, err
+}
+
+// GetAPIUsageCalls returns the number of the calls of the ecosystem in the day
+func GetAPIUsageCalls(ecosystem, day int64) (int64, error) {
+	return Single(`SELECT COALESCE(SUM(calls), 0) FROM "api_usage" WHERE ecosystem = ? AND day = ?`,
+		ecosystem, day).Int64()
+}
@@ -1,7 +1,10 @@
 package model
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 )
 
 const BinaryTableSuffix = "_binaries"
@@ -45,3 +48,99 @@ func (b *Binary) Link() string {
 func (b *Binary) GetByID(id int64) (bool, error) {
 	return isFound(DBConn.Where("id=?", id).First(b))
 }
+
+// BinaryRefTable is the name of the table which ids are referenced by the columns with "ref" in
+// the conditions, e.g. {"update": "true", "ref": "binaries"}
+const BinaryRefTable = "binaries"
+
+// BinaryRef is the column which has the ids of the binaries
+type BinaryRef struct {
+	Table  string
+	Column string
+}
+
+// UnusedBinary is the binary which isn't referenced by the members, the pages and the reference columns
+type UnusedBinary struct {
+	ID       int64
+	AppID    int64
+	MemberID int64
+	Name     string
+	Hash     string
+	Size     int64
+}
+
+// GetBinaryEcosystems returns the ecosystems which have the table of the binaries
+func GetBinaryEcosystems() ([]int64, error) {
+	rows, err := DBConn.Raw(`SELECT substring(table_name from '^[0-9]+')::bigint FROM information_schema.tables
+		WHERE table_schema = 'public' AND table_name ~ '^[0-9]+` + BinaryTableSuffix + `$' ORDER BY 1`).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetBinaryRefs returns the avatars of the members and the columns of the ecosystem tables which are
+// declared as the references to the binaries
+func GetBinaryRefs(ecosystem int64) ([]BinaryRef, error) {
+	refs := []BinaryRef{{Table: fmt.Sprintf(`%d_members`, ecosystem), Column: `image_id`}}
+	tables, err := GetAllTransaction(nil, fmt.Sprintf(`SELECT name, columns FROM "%d_tables" ORDER BY name`, ecosystem), -1)
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range tables {
+		var columns map[string]string
+		if err = json.Unmarshal([]byte(table[`columns`]), &columns); err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(columns))
+		for name, cond := range columns {
+			var perm struct {
+				Ref string `json:"ref"`
+			}
+			if strings.HasPrefix(cond, `{`) && json.Unmarshal([]byte(cond), &perm) == nil && perm.Ref == BinaryRefTable {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			refs = append(refs, BinaryRef{Table: fmt.Sprintf(`%d_%s`, ecosystem, table[`name`]), Column: name})
+		}
+	}
+	return refs, nil
+}
+
+// GetUnusedBinaries returns the binaries of the ecosystem which aren't referenced by refs and aren't
+// mentioned in the pages by the name or by the link
+func GetUnusedBinaries(ecosystem int64, refs []BinaryRef) ([]UnusedBinary, error) {
+	table := fmt.Sprintf(`%d%s`, ecosystem, BinaryTableSuffix)
+	query := fmt.Sprintf(`SELECT b.id, b.app_id, b.member_id, b.name, b.hash, length(b.data) FROM "%[1]s" b
+		WHERE NOT EXISTS (SELECT 1 FROM "%[2]d_pages" p WHERE strpos(p.value, b.name) > 0 OR
+			strpos(p.value, '%[1]s/' || b.id || '/') > 0)`, table, ecosystem)
+	for _, ref := range refs {
+		query += fmt.Sprintf(` AND NOT EXISTS (SELECT 1 FROM "%s" r WHERE r."%s"::text = b.id::text)`,
+			ref.Table, ref.Column)
+	}
+	rows, err := DBConn.Raw(query + ` ORDER BY b.id`).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := make([]UnusedBinary, 0)
+	for rows.Next() {
+		var item UnusedBinary
+		if err = rows.Scan(&item.ID, &item.AppID, &item.MemberID, &item.Name, &item.Hash, &item.Size); err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+	}
+	return list, rows.Err()
+}
@@ -1,11 +1,32 @@
 package model
 
+// States of the block on the remote node
+const (
+	ConfirmationHave    = "have"
+	ConfirmationMissing = "missing"
+	ConfirmationTimeout = "timeout"
+)
+
 // Confirmation is model
 type Confirmation struct {
 	BlockID int64 `gorm:"primary_key"`
 	Good    int32 `gorm:"not null"`
 	Bad     int32 `gorm:"not null"`
 	Time    int32 `gorm:"not null"`
+	Quorum  int32 `gorm:"not null"`
+}
+
+// ConfirmationPeer is the answer of the remote node in the check of the block
+type ConfirmationPeer struct {
+	BlockID int64  `gorm:"primary_key;not null"`
+	Host    string `gorm:"primary_key;not null"`
+	State   string `gorm:"not null"`
+	Hash    []byte `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (ConfirmationPeer) TableName() string {
+	return "confirmation_peers"
 }
 
 // GetGoodBlock returns last good block
@@ -18,7 +39,49 @@ func (c *Confirmation) GetConfirmation(blockID int64) (bool, error) {
 	return isFound(DBConn.Where("block_id= ?", blockID).First(&c))
 }
 
+// GetLast returns the latest check of the blocks
+func (c *Confirmation) GetLast() (bool, error) {
+	return isFound(DBConn.Order("time desc, block_id desc").First(c))
+}
+
 // Save is saving model
 func (c *Confirmation) Save() error {
 	return DBConn.Save(c).Error
 }
+
+// SaveWithPeers saves the check of the block with the answers of the remote nodes,
+// the answers of the previous check of the block are replaced
+func (c *Confirmation) SaveWithPeers(peers []ConfirmationPeer) error {
+	tx, err := StartTransaction()
+	if err != nil {
+		return err
+	}
+	conn := tx.Connection()
+	if err = conn.Save(c).Error; err == nil {
+		if err = conn.Where("block_id = ?", c.BlockID).Delete(&ConfirmationPeer{}).Error; err == nil {
+			for i := range peers {
+				peers[i].BlockID = c.BlockID
+				if err = conn.Create(&peers[i]).Error; err != nil {
+					break
+				}
+			}
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetConfirmationPeers returns the answers of the remote nodes about the block
+func GetConfirmationPeers(blockID int64) ([]ConfirmationPeer, error) {
+	var peers []ConfirmationPeer
+	err := DBConn.Where("block_id = ?", blockID).Order("host").Find(&peers).Error
+	return peers, err
+}
+
+// DeleteConfirmationPeers deletes the answers about the blocks which are lower than blockID
+func DeleteConfirmationPeers(blockID int64) error {
+	return DBConn.Where("block_id < ?", blockID).Delete(&ConfirmationPeer{}).Error
+}
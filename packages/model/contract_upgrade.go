@@ -0,0 +1,44 @@
+package model
+
+const tableContractUpgrades = "1_contract_upgrades"
+
+// ContractUpgrade is the single execution of the upgrade function of the replaced contract.
+// The upgrade is pending while ExecutedBlock is zero
+type ContractUpgrade struct {
+	ID            int64  `gorm:"primary_key;not null"`
+	Ecosystem     int64  `gorm:"not null"`
+	ContractID    int64  `gorm:"not null"`
+	Contract      string `gorm:"not null"`
+	KeyID         int64  `gorm:"not null"`
+	OldVersion    int64  `gorm:"not null"`
+	NewVersion    int64  `gorm:"not null"`
+	BlockID       int64  `gorm:"not null"`
+	ExecutedBlock int64  `gorm:"not null"`
+	Result        string `gorm:"not null"`
+	Error         string `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (ContractUpgrade) TableName() string {
+	return tableContractUpgrades
+}
+
+// GetTransaction returns the upgrade by id
+func (u *ContractUpgrade) GetTransaction(transaction *DbTransaction, id int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("id = ?", id).First(u))
+}
+
+// GetPending returns the pending upgrade of the contract of the ecosystem
+func (u *ContractUpgrade) GetPending(transaction *DbTransaction, ecosystem, contractID int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("ecosystem = ? AND contract_id = ? AND executed_block = 0",
+		ecosystem, contractID).First(u))
+}
+
+// GetContractUpgradesToRun returns the pending upgrades which have to be executed in the block with blockID
+func GetContractUpgradesToRun(blockID int64) ([]*ContractUpgrade, error) {
+	var upgrades []*ContractUpgrade
+	if err := DBConn.Where("executed_block = 0 AND block_id <= ?", blockID).Order("id").Find(&upgrades).Error; err != nil {
+		return nil, err
+	}
+	return upgrades, nil
+}
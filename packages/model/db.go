@@ -28,6 +28,9 @@ var (
 
 	// ErrDBConn database connection error
 	ErrDBConn = errors.New("Database connection error")
+
+	// ErrTxExists is returned by SendTx with the hash of the transaction which has been already sent
+	ErrTxExists = errors.New("Transaction already exists")
 )
 
 func isFound(db *gorm.DB) (bool, error) {
@@ -138,6 +141,13 @@ func GetRecordsCountTx(db *DbTransaction, tableName string) (int64, error) {
 	return count, err
 }
 
+// GetRecordsCountWhere returns the count of the rows of the table which match the condition
+func GetRecordsCountWhere(db *DbTransaction, tableName, where string) (int64, error) {
+	var count int64
+	err := GetDB(db).Table(tableName).Where(where).Count(&count).Error
+	return count, err
+}
+
 // ExecSchemaEcosystem is executing ecosystem schema
 func ExecSchemaEcosystem(db *DbTransaction, id int, wallet int64, name string, founder int64) error {
 	q := fmt.Sprintf(migration.GetEcosystemScript(), id, wallet, name, founder)
@@ -188,13 +198,20 @@ func GetColumnCount(tableName string) (int64, error) {
 	return count, nil
 }
 
-// SendTx is creates transaction
+// SendTx is creates transaction. The resubmitted transaction isn't added again,
+// its hash is returned with ErrTxExists
 func SendTx(txType int64, adminWallet int64, data []byte) ([]byte, error) {
 	hash, err := crypto.Hash(data)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("hashing data")
 		return nil, err
 	}
+	if exists, err := TxExists(hash); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("checking sent transaction")
+		return nil, err
+	} else if exists {
+		return hash, ErrTxExists
+	}
 	ts := &TransactionStatus{
 		Hash:     hash,
 		Time:     time.Now().Unix(),
@@ -203,6 +220,10 @@ func SendTx(txType int64, adminWallet int64, data []byte) ([]byte, error) {
 	}
 	err = ts.Create()
 	if err != nil {
+		// the same transaction can be sent concurrently
+		if exists, _ := TxExists(hash); exists {
+			return hash, ErrTxExists
+		}
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("transaction status create")
 		return nil, err
 	}
@@ -214,6 +235,15 @@ func SendTx(txType int64, adminWallet int64, data []byte) ([]byte, error) {
 	return hash, err
 }
 
+// TxExists returns true if the transaction is in queue_tx or transactions_status
+func TxExists(hash []byte) (bool, error) {
+	count, err := GetQueuedTransactionsCount(hash)
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+	return (&TransactionStatus{}).Get(hash)
+}
+
 // AlterTableAddColumn is adding column to table
 func AlterTableAddColumn(transaction *DbTransaction, tableName, columnName, columnType string) error {
 	return GetDB(transaction).Exec(`ALTER TABLE "` + tableName + `" ADD COLUMN "` + columnName + `" ` + columnType).Error
@@ -224,6 +254,36 @@ func AlterTableDropColumn(tableName, columnName string) error {
 	return DBConn.Exec(`ALTER TABLE "` + tableName + `" DROP COLUMN "` + columnName + `"`).Error
 }
 
+// AlterColumnType changes the type of the column, the values are converted by the using expression.
+// The columns with notNull get the default value and the NOT NULL constraint
+func AlterColumnType(transaction *DbTransaction, tableName, columnName, columnType, using string,
+	notNull bool, defValue string) error {
+	column := `ALTER COLUMN "` + columnName + `" `
+	query := `ALTER TABLE "` + tableName + `" ` + column + `DROP DEFAULT, ` + column + `DROP NOT NULL, ` +
+		column + `TYPE ` + columnType + ` USING ` + using
+	if notNull {
+		query += `, ` + column + `SET DEFAULT '` + strings.Replace(defValue, `'`, `''`, -1) + `', ` +
+			column + `SET NOT NULL`
+	}
+	return GetDB(transaction).Exec(query).Error
+}
+
+// UpdateColumnValues writes the values to the column of the rows by one query, nil is NULL
+func UpdateColumnValues(transaction *DbTransaction, tableName, columnName, columnType string,
+	ids []int64, values []*string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	rows := make([]string, len(ids))
+	args := make([]interface{}, 0, len(ids)*2)
+	for i, id := range ids {
+		rows[i] = `(?::bigint, ?::text)`
+		args = append(args, id, values[i])
+	}
+	return GetDB(transaction).Exec(`UPDATE "`+tableName+`" AS t SET "`+columnName+`" = CAST(v.value AS `+
+		columnType+`) FROM (VALUES `+strings.Join(rows, `,`)+`) AS v(id, value) WHERE t.id = v.id`, args...).Error
+}
+
 // CreateIndex is creating index on table column
 func CreateIndex(transaction *DbTransaction, indexName, tableName, onColumn string) error {
 	return GetDB(transaction).Exec(`CREATE INDEX "` + indexName + `_index" ON "` + tableName + `" (` + onColumn + `)`).Error
@@ -300,6 +360,23 @@ func IsIndex(tblname, column string) (bool, error) {
 	return len(row) > 0 && row[`column_name`] == column, err
 }
 
+// GetColumnIndexes returns the indexed columns of the table, the value is true if the index is unique
+func GetColumnIndexes(tblname string) (map[string]bool, error) {
+	rows, err := GetAllTransaction(nil, `select a.attname as column_name, ix.indisunique::int as is_unique
+	 from pg_class t, pg_class i, pg_index ix, pg_attribute a
+	 where t.oid = ix.indrelid and i.oid = ix.indexrelid and a.attrelid = t.oid and a.attnum = ANY(ix.indkey)
+		 and t.relkind = 'r' and t.relname = ?`, -1, tblname)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make(map[string]bool)
+	for _, row := range rows {
+		name := row[`column_name`]
+		indexes[name] = indexes[name] || row[`is_unique`] == `1`
+	}
+	return indexes, nil
+}
+
 // ListResult is a structure for the list result
 type ListResult struct {
 	result []string
@@ -362,14 +439,25 @@ func GetColumnByID(table, column, id string) (result string, err error) {
 	return
 }
 
-// InitDB drop all tables and exec db schema
-func InitDB(cfg conf.DBConfig) error {
+// InitDB exec db schema, the existing tables are dropped if force is true.
+// It returns ErrSchemaExists if the database has tables and force is false
+func InitDB(cfg conf.DBConfig, force bool) error {
 
 	err := GormInit(cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name)
 	if err != nil || DBConn == nil {
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("initializing DB")
 		return ErrDBConn
 	}
+	if !force {
+		tables, err := GetTableNames()
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting table names")
+			return err
+		}
+		if len(tables) > 0 {
+			return ErrSchemaExists
+		}
+	}
 	if err = DropTables(); err != nil {
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("dropping all tables")
 		return err
@@ -416,3 +504,53 @@ func DropDatabase(name string) error {
 
 	return nil
 }
+
+// CreateDatabaseFromTemplate creates the copy of the template database, the template mustn't have connections
+func CreateDatabaseFromTemplate(name, template string) error {
+	if err := DBConn.Exec(fmt.Sprintf(`CREATE DATABASE "%s" TEMPLATE "%s"`, name, template)).Error; err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "dbname": name, "template": template}).Error("on create db from template")
+		return err
+	}
+	return nil
+}
+
+// GetTableNames returns the sorted names of the tables of the current schema
+func GetTableNames() ([]string, error) {
+	return GetList(`SELECT tablename FROM pg_tables WHERE schemaname = current_schema() ORDER BY tablename`).String()
+}
+
+// GetTableHash returns the number of the rows of the table and the md5 hash of the sorted rows
+func GetTableHash(table string) (count int64, hash string, err error) {
+	err = DBConn.Raw(fmt.Sprintf(`SELECT count(*), coalesce(md5(string_agg(t::text, E'\n' ORDER BY t::text)), '')
+		FROM "%s" t`, table)).Row().Scan(&count, &hash)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table}).Error("getting hash of table")
+	}
+	return
+}
+
+// GetNamesByIDs returns the names of the rows of the table by their ids
+func GetNamesByIDs(table string, ids []int64) (map[int64]string, error) {
+	names := make(map[int64]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+	rows, err := DBConn.Raw(`SELECT id, name FROM "`+table+`" WHERE id IN (?)`, ids).Rows()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table}).Error("getting names by ids")
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id   int64
+			name string
+		)
+		if err = rows.Scan(&id, &name); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table}).Error("scanning names by ids")
+			return nil, err
+		}
+		names[id] = name
+	}
+	return names, rows.Err()
+}
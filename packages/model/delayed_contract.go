@@ -11,8 +11,13 @@ type DelayedContract struct {
 	BlockID    int64  `gorm:"not null"`
 	Counter    int64  `gorm:"not null"`
 	Limit      int64  `gorm:"not null"`
-	Delete     bool   `gorm:"not null"`
+	Deleted    bool   `gorm:"not null"`
 	Conditions string `gorm:"not null"`
+	RunTime    int64  `gorm:"not null"`
+	EveryTime  int64  `gorm:"not null"`
+	LastBlock  int64  `gorm:"not null"`
+	LastResult string `gorm:"not null"`
+	LastError  string `gorm:"not null"`
 }
 
 // TableName returns name of table
@@ -20,10 +25,12 @@ func (DelayedContract) TableName() string {
 	return tableDelayedContracts
 }
 
-// GetAllDelayedContractsForBlockID returns contracts that want to execute for blockID
-func GetAllDelayedContractsForBlockID(blockID int64) ([]*DelayedContract, error) {
+// GetDelayedContractsToRun returns the active contracts which have to be executed in the block
+// with blockID and blockTime. The contract runs on the scheduled block or time and later if it was missed
+func GetDelayedContractsToRun(blockID, blockTime int64) ([]*DelayedContract, error) {
 	var contracts []*DelayedContract
-	if err := DBConn.Where("block_id = ?", blockID).Find(&contracts).Error; err != nil {
+	if err := DBConn.Where("deleted = false AND (run_time > 0 AND run_time <= ? OR run_time = 0 AND block_id <= ?)",
+		blockTime, blockID).Order("id").Find(&contracts).Error; err != nil {
 		return nil, err
 	}
 	return contracts, nil
@@ -0,0 +1,50 @@
+package model
+
+import "fmt"
+
+// Event represents record of {prefix}_events table
+type Event struct {
+	prefix   int64
+	ID       int64  `gorm:"primary_key;not null"`
+	BlockID  int64  `gorm:"not null"`
+	TxHash   string `gorm:"not null"`
+	Contract string `gorm:"not null"`
+	Name     string `gorm:"not null"`
+	Data     string `gorm:"type:jsonb(PostgreSQL)"`
+}
+
+// SetTablePrefix is setting table prefix
+func (e *Event) SetTablePrefix(prefix int64) *Event {
+	if prefix == 0 {
+		prefix = 1
+	}
+	e.prefix = prefix
+	return e
+}
+
+// TableName returns name of table
+func (e Event) TableName() string {
+	if e.prefix == 0 {
+		e.prefix = 1
+	}
+	return fmt.Sprintf("%d_events", e.prefix)
+}
+
+// GetEvents returns the events from fromBlock in the order of emission. The events are filtered
+// by name if it isn't empty
+func (e *Event) GetEvents(fromBlock int64, name string, limit int) ([]Event, error) {
+	var events []Event
+	query := DBConn.Table(e.TableName()).Where("block_id >= ?", fromBlock)
+	if len(name) > 0 {
+		query = query.Where("name = ?", name)
+	}
+	err := query.Order("id").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// GetBlockEvents returns the events which have been emitted in the block
+func (e *Event) GetBlockEvents(blockID int64) ([]Event, error) {
+	var events []Event
+	err := DBConn.Table(e.TableName()).Where("block_id = ?", blockID).Order("id").Find(&events).Error
+	return events, err
+}
@@ -11,6 +11,13 @@ import (
 
 const historyTableSuffix = "_history"
 
+// The types of the records of the history table
+const (
+	HistoryTransfer = "transfer"
+	HistoryFee      = "fee"
+	HistoryEmission = "emission"
+)
+
 var errLowBalance = errors.New("not enough APL on the balance")
 
 // History represent record of history table
@@ -20,6 +27,8 @@ type History struct {
 	SenderID    int64
 	RecipientID int64
 	Amount      decimal.Decimal
+	Commission  decimal.Decimal
+	Type        string
 	Comment     string
 	BlockID     int64
 	TxHash      []byte `gorm:"column:txhash"`
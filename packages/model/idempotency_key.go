@@ -0,0 +1,35 @@
+package model
+
+// IdempotencyKey represents record of idempotency_keys table, it's the transaction which has been sent
+// by the key with the idempotency key of the client
+type IdempotencyKey struct {
+	KeyID       int64  `gorm:"primary_key;not null"`
+	Key         string `gorm:"column:idempotency_key;primary_key;not null"`
+	Fingerprint []byte `gorm:"not null"`
+	TxHash      []byte `gorm:"not null"`
+	CreatedAt   int64  `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+// Get is retrieving the idempotency key of the key which has been created since the time
+func (ik *IdempotencyKey) Get(keyID int64, key string, since int64) (bool, error) {
+	return isFound(DBConn.Where("key_id = ? AND idempotency_key = ? AND created_at >= ?", keyID, key, since).First(ik))
+}
+
+// Save writes the idempotency key, the expired record with the same key is replaced
+func (ik *IdempotencyKey) Save(since int64) error {
+	return DBConn.Exec(`INSERT INTO "idempotency_keys" (key_id, idempotency_key, fingerprint, tx_hash, created_at)
+		VALUES (?,?,?,?,?) ON CONFLICT (key_id, idempotency_key) DO UPDATE SET fingerprint = excluded.fingerprint,
+		tx_hash = excluded.tx_hash, created_at = excluded.created_at WHERE idempotency_keys.created_at < ?`,
+		ik.KeyID, ik.Key, ik.Fingerprint, ik.TxHash, ik.CreatedAt, since).Error
+}
+
+// DeleteExpiredIdempotencyKeys deletes the idempotency keys which have been created before the time
+func DeleteExpiredIdempotencyKeys(before int64) (int64, error) {
+	query := DBConn.Where("created_at < ?", before).Delete(&IdempotencyKey{})
+	return query.RowsAffected, query.Error
+}
@@ -31,6 +31,16 @@ func (m *Key) Get(wallet int64) (bool, error) {
 	return isFound(DBConn.Where("id = ?", wallet).First(m))
 }
 
+// GetTransaction is retrieving model from database using transaction
+func (m *Key) GetTransaction(transaction *DbTransaction, wallet int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("id = ?", wallet).First(m))
+}
+
+// GetByPublicKey is retrieving the key with the specified public key
+func (m *Key) GetByPublicKey(transaction *DbTransaction, pub []byte) (bool, error) {
+	return isFound(GetDB(transaction).Where("pub = ?", pub).First(m))
+}
+
 // KeyTableName returns name of keys table
 func KeyTableName(prefix int64) string {
 	return fmt.Sprintf("%d%s", prefix, keyTableSuffix)
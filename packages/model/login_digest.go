@@ -0,0 +1,43 @@
+package model
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// LoginDigest is the summary of the ecosystem and the key which is returned by login
+type LoginDigest struct {
+	EcosystemName string
+	KeyExists     bool
+	Amount        string
+	MemberExists  bool
+	MemberName    string
+	ImageID       int64
+	Notifications int64
+}
+
+// GetLoginDigest returns the digest of the ecosystem for the key in one query
+func GetLoginDigest(transaction *DbTransaction, ecosystemID, keyID int64) (*LoginDigest, error) {
+	prefix := fmt.Sprintf(`"%d`, ecosystemID)
+	query := `SELECT
+		(SELECT name FROM "` + ecosysTable + `" WHERE id = ?),
+		(SELECT amount FROM ` + prefix + keyTableSuffix + `" WHERE id = ? AND deleted = 0),
+		(SELECT member_name FROM ` + prefix + `_members" WHERE id = ?),
+		(SELECT image_id FROM ` + prefix + `_members" WHERE id = ?),
+		(SELECT count(*) FROM ` + prefix + notificationTableSuffix + `"
+			WHERE closed = 0 AND recipient->>'member_id' = ?)`
+	var (
+		name, amount, member sql.NullString
+		image                sql.NullInt64
+		digest               LoginDigest
+	)
+	err := GetDB(transaction).Raw(query, ecosystemID, keyID, keyID, keyID, fmt.Sprint(keyID)).Row().
+		Scan(&name, &amount, &member, &image, &digest.Notifications)
+	if err != nil {
+		return nil, err
+	}
+	digest.EcosystemName = name.String
+	digest.KeyExists, digest.Amount = amount.Valid, amount.String
+	digest.MemberExists, digest.MemberName, digest.ImageID = member.Valid, member.String, image.Int64
+	return &digest, nil
+}
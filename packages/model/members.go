@@ -5,7 +5,7 @@ type Member struct {
 	tableName  string
 	ID         int64  `gorm:"primary_key;not null"`
 	MemberName string `gorm:"not null"`
-	ImageID    *int64
+	ImageID    int64  `gorm:"not null"`
 	MemberInfo string `gorm:"type:jsonb(PostgreSQL)"`
 }
 
@@ -1,7 +1,12 @@
 package model
 
+import "github.com/GenesisKernel/go-genesis/packages/converter"
+
 const tableNameMetrics = "1_metrics"
 
+// MetricRate is the aggregate function which returns the growth of the metric in each time bucket
+const MetricRate = "rate"
+
 // Metric represents record of system_metrics table
 type Metric struct {
 	ID     int64  `gorm:"primary_key;not null"`
@@ -9,6 +14,7 @@ type Metric struct {
 	Metric string `gorm:"not null"`
 	Key    string `gorm:"not null"`
 	Value  int64  `gorm:"not null"`
+	Delta  int64  `gorm:"not null"`
 }
 
 // TableName returns name of table
@@ -16,6 +22,11 @@ func (Metric) TableName() string {
 	return tableNameMetrics
 }
 
+// Get is retrieving the value of the metric in the time bucket
+func (m *Metric) Get(time int64, metric, key string) (bool, error) {
+	return isFound(DBConn.Where("time = ? AND metric = ? AND key = ?", time, metric, key).First(m))
+}
+
 // EcosystemTx represents value of metric
 type EcosystemTx struct {
 	UnixTime  int64
@@ -46,6 +57,9 @@ func GetEcosystemTxPerDay() ([]*EcosystemTx, error) {
 
 // GetMetricValues returns aggregated metric values in the time interval
 func GetMetricValues(metric, timeInterval, aggregateFunc string) ([]interface{}, error) {
+	if aggregateFunc == MetricRate {
+		return getMetricRates(metric, timeInterval)
+	}
 	rows, err := DBConn.Table(tableNameMetrics).Select("key,"+aggregateFunc+"(value)").
 		Where("metric = ? AND time >= EXTRACT(EPOCH FROM NOW() - CAST(? AS INTERVAL))", metric, timeInterval).
 		Group("key").Rows()
@@ -71,3 +85,23 @@ func GetMetricValues(metric, timeInterval, aggregateFunc string) ([]interface{},
 
 	return result, nil
 }
+
+// getMetricRates returns the growth of the metric in the time buckets of the interval
+func getMetricRates(metric, timeInterval string) ([]interface{}, error) {
+	var metrics []Metric
+	err := DBConn.Where("metric = ? AND time >= EXTRACT(EPOCH FROM NOW() - CAST(? AS INTERVAL))", metric, timeInterval).
+		Order("key, time").Find(&metrics).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]interface{}, 0, len(metrics))
+	for _, m := range metrics {
+		result = append(result, map[string]string{
+			"key":   m.Key,
+			"time":  converter.Int64ToStr(m.Time),
+			"value": converter.Int64ToStr(m.Delta),
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"fmt"
+)
+
+// OrphanedBinary is the unused binary which has been found by the node. The binaries are tracked
+// by the node locally, so they are deleted after the grace period since they have become unused
+type OrphanedBinary struct {
+	Ecosystem int64  `gorm:"primary_key;not null"`
+	BinaryID  int64  `gorm:"primary_key;not null"`
+	Hash      string `gorm:"primary_key;not null"`
+	FoundAt   int64  `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (ob *OrphanedBinary) TableName() string {
+	return "orphaned_binaries"
+}
+
+// TrackOrphanedBinaries saves the unused binaries of the ecosystem and forgets the binaries which
+// are used or changed since the previous scan. It returns the times when the binaries were found
+func TrackOrphanedBinaries(ecosystem int64, list []UnusedBinary, now int64) (map[int64]int64, error) {
+	transaction, err := StartTransaction()
+	if err != nil {
+		return nil, err
+	}
+	defer transaction.Rollback()
+
+	var tracked []OrphanedBinary
+	if err = GetDB(transaction).Where("ecosystem = ?", ecosystem).Find(&tracked).Error; err != nil {
+		return nil, err
+	}
+	found := make(map[int64]int64, len(list))
+	for _, item := range tracked {
+		found[item.BinaryID] = item.FoundAt
+	}
+	unused := make(map[OrphanedBinary]bool, len(list))
+	for _, item := range list {
+		unused[OrphanedBinary{Ecosystem: ecosystem, BinaryID: item.ID, Hash: item.Hash}] = true
+	}
+	for _, item := range tracked {
+		if unused[OrphanedBinary{Ecosystem: ecosystem, BinaryID: item.BinaryID, Hash: item.Hash}] {
+			continue
+		}
+		delete(found, item.BinaryID)
+		if err = GetDB(transaction).Delete(&item).Error; err != nil {
+			return nil, err
+		}
+	}
+	for _, item := range list {
+		if _, ok := found[item.ID]; ok {
+			continue
+		}
+		found[item.ID] = now
+		orphan := &OrphanedBinary{Ecosystem: ecosystem, BinaryID: item.ID, Hash: item.Hash, FoundAt: now}
+		if err = GetDB(transaction).Create(orphan).Error; err != nil {
+			return nil, err
+		}
+	}
+	return found, transaction.Commit()
+}
+
+// DeleteOrphanedBinary deletes the binary of the ecosystem if it hasn't been changed since it was found
+func DeleteOrphanedBinary(ecosystem, id int64, hash string) error {
+	transaction, err := StartTransaction()
+	if err != nil {
+		return err
+	}
+	defer transaction.Rollback()
+
+	if err = GetDB(transaction).Exec(fmt.Sprintf(`DELETE FROM "%d%s" WHERE id = ? AND hash = ?`, ecosystem,
+		BinaryTableSuffix), id, hash).Error; err != nil {
+		return err
+	}
+	if err = GetDB(transaction).Delete(&OrphanedBinary{Ecosystem: ecosystem, BinaryID: id, Hash: hash}).Error; err != nil {
+		return err
+	}
+	return transaction.Commit()
+}
@@ -214,3 +214,69 @@ func GetOneRowTransaction(transaction *DbTransaction, query string, args ...inte
 func GetOneRow(query string, args ...interface{}) *OneRow {
 	return GetOneRowTransaction(nil, query, args...)
 }
+
+// ZeroValue returns the value which replaces NULL of the column of the database type
+func ZeroValue(dbType string) string {
+	switch dbType {
+	case `INT2`, `INT4`, `INT8`, `NUMERIC`, `FLOAT4`, `FLOAT8`:
+		return `0`
+	case `BOOL`:
+		return `f`
+	}
+	return ``
+}
+
+// ScanRows reads the rows and replaces NULL with the zero value of the type of the column.
+// The rows of the ecosystem tables which are returned to the contracts and to the API are read by it
+func ScanRows(rows *sql.Rows, countRows int) ([]map[string]string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	zero := make([]string, len(types))
+	for i, colType := range types {
+		zero[i] = ZeroValue(colType.DatabaseTypeName())
+	}
+	values := make([][]byte, len(columns))
+	scanArgs := make([]interface{}, len(values))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+	result := make([]map[string]string, 0)
+	for rows.Next() {
+		if err = rows.Scan(scanArgs...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]string, len(columns))
+		for i, col := range values {
+			if col == nil {
+				row[columns[i]] = zero[i]
+			} else {
+				row[columns[i]] = string(col)
+			}
+		}
+		result = append(result, row)
+		if countRows != -1 && len(result) >= countRows {
+			break
+		}
+	}
+	return result, rows.Err()
+}
+
+// GetRows returns the rows of the query as ScanRows does it
+func GetRows(transaction *DbTransaction, query string, countRows int, args ...interface{}) ([]map[string]string, error) {
+	rows, err := GetDB(transaction).Raw(query, args...).Rows()
+	if err != nil {
+		return nil, fmt.Errorf("%s in query %s %s", err, query, args)
+	}
+	defer rows.Close()
+	result, err := ScanRows(rows, countRows)
+	if err != nil {
+		return nil, fmt.Errorf("%s in query %s %s", err, query, args)
+	}
+	return result, nil
+}
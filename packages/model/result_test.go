@@ -0,0 +1,38 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRows(t *testing.T) {
+	cfg, cleanup := testDB(t)
+	defer cleanup()
+	require.NoError(t, GormInit(cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name))
+
+	require.NoError(t, DBConn.Exec(`CREATE TABLE "1_nulls" ("id" bigint NOT NULL, "name" text, "code" varchar(100),
+		"amount" bigint, "money" decimal(30, 0), "rate" double precision, "flag" boolean, "info" jsonb,
+		"date" timestamp)`).Error)
+	require.NoError(t, DBConn.Exec(`INSERT INTO "1_nulls" (id) VALUES (1)`).Error)
+	require.NoError(t, DBConn.Exec(`INSERT INTO "1_nulls" VALUES (2, 'name', '', 5, 10, 1.5, true, '{}', NULL)`).Error)
+
+	rows, err := GetRows(nil, `SELECT * FROM "1_nulls" ORDER BY id`, -1)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]string{
+		{"id": "1", "name": "", "code": "", "amount": "0", "money": "0", "rate": "0", "flag": "f",
+			"info": "", "date": ""},
+		{"id": "2", "name": "name", "code": "", "amount": "5", "money": "10", "rate": "1.5", "flag": "t",
+			"info": "{}", "date": ""},
+	}, rows)
+
+	rows, err = GetRows(nil, `SELECT name, amount FROM "1_nulls" ORDER BY id`, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]string{{"name": "", "amount": "0"}}, rows)
+
+	// GetAll keeps NULL, the rollback records rely on it
+	all, err := GetAll(`SELECT name FROM "1_nulls" WHERE id = 1`, -1)
+	require.NoError(t, err)
+	assert.Equal(t, "NULL", all[0]["name"])
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Role represents record of {prefix}roles table
+type Role struct {
+	prefix      int64
+	ID          int64
+	DefaultPage string
+	RoleName    string
+	Deleted     int64
+	RoleType    int64
+	Creator     string `gorm:"type:jsonb(PostgreSQL)"`
+	DateCreated *time.Time
+	DateDeleted *time.Time
+	CompanyID   int64
+	RolesAccess *string `gorm:"type:jsonb(PostgreSQL)"`
+	ImageID     int64
+}
+
+// SetTablePrefix is setting table prefix
+func (r *Role) SetTablePrefix(prefix int64) *Role {
+	if prefix == 0 {
+		prefix = 1
+	}
+	r.prefix = prefix
+	return r
+}
+
+// TableName returns name of table
+func (r Role) TableName() string {
+	if r.prefix == 0 {
+		r.prefix = 1
+	}
+	return fmt.Sprintf("%d_roles", r.prefix)
+}
+
+// GetActiveRoles returns the roles which are not deleted
+func (r *Role) GetActiveRoles() ([]Role, error) {
+	var roles []Role
+	err := DBConn.Table(r.TableName()).Where("deleted = ?", 0).Order("id").Find(&roles).Error
+	return roles, err
+}
+
+// GetActive returns the role with id if it isn't deleted
+func (r *Role) GetActive(transaction *DbTransaction, id int64) (bool, error) {
+	return isFound(GetDB(transaction).Table(r.TableName()).Where("id = ? AND deleted = ?", id, 0).First(r))
+}
@@ -14,8 +14,8 @@ type RolesParticipants struct {
 	Role        string `gorm:"type":jsonb(PostgreSQL)`
 	Member      string `gorm:"type":jsonb(PostgreSQL)`
 	Appointed   string `gorm:"type":jsonb(PostgreSQL)`
-	DateCreated time.Time
-	DateDeleted time.Time
+	DateCreated *time.Time
+	DateDeleted *time.Time
 	Deleted     bool
 }
 
@@ -43,11 +43,18 @@ func (r *RolesParticipants) GetActiveMemberRoles(memberID int64) ([]RolesPartici
 	return *roles, err
 }
 
+// GetActiveRoleParticipants returns active participants of the role
+func (r *RolesParticipants) GetActiveRoleParticipants(roleID int64) ([]RolesParticipants, error) {
+	roles := new([]RolesParticipants)
+	err := DBConn.Table(r.TableName()).Where("role->>'id' = ? AND deleted = ?", converter.Int64ToStr(roleID), 0).Order("id").Find(&roles).Error
+	return *roles, err
+}
+
 // MemberHasRole returns true if member has role
 func MemberHasRole(tx *DbTransaction, ecosys, member, role int64) (bool, error) {
 	db := GetDB(tx)
 	var count int64
-	if err := db.Table(fmt.Sprint(ecosys, "_roles_participants")).Where(`role->>'id' = ? and member->>'member_id' = ?`, converter.Int64ToStr(role), converter.Int64ToStr(member)).Count(&count).Error; err != nil {
+	if err := db.Table(fmt.Sprint(ecosys, "_roles_participants")).Where(`role->>'id' = ? and member->>'member_id' = ? and deleted = ?`, converter.Int64ToStr(role), converter.Int64ToStr(member), 0).Count(&count).Error; err != nil {
 		return false, err
 	}
 
@@ -1,5 +1,15 @@
 package model
 
+import "github.com/GenesisKernel/go-genesis/packages/converter"
+
+const (
+	// RollbackFormatKey is the key of the format version in the rollback data of the updated row
+	RollbackFormatKey = `$v`
+	// RollbackFormatDiff is the version of the rollback data which contains only the changed columns.
+	// The rollback data without the version contains all the updated columns of the row
+	RollbackFormatDiff = `2`
+)
+
 // RollbackTx is model
 type RollbackTx struct {
 	ID        int64  `gorm:"primary_key;not null" json:"-"`
@@ -56,3 +66,43 @@ func (rt *RollbackTx) Create(transaction *DbTransaction) error {
 func (rt *RollbackTx) Get(dbTransaction *DbTransaction, transactionHash []byte, tableName string) (bool, error) {
 	return isFound(GetDB(dbTransaction).Where("tx_hash = ? AND table_name = ?", transactionHash, tableName).First(rt))
 }
+
+// MarshalRollbackDiff returns the rollback data of the updated row with the previous values of the changed columns
+func MarshalRollbackDiff(values map[string]string) (string, error) {
+	data := make(map[string]string, len(values)+1)
+	for k, v := range values {
+		data[k] = v
+	}
+	data[RollbackFormatKey] = RollbackFormatDiff
+	out, err := converter.CanonicalJSON(data)
+	if err != nil {
+		return ``, err
+	}
+	return string(out), nil
+}
+
+// UnmarshalRollbackData returns the previous values of the columns of the updated row.
+// The values of the columns which are missing have not been changed
+func UnmarshalRollbackData(data string) (map[string]string, error) {
+	values, err := converter.DecodeJSONMap([]byte(data))
+	if err != nil {
+		return nil, err
+	}
+	delete(values, RollbackFormatKey)
+	return values, nil
+}
+
+// GetChangedIDs returns the ids of the rows of the table which are changed in the blocks after the block.
+// The changes of the blocks are returned entirely, cursor is the last returned block
+func GetChangedIDs(tableName string, blockID int64, blocks int) (ids []string, cursor int64, err error) {
+	cursor = blockID
+	err = DBConn.Raw(`SELECT COALESCE(MAX(block_id), ?) FROM (SELECT DISTINCT block_id FROM rollback_tx
+		WHERE table_name = ? AND block_id > ? ORDER BY block_id LIMIT ?) AS b`, blockID, tableName, blockID,
+		blocks).Row().Scan(&cursor)
+	if err != nil || cursor == blockID {
+		return
+	}
+	err = DBConn.Raw(`SELECT DISTINCT table_id FROM rollback_tx WHERE table_name = ? AND block_id > ? AND
+		block_id <= ?`, tableName, blockID, cursor).Pluck(`table_id`, &ids).Error
+	return
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollbackData(t *testing.T) {
+	data, err := MarshalRollbackDiff(map[string]string{"value": "old"})
+	require.NoError(t, err)
+	assert.Equal(t, `{"$v":"2","value":"old"}`, data)
+
+	values, err := UnmarshalRollbackData(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"value": "old"}, values)
+
+	// the update which hasn't changed anything
+	data, err = MarshalRollbackDiff(map[string]string{})
+	require.NoError(t, err)
+	values, err = UnmarshalRollbackData(data)
+	require.NoError(t, err)
+	assert.Empty(t, values)
+
+	// the full row of the previous format
+	values, err = UnmarshalRollbackData(`{"value":"old","conditions":"true"}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"value": "old", "conditions": "true"}, values)
+}
@@ -0,0 +1,229 @@
+package model
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+)
+
+const rowHistoryTable = "row_history"
+
+// RowHistoryStatus is the range of the blocks which are indexed by the archive node
+type RowHistoryStatus struct {
+	ID   int64 `gorm:"primary_key;not null"`
+	Low  int64 `gorm:"not null"`
+	High int64 `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (RowHistoryStatus) TableName() string {
+	return "row_history_status"
+}
+
+// Get is retrieving model from database
+func (s *RowHistoryStatus) Get(transaction *DbTransaction) (bool, error) {
+	return isFound(GetDB(transaction).First(s))
+}
+
+// Save is saving model
+func (s *RowHistoryStatus) Save(transaction *DbTransaction) error {
+	s.ID = 1
+	return GetDB(transaction).Save(s).Error
+}
+
+// IndexRowHistory saves the states of the rows which are changed by the block. It must be called
+// after the block has been played in the same db transaction. If the previous block isn't indexed
+// the index is started again from this block
+func IndexRowHistory(transaction *DbTransaction, blockID int64) error {
+	status := &RowHistoryStatus{}
+	found, err := status.Get(transaction)
+	if err != nil {
+		return err
+	}
+	if !found || status.High != blockID-1 {
+		if err = GetDB(transaction).Exec(`DELETE FROM "` + rowHistoryTable + `"`).Error; err != nil {
+			return err
+		}
+		status.Low = blockID
+	}
+	rows, err := changedRows(transaction, blockID)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		state, err := currentRowState(transaction, row[0], row[1])
+		if err != nil {
+			return err
+		}
+		if err = saveRowState(transaction, row[0], row[1], blockID, state); err != nil {
+			return err
+		}
+	}
+	status.High = blockID
+	return status.Save(transaction)
+}
+
+// BackfillRowHistory indexes up to count blocks before the indexed range.
+// It returns the new lowest indexed block
+func BackfillRowHistory(transaction *DbTransaction, lastBlockID, count int64) (int64, error) {
+	status := &RowHistoryStatus{}
+	found, err := status.Get(transaction)
+	if err != nil || !found {
+		return 0, err
+	}
+	// the blocks which have been played without the archive mode aren't indexed yet
+	if status.Low <= 1 || status.High != lastBlockID {
+		return status.Low, nil
+	}
+	if count < 1 {
+		count = 1
+	}
+	from := status.Low - count
+	if from < 1 {
+		from = 1
+	}
+	for blockID := status.Low - 1; blockID >= from; blockID-- {
+		rows, err := changedRows(transaction, blockID)
+		if err != nil {
+			return 0, err
+		}
+		for _, row := range rows {
+			state, err := RowState(transaction, row[0], row[1], blockID)
+			if err != nil {
+				return 0, err
+			}
+			if err = saveRowState(transaction, row[0], row[1], blockID, state); err != nil {
+				return 0, err
+			}
+		}
+	}
+	status.Low = from
+	return from, status.Save(transaction)
+}
+
+// RollbackRowHistory deletes the states of the rows of the rolled back block and the next blocks
+func RollbackRowHistory(transaction *DbTransaction, blockID int64) error {
+	db := GetDB(transaction)
+	if err := db.Exec(`DELETE FROM "`+rowHistoryTable+`" WHERE block_id >= ?`, blockID).Error; err != nil {
+		return err
+	}
+	if err := db.Exec(`UPDATE "row_history_status" SET high = ? WHERE high >= ?`, blockID-1, blockID).Error; err != nil {
+		return err
+	}
+	return db.Exec(`DELETE FROM "row_history_status" WHERE high < low`).Error
+}
+
+// RowState returns the state of the row after the block, nil if the row didn't exist.
+// The block must be in the indexed range
+func RowState(transaction *DbTransaction, table, id string, blockID int64) (map[string]string, error) {
+	var data sql.NullString
+	err := GetDB(transaction).Raw(`SELECT data FROM "`+rowHistoryTable+`" WHERE table_name = ? AND
+		table_id = ? AND block_id <= ? ORDER BY block_id DESC LIMIT 1`, table, id, blockID).Row().Scan(&data)
+	if err == nil {
+		return decodeRowState(data)
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+	var next int64
+	err = GetDB(transaction).Raw(`SELECT block_id, data FROM "`+rowHistoryTable+`" WHERE table_name = ? AND
+		table_id = ? AND block_id > ? ORDER BY block_id LIMIT 1`, table, id, blockID).Row().Scan(&next, &data)
+	if err == sql.ErrNoRows {
+		return currentRowState(transaction, table, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the state before the next change is restored by the rollback data of that block
+	state, err := decodeRowState(data)
+	if err != nil {
+		return nil, err
+	}
+	var txs []RollbackTx
+	err = GetDB(transaction).Where("block_id = ? AND table_name = ? AND table_id = ?", next, table, id).
+		Order("id desc").Find(&txs).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range txs {
+		if len(tx.Data) == 0 {
+			state = nil
+			continue
+		}
+		values, err := UnmarshalRollbackData(tx.Data)
+		if err != nil {
+			return nil, err
+		}
+		if state == nil {
+			state = make(map[string]string)
+		}
+		for k, v := range values {
+			state[k] = v
+		}
+	}
+	return state, nil
+}
+
+// RowHistorySize returns the size of the index on the disk
+func RowHistorySize() (size int64, err error) {
+	err = DBConn.Raw(`SELECT pg_total_relation_size('` + rowHistoryTable + `')`).Row().Scan(&size)
+	return
+}
+
+// changedRows returns the table names and the ids of the rows which are changed by the block
+func changedRows(transaction *DbTransaction, blockID int64) ([][2]string, error) {
+	rows, err := GetDB(transaction).Raw(`SELECT DISTINCT table_name, table_id FROM rollback_tx
+		WHERE block_id = ?`, blockID).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ret [][2]string
+	for rows.Next() {
+		var row [2]string
+		if err = rows.Scan(&row[0], &row[1]); err != nil {
+			return nil, err
+		}
+		// the system changes like the new tables aren't the rows
+		if strings.HasPrefix(row[0], `@`) {
+			continue
+		}
+		ret = append(ret, row)
+	}
+	return ret, rows.Err()
+}
+
+func currentRowState(transaction *DbTransaction, table, id string) (map[string]string, error) {
+	row, err := GetOneRowTransaction(transaction, `SELECT * FROM `+converter.EscapeName(table)+
+		` WHERE id = ?`, id).String()
+	if err != nil || len(row) == 0 {
+		return nil, err
+	}
+	return row, nil
+}
+
+func saveRowState(transaction *DbTransaction, table, id string, blockID int64, state map[string]string) error {
+	var data interface{}
+	if state != nil {
+		out, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		data = string(out)
+	}
+	return GetDB(transaction).Exec(`INSERT INTO "`+rowHistoryTable+`" (table_name, table_id, block_id, data)
+		VALUES (?, ?, ?, ?)`, table, id, blockID, data).Error
+}
+
+func decodeRowState(data sql.NullString) (map[string]string, error) {
+	if !data.Valid {
+		return nil, nil
+	}
+	var state map[string]string
+	if err := json.Unmarshal([]byte(data.String), &state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
@@ -0,0 +1,199 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/migration"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrSchemaExists is returned by InitDB if the database already has tables
+var ErrSchemaExists = errors.New("database schema already exists")
+
+const firstEcosystemID = 1
+
+// SchemaStatus is the state of the schema of the node in the database
+type SchemaStatus struct {
+	Tables    []string // the tables of the current schema
+	Missing   []string // the tables of the migrations which are missing
+	Version   string   // the version of the last applied migration
+	Actual    bool     // all migrations are applied
+	Installed bool     // the installation is complete
+}
+
+// Present returns true if the database has any tables
+func (s *SchemaStatus) Present() bool {
+	return len(s.Tables) > 0
+}
+
+// Complete returns true if the schema is created by InitDB and isn't changed since
+func (s *SchemaStatus) Complete() bool {
+	return s.Actual && s.Installed && len(s.Missing) == 0
+}
+
+// CheckSchema returns the state of the schema of the connected database without changing it
+func CheckSchema() (*SchemaStatus, error) {
+	tables, err := GetTableNames()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting table names")
+		return nil, err
+	}
+	status := &SchemaStatus{Tables: tables, Missing: make([]string, 0)}
+	exists := make(map[string]bool, len(tables))
+	for _, name := range tables {
+		exists[name] = true
+	}
+	for _, name := range migration.Tables() {
+		if !exists[name] {
+			status.Missing = append(status.Missing, name)
+		}
+	}
+
+	if status.Version, err = (&MigrationHistory{}).CurrentVersion(); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting migration version")
+		return nil, err
+	}
+	if status.Actual, err = migration.IsActual(status.Version); err != nil {
+		return nil, err
+	}
+	if exists[(&Install{}).TableName()] {
+		install := &Install{}
+		if err = install.Get(); err != nil && err != ErrRecordNotFound {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting install")
+			return nil, err
+		}
+		status.Installed = install.Progress == ProgressComplete
+	}
+	return status, nil
+}
+
+// Seed is the data of the test environment which is applied after the schema is created.
+// The first block recreates the tables of the first ecosystem, so the seed replaces the first block
+type Seed struct {
+	// Ecosystem creates the tables of the first ecosystem
+	Ecosystem *SeedEcosystem `json:"ecosystem"`
+	// SystemParameters are the new values of the system parameters
+	SystemParameters map[string]string `json:"system_parameters"`
+	// Tables are the rows which are inserted into the tables
+	Tables map[string][]map[string]interface{} `json:"tables"`
+}
+
+// SeedEcosystem is the founder and the name of the first ecosystem
+type SeedEcosystem struct {
+	KeyID int64  `json:"key_id"`
+	Name  string `json:"name"`
+}
+
+// ReadSeed reads the JSON seed
+func ReadSeed(r io.Reader) (*Seed, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+	seed := &Seed{}
+	if err := dec.Decode(seed); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("decoding seed")
+		return nil, err
+	}
+	return seed, nil
+}
+
+// ApplySeed applies the seed to the connected database in one transaction
+func ApplySeed(seed *Seed) error {
+	tx, err := StartTransaction()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("starting transaction")
+		return err
+	}
+	if err = applySeed(tx, seed); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func applySeed(tx *DbTransaction, seed *Seed) error {
+	if eco := seed.Ecosystem; eco != nil {
+		if err := ExecSchemaEcosystem(tx, firstEcosystemID, eco.KeyID, eco.Name, eco.KeyID); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(seed.SystemParameters))
+	for name := range seed.SystemParameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		res := GetDB(tx).Exec(`UPDATE "1_system_parameters" SET value = ? WHERE name = ?`,
+			seed.SystemParameters[name], name)
+		if res.Error != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": res.Error, "name": name}).Error("updating system parameter")
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("system parameter %s isn't found", name)
+		}
+	}
+
+	tables := make([]string, 0, len(seed.Tables))
+	for table := range seed.Tables {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		for _, row := range seed.Tables[table] {
+			query, args, err := seedInsertQuery(table, row)
+			if err != nil {
+				return err
+			}
+			if err = GetDB(tx).Exec(query, args...).Error; err != nil {
+				log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table}).Error("inserting seed row")
+				return fmt.Errorf("table %s: %s", table, err)
+			}
+		}
+	}
+	return nil
+}
+
+// seedInsertQuery returns the query which inserts the row with the sorted columns
+func seedInsertQuery(table string, row map[string]interface{}) (string, []interface{}, error) {
+	if strings.Contains(table, `"`) {
+		return ``, nil, fmt.Errorf("invalid table %s", table)
+	}
+	if len(row) == 0 {
+		return ``, nil, fmt.Errorf("table %s: empty row", table)
+	}
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	args := make([]interface{}, len(columns))
+	for i, column := range columns {
+		if strings.Contains(column, `"`) {
+			return ``, nil, fmt.Errorf("table %s: invalid column %s", table, column)
+		}
+		switch value := row[column].(type) {
+		case json.Number:
+			args[i] = value.String()
+		case map[string]interface{}, []interface{}:
+			data, err := json.Marshal(value)
+			if err != nil {
+				return ``, nil, err
+			}
+			args[i] = string(data)
+		default:
+			args[i] = value
+		}
+		columns[i] = `"` + column + `"`
+	}
+	return fmt.Sprintf(`INSERT INTO "%s" (%s) VALUES (%s)`, table, strings.Join(columns, `,`),
+		strings.TrimSuffix(strings.Repeat(`?,`, len(columns)), `,`)), args, nil
+}
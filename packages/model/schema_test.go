@@ -0,0 +1,109 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDB creates the temporary database on the server of the GENESIS_DB_* environment variables.
+// The test is skipped if the server isn't available
+func testDB(t *testing.T) (conf.DBConfig, func()) {
+	cfg := conf.DBConfig{Host: "127.0.0.1", Port: 5432, User: "postgres", Password: os.Getenv("GENESIS_DB_PASSWORD"),
+		Name: "genesis_test_schema"}
+	if host := os.Getenv("GENESIS_DB_HOST"); len(host) > 0 {
+		cfg.Host = host
+	}
+	if port, err := strconv.Atoi(os.Getenv("GENESIS_DB_PORT")); err == nil {
+		cfg.Port = port
+	}
+	if user := os.Getenv("GENESIS_DB_USER"); len(user) > 0 {
+		cfg.User = user
+	}
+	if err := GormInit(cfg.Host, cfg.Port, cfg.User, cfg.Password, "postgres"); err != nil {
+		t.Skipf("postgres isn't available: %s", err)
+	}
+	require.NoError(t, DropDatabase(cfg.Name))
+	require.NoError(t, DBConn.Exec(fmt.Sprintf(`CREATE DATABASE "%s"`, cfg.Name)).Error)
+	require.NoError(t, GormClose())
+
+	return cfg, func() {
+		GormClose()
+		if err := GormInit(cfg.Host, cfg.Port, cfg.User, cfg.Password, "postgres"); err == nil {
+			DropDatabase(cfg.Name)
+			GormClose()
+		}
+	}
+}
+
+func TestInitDB(t *testing.T) {
+	cfg, cleanup := testDB(t)
+	defer cleanup()
+
+	require.NoError(t, InitDB(cfg, false))
+	status, err := CheckSchema()
+	require.NoError(t, err)
+	assert.True(t, status.Complete(), "%+v", status)
+
+	assert.Equal(t, ErrSchemaExists, InitDB(cfg, false))
+	require.NoError(t, InitDB(cfg, true))
+
+	require.NoError(t, DBConn.Exec(`DROP TABLE "queue_tx"`).Error)
+	status, err = CheckSchema()
+	require.NoError(t, err)
+	assert.True(t, status.Present())
+	assert.False(t, status.Complete())
+	assert.Equal(t, []string{"queue_tx"}, status.Missing)
+}
+
+func TestApplySeed(t *testing.T) {
+	cfg, cleanup := testDB(t)
+	defer cleanup()
+	require.NoError(t, InitDB(cfg, false))
+
+	seed, err := ReadSeed(strings.NewReader(`{
+		"ecosystem": {"key_id": -1744264011260937456, "name": "test"},
+		"system_parameters": {"max_tx_count": "500"},
+		"tables": {"1_keys": [{"id": 1234, "amount": "1000000"}]}
+	}`))
+	require.NoError(t, err)
+	require.NoError(t, ApplySeed(seed))
+
+	value, err := Single(`SELECT value FROM "1_system_parameters" WHERE name = 'max_tx_count'`).String()
+	require.NoError(t, err)
+	assert.Equal(t, "500", value)
+	amount, err := Single(`SELECT amount FROM "1_keys" WHERE id = 1234`).String()
+	require.NoError(t, err)
+	assert.Equal(t, "1000000", amount)
+
+	// the failed seed doesn't change anything
+	seed.SystemParameters = map[string]string{"max_tx_count": "100", "unknown": "1"}
+	assert.EqualError(t, ApplySeed(seed), "system parameter unknown isn't found")
+	value, err = Single(`SELECT value FROM "1_system_parameters" WHERE name = 'max_tx_count'`).String()
+	require.NoError(t, err)
+	assert.Equal(t, "500", value)
+}
+
+func TestReadSeed(t *testing.T) {
+	_, err := ReadSeed(strings.NewReader(`{"unknown": 1}`))
+	assert.Error(t, err)
+
+	seed, err := ReadSeed(strings.NewReader(`{"tables": {"1_keys": [{"id": -1744264011260937456, "pub": "", "multi": {"a": 1}}]}}`))
+	require.NoError(t, err)
+	query, args, err := seedInsertQuery("1_keys", seed.Tables["1_keys"][0])
+	require.NoError(t, err)
+	assert.Equal(t, `INSERT INTO "1_keys" ("id","multi","pub") VALUES (?,?,?)`, query)
+	assert.Equal(t, []interface{}{"-1744264011260937456", `{"a":1}`, ""}, args)
+
+	_, _, err = seedInsertQuery("1_keys", map[string]interface{}{`id"`: 1})
+	assert.Error(t, err)
+	_, _, err = seedInsertQuery("1_keys", map[string]interface{}{})
+	assert.Error(t, err)
+}
@@ -2,14 +2,18 @@ package model
 
 import (
 	"encoding/json"
+
+	"github.com/GenesisKernel/go-genesis/packages/converter"
 )
 
 // SystemParameter is model
 type SystemParameter struct {
-	ID         int64  `gorm:"primary_key;not null;"`
-	Name       string `gorm:"not null;size:255"`
-	Value      string `gorm:"not null"`
-	Conditions string `gorm:"not null"`
+	ID              int64  `gorm:"primary_key;not null;"`
+	Name            string `gorm:"not null;size:255"`
+	Value           string `gorm:"not null"`
+	Conditions      string `gorm:"not null"`
+	PendingValue    string `gorm:"not null"`
+	ActivationBlock int64  `gorm:"not null"`
 }
 
 // TableName returns name of table
@@ -59,6 +63,8 @@ func (sp *SystemParameter) ToMap() map[string]string {
 	result["name"] = sp.Name
 	result["value"] = sp.Value
 	result["conditions"] = sp.Conditions
+	result["pending_value"] = sp.PendingValue
+	result["activation_block"] = converter.Int64ToStr(sp.ActivationBlock)
 	return result
 }
 
@@ -1,12 +1,16 @@
 package model
 
+import "github.com/GenesisKernel/go-genesis/packages/consts"
+
 const ecosysTable = "1_ecosystems"
 
 // Ecosystem is model
 type Ecosystem struct {
-	ID       int64 `gorm:"primary_key;not null"`
-	Name     string
-	IsValued bool
+	ID            int64 `gorm:"primary_key;not null"`
+	Name          string
+	IsValued      bool
+	TokenDecimals int64
+	TotalSupply   string `gorm:"not null"`
 }
 
 // TableName returns name of table
@@ -40,6 +44,22 @@ func (sys *Ecosystem) Get(id int64) (bool, error) {
 	return isFound(DBConn.First(sys, "id = ?", id))
 }
 
+// GetTransaction is retrieving the ecosystem with id using transaction
+func (sys *Ecosystem) GetTransaction(transaction *DbTransaction, id int64) (bool, error) {
+	return isFound(GetDB(transaction).First(sys, "id = ?", id))
+}
+
+// GetTokenDecimals returns the number of the decimals of the ecosystem token,
+// the unknown ecosystem has the decimals of the platform token
+func GetTokenDecimals(id int64) (int, error) {
+	sys := &Ecosystem{}
+	found, err := sys.Get(id)
+	if err != nil || !found {
+		return consts.EGS_DIGIT, err
+	}
+	return int(sys.TokenDecimals), nil
+}
+
 // Delete is deleting record
 func (sys *Ecosystem) Delete(transaction *DbTransaction) error {
 	return GetDB(transaction).Delete(sys).Error
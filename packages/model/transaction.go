@@ -26,6 +26,15 @@ type Transaction struct {
 	Verified int8            `gorm:"not null;default:1"`
 }
 
+// GetUnusedTransactionsByKeys returns the unused transactions of the keys
+func GetUnusedTransactionsByKeys(keys []int64) ([]*Transaction, error) {
+	var transactions []*Transaction
+	if err := DBConn.Where("used = ? AND key_id IN (?)", "0", keys).Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
 // GetAllTransactions is retrieving all transactions with limit
 func GetAllTransactions(limit int) (*[]Transaction, error) {
 	transactions := new([]Transaction)
@@ -68,6 +77,15 @@ func GetTransactionCountAll() (int64, error) {
 	return rowsCount, nil
 }
 
+// GetUnusedTransactionsCount counts the transactions which aren't included in a block yet
+func GetUnusedTransactionsCount() (int64, error) {
+	var rowsCount int64
+	if err := DBConn.Table("transactions").Where("used = ?", 0).Count(&rowsCount).Error; err != nil {
+		return -1, err
+	}
+	return rowsCount, nil
+}
+
 // GetTransactionsCount count all transactions by hash
 func GetTransactionsCount(hash []byte) (int64, error) {
 	var rowsCount int64
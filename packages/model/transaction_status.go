@@ -40,3 +40,14 @@ func (ts *TransactionStatus) UpdateBlockMsg(transaction *DbTransaction, newBlock
 func (ts *TransactionStatus) SetError(transaction *DbTransaction, errorText string, transactionHash []byte) error {
 	return GetDB(transaction).Model(&TransactionStatus{}).Where("hash = ?", transactionHash).Update("error", errorText).Error
 }
+
+// GetFailedTxHashes returns the hashes of the list which have the error and haven't been included in a block
+func GetFailedTxHashes(transaction *DbTransaction, hashes [][]byte) ([][]byte, error) {
+	var failed [][]byte
+	if len(hashes) == 0 {
+		return failed, nil
+	}
+	err := GetDB(transaction).Model(&TransactionStatus{}).Where("hash IN (?) AND block_id = 0 AND error <> ''", hashes).
+		Pluck("hash", &failed).Error
+	return failed, err
+}
@@ -0,0 +1,19 @@
+package model
+
+const tableTxNonces = "1_tx_nonces"
+
+// TxNonce is the last executed nonce of the transactions of the key
+type TxNonce struct {
+	ID    int64 `gorm:"primary_key;not null"`
+	Nonce int64 `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (TxNonce) TableName() string {
+	return tableTxNonces
+}
+
+// GetTransaction returns the last nonce of the key, the found flag is false if the key hasn't sent nonces
+func (n *TxNonce) GetTransaction(transaction *DbTransaction, keyID int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("id = ?", keyID).First(n))
+}
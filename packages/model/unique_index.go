@@ -0,0 +1,62 @@
+package model
+
+// UniqueIndex is the unique index of the table. Constraint is the name of the constraint
+// which owns the index if the index has been created by UNIQUE of the column
+type UniqueIndex struct {
+	Name       string `json:"name"`
+	Constraint string `json:"constraint,omitempty"`
+	Definition string `json:"definition"`
+}
+
+// GetUniqueIndexes returns the unique indexes of the table except the primary key and the partial indexes
+func GetUniqueIndexes(transaction *DbTransaction, tableName string) ([]UniqueIndex, error) {
+	rows, err := GetDB(transaction).Raw(`SELECT c.relname, COALESCE(con.conname, ''), pg_get_indexdef(i.indexrelid)
+		FROM pg_index i
+		INNER JOIN pg_class c ON c.oid = i.indexrelid
+		LEFT JOIN pg_constraint con ON con.conindid = i.indexrelid AND con.contype = 'u'
+		WHERE i.indrelid = to_regclass(?) AND i.indisunique AND NOT i.indisprimary AND i.indpred IS NULL
+		ORDER BY c.relname`, `"`+tableName+`"`).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes []UniqueIndex
+	for rows.Next() {
+		var index UniqueIndex
+		if err = rows.Scan(&index.Name, &index.Constraint, &index.Definition); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, rows.Err()
+}
+
+// SetIndexCondition replaces the unique index with the partial index which is unique only for the rows
+// matching the condition
+func SetIndexCondition(transaction *DbTransaction, tableName string, index UniqueIndex, condition string) error {
+	db := GetDB(transaction)
+	drop := `DROP INDEX "` + index.Name + `"`
+	if len(index.Constraint) > 0 {
+		drop = `ALTER TABLE "` + tableName + `" DROP CONSTRAINT "` + index.Constraint + `"`
+	}
+	if err := db.Exec(drop).Error; err != nil {
+		return err
+	}
+	return db.Exec(index.Definition + ` WHERE ` + condition).Error
+}
+
+// RestoreUniqueIndex replaces the partial index with the original unique index
+func RestoreUniqueIndex(transaction *DbTransaction, tableName string, index UniqueIndex) error {
+	db := GetDB(transaction)
+	if err := db.Exec(`DROP INDEX IF EXISTS "` + index.Name + `"`).Error; err != nil {
+		return err
+	}
+	if err := db.Exec(index.Definition).Error; err != nil {
+		return err
+	}
+	if len(index.Constraint) == 0 {
+		return nil
+	}
+	return db.Exec(`ALTER TABLE "` + tableName + `" ADD CONSTRAINT "` + index.Constraint +
+		`" UNIQUE USING INDEX "` + index.Name + `"`).Error
+}
@@ -0,0 +1,152 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SyncSourceActive is the status of the source which is synchronized
+	SyncSourceActive = `active`
+	// SyncSourcePaused is the status of the paused source
+	SyncSourcePaused = `paused`
+
+	// SyncConflictSource overwrites the local changes of the synchronized rows
+	SyncConflictSource = `source`
+	// SyncConflictSkip keeps the local changes and flags the row
+	SyncConflictSkip = `skip`
+)
+
+// SyncSource represents record of {prefix}_vde_sync_sources table
+type SyncSource struct {
+	tableName       string
+	ID              int64
+	Name            string
+	SourceType      string
+	URL             string `gorm:"column:url"`
+	SourceEcosystem int64
+	SourceTable     string
+	TargetTable     string
+	Columns         string
+	Period          int64
+	Conflict        string
+	Cursor          int64
+	Status          string
+	LastSync        *time.Time
+	LastError       string
+}
+
+// SetTablePrefix is setting table prefix
+func (s *SyncSource) SetTablePrefix(prefix int64) {
+	s.tableName = fmt.Sprintf(`%d_vde_sync_sources`, prefix)
+}
+
+// TableName returns name of table
+func (s *SyncSource) TableName() string {
+	return s.tableName
+}
+
+// Get is retrieving model from database
+func (s *SyncSource) Get(transaction *DbTransaction, id int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("id = ?", id).First(s))
+}
+
+// GetSourcesToSync returns the active sources which period has passed
+func (s *SyncSource) GetSourcesToSync(now time.Time) ([]SyncSource, error) {
+	var sources []SyncSource
+	err := DBConn.Table(s.TableName()).Where(`status = ? AND (last_sync IS NULL OR
+		last_sync + period * interval '1 second' <= ?)`, SyncSourceActive, now).Order("id").Find(&sources).Error
+	for i := range sources {
+		sources[i].tableName = s.tableName
+	}
+	return sources, err
+}
+
+// SetResult saves the cursor and the error of the synchronization
+func (s *SyncSource) SetResult(transaction *DbTransaction, cursor int64, lastError string, now time.Time) error {
+	return GetDB(transaction).Table(s.TableName()).Where("id = ?", s.ID).Updates(map[string]interface{}{
+		"cursor": cursor, "last_error": lastError, "last_sync": now}).Error
+}
+
+// SyncRow represents record of {prefix}_vde_sync_rows table
+type SyncRow struct {
+	tableName string
+	ID        int64
+	SourceID  int64
+	RowID     int64
+	Hash      string
+	Flagged   int64
+}
+
+// SetTablePrefix is setting table prefix
+func (r *SyncRow) SetTablePrefix(prefix int64) {
+	r.tableName = fmt.Sprintf(`%d_vde_sync_rows`, prefix)
+}
+
+// TableName returns name of table
+func (r *SyncRow) TableName() string {
+	return r.tableName
+}
+
+// Get is retrieving the synchronized row of the source
+func (r *SyncRow) Get(transaction *DbTransaction, sourceID, rowID int64) (bool, error) {
+	return isFound(GetDB(transaction).Where("source_id = ? AND row_id = ?", sourceID, rowID).First(r))
+}
+
+// Save inserts or updates the synchronized row
+func (r *SyncRow) Save(transaction *DbTransaction) error {
+	if r.ID == 0 {
+		id, err := GetNextID(transaction, r.TableName())
+		if err != nil {
+			return err
+		}
+		r.ID = id
+		return GetDB(transaction).Create(r).Error
+	}
+	return GetDB(transaction).Save(r).Error
+}
+
+// GetSyncedRow returns the columns of the row of the table, found is false if the row doesn't exist
+func GetSyncedRow(transaction *DbTransaction, table string, id int64, columns []string) (map[string]string, bool, error) {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = `"` + column + `"`
+	}
+	rows, err := GetAllTransaction(transaction, `SELECT `+strings.Join(names, `,`)+` FROM "`+table+
+		`" WHERE id = ?`, 1, id)
+	if err != nil || len(rows) == 0 {
+		return nil, false, err
+	}
+	return rows[0], true, nil
+}
+
+// SaveSyncedRow inserts or updates the row of the table with the values of the columns
+func SaveSyncedRow(transaction *DbTransaction, table string, id int64, values map[string]interface{}, exists bool) error {
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	args := make([]interface{}, 0, len(columns)+1)
+	if exists {
+		set := make([]string, len(columns))
+		for i, column := range columns {
+			set[i] = `"` + column + `" = ?`
+			args = append(args, values[column])
+		}
+		args = append(args, id)
+		return GetDB(transaction).Exec(`UPDATE "`+table+`" SET `+strings.Join(set, `,`)+
+			` WHERE id = ?`, args...).Error
+	}
+	names := []string{`"id"`}
+	args = append(args, strconv.FormatInt(id, 10))
+	for _, column := range columns {
+		names = append(names, `"`+column+`"`)
+		args = append(args, values[column])
+	}
+	return GetDB(transaction).Exec(`INSERT INTO "`+table+`" (`+strings.Join(names, `,`)+`) VALUES (`+
+		strings.TrimSuffix(strings.Repeat(`?,`, len(names)), `,`)+`)`, args...).Error
+}
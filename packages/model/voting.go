@@ -0,0 +1,58 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Statuses of the voting
+const (
+	VotingActive   = 0
+	VotingAccepted = 1
+	VotingRejected = 2
+)
+
+// Voting represents record of {prefix}_votings table
+type Voting struct {
+	prefix      int64
+	ID          int64  `gorm:"primary_key;not null"`
+	Subject     string `gorm:"type:jsonb(PostgreSQL)"`
+	Voters      string `gorm:"type:jsonb(PostgreSQL)"`
+	Votes       string `gorm:"type:jsonb(PostgreSQL);not null"`
+	Threshold   int64  `gorm:"not null"`
+	Deadline    int64  `gorm:"not null"`
+	Creator     int64  `gorm:"not null"`
+	Status      int64  `gorm:"not null"`
+	Result      string `gorm:"not null"`
+	DateCreated *time.Time
+}
+
+// SetTablePrefix is setting table prefix
+func (v *Voting) SetTablePrefix(prefix int64) *Voting {
+	if prefix == 0 {
+		prefix = 1
+	}
+	v.prefix = prefix
+	return v
+}
+
+// TableName returns name of table
+func (v Voting) TableName() string {
+	if v.prefix == 0 {
+		v.prefix = 1
+	}
+	return fmt.Sprintf("%d_votings", v.prefix)
+}
+
+// Get is retrieving model from database
+func (v *Voting) Get(transaction *DbTransaction, id int64) (bool, error) {
+	return isFound(GetDB(transaction).Table(v.TableName()).Where("id = ?", id).First(v))
+}
+
+// GetActiveVotings returns the votings which are waiting for the votes at the moment now
+func (v *Voting) GetActiveVotings(now int64) ([]Voting, error) {
+	var votings []Voting
+	err := DBConn.Table(v.TableName()).Where("status = ? AND deadline > ?", VotingActive, now).
+		Order("id").Find(&votings).Error
+	return votings, err
+}
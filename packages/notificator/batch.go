@@ -0,0 +1,136 @@
+package notificator
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/publisher"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Batch collects the ecosystems whose notifications were changed during the block processing.
+// The stats are published only after the commit of the block's db transaction
+type Batch struct {
+	mu         sync.Mutex
+	ecosystems map[int64]struct{}
+	events     map[eventsKey]struct{}
+	blocks     map[int64]struct{}
+	txs        [][]byte
+}
+
+// eventsKey is the ecosystem and the block which have the emitted events
+type eventsKey struct {
+	ecosystemID int64
+	blockID     int64
+}
+
+// NewBatch returns new batch of notifications
+func NewBatch() *Batch {
+	return &Batch{ecosystems: make(map[int64]struct{}), events: make(map[eventsKey]struct{}),
+		blocks: make(map[int64]struct{})}
+}
+
+// Add marks notifications of the ecosystem as changed
+func (b *Batch) Add(ecosystemID int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.ecosystems[ecosystemID] = struct{}{}
+}
+
+// AddEvents marks that the events have been emitted in the ecosystem in the block
+func (b *Batch) AddEvents(ecosystemID, blockID int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.events[eventsKey{ecosystemID, blockID}] = struct{}{}
+}
+
+// AddBlock marks that the changes of the cached objects of the block are published, see sendCache
+func (b *Batch) AddBlock(blockID int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.blocks[blockID] = struct{}{}
+}
+
+// AddTxStatus marks that the status of the transaction has been changed, see PublishTxStatus
+func (b *Batch) AddTxStatus(hash []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.txs = append(b.txs, hash)
+}
+
+// Discard drops the collected changes, it is called when the db transaction is rolled back
+func (b *Batch) Discard() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.ecosystems = make(map[int64]struct{})
+	b.events = make(map[eventsKey]struct{})
+	b.blocks = make(map[int64]struct{})
+	b.txs = nil
+}
+
+// Send publishes the changed stats of users as one message per channel
+func (b *Batch) Send() {
+	b.mu.Lock()
+	ecosystems := make([]int64, 0, len(b.ecosystems))
+	for ecosystemID := range b.ecosystems {
+		ecosystems = append(ecosystems, ecosystemID)
+	}
+	b.ecosystems = make(map[int64]struct{})
+	events := make([]eventsKey, 0, len(b.events))
+	for key := range b.events {
+		events = append(events, key)
+	}
+	b.events = make(map[eventsKey]struct{})
+	blocks := make([]int64, 0, len(b.blocks))
+	for blockID := range b.blocks {
+		blocks = append(blocks, blockID)
+	}
+	b.blocks = make(map[int64]struct{})
+	txs := b.txs
+	b.txs = nil
+	b.mu.Unlock()
+
+	PublishTxStatus(txs...)
+	sendEvents(events)
+	sendCache(blocks)
+	if len(ecosystems) == 0 {
+		return
+	}
+	sort.Slice(ecosystems, func(i, j int) bool { return ecosystems[i] < ecosystems[j] })
+
+	messages := make(map[int64][]notificationRecord)
+	for _, ecosystemID := range ecosystems {
+		mu.Lock()
+		var users []int64
+		if val, ok := systemUsers[ecosystemID]; ok {
+			users = append(users, *val...)
+		}
+		mu.Unlock()
+
+		if len(users) > 0 {
+			collectChangedStats(ecosystemID, users, messages)
+		}
+	}
+
+	rawMessages := make(map[int64]string, len(messages))
+	for user, stats := range messages {
+		rawStats, err := json.Marshal(stats)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("notification statistic")
+			continue
+		}
+		rawMessages[user] = string(rawStats)
+	}
+
+	if err := publisher.WriteBatch(rawMessages); err != nil {
+		log.WithFields(log.Fields{"type": consts.CentrifugoError, "error": err}).Error("writing batch to centrifugo")
+	}
+}
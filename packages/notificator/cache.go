@@ -0,0 +1,130 @@
+package notificator
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/publisher"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// cacheRecord is the message which lists the names of the cached objects of the ecosystem
+// changed in the block, the names are grouped by the type of the object
+type cacheRecord struct {
+	BlockID string              `json:"block_id"`
+	Changes map[string][]string `json:"changes"`
+}
+
+var (
+	regCacheTable = regexp.MustCompile(`^(\d+)_(pages|menu|parameters|languages)$`)
+
+	blockRollbacks = getBlockRollbacks
+	objectNames    = model.GetNamesByIDs
+)
+
+// getBlockRollbacks returns the rollback records which have been written during the block processing
+func getBlockRollbacks(blockID int64) ([]model.RollbackTx, error) {
+	rollback := &model.RollbackTx{}
+	return rollback.GetBlockRollbackTransactions(nil, blockID)
+}
+
+// sendCache publishes the changed pages, menus, parameters and languages of the blocks
+// to the cache channels of the ecosystems, one message per ecosystem and block
+func sendCache(blocks []int64) {
+	if len(blocks) == 0 {
+		return
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i] < blocks[j] })
+
+	messages := make(map[int64][]string)
+	for _, blockID := range blocks {
+		changes, err := blockChanges(blockID)
+		if err != nil {
+			continue
+		}
+		ecosystems := make([]int64, 0, len(changes))
+		for ecosystemID := range changes {
+			ecosystems = append(ecosystems, ecosystemID)
+		}
+		sort.Slice(ecosystems, func(i, j int) bool { return ecosystems[i] < ecosystems[j] })
+		for _, ecosystemID := range ecosystems {
+			data, err := json.Marshal(cacheRecord{BlockID: strconv.FormatInt(blockID, 10),
+				Changes: changes[ecosystemID]})
+			if err != nil {
+				log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling cache changes")
+				continue
+			}
+			messages[ecosystemID] = append(messages[ecosystemID], string(data))
+		}
+	}
+
+	if err := publisher.WriteCache(messages); err != nil {
+		log.WithFields(log.Fields{"type": consts.CentrifugoError, "error": err}).Error("writing cache changes to centrifugo")
+	}
+}
+
+// blockChanges returns the sorted names of the changed objects of the block by the ecosystems and the types
+func blockChanges(blockID int64) (map[int64]map[string][]string, error) {
+	rollbacks, err := blockRollbacks(blockID)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": blockID}).Error("getting rollbacks of block")
+		return nil, err
+	}
+	ids := make(map[string][]int64)
+	previous := make(map[string]map[int64]string)
+	for _, rollback := range rollbacks {
+		if !regCacheTable.MatchString(rollback.NameTable) {
+			continue
+		}
+		id, err := strconv.ParseInt(rollback.TableID, 10, 64)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": rollback.TableID}).Error("converting rollback table id")
+			continue
+		}
+		ids[rollback.NameTable] = append(ids[rollback.NameTable], id)
+		// the previous name of the renamed row is only in the rollback data
+		if data, err := model.UnmarshalRollbackData(rollback.Data); err == nil && len(data[`name`]) > 0 {
+			if previous[rollback.NameTable] == nil {
+				previous[rollback.NameTable] = make(map[int64]string)
+			}
+			previous[rollback.NameTable][id] = data[`name`]
+		}
+	}
+
+	changes := make(map[int64]map[string][]string)
+	for table, tableIDs := range ids {
+		names, err := objectNames(table, tableIDs)
+		if err != nil {
+			return nil, err
+		}
+		unique := make(map[string]struct{})
+		for _, id := range tableIDs {
+			if name, ok := names[id]; ok {
+				unique[name] = struct{}{}
+			}
+			if name, ok := previous[table][id]; ok {
+				unique[name] = struct{}{}
+			}
+		}
+		if len(unique) == 0 {
+			continue
+		}
+		match := regCacheTable.FindStringSubmatch(table)
+		ecosystemID, _ := strconv.ParseInt(match[1], 10, 64)
+		list := make([]string, 0, len(unique))
+		for name := range unique {
+			list = append(list, name)
+		}
+		sort.Strings(list)
+		if changes[ecosystemID] == nil {
+			changes[ecosystemID] = make(map[string][]string)
+		}
+		changes[ecosystemID][match[2]] = list
+	}
+	return changes, nil
+}
@@ -0,0 +1,72 @@
+package notificator
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/publisher"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// eventRecord is the message of the event which is published to the channel of the ecosystem
+type eventRecord struct {
+	ID       string          `json:"id"`
+	BlockID  string          `json:"block_id"`
+	TxHash   string          `json:"tx_hash"`
+	Contract string          `json:"contract"`
+	Name     string          `json:"name"`
+	Data     json.RawMessage `json:"data"`
+}
+
+var blockEvents = getBlockEvents
+
+// getBlockEvents returns the committed events of the ecosystem in the block
+func getBlockEvents(ecosystemID, blockID int64) ([]model.Event, error) {
+	event := &model.Event{}
+	return event.SetTablePrefix(ecosystemID).GetBlockEvents(blockID)
+}
+
+// sendEvents publishes the events of the blocks to the channels of the ecosystems
+func sendEvents(keys []eventsKey) {
+	if len(keys) == 0 {
+		return
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].ecosystemID == keys[j].ecosystemID {
+			return keys[i].blockID < keys[j].blockID
+		}
+		return keys[i].ecosystemID < keys[j].ecosystemID
+	})
+
+	messages := make(map[int64][]string)
+	for _, key := range keys {
+		events, err := blockEvents(key.ecosystemID, key.blockID)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "ecosystem": key.ecosystemID}).Error("getting events of block")
+			continue
+		}
+		for _, event := range events {
+			data, err := json.Marshal(eventRecord{
+				ID:       strconv.FormatInt(event.ID, 10),
+				BlockID:  strconv.FormatInt(event.BlockID, 10),
+				TxHash:   event.TxHash,
+				Contract: event.Contract,
+				Name:     event.Name,
+				Data:     json.RawMessage(event.Data),
+			})
+			if err != nil {
+				log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling event")
+				continue
+			}
+			messages[key.ecosystemID] = append(messages[key.ecosystemID], string(data))
+		}
+	}
+
+	if err := publisher.WriteEvents(messages); err != nil {
+		log.WithFields(log.Fields{"type": consts.CentrifugoError, "error": err}).Error("writing events to centrifugo")
+	}
+}
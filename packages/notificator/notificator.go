@@ -60,6 +60,8 @@ var (
 	systemUsers       map[int64]*[]int64
 	mu                sync.Mutex
 	lastMessagesStats *lastMessages
+
+	notificationStats = getEcosystemNotificationStats
 )
 
 func init() {
@@ -84,8 +86,16 @@ func AddUser(userID, systemID int64) {
 
 // UpdateNotifications send stats about unreaded messages to centrifugo for ecosystem
 func UpdateNotifications(ecosystemID int64, users []int64) {
+	messages := make(map[int64][]notificationRecord)
+	collectChangedStats(ecosystemID, users, messages)
+	for user, stats := range messages {
+		sendUserStats(user, stats)
+	}
+}
 
-	notificationsStats, err := getEcosystemNotificationStats(ecosystemID, users)
+// collectChangedStats appends the changed stats of users to the messages
+func collectChangedStats(ecosystemID int64, users []int64, messages map[int64][]notificationRecord) {
+	notificationsStats, err := notificationStats(ecosystemID, users)
 	if err != nil {
 		return
 	}
@@ -110,12 +120,12 @@ func UpdateNotifications(ecosystemID int64, users []int64) {
 			}
 
 			lastMessagesStats.delete(ecosystemID, user)
-			sendUserStats(user, oldStats)
+			messages[user] = append(messages[user], oldStats...)
 			continue
 		}
 
 		lastMessagesStats.set(ecosystemID, user, newStats)
-		sendUserStats(user, newStats)
+		messages[user] = append(messages[user], newStats...)
 	}
 }
 
@@ -221,7 +231,7 @@ func sendUserStats(user int64, stats []notificationRecord) {
 // SendNotificationsByRequest send stats by systemUsers one time
 func SendNotificationsByRequest(systemUsers map[int64][]int64) {
 	for ecosystemID, users := range systemUsers {
-		stats, err := getEcosystemNotificationStats(ecosystemID, users)
+		stats, err := notificationStats(ecosystemID, users)
 		if err != nil {
 			continue
 		}
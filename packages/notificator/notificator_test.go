@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/publisher"
+
+	"github.com/centrifugal/gocent"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -217,3 +221,152 @@ func TestStatsChanged(t *testing.T) {
 		}
 	}
 }
+
+type publishedMessage struct {
+	channel string
+	data    string
+}
+
+type fakeClient struct {
+	buffer    []publishedMessage
+	published []publishedMessage
+	sends     int
+}
+
+func (c *fakeClient) Publish(channel string, data []byte) (bool, error) {
+	c.published = append(c.published, publishedMessage{channel, string(data)})
+	return true, nil
+}
+
+func (c *fakeClient) AddPublish(channel string, data []byte) error {
+	c.buffer = append(c.buffer, publishedMessage{channel, string(data)})
+	return nil
+}
+
+func (c *fakeClient) Send() (gocent.Result, error) {
+	c.published = append(c.published, c.buffer...)
+	c.buffer = nil
+	c.sends++
+	return gocent.Result{}, nil
+}
+
+func (c *fakeClient) Reset() {
+	c.buffer = nil
+}
+
+func setupBatchTest(users map[int64]*[]int64) *fakeClient {
+	client := &fakeClient{}
+	publisher.SetClient(client)
+	systemUsers = users
+	lastMessagesStats = newLastMessages()
+	notificationStats = func(ecosystemID int64, users []int64) (map[int64]*[]notificationRecord, error) {
+		stats := make(map[int64]*[]notificationRecord)
+		for _, user := range users {
+			stats[user] = &[]notificationRecord{{EcosystemID: ecosystemID, RoleID: 1, RecordsCount: user}}
+		}
+		return stats, nil
+	}
+	return client
+}
+
+func TestBatchSend(t *testing.T) {
+	client := setupBatchTest(map[int64]*[]int64{
+		1: &[]int64{3, 1},
+		2: &[]int64{2, 3},
+	})
+	defer func() { notificationStats = getEcosystemNotificationStats }()
+
+	batch := NewBatch()
+	batch.Add(2)
+	batch.Add(1)
+	assert.Empty(t, client.published, "nothing is published before the commit")
+
+	batch.Send()
+	assert.Equal(t, 1, client.sends)
+	assert.Equal(t, []publishedMessage{
+		{"client1", `[{"ecosystem":1,"role_id":1,"count":1}]`},
+		{"client2", `[{"ecosystem":2,"role_id":1,"count":2}]`},
+		{"client3", `[{"ecosystem":1,"role_id":1,"count":3},{"ecosystem":2,"role_id":1,"count":3}]`},
+	}, client.published)
+
+	// the stats are not changed
+	batch.Add(1)
+	batch.Send()
+	assert.Equal(t, 1, client.sends)
+}
+
+func TestBatchDiscard(t *testing.T) {
+	client := setupBatchTest(map[int64]*[]int64{
+		1: &[]int64{1},
+	})
+	defer func() { notificationStats = getEcosystemNotificationStats }()
+
+	batch := NewBatch()
+	batch.Add(1)
+	batch.Discard()
+	batch.Send()
+	assert.Equal(t, 0, client.sends)
+	assert.Empty(t, client.published)
+}
+
+func TestBatchEvents(t *testing.T) {
+	client := setupBatchTest(map[int64]*[]int64{})
+	defer func() {
+		notificationStats = getEcosystemNotificationStats
+		blockEvents = getBlockEvents
+	}()
+	blockEvents = func(ecosystemID, blockID int64) ([]model.Event, error) {
+		return []model.Event{{ID: blockID, BlockID: blockID, TxHash: "ab", Contract: "@1Test",
+			Name: fmt.Sprint("Event", ecosystemID), Data: `{"a":1}`}}, nil
+	}
+
+	batch := NewBatch()
+	batch.AddEvents(2, 5)
+	batch.AddEvents(1, 5)
+	batch.AddEvents(1, 5)
+	batch.Discard()
+	batch.Send()
+	assert.Empty(t, client.published)
+
+	batch.AddEvents(2, 5)
+	batch.AddEvents(1, 5)
+	batch.Send()
+	assert.Equal(t, 1, client.sends)
+	assert.Equal(t, []publishedMessage{
+		{"events1", `{"id":"5","block_id":"5","tx_hash":"ab","contract":"@1Test","name":"Event1","data":{"a":1}}`},
+		{"events2", `{"id":"5","block_id":"5","tx_hash":"ab","contract":"@1Test","name":"Event2","data":{"a":1}}`},
+	}, client.published)
+}
+
+func TestBatchCache(t *testing.T) {
+	client := setupBatchTest(map[int64]*[]int64{})
+	defer func() {
+		notificationStats = getEcosystemNotificationStats
+		blockRollbacks = getBlockRollbacks
+		objectNames = model.GetNamesByIDs
+	}()
+	blockRollbacks = func(blockID int64) ([]model.RollbackTx, error) {
+		return []model.RollbackTx{
+			{BlockID: blockID, NameTable: "1_pages", TableID: "5", Data: `{"value":"old"}`},
+			{BlockID: blockID, NameTable: "1_keys", TableID: "5", Data: `{"amount":"1"}`},
+			{BlockID: blockID, NameTable: "1_pages", TableID: "5", Data: `{"value":"older"}`},
+		}, nil
+	}
+	objectNames = func(table string, ids []int64) (map[int64]string, error) {
+		assert.Equal(t, "1_pages", table)
+		return map[int64]string{5: "main"}, nil
+	}
+
+	batch := NewBatch()
+	batch.AddBlock(7)
+	batch.Discard()
+	batch.Send()
+	assert.Empty(t, client.published)
+
+	batch.AddBlock(7)
+	batch.Send()
+	assert.Equal(t, 1, client.sends)
+	assert.Equal(t, []publishedMessage{
+		{"cache1", `{"block_id":"7","changes":{"pages":["main"]}}`},
+	}, client.published)
+}
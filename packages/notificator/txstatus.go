@@ -0,0 +1,54 @@
+package notificator
+
+import (
+	"sync"
+)
+
+// txWaiters are the channels of the subscribers by the hashes of the transactions
+var txWaiters = struct {
+	sync.Mutex
+	list map[string][]chan struct{}
+}{list: make(map[string][]chan struct{})}
+
+// SubscribeTxStatus returns the channel which receives the signal when the status of the transaction
+// is changed and the function which removes the subscription. The signals aren't queued, so the
+// subscriber has to read the status again after the signal
+func SubscribeTxStatus(hash []byte) (<-chan struct{}, func()) {
+	key := string(hash)
+	ch := make(chan struct{}, 1)
+	txWaiters.Lock()
+	txWaiters.list[key] = append(txWaiters.list[key], ch)
+	txWaiters.Unlock()
+
+	return ch, func() {
+		txWaiters.Lock()
+		defer txWaiters.Unlock()
+		list := txWaiters.list[key]
+		for i, item := range list {
+			if item == ch {
+				list = append(list[:i], list[i+1:]...)
+				break
+			}
+		}
+		if len(list) == 0 {
+			delete(txWaiters.list, key)
+		} else {
+			txWaiters.list[key] = list
+		}
+	}
+}
+
+// PublishTxStatus signals the subscribers of the transactions that their statuses have been changed.
+// It's called after the changes are committed
+func PublishTxStatus(hashes ...[]byte) {
+	txWaiters.Lock()
+	defer txWaiters.Unlock()
+	for _, hash := range hashes {
+		for _, ch := range txWaiters.list[string(hash)] {
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package notificator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxStatus(t *testing.T) {
+	hash := []byte{1, 2, 3}
+	changed, cancel := SubscribeTxStatus(hash)
+	other, cancelOther := SubscribeTxStatus(hash)
+	defer cancelOther()
+
+	// the changes are published after the commit only
+	batch := NewBatch()
+	batch.AddTxStatus(hash)
+	batch.Discard()
+	batch.Send()
+	assert.Len(t, changed, 0)
+
+	batch.AddTxStatus(hash)
+	batch.AddTxStatus([]byte{4})
+	batch.Send()
+	assert.Len(t, changed, 1)
+	assert.Len(t, other, 1)
+
+	// the signals aren't queued
+	PublishTxStatus(hash)
+	assert.Len(t, changed, 1)
+	<-changed
+
+	cancel()
+	PublishTxStatus(hash)
+	assert.Len(t, changed, 0)
+	assert.Len(t, txWaiters.list[string(hash)], 1)
+	cancelOther()
+	assert.NotContains(t, txWaiters.list, string(hash))
+}
@@ -3,6 +3,7 @@ package publisher
 import (
 	"encoding/hex"
 	"fmt"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -31,10 +32,20 @@ func (cn *ClientsChannels) Get(id int64) string {
 	return cn.storage[id]
 }
 
+// Client is the interface of centrifugo client which is used for publishing
+type Client interface {
+	Publish(channel string, data []byte) (bool, error)
+	AddPublish(channel string, data []byte) error
+	Send() (gocent.Result, error)
+	Reset()
+}
+
 var (
 	clientsChannels   = ClientsChannels{storage: make(map[int64]string)}
 	centrifugoTimeout = time.Second * 5
 	publisher         *gocent.Client
+	client            Client
+	clientMutex       sync.Mutex
 	config            conf.CentrifugoConfig
 )
 
@@ -42,6 +53,18 @@ var (
 func InitCentrifugo(cfg conf.CentrifugoConfig) {
 	config = cfg
 	publisher = gocent.NewClient(cfg.URL, cfg.Secret, centrifugoTimeout)
+	SetClient(publisher)
+}
+
+// SetClient replaces the client which is used for publishing
+func SetClient(c Client) {
+	clientMutex.Lock()
+	defer clientMutex.Unlock()
+	client = c
+}
+
+func channelName(userID int64) string {
+	return "client" + strconv.FormatInt(userID, 10)
 }
 
 func GetHMACSign(userID int64) (string, string, error) {
@@ -60,7 +83,90 @@ func GetHMACSign(userID int64) (string, string, error) {
 
 // Write is publishing data to server
 func Write(userID int64, data string) (bool, error) {
-	return publisher.Publish("client"+strconv.FormatInt(userID, 10), []byte(data))
+	clientMutex.Lock()
+	defer clientMutex.Unlock()
+
+	if client == nil {
+		return false, fmt.Errorf("publisher not initialized")
+	}
+	return client.Publish(channelName(userID), []byte(data))
+}
+
+// WriteBatch is publishing data to server by one request, one message per channel in order of user ids
+func WriteBatch(messages map[int64]string) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	clientMutex.Lock()
+	defer clientMutex.Unlock()
+
+	if client == nil {
+		return fmt.Errorf("publisher not initialized")
+	}
+
+	users := make([]int64, 0, len(messages))
+	for userID := range messages {
+		users = append(users, userID)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i] < users[j] })
+
+	client.Reset()
+	for _, userID := range users {
+		if err := client.AddPublish(channelName(userID), []byte(messages[userID])); err != nil {
+			client.Reset()
+			return err
+		}
+	}
+
+	_, err := client.Send()
+	return err
+}
+
+// WriteEvents publishes the events of the ecosystems by one request, the events are published
+// to the channel of the ecosystem in the order of emission
+func WriteEvents(events map[int64][]string) error {
+	return writeEcosystems("events", events)
+}
+
+// WriteCache publishes the messages about the changed pages, menus, parameters and languages
+// to the cache channels of the ecosystems by one request
+func WriteCache(messages map[int64][]string) error {
+	return writeEcosystems("cache", messages)
+}
+
+// writeEcosystems publishes the messages to the channels of the ecosystems with the prefix
+func writeEcosystems(prefix string, events map[int64][]string) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	clientMutex.Lock()
+	defer clientMutex.Unlock()
+
+	if client == nil {
+		return fmt.Errorf("publisher not initialized")
+	}
+
+	ecosystems := make([]int64, 0, len(events))
+	for ecosystemID := range events {
+		ecosystems = append(ecosystems, ecosystemID)
+	}
+	sort.Slice(ecosystems, func(i, j int) bool { return ecosystems[i] < ecosystems[j] })
+
+	client.Reset()
+	for _, ecosystemID := range ecosystems {
+		channel := prefix + strconv.FormatInt(ecosystemID, 10)
+		for _, event := range events[ecosystemID] {
+			if err := client.AddPublish(channel, []byte(event)); err != nil {
+				client.Reset()
+				return err
+			}
+		}
+	}
+
+	_, err := client.Send()
+	return err
 }
 
 // GetStats returns Stats
@@ -32,6 +32,23 @@ import (
 
 // BlockRollback is blocking rollback
 func RollbackBlock(data []byte, deleteBlock bool) error {
+	dbTransaction, err := model.StartTransaction()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("starting transaction")
+		return err
+	}
+
+	if err = RollbackBlockTx(dbTransaction, data, deleteBlock); err != nil {
+		dbTransaction.Rollback()
+		return err
+	}
+
+	err = dbTransaction.Commit()
+	return err
+}
+
+// RollbackBlockTx rollbacks the block inside of the specified db transaction
+func RollbackBlockTx(dbTransaction *model.DbTransaction, data []byte, deleteBlock bool) error {
 	buf := bytes.NewBuffer(data)
 	if buf.Len() == 0 {
 		log.WithFields(log.Fields{"type": consts.EmptyObject}).Error("empty buffer")
@@ -43,16 +60,12 @@ func RollbackBlock(data []byte, deleteBlock bool) error {
 		return err
 	}
 
-	dbTransaction, err := model.StartTransaction()
-	if err != nil {
-		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("starting transaction")
+	if err = rollbackBlock(dbTransaction, block); err != nil {
 		return err
 	}
 
-	err = rollbackBlock(dbTransaction, block)
-
-	if err != nil {
-		dbTransaction.Rollback()
+	if err = model.RollbackRowHistory(dbTransaction, block.Header.BlockID); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting row history")
 		return err
 	}
 
@@ -61,13 +74,10 @@ func RollbackBlock(data []byte, deleteBlock bool) error {
 		err = b.DeleteById(dbTransaction, block.Header.BlockID)
 		if err != nil {
 			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting block by id")
-			dbTransaction.Rollback()
 			return err
 		}
 	}
-
-	err = dbTransaction.Commit()
-	return err
+	return nil
 }
 
 func rollbackBlock(dbTransaction *model.DbTransaction, block *block.Block) error {
@@ -18,6 +18,7 @@ package rollback
 
 import (
 	"bytes"
+	"fmt"
 	"strconv"
 
 	"github.com/GenesisKernel/go-genesis/packages/consts"
@@ -57,8 +58,69 @@ func ToBlockID(blockID int64, dbTransaction *model.DbTransaction, logger *log.En
 		}
 		blocks = blocks[:0]
 	}
+	return updateInfoBlock(blockID, dbTransaction, logger)
+}
+
+// ProgressFunc is called after the block has been rolled back, blockID is the new last block
+type ProgressFunc func(blockID int64)
+
+// Blocks rollbacks the last blocks one by one till blockID. Every block is rolled back together with
+// the info block in its own db transaction, so the chain stays consistent if the rollback fails.
+// It returns the id of the last block which has been reached
+func Blocks(blockID int64, progress ProgressFunc, logger *log.Entry) (int64, error) {
+	if _, err := model.MarkVerifiedAndNotUsedTransactionsUnverified(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("marking verified and not used transactions unverified")
+		return 0, err
+	}
+	last := &model.Block{}
+	if _, err := last.GetMaxBlock(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting max block")
+		return 0, err
+	}
+	current := last.ID
+	for current > blockID {
+		block := &model.Block{}
+		found, err := block.Get(current)
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": current}).Error("getting block")
+			return current, err
+		}
+		if !found {
+			logger.WithFields(log.Fields{"type": consts.NotFound, "block_id": current}).Error("block not found")
+			return current, fmt.Errorf("block %d isn't found", current)
+		}
+		if err = rollbackLastBlock(block.Data, current-1, logger); err != nil {
+			return current, err
+		}
+		current--
+		if progress != nil {
+			progress(current)
+		}
+	}
+	return current, nil
+}
+
+// rollbackLastBlock rollbacks the last block and makes prevID the last block
+func rollbackLastBlock(data []byte, prevID int64, logger *log.Entry) error {
+	dbTransaction, err := model.StartTransaction()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("starting transaction")
+		return err
+	}
+	if err = RollbackBlockTx(dbTransaction, data, true); err == nil {
+		err = updateInfoBlock(prevID, dbTransaction, logger)
+	}
+	if err != nil {
+		dbTransaction.Rollback()
+		return err
+	}
+	return dbTransaction.Commit()
+}
+
+// updateInfoBlock writes the header of the block blockID to the info block
+func updateInfoBlock(blockID int64, dbTransaction *model.DbTransaction, logger *log.Entry) error {
 	block := &model.Block{}
-	_, err = block.Get(blockID)
+	_, err := block.Get(blockID)
 	if err != nil {
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting block")
 		return err
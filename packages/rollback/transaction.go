@@ -17,7 +17,6 @@
 package rollback
 
 import (
-	"encoding/json"
 	"strings"
 
 	"github.com/GenesisKernel/go-genesis/packages/consts"
@@ -29,11 +28,15 @@ import (
 )
 
 func rollbackUpdatedRow(tx map[string]string, where string, dbTransaction *model.DbTransaction, logger *log.Entry) error {
-	var rollbackInfo map[string]string
-	if err := json.Unmarshal([]byte(tx["data"]), &rollbackInfo); err != nil {
+	rollbackInfo, err := model.UnmarshalRollbackData(tx["data"])
+	if err != nil {
 		logger.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling rollback.Data from json")
 		return err
 	}
+	// the update hasn't changed any column
+	if len(rollbackInfo) == 0 {
+		return nil
+	}
 	addSQLUpdate := ""
 	for k, v := range rollbackInfo {
 		if v == "NULL" {
@@ -69,9 +72,8 @@ func rollbackTransaction(txHash []byte, dbTransaction *model.DbTransaction, logg
 	}
 	for _, tx := range txs {
 		if tx["table_name"] == `@system` {
-			var v map[string]string
-
-			err := json.Unmarshal([]byte(tx["data"]), &v)
+			// the records which have been written before the canonical encoding are decoded too
+			v, err := converter.DecodeJSONMap([]byte(tx["data"]))
 			if err != nil {
 				logger.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling rollback.Data from json")
 				return err
@@ -82,8 +84,15 @@ func rollbackTransaction(txHash []byte, dbTransaction *model.DbTransaction, logg
 			case "NewColumn":
 				smart.SysRollbackColumn(dbTransaction, txHash, v["TableName"], v["Name"],
 					tx["table_id"])
+			case "AlterColumnType":
+				if err := smart.SysRollbackColumnType(dbTransaction, v["TableName"], v["Name"], v["OldType"],
+					v["Values"]); err != nil {
+					return err
+				}
 			case "NewContract":
 				smart.SysRollbackNewContract(v["Value"], tx["table_id"])
+			case "NewContracts":
+				smart.SysRollbackNewContracts(v["From"], v["Names"])
 			case "EditContract":
 				smart.SysRollbackEditContract(dbTransaction, txHash, tx["table_id"])
 			case "NewEcosystem":
@@ -92,6 +101,10 @@ func rollbackTransaction(txHash []byte, dbTransaction *model.DbTransaction, logg
 				smart.SysRollbackActivate(v["Id"], v["State"])
 			case "DeactivateContract":
 				smart.SysRollbackDeactivate(v["Id"], v["State"])
+			case "PartialIndexes":
+				if err := smart.SysRollbackPartialIndexes(dbTransaction, v["TableName"], v["Indexes"]); err != nil {
+					return err
+				}
 			}
 			continue
 		}
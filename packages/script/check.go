@@ -0,0 +1,100 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package script
+
+// Call is the call of the function or the contract in the source code
+type Call struct {
+	Name   string
+	Line   uint32
+	Column uint32
+}
+
+// SourceCalls returns the calls of the source code and the names of the contracts and the functions
+// which are declared in it. The method calls of the tails like .Columns() aren't returned
+func SourceCalls(input []rune) (calls []Call, declared []string, err error) {
+	lexems, err := lexParser(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, lexem := range lexems {
+		if lexem.Type != lexIdent || i == 0 {
+			continue
+		}
+		prev := lexems[i-1].Type
+		switch {
+		case prev == lexKeyword|(keyContract<<8) || prev == lexKeyword|(keyFunc<<8):
+			declared = append(declared, lexem.Value.(string))
+		case prev == isDot:
+		case i < len(lexems)-1 && lexems[i+1].Type == isLPar:
+			calls = append(calls, Call{Name: lexem.Value.(string), Line: lexem.Line, Column: lexem.Column})
+		}
+	}
+	return calls, declared, nil
+}
+
+// CallStrings returns the string constants which are passed as the parameters to the calls of the function
+// or the contract. The parameters which are calculated at runtime aren't returned
+func CallStrings(input []rune, name string) ([]string, error) {
+	lexems, err := lexParser(input)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for i := 0; i < len(lexems)-1; i++ {
+		if lexems[i].Type != lexIdent || lexems[i].Value.(string) != name || lexems[i+1].Type != isLPar {
+			continue
+		}
+		depth := 0
+		for i++; i < len(lexems); i++ {
+			switch lexems[i].Type {
+			case isLPar:
+				depth++
+			case isRPar:
+				depth--
+			case lexString:
+				if depth == 1 && i+1 < len(lexems) && (lexems[i+1].Type == isComma || lexems[i+1].Type == isRPar) &&
+					(lexems[i-1].Type == isComma || lexems[i-1].Type == isLPar) {
+					out = append(out, lexems[i].Value.(string))
+				}
+			}
+			if depth == 0 {
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// CallFirstStrings returns the first parameters of the calls of the function which are the string
+// constants. The parameters which are calculated at runtime are skipped
+func CallFirstStrings(input []rune, name string) ([]string, error) {
+	lexems, err := lexParser(input)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for i := 0; i < len(lexems)-3; i++ {
+		if lexems[i].Type != lexIdent || lexems[i].Value.(string) != name || lexems[i+1].Type != isLPar ||
+			(i > 0 && lexems[i-1].Type == isDot) {
+			continue
+		}
+		if lexems[i+2].Type == lexString && (lexems[i+3].Type == isComma || lexems[i+3].Type == isRPar) {
+			out = append(out, lexems[i+2].Value.(string))
+		}
+	}
+	return out, nil
+}
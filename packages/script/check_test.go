@@ -0,0 +1,62 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package script
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceCalls(t *testing.T) {
+	calls, declared, err := SourceCalls([]rune(`contract Test {
+	func helper(s string) string {
+		return Sprintf("%s!", s)
+	}
+	action {
+		var list array
+		list = DBFind("keys").Columns("id").Limit(1)
+		MyContract("Name", helper("a"))
+	}
+}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Test", "helper", "action"}, declared)
+	assert.Equal(t, []Call{{"Sprintf", 3, 11}, {"DBFind", 7, 11}, {"MyContract", 8, 4}, {"helper", 8, 23}}, calls)
+
+	_, _, err = SourceCalls([]rune(`contract Test { action { elif } }`))
+	assert.EqualError(t, err, `elif without if [Ln:1 Col:26]`)
+}
+
+func TestCallStrings(t *testing.T) {
+	list, err := CallStrings([]rune(`ContractAccess("@1EditPage", "NewPage") || ContractAccess($name, "A" + "B", "@app:2") && Size("x") > 0`),
+		"ContractAccess")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"@1EditPage", "NewPage", "@app:2"}, list)
+}
+
+func TestCallFirstStrings(t *testing.T) {
+	list, err := CallFirstStrings([]rune(`contract Test { action {
+		DBInsert("items", "name", "x")
+		DBInsert($table, "name", "y")
+		DBInsert("a" + "b", "name", "z")
+		DBFind("items").Where("id=1")
+		DBInsert("@1keys", "id", 1)
+	}}`), "DBInsert")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"items", "@1keys"}, list)
+}
@@ -40,6 +40,9 @@ const (
 	cmdFuncName              // set func name Func(...).Name(...)
 	cmdUnwrapArr             // unwrap array to stack
 	cmdError                 // error command
+	cmdSwitch                // run block of the case with the matching value
+	cmdArrayInit             // create array from the values on the stack
+	cmdMapInit               // create map from the pairs of keys and values on the stack
 )
 
 // the commands for operations in expressions are listed below
@@ -23,6 +23,7 @@ import (
 
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 
+	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -88,7 +89,15 @@ const (
 	stateConsts
 	stateConstsAssign
 	stateConstsValue
+	stateConstant
+	stateConstantName
+	stateConstantType
+	stateConstantValue
+	statePrivate
 	stateFields
+	stateSwitch
+	stateCase
+	stateCaseNext
 	stateEval
 
 	// The list of state flags
@@ -101,6 +110,7 @@ const (
 	stateToFork   = 0x4000
 	stateLabel    = 0x8000
 	stateMustEval = 0x010000
+	stateToSwitch = 0x020000
 
 	flushMark = 0x100000
 )
@@ -117,6 +127,7 @@ const (
 	errVarType               // must be type
 	errAssign                // must be '='
 	errStrNum                // must be number or string
+	errMustFunc              // must be func
 )
 
 const (
@@ -140,6 +151,11 @@ const (
 	cfSettings
 	cfConstName
 	cfConstValue
+	cfConstant
+	cfConstantName
+	cfConstantType
+	cfConstantValue
+	cfPrivate
 	cfField
 	cfFieldType
 	cfFieldTag
@@ -147,11 +163,17 @@ const (
 	cfContinue
 	cfBreak
 	cfCmdError
+	cfSwitch
+	cfCase
+	cfCaseValue
+	cfDefault
 
 //	cfEval
 )
 
 var (
+	// entryFuncs are the functions of the contract which are called by the platform
+	entryFuncs = map[string]struct{}{`init`: {}, `conditions`: {}, `action`: {}, `price`: {}, `upgrade`: {}}
 	// Array of operations and their priority
 	opers = map[uint32]operPrior{
 		isOr: {cmdOr, 10}, isAnd: {cmdAnd, 15}, isEqEq: {cmdEqual, 20}, isNotEq: {cmdNotEq, 20},
@@ -177,6 +199,11 @@ var (
 		fSettings,
 		fConstName,
 		fConstValue,
+		fConstant,
+		fConstantName,
+		fConstantType,
+		fConstantValue,
+		fPrivate,
 		fField,
 		fFieldType,
 		fFieldTag,
@@ -184,6 +211,10 @@ var (
 		fContinue,
 		fBreak,
 		fCmdError,
+		fSwitch,
+		fCase,
+		fCaseValue,
+		fDefault,
 	}
 
 	// 'states' describes a finite machine with states on the base of which a bytecode will be generated
@@ -192,20 +223,24 @@ var (
 			lexNewLine:                      {stateRoot, 0},
 			lexKeyword | (keyContract << 8): {stateContract | statePush, 0},
 			lexKeyword | (keyFunc << 8):     {stateFunc | statePush, 0},
+			lexKeyword | (keyPrivate << 8):  {statePrivate | statePush, cfPrivate},
 			0: {errUnknownCmd, cfError},
 		},
 		{ // stateBody
 			lexNewLine:                      {stateBody, 0},
 			lexKeyword | (keyFunc << 8):     {stateFunc | statePush, 0},
+			lexKeyword | (keyPrivate << 8):  {statePrivate | statePush, cfPrivate},
 			lexKeyword | (keyReturn << 8):   {stateEval, cfReturn},
 			lexKeyword | (keyContinue << 8): {stateBody, cfContinue},
 			lexKeyword | (keyBreak << 8):    {stateBody, cfBreak},
 			lexKeyword | (keyIf << 8):       {stateEval | statePush | stateToBlock | stateMustEval, cfIf},
 			lexKeyword | (keyWhile << 8):    {stateEval | statePush | stateToBlock | stateLabel | stateMustEval, cfWhile},
+			lexKeyword | (keySwitch << 8):   {stateEval | stateToSwitch | stateMustEval, cfSwitch},
 			lexKeyword | (keyElse << 8):     {stateBlock | statePush, cfElse},
 			lexKeyword | (keyVar << 8):      {stateVar, 0},
 			lexKeyword | (keyTX << 8):       {stateTX, cfTX},
 			lexKeyword | (keySettings << 8): {stateSettings, cfSettings},
+			lexKeyword | (keyConst << 8):    {stateConstant, cfConstant},
 			lexKeyword | (keyError << 8):    {stateEval, cfCmdError},
 			lexKeyword | (keyWarning << 8):  {stateEval, cfCmdError},
 			lexKeyword | (keyInfo << 8):     {stateEval, cfCmdError},
@@ -318,6 +353,32 @@ var (
 			lexNumber: {stateConsts, cfConstValue},
 			0:         {errStrNum, cfError},
 		},
+		{ // stateConstant
+			lexNewLine: {stateConstant, 0},
+			isLCurly:   {stateConstantName, 0},
+			0:          {errMustLCurly, cfError},
+		},
+		{ // stateConstantName
+			lexNewLine: {stateConstantName, 0},
+			isComma:    {stateConstantName, 0},
+			lexIdent:   {stateConstantType, cfConstantName},
+			isRCurly:   {stateToBody, 0},
+			0:          {errMustRCurly, cfError},
+		},
+		{ // stateConstantType
+			lexType: {stateConstantType, cfConstantType},
+			isEq:    {stateConstantValue, 0},
+			0:       {errAssign, cfError},
+		},
+		{ // stateConstantValue
+			lexString: {stateConstantName, cfConstantValue},
+			lexNumber: {stateConstantName, cfConstantValue},
+			0:         {errStrNum, cfError},
+		},
+		{ // statePrivate
+			lexKeyword | (keyFunc << 8): {stateFunc, 0},
+			0:                           {errMustFunc, cfError},
+		},
 		{ // stateFields
 			lexNewLine: {stateFields, 0},
 			isComma:    {stateFields, 0},
@@ -327,6 +388,23 @@ var (
 			isRCurly:   {stateToBody, 0},
 			0:          {errMustRCurly, cfError},
 		},
+		{ // stateSwitch
+			lexNewLine:                     {stateSwitch, 0},
+			lexKeyword | (keyCase << 8):    {stateCase | statePush, cfCase},
+			lexKeyword | (keyDefault << 8): {stateBlock | statePush, cfDefault},
+			0:                              {stateBody | stateStay, 0},
+		},
+		{ // stateCase
+			lexNumber: {stateCaseNext, cfCaseValue},
+			lexString: {stateCaseNext, cfCaseValue},
+			0:         {errStrNum, cfError},
+		},
+		{ // stateCaseNext
+			lexNewLine: {stateCaseNext, 0},
+			isComma:    {stateCase, 0},
+			isLCurly:   {stateBody, 0},
+			0:          {errMustLCurly, cfError},
+		},
 	}
 )
 
@@ -341,8 +419,8 @@ func fError(buf *[]*Block, state int, lexem *Lexem) error {
 		`must be type`,             // errVarType
 		`must be '='`,              // errAssign
 		`must be number or string`, // errStrNum
+		`must be func`,             // errMustFunc
 	}
-	fmt.Printf("%s %x %v [Ln:%d Col:%d]\r\n", errors[state], lexem.Type, lexem.Value, lexem.Line, lexem.Column)
 	logger := lexem.GetLogger()
 	if lexem.Type == lexNewLine {
 		logger.WithFields(log.Fields{"error": errors[state], "lex_value": lexem.Value, "type": consts.ParseError}).Error("unexpected new line")
@@ -370,6 +448,10 @@ func fCmdError(buf *[]*Block, state int, lexem *Lexem) error {
 
 func fFparam(buf *[]*Block, state int, lexem *Lexem) error {
 	block := (*buf)[len(*buf)-1]
+	if objInfo, _ := findVar(lexem.Value.(string), buf); objInfo != nil && objInfo.Type == ObjConst {
+		lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError, "lex_value": lexem.Value.(string)}).Error("constant is already declared")
+		return fmt.Errorf(eConstDuplicate, lexem.Value.(string), lexem.Line, lexem.Column)
+	}
 	if block.Type == ObjFunc && (state == stateFParam || state == stateFParamTYPE) {
 		fblock := block.Info.(*FuncInfo)
 		if fblock.Names == nil {
@@ -498,13 +580,119 @@ func fWhile(buf *[]*Block, state int, lexem *Lexem) error {
 	return nil
 }
 
-func fContinue(buf *[]*Block, state int, lexem *Lexem) error {
-	(*(*buf)[len(*buf)-1]).Code = append((*(*buf)[len(*buf)-1]).Code, &ByteCode{cmdContinue, 0})
+// inLoop returns true if the block is the body of while or it is nested into the body
+// within the same function
+func inLoop(block *Block) bool {
+	for ; block.Parent != nil && block.Type != ObjFunc && block.Type != ObjContract; block = block.Parent {
+		for _, cmd := range block.Parent.Code {
+			if cmd.Cmd == cmdWhile && cmd.Value == block {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func fLoopJump(buf *[]*Block, lexem *Lexem, cmd uint16, name string) error {
+	block := (*buf)[len(*buf)-1]
+	if !inLoop(block) {
+		lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError, "lex_value": name}).Error("jump outside of loop")
+		return fmt.Errorf(eOutsideLoop, name, lexem.Line, lexem.Column)
+	}
+	block.Code = append(block.Code, &ByteCode{cmd, 0})
 	return nil
 }
 
+func fContinue(buf *[]*Block, state int, lexem *Lexem) error {
+	return fLoopJump(buf, lexem, cmdContinue, `continue`)
+}
+
 func fBreak(buf *[]*Block, state int, lexem *Lexem) error {
-	(*(*buf)[len(*buf)-1]).Code = append((*(*buf)[len(*buf)-1]).Code, &ByteCode{cmdBreak, 0})
+	return fLoopJump(buf, lexem, cmdBreak, `break`)
+}
+
+// evalType returns the type of the expression if it's known at the compile time.
+// The last command of the compiled expression is the one which produces the result
+func evalType(block *Block) reflect.Type {
+	if len(block.Code) == 0 {
+		return nil
+	}
+	cmd := block.Code[len(block.Code)-1]
+	switch cmd.Cmd {
+	case cmdPush:
+		return reflect.TypeOf(cmd.Value)
+	case cmdVar:
+		vinfo := cmd.Value.(*VarInfo)
+		return vinfo.Owner.Vars[vinfo.Obj.Value.(int)]
+	case cmdCall:
+		var results []reflect.Type
+		switch obj := cmd.Value.(*ObjInfo); obj.Type {
+		case ObjFunc:
+			results = obj.Value.(*Block).Info.(*FuncInfo).Results
+		case ObjExtFunc:
+			results = obj.Value.(ExtFuncInfo).Results
+		}
+		if len(results) == 1 {
+			return results[0]
+		}
+	}
+	return nil
+}
+
+func fSwitch(buf *[]*Block, state int, lexem *Lexem) error {
+	block := (*buf)[len(*buf)-1]
+	info := &SwitchInfo{Cases: make(map[interface{}]*Block)}
+	if vtype := evalType(block); vtype == reflect.TypeOf(int64(0)) || vtype == reflect.TypeOf(``) {
+		info.Type = vtype
+	} else if vtype != nil && vtype.Kind() != reflect.Interface {
+		lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError, "switch_type": vtype.String()}).Error("wrong type of switch")
+		return fmt.Errorf(eSwitchType, vtype.String(), lexem.Line, lexem.Column)
+	}
+	block.Code = append(block.Code, &ByteCode{cmdSwitch, info})
+	return nil
+}
+
+// switchInfo returns the switch which the case block belongs to
+func switchInfo(buf *[]*Block) *SwitchInfo {
+	parent := (*buf)[len(*buf)-2]
+	return parent.Code[len(parent.Code)-1].Value.(*SwitchInfo)
+}
+
+func fCase(buf *[]*Block, state int, lexem *Lexem) error {
+	switchInfo(buf).current = (*buf)[len(*buf)-1]
+	return nil
+}
+
+func fCaseValue(buf *[]*Block, state int, lexem *Lexem) error {
+	info := switchInfo(buf)
+	logger := lexem.GetLogger()
+	value := lexem.Value
+	vtype := reflect.TypeOf(value)
+	if vtype != reflect.TypeOf(int64(0)) && vtype != reflect.TypeOf(``) {
+		logger.WithFields(log.Fields{"type": consts.ParseError, "lex_value": value}).Error("wrong type of case value")
+		return fmt.Errorf(eCaseValue, value, lexem.Line, lexem.Column)
+	}
+	if info.Type == nil {
+		info.Type = vtype
+	} else if info.Type != vtype {
+		logger.WithFields(log.Fields{"type": consts.ParseError, "lex_value": value, "switch_type": info.Type.String()}).Error("mismatched type of case value")
+		return fmt.Errorf(eCaseType, value, info.Type.String(), lexem.Line, lexem.Column)
+	}
+	if _, ok := info.Cases[value]; ok {
+		logger.WithFields(log.Fields{"type": consts.ParseError, "lex_value": value}).Error("duplicate case value")
+		return fmt.Errorf(eCaseDuplicate, value, lexem.Line, lexem.Column)
+	}
+	info.Cases[value] = info.current
+	return nil
+}
+
+func fDefault(buf *[]*Block, state int, lexem *Lexem) error {
+	info := switchInfo(buf)
+	if info.Default != nil {
+		lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError}).Error("duplicate default")
+		return fmt.Errorf(eDefaultDuplicate, lexem.Line, lexem.Column)
+	}
+	info.Default = (*buf)[len(*buf)-1]
 	return nil
 }
 
@@ -522,6 +710,10 @@ func fAssignVar(buf *[]*Block, state int, lexem *Lexem) error {
 		ivar = VarInfo{&ObjInfo{ObjExtend, lexem.Value.(string)}, nil}
 	} else {
 		objInfo, tobj := findVar(lexem.Value.(string), buf)
+		if objInfo != nil && objInfo.Type == ObjConst {
+			lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError, "lex_value": lexem.Value.(string)}).Error("modifying constant")
+			return fmt.Errorf(eConstAssign, lexem.Value.(string), lexem.Line, lexem.Column)
+		}
 		if objInfo == nil || objInfo.Type != ObjVar {
 			logger := lexem.GetLogger()
 			logger.WithFields(log.Fields{"type": consts.ParseError, "lex_value": lexem.Value.(string)}).Error("unknown variable")
@@ -587,6 +779,95 @@ func fConstValue(buf *[]*Block, state int, lexem *Lexem) error {
 	return nil
 }
 
+func fConstant(buf *[]*Block, state int, lexem *Lexem) error {
+	contract := (*buf)[len(*buf)-1]
+	if contract.Type != ObjContract {
+		logger := lexem.GetLogger()
+		logger.WithFields(log.Fields{"type": consts.ParseError, "contract_type": contract.Type, "lex_value": lexem.Value}).Error("const can only be in contract")
+		return fmt.Errorf(`const can only be in contract`)
+	}
+	if contract.Objects == nil {
+		contract.Objects = make(map[string]*ObjInfo)
+	}
+	return nil
+}
+
+// pendingConst returns the constant which value hasn't been assigned yet
+func pendingConst(block *Block) (string, *ConstInfo) {
+	for name, obj := range block.Objects {
+		if obj.Type == ObjConst && obj.Value.(*ConstInfo).Value == nil {
+			return name, obj.Value.(*ConstInfo)
+		}
+	}
+	return ``, nil
+}
+
+func fConstantName(buf *[]*Block, state int, lexem *Lexem) error {
+	contract := (*buf)[len(*buf)-1]
+	name := lexem.Value.(string)
+	if _, ok := contract.Objects[name]; ok {
+		lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError, "lex_value": name}).Error("constant is already declared")
+		return fmt.Errorf(eConstDuplicate, name, lexem.Line, lexem.Column)
+	}
+	contract.Objects[name] = &ObjInfo{Type: ObjConst, Value: &ConstInfo{}}
+	return nil
+}
+
+func fConstantType(buf *[]*Block, state int, lexem *Lexem) error {
+	name, cinfo := pendingConst((*buf)[len(*buf)-1])
+	ctype := lexem.Value.(reflect.Type)
+	if cinfo.Type != nil || (ctype != reflect.TypeOf(``) && ctype != reflect.TypeOf(int64(0)) &&
+		ctype.String() != Decimal) {
+		lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError, "lex_value": name}).Error("wrong type of constant")
+		return fmt.Errorf(eConstType, name, lexem.Line, lexem.Column)
+	}
+	cinfo.Type = ctype
+	return nil
+}
+
+func fConstantValue(buf *[]*Block, state int, lexem *Lexem) error {
+	var err error
+	name, cinfo := pendingConst((*buf)[len(*buf)-1])
+	value := lexem.Value
+	switch {
+	case cinfo.Type == nil:
+		if _, ok := value.(float64); ok {
+			err = fmt.Errorf(eConstType, name, lexem.Line, lexem.Column)
+		}
+	case cinfo.Type.String() == Decimal:
+		switch v := value.(type) {
+		case int64:
+			value = decimal.New(v, 0)
+		case string:
+			if value, err = decimal.NewFromString(v); err != nil {
+				err = fmt.Errorf(eConstValue, name, lexem.Line, lexem.Column)
+			}
+		default:
+			err = fmt.Errorf(eConstValue, name, lexem.Line, lexem.Column)
+		}
+	case reflect.TypeOf(value) != cinfo.Type:
+		err = fmt.Errorf(eConstValue, name, lexem.Line, lexem.Column)
+	}
+	if err != nil {
+		lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError, "lex_value": value}).Error("wrong value of constant")
+		return err
+	}
+	cinfo.Type = reflect.TypeOf(value)
+	cinfo.Value = value
+	return nil
+}
+
+func fPrivate(buf *[]*Block, state int, lexem *Lexem) error {
+	contract := (*buf)[len(*buf)-2]
+	if contract.Type != ObjContract {
+		logger := lexem.GetLogger()
+		logger.WithFields(log.Fields{"type": consts.ParseError, "contract_type": contract.Type, "lex_value": lexem.Value}).Error("private func can only be in contract")
+		return fmt.Errorf(`private func can only be in contract`)
+	}
+	(*buf)[len(*buf)-1].Info = &FuncInfo{Private: true}
+	return nil
+}
+
 func fField(buf *[]*Block, state int, lexem *Lexem) error {
 	tx := (*(*buf)[len(*buf)-1]).Info.(*ContractInfo).Tx
 	*tx = append(*tx, &FieldInfo{Name: lexem.Value.(string), Type: reflect.TypeOf(nil)})
@@ -608,6 +889,18 @@ func fFieldTag(buf *[]*Block, state int, lexem *Lexem) error {
 	for i := len(*tx) - 1; i >= 0; i-- {
 		if len((*tx)[i].Tags) == 0 {
 			(*tx)[i].Tags = lexem.Value.(string)
+			if value, ok := (*tx)[i].Default(); ok {
+				if _, err := parseDefault((*tx)[i].Type, value); err != nil {
+					lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError, "field": (*tx)[i].Name, "default": value, "error": err}).Error("wrong default value of data field")
+					return fmt.Errorf(eWrongDefault, value, (*tx)[i].Name, lexem.Line, lexem.Column)
+				}
+			}
+			for _, rule := range fieldRules((*tx)[i].Tags) {
+				if err := checkRule((*tx)[i], rule); err != nil {
+					lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError, "field": (*tx)[i].Name, "rule": rule.String(), "error": err}).Error("wrong rule of data field")
+					return fmt.Errorf(eWrongRule, rule, (*tx)[i].Name, err, lexem.Line, lexem.Column)
+				}
+			}
 			break
 		}
 	}
@@ -652,7 +945,15 @@ func fNameBlock(buf *[]*Block, state int, lexem *Lexem) error {
 			Owner: (*buf)[0].Owner}
 	default:
 		itype = ObjFunc
-		fblock.Info = &FuncInfo{}
+		if finfo, ok := fblock.Info.(*FuncInfo); ok && finfo.Private {
+			if _, entry := entryFuncs[name]; entry {
+				lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError, "lex_value": name}).Error("entry function cannot be private")
+				return fmt.Errorf(eEntryPrivate, name, lexem.Line, lexem.Column)
+			}
+			finfo.Name = name
+		} else {
+			fblock.Info = &FuncInfo{Name: name}
+		}
 	}
 	fblock.Type = itype
 	prev.Objects[name] = &ObjInfo{Type: itype, Value: fblock}
@@ -660,11 +961,13 @@ func fNameBlock(buf *[]*Block, state int, lexem *Lexem) error {
 }
 
 // CompileBlock compile the source code into the Block structure with a byte-code
-func (vm *VM) CompileBlock(input []rune, owner *OwnerInfo) (*Block, error) {
+// The compilation continues after the error from the next statement, up to maxCompileErrors are returned
+// as CompileErrors
+func (vm *VM) CompileBlock(input []rune, owner *OwnerInfo) (block *Block, err error) {
 	root := &Block{Info: owner.StateID, Owner: owner}
 	lexems, err := lexParser(input)
 	if err != nil {
-		return nil, err
+		return nil, CompileErrors{newCompileError(err, nil)}
 	}
 	if len(lexems) == 0 {
 		return root, nil
@@ -675,12 +978,66 @@ func (vm *VM) CompileBlock(input []rune, owner *OwnerInfo) (*Block, error) {
 	blockstack[0] = root
 	fork := 0
 
+	var (
+		errs    CompileErrors
+		mark    compileMark
+		lastErr = -1
+	)
+	defer func() {
+		// the blocks can be inconsistent after the recovery, so the panic is the end of the compilation
+		if r := recover(); r != nil {
+			if len(errs) == 0 {
+				panic(r)
+			}
+			block, err = nil, errs
+		}
+	}()
+	// skipStatement adds the error and returns the position of the end of the statement with the error
+	// or -1 if the compilation must be stopped
+	skipStatement := func(cerr error, pos int) int {
+		if pos >= len(lexems) {
+			pos = len(lexems) - 1
+		}
+		errs = append(errs, newCompileError(cerr, lexems[pos]))
+		if !mark.ok || len(errs) >= maxCompileErrors || pos <= lastErr {
+			return -1
+		}
+		lastErr = pos
+		stack, blockstack = stack[:mark.stack], blockstack[:mark.blocks]
+		curState, fork = stateBody, 0
+		depth := 0
+		for j := mark.lexem; j < len(lexems); j++ {
+			switch lexems[j].Type {
+			case isLCurly:
+				depth++
+			case isRCurly:
+				if depth == 0 {
+					if j <= pos {
+						return -1
+					}
+					return j - 1
+				}
+				depth--
+			case lexNewLine:
+				if depth == 0 && j > pos {
+					return j
+				}
+			}
+		}
+		return -1
+	}
+
 	for i := 0; i < len(lexems); i++ {
 		var (
 			newState compileState
 			ok       bool
 		)
+		if curState == stateBody {
+			mark = compileMark{stack: len(stack), blocks: len(blockstack), lexem: i, ok: true}
+		}
 		lexem := lexems[i]
+		top := blockstack[len(blockstack)-1]
+		topLen := len(top.Code)
 		if newState, ok = states[curState][int(lexem.Type)]; !ok {
 			newState = states[curState][0]
 		}
@@ -705,11 +1062,17 @@ func (vm *VM) CompileBlock(input []rune, owner *OwnerInfo) (*Block, error) {
 			}
 			curlen := len((*blockstack[len(blockstack)-1]).Code)
 			if err := vm.compileEval(&lexems, &i, &blockstack); err != nil {
-				return nil, err
+				if i = skipStatement(err, i); i < 0 {
+					return nil, errs
+				}
+				continue
 			}
 			if (newState.NewState&stateMustEval) > 0 && curlen == len((*blockstack[len(blockstack)-1]).Code) {
 				log.WithFields(log.Fields{"type": consts.ParseError}).Error("there is not eval expression")
-				return nil, fmt.Errorf("there is not eval expression")
+				if i = skipStatement(fmt.Errorf("there is not eval expression"), i); i < 0 {
+					return nil, errs
+				}
+				continue
 			}
 			nextState = curState
 		}
@@ -725,7 +1088,8 @@ func (vm *VM) CompileBlock(input []rune, owner *OwnerInfo) (*Block, error) {
 		}
 		if (newState.NewState & statePop) > 0 {
 			if len(stack) == 0 {
-				return nil, fError(&blockstack, errMustLCurly, lexem)
+				errs = append(errs, newCompileError(fError(&blockstack, errMustLCurly, lexem), lexem))
+				return nil, errs
 			}
 			nextState = stack[len(stack)-1]
 			stack = stack[:len(stack)-1]
@@ -746,19 +1110,38 @@ func (vm *VM) CompileBlock(input []rune, owner *OwnerInfo) (*Block, error) {
 		if (newState.NewState & stateToBody) > 0 {
 			nextState = stateBody
 		}
+		if (newState.NewState & stateToSwitch) > 0 {
+			nextState = stateSwitch
+		}
 		if newState.Func > 0 {
 			if err := funcs[newState.Func](&blockstack, nextState, lexem); err != nil {
-				return nil, err
+				if i = skipStatement(err, i); i < 0 {
+					return nil, errs
+				}
+				continue
 			}
 		}
+		top.addLine(topLen, lexem)
 		curState = nextState
 	}
-	if len(stack) > 0 {
-		return nil, fError(&blockstack, errMustRCurly, lexems[len(lexems)-1])
+	if len(stack) > 0 && len(errs) < maxCompileErrors {
+		lexem := lexems[len(lexems)-1]
+		errs = append(errs, newCompileError(fError(&blockstack, errMustRCurly, lexem), lexem))
+	}
+	if len(errs) > 0 {
+		return nil, errs
 	}
 	return root, nil
 }
 
+// compileMark is the state of the compiler at the beginning of the statement
+type compileMark struct {
+	stack  int
+	blocks int
+	lexem  int
+	ok     bool
+}
+
 // FlushBlock loads the compiled Block into the virtual machine
 func (vm *VM) FlushBlock(root *Block) {
 	shift := len(vm.Children)
@@ -843,16 +1226,63 @@ func (vm *VM) findObj(name string, block *[]*Block) (ret *ObjInfo, owner *Block)
 }
 
 // This function is responsible for the compilation of expressions
-func (vm *VM) compileEval(lexems *Lexems, ind *int, block *[]*Block) error {
+func (vm *VM) compileEval(lexems *Lexems, ind *int, block *[]*Block) (err error) {
 	var indexInfo *IndexInfo
 
 	i := *ind
+	// the index of the lexem with the error is returned for the error position
+	defer func() {
+		if err != nil && i < len(*lexems) {
+			*ind = i
+		}
+	}()
 	curBlock := (*block)[len(*block)-1]
 
 	buffer := make(ByteCodes, 0, 20)
 	bytecode := make(ByteCodes, 0, 100)
 	parcount := make([]int, 0, 20)
 	setIndex := false
+
+	// nextType returns the type of the next lexeme skipping new lines
+	nextType := func(k int) uint32 {
+		for k++; k < len(*lexems); k++ {
+			if (*lexems)[k].Type != lexNewLine {
+				return (*lexems)[k].Type
+			}
+		}
+		return 0
+	}
+	// isLiteral returns true if the bracket is in the place of the operand, so it starts the literal
+	isLiteral := func(k int) bool {
+		if k == 0 {
+			return true
+		}
+		switch (*lexems)[k-1].Type {
+		case lexNumber, lexString, lexIdent, lexExtend, isRPar, isRBrack, isRCurly:
+			return false
+		}
+		return true
+	}
+	// openLiteral returns the command of the innermost literal if the literal is not closed yet
+	openLiteral := func() *ByteCode {
+		for k := len(buffer) - 1; k > 0; k-- {
+			if buffer[k].Cmd == cmdSys && buffer[k].Value.(uint16) == 0xff {
+				if buffer[k-1].Cmd == cmdArrayInit || buffer[k-1].Cmd == cmdMapInit {
+					return buffer[k-1]
+				}
+				break
+			}
+		}
+		return nil
+	}
+	startLiteral := func(initCmd uint16, closeType uint32) {
+		buffer = append(buffer, &ByteCode{initCmd, 0}, &ByteCode{cmdSys, uint16(0xff)})
+		count := 0
+		if nextType(i) != closeType {
+			count++
+		}
+		parcount = append(parcount, count)
+	}
 main:
 	for ; i < len(*lexems); i++ {
 		var cmd *ByteCode
@@ -860,9 +1290,47 @@ main:
 		lexem := (*lexems)[i]
 		logger := lexem.GetLogger()
 		switch lexem.Type {
-		case isRCurly, isLCurly:
+		case isLCurly:
+			if i > *ind && isLiteral(i) {
+				startLiteral(cmdMapInit, isRCurly)
+				continue
+			}
 			i--
 			break main
+		case isRCurly:
+			lit := openLiteral()
+			if lit == nil || lit.Cmd != cmdMapInit {
+				i--
+				break main
+			}
+			for buffer[len(buffer)-1].Cmd != cmdSys {
+				bytecode = append(bytecode, buffer[len(buffer)-1])
+				buffer = buffer[:len(buffer)-1]
+			}
+			buffer = buffer[:len(buffer)-2]
+			count := parcount[len(parcount)-1]
+			parcount = parcount[:len(parcount)-1]
+			if count != lit.Value.(int) {
+				logger.WithFields(log.Fields{"type": consts.ParseError}).Error("map value without key")
+				return fmt.Errorf(eMapKey, lexem.Line, lexem.Column)
+			}
+			bytecode = append(bytecode, lit)
+		case isColon:
+			lit := openLiteral()
+			if lit == nil || lit.Cmd != cmdMapInit {
+				logger.WithFields(log.Fields{"type": consts.ParseError}).Error("unexpected colon")
+				return fmt.Errorf(`unexpected : [Ln:%d Col:%d]`, lexem.Line, lexem.Column)
+			}
+			key := i - 1
+			if (*lexems)[key].Type == lexString {
+				for key--; key > 0 && (*lexems)[key].Type == lexNewLine; key-- {
+				}
+			}
+			if (*lexems)[i-1].Type != lexString || ((*lexems)[key].Type != isLCurly && (*lexems)[key].Type != isComma) {
+				logger.WithFields(log.Fields{"type": consts.ParseError}).Error("map key is not a string")
+				return fmt.Errorf(eMapKey, lexem.Line, lexem.Column)
+			}
+			lit.Value = lit.Value.(int) + 1
 		case lexNewLine:
 			if i > 0 && ((*lexems)[i-1].Type == isComma || (*lexems)[i-1].Type == lexOper) {
 				continue main
@@ -876,9 +1344,15 @@ main:
 		case isLPar:
 			buffer = append(buffer, &ByteCode{cmdSys, uint16(0xff)})
 		case isLBrack:
+			if isLiteral(i) {
+				startLiteral(cmdArrayInit, isRBrack)
+				continue
+			}
 			buffer = append(buffer, &ByteCode{cmdSys, uint16(0xff)})
 		case isComma:
-			if len(parcount) > 0 {
+			// the trailing comma of the literal doesn't add an item
+			if lit := openLiteral(); len(parcount) > 0 && (lit == nil ||
+				(nextType(i) != isRBrack && nextType(i) != isRCurly)) {
 				parcount[len(parcount)-1]++
 			}
 			for len(buffer) > 0 {
@@ -893,7 +1367,7 @@ main:
 		case isRPar:
 			for {
 				if len(buffer) == 0 {
-					logger.WithFields(log.Fields{"lex_value": lexem.Value.(string), "type": consts.ParseError}).Error("there is not pair")
+					logger.WithFields(log.Fields{"lex_value": lexem.Value, "type": consts.ParseError}).Error("there is not pair")
 					return fmt.Errorf(`there is not pair`)
 				}
 				prev := buffer[len(buffer)-1]
@@ -981,7 +1455,7 @@ main:
 		case isRBrack:
 			for {
 				if len(buffer) == 0 {
-					logger.WithFields(log.Fields{"lex_value": lexem.Value.(string), "type": consts.ParseError}).Error("there is not pair")
+					logger.WithFields(log.Fields{"lex_value": lexem.Value, "type": consts.ParseError}).Error("there is not pair")
 					return fmt.Errorf(`there is not pair`)
 				}
 				prev := buffer[len(buffer)-1]
@@ -992,7 +1466,13 @@ main:
 					bytecode = append(bytecode, prev)
 				}
 			}
-			if len(buffer) > 0 {
+			if len(buffer) > 0 && buffer[len(buffer)-1].Cmd == cmdArrayInit {
+				prev := buffer[len(buffer)-1]
+				buffer = buffer[:len(buffer)-1]
+				prev.Value = parcount[len(parcount)-1]
+				parcount = parcount[:len(parcount)-1]
+				bytecode = append(bytecode, prev)
+			} else if len(buffer) > 0 {
 				if prev := buffer[len(buffer)-1]; prev.Cmd == cmdIndex {
 					buffer = buffer[:len(buffer)-1]
 					if i < len(*lexems)-1 && (*lexems)[i+1].Type == isEq {
@@ -1133,7 +1613,11 @@ main:
 				}
 			}
 			if !call {
-				cmd = &ByteCode{cmdVar, &VarInfo{objInfo, tobj}}
+				if objInfo.Type == ObjConst {
+					cmd = &ByteCode{cmdPush, objInfo.Value.(*ConstInfo).Value}
+				} else {
+					cmd = &ByteCode{cmdVar, &VarInfo{objInfo, tobj}}
+				}
 			}
 		}
 		if lexem.Type&0xff == lexKeyword {
@@ -1147,7 +1631,7 @@ main:
 	}
 	*ind = i
 	for i := len(buffer) - 1; i >= 0; i-- {
-		if buffer[i].Cmd == cmdSys {
+		if buffer[i].Cmd == cmdSys || buffer[i].Cmd == cmdArrayInit || buffer[i].Cmd == cmdMapInit {
 			log.WithFields(log.Fields{"type": consts.ParseError}).Error("there is not pair")
 			return fmt.Errorf(`there is not pair`)
 		}
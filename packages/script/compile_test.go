@@ -17,6 +17,7 @@
 package script
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
@@ -54,6 +55,11 @@ func getArray() []interface{} {
 		"The second string", int64(2000)}
 }
 
+func jsonEncode(v interface{}) string {
+	out, _ := json.Marshal(v)
+	return string(out)
+}
+
 // Str converts the value to a string
 func str(v interface{}) (ret string) {
 	return fmt.Sprint(v)
@@ -139,6 +145,301 @@ func TestVMCompile(t *testing.T) {
 							nop()
 							return Sprintf("val=%d", i)
 						}`, `loop`, `val=125`},
+		{`func nested string {
+							var i j k int
+							while i < 5 {
+								i = i + 1
+								j = 0
+								while true {
+									j = j + 1
+									if j > i {
+										break
+									}
+									if j == 2 || j == 4 {
+										continue
+									}
+									k = k + j
+								}
+								if i == 4 {
+									break
+								}
+							}
+							return Sprintf("%d %d %d", i, j, k)
+						}`, `nested`, `4 5 10`},
+		{`func sw(par string) string {
+							switch par
+							case "new", "open" {
+								return "active"
+							}
+							case "closed" {
+								if par == "closed" {
+									return "done"
+								} elif par == "" {
+									return "empty"
+								}
+							}
+							default {
+								return "unknown"
+							}
+							return "none"
+						}
+						func swnum(par int) int {
+							var i int
+							switch par * 2
+							case 2 {
+								i = 10
+							}
+							case 4, 6 {
+								i = 20
+							}
+							return i
+						}
+						func swloop string {
+							var i k int
+							while i < 10 {
+								i = i + 1
+								switch i
+								case 2 {
+									continue
+								}
+								case 5 {
+									break
+								}
+								k = k + i
+							}
+							return Sprintf("%s %s %s %d %d %d %d", sw("open"), sw("closed"), sw("paused"), swnum(1), swnum(3), swnum(5), k)
+						}`, `swloop`, `active done unknown 10 20 0 8`},
+		{`func swtype string {
+							var s string
+							switch s
+							case "a" {
+							}
+							case 1 {
+							}
+							return "ok"
+						}`, `swtype`, `case value 1 doesn't match switch type string [Ln:6 Col:14]`},
+		{`func swexpr string {
+							var i int
+							switch i
+							case "1" {
+							}
+							return "ok"
+						}`, `swexpr`, `case value 1 doesn't match switch type int64 [Ln:4 Col:14]`},
+		{`func swdup string {
+							switch $test1
+							case 1, 1 {
+							}
+							return "ok"
+						}`, `swdup`, `duplicate case value 1 [Ln:3 Col:17]`},
+		{`func swdef string {
+							switch $test2
+							case "test 2" {
+								return "test"
+							}
+							default {
+								return "default"
+							}
+							return "ok"
+						}`, `swdef`, `test`},
+		{`func swrt string {
+							switch $test2
+							case 1 {
+							}
+							return "ok"
+						}`, `swrt`, `switch value has type string, case values have type int64`},
+		{`func arrlit string {
+							var a array
+							a = ["a", 1, $test1, 2*3+1, [], ["nested", [true]]]
+							return JSONEncode(a)
+						}`, `arrlit`, `["a",1,101,7,[],["nested",[true]]]`},
+		{`func arrlitcomma string {
+							var a array
+							var s string
+							s = "b"
+							a = [
+								"a",
+								s + "c",
+							]
+							return JSONEncode(a) + JSONEncode(["x", "y"])
+						}`, `arrlitcomma`, `["a","bc"]["x","y"]`},
+		{`func maplit string {
+							var m map
+							var i int
+							i = 5
+							m = {"key": "value", "n": 2, "sum": i+1, "list": [1, {"in": $test2}], "empty": {}}
+							return JSONEncode(m)
+						}`, `maplit`, `{"empty":{},"key":"value","list":[1,{"in":"test 2"}],"n":2,"sum":6}`},
+		{`func maplitcomma string {
+							var m map
+							m = {
+								"a": $glob["test"],
+								"b": {"c": 3,},
+							}
+							return JSONEncode(m)
+						}`, `maplitcomma`, `{"a":"String value","b":{"c":3}}`},
+		{`func maplitkey string {
+							var m map
+							m = {"a": 1, 2: 3}
+							return JSONEncode(m)
+						}`, `maplitkey`, `map key must be string [Ln:3 Col:23]`},
+		{`func maplitval string {
+							var m map
+							m = {"a": 1, "b"}
+							return JSONEncode(m)
+						}`, `maplitval`, `map key must be string [Ln:3 Col:25]`},
+		{`func elseif string {
+							var i, ret int
+							while i < 5 {
+								if i == 0 {
+									ret = ret + 1
+								} else if i == 1 {
+									ret = ret + 10
+								} elif i == 2 {
+									ret = ret + 100
+								} else if i == 3 {
+									if i > 10 {
+										ret = 0
+									} else if i > 2 {
+										ret = ret + 1000
+									}
+								} else {
+									ret = ret + 10000
+								}
+								i = i + 1
+							}
+							return Sprintf("%d", ret)
+						}`, `elseif`, `11111`},
+		{`func elifdangling string {
+							var i int
+							elif i == 0 {
+							}
+							return "ok"
+						}`, `elifdangling`, `elif without if [Ln:3 Col:9]`},
+		{`func elifinside string {
+							var i int
+							if i == 0 {
+								i = 1
+								elif i == 1 {
+								}
+							}
+							return "ok"
+						}`, `elifinside`, `elif without if [Ln:5 Col:10]`},
+		{`func breakout string {
+							var i int
+							if i == 0 {
+								break
+							}
+							return "ok"
+						}`, `breakout`, `break is not inside of while [Ln:4 Col:10]`},
+		{`func contout string {
+							var i int
+							while i < 2 {
+								i = i + 1
+							}
+							continue
+							return "ok"
+						}`, `contout`, `continue is not inside of while [Ln:6 Col:9]`},
+		{`func loopfunc string {
+							var i int
+							while i < 2 {
+								i = i + 1
+								func inner {
+									break
+								}
+							}
+							return "ok"
+						}`, `loopfunc`, `break is not inside of while [Ln:6 Col:11]`},
+		{`contract consts {
+							const {
+								Limit = 3
+								Prefix string = "item"
+								Fee money = "1500000000000000000", Count int = 2
+							}
+							func get string {
+								var i int
+								while i < Limit {
+									i = i + 1
+								}
+								return Sprintf("%s %d %v", Prefix, i*Count, Fee*Money(Count))
+							}
+						}`, `consts.get`, `item 6 3000000000000000000`},
+		{`contract constdup {
+							const {
+								Limit = 3
+								Limit = 4
+							}
+						}`, `constdup`, `constant Limit is already declared [Ln:4 Col:10]`},
+		{`contract constassign {
+							const {
+								Limit = 3
+							}
+							func set string {
+								Limit = 4
+								return "ok"
+							}
+						}`, `constassign.set`, `constant Limit cannot be changed [Ln:6 Col:10]`},
+		{`contract constvar {
+							const {
+								Limit = 3
+							}
+							func set string {
+								var Limit int
+								return "ok"
+							}
+						}`, `constvar.set`, `constant Limit is already declared [Ln:6 Col:14]`},
+		{`contract constval {
+							const {
+								Limit int = "3"
+							}
+						}`, `constval`, `wrong value of constant Limit [Ln:3 Col:22]`},
+		{`contract consttype {
+							const {
+								Limit bool = true
+							}
+						}`, `consttype`, `constant Limit must be string, int or money [Ln:3 Col:16]`},
+		{`contract privsrc {
+							private func helper string {
+								return "secret"
+							}
+							func open string {
+								if true {
+									return helper() + " " + helper()
+								}
+							}
+						}`, `privsrc.open`, `secret secret`},
+		{`contract privcall {
+							private func helper string {
+								return "secret"
+							}
+						}`, `privcall.helper`, `function privcall.helper is private`},
+		{`contract privaction {
+							private action {
+							}
+						}`, `privaction`, `action cannot be private [Ln:2 Col:17]`},
+		{`private func privfunc string {
+							return "ok"
+						}`, `privfunc`, `private func can only be in contract`},
+		{`contract ruletype {
+							data {
+								Count int "regexp:^1$"
+							}
+						}`, `ruletype`, `wrong rule regexp:^1$ of field Count: regexp can be used only with string [Ln:3 Col:20]`},
+		{`contract rulevalue {
+							data {
+								Name string "optional max:ten"
+							}
+						}`, `rulevalue`, `wrong rule max:ten of field Name: max must be a number [Ln:3 Col:22]`},
+		{`contract ruledefault {
+							data {
+								Count int "default:many"
+							}
+						}`, `ruledefault`, `wrong default value many of field Count [Ln:3 Col:20]`},
+		{`func constfunc string {
+							const {
+								Limit = 3
+							}
+							return "ok"
+						}`, `constfunc`, `const can only be in contract`},
 		{`contract my {
 							data {
 								Par1 int
@@ -501,7 +802,7 @@ func TestVMCompile(t *testing.T) {
 	vm.Extern = true
 	vm.Extend(&ExtendData{map[string]interface{}{"Println": fmt.Println, "Sprintf": fmt.Sprintf,
 		"GetMap": getMap, "GetArray": getArray, "lenArray": lenArray,
-		"str": str, "Money": Money, "Replace": strings.Replace}, nil})
+		"str": str, "Money": Money, "Replace": strings.Replace, "JSONEncode": jsonEncode}, nil})
 
 	for ikey, item := range test {
 		source := []rune(item.Input)
@@ -16,17 +16,41 @@
 
 package script
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+)
 
 const (
-	eContractLoop    = `there is loop in %s contract`
-	eSysVar          = `system variable $%s cannot be changed`
-	eTypeParam       = `parameter %d has wrong type`
-	eUndefinedParam  = `%s is not defined`
-	eUnknownContract = `unknown contract %s`
-	eWrongParams     = `function %s must have %d parameters`
-	eArrIndex        = `index of array cannot be type %s`
-	eMapIndex        = `index of map cannot be type %s`
+	eContractLoop     = `there is loop in %s contract`
+	eSysVar           = `system variable $%s cannot be changed`
+	eTypeParam        = `parameter %d has wrong type`
+	eUndefinedParam   = `%s is not defined`
+	eUnknownContract  = `unknown contract %s`
+	eWrongParams      = `function %s must have %d parameters`
+	eArrIndex         = `index of array cannot be type %s`
+	eMapIndex         = `index of map cannot be type %s`
+	eOutsideLoop      = `%s is not inside of while [Ln:%d Col:%d]`
+	eSwitchType       = `switch cannot be type %s [Ln:%d Col:%d]`
+	eCaseValue        = `case value %v must be int or string [Ln:%d Col:%d]`
+	eCaseType         = `case value %v doesn't match switch type %s [Ln:%d Col:%d]`
+	eCaseDuplicate    = `duplicate case value %v [Ln:%d Col:%d]`
+	eDefaultDuplicate = `duplicate default [Ln:%d Col:%d]`
+	eSwitchValue      = `switch value has type %s, case values have type %s`
+	eMapKey           = `map key must be string [Ln:%d Col:%d]`
+	eConstDuplicate   = `constant %s is already declared [Ln:%d Col:%d]`
+	eConstAssign      = `constant %s cannot be changed [Ln:%d Col:%d]`
+	eConstType        = `constant %s must be string, int or money [Ln:%d Col:%d]`
+	eConstValue       = `wrong value of constant %s [Ln:%d Col:%d]`
+	eCallDepth        = `call depth exceeded in %s`
+	eEntryPrivate     = `%s cannot be private [Ln:%d Col:%d]`
+	ePrivateFunc      = `function %s is private`
+	eWrongRule        = `wrong rule %s of field %s: %v [Ln:%d Col:%d]`
+	eFieldRule        = `field %s violates %s`
+	eWrongDefault     = `wrong default value %s of field %s [Ln:%d Col:%d]`
+	eContractName     = `invalid contract name %s: %s`
 )
 
 var (
@@ -38,3 +62,75 @@ var (
 	errMaxMapCount     = errors.New(`The maxumim length of map`)
 	errRecursion       = errors.New(`The contract can't call itself recursively`)
 )
+
+const (
+	// CompileErrorType is the type of VMError which is returned if the source code can't be compiled
+	CompileErrorType = "compile"
+
+	// maxCompileErrors is the maximum number of the errors which are collected in one compilation
+	maxCompileErrors = 10
+)
+
+var errPosRegexp = regexp.MustCompile(`\s*\[Ln:(\d+)(?: Col:(\d+))?\]$`)
+
+// CompileError is the error of the compilation with the position in the source code
+type CompileError struct {
+	Line    uint32 `json:"line"`
+	Column  uint32 `json:"column"`
+	Message string `json:"message"`
+	Token   string `json:"token,omitempty"`
+	err     error
+}
+
+func (e *CompileError) Error() string {
+	if e.err == nil {
+		return e.Message
+	}
+	return e.err.Error()
+}
+
+// CompileErrors is the list of the errors of one compilation. The error text is the text of
+// the first error, the other errors are reported by the structured message
+type CompileErrors []*CompileError
+
+func (e CompileErrors) Error() string {
+	return e[0].Error()
+}
+
+// VMError returns JSON of VMError with the list of the errors. The last errors are dropped
+// if the text is longer than maxSize
+func (e CompileErrors) VMError(maxSize int) error {
+	vmErr := &VMError{Type: CompileErrorType, Error: e.Error(), Compile: e}
+	for {
+		out, err := json.Marshal(vmErr)
+		if err != nil {
+			return SetVMError(CompileErrorType, vmErr.Error)
+		}
+		if len(out) <= maxSize || len(vmErr.Compile) == 1 {
+			return errors.New(string(out))
+		}
+		vmErr.Compile = vmErr.Compile[:len(vmErr.Compile)-1]
+	}
+}
+
+// newCompileError returns the error with the position of the error text or the position of the lexem
+func newCompileError(err error, lexem *Lexem) *CompileError {
+	cerr := &CompileError{Message: err.Error(), err: err}
+	if lexem != nil {
+		cerr.Line, cerr.Column = lexem.Line, lexem.Column
+		// the operators and the keywords are kept as the codes
+		if token, ok := lexem.Value.(string); ok {
+			cerr.Token = token
+		}
+	}
+	if match := errPosRegexp.FindStringSubmatch(cerr.Message); match != nil {
+		cerr.Message = cerr.Message[:len(cerr.Message)-len(match[0])]
+		line, _ := strconv.ParseUint(match[1], 10, 32)
+		cerr.Line, cerr.Column = uint32(line), 0
+		if len(match[2]) > 0 {
+			column, _ := strconv.ParseUint(match[2], 10, 32)
+			cerr.Column = uint32(column)
+		}
+	}
+	return cerr
+}
@@ -0,0 +1,586 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package script
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// The formatter of the contract sources is implemented in this file. The source is split into the lexemes
+// with the same finite machine as the lexical analysis does it, but the lexemes keep their text and
+// the comments are kept too. The formatter changes only the whitespaces, the empty lines, the line breaks
+// around the braces of the blocks and the order of the sections of the contracts, so the source is
+// compiled to the same byte-code.
+
+const (
+	// fmtLineComment is the // comment which isn't the lexeme of the lexical analysis
+	fmtLineComment = 0xfe
+	// fmtIndent is the indent of the nested blocks
+	fmtIndent = `    `
+)
+
+var errFormat = errors.New(`formatting changes the lexemes of the source`)
+
+// The sections of the contract in the canonical order
+const (
+	sectionData = iota
+	sectionSettings
+	sectionConst
+	sectionConditions
+	sectionAction
+	sectionFunc
+)
+
+// fmtToken is the lexeme of the formatted source
+type fmtToken struct {
+	Type  uint32
+	Text  string
+	Space bool // the lexeme is separated from the previous one with whitespaces
+	block bool // the curly brace of the block but not of the map literal
+	unary bool // the unary operator
+}
+
+func (t *fmtToken) isComment() bool {
+	return t.Type == lexComment || t.Type == fmtLineComment
+}
+
+func (t *fmtToken) isKeyword(key uint32) bool {
+	return t.Type == lexKeyword|(key<<8)
+}
+
+// isOperand returns true if the lexeme can be the end of the operand
+func (t *fmtToken) isOperand() bool {
+	switch t.Type {
+	case lexNumber, lexString, lexIdent, lexExtend, lexType, isRPar, isRBrack, isRCurly:
+		return true
+	}
+	return false
+}
+
+// FormatSource returns the source of the contracts and the functions with the canonical indents and spaces.
+// The sections of the contracts are ordered as data, settings, const, conditions, action and the functions
+// if it doesn't change the order of the declarations and the usage of the names. The comments are kept
+func FormatSource(src string) (string, error) {
+	input := []rune(src)
+	if _, err := lexParser(input); err != nil {
+		return ``, err
+	}
+	tokens, err := fmtLexer(input)
+	if err != nil {
+		return ``, err
+	}
+	markBlocks(tokens)
+	tokens = orderSections(tokens)
+	out := printSource(tokens)
+
+	// the lexemes of the result must be the same except the new lines
+	check, err := fmtLexer([]rune(out))
+	if err != nil {
+		return ``, err
+	}
+	if !sameLexemes(tokens, check) {
+		return ``, errFormat
+	}
+	return out, nil
+}
+
+// fmtLexer splits the source into the lexemes with the text of the source including the comments
+func fmtLexer(input []rune) ([]*fmtToken, error) {
+	var (
+		curState      uint8
+		lexID, flags  uint32
+		start, prev   int
+		space         bool
+		lineComment   *fmtToken
+		tokens        = make([]*fmtToken, 0, len(input)/4)
+		irune         = len(alphabet) - 1
+		length        = len(input) + 1
+		gapWhitespace = func(text string) bool {
+			return len(strings.Trim(text, " \t\r")) == 0
+		}
+	)
+	// gap checks the text between the lexemes, it can contain only the whitespaces and the // comment
+	gap := func(right int) error {
+		text := string(input[prev:right])
+		space = len(text) > 0
+		lineComment = nil
+		if gapWhitespace(text) {
+			return nil
+		}
+		ind := strings.Index(text, `//`)
+		if ind < 0 || !gapWhitespace(text[:ind]) {
+			return errFormat
+		}
+		lineComment = &fmtToken{Type: fmtLineComment, Text: strings.TrimRight(text[ind:], " \t\r"),
+			Space: ind > 0}
+		space = false
+		return nil
+	}
+	for off := 0; off < length; {
+		r := ' '
+		if off < len(input) {
+			r = input[off]
+		}
+		letter := alphabet[irune]
+		if r <= 127 {
+			letter = alphabet[r]
+		}
+		val := lexTable[curState][letter]
+		curState, lexID, flags = uint8(val>>16), (val>>8)&0xff, val&0xff
+		if curState == lexError {
+			return nil, errFormat
+		}
+		if (flags & lexfSkip) != 0 {
+			off++
+			continue
+		}
+		if lexID > 0 {
+			lexOff := off
+			if (flags & lexfPop) != 0 {
+				lexOff = start
+			}
+			right := off
+			if (flags & lexfNext) != 0 {
+				right++
+			}
+			if err := gap(lexOff); err != nil {
+				return nil, err
+			}
+			if lineComment != nil {
+				tokens = append(tokens, lineComment)
+			}
+			token := &fmtToken{Type: lexID, Text: string(input[lexOff:right]), Space: space}
+			switch lexID {
+			case lexSys:
+				token.Type |= uint32(input[lexOff]) << 8
+			case lexIdent:
+				if token.Text[0] == '$' {
+					token.Type = lexExtend
+				} else if keyID, ok := keywords[token.Text]; ok {
+					switch keyID {
+					case keyTrue, keyFalse, keyNil:
+						token.Type = lexNumber
+					default:
+						token.Type = lexKeyword | (keyID << 8)
+					}
+				} else if _, ok := types[token.Text]; ok {
+					token.Type = lexType
+				}
+			}
+			tokens = append(tokens, token)
+			prev = right
+		}
+		if (flags & lexfPush) != 0 {
+			start = off
+		}
+		if (flags & lexfNext) != 0 {
+			off++
+		}
+	}
+	// the unclosed strings and comments are found here
+	if err := gap(len(input)); err != nil {
+		return nil, err
+	}
+	if lineComment != nil {
+		tokens = append(tokens, lineComment)
+	}
+	return tokens, nil
+}
+
+// markBlocks marks the curly braces of the blocks and the unary operators
+func markBlocks(tokens []*fmtToken) {
+	var (
+		prev   *fmtToken
+		braces []*fmtToken
+	)
+	for _, token := range tokens {
+		switch {
+		case token.Type == lexNewLine || token.isComment():
+			continue
+		case token.Type == isLCurly:
+			// the map literal is in the place of the operand
+			token.block = prev != nil && (prev.isOperand() || (prev.Type&0xff == lexKeyword &&
+				!prev.isKeyword(keyReturn) && !prev.isKeyword(keyError) && !prev.isKeyword(keyWarning) &&
+				!prev.isKeyword(keyInfo) && !prev.isKeyword(keyIf) && !prev.isKeyword(keyElif) &&
+				!prev.isKeyword(keyWhile) && !prev.isKeyword(keySwitch)))
+			braces = append(braces, token)
+		case token.Type == isRCurly:
+			if len(braces) > 0 {
+				token.block = braces[len(braces)-1].block
+				braces = braces[:len(braces)-1]
+			}
+		case token.Type == lexOper:
+			token.unary = token.Text == `!` || (token.Text == `-` && (prev == nil || !prev.isOperand()))
+		}
+		prev = token
+	}
+}
+
+// section is the part of the body of the contract with its comments
+type section struct {
+	kind     int
+	tokens   []*fmtToken
+	declared map[string]bool
+	used     map[string]bool
+}
+
+// orderSections changes the order of the sections of the contracts to the canonical one
+func orderSections(tokens []*fmtToken) []*fmtToken {
+	out := make([]*fmtToken, 0, len(tokens))
+	depth := 0
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		out = append(out, token)
+		switch token.Type {
+		case isLCurly, isLPar, isLBrack:
+			depth++
+		case isRCurly, isRPar, isRBrack:
+			depth--
+		}
+		if depth != 0 || !token.isKeyword(keyContract) {
+			continue
+		}
+		start := nextBrace(tokens, i+1)
+		end := closingBrace(tokens, start)
+		if start < 0 || end < 0 {
+			continue
+		}
+		out = append(out, tokens[i+1:start+1]...)
+		out = append(out, contractBody(tokens[start+1:end])...)
+		out = append(out, tokens[end])
+		i = end
+	}
+	return out
+}
+
+// nextBrace returns the index of the first curly brace of the block on the same level
+func nextBrace(tokens []*fmtToken, from int) int {
+	depth := 0
+	for i := from; i < len(tokens); i++ {
+		switch tokens[i].Type {
+		case isLPar, isLBrack:
+			depth++
+		case isRPar, isRBrack:
+			depth--
+		case isLCurly:
+			if depth == 0 {
+				return i
+			}
+			depth++
+		case isRCurly:
+			return -1
+		}
+	}
+	return -1
+}
+
+// closingBrace returns the index of the brace which closes the brace with the index start
+func closingBrace(tokens []*fmtToken, start int) int {
+	if start < 0 {
+		return -1
+	}
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		switch tokens[i].Type {
+		case isLCurly, isLPar, isLBrack:
+			depth++
+		case isRCurly, isRPar, isRBrack:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// contractBody returns the body of the contract with the ordered sections. The body is returned
+// without changes if it contains unknown statements or the new order can change the compiled code
+func contractBody(body []*fmtToken) []*fmtToken {
+	var sections []*section
+	i := 0
+	for {
+		from := i
+		for i < len(body) && (body[i].Type == lexNewLine || body[i].isComment()) {
+			i++
+		}
+		if i == len(body) {
+			break
+		}
+		kind, ok := sectionKind(body, i)
+		if !ok {
+			return body
+		}
+		end := closingBrace(body, nextBrace(body, i))
+		if end < 0 {
+			return body
+		}
+		// the comments on the same line belong to the section
+		for end+1 < len(body) && body[end+1].isComment() {
+			end++
+		}
+		sections = append(sections, newSection(kind, body[from:end+1]))
+		i = end + 1
+	}
+	ordered := make([]*section, len(sections))
+	copy(ordered, sections)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].kind < ordered[j].kind
+	})
+	for i, cur := range sections {
+		for _, next := range sections[i+1:] {
+			if next.kind < cur.kind && (intersect(next.used, cur.declared) || intersect(cur.used, next.declared)) {
+				return body
+			}
+		}
+	}
+	out := make([]*fmtToken, 0, len(body))
+	for _, item := range ordered {
+		out = append(out, item.tokens...)
+	}
+	return append(out, body[i:]...)
+}
+
+// sectionKind returns the kind of the section which starts with the lexeme
+func sectionKind(body []*fmtToken, i int) (int, bool) {
+	token := body[i]
+	switch {
+	case token.isKeyword(keyTX):
+		return sectionData, true
+	case token.isKeyword(keySettings):
+		return sectionSettings, true
+	case token.isKeyword(keyConst):
+		return sectionConst, true
+	case token.isKeyword(keyCond):
+		return sectionConditions, true
+	case token.isKeyword(keyAction):
+		return sectionAction, true
+	case token.isKeyword(keyPrivate):
+		return sectionFunc, true
+	case token.isKeyword(keyFunc):
+		if i+1 < len(body) {
+			if kind, ok := sectionKind(body, i+1); ok && kind != sectionFunc {
+				return kind, true
+			}
+		}
+		return sectionFunc, true
+	}
+	return 0, false
+}
+
+// newSection collects the names which are declared and used in the section
+func newSection(kind int, tokens []*fmtToken) *section {
+	item := &section{kind: kind, tokens: tokens, declared: make(map[string]bool), used: make(map[string]bool)}
+	var prev *fmtToken
+	body := nextBrace(tokens, 0)
+	for i, token := range tokens {
+		if token.Type != lexIdent {
+			if token.Type != lexNewLine && !token.isComment() {
+				prev = token
+			}
+			continue
+		}
+		item.used[token.Text] = true
+		switch kind {
+		case sectionFunc:
+			// the name of the function and the names of its tails
+			if i < body && prev != nil && (prev.isKeyword(keyFunc) || prev.Type == isDot) {
+				item.declared[token.Text] = true
+			}
+		case sectionConst:
+			item.declared[token.Text] = true
+		}
+		prev = token
+	}
+	return item
+}
+
+func intersect(left, right map[string]bool) bool {
+	for name := range left {
+		if right[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// splitLines splits the lexemes into the lines and puts the braces of the blocks on the separate lines
+func splitLines(tokens []*fmtToken) [][]*fmtToken {
+	lines := make([][]*fmtToken, 0)
+	var line []*fmtToken
+	flush := func() {
+		lines = append(lines, line)
+		line = nil
+	}
+	for i, token := range tokens {
+		if token.Type == lexNewLine {
+			flush()
+			continue
+		}
+		if token.Type == isRCurly && token.block && len(line) > 0 && line[len(line)-1].Type != isLCurly {
+			flush()
+		}
+		line = append(line, token)
+		if !token.block || i+1 == len(tokens) {
+			continue
+		}
+		next := tokens[i+1]
+		if next.Type == lexNewLine || next.Type == fmtLineComment || next.Type == isRCurly {
+			continue
+		}
+		if token.Type == isLCurly || (!next.isKeyword(keyElse) && !next.isKeyword(keyElif)) {
+			flush()
+		}
+	}
+	return append(lines, line)
+}
+
+// trimLines removes the empty lines at the begin and at the end of the blocks and the repeated empty lines
+func trimLines(lines [][]*fmtToken) [][]*fmtToken {
+	out := make([][]*fmtToken, 0, len(lines))
+	for i, line := range lines {
+		if len(line) > 0 {
+			out = append(out, line)
+			continue
+		}
+		if len(out) == 0 || len(out[len(out)-1]) == 0 {
+			continue
+		}
+		if last := lastToken(out[len(out)-1]); last != nil && last.Type == isLCurly && last.block {
+			continue
+		}
+		next := i + 1
+		for next < len(lines) && len(lines[next]) == 0 {
+			next++
+		}
+		if next == len(lines) || (lines[next][0].Type == isRCurly && lines[next][0].block) {
+			continue
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// lastToken returns the last lexeme of the line except the comments
+func lastToken(line []*fmtToken) *fmtToken {
+	for i := len(line) - 1; i >= 0; i-- {
+		if !line[i].isComment() {
+			return line[i]
+		}
+	}
+	return nil
+}
+
+// printSource prints the lines with the indents of the nested brackets. The continued expressions
+// get the additional indent
+func printSource(tokens []*fmtToken) string {
+	var (
+		out   strings.Builder
+		stack []int
+	)
+	lines := trimLines(splitLines(tokens))
+	// base is the indent of the line without the indent of the continued expression
+	base := make([]int, len(lines))
+	for k, line := range lines {
+		if k > 0 {
+			out.WriteString("\n")
+		}
+		if len(line) == 0 {
+			continue
+		}
+		closed := 0
+		for closed < len(line) && closed < len(stack) && (line[closed].Type == isRCurly ||
+			line[closed].Type == isRPar || line[closed].Type == isRBrack) {
+			closed++
+		}
+		indent := 0
+		if closed > 0 {
+			base[k] = base[stack[len(stack)-closed]]
+		} else {
+			if len(stack) > 0 {
+				base[k] = base[stack[len(stack)-1]] + 1
+			}
+			if k > 0 {
+				if last := lastToken(lines[k-1]); last != nil && last.Type == lexOper {
+					indent++
+				}
+			}
+		}
+		out.WriteString(strings.Repeat(fmtIndent, base[k]+indent))
+		for i, token := range line {
+			if i > 0 && fmtSpace(line[i-1], token) {
+				out.WriteString(` `)
+			}
+			out.WriteString(token.Text)
+			switch token.Type {
+			case isLCurly, isLPar, isLBrack:
+				stack = append(stack, k)
+			case isRCurly, isRPar, isRBrack:
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+			}
+		}
+	}
+	return out.String()
+}
+
+// fmtSpace returns true if the lexemes must be separated with the space
+func fmtSpace(prev, cur *fmtToken) bool {
+	switch {
+	case cur.isComment() || prev.isComment():
+		return true
+	case cur.Text == `...`:
+		return cur.Space
+	case cur.Type == isComma || cur.Type == isRPar || cur.Type == isRBrack || cur.Type == isColon ||
+		cur.Type == isDot:
+		return false
+	case cur.Type == isRCurly:
+		return cur.block && prev.Type != isLCurly
+	case prev.Type == isLPar || prev.Type == isLBrack || prev.Type == isDot || prev.Type == lexSys|('#'<<8) ||
+		(prev.Type == isLCurly && !prev.block) || prev.unary:
+		return false
+	case cur.Type == isLPar || cur.Type == isLBrack:
+		return prev.Type != lexIdent && prev.Type != lexExtend && prev.Type != isRPar && prev.Type != isRBrack
+	}
+	return true
+}
+
+// sameLexemes returns true if the lists contain the same lexemes except the new lines
+func sameLexemes(left, right []*fmtToken) bool {
+	filter := func(list []*fmtToken) []*fmtToken {
+		out := make([]*fmtToken, 0, len(list))
+		for _, token := range list {
+			if token.Type != lexNewLine {
+				out = append(out, token)
+			}
+		}
+		return out
+	}
+	left, right = filter(left), filter(right)
+	if len(left) != len(right) {
+		return false
+	}
+	for i, token := range left {
+		if token.Type != right[i].Type || token.Text != right[i].Text {
+			return false
+		}
+	}
+	return true
+}
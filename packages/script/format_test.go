@@ -0,0 +1,125 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package script
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const messySource = `func   Sum(a int,b int) int{return a+b}
+contract  Messy{
+    func  double(x int) int { return x*2 }   // helper
+    action{ var i int; var list array
+        list = [1,-2 ,
+            3]
+        if !$Flag&&i==0 {i=Sum(i ,1)} elif i>1 {i = -i} else if i < -1 { i = Sum(i, 2) } else {i=0}
+        $result = {"a":list , "b":{"c": "text \"quoted\""}}
+        while i<3 { i=i+1
+            if i == 2 { continue } }
+        $text = ` + "`multi\n   line`" + ` + Sprintf("%v",
+            i) +
+            Sprintf("%v", Sum(1,
+        2))
+    }
+
+
+    conditions {
+        /* check
+           the flag */
+        if $Flag == nil { error "no flag" }
+    }
+    data {
+        Flag bool "optional"
+        Comment string
+    }
+}`
+
+// the contract function is declared before the action which calls it
+const unsafeSource = `contract Unsafe {
+	func double(x int) int {
+		return x * 2
+	}
+	action {
+		$result = double(2)
+	}
+	data {
+		Value int
+	}
+}`
+
+// compiledCode returns the byte-code of the source without the positions of the lexemes, the functions
+// of the contracts are sorted by names because their order depends on the order of the sections
+func compiledCode(t *testing.T, src string) []byte {
+	vm := NewVM()
+	vm.Extend(&ExtendData{map[string]interface{}{"Sprintf": fmt.Sprintf}, nil})
+	vm.Extern = true
+	root, err := vm.CompileBlock([]rune(src), &OwnerInfo{StateID: 1, Active: true, TableID: 1})
+	require.NoError(t, err)
+	var clean func(block *Block)
+	clean = func(block *Block) {
+		block.Lines = nil
+		if block.Type == ObjContract {
+			sort.SliceStable(block.Children, func(i, j int) bool {
+				return block.Children[i].Info.(*FuncInfo).Name < block.Children[j].Info.(*FuncInfo).Name
+			})
+		}
+		for _, child := range block.Children {
+			clean(child)
+		}
+	}
+	clean(root)
+	data, err := vm.EncodeBlock(root)
+	require.NoError(t, err)
+	return data
+}
+
+func TestFormatSource(t *testing.T) {
+	for _, src := range []string{serializeSource, messySource, unsafeSource,
+		"func Join(list ...) string {return Sprintf(\"%v\", list)}\n\n\n// the end"} {
+		out, err := FormatSource(src)
+		require.NoError(t, err)
+		assert.Equal(t, compiledCode(t, src), compiledCode(t, out), out)
+
+		again, err := FormatSource(out)
+		require.NoError(t, err)
+		assert.Equal(t, out, again)
+	}
+
+	out, err := FormatSource("contract Small {action { $a = 1;$b = {\"x\": [1, 2]} }\ndata {A int}}")
+	require.NoError(t, err)
+	assert.Equal(t, `contract Small {
+    data {
+        A int
+    }
+    action {
+        $a = 1
+        $b = {"x": [1, 2]}
+    }
+}`, out)
+
+	out, err = FormatSource(unsafeSource)
+	require.NoError(t, err)
+	assert.Regexp(t, `(?s)double.*action.*data`, out)
+
+	_, err = FormatSource(`contract Wrong { action { $a = 1 | 2 } }`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,44 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package script
+
+// JoinBlocks returns the root block which contains the contracts of the compiled roots, so they are
+// loaded into the virtual machine by one FlushBlock. The roots must be compiled for the same ecosystem
+func JoinBlocks(roots []*Block) *Block {
+	fragment := &Block{Objects: make(map[string]*ObjInfo)}
+	for _, root := range roots {
+		fragment.Info, fragment.Owner = root.Info, root.Owner
+		for key, item := range root.Objects {
+			fragment.Objects[key] = item
+		}
+		for _, item := range root.Children {
+			// the contract id is the index of the contract in the root
+			if info, ok := item.Info.(*ContractInfo); ok {
+				info.ID = uint32(len(fragment.Children))
+			}
+			item.Parent = fragment
+			fragment.Children = append(fragment.Children, item)
+		}
+	}
+	return fragment
+}
+
+// IsObject returns true if the name is the object of the virtual machine, the name is looked up
+// in the ecosystem too
+func (vm *VM) IsObject(name string, state uint32) bool {
+	return vm.getObjByNameExt(name, state) != nil
+}
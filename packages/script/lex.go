@@ -63,6 +63,7 @@ const (
 	isRCurly = 0x7d01 // }
 	isLBrack = 0x5b01 // [
 	isRBrack = 0x5d01 // ]
+	isColon  = 0x3a01 // :
 
 	// Constants for operations
 	isNot      = 0x0021 // !
@@ -107,6 +108,11 @@ const (
 	keyCond
 	keyTail
 	keyError
+	keySwitch
+	keyCase
+	keyDefault
+	keyConst
+	keyPrivate
 )
 
 const (
@@ -122,7 +128,8 @@ var (
 		msgInfo: keyInfo, `while`: keyWhile, `data`: keyTX, `settings`: keySettings, `nil`: keyNil,
 		`action`: keyAction, `conditions`: keyCond,
 		`true`: keyTrue, `false`: keyFalse, `break`: keyBreak, `continue`: keyContinue,
-		`var`: keyVar, `...`: keyTail}
+		`var`: keyVar, `...`: keyTail, `switch`: keySwitch, `case`: keyCase, `default`: keyDefault,
+		`const`: keyConst, `private`: keyPrivate}
 	// list of available types
 	// The list of types which save the corresponding 'reflect' type
 	types = map[string]reflect.Type{`bool`: reflect.TypeOf(true), `bytes`: reflect.TypeOf([]byte{}),
@@ -215,6 +222,7 @@ func lexParser(input []rune) (Lexems, error) {
 			if (flags & lexfNext) != 0 {
 				right++
 			}
+			afterIf := false
 			if len(ifbuf) > 0 && ifbuf[len(ifbuf)-1].stop && lexID != lexNewLine {
 				name := string(input[lexOff:right])
 				if name != `else` && name != `elif` {
@@ -225,6 +233,7 @@ func lexParser(input []rune) (Lexems, error) {
 					ifbuf = ifbuf[:len(ifbuf)-1]
 				} else {
 					ifbuf[len(ifbuf)-1].stop = false
+					afterIf = true
 				}
 			}
 			var value interface{}
@@ -288,18 +297,27 @@ func lexParser(input []rune) (Lexems, error) {
 				} else if keyID, ok := keywords[name]; ok {
 					switch keyID {
 					case keyIf:
-						ifbuf = append(ifbuf, ifBuf{})
+						// else if is the same as elif
+						if last := len(lexems) - 1; last >= 0 && lexems[last].Type == lexKeyword|(keyElse<<8) &&
+							len(ifbuf) > 0 && ifbuf[len(ifbuf)-1].pair == 0 {
+							lexems = append(lexems, &Lexem{lexSys | ('{' << 8), uint32('{'), line, lexOff - offline + 1})
+							ifbuf[len(ifbuf)-1].count++
+						} else {
+							ifbuf = append(ifbuf, ifBuf{})
+						}
 						lexID = lexKeyword | (keyID << 8)
 						value = keyID
 					case keyElif:
-						if len(ifbuf) > 0 {
-							lexems = append(lexems, &Lexem{lexKeyword | (keyElse << 8),
-								uint32(keyElse), line, lexOff - offline + 1},
-								&Lexem{lexSys | ('{' << 8), uint32('{'), line, lexOff - offline + 1})
-							lexID = lexKeyword | (keyIf << 8)
-							value = uint32(keyIf)
-							ifbuf[len(ifbuf)-1].count++
+						if !afterIf {
+							log.WithFields(log.Fields{"lex_line": line, "lex_col": lexOff - offline + 1, "type": consts.ParseError}).Error("elif without if")
+							return nil, fmt.Errorf(`elif without if [Ln:%d Col:%d]`, line, lexOff-offline+1)
 						}
+						lexems = append(lexems, &Lexem{lexKeyword | (keyElse << 8),
+							uint32(keyElse), line, lexOff - offline + 1},
+							&Lexem{lexSys | ('{' << 8), uint32('{'), line, lexOff - offline + 1})
+						lexID = lexKeyword | (keyIf << 8)
+						value = uint32(keyIf)
+						ifbuf[len(ifbuf)-1].count++
 					case keyAction, keyCond:
 						if len(lexems) > 0 {
 							lexf := *lexems[len(lexems)-1]
@@ -33,7 +33,7 @@ type States map[string]Action
 
 const (
 	// AlphaSize is the length of alphabet
-	AlphaSize = 33
+	AlphaSize = 34
 )
 
 /* Здесь мы определяем алфавит, с которым будет работать наш язык и описываем конечный автомат, который
@@ -50,7 +50,7 @@ var (
 	alphabet = []byte{0x01, 0x0a, ' ', '`', '"', ';', '(', ')', '[', ']', '{', '}', '&',
 		//           default  n    s    q    Q
 		'|', '#', '.', ',', '<', '>', '=', '!', '*', '$', '@',
-		'+', '-', '/', '\\', '0', '1', 'a', '_', 128}
+		'+', '-', '/', '\\', '0', '1', 'a', '_', ':', 128}
 	//													r
 
 	// В states мы обозначили за d - все символы, которые не указаны в состоянии
@@ -70,7 +70,7 @@ var (
 	states = `{
 	"main": {
 			"n;": ["main", "newline", "next"],
-			"()#[],{}": ["main", "sys", "next"],
+			"()#[],{}:": ["main", "sys", "next"],
 			"s": ["main", "", "next"],
 			"q": ["string", "", "push next"],
 			"Q": ["dstring", "", "push next"],
@@ -0,0 +1,1119 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package script
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+// CompilerVersion is the version of the compiler and of the format of the serialized byte-code.
+// It must be increased when the generated byte-code or the structures of the blocks are changed
+const CompilerVersion = 1
+
+var (
+	bytecodeMagic = []byte(`GBC`)
+
+	errBytecodeVersion = errors.New(`wrong version of the byte-code`)
+)
+
+// The kinds of the values of the byte-code commands
+const (
+	valNil = iota
+	valInt
+	valInt64
+	valUint16
+	valUint32
+	valFloat
+	valString
+	valBool
+	valDecimal
+	valBlock
+	valObj
+	valVar
+	valVars
+	valIndex
+	valSwitch
+	valFuncName
+)
+
+type valueData struct {
+	Kind     uint8
+	Int      int64
+	Float    float64
+	Str      string
+	Bool     bool
+	Block    int
+	Obj      *objRef
+	Vars     []varData
+	Index    *indexData
+	Switch   *switchData
+	FuncName *FuncNameCmd
+}
+
+// objRef refers to the object of the same unit by the block and the name or to the object of the VM
+type objRef struct {
+	Local    bool
+	Block    int
+	Name     string
+	Type     int
+	Variadic bool
+}
+
+type varData struct {
+	Type   int
+	Offset int
+	Extend string
+	Owner  int
+}
+
+type indexData struct {
+	VarOffset int
+	Owner     int
+	Extend    string
+}
+
+type caseData struct {
+	Value valueData
+	Block int
+}
+
+type switchData struct {
+	Type    string
+	Cases   []caseData
+	Default int
+}
+
+type fieldData struct {
+	Name string
+	Type string
+	Tags string
+}
+
+type settingData struct {
+	Name  string
+	Value valueData
+}
+
+type contractData struct {
+	ID       uint32
+	Name     string
+	Used     []string
+	HasTx    bool
+	Tx       []fieldData
+	Settings []settingData
+}
+
+type funcNameData struct {
+	Name     string
+	Params   []string
+	Offset   []int
+	Variadic bool
+}
+
+type funcData struct {
+	Name     string
+	Params   []string
+	Results  []string
+	HasNames bool
+	Names    []funcNameData
+	Variadic bool
+	ID       uint32
+	Private  bool
+}
+
+type objData struct {
+	Name      string
+	Type      int
+	Block     int
+	Var       int
+	ConstType string
+	Const     valueData
+}
+
+type codeData struct {
+	Cmd   uint16
+	Value valueData
+}
+
+type blockData struct {
+	Type       int
+	Parent     int
+	HasOwner   bool
+	State      uint32
+	Contract   *contractData
+	Func       *funcData
+	HasObjects bool
+	Objects    []objData
+	Vars       []string
+	Code       []codeData
+	Children   []int
+	Lines      []LineInfo
+}
+
+// typeNames binds the names of the types to the types which can be used in the byte-code
+var typeNames = func() map[string]reflect.Type {
+	ret := map[string]reflect.Type{``: nil}
+	for _, itype := range types {
+		ret[itype.String()] = itype
+	}
+	return ret
+}()
+
+func typeName(itype reflect.Type) string {
+	if itype == nil {
+		return ``
+	}
+	return itype.String()
+}
+
+func typeByName(name string) (reflect.Type, error) {
+	itype, ok := typeNames[name]
+	if !ok {
+		return nil, fmt.Errorf(`unknown type %s`, name)
+	}
+	return itype, nil
+}
+
+func typeNameList(list []reflect.Type) []string {
+	ret := make([]string, len(list))
+	for i, itype := range list {
+		ret[i] = typeName(itype)
+	}
+	return ret
+}
+
+func typeList(names []string) ([]reflect.Type, error) {
+	var err error
+	if len(names) == 0 {
+		return nil, nil
+	}
+	ret := make([]reflect.Type, len(names))
+	for i, name := range names {
+		if ret[i], err = typeByName(name); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+type encoder struct {
+	vm     *VM
+	blocks []*Block
+	index  map[*Block]int
+	locals map[*ObjInfo]objRef
+}
+
+func (e *encoder) addBlock(block *Block) {
+	e.index[block] = len(e.blocks)
+	e.blocks = append(e.blocks, block)
+	for _, child := range block.Children {
+		e.addBlock(child)
+	}
+}
+
+func (e *encoder) blockIndex(block *Block) (int, error) {
+	if block == nil {
+		return -1, nil
+	}
+	ind, ok := e.index[block]
+	if !ok {
+		return 0, fmt.Errorf(`block is outside of the unit`)
+	}
+	return ind, nil
+}
+
+func (e *encoder) objRef(obj *ObjInfo) (*objRef, error) {
+	if ref, ok := e.locals[obj]; ok {
+		return &ref, nil
+	}
+	ref := objRef{Type: obj.Type}
+	switch obj.Type {
+	case ObjExtFunc:
+		ref.Name = obj.Value.(ExtFuncInfo).Name
+		ref.Variadic = obj.Value.(ExtFuncInfo).Variadic
+	case ObjFunc:
+		ref.Name = obj.Value.(*Block).Info.(*FuncInfo).Name
+		ref.Variadic = obj.Value.(*Block).Info.(*FuncInfo).Variadic
+	default:
+		return nil, fmt.Errorf(`unsupported object type %d`, obj.Type)
+	}
+	if e.vm.Objects[ref.Name] != obj {
+		return nil, fmt.Errorf(`unknown object %s`, ref.Name)
+	}
+	return &ref, nil
+}
+
+func (e *encoder) value(val interface{}) (ret valueData, err error) {
+	switch v := val.(type) {
+	case nil:
+		ret.Kind = valNil
+	case int:
+		ret.Kind, ret.Int = valInt, int64(v)
+	case int64:
+		ret.Kind, ret.Int = valInt64, v
+	case uint16:
+		ret.Kind, ret.Int = valUint16, int64(v)
+	case uint32:
+		ret.Kind, ret.Int = valUint32, int64(v)
+	case float64:
+		ret.Kind, ret.Float = valFloat, v
+	case string:
+		ret.Kind, ret.Str = valString, v
+	case bool:
+		ret.Kind, ret.Bool = valBool, v
+	case decimal.Decimal:
+		ret.Kind, ret.Str = valDecimal, v.String()
+	case *Block:
+		ret.Kind = valBlock
+		ret.Block, err = e.blockIndex(v)
+	case *ObjInfo:
+		ret.Kind = valObj
+		ret.Obj, err = e.objRef(v)
+	case *VarInfo:
+		ret.Kind = valVar
+		ret.Vars = make([]varData, 1)
+		ret.Vars[0], err = e.varInfo(v)
+	case []*VarInfo:
+		ret.Kind = valVars
+		ret.Vars = make([]varData, len(v))
+		for i, ivar := range v {
+			if ret.Vars[i], err = e.varInfo(ivar); err != nil {
+				break
+			}
+		}
+	case *IndexInfo:
+		ret.Kind = valIndex
+		ret.Index = &indexData{VarOffset: v.VarOffset, Extend: v.Extend}
+		ret.Index.Owner, err = e.blockIndex(v.Owner)
+	case *SwitchInfo:
+		ret.Kind = valSwitch
+		ret.Switch, err = e.switchInfo(v)
+	case FuncNameCmd:
+		ret.Kind = valFuncName
+		ret.FuncName = &v
+	default:
+		err = fmt.Errorf(`unsupported value type %T`, val)
+	}
+	return
+}
+
+func (e *encoder) varInfo(ivar *VarInfo) (ret varData, err error) {
+	ret.Type = ivar.Obj.Type
+	switch ivar.Obj.Type {
+	case ObjVar:
+		ret.Offset = ivar.Obj.Value.(int)
+	case ObjExtend:
+		ret.Extend = ivar.Obj.Value.(string)
+	default:
+		return ret, fmt.Errorf(`unsupported variable type %d`, ivar.Obj.Type)
+	}
+	ret.Owner, err = e.blockIndex(ivar.Owner)
+	return
+}
+
+func (e *encoder) switchInfo(info *SwitchInfo) (*switchData, error) {
+	var err error
+	ret := &switchData{Type: typeName(info.Type), Cases: make([]caseData, 0, len(info.Cases))}
+	for val, block := range info.Cases {
+		var item caseData
+		if item.Value, err = e.value(val); err != nil {
+			return nil, err
+		}
+		if item.Block, err = e.blockIndex(block); err != nil {
+			return nil, err
+		}
+		ret.Cases = append(ret.Cases, item)
+	}
+	// the cases are sorted to get the same byte-code for the same source
+	sort.Slice(ret.Cases, func(i, j int) bool {
+		if ret.Cases[i].Value.Kind == valInt64 {
+			return ret.Cases[i].Value.Int < ret.Cases[j].Value.Int
+		}
+		return ret.Cases[i].Value.Str < ret.Cases[j].Value.Str
+	})
+	if ret.Default, err = e.blockIndex(info.Default); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (e *encoder) block(block *Block) (ret blockData, err error) {
+	ret = blockData{Type: block.Type, HasOwner: block.Owner != nil, HasObjects: block.Objects != nil,
+		Vars: typeNameList(block.Vars), Children: make([]int, len(block.Children)), Lines: block.Lines}
+	if ret.Parent, err = e.blockIndex(block.Parent); err != nil {
+		return
+	}
+	switch info := block.Info.(type) {
+	case uint32:
+		ret.State = info
+	case *ContractInfo:
+		ret.Contract = &contractData{ID: info.ID, Name: info.Name, HasTx: info.Tx != nil}
+		for name := range info.Used {
+			ret.Contract.Used = append(ret.Contract.Used, name)
+		}
+		sort.Strings(ret.Contract.Used)
+		if info.Tx != nil {
+			for _, field := range *info.Tx {
+				ret.Contract.Tx = append(ret.Contract.Tx, fieldData{Name: field.Name,
+					Type: typeName(field.Type), Tags: field.Tags})
+			}
+		}
+		for name, val := range info.Settings {
+			item := settingData{Name: name}
+			if item.Value, err = e.value(val); err != nil {
+				return
+			}
+			ret.Contract.Settings = append(ret.Contract.Settings, item)
+		}
+		sort.Slice(ret.Contract.Settings, func(i, j int) bool {
+			return ret.Contract.Settings[i].Name < ret.Contract.Settings[j].Name
+		})
+	case *FuncInfo:
+		ret.Func = &funcData{Name: info.Name, Params: typeNameList(info.Params),
+			Results: typeNameList(info.Results), HasNames: info.Names != nil, Variadic: info.Variadic,
+			ID: info.ID, Private: info.Private}
+		if info.Names != nil {
+			names := make([]string, 0, len(*info.Names))
+			for name := range *info.Names {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fname := (*info.Names)[name]
+				ret.Func.Names = append(ret.Func.Names, funcNameData{Name: name,
+					Params: typeNameList(fname.Params), Offset: fname.Offset, Variadic: fname.Variadic})
+			}
+		}
+	case nil:
+	default:
+		return ret, fmt.Errorf(`unsupported block info %T`, block.Info)
+	}
+	names := make([]string, 0, len(block.Objects))
+	for name := range block.Objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		obj := block.Objects[name]
+		item := objData{Name: name, Type: obj.Type}
+		switch obj.Type {
+		case ObjContract, ObjFunc:
+			item.Block, err = e.blockIndex(obj.Value.(*Block))
+		case ObjVar:
+			item.Var = obj.Value.(int)
+		case ObjConst:
+			item.ConstType = typeName(obj.Value.(*ConstInfo).Type)
+			item.Const, err = e.value(obj.Value.(*ConstInfo).Value)
+		default:
+			err = fmt.Errorf(`unsupported object type %d`, obj.Type)
+		}
+		if err != nil {
+			return
+		}
+		ret.Objects = append(ret.Objects, item)
+	}
+	ret.Code = make([]codeData, len(block.Code))
+	for i, cmd := range block.Code {
+		ret.Code[i].Cmd = cmd.Cmd
+		if ret.Code[i].Value, err = e.value(cmd.Value); err != nil {
+			return
+		}
+	}
+	for i, child := range block.Children {
+		ret.Children[i] = e.index[child]
+	}
+	return
+}
+
+// EncodeBlock serializes the compiled Block to the binary format. The Block must not be loaded
+// into the virtual machine with FlushBlock yet
+func (vm *VM) EncodeBlock(root *Block) ([]byte, error) {
+	e := &encoder{vm: vm, index: make(map[*Block]int), locals: make(map[*ObjInfo]objRef)}
+	e.addBlock(root)
+	for i, block := range e.blocks {
+		for name, obj := range block.Objects {
+			e.locals[obj] = objRef{Local: true, Block: i, Name: name, Type: obj.Type}
+		}
+	}
+	data := make([]blockData, len(e.blocks))
+	for i, block := range e.blocks {
+		var err error
+		if data[i], err = e.block(block); err != nil {
+			log.WithFields(log.Fields{"type": consts.VMError, "error": err}).Error("encoding byte-code")
+			return nil, err
+		}
+	}
+	w := &writer{}
+	w.buf.Write(bytecodeMagic)
+	binary.Write(&w.buf, binary.BigEndian, uint32(CompilerVersion))
+	w.uint(uint64(len(data)))
+	for i := range data {
+		w.block(&data[i])
+	}
+	return w.buf.Bytes(), nil
+}
+
+type decoder struct {
+	vm     *VM
+	data   []blockData
+	blocks []*Block
+}
+
+func (d *decoder) block(ind int) (*Block, error) {
+	if ind == -1 {
+		return nil, nil
+	}
+	if ind < 0 || ind >= len(d.blocks) {
+		return nil, fmt.Errorf(`wrong index of block %d`, ind)
+	}
+	return d.blocks[ind], nil
+}
+
+func (d *decoder) objRef(ref *objRef) (*ObjInfo, error) {
+	if ref == nil {
+		return nil, fmt.Errorf(`empty object`)
+	}
+	if ref.Local {
+		block, err := d.block(ref.Block)
+		if err != nil || block == nil {
+			return nil, fmt.Errorf(`wrong block of object %s`, ref.Name)
+		}
+		if obj, ok := block.Objects[ref.Name]; ok && obj.Type == ref.Type {
+			return obj, nil
+		}
+		return nil, fmt.Errorf(`unknown object %s`, ref.Name)
+	}
+	obj, ok := d.vm.Objects[ref.Name]
+	if !ok || obj.Type != ref.Type {
+		return nil, fmt.Errorf(`unknown object %s`, ref.Name)
+	}
+	// the byte-code of the call depends on the definition of the called function
+	switch obj.Type {
+	case ObjExtFunc:
+		ok = obj.Value.(ExtFuncInfo).Variadic == ref.Variadic
+	case ObjFunc:
+		ok = obj.Value.(*Block).Info.(*FuncInfo).Variadic == ref.Variadic
+	}
+	if !ok {
+		return nil, fmt.Errorf(`object %s has been changed`, ref.Name)
+	}
+	return obj, nil
+}
+
+func (d *decoder) varInfo(data varData) (*VarInfo, error) {
+	owner, err := d.block(data.Owner)
+	if err != nil {
+		return nil, err
+	}
+	switch data.Type {
+	case ObjVar:
+		return &VarInfo{&ObjInfo{ObjVar, data.Offset}, owner}, nil
+	case ObjExtend:
+		return &VarInfo{&ObjInfo{ObjExtend, data.Extend}, owner}, nil
+	}
+	return nil, fmt.Errorf(`unsupported variable type %d`, data.Type)
+}
+
+func (d *decoder) value(data valueData) (ret interface{}, err error) {
+	switch data.Kind {
+	case valNil:
+	case valInt:
+		ret = int(data.Int)
+	case valInt64:
+		ret = data.Int
+	case valUint16:
+		ret = uint16(data.Int)
+	case valUint32:
+		ret = uint32(data.Int)
+	case valFloat:
+		ret = data.Float
+	case valString:
+		ret = data.Str
+	case valBool:
+		ret = data.Bool
+	case valDecimal:
+		ret, err = decimal.NewFromString(data.Str)
+	case valBlock:
+		var block *Block
+		if block, err = d.block(data.Block); err == nil {
+			ret = block
+		}
+	case valObj:
+		var obj *ObjInfo
+		if obj, err = d.objRef(data.Obj); err == nil {
+			ret = obj
+		}
+	case valVar:
+		if len(data.Vars) != 1 {
+			return nil, fmt.Errorf(`wrong variable`)
+		}
+		var ivar *VarInfo
+		if ivar, err = d.varInfo(data.Vars[0]); err == nil {
+			ret = ivar
+		}
+	case valVars:
+		vars := make([]*VarInfo, len(data.Vars))
+		for i, item := range data.Vars {
+			if vars[i], err = d.varInfo(item); err != nil {
+				return
+			}
+		}
+		ret = vars
+	case valIndex:
+		if data.Index == nil {
+			return nil, fmt.Errorf(`empty index`)
+		}
+		info := &IndexInfo{VarOffset: data.Index.VarOffset, Extend: data.Index.Extend}
+		if info.Owner, err = d.block(data.Index.Owner); err == nil {
+			ret = info
+		}
+	case valSwitch:
+		var info *SwitchInfo
+		if info, err = d.switchInfo(data.Switch); err == nil {
+			ret = info
+		}
+	case valFuncName:
+		if data.FuncName == nil {
+			return nil, fmt.Errorf(`empty function name`)
+		}
+		ret = *data.FuncName
+	default:
+		err = fmt.Errorf(`unknown kind of value %d`, data.Kind)
+	}
+	return
+}
+
+func (d *decoder) switchInfo(data *switchData) (info *SwitchInfo, err error) {
+	if data == nil {
+		return nil, fmt.Errorf(`empty switch`)
+	}
+	info = &SwitchInfo{Cases: make(map[interface{}]*Block)}
+	if info.Type, err = typeByName(data.Type); err != nil {
+		return
+	}
+	for _, item := range data.Cases {
+		var val interface{}
+		if val, err = d.value(item.Value); err != nil {
+			return
+		}
+		if info.Cases[val], err = d.block(item.Block); err != nil {
+			return
+		}
+	}
+	info.Default, err = d.block(data.Default)
+	return
+}
+
+func (d *decoder) info(data blockData, owner *OwnerInfo) (interface{}, error) {
+	var err error
+	switch {
+	case data.Contract != nil:
+		info := &ContractInfo{ID: data.Contract.ID, Name: data.Contract.Name, Owner: owner}
+		if len(data.Contract.Used) > 0 {
+			info.Used = make(map[string]bool)
+			for _, name := range data.Contract.Used {
+				info.Used[name] = true
+			}
+		}
+		if data.Contract.HasTx {
+			tx := make([]*FieldInfo, len(data.Contract.Tx))
+			for i, field := range data.Contract.Tx {
+				tx[i] = &FieldInfo{Name: field.Name, Tags: field.Tags}
+				if tx[i].Type, err = typeByName(field.Type); err != nil {
+					return nil, err
+				}
+			}
+			info.Tx = &tx
+		}
+		if data.Contract.Settings != nil {
+			info.Settings = make(map[string]interface{})
+			for _, item := range data.Contract.Settings {
+				if info.Settings[item.Name], err = d.value(item.Value); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return info, nil
+	case data.Func != nil:
+		info := &FuncInfo{Name: data.Func.Name, Variadic: data.Func.Variadic, ID: data.Func.ID,
+			Private: data.Func.Private}
+		if info.Params, err = typeList(data.Func.Params); err != nil {
+			return nil, err
+		}
+		if info.Results, err = typeList(data.Func.Results); err != nil {
+			return nil, err
+		}
+		if data.Func.HasNames {
+			names := make(map[string]FuncName)
+			for _, item := range data.Func.Names {
+				fname := FuncName{Offset: item.Offset, Variadic: item.Variadic}
+				if fname.Params, err = typeList(item.Params); err != nil {
+					return nil, err
+				}
+				names[item.Name] = fname
+			}
+			info.Names = &names
+		}
+		return info, nil
+	case data.Parent == -1:
+		return data.State, nil
+	}
+	return nil, nil
+}
+
+func (d *decoder) decode(owner *OwnerInfo) (*Block, error) {
+	var err error
+	d.blocks = make([]*Block, len(d.data))
+	for i := range d.data {
+		d.blocks[i] = &Block{}
+	}
+	for i, data := range d.data {
+		block := d.blocks[i]
+		block.Type = data.Type
+		block.Lines = data.Lines
+		if data.HasOwner {
+			block.Owner = owner
+		}
+		if block.Parent, err = d.block(data.Parent); err != nil {
+			return nil, err
+		}
+		if block.Info, err = d.info(data, owner); err != nil {
+			return nil, err
+		}
+		if block.Vars, err = typeList(data.Vars); err != nil {
+			return nil, err
+		}
+		if len(data.Children) > 0 {
+			block.Children = make(Blocks, len(data.Children))
+			for j, ind := range data.Children {
+				if block.Children[j], err = d.block(ind); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if data.HasObjects {
+			block.Objects = make(map[string]*ObjInfo)
+		}
+		for _, item := range data.Objects {
+			obj := &ObjInfo{Type: item.Type}
+			switch item.Type {
+			case ObjContract, ObjFunc:
+				var fblock *Block
+				if fblock, err = d.block(item.Block); err != nil || fblock == nil {
+					return nil, fmt.Errorf(`wrong block of object %s`, item.Name)
+				}
+				obj.Value = fblock
+			case ObjVar:
+				obj.Value = item.Var
+			case ObjConst:
+				cinfo := &ConstInfo{}
+				if cinfo.Type, err = typeByName(item.ConstType); err != nil {
+					return nil, err
+				}
+				if cinfo.Value, err = d.value(item.Const); err != nil {
+					return nil, err
+				}
+				obj.Value = cinfo
+			default:
+				return nil, fmt.Errorf(`unsupported object type %d`, item.Type)
+			}
+			block.Objects[item.Name] = obj
+		}
+	}
+	// the code is restored after all objects because it refers to them
+	for i, data := range d.data {
+		block := d.blocks[i]
+		for _, cmd := range data.Code {
+			code := &ByteCode{Cmd: cmd.Cmd}
+			if code.Value, err = d.value(cmd.Value); err != nil {
+				return nil, err
+			}
+			block.Code = append(block.Code, code)
+		}
+	}
+	if d.blocks[0].Parent != nil {
+		return nil, fmt.Errorf(`wrong root block`)
+	}
+	return d.blocks[0], nil
+}
+
+// DecodeBlock restores the Block serialized with EncodeBlock. The objects of the virtual
+// machine which are used by the byte-code must be defined with the same parameters as at the
+// serialization. The returned Block must be loaded into the virtual machine with FlushBlock
+func (vm *VM) DecodeBlock(data []byte, owner *OwnerInfo) (root *Block, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			root, err = nil, fmt.Errorf(`corrupted byte-code: %v`, r)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.VMError, "error": err}).Warning("decoding byte-code")
+		}
+	}()
+	header := len(bytecodeMagic) + 4
+	if len(data) < header || !bytes.Equal(data[:len(bytecodeMagic)], bytecodeMagic) ||
+		binary.BigEndian.Uint32(data[len(bytecodeMagic):header]) != CompilerVersion {
+		return nil, errBytecodeVersion
+	}
+	r := &reader{data: data[header:]}
+	d := &decoder{vm: vm, data: make([]blockData, r.len())}
+	for i := range d.data {
+		r.block(&d.data[i])
+	}
+	if len(d.data) == 0 || len(r.data) > 0 {
+		return nil, fmt.Errorf(`corrupted byte-code`)
+	}
+	return d.decode(owner)
+}
+
+// writer writes the intermediate structures of the unit in the compact binary format
+type writer struct {
+	buf bytes.Buffer
+	tmp [binary.MaxVarintLen64]byte
+}
+
+func (w *writer) uint(v uint64) {
+	w.buf.Write(w.tmp[:binary.PutUvarint(w.tmp[:], v)])
+}
+
+func (w *writer) int(v int64) {
+	w.buf.Write(w.tmp[:binary.PutVarint(w.tmp[:], v)])
+}
+
+func (w *writer) bool(v bool) {
+	if v {
+		w.buf.WriteByte(1)
+	} else {
+		w.buf.WriteByte(0)
+	}
+}
+
+func (w *writer) str(v string) {
+	w.uint(uint64(len(v)))
+	w.buf.WriteString(v)
+}
+
+func (w *writer) strs(list []string) {
+	w.uint(uint64(len(list)))
+	for _, v := range list {
+		w.str(v)
+	}
+}
+
+func (w *writer) value(v *valueData) {
+	w.buf.WriteByte(v.Kind)
+	w.int(v.Int)
+	w.uint(math.Float64bits(v.Float))
+	w.str(v.Str)
+	w.bool(v.Bool)
+	w.int(int64(v.Block))
+	w.bool(v.Obj != nil)
+	if v.Obj != nil {
+		w.bool(v.Obj.Local)
+		w.int(int64(v.Obj.Block))
+		w.str(v.Obj.Name)
+		w.int(int64(v.Obj.Type))
+		w.bool(v.Obj.Variadic)
+	}
+	w.uint(uint64(len(v.Vars)))
+	for _, item := range v.Vars {
+		w.int(int64(item.Type))
+		w.int(int64(item.Offset))
+		w.str(item.Extend)
+		w.int(int64(item.Owner))
+	}
+	w.bool(v.Index != nil)
+	if v.Index != nil {
+		w.int(int64(v.Index.VarOffset))
+		w.int(int64(v.Index.Owner))
+		w.str(v.Index.Extend)
+	}
+	w.bool(v.Switch != nil)
+	if v.Switch != nil {
+		w.str(v.Switch.Type)
+		w.uint(uint64(len(v.Switch.Cases)))
+		for i := range v.Switch.Cases {
+			w.value(&v.Switch.Cases[i].Value)
+			w.int(int64(v.Switch.Cases[i].Block))
+		}
+		w.int(int64(v.Switch.Default))
+	}
+	w.bool(v.FuncName != nil)
+	if v.FuncName != nil {
+		w.str(v.FuncName.Name)
+		w.int(int64(v.FuncName.Count))
+	}
+}
+
+func (w *writer) block(b *blockData) {
+	w.int(int64(b.Type))
+	w.int(int64(b.Parent))
+	w.bool(b.HasOwner)
+	w.uint(uint64(b.State))
+	w.bool(b.Contract != nil)
+	if c := b.Contract; c != nil {
+		w.uint(uint64(c.ID))
+		w.str(c.Name)
+		w.strs(c.Used)
+		w.bool(c.HasTx)
+		w.uint(uint64(len(c.Tx)))
+		for _, field := range c.Tx {
+			w.str(field.Name)
+			w.str(field.Type)
+			w.str(field.Tags)
+		}
+		w.uint(uint64(len(c.Settings)))
+		for i := range c.Settings {
+			w.str(c.Settings[i].Name)
+			w.value(&c.Settings[i].Value)
+		}
+	}
+	w.bool(b.Func != nil)
+	if f := b.Func; f != nil {
+		w.str(f.Name)
+		w.strs(f.Params)
+		w.strs(f.Results)
+		w.bool(f.HasNames)
+		w.uint(uint64(len(f.Names)))
+		for _, fname := range f.Names {
+			w.str(fname.Name)
+			w.strs(fname.Params)
+			w.uint(uint64(len(fname.Offset)))
+			for _, offset := range fname.Offset {
+				w.int(int64(offset))
+			}
+			w.bool(fname.Variadic)
+		}
+		w.bool(f.Variadic)
+		w.uint(uint64(f.ID))
+		w.bool(f.Private)
+	}
+	w.bool(b.HasObjects)
+	w.uint(uint64(len(b.Objects)))
+	for i := range b.Objects {
+		obj := &b.Objects[i]
+		w.str(obj.Name)
+		w.int(int64(obj.Type))
+		w.int(int64(obj.Block))
+		w.int(int64(obj.Var))
+		w.str(obj.ConstType)
+		w.value(&obj.Const)
+	}
+	w.strs(b.Vars)
+	w.uint(uint64(len(b.Code)))
+	for i := range b.Code {
+		w.uint(uint64(b.Code[i].Cmd))
+		w.value(&b.Code[i].Value)
+	}
+	w.uint(uint64(len(b.Children)))
+	for _, child := range b.Children {
+		w.int(int64(child))
+	}
+	w.uint(uint64(len(b.Lines)))
+	for _, line := range b.Lines {
+		w.uint(uint64(line.Offset))
+		w.uint(uint64(line.Line))
+		w.uint(uint64(line.Column))
+	}
+}
+
+// reader reads the data written by writer. It panics if the data is corrupted,
+// the panic is recovered by DecodeBlock
+type reader struct {
+	data []byte
+}
+
+func (r *reader) uint() uint64 {
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		panic(`wrong uvarint`)
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *reader) int() int64 {
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		panic(`wrong varint`)
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *reader) byte() byte {
+	v := r.data[0]
+	r.data = r.data[1:]
+	return v
+}
+
+func (r *reader) bool() bool {
+	return r.byte() != 0
+}
+
+// len reads the length of the list, every item of the list takes at least one byte
+func (r *reader) len() int {
+	v := r.uint()
+	if v > uint64(len(r.data)) {
+		panic(`wrong length`)
+	}
+	return int(v)
+}
+
+func (r *reader) str() string {
+	size := r.len()
+	v := string(r.data[:size])
+	r.data = r.data[size:]
+	return v
+}
+
+func (r *reader) strs() []string {
+	size := r.len()
+	if size == 0 {
+		return nil
+	}
+	list := make([]string, size)
+	for i := range list {
+		list[i] = r.str()
+	}
+	return list
+}
+
+func (r *reader) value(v *valueData) {
+	v.Kind = r.byte()
+	v.Int = r.int()
+	v.Float = math.Float64frombits(r.uint())
+	v.Str = r.str()
+	v.Bool = r.bool()
+	v.Block = int(r.int())
+	if r.bool() {
+		v.Obj = &objRef{Local: r.bool(), Block: int(r.int()), Name: r.str(), Type: int(r.int()),
+			Variadic: r.bool()}
+	}
+	if size := r.len(); size > 0 {
+		v.Vars = make([]varData, size)
+		for i := range v.Vars {
+			v.Vars[i] = varData{Type: int(r.int()), Offset: int(r.int()), Extend: r.str(), Owner: int(r.int())}
+		}
+	}
+	if r.bool() {
+		v.Index = &indexData{VarOffset: int(r.int()), Owner: int(r.int()), Extend: r.str()}
+	}
+	if r.bool() {
+		v.Switch = &switchData{Type: r.str()}
+		if size := r.len(); size > 0 {
+			v.Switch.Cases = make([]caseData, size)
+			for i := range v.Switch.Cases {
+				r.value(&v.Switch.Cases[i].Value)
+				v.Switch.Cases[i].Block = int(r.int())
+			}
+		}
+		v.Switch.Default = int(r.int())
+	}
+	if r.bool() {
+		v.FuncName = &FuncNameCmd{Name: r.str(), Count: int(r.int())}
+	}
+}
+
+func (r *reader) block(b *blockData) {
+	b.Type = int(r.int())
+	b.Parent = int(r.int())
+	b.HasOwner = r.bool()
+	b.State = uint32(r.uint())
+	if r.bool() {
+		c := &contractData{ID: uint32(r.uint()), Name: r.str(), Used: r.strs(), HasTx: r.bool()}
+		if size := r.len(); size > 0 {
+			c.Tx = make([]fieldData, size)
+			for i := range c.Tx {
+				c.Tx[i] = fieldData{Name: r.str(), Type: r.str(), Tags: r.str()}
+			}
+		}
+		if size := r.len(); size > 0 {
+			c.Settings = make([]settingData, size)
+			for i := range c.Settings {
+				c.Settings[i].Name = r.str()
+				r.value(&c.Settings[i].Value)
+			}
+		}
+		b.Contract = c
+	}
+	if r.bool() {
+		f := &funcData{Name: r.str(), Params: r.strs(), Results: r.strs(), HasNames: r.bool()}
+		if size := r.len(); size > 0 {
+			f.Names = make([]funcNameData, size)
+			for i := range f.Names {
+				fname := &f.Names[i]
+				fname.Name = r.str()
+				fname.Params = r.strs()
+				if size := r.len(); size > 0 {
+					fname.Offset = make([]int, size)
+					for j := range fname.Offset {
+						fname.Offset[j] = int(r.int())
+					}
+				}
+				fname.Variadic = r.bool()
+			}
+		}
+		f.Variadic = r.bool()
+		f.ID = uint32(r.uint())
+		f.Private = r.bool()
+		b.Func = f
+	}
+	b.HasObjects = r.bool()
+	if size := r.len(); size > 0 {
+		b.Objects = make([]objData, size)
+		for i := range b.Objects {
+			obj := &b.Objects[i]
+			obj.Name = r.str()
+			obj.Type = int(r.int())
+			obj.Block = int(r.int())
+			obj.Var = int(r.int())
+			obj.ConstType = r.str()
+			r.value(&obj.Const)
+		}
+	}
+	b.Vars = r.strs()
+	if size := r.len(); size > 0 {
+		b.Code = make([]codeData, size)
+		for i := range b.Code {
+			b.Code[i].Cmd = uint16(r.uint())
+			r.value(&b.Code[i].Value)
+		}
+	}
+	if size := r.len(); size > 0 {
+		b.Children = make([]int, size)
+		for i := range b.Children {
+			b.Children[i] = int(r.int())
+		}
+	}
+	if size := r.len(); size > 0 {
+		b.Lines = make([]LineInfo, size)
+		for i := range b.Lines {
+			b.Lines[i] = LineInfo{Offset: uint32(r.uint()), Line: uint32(r.uint()), Column: uint32(r.uint())}
+		}
+	}
+}
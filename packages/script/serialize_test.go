@@ -0,0 +1,116 @@
+package script
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const serializeSource = `func DBFind(table string).Columns(columns string).Where(where string, params ...) string {
+		return Sprintf("%s %s %s %v", table, columns, where, params)
+	}
+	contract Serialize {
+		data {
+			Name string
+			Amount money "optional"
+		}
+		settings {
+			rate = 1.5
+		}
+		const {
+			Limit = 3
+			Fee money = "100"
+		}
+		private func list(count int) array {
+			var ret array
+			var i int
+			while i < count {
+				ret[i] = {"id": i, "tags": ["a", $Name]}
+				i = i + 1
+			}
+			return ret
+		}
+		func kind(val int) string {
+			switch val
+			case 0 {
+				return "zero"
+			}
+			case 1, 2 {
+				return "small"
+			}
+			default {
+				return "big"
+			}
+			return ""
+		}
+		action {
+			var out string
+			var i int
+			while i < Limit {
+				if i == 1 {
+					i = i + 1
+					continue
+				} elif i > 10 {
+					break
+				} else {
+					out = out + kind(i) + " "
+				}
+				i = i + 1
+			}
+			$result = out + Sprintf("%v %v %v ", list(2), Fee, Settings("@1Serialize", "rate")) +
+				DBFind("keys").Columns("id").Where("id=?", 1)
+		}
+	}`
+
+func TestEncodeBlock(t *testing.T) {
+	vm := NewVM()
+	vm.Extend(&ExtendData{map[string]interface{}{"Sprintf": fmt.Sprintf}, nil})
+	owner := &OwnerInfo{StateID: 1, Active: true, TableID: 1}
+
+	root, err := vm.CompileBlock([]rune(serializeSource), owner)
+	require.NoError(t, err)
+	data, err := vm.EncodeBlock(root)
+	require.NoError(t, err)
+
+	decoded, err := vm.DecodeBlock(data, owner)
+	require.NoError(t, err)
+	redata, err := vm.EncodeBlock(decoded)
+	require.NoError(t, err)
+	assert.Equal(t, data, redata)
+
+	vm.FlushBlock(decoded)
+	contract := vm.getObjByName(`@1Serialize`).Value.(*Block)
+	extend := map[string]interface{}{`Name`: `name`}
+	_, err = vm.RunInit(CostDefault).Run(contract.Objects[`action`].Value.(*Block), nil, &extend)
+	require.NoError(t, err)
+	assert.Equal(t, `zero small [map[id:0 tags:[a name]] map[id:1 tags:[a name]]] 100 1.5 keys id id=? [1]`,
+		extend[`result`])
+}
+
+func TestDecodeBlockErrors(t *testing.T) {
+	vm := NewVM()
+	vm.Extend(&ExtendData{map[string]interface{}{"Sprintf": fmt.Sprintf}, nil})
+	owner := &OwnerInfo{StateID: 1, Active: true, TableID: 1}
+
+	root, err := vm.CompileBlock([]rune(serializeSource), owner)
+	require.NoError(t, err)
+	data, err := vm.EncodeBlock(root)
+	require.NoError(t, err)
+
+	_, err = vm.DecodeBlock(data[:len(data)/2], owner)
+	assert.Error(t, err)
+
+	old := append([]byte{}, data...)
+	old[len(bytecodeMagic)+3]++
+	_, err = vm.DecodeBlock(old, owner)
+	assert.Equal(t, errBytecodeVersion, err)
+
+	// the byte-code can't be used if the called function is changed
+	vm.Extend(&ExtendData{map[string]interface{}{"Sprintf": func(format string) string {
+		return format
+	}}, nil})
+	_, err = vm.DecodeBlock(data, owner)
+	assert.EqualError(t, err, `object Sprintf has been changed`)
+}
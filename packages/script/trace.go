@@ -0,0 +1,79 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+package script
+
+import "reflect"
+
+// Tracer receives the steps of the execution of the byte-code. The hooks are compiled only
+// with the vmtrace build tag, otherwise TraceEnabled is false and the tracer is never called
+type Tracer interface {
+	// Step is called when the execution goes to the new line of the source code
+	Step(contract, function string, line uint32, cost int64)
+	// Assign is called when the value is assigned to the variable
+	Assign(name string, value interface{})
+	// Call is called when the extend function returns
+	Call(name string, params, results []interface{}, err error)
+}
+
+// TraceEnabled is true if the virtual machine is compiled with the trace hooks
+const TraceEnabled = traceEnabled
+
+// SetTracer sets the tracer of the execution, the nested runtimes inherit it
+func (rt *RunTime) SetTracer(tracer Tracer) {
+	rt.tracer = tracer
+}
+
+func (rt *RunTime) traceStep(bs *blockStack) {
+	line, _ := bs.Block.Position(bs.Pos)
+	if line == rt.traceLine && bs.Block == rt.traceBlock {
+		return
+	}
+	rt.traceLine, rt.traceBlock = line, bs.Block
+	contract, function := bs.Block.Location()
+	rt.tracer.Step(contract, function, line, rt.cost)
+}
+
+func (rt *RunTime) traceAssign(item *VarInfo, value interface{}) {
+	if item.Owner == nil {
+		rt.tracer.Assign(`$`+item.Obj.Value.(string), value)
+		return
+	}
+	for name, obj := range item.Owner.Objects {
+		if obj == item.Obj {
+			rt.tracer.Assign(name, value)
+			return
+		}
+	}
+}
+
+func (rt *RunTime) traceCall(name string, pars, result []reflect.Value) {
+	var err error
+	params := make([]interface{}, len(pars))
+	for i, par := range pars {
+		if par.IsValid() && par.CanInterface() {
+			params[i] = par.Interface()
+		}
+	}
+	results := make([]interface{}, 0, len(result))
+	for _, ret := range result {
+		if ret.Type().String() != `error` {
+			results = append(results, ret.Interface())
+		} else if ret.Interface() != nil {
+			err = ret.Interface().(error)
+		}
+	}
+	rt.tracer.Call(name, params, results, err)
+}
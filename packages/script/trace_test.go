@@ -0,0 +1,64 @@
+//go:build vmtrace
+// +build vmtrace
+
+package script
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testTracer struct {
+	steps   []string
+	assigns []string
+	calls   []string
+}
+
+func (t *testTracer) Step(contract, function string, line uint32, cost int64) {
+	t.steps = append(t.steps, fmt.Sprintf(`%s:%d`, function, line))
+}
+
+func (t *testTracer) Assign(name string, value interface{}) {
+	t.assigns = append(t.assigns, fmt.Sprintf(`%s=%v`, name, value))
+}
+
+func (t *testTracer) Call(name string, params, results []interface{}, err error) {
+	t.calls = append(t.calls, fmt.Sprintf(`%s%v%v %v`, name, params, results, err))
+}
+
+func TestTracer(t *testing.T) {
+	vm := NewVM()
+	vm.Extend(&ExtendData{map[string]interface{}{
+		"Double": func(i int64) int64 { return i * 2 },
+		"Fail":   func() error { return errors.New(`failed`) },
+	}, nil})
+	require.NoError(t, vm.Compile([]rune(`func traced int {
+			var i int
+			i = Double($n)
+			$out = i + 1
+			return i
+		}
+		func failed {
+			Fail()
+		}`), &OwnerInfo{StateID: 1, Active: true, TableID: 1}))
+
+	tracer := &testTracer{}
+	rt := vm.RunInit(CostDefault)
+	rt.SetTracer(tracer)
+	_, err := rt.Run(vm.getObjByName(`traced`).Value.(*Block), nil, &map[string]interface{}{`n`: int64(3)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{`traced:3`, `traced:4`, `traced:5`}, tracer.steps)
+	assert.Equal(t, []string{`i=6`, `$out=7`}, tracer.assigns)
+	assert.Equal(t, []string{`Double[3][6] <nil>`}, tracer.calls)
+
+	tracer = &testTracer{}
+	rt = vm.RunInit(CostDefault)
+	rt.SetTracer(tracer)
+	_, err = rt.Run(vm.getObjByName(`failed`).Value.(*Block), nil, &map[string]interface{}{})
+	assert.EqualError(t, err, `failed`)
+	assert.Equal(t, []string{`Fail[][] failed`}, tracer.calls)
+}
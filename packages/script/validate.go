@@ -0,0 +1,267 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package script
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+// The rules of the validation of the data fields. They are specified in the tags of the fields,
+// for example, `Name string "optional max:100"`. The regexp rule takes the rest of the tags,
+// so it must be the last one
+const (
+	RuleMin    = "min"
+	RuleMax    = "max"
+	RuleRegexp = "regexp"
+)
+
+// ValidationErrorType is the type of VMError which is returned if the data field violates the rule
+const ValidationErrorType = "validation"
+
+type fieldRule struct {
+	Name  string
+	Value string
+}
+
+func (rule fieldRule) String() string {
+	return rule.Name + `:` + rule.Value
+}
+
+var (
+	regexps      = make(map[string]*regexp.Regexp)
+	regexpsMutex sync.RWMutex
+)
+
+func getRegexp(pattern string) (*regexp.Regexp, error) {
+	regexpsMutex.RLock()
+	re, ok := regexps[pattern]
+	regexpsMutex.RUnlock()
+	if ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexpsMutex.Lock()
+	regexps[pattern] = re
+	regexpsMutex.Unlock()
+	return re, nil
+}
+
+func isNumericType(itype reflect.Type) bool {
+	switch itype.String() {
+	case `int64`, `float64`, Decimal:
+		return true
+	}
+	return false
+}
+
+// fieldRules returns the validation rules from the tags of the field
+func fieldRules(tags string) []fieldRule {
+	var rules []fieldRule
+	for tags = strings.TrimSpace(tags); len(tags) > 0; {
+		tag := tags
+		if off := strings.IndexAny(tags, " \t"); off >= 0 {
+			tag, tags = tags[:off], strings.TrimSpace(tags[off:])
+		} else {
+			tags = ``
+		}
+		off := strings.IndexByte(tag, ':')
+		if off < 0 {
+			continue
+		}
+		switch name := tag[:off]; name {
+		case RuleRegexp:
+			rules = append(rules, fieldRule{name, strings.TrimSpace(tag[off+1:] + ` ` + tags)})
+			return rules
+		case RuleMin, RuleMax:
+			rules = append(rules, fieldRule{name, tag[off+1:]})
+		}
+	}
+	return rules
+}
+
+// tagValue returns the value of the tag name:value
+func tagValue(tags, name string) (string, bool) {
+	for _, tag := range strings.Fields(tags) {
+		if strings.HasPrefix(tag, RuleRegexp+`:`) {
+			break
+		}
+		if strings.HasPrefix(tag, name+`:`) {
+			return tag[len(name)+1:], true
+		}
+	}
+	return ``, false
+}
+
+// Default returns the value of the default tag, for example, `Count int "default:1"`
+func (fi *FieldInfo) Default() (string, bool) {
+	return tagValue(fi.Tags, TagDefault)
+}
+
+// IsOptional returns whether the field can be omitted
+func (fi *FieldInfo) IsOptional() bool {
+	if _, ok := fi.Default(); ok {
+		return true
+	}
+	return fi.ContainsTag(TagOptional)
+}
+
+func parseDefault(itype reflect.Type, value string) (interface{}, error) {
+	switch itype.String() {
+	case `int64`:
+		return strconv.ParseInt(value, 10, 64)
+	case `float64`:
+		return strconv.ParseFloat(value, 64)
+	case Decimal:
+		return decimal.NewFromString(value)
+	case `string`:
+		return value, nil
+	}
+	return nil, fmt.Errorf(`default can be used only with string, int, float or money`)
+}
+
+// DefaultValue returns the value which is bound if the field is omitted. It is the value
+// of the default tag or the zero value of the type of the field
+func (fi *FieldInfo) DefaultValue() interface{} {
+	if value, ok := fi.Default(); ok {
+		if ret, err := parseDefault(fi.Type, value); err == nil {
+			return ret
+		}
+	}
+	return reflect.New(fi.Type).Elem().Interface()
+}
+
+// checkRule checks if the rule can be used with the type of the field
+func checkRule(field *FieldInfo, rule fieldRule) error {
+	switch rule.Name {
+	case RuleRegexp:
+		if field.Type.String() != `string` {
+			return fmt.Errorf(`regexp can be used only with string`)
+		}
+		_, err := getRegexp(rule.Value)
+		return err
+	default:
+		if field.Type.String() != `string` && !isNumericType(field.Type) {
+			return fmt.Errorf(`%s can be used only with string, int, float or money`, rule.Name)
+		}
+		if _, err := decimal.NewFromString(rule.Value); err != nil {
+			return fmt.Errorf(`%s must be a number`, rule.Name)
+		}
+	}
+	return nil
+}
+
+func toDecimal(value interface{}) (decimal.Decimal, error) {
+	switch v := value.(type) {
+	case int64:
+		return decimal.New(v, 0), nil
+	case float64:
+		return decimal.NewFromFloat(v), nil
+	case decimal.Decimal:
+		return v, nil
+	case string:
+		return decimal.NewFromString(v)
+	}
+	return decimal.Decimal{}, fmt.Errorf(`wrong type %T`, value)
+}
+
+// isValid returns false if the value violates the rule
+func (rule fieldRule) isValid(field *FieldInfo, value interface{}) bool {
+	if rule.Name == RuleRegexp {
+		re, err := getRegexp(rule.Value)
+		return err == nil && re.MatchString(fmt.Sprint(value))
+	}
+	limit, err := decimal.NewFromString(rule.Value)
+	if err != nil {
+		return false
+	}
+	var val decimal.Decimal
+	if isNumericType(field.Type) {
+		if val, err = toDecimal(value); err != nil {
+			return false
+		}
+	} else {
+		val = decimal.New(int64(utf8.RuneCountInString(fmt.Sprint(value))), 0)
+	}
+	if rule.Name == RuleMin {
+		return val.GreaterThanOrEqual(limit)
+	}
+	return val.LessThanOrEqual(limit)
+}
+
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return len(v) == 0
+	case int64:
+		return v == 0
+	case float64:
+		return v == 0
+	case decimal.Decimal:
+		return v.Sign() == 0
+	}
+	return false
+}
+
+func validationError(field *FieldInfo, rule fieldRule) error {
+	out, err := json.Marshal(&VMError{Type: ValidationErrorType, Error: fmt.Sprintf(eFieldRule, field.Name, rule),
+		Field: field.Name, Rule: rule.String()})
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling VMError")
+		return fmt.Errorf(eFieldRule, field.Name, rule)
+	}
+	return errors.New(string(out))
+}
+
+// ValidateFields checks the values of the data fields with the rules of their tags.
+// The empty optional fields and the fields with the default values aren't validated
+func ValidateFields(fields []*FieldInfo, values map[string]interface{}) error {
+	for _, field := range fields {
+		rules := fieldRules(field.Tags)
+		if len(rules) == 0 {
+			continue
+		}
+		value := values[field.Name]
+		if field.IsOptional() && isEmptyValue(value) {
+			continue
+		}
+		for _, rule := range rules {
+			if !rule.isValid(field, value) {
+				log.WithFields(log.Fields{"type": consts.InvalidObject, "field": field.Name, "rule": rule.String()}).Error("validating data field")
+				return validationError(field, rule)
+			}
+		}
+	}
+	return nil
+}
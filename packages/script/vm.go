@@ -76,28 +76,70 @@ var ErrMemoryLimit = errors.New("Memory limit exceeded")
 
 // VMError represents error of VM
 type VMError struct {
-	Type  string `json:"type"`
-	Error string `json:"error"`
+	Type     string `json:"type"`
+	Error    string `json:"error"`
+	Contract string `json:"contract,omitempty"`
+	Func     string `json:"func,omitempty"`
+	Line     uint32 `json:"line,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Rule     string `json:"rule,omitempty"`
+	// Reset is the time when the exceeded quota is reset
+	Reset int64 `json:"reset,omitempty"`
+	// Compile is the list of the errors of the compilation if the type is CompileErrorType
+	Compile []*CompileError `json:"compile,omitempty"`
+}
+
+// RuntimeError is the error of the byte-code execution with the position in the source code
+type RuntimeError struct {
+	Contract string
+	Func     string
+	Line     uint32
+	Column   uint32
+	Err      error
+}
+
+func (e *RuntimeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RuntimeError) fields() log.Fields {
+	return log.Fields{"contract": e.Contract, "func": e.Func, "line": e.Line, "column": e.Column}
+}
+
+func runtimeError(bs *blockStack, err error) *RuntimeError {
+	if rerr, ok := err.(*RuntimeError); ok {
+		return rerr
+	}
+	rerr := &RuntimeError{Err: err}
+	rerr.Line, rerr.Column = bs.Block.Position(bs.Pos)
+	rerr.Contract, rerr.Func = bs.Block.Location()
+	return rerr
 }
 
 type blockStack struct {
 	Block  *Block
 	Offset int
+	Pos    int // offset of the current command
 }
 
 // RunTime is needed for the execution of the byte-code
 type RunTime struct {
-	stack     []interface{}
-	blocks    []*blockStack
-	vars      []interface{}
-	extend    *map[string]interface{}
-	vm        *VM
-	cost      int64
-	err       error
-	unwrap    bool
-	callDepth uint16
-	mem       int64
-	memVars   map[interface{}]int64
+	stack      []interface{}
+	blocks     []*blockStack
+	vars       []interface{}
+	extend     *map[string]interface{}
+	vm         *VM
+	cost       int64
+	err        error
+	unwrap     bool
+	callDepth  int64
+	depthLimit int64
+	mem        int64
+	memLimit   int64
+	memVars    map[interface{}]int64
+	tracer     Tracer
+	traceBlock *Block
+	traceLine  uint32
 }
 
 func isSysVar(name string) bool {
@@ -107,13 +149,27 @@ func isSysVar(name string) bool {
 	return false
 }
 
+// callName returns the name of the called function in the form contract.func
+func callName(obj *ObjInfo) string {
+	if obj.Type != ObjFunc {
+		return obj.Value.(ExtFuncInfo).Name
+	}
+	contract, name := obj.Value.(*Block).Location()
+	if len(contract) == 0 {
+		return name
+	}
+	return contract + `.` + name
+}
+
 func (rt *RunTime) callFunc(cmd uint16, obj *ObjInfo) (err error) {
 	var (
 		count, in int
 	)
 
-	if rt.callDepth >= maxCallDepth {
-		return fmt.Errorf("max call depth")
+	if rt.callDepth >= rt.depthLimit {
+		name := callName(obj)
+		log.WithFields(log.Fields{"type": consts.VMError, "func_name": name, "depth": rt.callDepth}).Error("call depth exceeded")
+		return fmt.Errorf(eCallDepth, name)
 	}
 
 	rt.callDepth++
@@ -235,6 +291,9 @@ func (rt *RunTime) callFunc(cmd uint16, obj *ObjInfo) (err error) {
 		} else {
 			result = foo.Call(pars)
 		}
+		if traceEnabled && rt.tracer != nil {
+			rt.traceCall(finfo.Name, pars, result)
+		}
 		rt.stack = rt.stack[:shift]
 		if stack != nil {
 			stack.AppendStack("")
@@ -260,6 +319,9 @@ func (rt *RunTime) callFunc(cmd uint16, obj *ObjInfo) (err error) {
 					return iret.Interface().(error)
 				}
 			} else {
+				if err = rt.allocMem(resultMem(iret)); err != nil {
+					return err
+				}
 				rt.stack = append(rt.stack, iret.Interface())
 			}
 		}
@@ -292,6 +354,9 @@ func (rt *RunTime) extendFunc(name string) error {
 				return iret.Interface().(error)
 			}
 		} else {
+			if err := rt.allocMem(resultMem(iret)); err != nil {
+				return err
+			}
 			rt.stack = append(rt.stack, iret.Interface())
 		}
 	}
@@ -336,6 +401,25 @@ func calcMem(v interface{}) (mem int64) {
 	return
 }
 
+// resultMem returns the size of the string, array or map which has been returned by the function
+func resultMem(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Interface:
+		return calcMem(v.Interface())
+	}
+	return 0
+}
+
+// allocMem charges the fuel for the allocated memory and checks that the memory limit isn't exceeded
+func (rt *RunTime) allocMem(size int64) error {
+	rt.cost -= CostMemory * (size >> 10)
+	if rt.mem+size > rt.memLimit {
+		rt.vm.logger.WithFields(log.Fields{"type": consts.VMError, "size": size}).Warn(ErrMemoryLimit)
+		return ErrMemoryLimit
+	}
+	return nil
+}
+
 func (rt *RunTime) setExtendVar(k string, v interface{}) {
 	(*rt.extend)[k] = v
 	rt.recalcMemExtendVar(k)
@@ -448,14 +532,32 @@ func (rt *RunTime) Cost() int64 {
 // RunInit creates a new RunTime for the virtual machine
 func (vm *VM) RunInit(cost int64) *RunTime {
 	rt := RunTime{
-		stack:   make([]interface{}, 0, 1024),
-		vm:      vm,
-		cost:    cost,
-		memVars: make(map[interface{}]int64),
+		stack:      make([]interface{}, 0, 1024),
+		vm:         vm,
+		cost:       cost,
+		memLimit:   memoryLimit,
+		depthLimit: maxCallDepth,
+		memVars:    make(map[interface{}]int64),
 	}
 	return &rt
 }
 
+// SetMemoryLimit sets the maximum size of the memory which can be used during the execution
+func (rt *RunTime) SetMemoryLimit(limit int64) {
+	rt.memLimit = limit
+}
+
+// SetCallDepthLimit sets the maximum depth of the nested calls of functions and contracts
+func (rt *RunTime) SetCallDepthLimit(limit int64) {
+	rt.depthLimit = limit
+}
+
+// InheritCallDepth continues the counting of the nested calls from the parent runtime
+func (rt *RunTime) InheritCallDepth(parent *RunTime) {
+	rt.callDepth = parent.callDepth
+	rt.depthLimit = parent.depthLimit
+}
+
 // SetVMError sets error of VM
 func SetVMError(eType string, eText interface{}) error {
 	out, err := json.Marshal(&VMError{Type: eType, Error: fmt.Sprintf(`%v`, eText)})
@@ -466,10 +568,48 @@ func SetVMError(eType string, eText interface{}) error {
 	return fmt.Errorf(string(out))
 }
 
+// ToVMError converts the error to JSON of VMError. The position in the source code is added
+// if it is the runtime error which is not raised by error, warning or info commands
+func ToVMError(err error) error {
+	eText := err.Error()
+	rerr, ok := err.(*RuntimeError)
+	if !strings.HasPrefix(eText, `{`) {
+		if !ok {
+			return SetVMError(`panic`, eText)
+		}
+		return setVMErrorPos(&VMError{Type: `panic`, Error: eText}, rerr)
+	}
+	if !ok {
+		return err
+	}
+	var vmErr VMError
+	if json.Unmarshal([]byte(eText), &vmErr) != nil || vmErr.Line > 0 || vmErr.Type == CompileErrorType ||
+		vmErr.Type == msgError || vmErr.Type == msgWarning || vmErr.Type == msgInfo {
+		return rerr.Err
+	}
+	return setVMErrorPos(&vmErr, rerr)
+}
+
+func setVMErrorPos(vmErr *VMError, rerr *RuntimeError) error {
+	vmErr.Contract, vmErr.Func, vmErr.Line = rerr.Contract, rerr.Func, rerr.Line
+	out, err := json.Marshal(vmErr)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling VMError")
+		return rerr.Err
+	}
+	return errors.New(string(out))
+}
+
 // RunCode executes Block
 func (rt *RunTime) RunCode(block *Block) (status int, err error) {
 	top := make([]interface{}, 8)
-	rt.blocks = append(rt.blocks, &blockStack{block, len(rt.vars)})
+	bs := &blockStack{Block: block, Offset: len(rt.vars)}
+	rt.blocks = append(rt.blocks, bs)
+	defer func() {
+		if err != nil {
+			err = runtimeError(bs, err)
+		}
+	}()
 	var namemap map[string][]interface{}
 	if block.Type == ObjFunc && block.Info.(*FuncInfo).Names != nil {
 		if rt.stack[len(rt.stack)-1] != nil {
@@ -520,13 +660,17 @@ func (rt *RunTime) RunCode(block *Block) (status int, err error) {
 	)
 	labels := make([]int, 0)
 	for ci := 0; ci < len(block.Code); ci++ {
+		bs.Pos = ci
+		if traceEnabled && rt.tracer != nil {
+			rt.traceStep(bs)
+		}
 		rt.cost--
 		if rt.cost <= 0 {
 			rt.vm.logger.WithFields(log.Fields{"type": consts.VMError}).Warn("paid CPU resource is over")
 			return 0, fmt.Errorf(`paid CPU resource is over`)
 		}
 
-		if rt.mem > memoryLimit {
+		if rt.mem > rt.memLimit {
 			rt.vm.logger.WithFields(log.Fields{"type": consts.VMError}).Warn(ErrMemoryLimit)
 			return 0, ErrMemoryLimit
 		}
@@ -557,10 +701,10 @@ func (rt *RunTime) RunCode(block *Block) (status int, err error) {
 		case cmdWhile:
 			val := rt.stack[len(rt.stack)-1]
 			rt.stack = rt.stack[:len(rt.stack)-1]
+			newci := labels[len(labels)-1]
+			labels = labels[:len(labels)-1]
 			if valueToBool(val) {
 				status, err = rt.RunCode(cmd.Value.(*Block))
-				newci := labels[len(labels)-1]
-				labels = labels[:len(labels)-1]
 				if status == statusContinue {
 					ci = newci - 1
 					status = statusNormal
@@ -571,6 +715,38 @@ func (rt *RunTime) RunCode(block *Block) (status int, err error) {
 					break
 				}
 			}
+		case cmdSwitch:
+			val := rt.stack[len(rt.stack)-1]
+			rt.stack = rt.stack[:len(rt.stack)-1]
+			info := cmd.Value.(*SwitchInfo)
+			if v, ok := val.(int); ok {
+				val = int64(v)
+			}
+			if info.Type != nil && reflect.TypeOf(val) != info.Type {
+				err = fmt.Errorf(eSwitchValue, reflect.TypeOf(val), info.Type)
+				break
+			}
+			// the case is found by the value, so the cost doesn't depend on the count of cases
+			caseBlock, ok := info.Cases[val]
+			if !ok {
+				caseBlock = info.Default
+			}
+			if caseBlock != nil {
+				status, err = rt.RunCode(caseBlock)
+			}
+		case cmdArrayInit:
+			count := cmd.Value.(int)
+			initArray := make([]interface{}, count)
+			copy(initArray, rt.stack[len(rt.stack)-count:])
+			rt.stack = append(rt.stack[:len(rt.stack)-count], initArray)
+		case cmdMapInit:
+			count := cmd.Value.(int)
+			off := len(rt.stack) - 2*count
+			initMap := make(map[string]interface{}, count)
+			for k := off; k < len(rt.stack); k += 2 {
+				initMap[rt.stack[k].(string)] = rt.stack[k+1]
+			}
+			rt.stack = append(rt.stack[:off], initMap)
 		case cmdLabel:
 			labels = append(labels, ci)
 		case cmdContinue:
@@ -610,6 +786,9 @@ func (rt *RunTime) RunCode(block *Block) (status int, err error) {
 						}
 					}
 				}
+				if traceEnabled && rt.tracer != nil {
+					rt.traceAssign(item, rt.stack[len(rt.stack)-count+ivar])
+				}
 			}
 		case cmdReturn:
 			status = statusReturn
@@ -645,7 +824,11 @@ func (rt *RunTime) RunCode(block *Block) (status int, err error) {
 			if cmd.Value.(*ObjInfo).Type == ObjExtFunc {
 				finfo := cmd.Value.(*ObjInfo).Value.(ExtFuncInfo)
 				if rt.vm.ExtCost != nil {
-					cost := rt.vm.ExtCost(finfo.Name)
+					var extend map[string]interface{}
+					if rt.extend != nil {
+						extend = *rt.extend
+					}
+					cost := rt.vm.ExtCost(finfo.Name, extend)
 					if cost > rt.cost {
 						rt.cost = 0
 						rt.vm.logger.WithFields(log.Fields{"type": consts.VMError}).Warning("paid CPU resource is over")
@@ -808,7 +991,9 @@ func (rt *RunTime) RunCode(block *Block) (status int, err error) {
 			case string:
 				switch top[0].(type) {
 				case string:
-					bin = top[1].(string) + top[0].(string)
+					if err = rt.allocMem(int64(len(top[1].(string)) + len(top[0].(string)))); err == nil {
+						bin = top[1].(string) + top[0].(string)
+					}
 				case int64:
 					if tmpInt, err = converter.ValueToInt(top[1]); err == nil {
 						bin = tmpInt + top[0].(int64)
@@ -1113,6 +1298,7 @@ func (rt *RunTime) RunCode(block *Block) (status int, err error) {
 			err = fmt.Errorf(`Unknown command %d`, cmd.Cmd)
 		}
 		if err != nil {
+			err = runtimeError(bs, err)
 			rt.err = err
 			break
 		}
@@ -1139,7 +1325,7 @@ func (rt *RunTime) RunCode(block *Block) (status int, err error) {
 	}
 	rt.stack = rt.stack[:start]
 	if err != nil {
-		rt.vm.logger.WithFields(log.Fields{"type": consts.VMError, "error": err}).Error("error in vm")
+		rt.vm.logger.WithFields(runtimeError(bs, err).fields()).WithFields(log.Fields{"type": consts.VMError, "error": err}).Error("error in vm")
 	}
 	return
 }
@@ -1148,8 +1334,12 @@ func (rt *RunTime) RunCode(block *Block) (status int, err error) {
 func (rt *RunTime) Run(block *Block, params []interface{}, extend *map[string]interface{}) (ret []interface{}, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			rt.vm.logger.WithFields(log.Fields{"type": consts.PanicRecoveredError, "error_info": r, "stack": string(debug.Stack())}).Error("runtime panic error")
-			err = fmt.Errorf(`runtime panic error`)
+			rerr := &RuntimeError{Err: fmt.Errorf(`runtime panic error`)}
+			if len(rt.blocks) > 0 {
+				rerr = runtimeError(rt.blocks[len(rt.blocks)-1], rerr.Err)
+			}
+			rt.vm.logger.WithFields(rerr.fields()).WithFields(log.Fields{"type": consts.PanicRecoveredError, "error_info": r, "stack": string(debug.Stack())}).Error("runtime panic error")
+			err = rerr
 		}
 	}()
 	info := block.Info.(*FuncInfo)
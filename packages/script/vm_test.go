@@ -1,8 +1,12 @@
 package script
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,3 +29,376 @@ func TestCalcMem(t *testing.T) {
 		assert.Equal(t, v.mem, calcMem(v.v))
 	}
 }
+
+func runFuel(t *testing.T, vm *VM, name string, n int64) int64 {
+	rt := vm.RunInit(CostDefault)
+	_, err := rt.Run(vm.getObjByName(name).Value.(*Block), nil, &map[string]interface{}{`n`: n})
+	assert.NoError(t, err)
+	return CostDefault - rt.Cost()
+}
+
+// codeFuel returns the fuel of the byte-code, every instruction costs 1 and reading of
+// the extend variable costs CostExtend in addition
+func codeFuel(code []*ByteCode) (fuel int64) {
+	for _, cmd := range code {
+		fuel++
+		if cmd.Cmd == cmdExtend {
+			fuel += CostExtend
+		}
+	}
+	return
+}
+
+// loopCode returns the byte-code of the loop condition and the body of the loop
+func loopCode(block *Block) (cond []*ByteCode, body *Block) {
+	var start int
+	for i, cmd := range block.Code {
+		switch cmd.Cmd {
+		case cmdLabel:
+			start = i
+		case cmdWhile:
+			return block.Code[start : i+1], cmd.Value.(*Block)
+		}
+	}
+	return nil, nil
+}
+
+func TestLoopFuel(t *testing.T) {
+	vm := NewVM()
+	err := vm.Compile([]rune(`func plain int {
+			var i int
+			while i < $n {
+				i = i + 1
+			}
+			return i
+		}
+		func withbreak int {
+			var i int
+			while true {
+				i = i + 1
+				if i == $n {
+					break
+				}
+			}
+			return i
+		}
+		func withcontinue int {
+			var i int
+			while i < $n {
+				i = i + 1
+				if i > 0 {
+					continue
+				}
+				i = i + 100
+			}
+			return i
+		}`), &OwnerInfo{StateID: 1, Active: true, TableID: 1})
+	assert.NoError(t, err)
+
+	// every additional iteration costs exactly the count of the executed instructions
+	for _, name := range []string{`plain`, `withbreak`, `withcontinue`} {
+		cond, body := loopCode(vm.getObjByName(name).Value.(*Block))
+		executed := codeFuel(cond) + codeFuel(body.Code)
+		if name == `withcontinue` {
+			// the rest of the body after the if with continue is skipped
+			for i, cmd := range body.Code {
+				if cmd.Cmd == cmdIf {
+					executed = codeFuel(cond) + codeFuel(body.Code[:i+1]) + codeFuel(cmd.Value.(*Block).Code)
+					break
+				}
+			}
+		}
+		assert.Equal(t, executed, runFuel(t, vm, name, 3)-runFuel(t, vm, name, 2), name)
+	}
+}
+
+func TestRuntimeErrorPosition(t *testing.T) {
+	vm := NewVM()
+	vm.Extend(&ExtendData{map[string]interface{}{"Fail": func(s string) error {
+		return errors.New(s)
+	}, "Crash": func() {
+		var m map[string]int
+		m["key"] = 1
+	}}, nil})
+	err := vm.Compile([]rune(`contract Pos {
+			func check(i int) {
+				if i > 0 {
+					Fail("wrong value")
+				}
+			}
+			func crash() {
+				Crash()
+			}
+			func warn() {
+				warning "check warning"
+			}
+			action {
+				var i int
+				i = 1
+				check(i)
+			}
+		}`), &OwnerInfo{StateID: 1, Active: true, TableID: 1})
+	assert.NoError(t, err)
+
+	contract := vm.getObjByName(`@1Pos`).Value.(*Block)
+	run := func(name string) error {
+		rt := vm.RunInit(CostDefault)
+		_, err := rt.Run(contract.Objects[name].Value.(*Block), nil, &map[string]interface{}{})
+		return err
+	}
+
+	err = run(`action`)
+	assert.Equal(t, &RuntimeError{Contract: `@1Pos`, Func: `check`, Line: 4, Column: 7,
+		Err: errors.New(`wrong value`)}, err)
+	assert.EqualError(t, ToVMError(err),
+		`{"type":"panic","error":"wrong value","contract":"@1Pos","func":"check","line":4}`)
+
+	err = run(`crash`)
+	assert.EqualError(t, err, `runtime panic error`)
+	assert.EqualError(t, ToVMError(err),
+		`{"type":"panic","error":"runtime panic error","contract":"@1Pos","func":"crash","line":8}`)
+
+	// the messages of the contract don't contain the position
+	err = run(`warn`)
+	assert.Equal(t, uint32(11), err.(*RuntimeError).Line)
+	assert.EqualError(t, ToVMError(err), `{"type":"warning","error":"check warning"}`)
+
+	assert.EqualError(t, ToVMError(errors.New(`plain error`)), `{"type":"panic","error":"plain error"}`)
+}
+
+func TestLineTable(t *testing.T) {
+	vm := NewVM()
+	err := vm.Compile([]rune(`func lines int {
+			var i, j int
+			while i < 10 { i = i + 1
+				j = j + i * 2
+			}
+			return j
+		}`), &OwnerInfo{StateID: 1, Active: true, TableID: 1})
+	assert.NoError(t, err)
+
+	block := vm.getObjByName(`lines`).Value.(*Block)
+	// only the first command of the line has the item in the line table
+	assert.Equal(t, []LineInfo{{0, 3, 5}, {5, 6, 5}}, block.Lines)
+	body := block.Code[4].Value.(*Block)
+	assert.Equal(t, []LineInfo{{0, 3, 20}, {5, 4, 6}, {12, 5, 5}}, body.Lines)
+
+	for offset, line := range []uint32{3, 3, 3, 3, 3, 4, 4, 4, 4, 4, 4, 4, 5} {
+		pos, _ := body.Position(offset)
+		assert.Equal(t, line, pos, offset)
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	vm := NewVM()
+	vm.Extend(&ExtendData{map[string]interface{}{"Repeat": func(s string, count int64) string {
+		return strings.Repeat(s, int(count))
+	}}, nil})
+	err := vm.Compile([]rune(`func double string {
+			var s string
+			s = "double"
+			while true {
+				s = s + s
+			}
+			return s
+		}
+		func repeat string {
+			var s string
+			s = Repeat("repeat", 1000000)
+			return s
+		}`), &OwnerInfo{StateID: 1, Active: true, TableID: 1})
+	assert.NoError(t, err)
+
+	for _, name := range []string{`double`, `repeat`} {
+		rt := vm.RunInit(CostDefault)
+		rt.SetMemoryLimit(1 << 20)
+		_, err = rt.Run(vm.getObjByName(name).Value.(*Block), nil, &map[string]interface{}{})
+		assert.Equal(t, ErrMemoryLimit, err.(*RuntimeError).Err, name)
+		assert.True(t, rt.mem < 1<<20, name)
+	}
+
+	// the allocated memory is paid
+	rt := vm.RunInit(CostDefault)
+	_, err = rt.Run(vm.getObjByName(`repeat`).Value.(*Block), nil, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.True(t, CostDefault-rt.Cost() > CostMemory*6000000>>10)
+}
+
+func TestCallDepth(t *testing.T) {
+	vm := NewVM()
+	err := vm.Compile([]rune(`contract Recursion {
+			func ping(n int) {
+				func pong(n int) {
+					$depth = n
+					ping(n + 1)
+				}
+				$depth = n
+				pong(n + 1)
+			}
+			action {
+				ping(1)
+			}
+		}`), &OwnerInfo{StateID: 1, Active: true, TableID: 1})
+	assert.NoError(t, err)
+
+	action := vm.getObjByName(`@1Recursion`).Value.(*Block).Objects[`action`].Value.(*Block)
+	for i := 0; i < 3; i++ {
+		rt := vm.RunInit(CostDefault)
+		rt.SetCallDepthLimit(50)
+		extend := map[string]interface{}{}
+		_, err = rt.Run(action, nil, &extend)
+		assert.EqualError(t, err, `call depth exceeded in @1Recursion.ping`)
+		assert.Equal(t, int64(50), extend[`depth`])
+		assert.Equal(t, int64(0), rt.callDepth)
+	}
+}
+
+func TestValidateFields(t *testing.T) {
+	vm := NewVM()
+	err := vm.Compile([]rune(`contract Validate {
+			data {
+				Name string "max:5"
+				Amount money "min:0 max:100"
+				Code string "optional regexp:^[a-z]+ [0-9]+$"
+				Count int "optional min:1"
+			}
+		}`), &OwnerInfo{StateID: 1, Active: true, TableID: 1})
+	assert.NoError(t, err)
+
+	fields := *vm.getObjByName(`@1Validate`).Value.(*Block).Info.(*ContractInfo).Tx
+	for _, item := range []struct {
+		values map[string]interface{}
+		err    string
+	}{
+		{map[string]interface{}{`Name`: `имя`, `Amount`: decimal.New(100, 0), `Code`: ``, `Count`: int64(0)}, ``},
+		{map[string]interface{}{`Name`: `name`, `Amount`: `5.5`, `Code`: `ab 12`, `Count`: int64(2)}, ``},
+		{map[string]interface{}{`Name`: `long name`, `Amount`: decimal.New(1, 0)},
+			`{"type":"validation","error":"field Name violates max:5","field":"Name","rule":"max:5"}`},
+		{map[string]interface{}{`Name`: `name`, `Amount`: decimal.New(-1, 0)},
+			`{"type":"validation","error":"field Amount violates min:0","field":"Amount","rule":"min:0"}`},
+		{map[string]interface{}{`Name`: `name`, `Amount`: int64(1), `Code`: `ab12`},
+			`{"type":"validation","error":"field Code violates regexp:^[a-z]+ [0-9]+$","field":"Code","rule":"regexp:^[a-z]+ [0-9]+$"}`},
+		{map[string]interface{}{`Name`: `name`, `Amount`: int64(1), `Count`: int64(-2)},
+			`{"type":"validation","error":"field Count violates min:1","field":"Count","rule":"min:1"}`},
+	} {
+		err = ValidateFields(fields, item.values)
+		if len(item.err) == 0 {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, item.err)
+		}
+	}
+}
+
+func TestFieldDefaults(t *testing.T) {
+	vm := NewVM()
+	vm.Extend(&ExtendData{map[string]interface{}{"Sprintf": fmt.Sprintf}, nil})
+	err := vm.Compile([]rune(`contract Defaults {
+			data {
+				Name string
+				Comment string "optional"
+				Count int "default:3"
+				Rate money "default:10 min:1"
+			}
+			action {
+				$result = Sprintf("%s|%s|%d|%v", $Name, $Comment, $Count, $Rate)
+			}
+		}
+		contract CallDefaults {
+			action {
+				$result = Defaults("Name", "name")
+			}
+		}`), &OwnerInfo{StateID: 1, Active: true, TableID: 1})
+	assert.NoError(t, err)
+
+	fields := *vm.getObjByName(`@1Defaults`).Value.(*Block).Info.(*ContractInfo).Tx
+	for i, optional := range []bool{false, true, true, true} {
+		assert.Equal(t, optional, fields[i].IsOptional())
+	}
+	def, ok := fields[2].Default()
+	assert.True(t, ok)
+	assert.Equal(t, `3`, def)
+	assert.Equal(t, int64(3), fields[2].DefaultValue())
+	assert.Equal(t, ``, fields[1].DefaultValue())
+
+	action := vm.getObjByName(`@1CallDefaults`).Value.(*Block).Objects[`action`].Value.(*Block)
+	extend := map[string]interface{}{}
+	_, err = vm.RunInit(CostDefault).Run(action, nil, &extend)
+	assert.NoError(t, err)
+	assert.Equal(t, `name||3|10`, extend[`result`])
+}
+
+func TestParseContract(t *testing.T) {
+	for _, item := range []struct {
+		in   string
+		id   uint64
+		name string
+		err  string
+	}{
+		{`@1NewContract`, 1, `NewContract`, ``},
+		{`@12_new_Contract2`, 12, `_new_Contract2`, ``},
+		{`@0Контракт`, 0, `Контракт`, ``},
+		{`@Contract`, 0, `Contract`, ``},
+		{`Contract`, 0, `Contract`, ``},
+		{``, 0, ``, `invalid contract name : wrong identifier`},
+		{`@`, 0, ``, `invalid contract name @: wrong identifier`},
+		{`@1`, 0, ``, `invalid contract name @1: wrong identifier`},
+		{`@@1Contract`, 0, ``, `invalid contract name @@1Contract: wrong identifier`},
+		{`@1Con-tract`, 0, ``, `invalid contract name @1Con-tract: wrong identifier`},
+		{`@99999999999Contract`, 0, ``, `invalid contract name @99999999999Contract: wrong ecosystem`},
+	} {
+		id, name, err := ParseContractStrict(item.in)
+		if len(item.err) > 0 {
+			assert.EqualError(t, err, item.err)
+		} else {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, item.id, id)
+		assert.Equal(t, item.name, name)
+
+		id, name = ParseContract(item.in)
+		assert.Equal(t, item.id, id)
+		assert.Equal(t, item.name, name)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	vm := NewVM()
+	_, err := vm.CompileBlock([]rune(`contract Errs {
+	action {
+		var i int
+		i = 4 + )
+		if i > 0 {
+			i = j
+		}
+		Unknown(i)
+		i = 5
+	}
+}`), &OwnerInfo{StateID: 1})
+	errs, ok := err.(CompileErrors)
+	if !assert.True(t, ok, err) {
+		return
+	}
+	// the compilation continues from the next statement after the error
+	assert.Equal(t, []string{"4:12:there is not pair:", "6:9:unknown identifier j:j",
+		"8:4:unknown identifier Unknown:Unknown"}, func() (ret []string) {
+		for _, e := range errs {
+			ret = append(ret, fmt.Sprintf("%d:%d:%s:%s", e.Line, e.Column, e.Message, e.Token))
+		}
+		return
+	}())
+	assert.EqualError(t, err, `there is not pair`)
+
+	// the last errors are dropped if the message is too long
+	vmErr := errs.VMError(100)
+	assert.EqualError(t, vmErr, `{"type":"compile","error":"there is not pair",`+
+		`"compile":[{"line":4,"column":12,"message":"there is not pair"}]}`)
+	assert.Equal(t, vmErr, ToVMError(&RuntimeError{Contract: `@1NewContract`, Line: 5, Err: vmErr}))
+
+	_, err = vm.CompileBlock([]rune("func errs {\n"+strings.Repeat("Unknown()\n", 12)+"}"), &OwnerInfo{StateID: 1})
+	assert.Len(t, err, maxCompileErrors)
+
+	// the position of the error text is used instead of the position of the lexem
+	cerr := newCompileError(errors.New(`unexpected : [Ln:3 Col:7]`), &Lexem{Line: 1, Column: 1})
+	assert.Equal(t, []interface{}{uint32(3), uint32(7), `unexpected :`}, []interface{}{cerr.Line, cerr.Column, cerr.Message})
+}
@@ -19,9 +19,10 @@ package script
 import (
 	"fmt"
 	"reflect"
-	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 
@@ -55,6 +56,8 @@ const (
 	ObjVar
 	// ObjExtend is an extended variable. $myvar
 	ObjExtend
+	// ObjConst is a constant of the contract. const { MyConst = 10 }
+	ObjConst
 
 	// CostCall is the cost of the function calling
 	CostCall = 50
@@ -62,6 +65,8 @@ const (
 	CostContract = 100
 	// CostExtend is the cost of the extend function calling
 	CostExtend = 10
+	// CostMemory is the cost of every KB of the allocated memory
+	CostMemory = 1
 	// CostDefault is the default maximum cost of F
 	CostDefault = int64(10000000)
 
@@ -76,6 +81,7 @@ const (
 	TagAddress   = "address"
 	TagSignature = "signature"
 	TagOptional  = "optional"
+	TagDefault   = "default"
 )
 
 // ExtFuncInfo is the structure for the extrended function
@@ -110,6 +116,12 @@ type ContractInfo struct {
 	Settings map[string]interface{}
 }
 
+// ConstInfo contains the type and the value of the contract constant
+type ConstInfo struct {
+	Type  reflect.Type
+	Value interface{}
+}
+
 // FuncNameCmd for cmdFuncName
 type FuncNameCmd struct {
 	Name  string
@@ -125,11 +137,13 @@ type FuncName struct {
 
 // FuncInfo contains the function information
 type FuncInfo struct {
+	Name     string
 	Params   []reflect.Type
 	Results  []reflect.Type
 	Names    *map[string]FuncName
 	Variadic bool
 	ID       uint32
+	Private  bool // the function can be called only inside of its contract
 }
 
 // VarInfo contains the variable information
@@ -145,6 +159,14 @@ type IndexInfo struct {
 	Extend    string
 }
 
+// SwitchInfo contains the cases of switch
+type SwitchInfo struct {
+	Type    reflect.Type           // type of case values, nil if there are no cases
+	Cases   map[interface{}]*Block // blocks of cases by their values
+	Default *Block
+	current *Block // the case which values are being compiled
+}
+
 // ObjInfo is the common object type
 type ObjInfo struct {
 	Type  int
@@ -170,6 +192,51 @@ type Block struct {
 	Vars     []reflect.Type
 	Code     ByteCodes
 	Children Blocks
+	Lines    []LineInfo
+}
+
+// LineInfo binds the offset of the byte-code to the position in the source code.
+// The new item is added only if the line has been changed
+type LineInfo struct {
+	Offset uint32
+	Line   uint32
+	Column uint32
+}
+
+func (block *Block) addLine(offset int, lexem *Lexem) {
+	if len(block.Code) == offset {
+		return
+	}
+	if len(block.Lines) > 0 && block.Lines[len(block.Lines)-1].Line == lexem.Line {
+		return
+	}
+	block.Lines = append(block.Lines, LineInfo{uint32(offset), lexem.Line, lexem.Column})
+}
+
+// Position returns the line and the column of the source code for the command with the specified offset
+func (block *Block) Position(offset int) (line, column uint32) {
+	i := sort.Search(len(block.Lines), func(i int) bool {
+		return block.Lines[i].Offset > uint32(offset)
+	})
+	if i == 0 {
+		return 0, 0
+	}
+	return block.Lines[i-1].Line, block.Lines[i-1].Column
+}
+
+// Location returns the names of the contract and the function which contain the block
+func (block *Block) Location() (contract, name string) {
+	for cur := block; cur != nil; cur = cur.Parent {
+		switch cur.Type {
+		case ObjFunc:
+			if len(name) == 0 {
+				name = cur.Info.(*FuncInfo).Name
+			}
+		case ObjContract:
+			return cur.Info.(*ContractInfo).Name, name
+		}
+	}
+	return
 }
 
 // Blocks is a slice of blocks
@@ -178,7 +245,7 @@ type Blocks []*Block
 // VM is the main type of the virtual machine
 type VM struct {
 	Block
-	ExtCost     func(string) int64
+	ExtCost     func(string, map[string]interface{}) int64
 	FuncCallsDB map[string]struct{}
 	Extern      bool // extern mode of compilation
 	logger      *log.Entry
@@ -195,17 +262,48 @@ type Stacker interface {
 	AppendStack(contract string) error
 }
 
-// ParseContract gets a state identifier and the name of the contract from the full name like @[id]name
+// ParseContract gets a state identifier and the name of the contract from the full name like @[id]name.
+// It returns zero values if the full name is malformed
 func ParseContract(in string) (id uint64, name string) {
-	var err error
-	re := regexp.MustCompile(`(?is)^@(\d+)(\w[_\w\d]*)$`)
-	ret := re.FindStringSubmatch(in)
-	if len(ret) == 3 {
-		id, err = strconv.ParseUint(ret[1], 10, 32)
-		if err != nil {
-			log.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": ret[1]}).Error("converting state identifier from string to int while parsing contract")
+	id, name, _ = ParseContractStrict(in)
+	return
+}
+
+// isIdent returns whether the name is the identifier which is accepted by the compiler
+func isIdent(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
 		}
-		name = ret[2]
+	}
+	return true
+}
+
+// ParseContractStrict gets a state identifier and the name of the contract from the full name
+// like @[id]name. The name without the state identifier like @name or name belongs to the state 0
+// as in StateName. It returns an error if the full name is malformed
+func ParseContractStrict(in string) (id uint64, name string, err error) {
+	name = in
+	if strings.HasPrefix(name, `@`) {
+		name = name[1:]
+		off := strings.IndexFunc(name, func(r rune) bool { return r < '0' || r > '9' })
+		if off < 0 {
+			off = len(name)
+		}
+		if off > 0 {
+			if id, err = strconv.ParseUint(name[:off], 10, 32); err != nil {
+				log.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": name[:off]}).Error("converting state identifier from string to int while parsing contract")
+				return 0, ``, fmt.Errorf(eContractName, in, `wrong ecosystem`)
+			}
+		}
+		name = name[off:]
+	}
+	if !isIdent(name) {
+		log.WithFields(log.Fields{"type": consts.ParseError, "contract_name": in}).Error("parsing contract name")
+		return 0, ``, fmt.Errorf(eContractName, in, `wrong identifier`)
 	}
 	return
 }
@@ -227,8 +325,15 @@ func ExecContract(rt *RunTime, name, txs string, params ...interface{}) (interfa
 		logger.WithFields(log.Fields{"contract_params_len": len(pars), "contract_params_len_needed": len(params), "type": consts.ContractError}).Error("wrong contract parameters pars")
 		return ``, errContractPars
 	}
-	for _, ipar := range pars {
+	values := make(map[string]interface{})
+	for i, ipar := range pars {
 		parnames[ipar] = true
+		values[ipar] = params[i]
+	}
+	if cblock.Info.(*ContractInfo).Tx != nil {
+		if err := ValidateFields(*cblock.Info.(*ContractInfo).Tx, values); err != nil {
+			return nil, err
+		}
 	}
 	if _, ok := (*rt.extend)[`loop_`+name]; ok {
 		logger.WithFields(log.Fields{"type": consts.ContractError, "contract_name": name}).Error("there is loop in contract")
@@ -250,11 +355,11 @@ func ExecContract(rt *RunTime, name, txs string, params ...interface{}) (interfa
 	if cblock.Info.(*ContractInfo).Tx != nil {
 		for _, tx := range *cblock.Info.(*ContractInfo).Tx {
 			if !parnames[tx.Name] {
-				if !strings.Contains(tx.Tags, `optional`) {
+				if !tx.IsOptional() {
 					logger.WithFields(log.Fields{"transaction_name": tx.Name, "type": consts.ContractError}).Error("transaction not defined")
 					return ``, fmt.Errorf(eUndefinedParam, tx.Name)
 				}
-				(*rt.extend)[tx.Name] = reflect.New(tx.Type).Elem().Interface()
+				(*rt.extend)[tx.Name] = tx.DefaultValue()
 			}
 			if tx.Name == `Signature` {
 				isSignature = true
@@ -305,6 +410,9 @@ func ExecContract(rt *RunTime, name, txs string, params ...interface{}) (interfa
 	for _, method := range []string{`init`, `conditions`, `action`} {
 		if block, ok := (*cblock).Objects[method]; ok && block.Type == ObjFunc {
 			rtemp := rt.vm.RunInit(rt.cost)
+			rtemp.memLimit = rt.memLimit
+			rtemp.InheritCallDepth(rt)
+			rtemp.tracer = rt.tracer
 			(*rt.extend)[`parent`] = parent
 			_, err := rtemp.Run(block.Value.(*Block), nil, rt.extend)
 			rt.cost = rtemp.cost
@@ -439,6 +547,10 @@ func (vm *VM) Call(name string, params []interface{}, extend *map[string]interfa
 	}
 	switch obj.Type {
 	case ObjFunc:
+		if obj.Value.(*Block).Info.(*FuncInfo).Private {
+			vm.logger.WithFields(log.Fields{"type": consts.VMError, "vm_func_name": name}).Error("calling private function")
+			return nil, fmt.Errorf(ePrivateFunc, name)
+		}
 		rt := vm.RunInit(CostDefault)
 		ret, err = rt.Run(obj.Value.(*Block), params, extend)
 	case ObjExtFunc:
@@ -488,11 +600,11 @@ func ExContract(rt *RunTime, state uint32, name string, params map[string]interf
 		for _, tx := range *cblock.Info.(*ContractInfo).Tx {
 			val, ok := params[tx.Name]
 			if !ok {
-				if !strings.Contains(tx.Tags, `optional`) {
+				if !tx.IsOptional() {
 					logger.WithFields(log.Fields{"transaction_name": tx.Name, "type": consts.ContractError}).Error("transaction not defined")
 					return nil, fmt.Errorf(eUndefinedParam, tx.Name)
 				}
-				val = reflect.New(tx.Type).Elem().Interface()
+				val = tx.DefaultValue()
 			}
 			names = append(names, tx.Name)
 			vals = append(vals, val)
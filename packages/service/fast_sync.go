@@ -0,0 +1,102 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// FastSync keeps the best known block id received from other nodes.
+// Blocks which are more than rb_blocks_1 blocks behind this height can never be rolled back,
+// so rollback records are not written for them
+type FastSync struct {
+	mutex sync.RWMutex
+
+	bestBlockID int64
+	active      bool
+}
+
+var fs = &FastSync{}
+
+func fastSyncPath() string {
+	return filepath.Join(conf.Config.DataDir, consts.FastSyncFilename)
+}
+
+// InitFastSync restores the best known block id saved before the restart
+func InitFastSync() error {
+	data, err := ioutil.ReadFile(fastSyncPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": fastSyncPath()}).Error("reading fast sync file")
+		return err
+	}
+
+	blockID, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": string(data)}).Error("converting best block id to int")
+		return err
+	}
+
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	fs.bestBlockID = blockID
+	return nil
+}
+
+// SetBestBlockID saves the max block id received from other nodes
+func SetBestBlockID(blockID int64) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if blockID <= fs.bestBlockID {
+		return
+	}
+	fs.bestBlockID = blockID
+
+	// the file is replaced atomically so the height survives a crash in the middle of writing
+	path := fastSyncPath()
+	if err := ioutil.WriteFile(path+".tmp", []byte(strconv.FormatInt(blockID, 10)), 0644); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": path}).Error("writing fast sync file")
+		return
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": path}).Error("renaming fast sync file")
+	}
+}
+
+// IsFastSyncBlock returns true if the rollback records can be skipped for the block
+func IsFastSyncBlock(blockID int64) bool {
+	fs.mutex.RLock()
+	defer fs.mutex.RUnlock()
+
+	return fs.bestBlockID > 0 && blockID <= fs.bestBlockID-syspar.GetRbBlocks1()
+}
+
+// FastSyncBlock returns the mode for applying of the block and logs the switching of the mode
+func FastSyncBlock(blockID int64) bool {
+	active := IsFastSyncBlock(blockID)
+
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if active != fs.active {
+		fs.active = active
+		if active {
+			log.WithFields(log.Fields{"block_id": blockID, "best_block_id": fs.bestBlockID}).Info("fast sync mode is on")
+		} else {
+			log.WithFields(log.Fields{"block_id": blockID, "best_block_id": fs.bestBlockID}).Info("fast sync mode is off")
+		}
+	}
+	return active
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// GenerationPause is the pause of the block generation requested by the node operator.
+// The node keeps validating of blocks and serving of API while the generation is paused
+type GenerationPause struct {
+	mutex sync.RWMutex
+
+	paused bool
+}
+
+var gp = &GenerationPause{}
+
+func generationPausedPath() string {
+	return filepath.Join(conf.Config.DataDir, consts.GenerationPausedFilename)
+}
+
+// InitGenerationPause restores the pause of the block generation saved before the restart
+func InitGenerationPause() error {
+	_, err := os.Stat(generationPausedPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": generationPausedPath()}).Error("checking generation paused file")
+		return err
+	}
+
+	gp.mutex.Lock()
+	defer gp.mutex.Unlock()
+	gp.paused = true
+	log.WithFields(log.Fields{"path": generationPausedPath()}).Info("block generation is paused since the previous run")
+	return nil
+}
+
+// PauseGeneration stops the generation of blocks until ResumeGeneration is called
+func PauseGeneration() error {
+	gp.mutex.Lock()
+	defer gp.mutex.Unlock()
+
+	if gp.paused {
+		log.Info("block generation is already paused")
+		return nil
+	}
+	if err := ioutil.WriteFile(generationPausedPath(), []byte{}, 0644); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": generationPausedPath()}).Error("writing generation paused file")
+		return err
+	}
+	gp.paused = true
+	log.Info("block generation is paused")
+	return nil
+}
+
+// ResumeGeneration continues the generation of blocks
+func ResumeGeneration() error {
+	gp.mutex.Lock()
+	defer gp.mutex.Unlock()
+
+	if !gp.paused {
+		log.Info("block generation is already running")
+		return nil
+	}
+	if err := os.Remove(generationPausedPath()); err != nil && !os.IsNotExist(err) {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": generationPausedPath()}).Error("removing generation paused file")
+		return err
+	}
+	gp.paused = false
+	log.Info("block generation is resumed")
+	return nil
+}
+
+// IsGenerationPaused returns true if the generation of blocks is paused by the operator
+func IsGenerationPaused() bool {
+	gp.mutex.RLock()
+	defer gp.mutex.RUnlock()
+
+	return gp.paused
+}
@@ -1,6 +1,9 @@
 package service
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 const (
 	NoPause PauseType = 0
@@ -18,6 +21,10 @@ type NodePaused struct {
 	mutex sync.RWMutex
 
 	PauseType PauseType
+
+	// StopReason and StopTime are taken from the certificate which stopped the network
+	StopReason string
+	StopTime   time.Time
 }
 
 func (np *NodePaused) Set(pt PauseType) {
@@ -32,6 +39,7 @@ func (np *NodePaused) Unset() {
 	defer np.mutex.Unlock()
 
 	np.PauseType = NoPause
+	np.StopReason, np.StopTime = "", time.Time{}
 }
 
 func (np *NodePaused) Get() PauseType {
@@ -59,3 +67,20 @@ func PauseNodeActivity(pt PauseType) {
 func NodePauseType() PauseType {
 	return np.Get()
 }
+
+// StopNetwork sets the node in a pause state because the network is stopped by the certificate
+func StopNetwork(reason string, at time.Time) {
+	np.mutex.Lock()
+	defer np.mutex.Unlock()
+
+	np.PauseType = PauseTypeStopingNetwork
+	np.StopReason, np.StopTime = reason, at
+}
+
+// NetworkStopped returns the reason and the time of the network stopping, ok is false if the network isn't stopped
+func NetworkStopped() (reason string, at time.Time, ok bool) {
+	np.mutex.RLock()
+	defer np.mutex.RUnlock()
+
+	return np.StopReason, np.StopTime, np.PauseType == PauseTypeStopingNetwork
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// KeyTxBudget is the state of the budget of the key
+type KeyTxBudget struct {
+	KeyID         string `json:"key_id"`
+	Included      int64  `json:"included"`
+	Failed        int64  `json:"failed"`
+	CooldownUntil int64  `json:"cooldown_until,omitempty"`
+}
+
+// budgetBlock is the number of the transactions of the keys in the generated block
+type budgetBlock struct {
+	blockID  int64
+	included map[int64]int64
+	failed   map[int64]int64
+}
+
+// TxBudget counts the included and the failed transactions of the keys in the last blocks which have
+// been generated by the node. The pending transactions of the keys which have exceeded the failures
+// are included in the next blocks after the transactions of the other keys. The state is node-local
+// and it's lost on the restart
+type TxBudget struct {
+	mutex sync.Mutex
+
+	blocks   []budgetBlock
+	cooldown map[int64]int64 // the last block in which the key is deprioritized
+}
+
+var txBudget = NewTxBudget()
+
+// NewTxBudget returns the empty budget
+func NewTxBudget() *TxBudget {
+	return &TxBudget{cooldown: make(map[int64]int64)}
+}
+
+// GetTxBudget returns the budget of the block generator
+func GetTxBudget() *TxBudget {
+	return txBudget
+}
+
+// Register adds the transactions of the generated block. The keys which have exceeded the failures
+// in the last blocks get the cooldown
+func (tb *TxBudget) Register(blockID int64, included, failed map[int64]int64) {
+	cfg := conf.Config.TxBudget
+	tb.mutex.Lock()
+	defer tb.mutex.Unlock()
+
+	tb.blocks = append(tb.blocks, budgetBlock{blockID: blockID, included: included, failed: failed})
+	tb.expire(blockID)
+	if cfg.MaxFailures <= 0 {
+		return
+	}
+	for keyID := range failed {
+		if tb.count(keyID).Failed >= cfg.MaxFailures && tb.cooldown[keyID] < blockID+cfg.Cooldown {
+			tb.cooldown[keyID] = blockID + cfg.Cooldown
+			log.WithFields(log.Fields{"key_id": keyID, "block_id": blockID, "until": tb.cooldown[keyID]}).
+				Warning("transactions of key are deprioritized")
+		}
+	}
+}
+
+// IsDeprioritized returns true if the transactions of the key are included in the block after the others
+func (tb *TxBudget) IsDeprioritized(keyID, blockID int64) bool {
+	tb.mutex.Lock()
+	defer tb.mutex.Unlock()
+
+	return tb.cooldown[keyID] >= blockID
+}
+
+// State returns the budgets of the keys which have the transactions in the last blocks before the block
+// or have the cooldown. The keys are sorted by the number of the failures
+func (tb *TxBudget) State(blockID int64) []KeyTxBudget {
+	tb.mutex.Lock()
+	defer tb.mutex.Unlock()
+
+	tb.expire(blockID)
+	keys := make(map[int64]bool)
+	for _, block := range tb.blocks {
+		for keyID := range block.included {
+			keys[keyID] = true
+		}
+		for keyID := range block.failed {
+			keys[keyID] = true
+		}
+	}
+	for keyID := range tb.cooldown {
+		keys[keyID] = true
+	}
+	list := make([]KeyTxBudget, 0, len(keys))
+	for keyID := range keys {
+		list = append(list, tb.count(keyID))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Failed != list[j].Failed {
+			return list[i].Failed > list[j].Failed
+		}
+		return list[i].KeyID < list[j].KeyID
+	})
+	return list
+}
+
+// expire removes the blocks which are out of the window and the finished cooldowns
+func (tb *TxBudget) expire(blockID int64) {
+	window := conf.Config.TxBudget.Blocks
+	i := 0
+	for i < len(tb.blocks) && tb.blocks[i].blockID <= blockID-window {
+		i++
+	}
+	tb.blocks = tb.blocks[i:]
+	for keyID, until := range tb.cooldown {
+		if until < blockID {
+			delete(tb.cooldown, keyID)
+		}
+	}
+}
+
+func (tb *TxBudget) count(keyID int64) KeyTxBudget {
+	budget := KeyTxBudget{KeyID: strconv.FormatInt(keyID, 10), CooldownUntil: tb.cooldown[keyID]}
+	for _, block := range tb.blocks {
+		budget.Included += block.included[keyID]
+		budget.Failed += block.failed[keyID]
+	}
+	return budget
+}
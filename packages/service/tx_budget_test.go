@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxBudget(t *testing.T) {
+	saved := conf.Config.TxBudget
+	defer func() { conf.Config.TxBudget = saved }()
+	conf.Config.TxBudget = conf.TxBudgetConfig{Blocks: 3, MaxFailures: 4, Cooldown: 2}
+
+	tb := NewTxBudget()
+	tb.Register(10, map[int64]int64{1: 2, 2: 1}, map[int64]int64{1: 2})
+	tb.Register(11, nil, map[int64]int64{1: 1, 2: 1})
+	assert.False(t, tb.IsDeprioritized(1, 12))
+
+	// the failures of the key are counted across the blocks
+	tb.Register(12, map[int64]int64{2: 1}, map[int64]int64{1: 1})
+	assert.True(t, tb.IsDeprioritized(1, 13))
+	assert.True(t, tb.IsDeprioritized(1, 14))
+	assert.False(t, tb.IsDeprioritized(1, 15))
+	assert.False(t, tb.IsDeprioritized(2, 13))
+
+	// the blocks which are out of the window aren't counted
+	assert.Equal(t, []KeyTxBudget{
+		{KeyID: "1", Failed: 2, CooldownUntil: 14},
+		{KeyID: "2", Included: 1, Failed: 1},
+	}, tb.State(13))
+	tb.Register(13, nil, map[int64]int64{2: 2})
+	assert.Equal(t, []KeyTxBudget{
+		{KeyID: "2", Included: 1, Failed: 2},
+		{KeyID: "1", Failed: 1, CooldownUntil: 14},
+	}, tb.State(14))
+	assert.Empty(t, tb.State(20))
+
+	conf.Config.TxBudget.MaxFailures = 0
+	tb.Register(20, nil, map[int64]int64{3: 100})
+	assert.False(t, tb.IsDeprioritized(3, 21))
+}
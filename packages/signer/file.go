@@ -0,0 +1,41 @@
+package signer
+
+import (
+	"encoding/hex"
+	"errors"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// fileSigner signs by the node private key file, it's read by utils.GetNodeKeys
+type fileSigner struct{}
+
+func (fileSigner) Sign(digest []byte) ([]byte, error) {
+	nodePrivateKey, _, err := utils.GetNodeKeys()
+	if err != nil {
+		return nil, err
+	}
+	if len(nodePrivateKey) == 0 {
+		log.WithFields(log.Fields{"type": consts.EmptyObject}).Error("node private key is empty")
+		return nil, errors.New("node private key is empty")
+	}
+	key, err := hex.DecodeString(nodePrivateKey)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding node private key from hex")
+		return nil, err
+	}
+	defer crypto.WipeKey(key)
+	return crypto.SignDigest(key, digest)
+}
+
+func (fileSigner) PublicKey() ([]byte, error) {
+	_, nodePublicKey, err := utils.GetNodeKeys()
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(nodePublicKey)
+}
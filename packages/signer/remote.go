@@ -0,0 +1,170 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultTimeout = 2 * time.Second
+	unixPrefix     = `unix://`
+	maxResponse    = 1 << 16
+)
+
+// The requests and the responses of the remote signer.
+// POST /sign gets signRequest and returns signResponse, GET /public_key returns publicKeyResponse
+type signRequest struct {
+	Digest string `json:"digest"`
+}
+
+type signResponse struct {
+	Signature string `json:"signature"`
+}
+
+type publicKeyResponse struct {
+	PublicKey string `json:"public_key"`
+}
+
+// remoteSigner calls the signing service. The public key is requested once
+type remoteSigner struct {
+	client *http.Client
+	url    string
+
+	mutex  sync.Mutex
+	public []byte
+}
+
+func newRemoteSigner(config conf.SignerConfig) (*remoteSigner, error) {
+	transport := &http.Transport{}
+	url := strings.TrimRight(config.Endpoint, `/`)
+	switch {
+	case strings.HasPrefix(url, unixPrefix):
+		// the access to the local socket is limited by the permissions of the file
+		path := strings.TrimPrefix(url, unixPrefix)
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, `unix`, path)
+		}
+		url = `http://signer`
+	case strings.HasPrefix(url, `https://`):
+		tlsConfig, err := clientTLSConfig(config)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.ConfigError, "error": err}).Error("loading certificates of remote signer")
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	default:
+		log.WithFields(log.Fields{"type": consts.ConfigError, "endpoint": config.Endpoint}).Error("wrong endpoint of remote signer")
+		return nil, fmt.Errorf("endpoint of remote signer must be https://host:port or unix:///path, got %q", config.Endpoint)
+	}
+	timeout := time.Duration(config.Timeout) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &remoteSigner{client: &http.Client{Transport: transport, Timeout: timeout}, url: url}, nil
+}
+
+// clientTLSConfig returns the config of mutual TLS, the signer checks the client certificate
+func clientTLSConfig(config conf.SignerConfig) (*tls.Config, error) {
+	if len(config.Cert) == 0 || len(config.Key) == 0 {
+		return nil, errors.New("client certificate and key of remote signer are required")
+	}
+	cert, err := tls.LoadX509KeyPair(config.Cert, config.Key)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if len(config.CA) > 0 {
+		data, err := ioutil.ReadFile(config.CA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates in %s", config.CA)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+func (s *remoteSigner) Sign(digest []byte) ([]byte, error) {
+	body, err := json.Marshal(signRequest{Digest: hex.EncodeToString(digest)})
+	if err != nil {
+		return nil, err
+	}
+	var res signResponse
+	if err = s.call(http.MethodPost, `/sign`, body, &res); err != nil {
+		return nil, err
+	}
+	signature, err := hex.DecodeString(res.Signature)
+	if err != nil || len(signature) == 0 {
+		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("wrong signature of remote signer")
+		return nil, fmt.Errorf("wrong signature of remote signer")
+	}
+	return signature, nil
+}
+
+func (s *remoteSigner) PublicKey() ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.public != nil {
+		return s.public, nil
+	}
+	var res publicKeyResponse
+	if err := s.call(http.MethodGet, `/public_key`, nil, &res); err != nil {
+		return nil, err
+	}
+	public, err := hex.DecodeString(res.PublicKey)
+	if err != nil || len(public) == 0 {
+		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("wrong public key of remote signer")
+		return nil, fmt.Errorf("wrong public key of remote signer")
+	}
+	s.public = public
+	return public, nil
+}
+
+func (s *remoteSigner) call(method, path string, body []byte, result interface{}) error {
+	req, err := http.NewRequest(method, s.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(`Content-Type`, `application/json`)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err}).Error("calling remote signer")
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(http.MaxBytesReader(nil, resp.Body, maxResponse))
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err}).Error("reading response of remote signer")
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.WithFields(log.Fields{"type": consts.NetworkError, "status": resp.StatusCode}).Error("remote signer returned error")
+		return fmt.Errorf("remote signer returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if err = json.Unmarshal(data, result); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling response of remote signer")
+		return err
+	}
+	return nil
+}
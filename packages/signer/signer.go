@@ -0,0 +1,74 @@
+package signer
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// The types of the signers of conf.Config.Signer
+const (
+	TypeFile   = "file"
+	TypeRemote = "remote"
+)
+
+// ErrUnknownType is returned if the type of the signer in the config is unknown
+var ErrUnknownType = errors.New("unknown signer type")
+
+// BlockSigner signs the blocks and the transactions of the block generator by the node key
+type BlockSigner interface {
+	// Sign signs the digest of the data, the digest is returned by crypto.Digest
+	Sign(digest []byte) ([]byte, error)
+	// PublicKey returns the public key of the node
+	PublicKey() ([]byte, error)
+}
+
+var current struct {
+	sync.Mutex
+	config conf.SignerConfig
+	signer BlockSigner
+}
+
+// New returns the signer of the config
+func New(config conf.SignerConfig) (BlockSigner, error) {
+	switch config.Type {
+	case ``, TypeFile:
+		return fileSigner{}, nil
+	case TypeRemote:
+		return newRemoteSigner(config)
+	}
+	log.WithFields(log.Fields{"type": consts.ConfigError, "signer": config.Type}).Error("unknown signer type")
+	return nil, ErrUnknownType
+}
+
+// Get returns the signer of conf.Config.Signer. The signer is created again if the config is changed
+func Get() (BlockSigner, error) {
+	current.Lock()
+	defer current.Unlock()
+	if current.signer == nil || current.config != conf.Config.Signer {
+		s, err := New(conf.Config.Signer)
+		if err != nil {
+			return nil, err
+		}
+		current.config, current.signer = conf.Config.Signer, s
+	}
+	return current.signer, nil
+}
+
+// SignData signs the data by the signer like crypto.Sign signs it by the private key
+func SignData(s BlockSigner, data string) ([]byte, error) {
+	public, err := s.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	digest, err := crypto.Digest(public, data)
+	if err != nil {
+		return nil, err
+	}
+	return s.Sign(digest)
+}
@@ -0,0 +1,198 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const forSign = `0,2,00,1500000000,0,1,0,data`
+
+func checkSigner(t *testing.T, s BlockSigner) {
+	public, err := s.PublicKey()
+	require.NoError(t, err)
+	sign, err := SignData(s, forSign)
+	require.NoError(t, err)
+	ok, err := crypto.CheckSign(public, forSign, sign)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestFileSigner(t *testing.T) {
+	dir, err := ioutil.TempDir(``, `keys`)
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(keysDir string) { conf.Config.KeysDir = keysDir }(conf.Config.KeysDir)
+
+	priv, _, err := crypto.GenHexKeys()
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, consts.NodePrivateKeyFilename), []byte(priv), 0600))
+	conf.Config.KeysDir = dir
+
+	s, err := New(conf.SignerConfig{Type: TypeFile})
+	require.NoError(t, err)
+	checkSigner(t, s)
+
+	_, err = New(conf.SignerConfig{Type: `hsm`})
+	assert.Equal(t, ErrUnknownType, err)
+}
+
+// certificate returns the PEM certificate and key signed by ca, ca is self-signed if it's nil
+func certificate(t *testing.T, ca *tls.Certificate, template *x509.Certificate) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	parent, signKey := template, interface{}(key)
+	if ca != nil {
+		parent, signKey = ca.Leaf, ca.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signKey)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: `CERTIFICATE`, Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: `EC PRIVATE KEY`, Bytes: keyDer})
+}
+
+func keyPair(t *testing.T, certPEM, keyPEM []byte) tls.Certificate {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return cert
+}
+
+// remoteServer starts the signing service which requires the client certificate signed by the test CA
+func remoteServer(t *testing.T, dir string, handler http.Handler) (*httptest.Server, conf.SignerConfig) {
+	notAfter := time.Now().Add(time.Hour)
+	caPEM, caKeyPEM := certificate(t, nil, &x509.Certificate{SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{CommonName: `signer ca`}, NotAfter: notAfter, IsCA: true,
+		BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign})
+	ca := keyPair(t, caPEM, caKeyPEM)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+
+	serverPEM, serverKeyPEM := certificate(t, &ca, &x509.Certificate{SerialNumber: big.NewInt(2),
+		Subject: pkix.Name{CommonName: `127.0.0.1`}, NotAfter: notAfter,
+		IPAddresses: []net.IP{net.ParseIP(`127.0.0.1`)}, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}})
+	clientPEM, clientKeyPEM := certificate(t, &ca, &x509.Certificate{SerialNumber: big.NewInt(3),
+		Subject: pkix.Name{CommonName: `node`}, NotAfter: notAfter,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+
+	config := conf.SignerConfig{
+		Type:    TypeRemote,
+		CA:      filepath.Join(dir, `ca.pem`),
+		Cert:    filepath.Join(dir, `node.pem`),
+		Key:     filepath.Join(dir, `node.key`),
+		Timeout: 500,
+	}
+	require.NoError(t, ioutil.WriteFile(config.CA, caPEM, 0600))
+	require.NoError(t, ioutil.WriteFile(config.Cert, clientPEM, 0600))
+	require.NoError(t, ioutil.WriteFile(config.Key, clientKeyPEM, 0600))
+
+	server := httptest.NewUnstartedServer(handler)
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{keyPair(t, serverPEM, serverKeyPEM)},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	server.StartTLS()
+	config.Endpoint = server.URL
+	return server, config
+}
+
+// signingHandler signs the digests by the private key like the signing service does
+func signingHandler(t *testing.T, private []byte) http.Handler {
+	public, err := crypto.PrivateToPublic(private)
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	mux.HandleFunc(`/public_key`, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(publicKeyResponse{PublicKey: hex.EncodeToString(public)})
+	})
+	mux.HandleFunc(`/sign`, func(w http.ResponseWriter, r *http.Request) {
+		var req signRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		digest, err := hex.DecodeString(req.Digest)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sign, err := crypto.SignDigest(private, digest)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(signResponse{Signature: hex.EncodeToString(sign)})
+	})
+	return mux
+}
+
+func TestRemoteSigner(t *testing.T) {
+	dir, err := ioutil.TempDir(``, `signer`)
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, version := range []byte{crypto.SchemeECDSA, crypto.SchemeEd25519} {
+		private, _, err := crypto.GenSchemeKeys(version)
+		require.NoError(t, err)
+		server, config := remoteServer(t, dir, signingHandler(t, private))
+		s, err := New(config)
+		require.NoError(t, err)
+		checkSigner(t, s)
+
+		// the client certificate is required by mutual TLS
+		config.Cert, config.Key = ``, ``
+		_, err = New(config)
+		assert.Error(t, err)
+
+		// the unavailable signer returns the error instead of the signature
+		server.Close()
+		_, err = s.Sign(make([]byte, 32))
+		assert.Error(t, err)
+	}
+}
+
+func TestRemoteSignerTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir(``, `signer`)
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	done := make(chan struct{})
+	server, config := remoteServer(t, dir, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	config.Timeout = 100
+	s, err := New(config)
+	require.NoError(t, err)
+	start := time.Now()
+	_, err = s.PublicKey()
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
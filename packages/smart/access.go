@@ -0,0 +1,36 @@
+package smart
+
+import "strings"
+
+// accessKey returns the key of the access granted in the transaction. The conditions of the table
+// can check the calling contract, so the access is granted to the contract on top of the stack
+func (sc *SmartContract) accessKey(parts ...string) string {
+	var contract string
+	if sc.TxContract != nil && len(sc.TxContract.StackCont) > 0 {
+		contract, _ = sc.TxContract.StackCont[len(sc.TxContract.StackCont)-1].(string)
+	}
+	return contract + "\x00" + strings.Join(parts, "\x00")
+}
+
+// grantedAccess returns the columns of the access which is already granted in the transaction
+func (sc *SmartContract) grantedAccess(key string) ([]string, bool) {
+	columns, ok := sc.access[key]
+	return columns, ok
+}
+
+// grantAccess keeps the granted access until the end of the transaction
+func (sc *SmartContract) grantAccess(key string, columns []string) {
+	if sc.access == nil {
+		sc.access = make(map[string][]string)
+	}
+	sc.access[key] = columns
+}
+
+// resetAccess forgets the granted accesses, the bounds of the columns and the counters if the transaction changes the table of the tables
+func (sc *SmartContract) resetAccess(table string) {
+	if table == `tables` || strings.HasSuffix(table, `_tables`) {
+		sc.access = nil
+		sc.bounds = nil
+		sc.counters = nil
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// alterColumnBatch is the number of the rows which are read and updated by one query
+	alterColumnBatch = 1000
+	// alterColumnRollbackRows is the maximum number of the rows which values are changed by the conversion,
+	// the original values of these rows are kept in the rollback record
+	alterColumnRollbackRows = 10000
+	// defaultAlterColumnRows is used if the system parameter is not defined
+	defaultAlterColumnRows = 100000
+	maxVarcharLen          = 102400
+	maxMoneyDigits         = 30
+)
+
+// alterColumnSQLTypes are the SQL types of the columns which can be converted, see columnType
+var alterColumnSQLTypes = map[string]string{
+	`text`:    `text`,
+	`varchar`: `varchar(102400)`,
+	`json`:    `jsonb`,
+	`number`:  `bigint`,
+	`money`:   `decimal(30, 0)`,
+}
+
+// alterColumnConversions are the allowed changes of the column types
+var alterColumnConversions = map[string]map[string]bool{
+	`text`:    {`varchar`: true, `json`: true, `number`: true, `money`: true},
+	`varchar`: {`text`: true, `json`: true, `number`: true, `money`: true},
+	`number`:  {`money`: true},
+}
+
+// alterColumnDefaults are the default values of the NOT NULL columns, see columnType
+var alterColumnDefaults = map[string]string{
+	`text`:    ``,
+	`varchar`: ``,
+	`number`:  `0`,
+	`money`:   `0`,
+}
+
+// alterColumnUsing returns the expression which converts the values of the column to the type
+func alterColumnUsing(column, colType string) string {
+	if colType == `json` {
+		return fmt.Sprintf(`NULLIF("%s"::text, '')::jsonb`, column)
+	}
+	if colType == `text` || colType == `varchar` {
+		return fmt.Sprintf(`COALESCE("%s"::text, '')::%s`, column, alterColumnSQLTypes[colType])
+	}
+	return fmt.Sprintf(`"%s"::text::%s`, column, alterColumnSQLTypes[colType])
+}
+
+// convertColumnValue checks that the value can be converted to the type and returns the value
+// which is written to the column before the change of the type
+func convertColumnValue(colType, value string) (string, error) {
+	switch colType {
+	case `varchar`:
+		if utf8.RuneCountInString(value) > maxVarcharLen {
+			return ``, fmt.Errorf(`it is longer than %d characters`, maxVarcharLen)
+		}
+	case `json`:
+		if len(value) > 0 && (!json.Valid([]byte(value)) || strings.Contains(value, `\u0000`)) {
+			return ``, fmt.Errorf(`it is not valid JSON`)
+		}
+	case `number`:
+		value = strings.TrimSpace(value)
+		if len(value) == 0 {
+			return `0`, nil
+		}
+		num, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return ``, fmt.Errorf(`it is not an integer`)
+		}
+		return strconv.FormatInt(num, 10), nil
+	case `money`:
+		value = strings.TrimSpace(value)
+		if len(value) == 0 {
+			return `0`, nil
+		}
+		num, err := decimal.NewFromString(value)
+		if err != nil || !num.Equal(num.Truncate(0)) {
+			return ``, fmt.Errorf(`it is not an integer`)
+		}
+		if value = num.Truncate(0).String(); len(strings.TrimPrefix(value, `-`)) > maxMoneyDigits {
+			return ``, fmt.Errorf(`it has more than %d digits`, maxMoneyDigits)
+		}
+	}
+	return value, nil
+}
+
+// AlterColumnType changes the type of the column of the table if the conditions of the table are true.
+// The values of all rows are checked before the change, the first value which can't be converted
+// breaks the contract. The number and money columns get 0 instead of the empty strings and NULL.
+// The rollback restores the type and the original values of the rows which values have been changed
+func AlterColumnType(sc *SmartContract, tableName, name, colType string) error {
+	name = converter.EscapeSQL(strings.ToLower(name))
+	if err := checkColumnName(name); err != nil {
+		return err
+	}
+	if name == `id` {
+		return fmt.Errorf(`Type of column id cannot be changed`)
+	}
+	tableName = strings.ToLower(tableName)
+	if !converter.IsLatin(tableName) {
+		return fmt.Errorf(eLatin, tableName)
+	}
+	if err := sc.tableConditions(tableName); err != nil {
+		return err
+	}
+	tblname := getDefTableName(sc, tableName)
+	oldType, err := model.GetColumnType(tblname, name)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": tblname}).Error("getting column type")
+		return err
+	}
+	if len(oldType) == 0 {
+		return fmt.Errorf(`Column %s has not been found`, name)
+	}
+	if oldType == colType {
+		return nil
+	}
+	if !alterColumnConversions[oldType][colType] {
+		return fmt.Errorf(`Type of column %s cannot be changed from %s to %s`, name, oldType, colType)
+	}
+
+	row, err := model.GetOneRowTransaction(sc.DbTransaction, fmt.Sprintf(`SELECT count(*) AS count FROM "%s"`,
+		tblname)).Int64()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": tblname}).Error("counting rows")
+		return err
+	}
+	if maxRows := syspar.SysInt64Def(syspar.MaxAlterColumnRows, defaultAlterColumnRows); row[`count`] > maxRows {
+		return fmt.Errorf(`Table %s has more than %d rows`, tableName, maxRows)
+	}
+
+	// the original values of the changed rows by id
+	original := make(map[string]*string)
+	addOriginal := func(id string, value *string) error {
+		if len(original) >= alterColumnRollbackRows {
+			return fmt.Errorf(`Conversion changes the values of more than %d rows`, alterColumnRollbackRows)
+		}
+		original[id] = value
+		return nil
+	}
+	for lastID := int64(0); ; {
+		rows, err := model.GetAllTransaction(sc.DbTransaction, fmt.Sprintf(`SELECT id, COALESCE("%[1]s"::text, '') AS value,
+			("%[1]s" IS NULL)::int AS isnull FROM "%[2]s" WHERE id > ? ORDER BY id`, name, tblname),
+			alterColumnBatch, lastID)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": tblname}).Error("selecting column values")
+			return err
+		}
+		var (
+			ids    []int64
+			values []*string
+		)
+		for _, item := range rows {
+			value, err := convertColumnValue(colType, item[`value`])
+			if err != nil {
+				return fmt.Errorf(`Value of column %s in row %s cannot be converted to %s: %s`, name, item[`id`], colType, err)
+			}
+			if lastID, err = strconv.ParseInt(item[`id`], 10, 64); err != nil {
+				return err
+			}
+			// NULL of the json columns is kept, the other columns get the zero value
+			if value == item[`value`] {
+				continue
+			}
+			var orig *string
+			if item[`isnull`] != `1` {
+				orig = new(string)
+				*orig = item[`value`]
+			}
+			if err = addOriginal(item[`id`], orig); err != nil {
+				return err
+			}
+			ids = append(ids, lastID)
+			values = append(values, &value)
+		}
+		if err = model.UpdateColumnValues(sc.DbTransaction, tblname, name, alterColumnSQLTypes[oldType],
+			ids, values); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": tblname}).Error("updating column values")
+			return err
+		}
+		if len(rows) < alterColumnBatch {
+			break
+		}
+	}
+	if colType == `json` {
+		// jsonb keeps the values in the normalized form, so the text of the rows can be changed
+		for lastID := int64(0); ; {
+			rows, err := model.GetAllTransaction(sc.DbTransaction, fmt.Sprintf(`SELECT id, "%[1]s" AS value
+				FROM "%[2]s" WHERE id > ? AND "%[1]s" IS NOT NULL AND "%[1]s"::text IS DISTINCT FROM (%[3]s)::text
+				ORDER BY id`, name, tblname, alterColumnUsing(name, colType)), alterColumnBatch, lastID)
+			if err != nil {
+				log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": tblname}).Error("selecting json values")
+				return err
+			}
+			for _, item := range rows {
+				orig := item[`value`]
+				if err = addOriginal(item[`id`], &orig); err != nil {
+					return err
+				}
+				lastID, _ = strconv.ParseInt(item[`id`], 10, 64)
+			}
+			if len(rows) < alterColumnBatch {
+				break
+			}
+		}
+	}
+
+	defValue, notNull := alterColumnDefaults[colType]
+	if err = model.AlterColumnType(sc.DbTransaction, tblname, name, alterColumnSQLTypes[colType],
+		alterColumnUsing(name, colType), notNull, defValue); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": tblname}).Error("changing column type")
+		return err
+	}
+	// the bounds of the columns are read again
+	delete(sc.bounds, tblname)
+	if sc.VDE {
+		return nil
+	}
+	values, err := converter.CanonicalJSON(original)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling original values")
+		return err
+	}
+	return SysRollback(sc, map[string]string{"Type": "AlterColumnType", "TableName": tblname, "Name": name,
+		"OldType": oldType, "Values": string(values)})
+}
@@ -0,0 +1,58 @@
+package smart
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertColumnValue(t *testing.T) {
+	cases := []struct {
+		colType, value, result string
+		err                    bool
+	}{
+		{`text`, ` any value `, ` any value `, false},
+		{`varchar`, `short`, `short`, false},
+		{`varchar`, strings.Repeat(`я`, maxVarcharLen), strings.Repeat(`я`, maxVarcharLen), false},
+		{`varchar`, strings.Repeat(`a`, maxVarcharLen+1), ``, true},
+		{`json`, `{"a": [1, 2]}`, `{"a": [1, 2]}`, false},
+		{`json`, ``, ``, false},
+		{`json`, `{"a":`, ``, true},
+		{`json`, `"\u0000"`, ``, true},
+		{`number`, ` 42 `, `42`, false},
+		{`number`, `-007`, `-7`, false},
+		{`number`, ``, `0`, false},
+		{`number`, `1.5`, ``, true},
+		{`number`, `99999999999999999999`, ``, true},
+		{`money`, `1000000000000000000`, `1000000000000000000`, false},
+		{`money`, `12.00`, `12`, false},
+		{`money`, ``, `0`, false},
+		{`money`, `12.5`, ``, true},
+		{`money`, strings.Repeat(`9`, maxMoneyDigits+1), ``, true},
+		{`money`, `abc`, ``, true},
+	}
+	for _, v := range cases {
+		result, err := convertColumnValue(v.colType, v.value)
+		if v.err {
+			assert.Error(t, err, v.colType+` `+v.value)
+			continue
+		}
+		if assert.NoError(t, err, v.colType+` `+v.value) {
+			assert.Equal(t, v.result, result, v.colType+` `+v.value)
+		}
+	}
+}
+
+func TestAlterColumnConversions(t *testing.T) {
+	for from, list := range alterColumnConversions {
+		assert.NotEmpty(t, alterColumnSQLTypes[from], from)
+		for to := range list {
+			assert.NotEmpty(t, alterColumnSQLTypes[to], to)
+			sqlType, err := columnType(to)
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(strings.Replace(sqlType, ` `, ``, -1),
+				strings.Replace(alterColumnSQLTypes[to], ` `, ``, -1)), to)
+		}
+	}
+}
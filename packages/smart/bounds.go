@@ -0,0 +1,165 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+// ColumnBoundError is returned when the value which is written to the column is out of its bounds
+type ColumnBoundError struct {
+	Table  string
+	Column string
+	Value  string
+	Bound  string // min or max
+	Limit  string
+}
+
+func (e *ColumnBoundError) Error() string {
+	rel := `less than the minimum`
+	if e.Bound == `max` {
+		rel = `greater than the maximum`
+	}
+	return fmt.Sprintf(`Value %s of column %s in table %s is %s %s`, e.Value, e.Column, e.Table, rel, e.Limit)
+}
+
+// checkColumnBounds checks the syntax of the min and max bounds of the column
+func checkColumnBounds(colType string, perm permColumn) error {
+	if len(perm.Min) == 0 && len(perm.Max) == 0 {
+		return nil
+	}
+	if colType != `number` && colType != `money` {
+		return fmt.Errorf(`Bounds can be only defined for number and money columns`)
+	}
+	var (
+		bounds [2]decimal.Decimal
+		err    error
+	)
+	for i, bound := range []string{perm.Min, perm.Max} {
+		if len(bound) == 0 {
+			continue
+		}
+		if bounds[i], err = decimal.NewFromString(bound); err != nil {
+			log.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "bound": bound}).Error("parsing column bound")
+			return fmt.Errorf(`Bound %s is not a number`, bound)
+		}
+		if colType == `number` && !bounds[i].Equal(bounds[i].Floor()) {
+			return fmt.Errorf(`Bound %s of number column must be an integer`, bound)
+		}
+	}
+	if len(perm.Min) > 0 && len(perm.Max) > 0 && bounds[0].GreaterThan(bounds[1]) {
+		return fmt.Errorf(`Minimum %s is greater than maximum %s`, perm.Min, perm.Max)
+	}
+	return nil
+}
+
+// columnBounds returns the columns of the table which have bounds. The bounds are kept
+// until the end of the transaction or until the table of the tables is changed
+func (sc *SmartContract) columnBounds(table string) (map[string]permColumn, error) {
+	if bounds, ok := sc.bounds[table]; ok {
+		return bounds, nil
+	}
+	bounds := make(map[string]permColumn)
+	prefix, name := PrefixName(table)
+	if len(prefix) > 0 {
+		tables := &model.Table{}
+		tables.SetTablePrefix(prefix)
+		found, err := tables.Get(sc.DbTransaction, name)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table}).Error("getting table columns")
+			return nil, err
+		}
+		if found {
+			var cols map[string]string
+			if err = json.Unmarshal([]byte(tables.Columns), &cols); err != nil {
+				log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("getting table columns")
+				return nil, err
+			}
+			for column, cond := range cols {
+				perm, err := getPermColumns(cond)
+				if err == nil && (len(perm.Min) > 0 || len(perm.Max) > 0) {
+					bounds[column] = perm
+				}
+			}
+		}
+	}
+	if sc.bounds == nil {
+		sc.bounds = make(map[string]map[string]permColumn)
+	}
+	sc.bounds[table] = bounds
+	return bounds, nil
+}
+
+// checkBounds checks that the written values are in the bounds of the columns. The values of
+// +column and -column fields are added to the current values of the row
+func (sc *SmartContract) checkBounds(table string, fields, values []string, row map[string]string) error {
+	bounds, err := sc.columnBounds(table)
+	if err != nil || len(bounds) == 0 {
+		return err
+	}
+	for i, field := range fields {
+		column, sign := field, field[:1]
+		if sign == `+` || sign == `-` {
+			column = field[1:]
+		}
+		perm, ok := bounds[column]
+		if !ok || values[i] == `NULL` {
+			continue
+		}
+		value, err := decimal.NewFromString(values[i])
+		if err != nil {
+			continue
+		}
+		if sign == `+` || sign == `-` {
+			current := decimal.Zero
+			if cur, err := decimal.NewFromString(row[column]); err == nil {
+				current = cur
+			}
+			if sign == `+` {
+				value = current.Add(value)
+			} else {
+				value = current.Sub(value)
+			}
+		}
+		for _, bound := range []struct {
+			name, limit string
+			cmp         int
+		}{{`min`, perm.Min, -1}, {`max`, perm.Max, 1}} {
+			if len(bound.limit) == 0 {
+				continue
+			}
+			limit, err := decimal.NewFromString(bound.limit)
+			if err != nil {
+				continue
+			}
+			if value.Cmp(limit) == bound.cmp {
+				log.WithFields(log.Fields{"type": consts.InvalidObject, "table": table, "column": column,
+					"value": value.String(), bound.name: bound.limit}).Error("value is out of column bounds")
+				return &ColumnBoundError{Table: table, Column: column, Value: value.String(),
+					Bound: bound.name, Limit: bound.limit}
+			}
+		}
+	}
+	return nil
+}
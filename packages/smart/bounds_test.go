@@ -0,0 +1,46 @@
+package smart
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckColumnBounds(t *testing.T) {
+	assert.NoError(t, checkColumnBounds(`varchar`, permColumn{Update: `true`}))
+	assert.NoError(t, checkColumnBounds(`number`, permColumn{Min: `0`, Max: `100`}))
+	assert.NoError(t, checkColumnBounds(`money`, permColumn{Min: `0.5`}))
+	assert.Error(t, checkColumnBounds(`varchar`, permColumn{Min: `0`}))
+	assert.Error(t, checkColumnBounds(`number`, permColumn{Min: `0.5`}))
+	assert.Error(t, checkColumnBounds(`money`, permColumn{Max: `many`}))
+	assert.Error(t, checkColumnBounds(`money`, permColumn{Min: `10`, Max: `1`}))
+}
+
+func TestCheckColumnRef(t *testing.T) {
+	assert.NoError(t, checkColumnRef(`money`, permColumn{Update: `true`}))
+	assert.NoError(t, checkColumnRef(`number`, permColumn{Ref: `binaries`}))
+	assert.Error(t, checkColumnRef(`number`, permColumn{Ref: `pages`}))
+	assert.Error(t, checkColumnRef(`json`, permColumn{Ref: `binaries`}))
+}
+
+func TestCheckBounds(t *testing.T) {
+	sc := &SmartContract{bounds: map[string]map[string]permColumn{
+		`1_wallets`: {`amount`: {Min: `0`, Max: `1000`}},
+	}}
+	row := map[string]string{`amount`: `100`}
+
+	assert.NoError(t, sc.checkBounds(`1_wallets`, []string{`amount`, `name`}, []string{`1000`, `-5`}, nil))
+	assert.NoError(t, sc.checkBounds(`1_wallets`, []string{`-amount`}, []string{`100`}, row))
+
+	err := sc.checkBounds(`1_wallets`, []string{`amount`}, []string{`-1`}, nil)
+	assert.EqualError(t, err, `Value -1 of column amount in table 1_wallets is less than the minimum 0`)
+
+	err = sc.checkBounds(`1_wallets`, []string{`-amount`}, []string{`101`}, row)
+	if assert.IsType(t, &ColumnBoundError{}, err) {
+		assert.Equal(t, `min`, err.(*ColumnBoundError).Bound)
+		assert.Equal(t, `-1`, err.(*ColumnBoundError).Value)
+	}
+
+	err = sc.checkBounds(`1_wallets`, []string{`+amount`}, []string{`901`}, row)
+	assert.EqualError(t, err, `Value 1001 of column amount in table 1_wallets is greater than the maximum 1000`)
+}
@@ -0,0 +1,189 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// contractCache keeps the byte-code of the compiled contracts in the data directory.
+// The byte-code of the unit depends on the objects of the VM defined before it, so the key
+// of the unit is the hash of its source chained with the keys of all previous units of the loading.
+// The chain starts with the hash of the compiler version, of the executable and of the VM objects
+type contractCache struct {
+	dir    string
+	name   string
+	digest []byte
+	used   map[string]bool
+}
+
+var (
+	executableHash     []byte
+	executableHashOnce sync.Once
+)
+
+func getExecutableHash() []byte {
+	executableHashOnce.Do(func() {
+		path, err := os.Executable()
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("getting executable path")
+			return
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": path}).Error("opening executable")
+			return
+		}
+		defer file.Close()
+		hash := sha256.New()
+		if _, err = io.Copy(hash, file); err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": path}).Error("reading executable")
+			return
+		}
+		executableHash = hash.Sum(nil)
+	})
+	return executableHash
+}
+
+// newContractCache starts the loading of contracts to vm with the cache.
+// It returns nil if the cache can't be used, in this case the contracts are compiled
+func newContractCache(vm *script.VM, name string) *contractCache {
+	if len(conf.Config.DataDir) == 0 {
+		return nil
+	}
+	exeHash := getExecutableHash()
+	if exeHash == nil {
+		return nil
+	}
+	dir := filepath.Join(conf.Config.DataDir, consts.ContractsCacheDirname)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": dir}).Error("creating contracts cache directory")
+		return nil
+	}
+
+	names := make([]string, 0, len(vm.Objects))
+	for key, obj := range vm.Objects {
+		names = append(names, fmt.Sprintf("%s:%d", key, obj.Type))
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d %x %t\n", script.CompilerVersion, exeHash, vm.Extern)
+	hash.Write([]byte(strings.Join(names, ",")))
+	return &contractCache{
+		dir:    dir,
+		name:   name,
+		digest: hash.Sum(nil),
+		used:   make(map[string]bool),
+	}
+}
+
+// mix adds the data which affects the compilation of the next units to the chain
+func (cache *contractCache) mix(data string) {
+	if cache == nil {
+		return
+	}
+	hash := sha256.New()
+	hash.Write(cache.digest)
+	hash.Write([]byte(data))
+	cache.digest = hash.Sum(nil)
+}
+
+// compile loads the byte-code of the unit from the cache. The source is compiled
+// if the unit isn't in the cache or the cached byte-code can't be decoded
+func (cache *contractCache) compile(vm *script.VM, src string, owner *script.OwnerInfo) error {
+	if cache == nil {
+		return vmCompile(vm, src, owner)
+	}
+	cache.mix(fmt.Sprintf("%d %t %d %d %d\n%s", owner.StateID, owner.Active, owner.TableID,
+		owner.WalletID, owner.TokenID, src))
+	filename := cache.name + `-` + hex.EncodeToString(cache.digest)
+	cache.used[filename] = true
+	path := filepath.Join(cache.dir, filename)
+
+	data, err := ioutil.ReadFile(path)
+	if err == nil {
+		var root *script.Block
+		if root, err = vm.DecodeBlock(data, owner); err == nil {
+			vm.FlushBlock(root)
+			return nil
+		}
+		log.WithFields(log.Fields{"type": consts.VMError, "error": err, "path": path}).Warning("decoding cached byte-code")
+	} else if !os.IsNotExist(err) {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": path}).Error("reading cached byte-code")
+	}
+
+	root, err := vm.CompileBlock([]rune(src), owner)
+	if err != nil {
+		return err
+	}
+	if data, err = vm.EncodeBlock(root); err != nil {
+		log.WithFields(log.Fields{"type": consts.VMError, "error": err}).Error("encoding byte-code")
+	} else {
+		cache.write(path, data)
+	}
+	vm.FlushBlock(root)
+	return nil
+}
+
+func (cache *contractCache) write(path string, data []byte) {
+	tmp := path + `.tmp`
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": tmp}).Error("writing cached byte-code")
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": path}).Error("renaming cached byte-code")
+		os.Remove(tmp)
+	}
+}
+
+// prune removes the files of the previous loadings which haven't been used by this one
+func (cache *contractCache) prune() {
+	if cache == nil {
+		return
+	}
+	files, err := ioutil.ReadDir(cache.dir)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": cache.dir}).Error("reading contracts cache directory")
+		return
+	}
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name(), cache.name+`-`) || cache.used[file.Name()] {
+			continue
+		}
+		path := filepath.Join(cache.dir, file.Name())
+		if err = os.Remove(path); err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": path}).Error("removing cached byte-code")
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package smart
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/GenesisKernel/go-genesis/packages/script"
+)
+
+// The levels of the issues of the contract sources
+const (
+	CheckError   = "error"
+	CheckWarning = "warning"
+)
+
+// checkState is the ecosystem which the checked contracts are compiled for
+const checkState = 1
+
+// DeprecatedFuncs are the embedded functions which are kept for the old contracts and their replacements
+var DeprecatedFuncs = map[string]string{
+	"JSONToMap": "JSONDecode",
+}
+
+// errPosition is the position of the error in the messages of the compiler
+var errPosition = regexp.MustCompile(`\s*\[Ln:(\d+)(?: Col:(\d+))?\]`)
+
+// CheckIssue is the error or the warning of the contract source
+type CheckIssue struct {
+	File    string `json:"file"`
+	Line    int64  `json:"line"`
+	Column  int64  `json:"column"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// NewCheckVM returns the virtual machine of the type with the embedded and the system functions,
+// the contracts are compiled by it without the database
+func NewCheckVM(vt script.VMType) (*script.VM, error) {
+	vm := newVM()
+	EmbedFuncs(vm, vt)
+	if err := LoadSysFuncs(vm, checkState); err != nil {
+		return nil, err
+	}
+	return vm, nil
+}
+
+// CheckContracts compiles the sources of the files and checks that the called functions are the embedded
+// functions of vm or the contracts and the functions of the sources. The calls of the other contracts
+// are only warnings because they can be in the blockchain. The issues are sorted by file and position
+func CheckContracts(vm *script.VM, sources map[string]string) []CheckIssue {
+	issues := make([]CheckIssue, 0)
+	declared := make(map[string]bool)
+	calls := make(map[string][]script.Call)
+	for file, src := range sources {
+		list, names, err := script.SourceCalls([]rune(src))
+		if err != nil {
+			issues = append(issues, compileIssue(file, err))
+			continue
+		}
+		calls[file] = list
+		for _, name := range names {
+			declared[name] = true
+		}
+	}
+
+	// the unknown functions are compiled as the calls of the contracts in the extern mode
+	vm.Extern = true
+	for file, src := range sources {
+		if _, ok := calls[file]; !ok {
+			continue
+		}
+		if _, err := vm.CompileBlock([]rune(src), &script.OwnerInfo{StateID: checkState}); err != nil {
+			issues = append(issues, compileIssue(file, err))
+		}
+		for _, call := range calls[file] {
+			issue := CheckIssue{File: file, Line: int64(call.Line), Column: column(call.Line, call.Column),
+				Level: CheckWarning}
+			if replace, ok := DeprecatedFuncs[call.Name]; ok {
+				issue.Message = fmt.Sprintf("%s is deprecated, use %s", call.Name, replace)
+			} else if !declared[call.Name] && !isVMObject(vm, call.Name) {
+				issue.Message = fmt.Sprintf("%s is neither a builtin function nor a contract of the checked files",
+					call.Name)
+			} else {
+				continue
+			}
+			issues = append(issues, issue)
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Column < issues[j].Column
+	})
+	return issues
+}
+
+func isVMObject(vm *script.VM, name string) bool {
+	if _, ok := vm.Objects[name]; ok {
+		return true
+	}
+	_, ok := vm.Objects[script.StateName(checkState, name)]
+	return ok
+}
+
+// compileIssue returns the error of the compiler with the position which is cut from the message
+func compileIssue(file string, err error) CheckIssue {
+	issue := CheckIssue{File: file, Level: CheckError, Message: err.Error()}
+	if match := errPosition.FindStringSubmatch(issue.Message); match != nil {
+		// the numbers of the position are matched by the regexp
+		line, _ := strconv.ParseUint(match[1], 10, 32)
+		col, _ := strconv.ParseUint(match[2], 10, 32)
+		issue.Line, issue.Column = int64(line), column(uint32(line), uint32(col))
+		issue.Message = errPosition.ReplaceAllString(issue.Message, "")
+	}
+	return issue
+}
+
+// column returns the column from 1, the lexer counts the columns after the first line from the new line character
+func column(line, col uint32) int64 {
+	if line > 1 && col > 0 {
+		col--
+	}
+	return int64(col)
+}
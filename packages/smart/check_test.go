@@ -0,0 +1,45 @@
+package smart
+
+import (
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/script"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckContracts(t *testing.T) {
+	sources := map[string]string{
+		"a.sim": `contract A {
+	action {
+		var m map
+		m = JSONToMap("{}")
+		B()
+		HTTPRequest("url", "GET", m, m)
+	}
+}`,
+		"b.sim": `contract B {
+	action {
+		DBFind("keys").Columns("id")
+	}
+}`,
+		"c.sim": `contract C {
+	func {
+	}
+}`,
+	}
+	vm, err := NewCheckVM(script.VMTypeSmart)
+	require.NoError(t, err)
+	assert.Equal(t, []CheckIssue{
+		{"a.sim", 4, 7, CheckWarning, "JSONToMap is deprecated, use JSONDecode"},
+		{"a.sim", 6, 3, CheckWarning, "HTTPRequest is neither a builtin function nor a contract of the checked files"},
+		{"c.sim", 2, 7, CheckError, "must be the name 7b01 123"},
+	}, CheckContracts(vm, sources))
+
+	vm, err = NewCheckVM(script.VMTypeVDE)
+	require.NoError(t, err)
+	issues := CheckContracts(vm, map[string]string{"a.sim": sources["a.sim"]})
+	require.Len(t, issues, 2)
+	assert.Equal(t, "B is neither a builtin function nor a contract of the checked files", issues[1].Message)
+}
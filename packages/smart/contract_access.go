@@ -0,0 +1,158 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	accessName = iota // the name of the contract
+	accessGlob        // the name of the contract with * and ? wildcards
+	accessApp         // @app:<id> any contract of the application
+	accessRole        // @role:<id> any contract which owner has the role
+)
+
+var (
+	// the ecosystem of the contract name is never matched by the wildcards
+	regAccessName = regexp.MustCompile(`^(?:@(\d+))?([A-Za-z_*?][A-Za-z0-9_*?]*)$`)
+	regAccessID   = regexp.MustCompile(`^@(app|role):([1-9]\d*)$`)
+)
+
+// accessPattern is the parsed parameter of ContractAccess
+type accessPattern struct {
+	kind  int
+	state int64
+	name  string
+	id    int64
+}
+
+// parseAccessPattern parses the parameter of ContractAccess, state is the ecosystem of the names without it
+func parseAccessPattern(pattern string, state int64) (*accessPattern, error) {
+	if match := regAccessID.FindStringSubmatch(pattern); match != nil {
+		id, err := strconv.ParseInt(match[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(eAccessPattern, pattern)
+		}
+		kind := accessApp
+		if match[1] == `role` {
+			kind = accessRole
+		}
+		return &accessPattern{kind: kind, state: state, id: id}, nil
+	}
+	match := regAccessName.FindStringSubmatch(pattern)
+	if match == nil {
+		return nil, fmt.Errorf(eAccessPattern, pattern)
+	}
+	p := &accessPattern{kind: accessName, state: state, name: match[2]}
+	if len(match[1]) > 0 {
+		var err error
+		if p.state, err = strconv.ParseInt(match[1], 10, 64); err != nil {
+			return nil, fmt.Errorf(eAccessPattern, pattern)
+		}
+	}
+	if strings.ContainsAny(p.name, `*?`) {
+		p.kind = accessGlob
+	}
+	return p, nil
+}
+
+// splitContractName returns the ecosystem and the name of the full contract name like @1EditPage
+func splitContractName(name string) (int64, string) {
+	match := regAccessName.FindStringSubmatch(name)
+	if match == nil || len(match[1]) == 0 || strings.ContainsAny(match[2], `*?`) {
+		return 0, ``
+	}
+	state, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0, ``
+	}
+	return state, match[2]
+}
+
+// match checks the full name of the calling contract
+func (p *accessPattern) match(sc *SmartContract, contName string) (bool, error) {
+	state, name := splitContractName(contName)
+	if len(name) == 0 || state != p.state {
+		return false, nil
+	}
+	switch p.kind {
+	case accessName:
+		return name == p.name, nil
+	case accessGlob:
+		return path.Match(p.name, name)
+	}
+	contract := VMGetContract(sc.VM, contName, uint32(state))
+	if contract == nil {
+		return false, nil
+	}
+	owner := contract.Block.Info.(*script.ContractInfo).Owner
+	if p.kind == accessRole {
+		return model.MemberHasRole(sc.DbTransaction, state, owner.WalletID, p.id)
+	}
+	app, err := model.GetOneRowTransaction(sc.DbTransaction, fmt.Sprintf(`SELECT app_id FROM "%d_contracts" WHERE id = ?`,
+		state), owner.TableID).Int64()
+	if err != nil {
+		return false, err
+	}
+	return app[`app_id`] == p.id, nil
+}
+
+// contractAccess checks the calling contract against the parameter of ContractAccess
+func (sc *SmartContract) contractAccess(contName, name string) bool {
+	if !strings.ContainsAny(name, `*?:`) {
+		if name[0] != '@' {
+			name = fmt.Sprintf(`@%d`, sc.TxSmart.EcosystemID) + name
+		}
+		return contName == name
+	}
+	p, err := parseAccessPattern(name, sc.TxSmart.EcosystemID)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.InvalidObject, "error": err}).Error("parsing contract access pattern")
+		return false
+	}
+	ok, err := p.match(sc, contName)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "pattern": name}).Error("matching contract access pattern")
+		return false
+	}
+	return ok
+}
+
+// checkContractAccess validates the constant parameters of ContractAccess calls of the condition
+func checkContractAccess(condition string) error {
+	list, err := script.CallStrings([]rune(condition), `ContractAccess`)
+	if err != nil {
+		return err
+	}
+	for _, name := range list {
+		if _, err = parseAccessPattern(name, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package smart
+
+import (
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContractAccess(t *testing.T) {
+	access := func(ecosystem int64, contract string, names ...interface{}) bool {
+		sc := &SmartContract{
+			TxSmart:    tx.SmartContract{Header: tx.Header{EcosystemID: ecosystem}},
+			TxContract: &Contract{StackCont: []interface{}{contract, `DBFind`}},
+		}
+		return ContractAccess(sc, names...)
+	}
+	assert.True(t, access(1, "@1EditPage", "@1NewPage", "@1EditPage"))
+	assert.True(t, access(1, "@1EditPage", "EditPage"))
+	assert.False(t, access(2, "@1EditPage", "EditPage"))
+	assert.True(t, access(1, "@1EditPage", "@1*Page"))
+	assert.True(t, access(3, "@3NewPage", "*Page"))
+	assert.True(t, access(1, "@1EditMenu", "@1Edit????"))
+	assert.False(t, access(1, "@1EditPages", "@1*Page"))
+	assert.False(t, access(1, "@1EditPage", "@1Edit"))
+
+	// the contracts of other ecosystems which names collide with the pattern
+	assert.False(t, access(1, "@12EvilPage", "@1*Page"))
+	assert.False(t, access(1, "@11Page", "*Page"))
+	assert.False(t, access(1, "@21EditPage", "@*EditPage"))
+	assert.False(t, access(1, "@2EditPage", "@1EditPage*"))
+}
+
+func TestCheckContractAccess(t *testing.T) {
+	assert.NoError(t, checkContractAccess(`ContractAccess("@1*Page", "NewMenu", "@app:2", "@role:1", $name)`))
+	assert.NoError(t, checkContractAccess(`true`))
+	for _, name := range []string{"@*Page", "@1[a-z]Page", "Edit Page", "@app:0", "@app:x", "@role:", "@1", "1Page"} {
+		assert.EqualError(t, checkContractAccess(`ContractAccess("`+name+`")`),
+			"Wrong contract access pattern "+name, name)
+	}
+}
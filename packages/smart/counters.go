@@ -0,0 +1,222 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// tableCounter is the counter of the rows which is kept in the column of other table.
+// The value of KeyColumn is the id of the row of Table whose CounterColumn is changed
+type tableCounter struct {
+	Table         string `json:"table"`
+	KeyColumn     string `json:"key_column"`
+	CounterColumn string `json:"counter_column"`
+}
+
+// counterInfo is the counters of the table and the columns which must be read before the update
+type counterInfo struct {
+	counters []tableCounter
+	columns  []string
+}
+
+// counterDelta is the change of the counter of the row of the target table
+type counterDelta struct {
+	table  string
+	column string
+	id     string
+	delta  int
+}
+
+// checkCounters checks that the key columns are in the columns of the table and
+// the target tables have the number counter columns
+func checkCounters(sc *SmartContract, prefix string, counters []tableCounter, columns map[string]bool) error {
+	for _, counter := range counters {
+		if len(counter.Table) == 0 || len(counter.KeyColumn) == 0 || len(counter.CounterColumn) == 0 {
+			log.WithFields(log.Fields{"type": consts.EmptyObject}).Error("counter is incomplete")
+			return fmt.Errorf(`counter must have table, key_column and counter_column`)
+		}
+		if !columns[strings.ToLower(counter.KeyColumn)] {
+			log.WithFields(log.Fields{"type": consts.NotFound, "column": counter.KeyColumn}).Error("counter key column does not exist")
+			return fmt.Errorf(eColumnNotFound, counter.KeyColumn)
+		}
+		target := &model.Table{}
+		target.SetTablePrefix(prefix)
+		found, err := target.Get(sc.DbTransaction, strings.ToLower(counter.Table))
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting counter table")
+			return err
+		}
+		if !found {
+			log.WithFields(log.Fields{"type": consts.NotFound, "table_name": counter.Table}).Error("counter table does not exist")
+			return fmt.Errorf(eTableNotFound, counter.Table)
+		}
+		var cols map[string]string
+		if err = json.Unmarshal([]byte(target.Columns), &cols); err != nil {
+			log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("getting counter table columns")
+			return err
+		}
+		column := strings.ToLower(counter.CounterColumn)
+		if _, ok := cols[column]; !ok {
+			log.WithFields(log.Fields{"type": consts.NotFound, "column": counter.CounterColumn}).Error("counter column does not exist")
+			return fmt.Errorf(eColumnNotFound, counter.CounterColumn)
+		}
+		itype, err := model.GetColumnType(prefix+`_`+strings.ToLower(counter.Table), column)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting counter column type")
+			return err
+		}
+		if itype != `number` && itype != `money` {
+			log.WithFields(log.Fields{"type": consts.InvalidObject, "column": counter.CounterColumn}).Error("counter column is not number")
+			return fmt.Errorf(`counter column %s must be number or money`, counter.CounterColumn)
+		}
+	}
+	return nil
+}
+
+// tableCounters returns the counters which are declared in the permissions of the table.
+// The counters are kept until the end of the transaction or until the table of the tables is changed
+func (sc *SmartContract) tableCounters(table string) (*counterInfo, error) {
+	if info, ok := sc.counters[table]; ok {
+		return info, nil
+	}
+	info := &counterInfo{}
+	prefix, name := PrefixName(table)
+	if len(prefix) > 0 {
+		tables := &model.Table{}
+		tables.SetTablePrefix(prefix)
+		found, err := tables.Get(sc.DbTransaction, name)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table}).Error("getting table permissions")
+			return nil, err
+		}
+		if found && len(tables.Permissions) > 0 {
+			var perm permTable
+			if err = json.Unmarshal([]byte(tables.Permissions), &perm); err != nil {
+				log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("getting table permissions")
+				return nil, err
+			}
+			info.counters = perm.Counters
+		}
+		if len(info.counters) > 0 {
+			var cols map[string]string
+			if err = json.Unmarshal([]byte(tables.Columns), &cols); err != nil {
+				log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("getting table columns")
+				return nil, err
+			}
+			if _, ok := cols[`deleted`]; ok {
+				info.columns = append(info.columns, `deleted`)
+			}
+			for _, counter := range info.counters {
+				info.columns = append(info.columns, strings.ToLower(counter.KeyColumn))
+			}
+		}
+	}
+	if sc.counters == nil {
+		sc.counters = make(map[string]*counterInfo)
+	}
+	sc.counters[table] = info
+	return info, nil
+}
+
+// counterDeltas returns the changes of the counters for the written row. The inserted row
+// increments the counter, the row which is marked as deleted decrements it and the row which
+// is restored or moved to other key changes the counters of the both keys
+func counterDeltas(prefix string, counters []tableCounter, fields, values []string,
+	logData map[string]string, isInsert bool) []counterDelta {
+	newValues := make(map[string]string)
+	for i, field := range fields {
+		if i < len(values) {
+			newValues[field] = values[i]
+		}
+	}
+	deleted := func(data map[string]string) bool {
+		return data[`deleted`] == `1`
+	}
+	var deltas []counterDelta
+	add := func(counter tableCounter, id string, delta int) {
+		if key, err := converter.StrToInt64E(id); err != nil || key <= 0 {
+			return
+		}
+		deltas = append(deltas, counterDelta{
+			table:  prefix + `_` + strings.ToLower(counter.Table),
+			column: strings.ToLower(counter.CounterColumn),
+			id:     id,
+			delta:  delta,
+		})
+	}
+	for _, counter := range counters {
+		key := strings.ToLower(counter.KeyColumn)
+		if isInsert {
+			if !deleted(newValues) {
+				add(counter, newValues[key], 1)
+			}
+			continue
+		}
+		after := make(map[string]string)
+		for _, column := range []string{key, `deleted`} {
+			after[column] = logData[column]
+			if v, ok := newValues[column]; ok {
+				after[column] = v
+			}
+		}
+		wasCounted, isCounted := !deleted(logData), !deleted(after)
+		if wasCounted == isCounted && logData[key] == after[key] {
+			continue
+		}
+		if wasCounted {
+			add(counter, logData[key], -1)
+		}
+		if isCounted {
+			add(counter, after[key], 1)
+		}
+	}
+	return deltas
+}
+
+// updateCounters changes the counters of the target tables through selectiveLoggingAndUpd
+// so the changes are rolled back with the written row
+func (sc *SmartContract) updateCounters(table string, fields, values []string,
+	logData map[string]string, isInsert, generalRollback bool) (int64, error) {
+	info, err := sc.tableCounters(table)
+	if err != nil || len(info.counters) == 0 {
+		return 0, err
+	}
+	prefix, _ := PrefixName(table)
+	var cost int64
+	for _, item := range counterDeltas(prefix, info.counters, fields, values, logData, isInsert) {
+		field := `+` + item.column
+		if item.delta < 0 {
+			field = `-` + item.column
+		}
+		itemCost, _, err := sc.selectiveLoggingAndUpd([]string{field}, []interface{}{1}, item.table,
+			[]string{`id`}, []string{item.id}, generalRollback, true)
+		if err != nil {
+			return 0, err
+		}
+		cost += itemCost
+	}
+	return cost, nil
+}
@@ -0,0 +1,36 @@
+package smart
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterDeltas(t *testing.T) {
+	counters := []tableCounter{{Table: "Categories", KeyColumn: "category_id", CounterColumn: "posts_count"}}
+	inc := func(id string) counterDelta {
+		return counterDelta{table: "1_categories", column: "posts_count", id: id, delta: 1}
+	}
+	dec := func(id string) counterDelta {
+		return counterDelta{table: "1_categories", column: "posts_count", id: id, delta: -1}
+	}
+
+	cases := []struct {
+		fields, values []string
+		logData        map[string]string
+		isInsert       bool
+		want           []counterDelta
+	}{
+		{[]string{"title", "category_id"}, []string{"post", "3"}, nil, true, []counterDelta{inc("3")}},
+		{[]string{"title"}, []string{"post"}, nil, true, nil},
+		{[]string{"category_id", "deleted"}, []string{"3", "1"}, nil, true, nil},
+		{[]string{"title"}, []string{"new"}, map[string]string{"category_id": "3", "deleted": "0"}, false, nil},
+		{[]string{"deleted"}, []string{"1"}, map[string]string{"category_id": "3", "deleted": "0"}, false, []counterDelta{dec("3")}},
+		{[]string{"deleted"}, []string{"0"}, map[string]string{"category_id": "3", "deleted": "1"}, false, []counterDelta{inc("3")}},
+		{[]string{"category_id"}, []string{"4"}, map[string]string{"category_id": "3", "deleted": "0"}, false, []counterDelta{dec("3"), inc("4")}},
+		{[]string{"category_id"}, []string{"4"}, map[string]string{"category_id": "3", "deleted": "1"}, false, nil},
+	}
+	for i, v := range cases {
+		assert.Equal(t, v.want, counterDeltas("1", counters, v.fields, v.values, v.logData, v.isInsert), "case %d", i)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"fmt"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// trySavepoint is the index of the savepoint of TryCallContract, the savepoints of
+// the transactions of the block have non-negative indexes
+const trySavepoint = -1
+
+// TryCallContract calls the contract like CallContract but the error of the called contract
+// doesn't break the current one. The changes of the failed contract are rolled back and
+// the returned map contains the result and the error of the call
+func TryCallContract(sc *SmartContract, rt *script.RunTime, name string,
+	params map[string]interface{}) (map[string]interface{}, error) {
+
+	if sc.DbTransaction == nil {
+		return nil, errTryCallContract
+	}
+	if err := sc.DbTransaction.Savepoint(trySavepoint); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("creating savepoint")
+		return nil, err
+	}
+	vars := *sc.TxContract.Extend
+	extend := make(map[string]interface{}, len(vars))
+	for key, val := range vars {
+		extend[key] = val
+	}
+	stackLen := len(sc.TxContract.StackCont)
+
+	ret := map[string]interface{}{`result`: ``, `error`: ``}
+	result, err := script.ExContract(rt, uint32(sc.TxSmart.EcosystemID), name, params)
+	if err != nil {
+		if errRollback := sc.DbTransaction.RollbackSavepoint(trySavepoint); errRollback != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": errRollback}).Error("rollback to savepoint")
+			return nil, errRollback
+		}
+		for key := range vars {
+			delete(vars, key)
+		}
+		for key, val := range extend {
+			vars[key] = val
+		}
+		sc.TxContract.StackCont = sc.TxContract.StackCont[:stackLen]
+		vars[`stack`] = sc.contractStack()
+		ret[`error`] = err.Error()
+		return ret, nil
+	}
+	if err = sc.DbTransaction.ReleaseSavepoint(trySavepoint); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("releasing savepoint")
+		return nil, err
+	}
+	if result != nil {
+		ret[`result`] = fmt.Sprint(result)
+	}
+	return ret, nil
+}
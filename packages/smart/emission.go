@@ -0,0 +1,148 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+const emissionContractParam = `emission_contract`
+
+var (
+	errEmissionContract = errors.New(`Tokens can be only issued or burnt by the emission contract of the ecosystem`)
+	errEmissionAmount   = errors.New(`Amount of tokens must be a positive integer`)
+	errBurnBalance      = errors.New(`Balance is not enough to burn the tokens`)
+	errBurnSupply       = errors.New(`Total supply is not enough to burn the tokens`)
+)
+
+// checkEmission checks that the current contract is the emission contract of the ecosystem
+// and returns the amount of tokens
+func (sc *SmartContract) checkEmission(value interface{}) (decimal.Decimal, error) {
+	amount, err := Money(value)
+	if err != nil {
+		return amount, err
+	}
+	if amount.Sign() <= 0 || !amount.Equal(amount.Floor()) {
+		return amount, errEmissionAmount
+	}
+	name := EcosysParam(sc, emissionContractParam)
+	if sc.VDE || len(name) == 0 ||
+		script.StateName(uint32(sc.TxSmart.EcosystemID), name) != sc.stackContract(-1) {
+		log.WithFields(log.Fields{"type": consts.AccessDenied, "contract": sc.stackContract(-1),
+			"emission_contract": name}).Error("issuing or burning tokens")
+		return amount, errEmissionContract
+	}
+	return amount, nil
+}
+
+// balances returns the balance of the key and the total supply of the ecosystem
+func (sc *SmartContract) balances(keyID int64) (balance, supply decimal.Decimal, err error) {
+	key := &model.Key{}
+	if _, err = key.SetTablePrefix(sc.TxSmart.EcosystemID).GetTransaction(sc.DbTransaction, keyID); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting key")
+		return
+	}
+	ecosystem := &model.Ecosystem{}
+	if _, err = ecosystem.GetTransaction(sc.DbTransaction, sc.TxSmart.EcosystemID); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting ecosystem")
+		return
+	}
+	if balance, err = decimalOrZero(key.Amount); err != nil {
+		return
+	}
+	supply, err = decimalOrZero(ecosystem.TotalSupply)
+	return
+}
+
+func decimalOrZero(value string) (decimal.Decimal, error) {
+	if len(value) == 0 {
+		return decimal.Zero, nil
+	}
+	ret, err := decimal.NewFromString(value)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": value}).Error("converting to decimal")
+	}
+	return ret, err
+}
+
+// setSupply writes the total supply of the ecosystem
+func (sc *SmartContract) setSupply(supply decimal.Decimal) error {
+	_, _, err := sc.selectiveLoggingAndUpd([]string{`total_supply`}, []interface{}{supply.String()},
+		(&model.Ecosystem{}).TableName(), []string{`id`}, []string{strconv.FormatInt(sc.TxSmart.EcosystemID, 10)},
+		sc.Rollback, false)
+	return err
+}
+
+// Issue creates the tokens of the ecosystem on the balance of the recipient
+func Issue(sc *SmartContract, recipient int64, value interface{}) error {
+	amount, err := sc.checkEmission(value)
+	if err != nil {
+		return err
+	}
+	_, supply, err := sc.balances(recipient)
+	if err != nil {
+		return err
+	}
+	err = sc.moveTokens(ledgerEntry{ecosystem: sc.TxSmart.EcosystemID, recipient: recipient, amount: amount,
+		kind: model.HistoryEmission}, sc.Rollback)
+	if err != nil {
+		return err
+	}
+	return sc.setSupply(supply.Add(amount))
+}
+
+// Burn destroys the tokens of the ecosystem on the balance of the holder
+func Burn(sc *SmartContract, holder int64, value interface{}) error {
+	amount, err := sc.checkEmission(value)
+	if err != nil {
+		return err
+	}
+	balance, supply, err := sc.balances(holder)
+	if err != nil {
+		return err
+	}
+	if balance.LessThan(amount) {
+		return errBurnBalance
+	}
+	if supply.LessThan(amount) {
+		return errBurnSupply
+	}
+	err = sc.moveTokens(ledgerEntry{ecosystem: sc.TxSmart.EcosystemID, sender: holder, amount: amount,
+		kind: model.HistoryEmission}, sc.Rollback)
+	if err != nil {
+		return err
+	}
+	return sc.setSupply(supply.Sub(amount))
+}
+
+// TotalSupply returns the amount of the issued tokens of the ecosystem
+func TotalSupply(sc *SmartContract) (decimal.Decimal, error) {
+	ecosystem := &model.Ecosystem{}
+	if _, err := ecosystem.GetTransaction(sc.DbTransaction, sc.TxSmart.EcosystemID); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting ecosystem")
+		return decimal.Zero, err
+	}
+	return decimalOrZero(ecosystem.TotalSupply)
+}
@@ -19,10 +19,13 @@ package smart
 import "errors"
 
 const (
-	eTableNotFound = `Table %s has not been found`
-	eContractLoop  = `There is loop in %s contract`
-	eContractExist = `Contract %s already exists`
-	eLatin         = `Name %s must only contain latin, digit and '_', '-' characters`
+	eTableNotFound  = `Table %s has not been found`
+	eColumnNotFound = `Column %s has not been found`
+	eContractLoop   = `There is loop in %s contract`
+	eContractExist  = `Contract %s already exists`
+	eLatin          = `Name %s must only contain latin, digit and '_', '-' characters`
+	eAccessPattern  = `Wrong contract access pattern %s`
+	eNotSoftDelete  = `Table %s doesn't support soft delete`
 )
 
 var (
@@ -31,9 +34,10 @@ var (
 	errConditionEmpty         = errors.New(`Conditions is empty`)
 	errContractNotFound       = errors.New(`Contract has not been found`)
 	errAccessRollbackContract = errors.New(`RollbackContract can be only called from Import or NewContract`)
-	errCommission             = errors.New("There is not enough money to pay the commission fee")
 	errEmptyColumn            = errors.New(`Column name is empty`)
 	errWrongColumn            = errors.New(`Column name cannot begin with digit`)
 	errNotFound               = errors.New(`Record has not been found`)
+	errSoftDeleteColumn       = errors.New(`Soft delete requires the deleted column of number type`)
 	errNow                    = errors.New(`It is prohibited to use NOW() or current time functions`)
+	errTryCallContract        = errors.New(`TryCallContract can be only called in the transaction of the block`)
 )
@@ -0,0 +1,90 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxEventSize is the maximum size of the JSON data of the event
+	maxEventSize = 8192
+	// eventByteCost is the cost of the byte of the event data
+	eventByteCost = 1
+)
+
+var (
+	regEventName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,254}$`)
+
+	errEventName = errors.New(`Event name must be an identifier from 1 to 255 characters`)
+	errEventVDE  = errors.New(`Events can't be emitted in VDE`)
+)
+
+// EmitEvent appends the event with the name and the data to the log of the events of the ecosystem.
+// The event is published to centrifugo after the commit of the block
+func EmitEvent(sc *SmartContract, rt *script.RunTime, name string, data map[string]interface{}) error {
+	if sc.VDE {
+		return errEventVDE
+	}
+	if !regEventName.MatchString(name) {
+		return errEventName
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling event data")
+		return err
+	}
+	if len(payload) > maxEventSize {
+		return fmt.Errorf(`Event data is larger than %d bytes`, maxEventSize)
+	}
+	if rt != nil {
+		rt.SetCost(rt.Cost() - int64(len(payload))*eventByteCost)
+		if rt.Cost() < 0 {
+			log.WithFields(log.Fields{"type": consts.VMError, "event": name}).Error("paid CPU resource is over")
+			return fmt.Errorf(`paid CPU resource is over`)
+		}
+	}
+	var blockID int64
+	if sc.BlockData != nil {
+		blockID = sc.BlockData.BlockID
+	}
+	event := &model.Event{}
+	event.SetTablePrefix(sc.TxSmart.EcosystemID)
+	_, _, err = sc.selectiveLoggingAndUpd([]string{`block_id`, `tx_hash`, `contract`, `name`, `data`},
+		[]interface{}{blockID, hex.EncodeToString(sc.TxHash), sc.stackContract(-1), name, string(payload)},
+		event.TableName(), nil, nil, sc.Rollback, false)
+	if err != nil {
+		return err
+	}
+	if sc.Notifications != nil {
+		sc.Notifications.AddEvents(sc.TxSmart.EcosystemID, blockID)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	founderParam         = `founder_account`
+	changingFounderParam = `changing_founder`
+	founderHistoryTable  = `founder_history`
+)
+
+var (
+	errTransferOwnership = errors.New(`TransferEcosystemOwnership can be only called from TransferEcosystemOwnership`)
+	errSameFounder       = errors.New(`The key is already the founder of the ecosystem`)
+	errFounderKey        = errors.New(`The key of the new founder has not been found in the ecosystem`)
+	errFounderVDE        = errors.New(`The founder of VDE can't be changed`)
+)
+
+// TransferEcosystemOwnership makes the key the founder of the ecosystem of the transaction.
+// The changing_founder condition of the ecosystem must be passed
+func TransferEcosystemOwnership(sc *SmartContract, newFounder int64) error {
+	if sc.VDE {
+		return errFounderVDE
+	}
+	if !accessContracts(sc, `TransferEcosystemOwnership`) {
+		log.WithFields(log.Fields{"type": consts.IncorrectCallingContract}).Error("TransferEcosystemOwnership can be only called from @1TransferEcosystemOwnership")
+		return errTransferOwnership
+	}
+	if err := sc.AccessRights(changingFounderParam, false); err != nil {
+		return err
+	}
+	founder, err := strconv.ParseInt(EcosysParam(sc, founderParam), 10, 64)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting founder account")
+		return err
+	}
+	if founder == newFounder {
+		return errSameFounder
+	}
+	key := &model.Key{}
+	key.SetTablePrefix(sc.TxSmart.EcosystemID)
+	found, err := key.GetTransaction(sc.DbTransaction, newFounder)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting key of the new founder")
+		return err
+	}
+	if !found || key.Deleted == 1 {
+		return errFounderKey
+	}
+
+	_, _, err = sc.selectiveLoggingAndUpd([]string{`value`}, []interface{}{strconv.FormatInt(newFounder, 10)},
+		getDefTableName(sc, `parameters`), []string{`name`}, []string{founderParam}, sc.Rollback, false)
+	if err != nil {
+		return err
+	}
+	var blockID int64
+	if sc.BlockData != nil {
+		blockID = sc.BlockData.BlockID
+	}
+	_, _, err = sc.selectiveLoggingAndUpd([]string{`old_founder`, `new_founder`, `block_id`, `tx_hash`,
+		`timestamp date_created`}, []interface{}{founder, newFounder, blockID, hex.EncodeToString(sc.TxHash),
+		sc.TxSmart.Time}, sc.tablePrefix()+`_`+founderHistoryTable, nil, nil, sc.Rollback, false)
+	if err != nil {
+		return err
+	}
+	// the accesses granted by the conditions of the previous founder are not valid anymore
+	sc.access = nil
+	return nil
+}
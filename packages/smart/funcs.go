@@ -42,6 +42,7 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/converter"
 	"github.com/GenesisKernel/go-genesis/packages/crypto"
 	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/notificator"
 	"github.com/GenesisKernel/go-genesis/packages/scheduler"
 	"github.com/GenesisKernel/go-genesis/packages/scheduler/contract"
 	"github.com/GenesisKernel/go-genesis/packages/script"
@@ -62,16 +63,36 @@ const (
 var BOM = []byte{0xEF, 0xBB, 0xBF}
 
 type permTable struct {
-	Insert    string `json:"insert"`
-	Update    string `json:"update"`
-	NewColumn string `json:"new_column"`
-	Read      string `json:"read,omitempty"`
-	Filter    string `json:"filter,omitempty"`
+	Insert    string         `json:"insert"`
+	Update    string         `json:"update"`
+	NewColumn string         `json:"new_column"`
+	Read      string         `json:"read,omitempty"`
+	Filter    string         `json:"filter,omitempty"`
+	Counters  []tableCounter `json:"counters,omitempty"`
+	// SoftDelete hides the rows marked by the deleted column from DBSelect and the API
+	SoftDelete bool `json:"soft_delete,omitempty"`
 }
 
 type permColumn struct {
 	Update string `json:"update"`
 	Read   string `json:"read,omitempty"`
+	Min    string `json:"min,omitempty"`
+	Max    string `json:"max,omitempty"`
+	Ref    string `json:"ref,omitempty"` // the table which ids are in the column, see model.BinaryRef
+}
+
+// checkColumnRef checks the table of the column which has the references, only binaries are supported
+func checkColumnRef(colType string, perm permColumn) error {
+	if len(perm.Ref) == 0 {
+		return nil
+	}
+	if perm.Ref != model.BinaryRefTable {
+		return fmt.Errorf(`Column can only reference %s`, model.BinaryRefTable)
+	}
+	if colType != `number` && colType != `varchar` {
+		return fmt.Errorf(`Reference can be only defined for number and varchar columns`)
+	}
+	return nil
 }
 
 // SmartContract is storing smart contract data
@@ -80,6 +101,7 @@ type SmartContract struct {
 	Rollback      bool
 	FullAccess    bool
 	SysUpdate     bool
+	ReadOnly      bool // the changes of the database are forbidden, see errReadOnly
 	VM            *script.VM
 	TxSmart       tx.SmartContract
 	TxData        map[string]interface{}
@@ -91,7 +113,14 @@ type SmartContract struct {
 	Loop          map[string]bool
 	TxHash        []byte
 	PublicKeys    [][]byte
+	VerifiedKey   []byte // the signature is checked before calling if it's equal to the public key
 	DbTransaction *model.DbTransaction
+	Notifications *notificator.Batch
+	overrides     map[string]int64    // the values of the overridden system parameters of the ecosystem
+	access        map[string][]string // the granted accesses to the tables of the transaction, see accessKey
+	bounds        map[string]map[string]permColumn // the bounds of the columns of the tables, see columnBounds
+	counters      map[string]*counterInfo          // the counters of the tables, see tableCounters
+	tracer        script.Tracer                    // the tracer of the debug execution, see SetTracer
 }
 
 // AppendStack adds an element to the stack of contract call or removes the top element when name is empty
@@ -107,22 +136,43 @@ func (sc *SmartContract) AppendStack(contract string) error {
 	} else {
 		cont.StackCont = cont.StackCont[:len(cont.StackCont)-1]
 	}
-	(*sc.TxContract.Extend)["stack"] = cont.StackCont
+	(*sc.TxContract.Extend)["stack"] = sc.contractStack()
 	return nil
 }
 
 var (
 	funcCallsDB = map[string]struct{}{
-		"DBInsert":    {},
-		"DBSelect":    {},
-		"DBUpdate":    {},
-		"DBUpdateExt": {},
-		"SetPubKey":   {},
+		"DBInsert":     {},
+		"DBSelect":     {},
+		"DBUpdate":     {},
+		"MarkDeleted":  {},
+		"DBUpdateExt":  {},
+		"SetPubKey":    {},
+		"UpdateKeyPub": {},
 	}
 	extendCost = map[string]int64{
 		"AddressToId":                  10,
+		"AlterColumnType":              100,
 		"ColumnCondition":              50,
 		"Contains":                     10,
+		"CallerContract":               10,
+		"OriginalContract":             10,
+		"UpdateMember":                 50,
+		"CreateRole":                   50,
+		"AssignRole":                   50,
+		"RemoveRole":                   50,
+		"RoleAccess":                   50,
+		"TryCallContract":              50,
+		"StartVoting":                  50,
+		"Vote":                         50,
+		"Issue":                        50,
+		"Burn":                         50,
+		"TotalSupply":                  10,
+		"TransferTokens":               50,
+		"EmitEvent":                    50,
+		"TransferEcosystemOwnership":   50,
+		"CreateSyncSource":             50,
+		"PauseSyncSource":              50,
 		"ContractAccess":               50,
 		"ContractConditions":           50,
 		"ContractName":                 10,
@@ -131,16 +181,23 @@ var (
 		"CreateLanguage":               50,
 		"EditLanguage":                 50,
 		"CreateContract":               60,
+		"CreateContracts":              60,
 		"UpdateContract":               60,
+		"RunContractUpgrade":           50,
 		"EcosysParam":                  10,
 		"AppParam":                     10,
 		"Eval":                         10,
 		"EvalCondition":                20,
 		"GetContractByName":            20,
 		"GetContractById":              20,
+		"GetPage":                      20,
+		"GetMenu":                      20,
+		"GetBlockInterface":            20,
+		"UpdateMenuItem":               60,
 		"HMac":                         50,
 		"Join":                         10,
 		"JSONToMap":                    50,
+		"JSONDecodeSchema":             50,
 		"Sha256":                       50,
 		"IdToAddress":                  10,
 		"Len":                          5,
@@ -169,7 +226,7 @@ var (
 	}
 )
 
-func getCost(name string) int64 {
+func getCost(name string, _ map[string]interface{}) int64 {
 	if val, ok := extendCost[name]; ok {
 		return val
 	}
@@ -180,8 +237,25 @@ func getCost(name string) int64 {
 func EmbedFuncs(vm *script.VM, vt script.VMType) {
 	f := map[string]interface{}{
 		"AddressToId":                  AddressToID,
+		"AlterColumnType":              AlterColumnType,
 		"ColumnCondition":              ColumnCondition,
 		"Contains":                     strings.Contains,
+		"CallerContract":               CallerContract,
+		"OriginalContract":             OriginalContract,
+		"UpdateMember":                 UpdateMember,
+		"CreateRole":                   CreateRole,
+		"AssignRole":                   AssignRole,
+		"RemoveRole":                   RemoveRole,
+		"RoleAccess":                   RoleAccess,
+		"TryCallContract":              TryCallContract,
+		"StartVoting":                  StartVoting,
+		"Vote":                         Vote,
+		"Issue":                        Issue,
+		"Burn":                         Burn,
+		"TotalSupply":                  TotalSupply,
+		"TransferTokens":               TransferTokens,
+		"EmitEvent":                    EmitEvent,
+		"TransferEcosystemOwnership":   TransferEcosystemOwnership,
 		"ContractAccess":               ContractAccess,
 		"ContractConditions":           ContractConditions,
 		"ContractName":                 contractName,
@@ -191,13 +265,16 @@ func EmbedFuncs(vm *script.VM, vt script.VMType) {
 		"DBInsert":                     DBInsert,
 		"DBSelect":                     DBSelect,
 		"DBUpdate":                     DBUpdate,
+		"MarkDeleted":                  MarkDeleted,
 		"DBUpdateSysParam":             UpdateSysParam,
+		"DBScheduleSysParam":           ScheduleSysParam,
 		"DBUpdateExt":                  DBUpdateExt,
 		"EcosysParam":                  EcosysParam,
 		"AppParam":                     AppParam,
 		"SysParamString":               SysParamString,
 		"SysParamInt":                  SysParamInt,
 		"SysFuel":                      SysFuel,
+		"HasFuelRate":                  HasFuelRate,
 		"Eval":                         Eval,
 		"EvalCondition":                EvalCondition,
 		"Float":                        Float,
@@ -207,6 +284,7 @@ func EmbedFuncs(vm *script.VM, vt script.VMType) {
 		"Join":                         Join,
 		"JSONToMap":                    JSONDecode, // Deprecated
 		"JSONDecode":                   JSONDecode,
+		"JSONDecodeSchema":             JSONDecodeSchema,
 		"JSONEncode":                   JSONEncode,
 		"IdToAddress":                  IDToAddress,
 		"Int":                          Int,
@@ -231,7 +309,9 @@ func EmbedFuncs(vm *script.VM, vt script.VMType) {
 		"ToUpper":                      strings.ToUpper,
 		"CreateEcosystem":              CreateEcosystem,
 		"CreateContract":               CreateContract,
+		"CreateContracts":              CreateContracts,
 		"UpdateContract":               UpdateContract,
+		"RunContractUpgrade":           RunContractUpgrade,
 		"TableConditions":              TableConditions,
 		"CreateLanguage":               CreateLanguage,
 		"EditLanguage":                 EditLanguage,
@@ -245,12 +325,14 @@ func EmbedFuncs(vm *script.VM, vt script.VMType) {
 		"EncodeBase64":                 EncodeBase64,
 		"MD5":                          MD5,
 		"EditEcosysName":               EditEcosysName,
+		"EditEcosysTokenDecimals":      EditEcosysTokenDecimals,
 		"GetColumnType":                GetColumnType,
 		"GetType":                      GetType,
 		"AllowChangeCondition":         AllowChangeCondition,
 		"StringToBytes":                StringToBytes,
 		"BytesToString":                BytesToString,
 		"SetPubKey":                    SetPubKey,
+		"UpdateKeyPub":                 UpdateKeyPub,
 		"NewMoney":                     NewMoney,
 		"GetMapKeys":                   GetMapKeys,
 		"SortedKeys":                   SortedKeys,
@@ -262,6 +344,10 @@ func EmbedFuncs(vm *script.VM, vt script.VMType) {
 		"GetPageHistoryRow":            GetPageHistoryRow,
 		"GetBlockHistoryRow":           GetBlockHistoryRow,
 		"GetMenuHistoryRow":            GetMenuHistoryRow,
+		"GetPage":                      GetPage,
+		"GetMenu":                      GetMenu,
+		"GetBlockInterface":            GetBlockInterface,
+		"UpdateMenuItem":               UpdateMenuItem,
 		"GetContractHistoryRow":        GetContractHistoryRow,
 		"GetDataFromXLSX":              GetDataFromXLSX,
 		"GetRowsCountXLSX":             GetRowsCountXLSX,
@@ -275,6 +361,8 @@ func EmbedFuncs(vm *script.VM, vt script.VMType) {
 		f["HTTPPostJSON"] = HTTPPostJSON
 		f["ValidateCron"] = ValidateCron
 		f["UpdateCron"] = UpdateCron
+		f["CreateSyncSource"] = CreateSyncSource
+		f["PauseSyncSource"] = PauseSyncSource
 		vmExtendCost(vm, getCost)
 		vmFuncCallsDB(vm, funcCallsDB)
 	case script.VMTypeVDEMaster:
@@ -285,6 +373,8 @@ func EmbedFuncs(vm *script.VM, vt script.VMType) {
 		f["HTTPPostJSON"] = HTTPPostJSON
 		f["ValidateCron"] = ValidateCron
 		f["UpdateCron"] = UpdateCron
+		f["CreateSyncSource"] = CreateSyncSource
+		f["PauseSyncSource"] = PauseSyncSource
 		f["CreateVDE"] = CreateVDE
 		f["DeleteVDE"] = DeleteVDE
 		f["StartVDE"] = StartVDE
@@ -303,6 +393,7 @@ func EmbedFuncs(vm *script.VM, vt script.VMType) {
 
 	vmExtend(vm, &script.ExtendData{Objects: f, AutoPars: map[string]string{
 		`*smart.SmartContract`: `sc`,
+		`*script.RunTime`:      `rt`,
 	}})
 }
 
@@ -325,8 +416,9 @@ func accessContracts(sc *SmartContract, names ...string) bool {
 	} else {
 		prefix = fmt.Sprintf(`@%d`, sc.TxSmart.EcosystemID)
 	}
+	original := OriginalContract(sc)
 	for _, item := range names {
-		if sc.TxContract.Name == prefix+item {
+		if original == prefix+item {
 			return true
 		}
 	}
@@ -339,27 +431,40 @@ func CompileContract(sc *SmartContract, code string, state, id, token int64) (in
 		log.WithFields(log.Fields{"type": consts.IncorrectCallingContract}).Error("CompileContract can be only called from NewContract or EditContract")
 		return 0, fmt.Errorf(`CompileContract can be only called from NewContract or EditContract`)
 	}
-	return VMCompileBlock(sc.VM, code, &script.OwnerInfo{StateID: uint32(state), WalletID: id, TokenID: token})
+	root, err := VMCompileBlock(sc.VM, code, &script.OwnerInfo{StateID: uint32(state), WalletID: id, TokenID: token})
+	if errs, ok := err.(script.CompileErrors); ok {
+		return nil, errs.VMError(consts.TxErrorSize)
+	}
+	return root, err
+}
+
+// formatContract returns the source of the contract in the canonical format if it's enabled by
+// the system parameter. The source is returned without changes if it can't be formatted
+func formatContract(value string) string {
+	if !syspar.SysBool(syspar.FormatContracts) {
+		return value
+	}
+	out, err := script.FormatSource(value)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ParseError, "error": err}).Warning("formatting contract source")
+		return value
+	}
+	return out
 }
 
 // ContractAccess checks whether the name of the executable contract matches one of the names listed in the parameters.
+// The names can contain * and ? wildcards like @1*Page, @app:<id> matches the contracts of the application
+// and @role:<id> matches the contracts which owner has the role. The ecosystem of the name must be the same
 func ContractAccess(sc *SmartContract, names ...interface{}) bool {
+	contName := sc.stackContract(-1)
+	if len(contName) == 0 {
+		return false
+	}
 	for _, iname := range names {
 		switch name := iname.(type) {
 		case string:
-			if len(name) > 0 {
-				if name[0] != '@' {
-					name = fmt.Sprintf(`@%d`, sc.TxSmart.EcosystemID) + name
-				}
-				for i := len(sc.TxContract.StackCont) - 1; i >= 0; i-- {
-					contName := sc.TxContract.StackCont[i].(string)
-					if strings.HasPrefix(contName, `@`) {
-						if contName == name {
-							return true
-						}
-						break
-					}
-				}
+			if len(name) > 0 && sc.contractAccess(contName, name) {
+				return true
 			}
 		}
 	}
@@ -367,7 +472,7 @@ func ContractAccess(sc *SmartContract, names ...interface{}) bool {
 }
 
 // ContractConditions calls the 'conditions' function for each of the contracts specified in the parameters
-func ContractConditions(sc *SmartContract, names ...interface{}) (bool, error) {
+func ContractConditions(sc *SmartContract, rt *script.RunTime, names ...interface{}) (bool, error) {
 	for _, iname := range names {
 		name := iname.(string)
 		if len(name) > 0 {
@@ -384,12 +489,13 @@ func ContractConditions(sc *SmartContract, names ...interface{}) (bool, error) {
 				log.WithFields(log.Fields{"contract_name": name, "type": consts.EmptyObject}).Error("There is not conditions in contract")
 				return false, fmt.Errorf(`There is not conditions in contract %s`, name)
 			}
-			vars := map[string]interface{}{`ecosystem_id`: int64(sc.TxSmart.EcosystemID),
-				`key_id`: sc.TxSmart.KeyID, `sc`: sc, `original_contract`: ``, `this_contract`: ``, `role_id`: sc.TxSmart.RoleID}
-			if err := sc.AppendStack(name); err != nil {
+			if err := sc.AppendStack(contract.Name); err != nil {
 				return false, err
 			}
-			_, err := VMRun(sc.VM, block, []interface{}{}, &vars)
+			vars := map[string]interface{}{`ecosystem_id`: int64(sc.TxSmart.EcosystemID),
+				`key_id`: sc.TxSmart.KeyID, `sc`: sc, `original_contract`: ``, `this_contract`: ``, `role_id`: sc.TxSmart.RoleID,
+				`stack`: sc.contractStack()}
+			_, err := vmRun(sc.VM, block, []interface{}{}, &vars, rt)
 			if err != nil {
 				return false, err
 			}
@@ -451,8 +557,13 @@ func UpdateContract(sc *SmartContract, id int64, value, conditions, walletID str
 	ecosystemID := sc.TxSmart.EcosystemID
 	var root interface{}
 	if value != "" {
-		var err error
-		root, err = CompileContract(sc, value, ecosystemID, recipient, converter.StrToInt64(tokenID))
+		token, err := converter.StrToInt64E(tokenID)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting token id")
+			return err
+		}
+		value = formatContract(value)
+		root, err = CompileContract(sc, value, ecosystemID, recipient, token)
 		if err != nil {
 			return err
 		}
@@ -472,13 +583,18 @@ func UpdateContract(sc *SmartContract, id int64, value, conditions, walletID str
 			return err
 		}
 		if !sc.VDE {
-			if err := SysRollback(sc, `{"Type": "EditContract"}`); err != nil {
+			if err := SysRollback(sc, map[string]string{"Type": "EditContract"}); err != nil {
 				return err
 			}
 		}
 	}
 	if value != "" {
-		if err := FlushContract(sc, root, id, converter.StrToInt64(active) == 1); err != nil {
+		if !sc.VDE {
+			if err := scheduleUpgrade(sc, root.(*script.Block), id); err != nil {
+				return err
+			}
+		}
+		if err := FlushContract(sc, root, id, active == `1`); err != nil {
 			return err
 		}
 	} else {
@@ -502,6 +618,7 @@ func CreateContract(sc *SmartContract, name, value, conditions string, walletID,
 	if GetContractByName(sc, name) != 0 {
 		return 0, fmt.Errorf(eContractExist, name)
 	}
+	value = formatContract(value)
 	root, err := CompileContract(sc, value, sc.TxSmart.EcosystemID, walletID, tokenEcosystem)
 	if err != nil {
 		return 0, err
@@ -514,15 +631,10 @@ func CreateContract(sc *SmartContract, name, value, conditions string, walletID,
 		return 0, err
 	}
 	if !sc.VDE {
-		out, err := json.Marshal(map[string]string{
+		err = SysRollback(sc, map[string]string{
 			"Type":  "NewContract",
 			"Value": value,
 		})
-		if err != nil {
-			log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling contract to json")
-			return 0, err
-		}
-		err = SysRollback(sc, string(out))
 		if err != nil {
 			return 0, err
 		}
@@ -653,8 +765,9 @@ func CreateTable(sc *SmartContract, name, columns, permissions string, applicati
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("insert vde table info")
 		return err
 	}
+	sc.resetAccess(`tables`)
 	if !sc.VDE {
-		err = SysRollback(sc, `{"Type": "NewTable", "Name": "`+tableName+`"}`)
+		err = SysRollback(sc, map[string]string{"Type": "NewTable", "Name": tableName})
 		if err != nil {
 			return err
 		}
@@ -667,7 +780,7 @@ func columnType(colType string) (sqlColType string, err error) {
 	case "json":
 		sqlColType = `jsonb`
 	case "varchar":
-		sqlColType = `varchar(102400)`
+		sqlColType = `varchar(102400) NOT NULL DEFAULT ''`
 	case "character":
 		sqlColType = `character(1) NOT NULL DEFAULT '0'`
 	case "number":
@@ -679,7 +792,7 @@ func columnType(colType string) (sqlColType string, err error) {
 	case "money":
 		sqlColType = `decimal (30, 0) NOT NULL DEFAULT '0'`
 	case "text":
-		sqlColType = "text"
+		sqlColType = `text NOT NULL DEFAULT ''`
 	default:
 		err = fmt.Errorf("Type '%s' of columns is not supported", colType)
 	}
@@ -787,8 +900,9 @@ func checkNow(inputs ...string) error {
 }
 
 // DBSelect returns an array of values of the specified columns when there is selection of data 'offset', 'limit', 'where'
+// The soft-deleted rows are returned only if includeDeleted is true
 func DBSelect(sc *SmartContract, tblname string, columns string, id int64, order string, offset, limit, ecosystem int64,
-	where string, params []interface{}) (int64, []interface{}, error) {
+	includeDeleted bool, where string, params []interface{}) (int64, []interface{}, error) {
 
 	var (
 		err  error
@@ -830,6 +944,9 @@ func DBSelect(sc *SmartContract, tblname string, columns string, id int64, order
 		return 0, nil, err
 	}
 	columns = strings.Join(colsList, `,`)
+	if isSoftDelete(perm) && !includeDeleted {
+		where = deletedCondition(where)
+	}
 
 	columns = PrepareColumns(columns)
 	rows, err = model.GetDB(sc.DbTransaction).Table(tblname).Select(columns).Where(where, params...).Order(order).
@@ -839,31 +956,17 @@ func DBSelect(sc *SmartContract, tblname string, columns string, id int64, order
 		return 0, nil, err
 	}
 	defer rows.Close()
-	cols, err := rows.Columns()
+	list, err := model.ScanRows(rows, -1)
 	if err != nil {
-		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting rows columns")
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("scanning rows")
 		return 0, nil, err
 	}
-	values := make([][]byte, len(cols))
-	scanArgs := make([]interface{}, len(values))
-	for i := range values {
-		scanArgs[i] = &values[i]
-	}
 
-	result := make([]interface{}, 0, 50)
-	for rows.Next() {
-		err = rows.Scan(scanArgs...)
-		if err != nil {
-			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("scanning next row")
-			return 0, nil, err
-		}
-		row := make(map[string]interface{})
-		for i, col := range values {
-			var value string
-			if col != nil {
-				value = string(col)
-			}
-			row[cols[i]] = value
+	result := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		row := make(map[string]interface{}, len(item))
+		for key, value := range item {
+			row[key] = value
 		}
 		result = append(result, reflect.ValueOf(row).Interface())
 	}
@@ -907,9 +1010,11 @@ func DBUpdate(sc *SmartContract, tblname string, id int64, params string, val ..
 }
 
 // EcosysParam returns the value of the specified parameter for the ecosystem
+// The value is read in the transaction so the changes of the current block are visible
 func EcosysParam(sc *SmartContract, name string) string {
-	val, _ := model.Single(`SELECT value FROM "`+getDefTableName(sc, `parameters`)+`" WHERE name = ?`, name).String()
-	return val
+	val, _ := model.GetOneRowTransaction(sc.DbTransaction, `SELECT value FROM "`+getDefTableName(sc, `parameters`)+
+		`" WHERE name = ?`, name).String()
+	return val[`value`]
 }
 
 // AppParam returns the value of the specified app parameter for the ecosystem
@@ -991,7 +1096,10 @@ func PermTable(sc *SmartContract, name, permissions string) error {
 	}
 	_, _, err = sc.selectiveLoggingAndUpd([]string{`permissions`}, []interface{}{string(permout)},
 		getDefTableName(sc, `tables`), []string{`name`}, []string{strings.ToLower(name)}, !sc.VDE && sc.Rollback, false)
-	return err
+	if err != nil || !perm.SoftDelete {
+		return err
+	}
+	return partialUniqueIndexes(sc, getDefTableName(sc, name))
 }
 
 // TableConditions is contract func
@@ -1038,7 +1146,10 @@ func TableConditions(sc *SmartContract, name, columns, permissions string) (err
 	}
 	v := reflect.ValueOf(perm)
 	for i := 0; i < v.NumField(); i++ {
-		cond := v.Field(i).Interface().(string)
+		cond, ok := v.Field(i).Interface().(string)
+		if !ok {
+			continue
+		}
 		name := v.Type().Field(i).Name
 		if len(cond) == 0 && name != `Read` && name != `Filter` {
 			log.WithFields(log.Fields{"condition_type": name, "type": consts.EmptyObject}).Error("condition is empty")
@@ -1051,6 +1162,30 @@ func TableConditions(sc *SmartContract, name, columns, permissions string) (err
 	}
 
 	if isEdit {
+		if perm.SoftDelete {
+			itype, err := model.GetColumnType(prefix+`_`+name, deletedColumn)
+			if err != nil {
+				log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting deleted column type")
+				return err
+			}
+			if err = checkSoftDelete(itype); err != nil {
+				return err
+			}
+		}
+		if len(perm.Counters) > 0 {
+			var cols map[string]string
+			if err = json.Unmarshal([]byte(t.Columns), &cols); err != nil {
+				log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("getting table columns")
+				return err
+			}
+			colList := make(map[string]bool)
+			for colname := range cols {
+				colList[colname] = true
+			}
+			if err = checkCounters(sc, prefix, perm.Counters, colList); err != nil {
+				return err
+			}
+		}
 		if err = sc.AccessTable(name, `update`); err != nil {
 			if err = sc.AccessRights(`changing_tables`, false); err != nil {
 				return err
@@ -1073,6 +1208,8 @@ func TableConditions(sc *SmartContract, name, columns, permissions string) (err
 		log.WithFields(log.Fields{"size": len(cols), "max_size": syspar.GetMaxColumns(), "type": consts.ParameterExceeded}).Error("Too many columns")
 		return fmt.Errorf(`Too many columns. Limit is %d`, syspar.GetMaxColumns())
 	}
+	colList := make(map[string]bool)
+	var deletedType string
 	for _, icol := range cols {
 		var data map[string]interface{}
 		switch v := icol.(type) {
@@ -1091,6 +1228,10 @@ func TableConditions(sc *SmartContract, name, columns, permissions string) (err
 			return fmt.Errorf(`worng column`)
 		}
 		itype := data[`type`].(string)
+		colList[strings.ToLower(fmt.Sprint(data[`name`]))] = true
+		if strings.ToLower(fmt.Sprint(data[`name`])) == deletedColumn {
+			deletedType = itype
+		}
 		if itype != `varchar` && itype != `number` && itype != `datetime` && itype != `text` &&
 			itype != `bytea` && itype != `double` && itype != `json` && itype != `money` &&
 			itype != `character` {
@@ -1118,6 +1259,12 @@ func TableConditions(sc *SmartContract, name, columns, permissions string) (err
 			log.WithFields(log.Fields{"type": consts.EmptyObject}).Error("Update condition is empty")
 			return errConditionEmpty
 		}
+		if err = checkColumnBounds(itype, perm); err != nil {
+			return err
+		}
+		if err = checkColumnRef(itype, perm); err != nil {
+			return err
+		}
 		if err = VMCompileEval(sc.VM, perm.Update, uint32(sc.TxSmart.EcosystemID)); err != nil {
 			log.WithFields(log.Fields{"type": consts.EvalError}).Error("compile update conditions")
 			return err
@@ -1130,6 +1277,14 @@ func TableConditions(sc *SmartContract, name, columns, permissions string) (err
 		}
 
 	}
+	if err = checkCounters(sc, prefix, perm.Counters, colList); err != nil {
+		return err
+	}
+	if perm.SoftDelete {
+		if err = checkSoftDelete(deletedType); err != nil {
+			return err
+		}
+	}
 	if err := sc.AccessRights("new_table", false); err != nil {
 		return err
 	}
@@ -1143,6 +1298,9 @@ func ValidateCondition(sc *SmartContract, condition string, state int64) error {
 		log.WithFields(log.Fields{"type": consts.EmptyObject}).Error("conditions cannot be empty")
 		return fmt.Errorf("Conditions cannot be empty")
 	}
+	if err := checkContractAccess(condition); err != nil {
+		return err
+	}
 	return VMCompileEval(sc.VM, condition, uint32(state))
 }
 
@@ -1191,6 +1349,16 @@ func ColumnCondition(sc *SmartContract, tableName, name, coltype, permissions st
 	}
 	tblName := getDefTableName(sc, tableName)
 	if isExist {
+		if coltype, err = model.GetColumnType(tblName, name); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": tblName, "column": name}).Error("getting column type")
+			return err
+		}
+		if err = checkColumnBounds(coltype, perm); err != nil {
+			return err
+		}
+		if err = checkColumnRef(coltype, perm); err != nil {
+			return err
+		}
 		return sc.AccessTable(tblName, `update`)
 	}
 	count, err := model.GetColumnCount(tblName)
@@ -1208,6 +1376,12 @@ func ColumnCondition(sc *SmartContract, tableName, name, coltype, permissions st
 		log.WithFields(log.Fields{"column_type": coltype, "type": consts.InvalidObject}).Error("Unknown column type")
 		return fmt.Errorf(`incorrect type`)
 	}
+	if err = checkColumnBounds(coltype, perm); err != nil {
+		return err
+	}
+	if err = checkColumnRef(coltype, perm); err != nil {
+		return err
+	}
 	return sc.AccessTable(tblName, "new_column")
 }
 
@@ -1319,7 +1493,7 @@ func CreateColumn(sc *SmartContract, tableName, name, colType, permissions strin
 		return
 	}
 	if !sc.VDE {
-		return SysRollback(sc, `{"Type":"NewColumn","TableName": "`+tblname+`", "Name": "`+name+`" }`)
+		return SysRollback(sc, map[string]string{"Type": "NewColumn", "TableName": tblname, "Name": name})
 	}
 	return nil
 }
@@ -1330,17 +1504,104 @@ func SetPubKey(sc *SmartContract, id int64, pubKey []byte) (qcost int64, err err
 		log.WithFields(log.Fields{"type": consts.IncorrectCallingContract}).Error("SetPubKey can be only called from NewUser")
 		return 0, fmt.Errorf(`SetPubKey can be only called from NewUser contract`)
 	}
+	if pubKey, err = decodePubKey(pubKey); err != nil {
+		return
+	}
+	qcost, _, err = sc.selectiveLoggingAndUpd([]string{`pub`}, []interface{}{pubKey},
+		getDefTableName(sc, `keys`), []string{`id`}, []string{converter.Int64ToStr(id)},
+		!sc.VDE && sc.Rollback, true)
+	return qcost, err
+}
+
+// decodePubKey converts the hex public key of the contract to the stored public key
+func decodePubKey(pubKey []byte) ([]byte, error) {
 	if len(pubKey) == consts.PubkeySizeLength*2 {
-		pubKey, err = hex.DecodeString(string(pubKey))
+		key, err := hex.DecodeString(string(pubKey))
 		if err != nil {
 			log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding public key from hex")
-			return
+			return nil, err
 		}
+		return key, nil
+	}
+	if key, err := hex.DecodeString(string(pubKey)); err == nil && len(key) > 0 {
+		// the keys of the schemes except ECDSA have the version of the scheme
+		if _, err = crypto.KeyScheme(key); err == nil {
+			return key, nil
+		}
+	}
+	return pubKey, nil
+}
+
+// UpdateKeyPub replaces the public key of the key which has signed the transaction.
+// The replaced public key is saved in keys_history
+func UpdateKeyPub(sc *SmartContract, newPub string) (qcost int64, err error) {
+	if !accessContracts(sc, `ChangeKey`) {
+		log.WithFields(log.Fields{"type": consts.IncorrectCallingContract}).Error("UpdateKeyPub can be only called from ChangeKey")
+		return 0, fmt.Errorf(`UpdateKeyPub can be only called from ChangeKey contract`)
+	}
+	if sc.TxSmart.SignedBy != 0 {
+		log.WithFields(log.Fields{"type": consts.InvalidObject, "signed_by": sc.TxSmart.SignedBy}).Error("key is changed not by its owner")
+		return 0, fmt.Errorf(`the key can be changed only by its owner`)
+	}
+	pubKey, err := decodePubKey([]byte(newPub))
+	if err != nil {
+		return 0, err
+	}
+	if err = crypto.CheckPublicKey(pubKey); err != nil {
+		log.WithFields(log.Fields{"type": consts.InvalidObject, "error": err}).Error("checking new public key")
+		return 0, err
+	}
+
+	keyID := sc.TxSmart.KeyID
+	key := &model.Key{}
+	key.SetTablePrefix(sc.TxSmart.EcosystemID)
+	found, err := key.GetTransaction(sc.DbTransaction, keyID)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting key")
+		return 0, err
+	}
+	if !found {
+		log.WithFields(log.Fields{"type": consts.NotFound, "key_id": keyID}).Error("key is not found")
+		return 0, fmt.Errorf(`key %d is not found`, keyID)
+	}
+	oldKey := key.PublicKey
+	if len(oldKey) == 0 {
+		// the key isn't stored yet, so the transaction is signed by the key of the address
+		oldKey = sc.TxSmart.PublicKey
+	}
+	if bytes.Equal(oldKey, pubKey) {
+		return 0, fmt.Errorf(`the new public key is the same as the current one`)
+	}
+
+	// the key must not be bound to another key id, otherwise the key id of the key is ambiguous
+	used := &model.Key{}
+	used.SetTablePrefix(sc.TxSmart.EcosystemID)
+	if found, err = used.GetTransaction(sc.DbTransaction, crypto.Address(pubKey)); err == nil && (!found || used.ID == keyID) {
+		found, err = used.GetByPublicKey(sc.DbTransaction, pubKey)
+	}
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting key by public key")
+		return 0, err
+	}
+	if found {
+		log.WithFields(log.Fields{"type": consts.InvalidObject, "key_id": used.ID}).Error("public key is already used")
+		return 0, fmt.Errorf(`the public key is already used by key %d`, used.ID)
+	}
+
+	var block int64
+	if sc.BlockData != nil {
+		block = sc.BlockData.BlockID
+	}
+	cost, _, err := sc.selectiveLoggingAndUpd([]string{`key_id`, `pub`, `block_id`, `txhash`},
+		[]interface{}{keyID, oldKey, block, sc.TxHash},
+		getDefTableName(sc, `keys_history`), nil, nil, !sc.VDE && sc.Rollback, false)
+	if err != nil {
+		return 0, err
 	}
 	qcost, _, err = sc.selectiveLoggingAndUpd([]string{`pub`}, []interface{}{pubKey},
-		getDefTableName(sc, `keys`), []string{`id`}, []string{converter.Int64ToStr(id)},
+		getDefTableName(sc, `keys`), []string{`id`}, []string{converter.Int64ToStr(keyID)},
 		!sc.VDE && sc.Rollback, true)
-	return qcost, err
+	return cost + qcost, err
 }
 
 func NewMoney(sc *SmartContract, id int64, amount, comment string) (err error) {
@@ -1348,19 +1609,13 @@ func NewMoney(sc *SmartContract, id int64, amount, comment string) (err error) {
 		log.WithFields(log.Fields{"type": consts.IncorrectCallingContract}).Error("NewMoney can be only called from NewUser")
 		return fmt.Errorf(`NewMoney can be only called from NewUser contract`)
 	}
-	_, _, err = sc.selectiveLoggingAndUpd([]string{`id`, `amount`}, []interface{}{id, amount},
-		getDefTableName(sc, `keys`), nil, nil, !sc.VDE && sc.Rollback, false)
-	if err == nil {
-		var block int64
-		if sc.BlockData != nil {
-			block = sc.BlockData.BlockID
-		}
-		_, _, err = sc.selectiveLoggingAndUpd([]string{`sender_id`, `recipient_id`, `amount`,
-			`comment`, `block_id`, `txhash`},
-			[]interface{}{0, id, amount, comment, block, sc.TxHash},
-			getDefTableName(sc, `history`), nil, nil, !sc.VDE && sc.Rollback, false)
+	value, err := decimal.NewFromString(amount)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": amount}).Error("converting amount to decimal")
+		return err
 	}
-	return err
+	return sc.moveTokens(ledgerEntry{ecosystem: sc.TxSmart.EcosystemID, recipient: id, amount: value,
+		kind: model.HistoryEmission, comment: comment}, !sc.VDE && sc.Rollback)
 }
 
 // PermColumn is contract func
@@ -1406,7 +1661,8 @@ func AddressToID(input string) (addr int64) {
 	}
 	if input[0] == '-' {
 		addr, _ = strconv.ParseInt(input, 10, 64)
-	} else if strings.Count(input, `-`) == 4 {
+	} else if dashes := strings.Count(input, `-`); dashes == 4 || dashes == 5 {
+		// XXXX-...-XXXX or the checksummed XXXX-...-XXXX-C address
 		addr = converter.StringToAddress(input)
 	} else {
 		uaddr, _ := strconv.ParseUint(input, 10, 64)
@@ -1440,13 +1696,9 @@ func HMac(key, data string, raw_output bool) (ret string, err error) {
 	}
 }
 
-//Returns the array of keys of the map
+//Returns the array of keys of the map. The keys are sorted so the result is the same on all nodes
 func GetMapKeys(in map[string]interface{}) []interface{} {
-	keys := make([]interface{}, 0, len(in))
-	for k := range in {
-		keys = append(keys, k)
-	}
-	return keys
+	return SortedKeys(in)
 }
 
 //Returns the sorted array of keys of the map
@@ -1859,13 +2111,17 @@ func GetHistory(transaction *model.DbTransaction, ecosystem int64, tableName str
 		if tx.Data == "" {
 			continue
 		}
+		values, err := model.UnmarshalRollbackData(tx.Data)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling rollbackTx.Data from JSON")
+			return nil, err
+		}
 		rollback := make(map[string]string)
 		for k, v := range curVal {
 			rollback[k] = v
 		}
-		if err := json.Unmarshal([]byte(tx.Data), &rollback); err != nil {
-			log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling rollbackTx.Data from JSON")
-			return nil, err
+		for k, v := range values {
+			rollback[k] = v
 		}
 		rollbackList = append(rollbackList, rollback)
 		curVal = rollback
@@ -0,0 +1,161 @@
+package smart
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ImportContract is the new contract of the imported application
+type ImportContract struct {
+	Name          string
+	Value         string
+	Conditions    string
+	WalletID      int64
+	TokenID       int64
+	ApplicationID int64
+}
+
+// CreateContracts creates the new contracts of the imported application. The contracts are compiled
+// together, so they can call each other, and are loaded into the virtual machine by one flush.
+// No contract is created if one of them isn't valid
+func CreateContracts(sc *SmartContract, list []interface{}) error {
+	if !accessContracts(sc, `Import`) {
+		log.WithFields(log.Fields{"type": consts.IncorrectCallingContract}).Error("CreateContracts can be only called from Import")
+		return fmt.Errorf(`CreateContracts can be only called from Import`)
+	}
+	contracts, err := importContracts(sc, list)
+	if err != nil {
+		return err
+	}
+	roots, err := CompileContracts(sc.VM, uint32(sc.TxSmart.EcosystemID), contracts)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(contracts))
+	for i, c := range contracts {
+		_, id, err := DBInsert(sc, "contracts", "name,value,conditions,wallet_id,token_id,app_id",
+			c.Name, c.Value, c.Conditions, c.WalletID, c.TokenID, c.ApplicationID)
+		if err != nil {
+			return fmt.Errorf("contract %s: %s", c.Name, err)
+		}
+		info := roots[i].Children[0].Info.(*script.ContractInfo)
+		info.Owner.TableID = id
+		info.Owner.Active = false
+		names[i] = info.Name
+	}
+	from := len(sc.VM.Children)
+	VMFlushBlock(sc.VM, script.JoinBlocks(roots))
+	if !sc.VDE {
+		if err := SysRollback(sc, map[string]string{
+			"Type":  "NewContracts",
+			"From":  strconv.Itoa(from),
+			"Names": strings.Join(names, ","),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importContracts checks the contracts of the import like NewContract does
+func importContracts(sc *SmartContract, list []interface{}) ([]*ImportContract, error) {
+	contracts := make([]*ImportContract, 0, len(list))
+	names := make(map[string]bool)
+	for i, item := range list {
+		data, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("contract %d: data isn't a map", i+1)
+		}
+		c := &ImportContract{
+			Value:      Str(data["Value"]),
+			Conditions: Str(data["Conditions"]),
+			WalletID:   sc.TxSmart.KeyID,
+		}
+		name, err := contractName(c.Value)
+		if err != nil {
+			return nil, fmt.Errorf("contract %d: %s", i+1, err)
+		}
+		if len(name) == 0 {
+			return nil, fmt.Errorf("contract %d: must be the name", i+1)
+		}
+		c.Name = name
+		if names[name] || GetContractByName(sc, name) != 0 {
+			return nil, fmt.Errorf(eContractExist, name)
+		}
+		names[name] = true
+
+		if err = ValidateCondition(sc, c.Conditions, sc.TxSmart.EcosystemID); err != nil {
+			return nil, fmt.Errorf("contract %s: %s", name, err)
+		}
+		if c.ApplicationID, err = Int(data["ApplicationId"]); err != nil {
+			return nil, fmt.Errorf("contract %s: %s", name, err)
+		}
+		if c.ApplicationID == 0 {
+			return nil, fmt.Errorf("contract %s: application id cannot equal 0", name)
+		}
+		if wallet := Str(data["Wallet"]); len(wallet) > 0 {
+			if c.WalletID = AddressToID(wallet); c.WalletID == 0 {
+				return nil, fmt.Errorf("contract %s: wrong wallet %s", name, wallet)
+			}
+		}
+		if c.TokenID, err = Int(data["TokenEcosystem"]); err != nil {
+			return nil, fmt.Errorf("contract %s: %s", name, err)
+		}
+		if c.TokenID == 0 {
+			c.TokenID = 1
+		} else if !HasFuelRate(c.TokenID) {
+			return nil, fmt.Errorf("contract %s: ecosystem %d is not system", name, c.TokenID)
+		}
+		contracts = append(contracts, c)
+	}
+	return contracts, nil
+}
+
+// CompileContracts compiles the contracts without loading them into vm. The contracts can call each other
+// in any order, the calls of the unknown contracts and functions are errors. The error names the contract
+func CompileContracts(vm *script.VM, state uint32, contracts []*ImportContract) ([]*script.Block, error) {
+	declared := make(map[string]bool)
+	calls := make([][]script.Call, len(contracts))
+	for i, c := range contracts {
+		list, names, err := script.SourceCalls([]rune(c.Value))
+		if err != nil {
+			return nil, fmt.Errorf("contract %s: %s", c.Name, err)
+		}
+		calls[i] = list
+		for _, name := range names {
+			declared[name] = true
+		}
+	}
+
+	// the calls of the contracts of the import are compiled before the contracts are loaded
+	extern := vm.Extern
+	vm.Extern = true
+	defer func() {
+		vm.Extern = extern
+	}()
+	roots := make([]*script.Block, len(contracts))
+	for i, c := range contracts {
+		root, err := VMCompileBlock(vm, c.Value,
+			&script.OwnerInfo{StateID: state, WalletID: c.WalletID, TokenID: c.TokenID})
+		if err != nil {
+			return nil, fmt.Errorf("contract %s: %s", c.Name, err)
+		}
+		if len(root.Children) != 1 || root.Children[0].Type != script.ObjContract {
+			return nil, fmt.Errorf("contract %s: only one contract must be in the record", c.Name)
+		}
+		for _, call := range calls[i] {
+			if !declared[call.Name] && !vm.IsObject(call.Name, state) {
+				return nil, fmt.Errorf("contract %s: unknown contract or function %s at line %d", c.Name, call.Name, call.Line)
+			}
+		}
+		roots[i] = root
+	}
+	return roots, nil
+}
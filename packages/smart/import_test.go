@@ -0,0 +1,124 @@
+package smart
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/script"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileContracts(t *testing.T) {
+	vm, err := NewCheckVM(script.VMTypeSmart)
+	require.NoError(t, err)
+	// smartVM compiles in the extern mode only while the contracts are loaded
+	vm.FlushExtern()
+
+	contracts := []*ImportContract{
+		{Name: "Alpha", Value: `contract Alpha {
+	action {
+		Beta()
+	}
+}`},
+		{Name: "Beta", Value: `contract Beta {
+	action {
+		DBFind("keys").Columns("id")
+	}
+}`},
+	}
+	roots, err := CompileContracts(vm, 1, contracts)
+	require.NoError(t, err)
+	from := len(vm.Children)
+	VMFlushBlock(vm, script.JoinBlocks(roots))
+	for i, name := range []string{"Alpha", "Beta"} {
+		contract := VMGetContractByID(vm, int32(from+i))
+		require.NotNil(t, contract)
+		assert.Equal(t, "@1"+name, contract.Name)
+		assert.NotNil(t, VMGetContract(vm, name, 1))
+	}
+
+	_, err = CompileContracts(vm, 1, []*ImportContract{{Name: "Gamma", Value: `contract Gamma {
+	action {
+		Delta()
+	}
+}`}})
+	assert.EqualError(t, err, "contract Gamma: unknown contract or function Delta at line 3")
+
+	_, err = CompileContracts(vm, 1, []*ImportContract{{Name: "Echo", Value: `contract Echo {
+	action {
+		value = 1
+	}
+}`}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "contract Echo: ")
+
+	_, err = CompileContracts(vm, 1, []*ImportContract{{Name: "Foxtrot", Value: `contract Foxtrot {}
+contract Golf {}`}})
+	assert.EqualError(t, err, "contract Foxtrot: only one contract must be in the record")
+	assert.False(t, vm.Extern)
+}
+
+// importSources returns the contracts which call the previous contract
+func importSources(count int) []*ImportContract {
+	contracts := make([]*ImportContract, count)
+	for i := range contracts {
+		call := ""
+		if i > 0 {
+			call = fmt.Sprintf("Bench%d()", i-1)
+		}
+		contracts[i] = &ImportContract{Name: fmt.Sprintf("Bench%d", i), Value: fmt.Sprintf(`contract Bench%d {
+	data {
+		Name string
+	}
+	conditions {
+		if Size($Name) == 0 {
+			warning "empty name"
+		}
+	}
+	action {
+		var m map
+		m["name"] = $Name
+		%s
+	}
+}`, i, call)}
+	}
+	return contracts
+}
+
+func BenchmarkImportContracts(b *testing.B) {
+	contracts := importSources(300)
+	b.Run("PerContract", func(b *testing.B) {
+		b.StopTimer()
+		for n := 0; n < b.N; n++ {
+			vm, err := NewCheckVM(script.VMTypeSmart)
+			require.NoError(b, err)
+			vm.FlushExtern()
+			b.StartTimer()
+			for _, c := range contracts {
+				root, err := VMCompileBlock(vm, c.Value, &script.OwnerInfo{StateID: 1})
+				if err != nil {
+					b.Fatal(err)
+				}
+				VMFlushBlock(vm, root)
+			}
+			b.StopTimer()
+		}
+	})
+	b.Run("Batch", func(b *testing.B) {
+		b.StopTimer()
+		for n := 0; n < b.N; n++ {
+			vm, err := NewCheckVM(script.VMTypeSmart)
+			require.NoError(b, err)
+			vm.FlushExtern()
+			b.StartTimer()
+			roots, err := CompileContracts(vm, 1, contracts)
+			if err != nil {
+				b.Fatal(err)
+			}
+			VMFlushBlock(vm, script.JoinBlocks(roots))
+			b.StopTimer()
+		}
+	})
+}
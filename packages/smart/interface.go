@@ -0,0 +1,116 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	pagesTable  = `pages`
+	menuTable   = `menu`
+	blocksTable = `blocks`
+
+	eInterfaceNotFound = `%s %s has not been found`
+)
+
+var errMenuItemTitle = errors.New(`Title of menu item is empty`)
+
+// interfaceColumns are the columns of the interface objects which are returned to the contracts
+var interfaceColumns = map[string]string{
+	pagesTable:  `id, name, value, menu, conditions, app_id`,
+	menuTable:   `id, name, title, value, conditions`,
+	blocksTable: `id, name, value, conditions, app_id`,
+}
+
+// getInterface returns the page, the menu or the block of the ecosystem by the name
+func getInterface(sc *SmartContract, table, kind, name string) (map[string]interface{}, error) {
+	row, err := model.GetOneRowTransaction(sc.DbTransaction, fmt.Sprintf(`SELECT %s FROM "%s" WHERE name = ?`,
+		interfaceColumns[table], getDefTableName(sc, table)), name).String()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table, "name": name}).Error("getting interface object")
+		return nil, err
+	}
+	if len(row) == 0 {
+		return nil, fmt.Errorf(eInterfaceNotFound, kind, name)
+	}
+	ret := make(map[string]interface{}, len(row))
+	for key, val := range row {
+		ret[key] = val
+	}
+	return ret, nil
+}
+
+// GetPage returns the id, the value, the menu, the conditions and the app_id of the page
+func GetPage(sc *SmartContract, name string) (map[string]interface{}, error) {
+	return getInterface(sc, pagesTable, `Page`, name)
+}
+
+// GetMenu returns the id, the title, the value and the conditions of the menu
+func GetMenu(sc *SmartContract, name string) (map[string]interface{}, error) {
+	return getInterface(sc, menuTable, `Menu`, name)
+}
+
+// GetBlockInterface returns the id, the value, the conditions and the app_id of the block
+func GetBlockInterface(sc *SmartContract, name string) (map[string]interface{}, error) {
+	return getInterface(sc, blocksTable, `Block`, name)
+}
+
+// UpdateMenuItem adds MenuItem of the page to the menu or changes the title and the icon
+// of the existing item of the page, the items of the groups are changed too. The menu isn't changed
+// if it already has the same item, so the function can be called repeatedly by the installers.
+// The conditions of the menu must be true
+func UpdateMenuItem(sc *SmartContract, menu, title, page, icon string) error {
+	if len(title) == 0 {
+		return errMenuItemTitle
+	}
+	if len(page) == 0 || !converter.IsLatin(page) {
+		return fmt.Errorf(eLatin, page)
+	}
+	row, err := GetMenu(sc, menu)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.ParseInt(row[`id`].(string), 10, 64)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": row[`id`]}).Error("converting menu id")
+		return err
+	}
+	if err = RowConditions(sc, menuTable, id, false); err != nil {
+		return err
+	}
+	value, changed, err := setMenuItem(row[`value`].(string), title, page, icon)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ParseError, "error": err, "menu": menu}).Error("parsing menu")
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	_, _, err = sc.selectiveLoggingAndUpd([]string{`value`}, []interface{}{value},
+		getDefTableName(sc, menuTable), []string{`id`}, []string{strconv.FormatInt(id, 10)},
+		!sc.VDE && sc.Rollback, false)
+	return err
+}
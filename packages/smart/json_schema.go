@@ -0,0 +1,268 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+// jsonByteCost is the cost of the byte of the input of JSONDecodeSchema
+const jsonByteCost = 1
+
+// jsonSchemaTypes are the types of the values of the schema
+var jsonSchemaTypes = map[string]bool{
+	`string`: true,
+	`int`:    true,
+	`float`:  true,
+	`money`:  true,
+	`bool`:   true,
+	`array`:  true,
+	`map`:    true,
+	`any`:    true,
+}
+
+// JSONDecodeSchema decodes the JSON input and checks it by the schema. The schema is the JSON object
+// which describes the keys of the input, for example {"name":"string","?amount":"money","tags":["string"]}.
+// The value of the key is the type, the nested object or the array of one element which is the schema
+// of the items. The keys with the '?' prefix are optional. The keys which are not described are
+// errors if the strict flag is true. The values are converted to int64, float64, decimal.Decimal,
+// string, bool, []interface{} and map[string]interface{}
+func JSONDecodeSchema(rt *script.RunTime, input, schema string, strict ...interface{}) (interface{}, error) {
+	if rt != nil {
+		rt.SetCost(rt.Cost() - int64(len(input)+len(schema))*jsonByteCost)
+		if rt.Cost() < 0 {
+			log.WithFields(log.Fields{"type": consts.VMError}).Error("paid CPU resource is over")
+			return nil, fmt.Errorf(`paid CPU resource is over`)
+		}
+	}
+	var isStrict bool
+	if len(strict) > 0 {
+		var ok bool
+		if isStrict, ok = strict[0].(bool); !ok {
+			return nil, fmt.Errorf(`Strict flag must be bool`)
+		}
+	}
+	var rules interface{}
+	if err := json.Unmarshal([]byte(schema), &rules); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling json schema")
+		return nil, fmt.Errorf(`Invalid schema: %s`, err)
+	}
+	if err := checkJSONSchema(rules, `$`); err != nil {
+		return nil, err
+	}
+	value, err := decodeJSONNumbers(input)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling json")
+		return nil, err
+	}
+	return applyJSONSchema(value, rules, `$`, isStrict)
+}
+
+// decodeJSONNumbers decodes the input, the numbers are decoded as json.Number
+func decodeJSONNumbers(input string) (interface{}, error) {
+	var value interface{}
+	dec := json.NewDecoder(strings.NewReader(input))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf(`invalid character after top-level value`)
+	}
+	return value, nil
+}
+
+// checkJSONSchema checks the rules of the schema before the input is checked
+func checkJSONSchema(rules interface{}, path string) error {
+	switch v := rules.(type) {
+	case string:
+		if !jsonSchemaTypes[v] {
+			return fmt.Errorf(`Invalid schema: unknown type %s of %s`, v, path)
+		}
+	case []interface{}:
+		if len(v) != 1 {
+			return fmt.Errorf(`Invalid schema: array of %s must have one item`, path)
+		}
+		return checkJSONSchema(v[0], path+`[]`)
+	case map[string]interface{}:
+		for key, item := range v {
+			name := strings.TrimPrefix(key, `?`)
+			if len(name) == 0 {
+				return fmt.Errorf(`Invalid schema: empty key of %s`, path)
+			}
+			if err := checkJSONSchema(item, path+`.`+name); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf(`Invalid schema: rule of %s must be type, array or object`, path)
+	}
+	return nil
+}
+
+// applyJSONSchema checks the value by the rules and converts it to the types of VM
+func applyJSONSchema(value, rules interface{}, path string, strict bool) (interface{}, error) {
+	switch v := rules.(type) {
+	case string:
+		return convertJSONValue(value, v, path)
+	case []interface{}:
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, jsonTypeError(path, `array`)
+		}
+		ret := make([]interface{}, len(list))
+		for i, item := range list {
+			var err error
+			if ret[i], err = applyJSONSchema(item, v[0], path+`[`+strconv.Itoa(i)+`]`, strict); err != nil {
+				return nil, err
+			}
+		}
+		return ret, nil
+	case map[string]interface{}:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, jsonTypeError(path, `map`)
+		}
+		ret := make(map[string]interface{}, len(obj))
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		known := make(map[string]bool, len(v))
+		for _, key := range keys {
+			name := strings.TrimPrefix(key, `?`)
+			known[name] = true
+			item, ok := obj[name]
+			if !ok {
+				if name == key {
+					return nil, fmt.Errorf(`JSON key %s is required`, path+`.`+name)
+				}
+				continue
+			}
+			var err error
+			if ret[name], err = applyJSONSchema(item, v[key], path+`.`+name, strict); err != nil {
+				return nil, err
+			}
+		}
+		// the keys which are not described are kept as is if the mode isn't strict
+		extra := make([]string, 0)
+		for key := range obj {
+			if !known[key] {
+				extra = append(extra, key)
+			}
+		}
+		sort.Strings(extra)
+		for _, key := range extra {
+			if strict {
+				return nil, fmt.Errorf(`JSON key %s is not expected`, path+`.`+key)
+			}
+			ret[key] = convertJSONAny(obj[key])
+		}
+		return ret, nil
+	}
+	return nil, fmt.Errorf(`Invalid schema of %s`, path)
+}
+
+func jsonTypeError(path, colType string) error {
+	return fmt.Errorf(`JSON value %s must be %s`, path, colType)
+}
+
+// convertJSONValue converts the decoded value to the type
+func convertJSONValue(value interface{}, colType, path string) (interface{}, error) {
+	switch colType {
+	case `string`:
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+	case `int`:
+		if v, ok := value.(json.Number); ok {
+			if ret, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+				return ret, nil
+			}
+		}
+	case `float`:
+		if v, ok := value.(json.Number); ok {
+			if ret, err := v.Float64(); err == nil {
+				return ret, nil
+			}
+		}
+	case `money`:
+		var num string
+		switch v := value.(type) {
+		case json.Number:
+			num = string(v)
+		case string:
+			num = strings.TrimSpace(v)
+		default:
+			return nil, jsonTypeError(path, colType)
+		}
+		if ret, err := decimal.NewFromString(num); err == nil && ret.Equal(ret.Truncate(0)) {
+			return ret.Truncate(0), nil
+		}
+	case `bool`:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	case `array`:
+		if _, ok := value.([]interface{}); ok {
+			return convertJSONAny(value), nil
+		}
+	case `map`:
+		if _, ok := value.(map[string]interface{}); ok {
+			return convertJSONAny(value), nil
+		}
+	case `any`:
+		return convertJSONAny(value), nil
+	}
+	return nil, jsonTypeError(path, colType)
+}
+
+// convertJSONAny converts the numbers of the value which has no schema to int64 or decimal.Decimal
+func convertJSONAny(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if ret, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return ret
+		}
+		if ret, err := decimal.NewFromString(string(v)); err == nil {
+			return ret
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i, item := range v {
+			v[i] = convertJSONAny(item)
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = convertJSONAny(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,64 @@
+package smart
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONDecodeSchema(t *testing.T) {
+	schema := `{"name":"string","amount":"money","?count":"int","?rate":"float","flag":"bool",
+		"tags":["string"],"?items":[{"id":"int","?data":"any"}],"?info":"map","?list":"array"}`
+	value, err := JSONDecodeSchema(nil, `{"name":"test","amount":"1000000000000000000000","count":12,
+		"rate":1.5,"flag":true,"tags":["a","b"],"items":[{"id":1,"data":{"n":2,"f":0.5}}],"list":[1,"x"],
+		"extra":7}`, schema)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name":   "test",
+		"amount": decimal.RequireFromString("1000000000000000000000"),
+		"count":  int64(12),
+		"rate":   1.5,
+		"flag":   true,
+		"tags":   []interface{}{"a", "b"},
+		"items": []interface{}{map[string]interface{}{"id": int64(1),
+			"data": map[string]interface{}{"n": int64(2), "f": decimal.RequireFromString("0.5")}}},
+		"list":  []interface{}{int64(1), "x"},
+		"extra": int64(7),
+	}, value)
+
+	value, err = JSONDecodeSchema(nil, `{"name":"","amount":5,"flag":false,"tags":[]}`, schema, true)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "", "amount": decimal.New(5, 0), "flag": false,
+		"tags": []interface{}{}}, value)
+
+	base := `"name":"a","amount":"1","flag":true,"tags":[]`
+	for input, msg := range map[string]string{
+		`{"amount":"1","flag":true,"tags":[]}`:              `JSON key $.name is required`,
+		`{` + base + `,"count":"12"}`:                       `JSON value $.count must be int`,
+		`{` + base + `,"count":1.5}`:                        `JSON value $.count must be int`,
+		`{"name":"a","amount":"1.5","flag":true,"tags":[]}`: `JSON value $.amount must be money`,
+		`{"name":"a","amount":"1","flag":true,"tags":[1]}`:  `JSON value $.tags[0] must be string`,
+		`{` + base + `,"items":[{"id":1},{"data":1}]}`:      `JSON key $.items[1].id is required`,
+		`{` + base + `,"info":[]}`:                          `JSON value $.info must be map`,
+		`{` + base + `,"extra":1}`:                          `JSON key $.extra is not expected`,
+		`[]`:                                                `JSON value $ must be map`,
+		`{` + base + `} {}`:                                 `invalid character after top-level value`,
+	} {
+		_, err = JSONDecodeSchema(nil, input, schema, true)
+		assert.EqualError(t, err, msg, input)
+	}
+
+	for wrong, msg := range map[string]string{
+		`{"a":"text"}`:      `Invalid schema: unknown type text of $.a`,
+		`{"a":["int",1]}`:   `Invalid schema: array of $.a must have one item`,
+		`{"a":{"?":"int"}}`: `Invalid schema: empty key of $.a`,
+		`{"a":1}`:           `Invalid schema: rule of $.a must be type, array or object`,
+	} {
+		_, err = JSONDecodeSchema(nil, `{}`, wrong)
+		assert.EqualError(t, err, msg, wrong)
+	}
+	_, err = JSONDecodeSchema(nil, `{}`, `{}`, "true")
+	assert.EqualError(t, err, `Strict flag must be bool`)
+}
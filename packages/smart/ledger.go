@@ -0,0 +1,115 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	errTransferAmount    = errors.New(`Amount of tokens must be greater than zero`)
+	errTransferRecipient = errors.New(`Recipient of tokens is invalid`)
+	errTransferBalance   = errors.New(`Balance is not enough to transfer the tokens`)
+	errTransferTokens    = errors.New(`TransferTokens can be only called from MoneyTransfer`)
+)
+
+// ledgerEntry is the movement of the tokens of the ecosystem
+type ledgerEntry struct {
+	ecosystem int64
+	// sender is 0 if the tokens are issued
+	sender int64
+	// recipient is 0 if the tokens are burnt
+	recipient int64
+	amount    decimal.Decimal
+	// commission is the part of the fee which is paid to the commission wallet,
+	// it's written to the record of the fee of the node
+	commission decimal.Decimal
+	kind       string
+	comment    string
+	// existing is true if the recipient must exist, otherwise the key is created
+	existing bool
+}
+
+// moveTokens changes the balances of the keys and writes the record of the history table.
+// All the balances must be changed by this function, so the sum of the records of the history
+// is equal to the changes of the balances. errUpdNotExistRecord is returned and the balances
+// aren't changed if the recipient must exist and it doesn't
+func (sc *SmartContract) moveTokens(entry ledgerEntry, generalRollback bool) error {
+	keys := model.KeyTableName(entry.ecosystem)
+	amount := entry.amount.String()
+	if entry.recipient != 0 {
+		if _, _, err := sc.selectiveLoggingAndUpd([]string{`+amount`}, []interface{}{amount}, keys,
+			[]string{`id`}, []string{strconv.FormatInt(entry.recipient, 10)}, generalRollback, entry.existing); err != nil {
+			return err
+		}
+	}
+	if entry.sender != 0 {
+		if _, _, err := sc.selectiveLoggingAndUpd([]string{`-amount`}, []interface{}{amount}, keys,
+			[]string{`id`}, []string{strconv.FormatInt(entry.sender, 10)}, generalRollback, true); err != nil {
+			return err
+		}
+	}
+	var block int64
+	if sc.BlockData != nil {
+		block = sc.BlockData.BlockID
+	}
+	_, _, err := sc.selectiveLoggingAndUpd([]string{`sender_id`, `recipient_id`, `amount`, `commission`, `type`,
+		`comment`, `block_id`, `txhash`},
+		[]interface{}{entry.sender, entry.recipient, amount, entry.commission.String(), entry.kind,
+			entry.comment, block, sc.TxHash},
+		model.HistoryTableName(entry.ecosystem), nil, nil, generalRollback, false)
+	return err
+}
+
+// TransferTokens transfers the tokens of the ecosystem from the key of the transaction to the recipient
+func TransferTokens(sc *SmartContract, recipient int64, value interface{}, comment string) error {
+	if !accessContracts(sc, `MoneyTransfer`) {
+		log.WithFields(log.Fields{"type": consts.IncorrectCallingContract}).Error("TransferTokens can be only called from @1MoneyTransfer")
+		return errTransferTokens
+	}
+	amount, err := Money(value)
+	if err != nil {
+		return err
+	}
+	if amount.Sign() <= 0 {
+		return errTransferAmount
+	}
+	if recipient == 0 {
+		return errTransferRecipient
+	}
+	key := &model.Key{}
+	if _, err = key.SetTablePrefix(sc.TxSmart.EcosystemID).GetTransaction(sc.DbTransaction, sc.TxSmart.KeyID); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting key")
+		return err
+	}
+	balance, err := decimalOrZero(key.Amount)
+	if err != nil {
+		return err
+	}
+	if balance.LessThan(amount) {
+		return errTransferBalance
+	}
+	return sc.moveTokens(ledgerEntry{ecosystem: sc.TxSmart.EcosystemID, sender: sc.TxSmart.KeyID,
+		recipient: recipient, amount: amount, kind: model.HistoryTransfer, comment: comment}, sc.Rollback)
+}
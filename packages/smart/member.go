@@ -0,0 +1,64 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+const (
+	membersTable     = `members`
+	maxMemberNameLen = 255
+)
+
+var (
+	errMemberName  = fmt.Errorf(`Member name must be from 1 to %d characters`, maxMemberNameLen)
+	errMemberImage = errors.New(`Image id cannot be negative`)
+	errMemberInfo  = errors.New(`Member info must be JSON object`)
+)
+
+// UpdateMember creates or changes the profile of the member of the ecosystem. The member can change
+// its own profile, the profiles of the other members are changed if the conditions of the members table are true
+func UpdateMember(sc *SmartContract, id int64, name string, imageID int64, info string) error {
+	if len(name) == 0 || len(name) > maxMemberNameLen {
+		return errMemberName
+	}
+	if imageID < 0 {
+		return errMemberImage
+	}
+	if len(info) == 0 {
+		info = `{}`
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(info), &obj); err != nil || obj == nil {
+		return errMemberInfo
+	}
+
+	if id != sc.TxSmart.KeyID {
+		if err := sc.tableConditions(membersTable); err != nil {
+			return err
+		}
+	}
+
+	_, _, err := sc.selectiveLoggingAndUpd([]string{`member_name`, `image_id`, `member_info`},
+		[]interface{}{name, imageID, info}, sc.tablePrefix()+`_`+membersTable, []string{`id`},
+		[]string{strconv.FormatInt(id, 10)}, !sc.VDE && sc.Rollback, false)
+	return err
+}
@@ -0,0 +1,314 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	menuItemFunc  = `MenuItem`
+	menuGroupFunc = `MenuGroup`
+)
+
+// menuParams are the names of the positional parameters as the template defines them
+var menuParams = map[string][]string{
+	menuItemFunc:  {`Title`, `Page`, `PageParams`, `Icon`, `Vde`},
+	menuGroupFunc: {`Title`, `Body`, `Icon`},
+}
+
+// menuEntry is MenuItem or MenuGroup of the source of the menu
+type menuEntry struct {
+	Name     string
+	Params   map[string]string
+	Children []*menuEntry // the entries of the body of the group
+	start    int          // the offset of the call in the source
+	end      int          // the offset after the call including the body
+}
+
+// parseMenu parses the source of the menu. The entries of the bodies of the other functions
+// such as If are added to the list which contains the function
+func parseMenu(src string) ([]*menuEntry, error) {
+	return parseMenuBlock(src, 0, len(src))
+}
+
+func parseMenuBlock(src string, off, end int) ([]*menuEntry, error) {
+	list := make([]*menuEntry, 0)
+	for i := off; i < end; {
+		if !isIdentChar(src[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < end && isIdentChar(src[j]) {
+			j++
+		}
+		name := src[i:j]
+		k := skipMenuSpaces(src, j, end)
+		if k >= end || src[k] != '(' {
+			i = j
+			continue
+		}
+		pos, err := matchMenuParen(src, k, end)
+		if err != nil {
+			return nil, err
+		}
+		params := src[k+1 : pos-1]
+
+		var bodies [][2]int
+		for {
+			next := skipMenuSpaces(src, pos, end)
+			if next < end && src[next] == '{' {
+				bodyEnd, err := matchMenuBrace(src, next, end)
+				if err != nil {
+					return nil, err
+				}
+				bodies = append(bodies, [2]int{next + 1, bodyEnd - 1})
+				pos = bodyEnd
+				continue
+			}
+			// the tails such as .ElseIf(...){...} and .Else{...}
+			if next < end && src[next] == '.' {
+				tail := next + 1
+				for tail < end && isIdentChar(src[tail]) {
+					tail++
+				}
+				tail = skipMenuSpaces(src, tail, end)
+				if tail < end && src[tail] == '(' {
+					if pos, err = matchMenuParen(src, tail, end); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				if tail < end && src[tail] == '{' {
+					pos = tail
+					continue
+				}
+			}
+			break
+		}
+
+		switch name {
+		case menuItemFunc, menuGroupFunc:
+			entry := &menuEntry{Name: name, Params: parseMenuParams(params, menuParams[name]),
+				start: i, end: pos}
+			if name == menuGroupFunc {
+				entry.Children = make([]*menuEntry, 0)
+			}
+			for _, body := range bodies {
+				children, err := parseMenuBlock(src, body[0], body[1])
+				if err != nil {
+					return nil, err
+				}
+				if name == menuGroupFunc {
+					entry.Children = append(entry.Children, children...)
+				}
+			}
+			list = append(list, entry)
+		default:
+			for _, body := range bodies {
+				children, err := parseMenuBlock(src, body[0], body[1])
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, children...)
+			}
+		}
+		i = pos
+	}
+	return list, nil
+}
+
+func isIdentChar(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
+func skipMenuSpaces(src string, off, end int) int {
+	for off < end && (src[off] == ' ' || src[off] == '\t' || src[off] == '\r' || src[off] == '\n') {
+		off++
+	}
+	return off
+}
+
+// skipMenuQuote returns the offset after the quoted string, the doubled quote is the quote character
+func skipMenuQuote(src string, off, end int) (int, error) {
+	quote := src[off]
+	for i := off + 1; i < end; i++ {
+		if src[i] == quote {
+			if i+1 < end && src[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf(`Quote %c at %d is not closed`, quote, off)
+}
+
+// matchMenuParen returns the offset after the parenthesis which closes the parenthesis at off
+func matchMenuParen(src string, off, end int) (int, error) {
+	level := 0
+	for i := off; i < end; i++ {
+		switch src[i] {
+		case '"', '`':
+			next, err := skipMenuQuote(src, i, end)
+			if err != nil {
+				return 0, err
+			}
+			i = next - 1
+		case '(':
+			level++
+		case ')':
+			level--
+			if level == 0 {
+				return i + 1, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf(`Parenthesis at %d is not closed`, off)
+}
+
+// matchMenuBrace returns the offset after the brace which closes the body at off
+func matchMenuBrace(src string, off, end int) (int, error) {
+	level := 0
+	for i := off; i < end; i++ {
+		switch src[i] {
+		case '{':
+			level++
+		case '}':
+			level--
+			if level == 0 {
+				return i + 1, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf(`Brace at %d is not closed`, off)
+}
+
+// parseMenuParams splits the parameters of the function by the commas as the template does it,
+// the named parameters are Name:value, the others are taken by their position
+func parseMenuParams(input string, names []string) map[string]string {
+	var list []string
+	level, start := 0, 0
+	for i := 0; i < len(input); i++ {
+		switch input[i] {
+		case '"', '`':
+			if next, err := skipMenuQuote(input, i, len(input)); err == nil {
+				i = next - 1
+			}
+		case '(':
+			level++
+		case ')':
+			level--
+		case ',':
+			if level == 0 && len(list) < len(names)-1 {
+				list = append(list, input[start:i])
+				start = i + 1
+			}
+		}
+	}
+	list = append(list, input[start:])
+
+	params := make(map[string]string)
+	for i, item := range list {
+		item = strings.TrimSpace(item)
+		if off := strings.IndexByte(item, ':'); off > 0 {
+			key := strings.TrimSpace(item[:off])
+			if isMenuParam(key, names) {
+				params[key] = unquoteMenuParam(strings.TrimSpace(item[off+1:]))
+				continue
+			}
+		}
+		if i < len(names) && len(item) > 0 {
+			params[names[i]] = unquoteMenuParam(item)
+		}
+	}
+	return params
+}
+
+func isMenuParam(key string, names []string) bool {
+	for _, name := range names {
+		if name == key {
+			return true
+		}
+	}
+	return false
+}
+
+func unquoteMenuParam(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '`') && value[len(value)-1] == value[0] {
+		quote := value[:1]
+		return strings.Replace(value[1:len(value)-1], quote+quote, quote, -1)
+	}
+	return value
+}
+
+func quoteMenuParam(value string) string {
+	return `"` + strings.Replace(value, `"`, `""`, -1) + `"`
+}
+
+// findMenuItem returns the first item of the page in the entries and in the groups
+func findMenuItem(list []*menuEntry, page string) *menuEntry {
+	for _, entry := range list {
+		if entry.Name == menuItemFunc && entry.Params[`Page`] == page {
+			return entry
+		}
+		if found := findMenuItem(entry.Children, page); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// setMenuItem replaces the item of the page in the source of the menu or appends it to the end.
+// It returns false if the menu already has the item with the same title and icon
+func setMenuItem(src, title, page, icon string) (string, bool, error) {
+	list, err := parseMenu(src)
+	if err != nil {
+		return ``, false, err
+	}
+	params := map[string]string{`Title`: title, `Page`: page, `Icon`: icon}
+	item := findMenuItem(list, page)
+	if item != nil {
+		if item.Params[`Title`] == title && item.Params[`Icon`] == icon {
+			return src, false, nil
+		}
+		params[`PageParams`] = item.Params[`PageParams`]
+		params[`Vde`] = item.Params[`Vde`]
+		return src[:item.start] + formatMenuItem(params) + src[item.end:], true, nil
+	}
+	src = strings.TrimRight(src, " \t\r\n")
+	if len(src) > 0 {
+		src += "\n"
+	}
+	return src + formatMenuItem(params), true, nil
+}
+
+func formatMenuItem(params map[string]string) string {
+	pars := []string{`Title:` + quoteMenuParam(params[`Title`]), `Page:` + params[`Page`]}
+	if len(params[`PageParams`]) > 0 {
+		pars = append(pars, `PageParams:`+quoteMenuParam(params[`PageParams`]))
+	}
+	if len(params[`Icon`]) > 0 {
+		pars = append(pars, `Icon:`+quoteMenuParam(params[`Icon`]))
+	}
+	if len(params[`Vde`]) > 0 {
+		pars = append(pars, `Vde:`+params[`Vde`])
+	}
+	return menuItemFunc + `(` + strings.Join(pars, `, `) + `)`
+}
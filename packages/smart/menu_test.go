@@ -0,0 +1,107 @@
+package smart
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMenu(t *testing.T) {
+	src := `MenuItem(Title:"Application", Page:apps_list, Icon:"icon-folder")
+MenuGroup(Title:"Resources", Icon:"icon-share"){
+	MenuItem(Title:"Pages", Page:app_pages, Icon:"icon-screen-desktop")
+	MenuGroup(Sub menu){
+		MenuItem(Deep, deep_page, "a=1,b=2", icon-deep)
+	}
+	If(#key_id# == 1){
+		MenuItem(Title: "Say ""Hi"" (admin)", Page: admin_page)
+	}.Else{
+		MenuItem(Title: User, Page: user_page)
+	}
+}
+Div(Body: text){MenuItem(Page: div_page, Title: In div)}
+MenuItem(First)MenuItem(Second)`
+
+	list, err := parseMenu(src)
+	require.NoError(t, err)
+	require.Len(t, list, 5)
+
+	assert.Equal(t, map[string]string{"Title": "Application", "Page": "apps_list", "Icon": "icon-folder"},
+		list[0].Params)
+	group := list[1]
+	assert.Equal(t, menuGroupFunc, group.Name)
+	assert.Equal(t, map[string]string{"Title": "Resources", "Icon": "icon-share"}, group.Params)
+	require.Len(t, group.Children, 4)
+	assert.Equal(t, "app_pages", group.Children[0].Params["Page"])
+
+	sub := group.Children[1]
+	assert.Equal(t, menuGroupFunc, sub.Name)
+	assert.Equal(t, "Sub menu", sub.Params["Title"])
+	require.Len(t, sub.Children, 1)
+	assert.Equal(t, map[string]string{"Title": "Deep", "Page": "deep_page", "PageParams": "a=1,b=2",
+		"Icon": "icon-deep"}, sub.Children[0].Params)
+
+	assert.Equal(t, `Say "Hi" (admin)`, group.Children[2].Params["Title"])
+	assert.Equal(t, "user_page", group.Children[3].Params["Page"])
+	assert.Equal(t, "div_page", list[2].Params["Page"])
+	assert.Equal(t, "First", list[3].Params["Title"])
+	assert.Equal(t, "MenuItem(Second)", src[list[4].start:list[4].end])
+	assert.True(t, findMenuItem(list, "deep_page") == sub.Children[0])
+
+	for _, wrong := range []string{`MenuItem(Title:"open)`, `MenuGroup(Title){MenuItem(A)`, `MenuItem(A`} {
+		_, err = parseMenu(wrong)
+		assert.Error(t, err, wrong)
+	}
+}
+
+func TestSetMenuItem(t *testing.T) {
+	src := `MenuItem(Title:"Home", Page:home)
+MenuGroup(Admin){
+	MenuGroup(Tables){
+		MenuItem(Title:"Tables", Page:tables, PageParams:"sort=1", Icon:"icon-docs")
+	}
+}
+`
+	cases := []struct {
+		title, page, icon string
+		changed           bool
+		result            string
+	}{
+		// the same item
+		{"Home", "home", "", false, src},
+		// the item of the nested group
+		{"All tables", "tables", "icon-docs", true, `MenuItem(Title:"Home", Page:home)
+MenuGroup(Admin){
+	MenuGroup(Tables){
+		MenuItem(Title:"All tables", Page:tables, PageParams:"sort=1", Icon:"icon-docs")
+	}
+}
+`},
+		// the new item
+		{`New "page"`, "new_page", "icon-plus", true, `MenuItem(Title:"Home", Page:home)
+MenuGroup(Admin){
+	MenuGroup(Tables){
+		MenuItem(Title:"Tables", Page:tables, PageParams:"sort=1", Icon:"icon-docs")
+	}
+}
+MenuItem(Title:"New ""page""", Page:new_page, Icon:"icon-plus")`},
+	}
+	for _, v := range cases {
+		result, changed, err := setMenuItem(src, v.title, v.page, v.icon)
+		require.NoError(t, err)
+		assert.Equal(t, v.changed, changed, v.title)
+		assert.Equal(t, v.result, result, v.title)
+
+		// the second call doesn't change the menu
+		again, changed, err := setMenuItem(result, v.title, v.page, v.icon)
+		require.NoError(t, err)
+		assert.False(t, changed, v.title)
+		assert.Equal(t, result, again)
+	}
+
+	result, changed, err := setMenuItem(``, "Home", "home", "")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, `MenuItem(Title:"Home", Page:home)`, result)
+}
@@ -0,0 +1,204 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	rolesTable             = `roles`
+	rolesParticipantsTable = `roles_participants`
+	maxRoleNameLen         = 255
+)
+
+var (
+	errRoleName       = fmt.Errorf(`Role name must be from 1 to %d characters`, maxRoleNameLen)
+	errRoleExists     = errors.New(`Role with the same name already exists`)
+	errRoleNotFound   = errors.New(`Role has not been found`)
+	errRoleAssigned   = errors.New(`Role is already assigned to the member`)
+	errRoleUnassigned = errors.New(`Role isn't assigned to the member`)
+)
+
+// tablePrefix returns the prefix of the tables of the ecosystem
+func (sc *SmartContract) tablePrefix() string {
+	prefix := strconv.FormatInt(sc.TxSmart.EcosystemID, 10)
+	if sc.VDE {
+		prefix += `_vde`
+	}
+	return prefix
+}
+
+// tableConditions checks the conditions of changing the table of the ecosystem
+func (sc *SmartContract) tableConditions(table string) error {
+	t := &model.Table{}
+	t.SetTablePrefix(sc.tablePrefix())
+	found, err := t.Get(sc.DbTransaction, table)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table}).Error("getting table")
+		return err
+	}
+	if !found {
+		return fmt.Errorf(eTableNotFound, table)
+	}
+	ret, err := sc.EvalIf(t.Conditions)
+	if err != nil {
+		return err
+	}
+	if !ret {
+		log.WithFields(log.Fields{"type": consts.AccessDenied, "table": table, "key_id": sc.TxSmart.KeyID}).Error("table conditions")
+		return errAccessDenied
+	}
+	return nil
+}
+
+// CreateRole creates the role if the conditions of the roles table are true and returns its id
+func CreateRole(sc *SmartContract, name string, roleType int64) (int64, error) {
+	if len(name) == 0 || len(name) > maxRoleNameLen {
+		return 0, errRoleName
+	}
+	if err := sc.tableConditions(rolesTable); err != nil {
+		return 0, err
+	}
+	table := sc.tablePrefix() + `_` + rolesTable
+	row, err := model.GetOneRowTransaction(sc.DbTransaction, `SELECT id FROM "`+table+`" WHERE role_name = ? AND deleted = 0`,
+		name).String()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting role by name")
+		return 0, err
+	}
+	if len(row) > 0 {
+		return 0, errRoleExists
+	}
+	_, id, err := sc.selectiveLoggingAndUpd([]string{`role_name`, `role_type`, `creator->member_id`, `timestamp date_created`},
+		[]interface{}{name, roleType, strconv.FormatInt(sc.TxSmart.KeyID, 10), sc.TxSmart.Time}, table, nil, nil,
+		!sc.VDE && sc.Rollback, false)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(id, 10, 64)
+}
+
+// activeParticipant returns the id of the assignment of the role to the member or an empty string
+func (sc *SmartContract) activeParticipant(roleID, member int64) (string, error) {
+	row, err := model.GetOneRowTransaction(sc.DbTransaction, `SELECT id FROM "`+sc.tablePrefix()+`_`+rolesParticipantsTable+
+		`" WHERE role->>'id' = ? AND member->>'member_id' = ? AND deleted = 0`,
+		strconv.FormatInt(roleID, 10), strconv.FormatInt(member, 10)).String()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting role participant")
+		return ``, err
+	}
+	return row[`id`], nil
+}
+
+// AssignRole assigns the role to the member if the conditions of the roles_participants table are true
+func AssignRole(sc *SmartContract, roleID, member int64) error {
+	if err := sc.tableConditions(rolesParticipantsTable); err != nil {
+		return err
+	}
+	role := &model.Role{}
+	role.SetTablePrefix(sc.TxSmart.EcosystemID)
+	found, err := role.GetActive(sc.DbTransaction, roleID)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting role")
+		return err
+	}
+	if !found {
+		return errRoleNotFound
+	}
+	id, err := sc.activeParticipant(roleID, member)
+	if err != nil {
+		return err
+	}
+	if len(id) > 0 {
+		return errRoleAssigned
+	}
+	profile, err := model.GetOneRowTransaction(sc.DbTransaction, `SELECT member_name, image_id FROM "`+
+		sc.tablePrefix()+`_`+membersTable+`" WHERE id = ?`, member).String()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting member")
+		return err
+	}
+	_, _, err = sc.selectiveLoggingAndUpd([]string{`role->id`, `role->type`, `role->name`, `role->image_id`,
+		`member->member_id`, `member->member_name`, `member->image_id`, `appointed->member_id`, `timestamp date_created`},
+		[]interface{}{strconv.FormatInt(roleID, 10), strconv.FormatInt(role.RoleType, 10), role.RoleName,
+			strconv.FormatInt(role.ImageID, 10), strconv.FormatInt(member, 10), profile[`member_name`],
+			profile[`image_id`], strconv.FormatInt(sc.TxSmart.KeyID, 10), sc.TxSmart.Time},
+		sc.tablePrefix()+`_`+rolesParticipantsTable, nil, nil, !sc.VDE && sc.Rollback, false)
+	return err
+}
+
+// RemoveRole takes the role away from the member if the conditions of the roles_participants table are true
+func RemoveRole(sc *SmartContract, roleID, member int64) error {
+	if err := sc.tableConditions(rolesParticipantsTable); err != nil {
+		return err
+	}
+	id, err := sc.activeParticipant(roleID, member)
+	if err != nil {
+		return err
+	}
+	if len(id) == 0 {
+		return errRoleUnassigned
+	}
+	_, _, err = sc.selectiveLoggingAndUpd([]string{`deleted`, `timestamp date_deleted`},
+		[]interface{}{1, sc.TxSmart.Time}, sc.tablePrefix()+`_`+rolesParticipantsTable, []string{`id`}, []string{id},
+		!sc.VDE && sc.Rollback, false)
+	return err
+}
+
+// RoleAccess returns true if the key of the transaction holds one of the roles
+func RoleAccess(sc *SmartContract, ids ...interface{}) (bool, error) {
+	for _, item := range ids {
+		id, err := Int(item)
+		if err != nil {
+			return false, err
+		}
+		ok, err := model.MemberHasRole(sc.DbTransaction, sc.TxSmart.EcosystemID, sc.TxSmart.KeyID, id)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("checking role of member")
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkTxRole checks that the key holds the role which the transaction is sent on behalf of
+func (sc *SmartContract) checkTxRole(keyID int64) error {
+	if sc.TxSmart.RoleID == 0 {
+		return nil
+	}
+	ok, err := model.MemberHasRole(sc.DbTransaction, sc.TxSmart.EcosystemID, keyID, sc.TxSmart.RoleID)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("checking role of transaction")
+		return err
+	}
+	if !ok {
+		log.WithFields(log.Fields{"type": consts.AccessDenied, "key_id": keyID, "role_id": sc.TxSmart.RoleID}).Error("key doesn't hold the role of transaction")
+		return ErrTxRole
+	}
+	return nil
+}
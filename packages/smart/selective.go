@@ -22,17 +22,22 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
 	"github.com/GenesisKernel/go-genesis/packages/model"
 	"github.com/GenesisKernel/go-genesis/packages/model/querycost"
+	"github.com/GenesisKernel/go-genesis/packages/tracing"
 
 	log "github.com/sirupsen/logrus"
 )
 
+const notificationsTableSuffix = `_notifications`
+
 var (
 	errUpdNotExistRecord = errors.New(`Update for not existing record`)
+	errReadOnly          = errors.New(`Writing to DB is disabled in the read-only mode`)
 )
 
 func (sc *SmartContract) selectiveLoggingAndUpd(fields []string, ivalues []interface{},
@@ -45,6 +50,11 @@ func (sc *SmartContract) selectiveLoggingAndUpd(fields []string, ivalues []inter
 		rollbackInfoStr string
 	)
 	logger := sc.GetLogger()
+	if sc.ReadOnly {
+		logger.WithFields(log.Fields{"type": consts.AccessDenied, "table": table}).Error("writing to DB in read-only mode")
+		return 0, ``, errReadOnly
+	}
+	sc.resetAccess(table)
 
 	if generalRollback && sc.BlockData == nil {
 		logger.WithFields(log.Fields{"type": consts.EmptyObject}).Error("Block is undefined")
@@ -87,6 +97,18 @@ func (sc *SmartContract) selectiveLoggingAndUpd(fields []string, ivalues []inter
 		}
 	}
 
+	if whereFields != nil {
+		counters, err := sc.tableCounters(table)
+		if err != nil {
+			return 0, ``, err
+		}
+		for _, column := range counters.columns {
+			if !strings.Contains(addSQLFields+`,`, `"`+column+`",`) {
+				addSQLFields += `"` + column + `",`
+			}
+		}
+	}
+
 	addSQLWhere := ""
 	if whereFields != nil && whereValues != nil {
 		for i := 0; i < len(whereFields); i++ {
@@ -117,32 +139,16 @@ func (sc *SmartContract) selectiveLoggingAndUpd(fields []string, ivalues []inter
 		logger.WithFields(log.Fields{"type": consts.NotFound, "err": errUpdNotExistRecord, "query": selectQuery}).Error("updating for not existing record")
 		return 0, tableID, errUpdNotExistRecord
 	}
+	if err = sc.checkBounds(table, fields, values, logData); err != nil {
+		return 0, tableID, err
+	}
 	jsonFields := make(map[string]map[string]string)
 	if whereFields != nil && len(logData) > 0 {
-		rollbackInfo := make(map[string]string)
-		for k, v := range logData {
-			if k == `id` {
-				continue
-			}
-			if converter.IsByteColumn(table, k) && v != "" {
-				rollbackInfo[k] = string(converter.BinToHex([]byte(v)))
-			} else {
-				rollbackInfo[k] = v
-			}
-			if k[:1] == "+" || k[:1] == "-" {
-				addSQLFields += k[1:] + ","
-			} else if strings.HasPrefix(k, `timestamp `) {
-				addSQLFields += k[len(`timestamp `):] + `,`
-			} else {
-				addSQLFields += k + ","
-			}
-		}
-		jsonRollbackInfo, err := json.Marshal(rollbackInfo)
+		rollbackInfoStr, err = model.MarshalRollbackDiff(rollbackDiff(table, fields, values, logData))
 		if err != nil {
 			logger.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling rollback info to json")
 			return 0, tableID, err
 		}
-		rollbackInfoStr = string(jsonRollbackInfo)
 		addSQLUpdate := ""
 		for i := 0; i < len(fields); i++ {
 			if converter.IsByteColumn(table, fields[i]) && len(values[i]) != 0 {
@@ -284,8 +290,23 @@ func (sc *SmartContract) selectiveLoggingAndUpd(fields []string, ivalues []inter
 	if err != nil {
 		return 0, tableID, err
 	}
+	isInsert := whereFields == nil || len(logData) == 0
+	if isInsert && whereFields != nil {
+		fields = append(append([]string{}, fields...), whereFields...)
+		values = append(append([]string{}, values...), whereValues...)
+	}
+	counterCost, err := sc.updateCounters(table, fields, values, logData, isInsert, generalRollback)
+	if err != nil {
+		return 0, tableID, err
+	}
+	cost += counterCost
+
+	if sc.Notifications != nil && strings.HasSuffix(table, notificationsTableSuffix) {
+		sc.Notifications.Add(converter.StrToInt64(strings.TrimSuffix(table, notificationsTableSuffix)))
+	}
 
 	if generalRollback {
+		start := time.Now()
 		rollbackTx := &model.RollbackTx{
 			BlockID:   sc.BlockData.BlockID,
 			TxHash:    sc.TxHash,
@@ -299,10 +320,55 @@ func (sc *SmartContract) selectiveLoggingAndUpd(fields []string, ivalues []inter
 			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("creating rollback tx")
 			return 0, tableID, err
 		}
+		tracing.Measure(tracing.StageRollbackWrite, time.Since(start))
 	}
 	return cost, tableID, nil
 }
 
+// rollbackDiff returns the previous values of the columns which are changed by the update.
+// The whole previous value of jsonb column is returned if one of its keys is changed
+func rollbackDiff(table string, fields, values []string, logData map[string]string) map[string]string {
+	diff := make(map[string]string)
+	for i, field := range fields {
+		var (
+			column  string
+			changed bool
+		)
+		switch {
+		case field[:1] == "+" || field[:1] == "-":
+			column, changed = field[1:], values[i] != "0"
+		case strings.HasPrefix(field, `timestamp `):
+			column, changed = field[len(`timestamp `):], true
+		case strings.Contains(field, `->`):
+			column = field[:strings.Index(field, `->`)]
+			changed = jsonKeyChanged(logData[column], field[len(column)+2:], values[i])
+		default:
+			column = field
+			changed = strings.HasPrefix(values[i], `timestamp `) || logData[column] != values[i]
+		}
+		v, ok := logData[column]
+		if !changed || !ok || column == `id` {
+			continue
+		}
+		if converter.IsByteColumn(table, column) && v != "" {
+			diff[column] = string(converter.BinToHex([]byte(v)))
+		} else {
+			diff[column] = v
+		}
+	}
+	return diff
+}
+
+// jsonKeyChanged returns false if the key of jsonb value already equals the value
+func jsonKeyChanged(data, key, value string) bool {
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &obj); err != nil {
+		return true
+	}
+	prev, ok := obj[key].(string)
+	return !ok || prev != value
+}
+
 func escapeSingleQuotes(val string) string {
 	return strings.Replace(val, `'`, `''`, -1)
 }
@@ -0,0 +1,40 @@
+package smart
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollbackDiff(t *testing.T) {
+	logData := map[string]string{
+		"id":         "5",
+		"name":       "page",
+		"value":      "old",
+		"amount":     "10",
+		"menu":       "default",
+		"parameters": `{"a":"1","b":"2"}`,
+		"pub":        "\x01\x02",
+	}
+	fields := []string{"id", "name", "value", "+amount", "menu", "parameters->a", "pub"}
+	values := []string{"5", "page", "new", "5", "NULL", "1", "\x01\x02"}
+	assert.Equal(t, map[string]string{
+		"value":  "old",
+		"amount": "10",
+		"menu":   "default",
+	}, rollbackDiff("1_keys", fields, values, logData))
+
+	assert.Equal(t, map[string]string{
+		"parameters": `{"a":"1","b":"2"}`,
+		"pub":        "0102",
+	}, rollbackDiff("1_keys", []string{"parameters->b", "pub"}, []string{"3", "\x03"}, logData))
+
+	assert.Equal(t, map[string]string{}, rollbackDiff("1_pages", []string{"name", "+amount"},
+		[]string{"page", "0"}, logData))
+}
+
+func TestReadOnly(t *testing.T) {
+	sc := &SmartContract{ReadOnly: true}
+	_, _, err := sc.selectiveLoggingAndUpd([]string{"name"}, []interface{}{"page"}, "1_pages", nil, nil, false, false)
+	assert.Equal(t, errReadOnly, err)
+}
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -33,6 +34,7 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/model"
 	"github.com/GenesisKernel/go-genesis/packages/script"
 	"github.com/GenesisKernel/go-genesis/packages/utils"
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
 
 	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
@@ -64,6 +66,7 @@ const (
 	MaxPrice = 100000000000000000
 
 	CallDelayedContract = "@1CallDelayedContract"
+	CallUpgradeContract = "@1CallContractUpgrade"
 	NewUserContract     = "@1NewUser"
 	NewBadBlockContract = "@1NewBadBlock"
 )
@@ -74,7 +77,7 @@ var (
 
 	ErrCurrentBalance = errors.New(`current balance is not enough`)
 	ErrDeletedKey     = errors.New(`The key is deleted`)
-	ErrDiffKeys       = errors.New(`Contract and user public keys are different`)
+	ErrDiffKeys       = utils.ErrDiffKeys
 	ErrEmptyPublicKey = errors.New(`empty public key`)
 	ErrFounderAccount = errors.New(`Unknown founder account`)
 	ErrFuelRate       = errors.New(`Fuel rate must be greater than 0`)
@@ -84,6 +87,15 @@ var (
 	ErrWrongPriceFunc = errors.New(`Wrong type of price function`)
 	ErrNegPrice       = errors.New(`Price value is negative`)
 	ErrMaxPrice       = errors.New(fmt.Sprintf(`Price value is more than %d`, MaxPrice))
+	ErrTxNonce        = errors.New(`Nonce must be greater than the nonce of the previous transaction`)
+	ErrTxRole         = errors.New(`The key doesn't hold the role of the transaction`)
+
+	// getTxNonce returns the last executed nonce of the key
+	getTxNonce = func(transaction *model.DbTransaction, keyID int64) (int64, error) {
+		nonce := &model.TxNonce{}
+		_, err := nonce.GetTransaction(transaction, keyID)
+		return nonce.Nonce, err
+	}
 )
 
 func testValue(name string, v ...interface{}) {
@@ -121,6 +133,19 @@ func newVM() *script.VM {
 
 func init() {
 	smartVM = newVM()
+	tx.ContractFields = contractFields
+}
+
+// contractFields returns the parameters of the contract by the full name
+func contractFields(name string) ([]*script.FieldInfo, bool) {
+	contract := GetContract(name, 0)
+	if contract == nil {
+		return nil, false
+	}
+	if info := contract.Block.Info.(*script.ContractInfo); info.Tx != nil {
+		return *info.Tx, true
+	}
+	return nil, true
 }
 
 // GetVM is returning smart vm
@@ -158,12 +183,32 @@ func vmExtend(vm *script.VM, ext *script.ExtendData) {
 }
 
 func VMRun(vm *script.VM, block *script.Block, params []interface{}, extend *map[string]interface{}) (ret []interface{}, err error) {
+	return vmRun(vm, block, params, extend, nil)
+}
+
+// vmRun runs the block, the nested calls are counted from the parent runtime if it is specified
+func vmRun(vm *script.VM, block *script.Block, params []interface{}, extend *map[string]interface{},
+	parent *script.RunTime) (ret []interface{}, err error) {
 	var extcost int64
 	cost := script.CostDefault
 	if ecost, ok := (*extend)[`txcost`]; ok {
 		cost = ecost.(int64)
 	}
 	rt := vm.RunInit(cost)
+	if limit := syspar.GetMaxTxMemory(); limit > 0 {
+		rt.SetMemoryLimit(limit)
+	}
+	if limit := syspar.GetMaxCallDepth(); limit > 0 {
+		rt.SetCallDepthLimit(limit)
+	}
+	if parent != nil {
+		rt.InheritCallDepth(parent)
+	}
+	if script.TraceEnabled {
+		if sc, ok := (*extend)[`sc`].(*SmartContract); ok && sc.tracer != nil {
+			rt.SetTracer(sc.tracer)
+		}
+	}
 	ret, err = rt.Run(block, params, extend)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.VMError, "error": err}).Error("running block in smart vm")
@@ -175,6 +220,12 @@ func VMRun(vm *script.VM, block *script.Block, params []interface{}, extend *map
 	return
 }
 
+// SetTracer sets the tracer of the execution of the contract. The tracer is called only if
+// the virtual machine is compiled with the trace hooks, see script.TraceEnabled
+func (sc *SmartContract) SetTracer(tracer script.Tracer) {
+	sc.tracer = tracer
+}
+
 func VMGetContract(vm *script.VM, name string, state uint32) *Contract {
 	name = script.StateName(state, name)
 	obj, ok := vm.Objects[name]
@@ -196,9 +247,15 @@ func vmGetUsedContracts(vm *script.VM, name string, state uint32, full bool) []s
 	if contract == nil || contract.Block.Info.(*script.ContractInfo).Used == nil {
 		return nil
 	}
+	keys := make([]string, 0, len(contract.Block.Info.(*script.ContractInfo).Used))
+	for key := range contract.Block.Info.(*script.ContractInfo).Used {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
 	ret := make([]string, 0)
 	used := make(map[string]bool)
-	for key := range contract.Block.Info.(*script.ContractInfo).Used {
+	for _, key := range keys {
 		ret = append(ret, key)
 		used[key] = true
 		if full {
@@ -223,7 +280,7 @@ func VMGetContractByID(vm *script.VM, id int32) *Contract {
 		Block: vm.Children[idcont]}
 }
 
-func vmExtendCost(vm *script.VM, ext func(string) int64) {
+func vmExtendCost(vm *script.VM, ext func(string, map[string]interface{}) int64) {
 	vm.ExtCost = ext
 }
 
@@ -262,7 +319,7 @@ func FlushBlock(root *script.Block) {
 }
 
 // ExtendCost sets the cost of calling extended obj in smartVM
-func ExtendCost(ext func(string) int64) {
+func ExtendCost(ext func(string, map[string]interface{}) int64) {
 	vmExtendCost(smartVM, ext)
 }
 
@@ -324,9 +381,10 @@ func GetContractByID(id int32) *Contract {
 	return VMGetContractByID(smartVM, id)
 }
 
-// GetFunc returns the block of the specified function in the contract
+// GetFunc returns the block of the specified function in the contract, private functions are not returned
 func (contract *Contract) GetFunc(name string) *script.Block {
-	if block, ok := (*contract).Block.Objects[name]; ok && block.Type == script.ObjFunc {
+	if block, ok := (*contract).Block.Objects[name]; ok && block.Type == script.ObjFunc &&
+		!block.Value.(*script.Block).Info.(*script.FuncInfo).Private {
 		return block.Value.(*script.Block)
 	}
 	return nil
@@ -341,25 +399,28 @@ func LoadContracts(transaction *model.DbTransaction) error {
 	}
 
 	defer ExternOff()
-	if err := LoadContract(transaction, "system"); err != nil {
+	cache := newContractCache(smartVM, `contracts`)
+	if err := loadContract(transaction, "system", cache); err != nil {
 		return err
 	}
 
 	for _, ecosystemID := range ecosystemsIds {
 		prefix := strconv.FormatInt(ecosystemID, 10)
-		if err := LoadContract(transaction, prefix); err != nil {
+		if err := loadContract(transaction, prefix, cache); err != nil {
 			return err
 		}
 	}
 
 	ExternOff()
+	cache.prune()
 	return nil
 }
 
 func LoadSysFuncs(vm *script.VM, state int) error {
 	code := `func DBFind(table string).Columns(columns string).Where(where string, params ...)
-	.WhereId(id int).Order(order string).Limit(limit int).Offset(offset int).Ecosystem(ecosystem int) array {
-   return DBSelect(table, columns, id, order, offset, limit, ecosystem, where, params)
+	.WhereId(id int).Order(order string).Limit(limit int).Offset(offset int).Ecosystem(ecosystem int)
+	.IncludeDeleted(include bool) array {
+   return DBSelect(table, columns, id, order, offset, limit, ecosystem, include, where, params)
 }
 
 func One(list array, name string) string {
@@ -408,10 +469,10 @@ func Row(list array) map {
 }
 
 func DBRow(table string).Columns(columns string).Where(where string, params ...)
-   .WhereId(id int).Order(order string).Ecosystem(ecosystem int) map {
+   .WhereId(id int).Order(order string).Ecosystem(ecosystem int).IncludeDeleted(include bool) map {
    
    var result array
-   result = DBFind(table).Columns(columns).Where(where, params...).WhereId(id).Order(order).Ecosystem(ecosystem)
+   result = DBFind(table).Columns(columns).Where(where, params...).WhereId(id).Order(order).Ecosystem(ecosystem).IncludeDeleted(include)
 
    var row map
    if Len(result) > 0 {
@@ -438,7 +499,11 @@ func ConditionById(table string, validate bool) {
 }
 
 // LoadContract reads and compiles contract of new state
-func LoadContract(transaction *model.DbTransaction, prefix string) (err error) {
+func LoadContract(transaction *model.DbTransaction, prefix string) error {
+	return loadContract(transaction, prefix, nil)
+}
+
+func loadContract(transaction *model.DbTransaction, prefix string, cache *contractCache) (err error) {
 	var contracts []map[string]string
 	contracts, err = model.GetAllTransaction(transaction, `select * from "`+prefix+`_contracts" order by id`, -1)
 	if err != nil {
@@ -461,17 +526,26 @@ func LoadContract(transaction *model.DbTransaction, prefix string) (err error) {
 			WalletID: converter.StrToInt64(item[`wallet_id`]),
 			TokenID:  converter.StrToInt64(item[`token_id`]),
 		}
-		if err = Compile(item[`value`], &owner); err != nil {
+		if err = cache.compile(smartVM, item[`value`], &owner); err != nil {
 			log.WithFields(log.Fields{"type": consts.EvalError, "names": names, "error": err}).Error("Load Contract")
 		} else {
 			log.WithFields(log.Fields{"contract_name": names, "contract_id": item["id"], "contract_active": item["active"]}).Info("OK Loading Contract")
 		}
 	}
-	LoadVDEContracts(transaction, prefix)
+	loadVDEContracts(transaction, prefix, cache)
 	return
 }
 
+// LoadVDEContracts reads and compiles contracts of VDE
 func LoadVDEContracts(transaction *model.DbTransaction, prefix string) (err error) {
+	cache := newContractCache(GetVM(), `vde`+prefix)
+	if err = loadVDEContracts(transaction, prefix, cache); err == nil {
+		cache.prune()
+	}
+	return
+}
+
+func loadVDEContracts(transaction *model.DbTransaction, prefix string, cache *contractCache) (err error) {
 	var contracts []map[string]string
 
 	if !model.IsTable(prefix + `_contracts`) {
@@ -492,6 +566,7 @@ func LoadVDEContracts(transaction *model.DbTransaction, prefix string) (err erro
 	}
 
 	EmbedFuncs(vm, vmt)
+	cache.mix(fmt.Sprintf("vde %d", vmt))
 	LoadSysFuncs(vm, int(state))
 	for _, item := range contracts {
 		list, err := script.ContractsList(item[`value`])
@@ -508,7 +583,7 @@ func LoadVDEContracts(transaction *model.DbTransaction, prefix string) (err erro
 			TokenID:  0,
 		}
 
-		if err = vmCompile(vm, item[`value`], &owner); err != nil {
+		if err = cache.compile(vm, item[`value`], &owner); err != nil {
 			log.WithFields(log.Fields{"names": names, "error": err}).Error("Load VDE Contract")
 		} else {
 			log.WithFields(log.Fields{"names": names, "contract_id": item["id"]}).Info("OK Load VDE Contract")
@@ -546,6 +621,7 @@ func (sc *SmartContract) getExtend() *map[string]interface{} {
 		`original_contract`: ``,
 		`this_contract`:     ``,
 		`role_id`:           head.RoleID,
+		`signers`:           []interface{}{},
 	}
 
 	for key, val := range sc.TxData {
@@ -584,6 +660,17 @@ func (sc *SmartContract) IsCustomTable(table string) (isCustom bool, err error)
 	return false, nil
 }
 
+// isFounder returns true if the key of the transaction is the founder account of the ecosystem.
+// The malformed founder account doesn't match any key
+func (sc *SmartContract) isFounder() bool {
+	founder, err := converter.StrToInt64E(EcosysParam(sc, `founder_account`))
+	if err != nil {
+		sc.GetLogger().WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting founder account")
+		return false
+	}
+	return sc.TxSmart.KeyID == founder
+}
+
 // AccessTable checks the access right to the table
 func (sc *SmartContract) AccessTablePerm(table, action string) (map[string]string, error) {
 	var (
@@ -593,7 +680,7 @@ func (sc *SmartContract) AccessTablePerm(table, action string) (map[string]strin
 	logger := sc.GetLogger()
 	isRead := action == `read`
 	if table == getDefTableName(sc, `parameters`) || table == getDefTableName(sc, `app_params`) {
-		if isRead || sc.TxSmart.KeyID == converter.StrToInt64(EcosysParam(sc, `founder_account`)) {
+		if isRead || sc.isFounder() {
 			return tablePermission, nil
 		}
 		logger.WithFields(log.Fields{"type": consts.AccessDenied}).Error("Access denied")
@@ -632,12 +719,20 @@ func (sc *SmartContract) AccessTablePerm(table, action string) (map[string]strin
 	return tablePermission, nil
 }
 
+// AccessTable checks the access right to the table, the granted access isn't checked again in the transaction
 func (sc *SmartContract) AccessTable(table, action string) error {
 	if sc.FullAccess {
 		return nil
 	}
-	_, err := sc.AccessTablePerm(table, action)
-	return err
+	key := sc.accessKey(table, action)
+	if _, ok := sc.grantedAccess(key); ok {
+		return nil
+	}
+	if _, err := sc.AccessTablePerm(table, action); err != nil {
+		return err
+	}
+	sc.grantAccess(key, nil)
+	return nil
 }
 
 func getPermColumns(input string) (perm permColumn, err error) {
@@ -651,15 +746,31 @@ func getPermColumns(input string) (perm permColumn, err error) {
 	return
 }
 
-// AccessColumns checks access rights to the columns
+// AccessColumns checks access rights to the columns, the granted access isn't checked again in the transaction.
+// The columns without the read access are removed from the read columns
 func (sc *SmartContract) AccessColumns(table string, columns *[]string, update bool) error {
-	logger := sc.GetLogger()
 	if sc.FullAccess {
 		return nil
 	}
+	key := sc.accessKey(table, strings.Join(*columns, ","), strconv.FormatBool(update))
+	if granted, ok := sc.grantedAccess(key); ok {
+		if !update {
+			*columns = append([]string{}, granted...)
+		}
+		return nil
+	}
+	if err := sc.accessColumns(table, columns, update); err != nil {
+		return err
+	}
+	sc.grantAccess(key, append([]string{}, *columns...))
+	return nil
+}
+
+func (sc *SmartContract) accessColumns(table string, columns *[]string, update bool) error {
+	logger := sc.GetLogger()
 	if table == getDefTableName(sc, `parameters`) || table == getDefTableName(sc, `app_params`) {
 		if update {
-			if sc.TxSmart.KeyID == converter.StrToInt64(EcosysParam(sc, `founder_account`)) {
+			if sc.isFounder() {
 				return nil
 			}
 			return errAccessDenied
@@ -821,14 +932,36 @@ func (sc *SmartContract) GetContractLimit() (ret int64) {
 	return sc.TxCost
 }
 
-func (sc *SmartContract) GetSignedBy(public []byte) (int64, error) {
+// checkTxNonce checks that the nonce of the transaction is greater than the last executed nonce
+// of the key and saves it. The transactions without the nonce aren't checked
+func (sc *SmartContract) checkTxNonce(keyID int64) error {
+	if sc.TxSmart.Nonce == 0 {
+		return nil
+	}
+	last, err := getTxNonce(sc.DbTransaction, keyID)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting last nonce of key")
+		return err
+	}
+	if sc.TxSmart.Nonce <= last {
+		log.WithFields(log.Fields{"type": consts.InvalidObject, "key_id": keyID, "nonce": sc.TxSmart.Nonce, "last": last}).Error("nonce of transaction has been used")
+		return ErrTxNonce
+	}
+	_, _, err = sc.selectiveLoggingAndUpd([]string{`nonce`}, []interface{}{sc.TxSmart.Nonce},
+		model.TxNonce{}.TableName(), []string{`id`}, []string{converter.Int64ToStr(keyID)}, sc.Rollback, false)
+	return err
+}
+
+// GetSignedBy returns the key id which has signed the transaction. The public key of the key id
+// is defined by utils.SignerPublicKey
+func (sc *SmartContract) GetSignedBy() (int64, error) {
 	signedBy := sc.TxSmart.KeyID
 	if sc.TxSmart.SignedBy != 0 {
 		var isNode bool
 		signedBy = sc.TxSmart.SignedBy
 		fullNodes := syspar.GetNodes()
-		if sc.TxContract.Name != CallDelayedContract && sc.TxContract.Name != NewUserContract &&
-			sc.TxContract.Name != NewBadBlockContract {
+		if sc.TxContract.Name != CallDelayedContract && sc.TxContract.Name != CallUpgradeContract &&
+			sc.TxContract.Name != NewUserContract && sc.TxContract.Name != NewBadBlockContract {
 			return 0, errDelayedContract
 		}
 		if len(fullNodes) > 0 {
@@ -848,18 +981,119 @@ func (sc *SmartContract) GetSignedBy(public []byte) (int64, error) {
 		if !isNode {
 			return 0, errDelayedContract
 		}
-	} else if len(public) > 0 && sc.TxSmart.KeyID != crypto.Address(public) {
-		return 0, ErrDiffKeys
 	}
 	return signedBy, nil
 }
 
+// checkSign checks the signature of the key which has signed the transaction and returns the key id.
+// The wallet gets the record of the key
+func (sc *SmartContract) checkSign(wallet *model.Key) (int64, error) {
+	var public []byte
+	logger := sc.GetLogger()
+	if len(sc.TxSmart.PublicKey) > 0 && string(sc.TxSmart.PublicKey) != `null` {
+		public = sc.TxSmart.PublicKey
+	}
+	signedBy, err := sc.GetSignedBy()
+	if err != nil {
+		return 0, err
+	}
+	// the key can be changed by the previous transaction of the block
+	_, err = wallet.GetTransaction(sc.DbTransaction, signedBy)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting wallet")
+		return 0, err
+	}
+	if wallet.Deleted == 1 {
+		return 0, ErrDeletedKey
+	}
+	if sc.TxSmart.SignedBy == 0 {
+		if public, err = utils.SignerPublicKey(signedBy, wallet.PublicKey, public); err != nil {
+			logger.WithFields(log.Fields{"type": consts.InvalidObject, "key_id": signedBy, "error": err}).Error("getting public key of wallet")
+			return 0, err
+		}
+	} else if len(wallet.PublicKey) > 0 {
+		public = wallet.PublicKey
+	}
+	if sc.TxSmart.Type == 258 { // UpdFullNodes
+		node := syspar.GetNode(sc.TxSmart.KeyID)
+		if node == nil {
+			logger.WithFields(log.Fields{"user_id": sc.TxSmart.KeyID, "type": consts.NotFound}).Error("unknown node id")
+			return 0, ErrUnknownNodeID
+		}
+		public = node.PublicKey
+	}
+	if len(public) == 0 {
+		logger.WithFields(log.Fields{"type": consts.EmptyObject}).Error("empty public key")
+		return 0, ErrEmptyPublicKey
+	}
+	sc.PublicKeys = append(sc.PublicKeys, public)
+
+	CheckSignResult := len(sc.VerifiedKey) > 0 && bytes.Equal(sc.VerifiedKey, public)
+	if !CheckSignResult {
+		CheckSignResult, err = utils.CheckSign(sc.PublicKeys, sc.TxData[`forsign`].(string), sc.TxSmart.BinSignatures, false)
+	}
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("checking tx data sign")
+		return 0, err
+	}
+	if !CheckSignResult {
+		logger.WithFields(log.Fields{"type": consts.InvalidObject}).Error("incorrect sign")
+		return 0, ErrIncorrectSign
+	}
+	(*sc.TxContract.Extend)[`signers`] = []interface{}{signedBy}
+	return signedBy, nil
+}
+
+// checkMultiSign checks the signatures of the co-signers of the transaction, at least the threshold
+// of them must sign it. The key ids which have signed the transaction are available as $signers.
+// The wallet gets the record of the key id of the co-signers
+func (sc *SmartContract) checkMultiSign(wallet *model.Key) (int64, error) {
+	logger := sc.GetLogger()
+	if err := sc.TxSmart.CheckSigners(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.InvalidObject, "error": err}).Error("checking signers of transaction")
+		return 0, err
+	}
+	if _, err := wallet.GetTransaction(sc.DbTransaction, sc.TxSmart.KeyID); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting wallet")
+		return 0, err
+	}
+	if wallet.Deleted == 1 {
+		return 0, ErrDeletedKey
+	}
+	publicKeys := make([][]byte, len(sc.TxSmart.Signers))
+	for i, keyID := range sc.TxSmart.Signers {
+		signer := &model.Key{}
+		signer.SetTablePrefix(sc.TxSmart.EcosystemID)
+		if _, err := signer.GetTransaction(sc.DbTransaction, keyID); err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting wallet of signer")
+			return 0, err
+		}
+		// the co-signer must have the stored public key which isn't deleted
+		if signer.Deleted == 0 {
+			publicKeys[i] = signer.PublicKey
+		}
+	}
+	signed, err := utils.CheckMultiSign(publicKeys, sc.TxData[`forsign`].(string), sc.TxSmart.BinSignatures,
+		int(sc.TxSmart.Threshold))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("checking tx data signs of signers")
+		return 0, err
+	}
+	signers := make([]interface{}, 0, len(signed))
+	for i, ok := range signed {
+		if ok {
+			signers = append(signers, sc.TxSmart.Signers[i])
+		}
+	}
+	(*sc.TxContract.Extend)[`signers`] = signers
+	return sc.TxSmart.KeyID, nil
+}
+
 // CallContract calls the contract functions according to the specified flags
 func (sc *SmartContract) CallContract(flags int) (string, error) {
 	var (
 		result                        string
 		err                           error
-		public                        []byte
 		sizeFuel, toID, fromID, price int64
 		fuelRate                      decimal.Decimal
 	)
@@ -868,70 +1102,59 @@ func (sc *SmartContract) CallContract(flags int) (string, error) {
 	sc.TxContract.Extend = sc.getExtend()
 
 	retError := func(err error) (string, error) {
-		eText := err.Error()
-		if !strings.HasPrefix(eText, `{`) {
-			err = script.SetVMError(`panic`, eText)
-		}
-		return ``, err
+		return ``, script.ToVMError(err)
 	}
 
 	methods := []string{`init`, `conditions`, `action`, `rollback`}
 	sc.AppendStack(sc.TxContract.Name)
-	sc.VM = GetVM()
+	// the contracts can be executed by the own vm, e.g. by the test harness
+	if sc.VM == nil {
+		sc.VM = GetVM()
+	}
+	if sc.VDE && (flags&CallRollback) == 0 && (flags&CallAction) != 0 {
+		countQuota, err := sc.startVDEQuota()
+		if err != nil {
+			return retError(err)
+		}
+		defer countQuota()
+	}
 	if (flags&CallRollback) == 0 && (flags&CallAction) != 0 {
 		if !sc.VDE {
 			toID = sc.BlockData.KeyID
 			fromID = sc.TxSmart.KeyID
 		}
-		if len(sc.TxSmart.PublicKey) > 0 && string(sc.TxSmart.PublicKey) != `null` {
-			public = sc.TxSmart.PublicKey
-		}
+		var signedBy int64
 		wallet := &model.Key{}
 		wallet.SetTablePrefix(sc.TxSmart.EcosystemID)
-		signedBy, err := sc.GetSignedBy(public)
-		if err != nil {
-			return retError(err)
+		if len(sc.TxSmart.Signers) > 0 {
+			signedBy, err = sc.checkMultiSign(wallet)
+		} else {
+			signedBy, err = sc.checkSign(wallet)
 		}
-		_, err = wallet.Get(signedBy)
 		if err != nil {
-			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting wallet")
 			return retError(err)
 		}
-		if wallet.Deleted == 1 {
-			return retError(ErrDeletedKey)
-		}
-		if len(wallet.PublicKey) > 0 {
-			public = wallet.PublicKey
-		}
-		if sc.TxSmart.Type == 258 { // UpdFullNodes
-			node := syspar.GetNode(sc.TxSmart.KeyID)
-			if node == nil {
-				logger.WithFields(log.Fields{"user_id": sc.TxSmart.KeyID, "type": consts.NotFound}).Error("unknown node id")
-				return retError(ErrUnknownNodeID)
+		if !sc.VDE {
+			if err = sc.checkTxNonce(signedBy); err != nil {
+				return retError(err)
+			}
+			if err = sc.checkTxRole(signedBy); err != nil {
+				return retError(err)
 			}
-			public = node.PublicKey
-		}
-		if len(public) == 0 {
-			logger.WithFields(log.Fields{"type": consts.EmptyObject}).Error("empty public key")
-			return retError(ErrEmptyPublicKey)
-		}
-		sc.PublicKeys = append(sc.PublicKeys, public)
-
-		var CheckSignResult bool
-		CheckSignResult, err = utils.CheckSign(sc.PublicKeys, sc.TxData[`forsign`].(string), sc.TxSmart.BinSignatures, false)
-		if err != nil {
-			logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("checking tx data sign")
-			return retError(err)
-		}
-		if !CheckSignResult {
-			logger.WithFields(log.Fields{"type": consts.InvalidObject}).Error("incorrect sign")
-			return retError(ErrIncorrectSign)
 		}
 		if sc.TxSmart.EcosystemID > 0 && !sc.VDE && !conf.Config.IsPrivateBlockchain() {
+			isActive := sc.TxContract.Block.Info.(*script.ContractInfo).Owner.Active
+			if isActive {
+				fromID = sc.TxContract.Block.Info.(*script.ContractInfo).Owner.WalletID
+				sc.TxSmart.TokenEcosystem = sc.TxContract.Block.Info.(*script.ContractInfo).Owner.TokenID
+			}
 			if sc.TxSmart.TokenEcosystem == 0 {
 				sc.TxSmart.TokenEcosystem = 1
 			}
-			fuelRate, err = decimal.NewFromString(syspar.GetFuelRate(sc.TxSmart.TokenEcosystem))
+			// the fee is paid by the tokens of the ecosystem which is declared in fuel_rate
+			fuel := syspar.GetFuel(sc.TxSmart.TokenEcosystem)
+			sc.TxSmart.TokenEcosystem = fuel.TokenEcosystem
+			fuelRate, err = decimal.NewFromString(fuel.Rate)
 			if err != nil {
 				logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": sc.TxSmart.TokenEcosystem}).Error("converting ecosystem fuel rate from string to decimal")
 				return retError(err)
@@ -940,25 +1163,10 @@ func (sc *SmartContract) CallContract(flags int) (string, error) {
 				logger.WithFields(log.Fields{"type": consts.ParameterExceeded}).Error("Fuel rate must be greater than 0")
 				return retError(ErrFuelRate)
 			}
-			var payOver decimal.Decimal
-			if len(sc.TxSmart.PayOver) > 0 {
-				payOver, err = decimal.NewFromString(sc.TxSmart.PayOver)
-				if err != nil {
-					log.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": sc.TxSmart.TokenEcosystem}).Error("converting tx smart pay over from string to decimal")
-					return retError(err)
-				}
-				fuelRate = fuelRate.Add(payOver)
-			}
-			isActive := sc.TxContract.Block.Info.(*script.ContractInfo).Owner.Active
-			if isActive {
-				fromID = sc.TxContract.Block.Info.(*script.ContractInfo).Owner.WalletID
-				sc.TxSmart.TokenEcosystem = sc.TxContract.Block.Info.(*script.ContractInfo).Owner.TokenID
-			} else if len(sc.TxSmart.PayOver) > 0 {
-				payOver, err = decimal.NewFromString(sc.TxSmart.PayOver)
-				if err != nil {
-					logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": sc.TxSmart.TokenEcosystem}).Error("converting tx smart pay over from string to decimal")
-					return retError(err)
-				}
+			// PayOver has been validated by CheckAmounts when the transaction was parsed
+			payOver := sc.TxSmart.PayOverValue()
+			fuelRate = fuelRate.Add(payOver)
+			if !isActive {
 				fuelRate = fuelRate.Add(payOver)
 			}
 			payWallet.SetTablePrefix(sc.TxSmart.TokenEcosystem)
@@ -1048,10 +1256,19 @@ func (sc *SmartContract) CallContract(flags int) (string, error) {
 		return retError(ErrCurrentBalance)
 	}
 
-	_, nameContract := script.ParseContract(sc.TxContract.Name)
+	_, nameContract, err := script.ParseContractStrict(sc.TxContract.Name)
+	if err != nil {
+		return retError(err)
+	}
 	(*sc.TxContract.Extend)[`original_contract`] = nameContract
 	(*sc.TxContract.Extend)[`this_contract`] = nameContract
 
+	if (flags&CallRollback) == 0 && sc.TxContract.Block.Info.(*script.ContractInfo).Tx != nil {
+		if err = script.ValidateFields(*sc.TxContract.Block.Info.(*script.ContractInfo).Tx, sc.TxData); err != nil {
+			return retError(err)
+		}
+	}
+
 	sc.TxContract.FreeRequest = false
 	for i := uint32(0); i < 4; i++ {
 		if (flags & (1 << i)) > 0 {
@@ -1089,50 +1306,33 @@ func (sc *SmartContract) CallContract(flags int) (string, error) {
 		}
 
 		commission := apl.Mul(decimal.New(syspar.SysInt64(`commission_size`), 0)).Div(decimal.New(100, 0)).Floor()
-		walletTable := model.KeyTableName(sc.TxSmart.TokenEcosystem)
-		historyTable := model.HistoryTableName(sc.TxSmart.TokenEcosystem)
 		comment := fmt.Sprintf("Commission for execution of %s contract", sc.TxContract.Name)
-		fromIDString := converter.Int64ToStr(fromID)
-
-		payCommission := func(toID string, sum decimal.Decimal) error {
-			_, _, err := sc.selectiveLoggingAndUpd(
-				[]string{"+amount"}, []interface{}{sum}, walletTable,
-				[]string{"id"}, []string{toID},
-				true, true,
-			)
-			if err != nil {
-				return err
-			}
 
-			_, _, err = sc.selectiveLoggingAndUpd(
-				[]string{"sender_id", "recipient_id", "amount", "comment", "block_id", "txhash"},
-				[]interface{}{fromIDString, toID, sum, comment, sc.BlockData.BlockID, sc.TxHash},
-				historyTable, nil, nil, true, false,
-			)
-			if err != nil {
-				return err
+		payCommission := func(toID int64, sum, part decimal.Decimal) error {
+			if toID == 0 {
+				return errUpdNotExistRecord
 			}
-
-			return nil
+			return sc.moveTokens(ledgerEntry{ecosystem: sc.TxSmart.TokenEcosystem, sender: fromID, recipient: toID,
+				amount: sum, commission: part, kind: model.HistoryFee, comment: comment, existing: true}, true)
 		}
 
-		if err := payCommission(converter.Int64ToStr(toID), apl.Sub(commission)); err != nil {
+		if err := payCommission(toID, apl.Sub(commission), commission); err != nil {
 			if err != errUpdNotExistRecord {
 				return retError(err)
 			}
-			apl = commission
 		}
 
-		if err := payCommission(syspar.GetCommissionWallet(sc.TxSmart.TokenEcosystem), commission); err != nil {
+		var walletID int64
+		if wallet := syspar.GetCommissionWallet(sc.TxSmart.TokenEcosystem); len(wallet) > 0 {
+			if walletID, ierr = converter.StrToInt64E(wallet); ierr != nil {
+				logger.WithFields(log.Fields{"type": consts.ConversionError, "error": ierr, "value": wallet}).Error("converting commission wallet to int")
+				return retError(ierr)
+			}
+		}
+		if err := payCommission(walletID, commission, decimal.Zero); err != nil {
 			if err != errUpdNotExistRecord {
 				return retError(err)
 			}
-			apl = apl.Sub(commission)
-		}
-
-		if _, _, ierr := sc.selectiveLoggingAndUpd([]string{`-amount`}, []interface{}{apl}, walletTable, []string{`id`},
-			[]string{fromIDString}, true, true); ierr != nil {
-			return retError(errCommission)
 		}
 		logger.WithFields(log.Fields{"commission": commission}).Debug("Paid commission")
 	}
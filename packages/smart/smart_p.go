@@ -20,8 +20,10 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
@@ -33,6 +35,7 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/script"
 	"github.com/GenesisKernel/go-genesis/packages/utils"
 	"github.com/GenesisKernel/go-genesis/packages/utils/metric"
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
 
 	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
@@ -40,11 +43,13 @@ import (
 
 var (
 	funcCallsDBP = map[string]struct{}{
-		"DBInsert":         {},
-		"DBUpdate":         {},
-		"DBUpdateSysParam": {},
-		"DBUpdateExt":      {},
-		"DBSelect":         {},
+		"DBInsert":           {},
+		"DBUpdate":           {},
+		"DBUpdateSysParam":   {},
+		"DBScheduleSysParam": {},
+		"DBUpdateExt":        {},
+		"DBSelect":           {},
+		"MarkDeleted":        {},
 	}
 
 	extendCostSysParams = map[string]string{
@@ -108,21 +113,218 @@ func init() {
 	EmbedFuncs(smartVM, script.VMTypeSmart)
 }
 
-func getCostP(name string) int64 {
-	if key, ok := extendCostSysParams[name]; ok && syspar.HasSys(key) {
-		return syspar.SysInt64(key)
+// ExtendCostParams returns the names of the system parameters with the costs of the functions
+func ExtendCostParams() []string {
+	ret := make([]string, 0, len(extendCostSysParams))
+	for _, key := range extendCostSysParams {
+		ret = append(ret, key)
 	}
-	return -1
+	return ret
+}
+
+func getCostP(name string, extend map[string]interface{}) int64 {
+	key, ok := extendCostSysParams[name]
+	if !ok {
+		return -1
+	}
+	cost := syspar.SysInt64Def(key, -1)
+	if sc, ok := extend[`sc`].(*SmartContract); ok && cost >= 0 {
+		return sc.sysParamInt(key, cost)
+	}
+	return cost
+}
+
+const (
+	overridePrefix = `override_`
+	// overrideRatio limits the override by the range from global/overrideRatio to global*overrideRatio
+	overrideRatio = 10
+)
+
+var (
+	// overridableParams returns the list of the system parameters which can be overridden by ecosystems,
+	// the item with * at the end is the prefix of the names
+	overridableParams = func() []string {
+		return syspar.SysStringList(syspar.EcosystemOverrides)
+	}
+	// getEcosystemParam returns the value of the parameter of the ecosystem
+	getEcosystemParam = func(transaction *model.DbTransaction, ecosystem int64, name string) (string, bool, error) {
+		sp := &model.StateParameter{}
+		sp.SetTablePrefix(converter.Int64ToStr(ecosystem))
+		found, err := sp.Get(transaction, name)
+		return sp.Value, found, err
+	}
+)
+
+func isOverridable(name string) bool {
+	for _, item := range overridableParams() {
+		if item == name || (strings.HasSuffix(item, `*`) && strings.HasPrefix(name, item[:len(item)-1])) {
+			return true
+		}
+	}
+	return false
+}
+
+// clampOverride returns the override which is limited by the bounds of the global value
+func clampOverride(value, global int64) int64 {
+	min, max := global/overrideRatio, global*overrideRatio
+	if global > math.MaxInt64/overrideRatio {
+		max = math.MaxInt64
+	}
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+// sysParamInt returns the global value of the system parameter or the override_<name> parameter
+// of the ecosystem if the system parameter is overridable. The ecosystem 1 uses the global values
+func (sc *SmartContract) sysParamInt(name string, global int64) int64 {
+	if sc == nil || sc.VDE || sc.TxSmart.EcosystemID <= 1 || global < 0 || !isOverridable(name) {
+		return global
+	}
+	if val, ok := sc.overrides[name]; ok {
+		return val
+	}
+	value, found, err := getEcosystemParam(sc.DbTransaction, sc.TxSmart.EcosystemID, overridePrefix+name)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "name": name}).Error("getting override of system parameter")
+		return global
+	}
+	val := global
+	if found {
+		override, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || override < 0 {
+			log.WithFields(log.Fields{"type": consts.InvalidObject, "name": name, "value": value,
+				"ecosystem": sc.TxSmart.EcosystemID}).Warning("invalid override of system parameter, using global value")
+		} else {
+			val = clampOverride(override, global)
+		}
+	}
+	if sc.overrides == nil {
+		sc.overrides = make(map[string]int64)
+	}
+	sc.overrides[name] = val
+	return val
 }
 
 // UpdateSysParam updates the system parameter
 func UpdateSysParam(sc *SmartContract, name, value, conditions string) (int64, error) {
+	return updateSysParam(sc, name, value, conditions, 0)
+}
+
+// ScheduleSysParam sets the value of the system parameter which becomes active at activationBlock,
+// the conditions are changed immediately. The previous pending value is replaced if it isn't active yet
+func ScheduleSysParam(sc *SmartContract, name, value, conditions string, activationBlock int64) (int64, error) {
+	if activationBlock <= 0 {
+		return updateSysParam(sc, name, value, conditions, 0)
+	}
+	if len(value) == 0 {
+		log.WithFields(log.Fields{"type": consts.EmptyObject, "name": name}).Error("empty scheduled value")
+		return 0, fmt.Errorf(`empty value of the scheduled parameter %s`, name)
+	}
+	return updateSysParam(sc, name, value, conditions, activationBlock)
+}
+
+// positiveInt returns true if the string is the integer greater than zero
+func positiveInt(s string) bool {
+	val, err := converter.StrToInt64E(s)
+	return err == nil && val > 0
+}
+
+// checkSysParam checks the value of the system parameter
+func checkSysParam(name, value string) error {
+	var (
+		ok, checked bool
+		list        [][]string
+	)
+	ival, ierr := converter.StrToInt64E(value)
+check:
+	switch name {
+	case `gap_between_blocks`:
+		ok = ival > 0 && ival < 86400
+	case `rb_blocks_1`, `number_of_nodes`:
+		ok = ival > 0 && ival < 1000
+	case `ecosystem_price`, `contract_price`, `column_price`, `table_price`, `menu_price`,
+		`page_price`, `commission_size`:
+		ok = ival >= 0
+	case `max_block_size`, `max_tx_size`, `max_tx_count`, `max_columns`, `max_indexes`,
+		`max_block_user_tx`, `max_fuel_tx`, `max_fuel_block`, `max_forsign_size`, `max_tx_memory`,
+		`max_call_depth`:
+		ok = ival > 0
+	case `fuel_rate`, `commission_wallet`:
+		err := json.Unmarshal([]byte(value), &list)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling system param")
+			return err
+		}
+		var first bool
+		for _, item := range list {
+			switch name {
+			case `fuel_rate`:
+				// the optional third column is the ecosystem of the tokens which pay the fee
+				if len(item) < 2 || len(item) > 3 || !positiveInt(item[0]) || !positiveInt(item[1]) ||
+					(len(item) == 3 && !positiveInt(item[2])) {
+					break check
+				}
+				// the rate of the ecosystem 1 is used for the ecosystems without the rate
+				first = first || item[0] == `1`
+			case `commission_wallet`:
+				if len(item) != 2 || !positiveInt(item[0]) {
+					break check
+				}
+				if wallet, err := converter.StrToInt64E(item[1]); err != nil || wallet == 0 {
+					break check
+				}
+			}
+		}
+		checked = name != `fuel_rate` || first
+	case syspar.FullNodes:
+		fnodes := []syspar.FullNode{}
+		if err := json.Unmarshal([]byte(value), &fnodes); err != nil {
+			break check
+		}
+		keys := make(map[int64]bool, len(fnodes))
+		for _, node := range fnodes {
+			if keys[node.KeyID] {
+				break check
+			}
+			keys[node.KeyID] = true
+		}
+		checked = len(fnodes) > 0
+	case syspar.StrictTxReplay, syspar.DisableForSignV1, syspar.FormatContracts:
+		checked = value == `true` || value == `false`
+	default:
+		if strings.HasPrefix(name, `extend_cost_`) {
+			ok = ival >= 0
+			break
+		}
+		checked = true
+	}
+	if !checked && (!ok || ierr != nil || converter.Int64ToStr(ival) != value) {
+		log.WithFields(log.Fields{"type": consts.InvalidObject, "value": value, "name": name}).Error(ErrInvalidValue.Error())
+		return ErrInvalidValue
+	}
+	return nil
+}
+
+func updateSysParam(sc *SmartContract, name, value, conditions string, activationBlock int64) (int64, error) {
 	var (
 		fields []string
 		values []interface{}
+		block  int64
 	)
+	if sc.BlockData != nil {
+		block = sc.BlockData.BlockID
+	}
+	if activationBlock > 0 && activationBlock <= block {
+		log.WithFields(log.Fields{"type": consts.InvalidObject, "activation_block": activationBlock, "block_id": block}).Error("activation block is not in the future")
+		return 0, fmt.Errorf(`activation block %d must be greater than the current block %d`, activationBlock, block)
+	}
 	par := &model.SystemParameter{}
-	found, err := par.Get(name)
+	found, err := par.GetTransaction(sc.DbTransaction, name)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("system parameter get")
 		return 0, err
@@ -144,59 +346,26 @@ func UpdateSysParam(sc *SmartContract, name, value, conditions string) (int64, e
 		}
 	}
 	if len(value) > 0 {
-		var (
-			ok, checked bool
-			list        [][]string
-		)
-		ival := converter.StrToInt64(value)
-	check:
-		switch name {
-		case `gap_between_blocks`:
-			ok = ival > 0 && ival < 86400
-		case `rb_blocks_1`, `number_of_nodes`:
-			ok = ival > 0 && ival < 1000
-		case `ecosystem_price`, `contract_price`, `column_price`, `table_price`, `menu_price`,
-			`page_price`, `commission_size`:
-			ok = ival >= 0
-		case `max_block_size`, `max_tx_size`, `max_tx_count`, `max_columns`, `max_indexes`,
-			`max_block_user_tx`, `max_fuel_tx`, `max_fuel_block`, `max_forsign_size`:
-			ok = ival > 0
-		case `fuel_rate`, `commission_wallet`:
-			err := json.Unmarshal([]byte(value), &list)
-			if err != nil {
-				log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling system param")
-				return 0, err
-			}
-			for _, item := range list {
-				switch name {
-				case `fuel_rate`, `commission_wallet`:
-					if len(item) != 2 || converter.StrToInt64(item[0]) <= 0 ||
-						(name == `fuel_rate` && converter.StrToInt64(item[1]) <= 0) ||
-						(name == `commission_wallet` && converter.StrToInt64(item[1]) == 0) {
-						break check
-					}
-				}
-			}
-			checked = true
-		case syspar.FullNodes:
-			fnodes := []syspar.FullNode{}
-			if err := json.Unmarshal([]byte(value), &fnodes); err != nil {
-				break check
+		if err := checkSysParam(name, value); err != nil {
+			return 0, err
+		}
+		// the pending value which is already active becomes the stored value
+		active := par.ActivationBlock > 0 && par.ActivationBlock <= block
+		if activationBlock > 0 {
+			if active {
+				fields = append(fields, "value")
+				values = append(values, par.PendingValue)
 			}
-			checked = len(fnodes) > 0
-		default:
-			if strings.HasPrefix(name, `extend_cost_`) {
-				ok = ival >= 0
-				break
+			fields = append(fields, "pending_value", "activation_block")
+			values = append(values, value, activationBlock)
+		} else {
+			fields = append(fields, "value")
+			values = append(values, value)
+			if active {
+				fields = append(fields, "pending_value", "activation_block")
+				values = append(values, "", 0)
 			}
-			checked = true
-		}
-		if !checked && (!ok || converter.Int64ToStr(ival) != value) {
-			log.WithFields(log.Fields{"type": consts.InvalidObject, "value": value, "name": name}).Error(ErrInvalidValue.Error())
-			return 0, ErrInvalidValue
 		}
-		fields = append(fields, "value")
-		values = append(values, value)
 	}
 	if len(conditions) > 0 {
 		if err := CompileEval(conditions, 0); err != nil {
@@ -247,16 +416,21 @@ func SysParamString(name string) string {
 	return syspar.SysString(name)
 }
 
-// SysParamInt returns the value of the system parameter
-func SysParamInt(name string) int64 {
-	return syspar.SysInt64(name)
+// SysParamInt returns the value of the system parameter, it can be overridden by the ecosystem
+func SysParamInt(sc *SmartContract, name string) int64 {
+	return sc.sysParamInt(name, syspar.SysInt64(name))
 }
 
-// SysFuel returns the fuel rate
+// SysFuel returns the fuel rate, the rate of the ecosystem 1 is returned if the ecosystem has no rate
 func SysFuel(state int64) string {
 	return syspar.GetFuelRate(state)
 }
 
+// HasFuelRate returns true if the ecosystem has its own fuel rate
+func HasFuelRate(state int64) bool {
+	return syspar.HasFuelRate(state)
+}
+
 // Int converts the value to a number
 func Int(v interface{}) (int64, error) {
 	return converter.ValueToInt(v)
@@ -382,7 +556,7 @@ func GetContractByName(sc *SmartContract, name string) int64 {
 // GetContractById returns the name of the contract with this id
 func GetContractById(sc *SmartContract, id int64) string {
 	_, ret, err := DBSelect(sc, "contracts", "value", id, `id`, 0, 1,
-		0, ``, []interface{}{})
+		0, false, ``, []interface{}{})
 	if err != nil || len(ret) != 1 {
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting contract name")
 		return ``
@@ -442,7 +616,13 @@ func CreateEcosystem(sc *SmartContract, wallet int64, name string) (int64, error
 		return 0, err
 	}
 
-	if err = model.ExecSchemaEcosystem(sc.DbTransaction, int(id), wallet, name, converter.StrToInt64(sp.Value)); err != nil {
+	founder, err := converter.StrToInt64E(sp.Value)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("converting founder account")
+		return 0, ErrFounderAccount
+	}
+
+	if err = model.ExecSchemaEcosystem(sc.DbTransaction, int(id), wallet, name, founder); err != nil {
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("executing ecosystem schema")
 		return 0, err
 	}
@@ -452,6 +632,7 @@ func CreateEcosystem(sc *SmartContract, wallet int64, name string) (int64, error
 		return 0, err
 	}
 
+	rollback := sc.Rollback
 	sc.Rollback = false
 	sc.FullAccess = true
 	if _, _, err = DBInsert(sc, `@`+idStr+"_pages", "id,name,value,menu,conditions", "1", "default_page",
@@ -469,7 +650,7 @@ func CreateEcosystem(sc *SmartContract, wallet int64, name string) (int64, error
 		ret []interface{}
 		pub string
 	)
-	_, ret, err = DBSelect(sc, "@1_keys", "pub", wallet, `id`, 0, 1, 0, ``, []interface{}{})
+	_, ret, err = DBSelect(sc, "@1_keys", "pub", wallet, `id`, 0, 1, 0, false, ``, []interface{}{})
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting pub key")
 		return 0, err
@@ -486,13 +667,13 @@ func CreateEcosystem(sc *SmartContract, wallet int64, name string) (int64, error
 	sc.FullAccess = false
 	// because of we need to know which ecosystem to rollback.
 	// All tables will be deleted so it's no need to rollback data from tables
-	sc.Rollback = true
+	sc.Rollback = rollback
 	if _, _, err := DBInsert(sc, "@1_ecosystems", "id,name", id, name); err != nil {
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("insert new ecosystem to stat table")
 		return 0, err
 	}
 	if !sc.VDE {
-		if err := SysRollback(sc, `{"Type": "NewEcosystem"}`); err != nil {
+		if err := SysRollback(sc, map[string]string{"Type": "NewEcosystem"}); err != nil {
 			return 0, err
 		}
 	}
@@ -510,6 +691,28 @@ func EditEcosysName(sc *SmartContract, sysID int64, newName string) error {
 	return err
 }
 
+// EditEcosysTokenDecimals sets the number of the decimals of the token of the current ecosystem.
+// The decimals can be changed only by the founder, the platform token always has EGS_DIGIT decimals
+func EditEcosysTokenDecimals(sc *SmartContract, decimals int64) error {
+	if sc.TxContract.Name != `@1EditEcosystemTokenDecimals` {
+		log.WithFields(log.Fields{"type": consts.IncorrectCallingContract}).Error("EditEcosysTokenDecimals can be only called from @1EditEcosystemTokenDecimals")
+		return fmt.Errorf(`EditEcosysTokenDecimals can be only called from @1EditEcosystemTokenDecimals`)
+	}
+	if sc.VDE || sc.TxSmart.EcosystemID <= 1 || !sc.isFounder() {
+		log.WithFields(log.Fields{"type": consts.AccessDenied, "ecosystem": sc.TxSmart.EcosystemID}).Error("changing token decimals")
+		return errAccessDenied
+	}
+	if decimals < 0 || decimals > consts.MaxTokenDecimals {
+		log.WithFields(log.Fields{"type": consts.InvalidObject, "value": decimals}).Error("wrong token decimals")
+		return ErrInvalidValue
+	}
+	// the founder of the ecosystem hasn't the access to the table of the ecosystems
+	sc.FullAccess = true
+	defer func() { sc.FullAccess = false }()
+	_, err := DBUpdate(sc, "@1_ecosystems", sc.TxSmart.EcosystemID, "token_decimals", decimals)
+	return err
+}
+
 // Size returns the length of the string
 func Size(s string) int64 {
 	return int64(len(s))
@@ -535,8 +738,8 @@ func Activate(sc *SmartContract, tblid int64, state int64) error {
 	}
 	ActivateContract(tblid, state, true)
 	if !sc.VDE {
-		if err := SysRollback(sc, fmt.Sprintf(`{"Type": "ActivateContract", "Id": "%d", "State": "%d"}`,
-			tblid, state)); err != nil {
+		if err := SysRollback(sc, map[string]string{"Type": "ActivateContract",
+			"Id": strconv.FormatInt(tblid, 10), "State": strconv.FormatInt(state, 10)}); err != nil {
 			return err
 		}
 	}
@@ -551,8 +754,8 @@ func Deactivate(sc *SmartContract, tblid int64, state int64) error {
 	}
 	ActivateContract(tblid, state, false)
 	if !sc.VDE {
-		if err := SysRollback(sc, fmt.Sprintf(`{"Type": "DeactivateContract", "Id": "%d", "State": "%d"}`,
-			tblid, state)); err != nil {
+		if err := SysRollback(sc, map[string]string{"Type": "DeactivateContract",
+			"Id": strconv.FormatInt(tblid, 10), "State": strconv.FormatInt(state, 10)}); err != nil {
 			return err
 		}
 	}
@@ -561,7 +764,10 @@ func Deactivate(sc *SmartContract, tblid int64, state int64) error {
 
 // CheckSignature checks the additional signatures for the contract
 func CheckSignature(i *map[string]interface{}, name string) error {
-	state, name := script.ParseContract(name)
+	state, name, err := script.ParseContractStrict(name)
+	if err != nil {
+		return err
+	}
 	pref := converter.Int64ToStr(int64(state))
 	sc := (*i)[`sc`].(*SmartContract)
 	value, err := model.Single(`select value from "`+pref+`_signatures" where name=?`, name).String()
@@ -585,14 +791,17 @@ func CheckSignature(i *map[string]interface{}, name string) error {
 		return err
 	}
 	wallet := (*i)[`key_id`].(int64)
-	forsign := fmt.Sprintf(`%d,%d`, uint64((*i)[`time`].(int64)), uint64(wallet))
-	for _, isign := range sign.Params {
+	names := make([]string, len(sign.Params))
+	values := make([]string, len(sign.Params))
+	for j, isign := range sign.Params {
 		val := (*i)[isign.Param]
 		if val == nil {
 			val = ``
 		}
-		forsign += fmt.Sprintf(`,%v`, val)
+		names[j], values[j] = isign.Param, fmt.Sprint(val)
 	}
+	forsign := tx.ParamsForSign(sc.TxSmart.SignVersion, fmt.Sprint(uint64((*i)[`time`].(int64))),
+		wallet, names, values)
 
 	CheckSignResult, err := utils.CheckSign(sc.PublicKeys, forsign, hexsign, true)
 	if err != nil {
@@ -623,7 +832,8 @@ func RollbackContract(sc *SmartContract, name string) error {
 	return nil
 }
 
-// DBSelectMetrics returns list of metrics by name and time interval
+// DBSelectMetrics returns list of metrics by name and time interval. The rate aggregate returns
+// the growth of the metric in each time bucket
 func DBSelectMetrics(sc *SmartContract, metric, timeInterval, aggregateFunc string) ([]interface{}, error) {
 	result, err := model.GetMetricValues(metric, timeInterval, aggregateFunc)
 	if err != nil {
@@ -640,6 +850,7 @@ func DBCollectMetrics() []interface{} {
 		metric.CollectMetricDataForEcosystemTables,
 		metric.CollectMetricDataForEcosystemTx,
 	)
+	c.SetPrevious(metric.PreviousValue)
 	return c.Values()
 }
 
@@ -17,11 +17,21 @@
 package smart
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
 	"github.com/GenesisKernel/go-genesis/packages/script"
+	"github.com/GenesisKernel/go-genesis/packages/utils"
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
 )
 
 type TestSmart struct {
@@ -93,3 +103,292 @@ func TestCheckAppend(t *testing.T) {
 	_, err := Run(cfunc, nil, &map[string]interface{}{})
 	require.NoError(t, err)
 }
+
+func TestMapDeterminism(t *testing.T) {
+	contracts := `contract MapKeys {
+		func keys() string {
+			var m map
+			m = {"zeta": 1, "alpha": 2, "kappa": 3, "beta": 4, "omega": 5, "delta": 6,
+				"gamma": 7, "sigma": 8, "theta": 9, "iota": 10, "lambda": 11, "mu": 12}
+			return Join(GetMapKeys(m), ",")
+		}
+		action {
+			MapUsedZ()
+			MapUsedA()
+			MapUsedM()
+			MapUsedB()
+			MapUsedY()
+		}
+	}`
+
+	owner := script.OwnerInfo{
+		StateID:  1,
+		Active:   false,
+		TableID:  1,
+		WalletID: 0,
+		TokenID:  0,
+	}
+	require.NoError(t, Compile(contracts, &owner))
+
+	cfunc := GetContract("MapKeys", 1).GetFunc("keys")
+	// the order of the map iteration is random in Go, so the result is checked many times
+	for i := 0; i < 20; i++ {
+		ret, err := Run(cfunc, nil, &map[string]interface{}{})
+		require.NoError(t, err)
+		require.Equal(t, "alpha,beta,delta,gamma,iota,kappa,lambda,mu,omega,sigma,theta,zeta", ret[0])
+
+		require.Equal(t, []string{"@1MapUsedA", "@1MapUsedB", "@1MapUsedM", "@1MapUsedY", "@1MapUsedZ"},
+			GetUsedContracts("MapKeys", 1, false))
+	}
+}
+
+func TestContractConsts(t *testing.T) {
+	source := `contract ConstTest {
+		const {
+			Limit = %d
+			Fee money = "100"
+		}
+		func limit() string {
+			return Sprintf("%%d %%v", Limit, Fee)
+		}
+	}`
+
+	owner := script.OwnerInfo{
+		StateID:  1,
+		Active:   false,
+		TableID:  1,
+		WalletID: 0,
+		TokenID:  0,
+	}
+	for _, limit := range []int{10, 20} {
+		root, err := VMCompileBlock(smartVM, fmt.Sprintf(source, limit), &owner)
+		require.NoError(t, err)
+		VMFlushBlock(smartVM, root)
+
+		ret, err := Run(GetContract("ConstTest", 1).GetFunc("limit"), nil, &map[string]interface{}{})
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("%d 100", limit), ret[0])
+	}
+}
+
+func TestPrivateFunc(t *testing.T) {
+	source := `contract PrivateTest {
+		private func helper() string {
+			return "secret"
+		}
+		action {
+			$result = helper()
+		}
+	}`
+
+	owner := script.OwnerInfo{
+		StateID:  1,
+		Active:   false,
+		TableID:  1,
+		WalletID: 0,
+		TokenID:  0,
+	}
+	require.NoError(t, Compile(source, &owner))
+
+	contract := GetContract("PrivateTest", 1)
+	require.Nil(t, contract.GetFunc("helper"))
+
+	extend := map[string]interface{}{}
+	_, err := Run(contract.GetFunc("action"), nil, &extend)
+	require.NoError(t, err)
+	require.Equal(t, "secret", extend["result"])
+}
+
+const cacheSource = `contract Cached%d {
+	data {
+		Name string
+		Amount money "optional"
+	}
+	conditions {
+		if Size($Name) == 0 {
+			error "empty name"
+		}
+	}
+	action {
+		var list array
+		var i int
+		while i < 3 {
+			list[i] = Sprintf("%%s-%%d", $Name, i)
+			i = i + 1
+		}
+		$result = Join(list, ",")
+	}
+}`
+
+func loadCached(vm *script.VM, count int, cache *contractCache) error {
+	for i := 0; i < count; i++ {
+		owner := script.OwnerInfo{StateID: 1, TableID: int64(i + 1)}
+		if err := cache.compile(vm, fmt.Sprintf(cacheSource, i), &owner); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestContractCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(dataDir string) { conf.Config.DataDir = dataDir }(conf.Config.DataDir)
+	conf.Config.DataDir = dir
+	cacheDir := filepath.Join(dir, consts.ContractsCacheDirname)
+
+	vm := newVM()
+	EmbedFuncs(vm, script.VMTypeSmart)
+	cache := newContractCache(vm, `contracts`)
+	require.NotNil(t, cache)
+	require.NoError(t, loadCached(vm, 3, cache))
+	files, err := ioutil.ReadDir(cacheDir)
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+
+	stale := filepath.Join(cacheDir, `contracts-stale`)
+	require.NoError(t, ioutil.WriteFile(stale, []byte(`stale`), 0644))
+	// the corrupted byte-code must be recompiled
+	require.NoError(t, ioutil.WriteFile(filepath.Join(cacheDir, files[0].Name()), []byte(`GBC`), 0644))
+
+	vm = newVM()
+	EmbedFuncs(vm, script.VMTypeSmart)
+	cache = newContractCache(vm, `contracts`)
+	require.NoError(t, loadCached(vm, 3, cache))
+	cache.prune()
+	_, err = os.Stat(stale)
+	require.True(t, os.IsNotExist(err))
+
+	for i := 0; i < 3; i++ {
+		obj := vm.Objects[fmt.Sprintf("@1Cached%d", i)]
+		require.NotNil(t, obj)
+		contract := &Contract{Name: fmt.Sprintf("@1Cached%d", i), Block: obj.Value.(*script.Block)}
+		extend := map[string]interface{}{`Name`: `name`}
+		_, err = VMRun(vm, contract.GetFunc(`action`), nil, &extend)
+		require.NoError(t, err)
+		require.Equal(t, `name-0,name-1,name-2`, extend[`result`])
+	}
+}
+
+func TestScheduleSysParam(t *testing.T) {
+	sc := &SmartContract{BlockData: &utils.BlockData{BlockID: 10}}
+	_, err := ScheduleSysParam(sc, `max_block_size`, ``, ``, 20)
+	require.Error(t, err)
+	for _, block := range []int64{5, 10} {
+		_, err = ScheduleSysParam(sc, `max_block_size`, `1000`, ``, block)
+		require.EqualError(t, err, fmt.Sprintf(`activation block %d must be greater than the current block 10`, block))
+	}
+}
+
+func TestCheckSysParam(t *testing.T) {
+	for value, valid := range map[string]bool{
+		`[["1","100"]]`:                true,
+		`[["1","100"],["2","50","1"]]`: true,
+		`[["1","100"],["2","50"]]`:     true,
+		`[["2","50"]]`:                 false,
+		`[["1","0"]]`:                  false,
+		`[["1","100"],["2","50","0"]]`: false,
+		`[["1","100","1","2"]]`:        false,
+		`[["1"]]`:                      false,
+	} {
+		assert.Equal(t, valid, checkSysParam(`fuel_rate`, value) == nil, value)
+	}
+	assert.NoError(t, checkSysParam(`commission_wallet`, `[["1","100"]]`))
+	assert.Error(t, checkSysParam(`commission_wallet`, `[["1","100","1"]]`))
+}
+
+func TestCheckTxNonce(t *testing.T) {
+	defer func(get func(*model.DbTransaction, int64) (int64, error)) { getTxNonce = get }(getTxNonce)
+	var reads int
+	getTxNonce = func(_ *model.DbTransaction, keyID int64) (int64, error) {
+		reads++
+		return 10, nil
+	}
+	sc := &SmartContract{}
+	// the transactions without the nonce aren't checked
+	assert.NoError(t, sc.checkTxNonce(1))
+	assert.Equal(t, 0, reads)
+	for _, nonce := range []int64{-1, 5, 10} {
+		sc.TxSmart.Nonce = nonce
+		assert.Equal(t, ErrTxNonce, sc.checkTxNonce(1), nonce)
+	}
+	assert.Equal(t, 3, reads)
+}
+
+func TestEcosystemOverrides(t *testing.T) {
+	defer func(list func() []string) { overridableParams = list }(overridableParams)
+	defer func(get func(*model.DbTransaction, int64, string) (string, bool, error)) {
+		getEcosystemParam = get
+	}(getEcosystemParam)
+	overridableParams = func() []string { return []string{`table_price`, `extend_cost_*`} }
+	params := map[string]string{
+		`override_table_price`:        `300`,
+		`override_menu_price`:         `1`,
+		`override_extend_cost_len`:    `1000`,
+		`override_extend_cost_join`:   `0`,
+		`override_extend_cost_eval`:   `wrong`,
+		`override_extend_cost_size`:   `-5`,
+		`override_extend_cost_sha256`: `60`,
+	}
+	var reads int
+	getEcosystemParam = func(_ *model.DbTransaction, _ int64, name string) (string, bool, error) {
+		reads++
+		value, ok := params[name]
+		return value, ok, nil
+	}
+
+	sc := &SmartContract{TxSmart: tx.SmartContract{Header: tx.Header{EcosystemID: 2}}}
+	for _, item := range []struct {
+		name           string
+		global, result int64
+	}{
+		{`table_price`, 200, 300},
+		// menu_price isn't in the list of the overridable parameters
+		{`menu_price`, 100, 100},
+		// the override is clamped by global/10 and global*10
+		{`extend_cost_len`, 5, 50},
+		{`extend_cost_join`, 10, 1},
+		{`extend_cost_eval`, 10, 10},
+		{`extend_cost_size`, 10, 10},
+		{`extend_cost_sha256`, 50, 60},
+		{`extend_cost_contains`, 10, 10},
+	} {
+		assert.Equal(t, item.result, sc.sysParamInt(item.name, item.global), item.name)
+	}
+	// the values are read once in the contract
+	count := reads
+	assert.Equal(t, int64(300), sc.sysParamInt(`table_price`, 200))
+	assert.Equal(t, count, reads)
+
+	// the ecosystem 1 uses the global values
+	sc = &SmartContract{TxSmart: tx.SmartContract{Header: tx.Header{EcosystemID: 1}}}
+	assert.Equal(t, int64(200), sc.sysParamInt(`table_price`, 200))
+	assert.Equal(t, count, reads)
+}
+
+// BenchmarkLoadContracts compares the loading of contracts with the compilation and from the cache
+func BenchmarkLoadContracts(b *testing.B) {
+	dir, err := ioutil.TempDir("", "cache")
+	require.NoError(b, err)
+	defer os.RemoveAll(dir)
+	defer func(dataDir string) { conf.Config.DataDir = dataDir }(conf.Config.DataDir)
+	conf.Config.DataDir = dir
+
+	const count = 1000
+	load := func(b *testing.B, useCache bool) {
+		for i := 0; i < b.N; i++ {
+			vm := newVM()
+			EmbedFuncs(vm, script.VMTypeSmart)
+			var cache *contractCache
+			if useCache {
+				cache = newContractCache(vm, `contracts`)
+			}
+			require.NoError(b, loadCached(vm, count, cache))
+		}
+	}
+	b.Run("compile", func(b *testing.B) { load(b, false) })
+	// the first loading fills the cache
+	load(b, true)
+	b.Run("cache", func(b *testing.B) { load(b, true) })
+}
@@ -0,0 +1,176 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smarttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
+	"github.com/GenesisKernel/go-genesis/packages/utils"
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
+
+	"github.com/shopspring/decimal"
+)
+
+// Params are the values of the data fields of the contract. The strings are converted
+// to the types of the fields as the values of the API forms
+type Params map[string]interface{}
+
+// Result is the result of the contract execution
+type Result struct {
+	Value   string          // the result of the contract
+	Fuel    int64           // the used fuel
+	Err     error           // the error of the contract
+	Message *script.VMError // the decoded error, the warnings and the info messages are errors too
+}
+
+// Call executes the contract by the default key of the environment
+func (env *Env) Call(name string, params Params) *Result {
+	return env.CallBy(env.KeyID, name, params)
+}
+
+// CallBy executes the contract by the key. The changes of the failed contract are rolled back
+// as the block does it, the signature of the key isn't checked
+func (env *Env) CallBy(keyID int64, name string, params Params) *Result {
+	contract := smart.VMGetContract(env.VM, name, Ecosystem)
+	if contract == nil {
+		env.t.Fatalf("contract %s has not been found", name)
+	}
+	data, err := fieldValues(contract, params)
+	if err != nil {
+		env.t.Fatalf("contract %s: %s", name, err)
+	}
+	hash, err := crypto.Hash([]byte(fmt.Sprintf("%s %d %d", name, keyID, time.Now().UnixNano())))
+	if err != nil {
+		env.t.Fatalf("getting hash: %s", err)
+	}
+	now := time.Now().Unix()
+	sc := smart.SmartContract{
+		VM: env.VM,
+		TxSmart: tx.SmartContract{
+			Header: tx.Header{
+				Type:        int(contract.Block.Info.(*script.ContractInfo).ID),
+				Time:        now,
+				EcosystemID: Ecosystem,
+				KeyID:       keyID,
+			},
+		},
+		TxData:        data,
+		TxContract:    contract,
+		TxHash:        hash,
+		BlockData:     &utils.BlockData{BlockID: env.BlockID, Time: now, KeyID: env.BlockKeyID},
+		VerifiedKey:   env.publicKey(keyID),
+		DbTransaction: env.tx,
+	}
+
+	env.savepoint++
+	if err = env.tx.Savepoint(env.savepoint); err != nil {
+		env.t.Fatalf("creating savepoint: %s", err)
+	}
+	result := &Result{}
+	result.Value, result.Err = sc.CallContract(smart.CallInit | smart.CallCondition | smart.CallAction)
+	result.Fuel = sc.TxFuel
+	if result.Err != nil {
+		result.Message = &script.VMError{}
+		if json.Unmarshal([]byte(result.Err.Error()), result.Message) != nil {
+			result.Message = &script.VMError{Type: `panic`, Error: result.Err.Error()}
+		}
+		err = env.tx.RollbackSavepoint(env.savepoint)
+	} else {
+		err = env.tx.ReleaseSavepoint(env.savepoint)
+	}
+	if err != nil {
+		env.t.Fatalf("closing savepoint: %s", err)
+	}
+	return result
+}
+
+// MustCall executes the contract and fails the test if the contract returns the error
+func (env *Env) MustCall(name string, params Params) *Result {
+	result := env.Call(name, params)
+	if result.Err != nil {
+		env.t.Fatalf("contract %s: %s", name, result.Err)
+	}
+	return result
+}
+
+// fieldValues converts the string parameters to the types of the data fields of the contract
+func fieldValues(contract *smart.Contract, params Params) (map[string]interface{}, error) {
+	data := make(map[string]interface{})
+	for key, val := range params {
+		data[key] = val
+	}
+	info := contract.Block.Info.(*script.ContractInfo)
+	if info.Tx == nil {
+		return data, nil
+	}
+	for _, field := range *info.Tx {
+		val, ok := data[field.Name]
+		if !ok {
+			data[field.Name] = fieldDefault(field)
+			continue
+		}
+		s, ok := val.(string)
+		if !ok {
+			if i, ok := val.(int); ok {
+				data[field.Name] = int64(i)
+			}
+			continue
+		}
+		var err error
+		switch field.Type.String() {
+		case `int64`:
+			data[field.Name], err = strconv.ParseInt(s, 10, 64)
+		case `float64`:
+			data[field.Name], err = strconv.ParseFloat(s, 64)
+		case `bool`:
+			data[field.Name], err = strconv.ParseBool(s)
+		case script.Decimal:
+			data[field.Name], err = decimal.NewFromString(s)
+		case `[]uint8`:
+			data[field.Name] = []byte(s)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %s", field.Name, err)
+		}
+	}
+	return data, nil
+}
+
+// fieldDefault returns the empty value of the type of the field
+func fieldDefault(field *script.FieldInfo) interface{} {
+	switch field.Type.String() {
+	case `int64`:
+		return int64(0)
+	case `float64`:
+		return float64(0)
+	case `bool`:
+		return false
+	case script.Decimal:
+		return decimal.New(0, 0)
+	case `[]uint8`:
+		return []byte{}
+	case `[]interface {}`:
+		return []interface{}{}
+	}
+	return ``
+}
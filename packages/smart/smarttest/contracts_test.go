@@ -0,0 +1,272 @@
+package smarttest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTryCallContract(t *testing.T) {
+	t.Parallel()
+	env := New(t, `contract TestFail {
+		action {
+			error "delayed failure"
+		}
+	}`, `contract TestTry {
+		action {
+			var params, ret map
+			ret = TryCallContract("@1TestFail", params)
+			$result = ret["error"]
+		}
+	}`)
+	defer env.Close()
+
+	result := env.MustCall("TestTry", nil)
+	assert.Contains(t, result.Value, `delayed failure`)
+	assert.True(t, result.Fuel > 0)
+}
+
+func TestJSON(t *testing.T) {
+	t.Parallel()
+	env := New(t, `contract TestJSONEncode {
+		action {
+			var a array, m map
+			m["k1"] = 1
+			m["k2"] = 2
+			a[0] = m
+			a[1] = m
+
+			info JSONEncode(a)
+		}
+	}`, `contract TestJSONDecode {
+		data {
+			Input string
+		}
+		action {
+			info Sprintf("%#v", JSONDecode($Input))
+		}
+	}`)
+	defer env.Close()
+
+	result := env.Call("TestJSONEncode", nil)
+	require.NotNil(t, result.Message)
+	assert.Equal(t, `info`, result.Message.Type)
+	assert.Equal(t, `[{"k1":1,"k2":2},{"k1":1,"k2":2}]`, result.Message.Error)
+
+	cases := []struct {
+		source string
+		kind   string
+		result string
+	}{
+		{`"test"`, `info`, `"test"`},
+		{`["test"]`, `info`, `[]interface {}{"test"}`},
+		{`{"test":1}`, `info`, `map[string]interface {}{"test":1}`},
+		{`[{"test":1}]`, `info`, `[]interface {}{map[string]interface {}{"test":1}}`},
+		{`{"test":1`, `panic`, `unexpected end of JSON input`},
+	}
+	for _, v := range cases {
+		result = env.Call("TestJSONDecode", Params{"Input": v.source})
+		if assert.NotNil(t, result.Message, v.source) {
+			assert.Equal(t, v.kind, result.Message.Type, v.source)
+			assert.Equal(t, v.result, result.Message.Error, v.source)
+		}
+	}
+}
+
+func TestStack(t *testing.T) {
+	t.Parallel()
+	env := New(t, `contract TestChild {
+		action {
+			$result = $stack
+		}
+	}`, `contract TestParent {
+		action {
+			var arr array
+			arr[0] = $stack
+			arr[1] = TestChild()
+			$result = arr
+		}
+	}`)
+	defer env.Close()
+
+	result := env.MustCall("TestParent", nil)
+	assert.Equal(t, "[[@1TestParent] [@1TestParent @1TestChild]]", result.Value)
+}
+
+func TestRows(t *testing.T) {
+	t.Parallel()
+	env := New(t)
+	defer env.Close()
+
+	params := Params{"Name": "smarttest_param", "Value": "test value", "Conditions": "true"}
+	env.MustCall("NewParameter", params)
+	rows := env.Rows("parameters", "name = ?", "smarttest_param")
+	require.Len(t, rows, 1)
+	assert.Equal(t, "test value", rows[0]["value"])
+
+	result := env.Call("NewParameter", params)
+	require.NotNil(t, result.Message)
+	assert.Equal(t, "warning", result.Message.Type)
+	assert.Equal(t, "Parameter smarttest_param already exists", result.Message.Error)
+	assert.Len(t, env.Rows("parameters", "name = ?", "smarttest_param"), 1)
+
+	key := env.NewKey("100")
+	assert.Equal(t, "100", env.Row("keys", key)["amount"])
+}
+
+func TestUpdateMenuItem(t *testing.T) {
+	t.Parallel()
+	env := New(t, `contract TestGetMenu {
+		data {
+			Name string
+		}
+		action {
+			var menu map
+			menu = GetMenu($Name)
+			$result = menu["value"]
+		}
+	}`, `contract TestMenuItem {
+		data {
+			Menu string
+			Title string
+			Page string
+		}
+		action {
+			UpdateMenuItem($Menu, $Title, $Page, "icon-test")
+		}
+	}`)
+	defer env.Close()
+
+	name := "smarttest_menu"
+	env.MustCall("NewMenu", Params{"Name": name, "Value": "MenuGroup(Group){\n\tMenuItem(Title:\"Old\", Page:test_page)\n}",
+		"Title": "Test", "Conditions": "true"})
+
+	params := Params{"Menu": name, "Title": "New", "Page": "test_page"}
+	env.MustCall("TestMenuItem", params)
+	value := "MenuGroup(Group){\n\tMenuItem(Title:\"New\", Page:test_page, Icon:\"icon-test\")\n}"
+	assert.Equal(t, value, env.MustCall("TestGetMenu", Params{"Name": name}).Value)
+
+	env.MustCall("TestMenuItem", params)
+	assert.Equal(t, value, env.MustCall("TestGetMenu", Params{"Name": name}).Value)
+
+	result := env.Call("TestGetMenu", Params{"Name": "smarttest_unknown"})
+	require.NotNil(t, result.Message)
+	assert.Contains(t, result.Message.Error, "Menu smarttest_unknown has not been found")
+}
+
+func TestAlterColumnType(t *testing.T) {
+	t.Parallel()
+	env := New(t, `contract TestFillAlter {
+		action {
+			DBInsert("smarttest_alter", "amount,info", " 12", "{}")
+			DBInsert("smarttest_alter", "amount,info", "7", "[1, 2]")
+		}
+	}`, `contract TestAlter {
+		data {
+			Column string
+			Type string
+		}
+		action {
+			AlterColumnType("smarttest_alter", $Column, $Type)
+		}
+	}`)
+	defer env.Close()
+
+	env.MustCall("NewTable", Params{"Name": "smarttest_alter", "ApplicationId": "1",
+		"Columns":     `[{"name":"amount","type":"text","conditions":"true"},{"name":"info","type":"text","conditions":"true"}]`,
+		"Permissions": `{"insert": "true", "update": "true", "new_column": "true"}`})
+	env.MustCall("TestFillAlter", nil)
+
+	env.MustCall("TestAlter", Params{"Column": "amount", "Type": "number"})
+	rows := env.Rows("smarttest_alter", "")
+	require.Len(t, rows, 2)
+	assert.Equal(t, "12", rows[0]["amount"])
+	assert.Equal(t, "7", rows[1]["amount"])
+
+	env.MustCall("TestAlter", Params{"Column": "info", "Type": "json"})
+	assert.Equal(t, "[1, 2]", env.Rows("smarttest_alter", "")[1]["info"])
+
+	result := env.Call("TestAlter", Params{"Column": "info", "Type": "number"})
+	require.NotNil(t, result.Message)
+	assert.Contains(t, result.Message.Error, "cannot be changed from json to number")
+
+	env.MustCall("TestFillAlter", nil)
+	result = env.Call("TestAlter", Params{"Column": "amount", "Type": "varchar"})
+	require.NotNil(t, result.Message)
+	assert.Contains(t, result.Message.Error, "cannot be changed from number to varchar")
+}
+
+func TestNullValues(t *testing.T) {
+	t.Parallel()
+	env := New(t, `contract TestInsertNulls {
+		action {
+			DBInsert("smarttest_nulls", "info", "inserted")
+		}
+	}`, `contract TestSelectNulls {
+		data {
+			Id int
+		}
+		action {
+			var row map
+			row = DBFind("smarttest_nulls").Where("id=$", $Id).Row()
+			if row["name"] == "" && row["code"] == "" {
+				$result = Sprintf("empty %s %s", row["amount"], row["info"])
+			} else {
+				$result = Sprintf("%v", row)
+			}
+		}
+	}`)
+	defer env.Close()
+
+	env.MustCall("NewTable", Params{"Name": "smarttest_nulls", "ApplicationId": "1",
+		"Columns": `[{"name":"name","type":"text","conditions":"true"},{"name":"code","type":"varchar","conditions":"true"},
+			{"name":"amount","type":"number","conditions":"true"},{"name":"info","type":"text","conditions":"true"}]`,
+		"Permissions": `{"insert": "true", "update": "true", "new_column": "true"}`})
+	table := `"1_smarttest_nulls"`
+
+	// the text columns are NOT NULL
+	require.NoError(t, env.Exec(`SAVEPOINT nulls`))
+	assert.Error(t, env.Exec(`INSERT INTO `+table+` (id, name) VALUES (100, NULL)`))
+	require.NoError(t, env.Exec(`ROLLBACK TO SAVEPOINT nulls`))
+
+	env.MustCall("TestInsertNulls", nil)
+	rows := env.Rows("smarttest_nulls", "")
+	require.Len(t, rows, 1)
+	assert.Equal(t, "", rows[0]["name"])
+	assert.Equal(t, "", rows[0]["code"])
+	assert.Equal(t, "empty 0 inserted", env.MustCall("TestSelectNulls", Params{"Id": rows[0]["id"]}).Value)
+
+	// the columns of the tables which have been created before NOT NULL
+	require.NoError(t, env.Exec(`ALTER TABLE `+table+` ALTER COLUMN name DROP NOT NULL, ALTER COLUMN code DROP NOT NULL,
+		ALTER COLUMN amount DROP NOT NULL, ALTER COLUMN info DROP NOT NULL`))
+	require.NoError(t, env.Exec(`INSERT INTO `+table+` (id, name, code, amount, info) VALUES (100, NULL, NULL, NULL, NULL)`))
+	assert.Equal(t, "NULL", env.Row("smarttest_nulls", 100)["name"])
+	assert.Equal(t, "empty 0 ", env.MustCall("TestSelectNulls", Params{"Id": "100"}).Value)
+}
+
+func TestJSONDecodeSchema(t *testing.T) {
+	t.Parallel()
+	env := New(t, `contract TestDecodeSchema {
+		data {
+			Input string
+			Schema string
+		}
+		action {
+			var m map
+			m = JSONDecodeSchema($Input, $Schema, true)
+			$result = Sprintf("%s %v %d", m["name"], m["amount"], Len(m["tags"]))
+		}
+	}`)
+	defer env.Close()
+
+	schema := `{"name":"string","amount":"money","?tags":["string"]}`
+	result := env.MustCall("TestDecodeSchema", Params{"Input": `{"name":"test","amount":"1000000000000000000000","tags":["a"]}`,
+		"Schema": schema})
+	assert.Equal(t, "test 1000000000000000000000 1", result.Value)
+	assert.True(t, result.Fuel > 0)
+
+	result = env.Call("TestDecodeSchema", Params{"Input": `{"name":"test","amount":1,"tags":["a",2]}`, "Schema": schema})
+	require.NotNil(t, result.Message)
+	assert.Contains(t, result.Message.Error, "JSON value $.tags[1] must be string")
+}
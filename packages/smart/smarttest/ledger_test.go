@@ -0,0 +1,113 @@
+package smarttest
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedger(t *testing.T) {
+	env := New(t, `contract TestLedgerEmission {
+		data {
+			Key int
+			Amount string
+			Burn bool
+		}
+		action {
+			if $Burn {
+				Burn($Key, $Amount)
+			} else {
+				Issue($Key, $Amount)
+			}
+		}
+	}`)
+	defer env.Close()
+
+	// the fee is paid in the public blockchain only, so the test isn't parallel
+	mode := conf.Config.RunningMode
+	conf.Config.RunningMode = "PublicBlockchain"
+	defer func() { conf.Config.RunningMode = mode }()
+
+	require.NoError(t, env.Exec(`DELETE FROM "1_parameters" WHERE name = 'emission_contract'`))
+	require.NoError(t, env.Exec(`INSERT INTO "1_parameters" (id, name, value, conditions)
+		VALUES ((SELECT max(id) + 1 FROM "1_parameters"), 'emission_contract', 'TestLedgerEmission', 'true')`))
+
+	const firstBlock = 2000000000
+	keys := []int64{env.NewKey("0"), env.NewKey("0"), env.NewKey("0")}
+	before, supply := env.balances(t)
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := int64(0); i < 10; i++ {
+		env.BlockID = firstBlock + i
+		env.BlockKeyID = keys[rnd.Intn(len(keys))]
+		for j := 0; j < 3; j++ {
+			key := keys[rnd.Intn(len(keys))]
+			amount := strconv.Itoa(rnd.Intn(1000) + 1)
+			switch rnd.Intn(3) {
+			case 0:
+				env.MustCall("MoneyTransfer", Params{"Recipient": converter.AddressToString(key), "Amount": amount})
+			case 1:
+				env.MustCall("TestLedgerEmission", Params{"Key": key, "Amount": amount})
+			default:
+				// the burning fails and doesn't change anything if the balance isn't enough
+				env.Call("TestLedgerEmission", Params{"Key": key, "Amount": amount, "Burn": true})
+			}
+		}
+	}
+	after, total := env.balances(t)
+
+	deltas := make(map[int64]decimal.Decimal)
+	issued := decimal.Zero
+	types := make(map[string]bool)
+	for _, row := range env.Rows("history", "block_id >= ?", firstBlock) {
+		amount, err := decimal.NewFromString(row["amount"])
+		require.NoError(t, err)
+		sender, _ := strconv.ParseInt(row["sender_id"], 10, 64)
+		recipient, _ := strconv.ParseInt(row["recipient_id"], 10, 64)
+		if sender == 0 {
+			issued = issued.Add(amount)
+		} else {
+			deltas[sender] = deltas[sender].Sub(amount)
+		}
+		if recipient == 0 {
+			issued = issued.Sub(amount)
+		} else {
+			deltas[recipient] = deltas[recipient].Add(amount)
+		}
+		types[row["type"]] = true
+	}
+	assert.True(t, types[model.HistoryTransfer] && types[model.HistoryFee] && types[model.HistoryEmission])
+	for id, amount := range after {
+		assert.True(t, amount.Sub(before[id]).Equal(deltas[id]), "balance of %d", id)
+	}
+	for id := range deltas {
+		_, ok := after[id]
+		assert.True(t, ok, "key %d", id)
+	}
+	assert.True(t, total.Sub(supply).Equal(issued))
+}
+
+// balances returns the balances of the keys and the total supply of the ecosystem
+func (env *Env) balances(t *testing.T) (map[int64]decimal.Decimal, decimal.Decimal) {
+	ret := make(map[int64]decimal.Decimal)
+	for _, row := range env.Rows("keys", "") {
+		id, _ := strconv.ParseInt(row["id"], 10, 64)
+		amount, err := decimal.NewFromString(row["amount"])
+		require.NoError(t, err)
+		ret[id] = amount
+	}
+	rows, err := model.GetAllTransaction(env.tx, `select total_supply from "1_ecosystems" where id = ?`, 1, Ecosystem)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	supply, err := decimal.NewFromString(rows[0]["total_supply"])
+	require.NoError(t, err)
+	return ret, supply
+}
@@ -0,0 +1,60 @@
+package smarttest
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContractUpgrade(t *testing.T) {
+	env := New(t)
+	defer env.Close()
+
+	result := env.MustCall("NewContract", Params{"ApplicationId": 1, "Conditions": "true",
+		"Value": `contract TestUpgrade { action { } }`})
+	id, err := strconv.ParseInt(result.Value, 10, 64)
+	require.NoError(t, err)
+
+	upgrades := func() []map[string]string {
+		return env.Rows("contract_upgrades", "contract_id = ?", id)
+	}
+	edit := func(upgrade string) {
+		env.MustCall("EditContract", Params{"Id": id,
+			"Value": `contract TestUpgrade { action { } ` + upgrade + ` }`})
+	}
+
+	edit(``)
+	assert.Equal(t, "2", env.Row("contracts", id)["version"])
+	assert.Empty(t, upgrades(), "the contract without the upgrade function")
+
+	edit(`func upgrade() string { return Sprintf("%d-%d", $old_version, $new_version) }`)
+	edit(`func upgrade() string { return Sprintf("%d>%d", $old_version, $new_version) }`)
+	list := upgrades()
+	require.Len(t, list, 1, "the pending upgrade is moved to the new version")
+	upgradeID := list[0]["id"]
+	assert.Equal(t, "2", list[0]["old_version"])
+	assert.Equal(t, "4", list[0]["new_version"])
+
+	next, err := strconv.ParseInt(list[0]["block_id"], 10, 64)
+	require.NoError(t, err)
+	assert.Error(t, env.Call("CallContractUpgrade", Params{"Id": upgradeID}).Err, "the upgrade runs in the next block")
+
+	env.BlockID = next
+	env.MustCall("CallContractUpgrade", Params{"Id": upgradeID})
+	list = upgrades()
+	assert.Equal(t, "2>4", list[0]["result"])
+	assert.Equal(t, strconv.FormatInt(next, 10), list[0]["executed_block"])
+	assert.Error(t, env.Call("CallContractUpgrade", Params{"Id": upgradeID}).Err, "the upgrade runs once")
+
+	edit(`func upgrade() { DBUpdate("contracts", $contract_id, "conditions", "false") error "upgrade failed" }`)
+	list = upgrades()
+	require.Len(t, list, 2)
+	env.BlockID++
+	env.MustCall("CallContractUpgrade", Params{"Id": list[1]["id"]})
+	row := env.Row("contracts", id)
+	assert.Contains(t, row["upgrade_error"], "upgrade failed")
+	assert.Equal(t, "true", row["conditions"], "the changes of the failed upgrade are rolled back")
+	assert.Contains(t, upgrades()[1]["error"], "upgrade failed")
+}
@@ -0,0 +1,128 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// deletedColumn is the column which marks the soft-deleted rows
+	deletedColumn = `deleted`
+	// notDeleted is the condition of the rows which aren't soft-deleted
+	notDeleted = `deleted = 0`
+)
+
+// checkSoftDelete checks the type of the deleted column of the table with soft delete
+func checkSoftDelete(itype string) error {
+	if itype != `number` {
+		log.WithFields(log.Fields{"type": consts.InvalidObject, "column_type": itype}).Error("soft delete column is not number")
+		return errSoftDeleteColumn
+	}
+	return nil
+}
+
+// isSoftDelete returns true if the permissions of the table declare soft delete
+func isSoftDelete(perm map[string]string) bool {
+	return perm[`soft_delete`] == `true`
+}
+
+// MarkDeleted marks the row of the table with soft delete as deleted
+func MarkDeleted(sc *SmartContract, tblname string, id int64) (qcost int64, err error) {
+	tblname = getDefTableName(sc, tblname)
+	prefix, name := PrefixName(tblname)
+	tables := &model.Table{}
+	tables.SetTablePrefix(prefix)
+	perm, err := tables.GetPermissions(sc.DbTransaction, name, "")
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": tblname}).Error("getting table permissions")
+		return 0, err
+	}
+	if !isSoftDelete(perm) {
+		return 0, fmt.Errorf(eNotSoftDelete, tblname)
+	}
+	if err = sc.AccessTable(tblname, "update"); err != nil {
+		return
+	}
+	columns := []string{deletedColumn}
+	if err = sc.AccessColumns(tblname, &columns, true); err != nil {
+		return
+	}
+	qcost, _, err = sc.selectiveLoggingAndUpd(columns, []interface{}{1}, tblname, []string{`id`},
+		[]string{converter.Int64ToStr(id)}, !sc.VDE && sc.Rollback, true)
+	return
+}
+
+// partialUniqueIndexes makes the unique indexes of the table with soft delete partial, so the values
+// of the deleted rows can be used again
+func partialUniqueIndexes(sc *SmartContract, tblname string) error {
+	indexes, err := model.GetUniqueIndexes(sc.DbTransaction, tblname)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": tblname}).Error("getting unique indexes")
+		return err
+	}
+	if len(indexes) == 0 {
+		return nil
+	}
+	for _, index := range indexes {
+		if err = model.SetIndexCondition(sc.DbTransaction, tblname, index, notDeleted); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "index": index.Name}).Error("setting index condition")
+			return err
+		}
+	}
+	if sc.VDE {
+		return nil
+	}
+	out, err := json.Marshal(indexes)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling unique indexes")
+		return err
+	}
+	return SysRollback(sc, map[string]string{"Type": "PartialIndexes", "TableName": tblname, "Indexes": string(out)})
+}
+
+// SysRollbackPartialIndexes restores the unique indexes which have been made partial
+func SysRollbackPartialIndexes(DbTransaction *model.DbTransaction, TableName, Indexes string) error {
+	var indexes []model.UniqueIndex
+	if err := json.Unmarshal([]byte(Indexes), &indexes); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling unique indexes")
+		return err
+	}
+	for _, index := range indexes {
+		if err := model.RestoreUniqueIndex(DbTransaction, TableName, index); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "index": index.Name}).Error("restoring unique index")
+			return err
+		}
+	}
+	return nil
+}
+
+// deletedCondition adds the condition which excludes the soft-deleted rows to the where
+func deletedCondition(where string) string {
+	if len(strings.TrimSpace(where)) == 0 {
+		return notDeleted
+	}
+	return `(` + where + `) AND ` + notDeleted
+}
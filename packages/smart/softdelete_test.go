@@ -0,0 +1,23 @@
+package smart
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSoftDelete(t *testing.T) {
+	assert.Equal(t, "deleted = 0", deletedCondition(""))
+	assert.Equal(t, "(id > ? or name = ?) AND deleted = 0", deletedCondition("id > ? or name = ?"))
+
+	assert.NoError(t, checkSoftDelete("number"))
+	assert.Equal(t, errSoftDeleteColumn, checkSoftDelete(""))
+	assert.Equal(t, errSoftDeleteColumn, checkSoftDelete("varchar"))
+
+	var perm permTable
+	assert.NoError(t, json.Unmarshal([]byte(`{"insert":"true","soft_delete":true}`), &perm))
+	assert.True(t, perm.SoftDelete)
+	assert.True(t, isSoftDelete(map[string]string{"soft_delete": "true"}))
+	assert.False(t, isSoftDelete(map[string]string{"insert": "true"}))
+}
@@ -0,0 +1,56 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import "strings"
+
+// contractStack returns the full names of the called contracts from the outermost to the current one.
+// The stack of the calls contains the names of the embedded functions too, they are skipped
+func (sc *SmartContract) contractStack() []interface{} {
+	stack := make([]interface{}, 0, len(sc.TxContract.StackCont))
+	for _, item := range sc.TxContract.StackCont {
+		if name, ok := item.(string); ok && strings.HasPrefix(name, `@`) {
+			stack = append(stack, name)
+		}
+	}
+	return stack
+}
+
+// stackContract returns the contract of the stack, the negative index is counted from the current contract
+func (sc *SmartContract) stackContract(index int) string {
+	if sc.TxContract == nil {
+		return ``
+	}
+	stack := sc.contractStack()
+	if index < 0 {
+		index += len(stack)
+	}
+	if index < 0 || index >= len(stack) {
+		return ``
+	}
+	return stack[index].(string)
+}
+
+// CallerContract returns the name of the contract which has called the current contract
+func CallerContract(sc *SmartContract) string {
+	return sc.stackContract(-2)
+}
+
+// OriginalContract returns the name of the contract of the transaction
+func OriginalContract(sc *SmartContract) string {
+	return sc.stackContract(0)
+}
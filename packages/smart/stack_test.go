@@ -0,0 +1,63 @@
+package smart
+
+import (
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/script"
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContractStack(t *testing.T) {
+	vm, err := NewCheckVM(script.VMTypeSmart)
+	require.NoError(t, err)
+	vm.FlushExtern()
+	for _, src := range []string{`contract GuardOne {
+	conditions {
+		ContractConditions("GuardTwo")
+	}
+}`, `contract GuardTwo {
+	conditions {
+		ContractConditions("GuardThree")
+	}
+}`, `contract GuardThree {
+	conditions {
+		if $stack[0] != "@1Main" {
+			error "wrong stack"
+		}
+		// the key 1 fails to return the stack
+		if $key_id == 1 {
+			error Sprintf("%s|%s|%s", Join($stack, ","), CallerContract(), OriginalContract())
+		}
+	}
+}`} {
+		root, err := VMCompileBlock(vm, src, &script.OwnerInfo{StateID: 1})
+		require.NoError(t, err)
+		VMFlushBlock(vm, root)
+	}
+
+	call := func(key int64) (*SmartContract, error) {
+		sc := &SmartContract{
+			VM:         vm,
+			TxSmart:    tx.SmartContract{Header: tx.Header{EcosystemID: 1, KeyID: key}},
+			TxContract: &Contract{Name: "@1Main", Extend: &map[string]interface{}{}},
+		}
+		require.NoError(t, sc.AppendStack(sc.TxContract.Name))
+		_, err := ContractConditions(sc, nil, "GuardOne")
+		return sc, err
+	}
+
+	_, err = call(1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "@1Main,@1GuardOne,@1GuardTwo,@1GuardThree|@1GuardTwo|@1Main")
+
+	sc, err := call(2)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"@1Main"}, (*sc.TxContract.Extend)[`stack`])
+	assert.Equal(t, "@1Main", OriginalContract(sc))
+	assert.Empty(t, CallerContract(sc))
+	assert.True(t, accessContracts(sc, "NewContract", "Main"))
+	assert.False(t, accessContracts(sc, "GuardOne"))
+}
@@ -19,6 +19,8 @@ package smart
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/GenesisKernel/go-genesis/packages/consts"
@@ -29,16 +31,25 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func SysRollback(sc *SmartContract, data string) error {
+// SysRollback writes the rollback record of the system change, the data is stored in the canonical JSON
+func SysRollback(sc *SmartContract, data map[string]string) error {
+	// the rollback records are skipped in the fast sync mode
+	if !sc.Rollback {
+		return nil
+	}
+	out, err := converter.CanonicalJSON(data)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling system rollback data")
+		return err
+	}
 	rollbackSys := &model.RollbackTx{
 		BlockID:   sc.BlockData.BlockID,
 		TxHash:    sc.TxHash,
 		NameTable: `@system`,
 		TableID:   converter.Int64ToStr(sc.TxSmart.EcosystemID),
-		Data:      data,
+		Data:      string(out),
 	}
-	err := rollbackSys.Create(sc.DbTransaction)
-	if err != nil {
+	if err = rollbackSys.Create(sc.DbTransaction); err != nil {
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("creating system  rollback")
 		return err
 	}
@@ -93,6 +104,56 @@ func SysRollbackColumn(DbTransaction *model.DbTransaction, TxHash []byte,
 	return model.AlterTableDropColumn(TableName, Name)
 }
 
+// SysRollbackColumnType restores the type of the column which has been changed by AlterColumnType
+// and the original values of the changed rows
+func SysRollbackColumnType(DbTransaction *model.DbTransaction, TableName, Name, OldType, Values string) error {
+	var original map[string]*string
+	if err := json.Unmarshal([]byte(Values), &original); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling original values")
+		return err
+	}
+	sqlType := alterColumnSQLTypes[OldType]
+	if len(sqlType) == 0 {
+		return fmt.Errorf(`Type %s of column %s is unknown`, OldType, Name)
+	}
+	defValue, notNull := alterColumnDefaults[OldType]
+	err := model.AlterColumnType(DbTransaction, TableName, Name, sqlType, alterColumnUsing(Name, OldType),
+		notNull, defValue)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": TableName}).Error("restoring column type")
+		return err
+	}
+	ids := make([]int64, 0, len(original))
+	for key := range original {
+		id, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": key}).Error("converting row id")
+			return err
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for len(ids) > 0 {
+		size := alterColumnBatch
+		if size > len(ids) {
+			size = len(ids)
+		}
+		values := make([]*string, size)
+		for i, id := range ids[:size] {
+			values[i] = original[strconv.FormatInt(id, 10)]
+			if values[i] == nil && notNull {
+				values[i] = &defValue
+			}
+		}
+		if err = model.UpdateColumnValues(DbTransaction, TableName, Name, sqlType, ids[:size], values); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": TableName}).Error("restoring column values")
+			return err
+		}
+		ids = ids[size:]
+	}
+	return nil
+}
+
 // SysRollbackContract performs rollback for the contract
 func SysRollbackContract(name string, EcosystemID int64) error {
 	vm := GetVM()
@@ -120,6 +181,24 @@ func SysRollbackNewContract(value, EcosystemID string) error {
 	return nil
 }
 
+// SysRollbackNewContracts removes the contracts which are loaded into smartVM by CreateContracts,
+// the contracts are the range of smartVM from the index from
+func SysRollbackNewContracts(from, names string) error {
+	index, err := strconv.Atoi(from)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": from}).Error("converting index of contracts")
+		return err
+	}
+	vm := GetVM()
+	if index < len(vm.Children) {
+		vm.Children = vm.Children[:index]
+	}
+	for _, name := range strings.Split(names, ",") {
+		delete(vm.Objects, name)
+	}
+	return nil
+}
+
 // SysFlushContract is flushing contract
 func SysFlushContract(iroot interface{}, id int64, active bool) error {
 	root := iroot.(*script.Block)
@@ -165,8 +244,7 @@ func SysRollbackEditContract(DbTransaction *model.DbTransaction, TxHash []byte,
 		// if there is not such hash then EditContract was faulty. Do nothing.
 		return nil
 	}
-	var fields map[string]string
-	err = json.Unmarshal([]byte(rollbackTx.Data), &fields)
+	fields, err := model.UnmarshalRollbackData(rollbackTx.Data)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling contract values")
 		return err
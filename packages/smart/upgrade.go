@@ -0,0 +1,177 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// upgradeFunc is the optional function of the contract which migrates the data of the previous version
+	upgradeFunc = `upgrade`
+	// upgradeSavepoint is the index of the savepoint of the upgrade function
+	upgradeSavepoint = -2
+
+	eUpgradeFunc = `Upgrade function of contract %s is not defined`
+)
+
+var (
+	errUpgradeCall     = errors.New(`RunContractUpgrade can be only called from CallContractUpgrade`)
+	errUpgradeNotFound = errors.New(`Contract upgrade has not been found`)
+	errUpgradeExecuted = errors.New(`Contract upgrade has been executed`)
+)
+
+// scheduleUpgrade increments the version of the replaced contract and schedules the single execution
+// of its upgrade function in the next block. If the upgrade of the contract is still pending then it
+// is moved to the new version, so the upgrade function runs once from the version of the pending upgrade
+func scheduleUpgrade(sc *SmartContract, root *script.Block, id int64) error {
+	logger := sc.GetLogger()
+	table := getDefTableName(sc, `contracts`)
+	row, err := model.GetOneRowTransaction(sc.DbTransaction, `SELECT version FROM "`+table+`" WHERE id = ?`, id).Int64()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "contract_id": id}).Error("getting contract version")
+		return err
+	}
+	version := row[`version`] + 1
+	if _, _, err = sc.selectiveLoggingAndUpd([]string{`version`}, []interface{}{version}, table,
+		[]string{`id`}, []string{strconv.FormatInt(id, 10)}, true, true); err != nil {
+		return err
+	}
+
+	upgrade := &model.ContractUpgrade{}
+	pending, err := upgrade.GetPending(sc.DbTransaction, sc.TxSmart.EcosystemID, id)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "contract_id": id}).Error("getting pending contract upgrade")
+		return err
+	}
+	var blockID int64
+	if sc.BlockData != nil {
+		blockID = sc.BlockData.BlockID
+	}
+	if pending {
+		_, _, err = sc.selectiveLoggingAndUpd([]string{`new_version`, `key_id`, `block_id`},
+			[]interface{}{version, sc.TxSmart.KeyID, blockID + 1}, upgrade.TableName(),
+			[]string{`id`}, []string{strconv.FormatInt(upgrade.ID, 10)}, true, true)
+		return err
+	}
+	if obj, ok := root.Children[0].Objects[upgradeFunc]; !ok || obj.Type != script.ObjFunc {
+		return nil
+	}
+	_, _, err = sc.selectiveLoggingAndUpd([]string{`ecosystem`, `contract_id`, `contract`, `key_id`,
+		`old_version`, `new_version`, `block_id`},
+		[]interface{}{sc.TxSmart.EcosystemID, id, root.Children[0].Info.(*script.ContractInfo).Name,
+			sc.TxSmart.KeyID, version - 1, version, blockID + 1}, upgrade.TableName(), nil, nil, true, false)
+	return err
+}
+
+// RunContractUpgrade executes the upgrade function of the contract in the ecosystem of the contract.
+// The old and new versions are available as $old_version and $new_version. The upgrade is executed
+// only once, the error of the upgrade function doesn't break the transaction, the changes of the failed
+// function are rolled back and the error is written to the upgrade and to the row of the contract
+func RunContractUpgrade(sc *SmartContract, id int64) error {
+	if !accessContracts(sc, `CallContractUpgrade`) {
+		log.WithFields(log.Fields{"type": consts.IncorrectCallingContract}).Error("RunContractUpgrade can be only called from CallContractUpgrade")
+		return errUpgradeCall
+	}
+	if sc.DbTransaction == nil || sc.BlockData == nil {
+		return errTryCallContract
+	}
+	logger := sc.GetLogger()
+	upgrade := &model.ContractUpgrade{}
+	found, err := upgrade.GetTransaction(sc.DbTransaction, id)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "id": id}).Error("getting contract upgrade")
+		return err
+	}
+	if !found {
+		return errUpgradeNotFound
+	}
+	if upgrade.ExecutedBlock != 0 {
+		return errUpgradeExecuted
+	}
+
+	var result, errText string
+	contract := VMGetContract(sc.VM, upgrade.Contract, uint32(upgrade.Ecosystem))
+	var block *script.Block
+	if contract != nil {
+		block = contract.GetFunc(upgradeFunc)
+	}
+	if block == nil {
+		errText = fmt.Sprintf(eUpgradeFunc, upgrade.Contract)
+	} else if result, errText, err = sc.runUpgrade(block, upgrade); err != nil {
+		return err
+	}
+
+	if _, _, err = sc.selectiveLoggingAndUpd([]string{`executed_block`, `result`, `error`},
+		[]interface{}{sc.BlockData.BlockID, result, errText}, upgrade.TableName(),
+		[]string{`id`}, []string{strconv.FormatInt(id, 10)}, true, true); err != nil {
+		return err
+	}
+	_, _, err = sc.selectiveLoggingAndUpd([]string{`upgrade_error`}, []interface{}{errText},
+		fmt.Sprintf(`%d_contracts`, upgrade.Ecosystem), []string{`id`},
+		[]string{strconv.FormatInt(upgrade.ContractID, 10)}, true, true)
+	return err
+}
+
+// runUpgrade runs the upgrade function with the savepoint and returns the result or the error text of it
+func (sc *SmartContract) runUpgrade(block *script.Block, upgrade *model.ContractUpgrade) (string, string, error) {
+	if err := sc.DbTransaction.Savepoint(upgradeSavepoint); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("creating savepoint")
+		return ``, ``, err
+	}
+	vars := *sc.TxContract.Extend
+	extend := make(map[string]interface{}, len(vars)+4)
+	for key, val := range vars {
+		extend[key] = val
+	}
+	extend[`ecosystem_id`] = upgrade.Ecosystem
+	extend[`contract_id`] = upgrade.ContractID
+	extend[`old_version`] = upgrade.OldVersion
+	extend[`new_version`] = upgrade.NewVersion
+
+	// the tables of the upgrade function are the tables of the ecosystem of the contract
+	ecosystem := sc.TxSmart.EcosystemID
+	sc.TxSmart.EcosystemID = upgrade.Ecosystem
+	ret, err := VMRun(sc.VM, block, nil, &extend)
+	sc.TxSmart.EcosystemID = ecosystem
+	vars[`txcost`] = extend[`txcost`]
+
+	if err != nil {
+		if errRollback := sc.DbTransaction.RollbackSavepoint(upgradeSavepoint); errRollback != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": errRollback}).Error("rollback to savepoint")
+			return ``, ``, errRollback
+		}
+		return ``, err.Error(), nil
+	}
+	if err = sc.DbTransaction.ReleaseSavepoint(upgradeSavepoint); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("releasing savepoint")
+		return ``, ``, err
+	}
+	var result string
+	if len(ret) > 0 && ret[0] != nil {
+		result = fmt.Sprint(ret[0])
+	}
+	return result, ``, nil
+}
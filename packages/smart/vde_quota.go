@@ -0,0 +1,74 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+	"github.com/GenesisKernel/go-genesis/packages/usage"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// QuotaErrorType is the type of the error of the contract of VDE which application has exceeded the quotas
+const QuotaErrorType = `quota`
+
+// vdeAppID returns the application of the contract of VDE, the contracts which application
+// can't be read are counted as the application 0
+func (sc *SmartContract) vdeAppID() int64 {
+	owner := sc.TxContract.Block.Info.(*script.ContractInfo).Owner
+	row, err := model.GetOneRowTransaction(sc.DbTransaction, `SELECT app_id FROM "`+getDefTableName(sc, `contracts`)+
+		`" WHERE id = ?`, owner.TableID).Int64()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "contract_id": owner.TableID}).Error("getting application of contract")
+		return 0
+	}
+	return row[`app_id`]
+}
+
+// startVDEQuota checks the quotas of the application of the contract of VDE. The returned function counts
+// the execution time and the fuel of the contract, it must be called when the contract is finished
+func (sc *SmartContract) startVDEQuota() (func(), error) {
+	appID := sc.vdeAppID()
+	if err := usage.CheckApp(appID, time.Now()); err != nil {
+		sc.GetLogger().WithFields(log.Fields{"type": consts.ParameterExceeded, "app_id": appID, "error": err}).Warn("vde quota is exceeded")
+		return nil, quotaError(err)
+	}
+	start := time.Now()
+	return func() {
+		usage.CountApp(appID, time.Since(start), sc.TxFuel, time.Now())
+	}, nil
+}
+
+// quotaError returns the error of the exceeded quota as JSON of VMError with the time of the reset
+func quotaError(err error) error {
+	vmErr := script.VMError{Type: QuotaErrorType, Error: err.Error()}
+	if quotaErr, ok := err.(*usage.AppQuotaError); ok {
+		vmErr.Reset = quotaErr.Reset
+	}
+	out, jsonErr := json.Marshal(&vmErr)
+	if jsonErr != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": jsonErr}).Error("marshalling VMError")
+		return err
+	}
+	return errors.New(string(out))
+}
@@ -0,0 +1,14 @@
+package smart
+
+import (
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/usage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaError(t *testing.T) {
+	err := quotaError(&usage.AppQuotaError{AppID: 2, Quota: `fuel`, Reset: 1530000060})
+	assert.EqualError(t, err, `{"type":"quota","error":"fuel quota of application 2 is exceeded until 1530000060","reset":1530000060}`)
+}
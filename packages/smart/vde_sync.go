@@ -0,0 +1,156 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/vdesync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	syncSourcesTable   = `vde_sync_sources`
+	changingSyncParam  = `changing_sync_sources`
+	errSyncSourceParam = `Wrong %s of the sync source`
+)
+
+var (
+	errSyncNotVDE      = errors.New(`Sync sources can be only changed in VDE`)
+	errSyncSourceExist = errors.New(`Sync source with the same name already exists`)
+)
+
+// CreateSyncSource creates the source which table is pulled to the target table of VDE
+func CreateSyncSource(sc *SmartContract, name, sourceType, sourceURL string, ecosystem int64,
+	sourceTable, targetTable, columns string, period int64, conflict string) (int64, error) {
+	if !sc.VDE {
+		return 0, errSyncNotVDE
+	}
+	if err := sc.syncAccess(); err != nil {
+		return 0, err
+	}
+	if len(name) == 0 || len(name) > 255 {
+		return 0, fmt.Errorf(errSyncSourceParam, `name`)
+	}
+	if sourceType != vdesync.SourceChain && sourceType != vdesync.SourceVDE {
+		return 0, fmt.Errorf(errSyncSourceParam, `type`)
+	}
+	if u, err := url.Parse(sourceURL); err != nil || (u.Scheme != `http` && u.Scheme != `https`) || len(u.Host) == 0 {
+		return 0, fmt.Errorf(errSyncSourceParam, `url`)
+	}
+	if ecosystem <= 0 {
+		return 0, fmt.Errorf(errSyncSourceParam, `ecosystem`)
+	}
+	if len(sourceTable) == 0 || !converter.IsLatin(sourceTable) {
+		return 0, fmt.Errorf(errSyncSourceParam, `source table`)
+	}
+	if period <= 0 {
+		return 0, fmt.Errorf(errSyncSourceParam, `period`)
+	}
+	if conflict != model.SyncConflictSource && conflict != model.SyncConflictSkip {
+		return 0, fmt.Errorf(errSyncSourceParam, `conflict`)
+	}
+	mapping, err := vdesync.ParseColumns(columns)
+	if err != nil {
+		return 0, err
+	}
+	if err = sc.checkSyncTarget(targetTable, mapping); err != nil {
+		return 0, err
+	}
+	table := getDefTableName(sc, syncSourcesTable)
+	row, err := model.GetOneRowTransaction(sc.DbTransaction, `SELECT id FROM "`+table+`" WHERE name = ?`, name).String()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting sync source")
+		return 0, err
+	}
+	if len(row) > 0 {
+		return 0, errSyncSourceExist
+	}
+	_, id, err := sc.selectiveLoggingAndUpd([]string{`name`, `source_type`, `url`, `source_ecosystem`, `source_table`,
+		`target_table`, `columns`, `period`, `conflict`, `status`}, []interface{}{name, sourceType, sourceURL, ecosystem,
+		sourceTable, targetTable, columns, period, conflict, model.SyncSourceActive}, table, nil, nil, false, false)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(id, 10, 64)
+}
+
+// PauseSyncSource pauses or resumes the synchronization of the source
+func PauseSyncSource(sc *SmartContract, id int64, pause bool) error {
+	if !sc.VDE {
+		return errSyncNotVDE
+	}
+	if err := sc.syncAccess(); err != nil {
+		return err
+	}
+	status := model.SyncSourceActive
+	if pause {
+		status = model.SyncSourcePaused
+	}
+	_, _, err := sc.selectiveLoggingAndUpd([]string{`status`}, []interface{}{status},
+		getDefTableName(sc, syncSourcesTable), []string{`id`}, []string{strconv.FormatInt(id, 10)}, false, true)
+	return err
+}
+
+// syncAccess checks the changing_sync_sources condition of the ecosystem
+func (sc *SmartContract) syncAccess() error {
+	condition := EcosysParam(sc, changingSyncParam)
+	if len(condition) == 0 {
+		return fmt.Errorf(`There is not %s in parameters`, changingSyncParam)
+	}
+	ret, err := sc.EvalIf(condition)
+	if err != nil {
+		return err
+	}
+	if !ret {
+		return errAccessDenied
+	}
+	return nil
+}
+
+// checkSyncTarget checks that the target table of the ecosystem has the columns of the mapping
+func (sc *SmartContract) checkSyncTarget(targetTable string, mapping map[string]string) error {
+	tables := &model.Table{}
+	tables.SetTablePrefix(strconv.FormatInt(sc.TxSmart.EcosystemID, 10))
+	found, err := tables.Get(sc.DbTransaction, targetTable)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting target table")
+		return err
+	}
+	if !found {
+		return fmt.Errorf(eTableNotFound, targetTable)
+	}
+	var cols map[string]string
+	if err = json.Unmarshal([]byte(tables.Columns), &cols); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("getting target table columns")
+		return err
+	}
+	for _, column := range mapping {
+		if _, ok := cols[column]; !ok {
+			return fmt.Errorf(`column %s has not been found in %s`, column, targetTable)
+		}
+	}
+	return nil
+}
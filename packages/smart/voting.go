@@ -0,0 +1,239 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+const votingsTable = `votings`
+
+var (
+	errVotingSubject   = errors.New(`Subject of voting must contain the name of the existing contract`)
+	errVotingVoters    = errors.New(`Voters must be the list of the different key ids`)
+	errVotingThreshold = errors.New(`Threshold must be from 1 to the number of voters`)
+	errVotingDeadline  = errors.New(`Deadline must be greater than the current time`)
+	errVotingClosed    = errors.New(`Voting is closed`)
+	errVotingNotVoter  = errors.New(`The key is not in the list of voters`)
+	errVotingVoted     = errors.New(`The key has already voted`)
+)
+
+// votingSubject is the action which is executed when the voting is accepted
+type votingSubject struct {
+	Contract string                 `json:"contract"`
+	Params   map[string]interface{} `json:"params"`
+}
+
+// currentTime returns the time of the block or the time of the transaction outside of the block
+func (sc *SmartContract) currentTime() int64 {
+	if sc.BlockData != nil && sc.BlockData.Time > 0 {
+		return sc.BlockData.Time
+	}
+	return sc.TxSmart.Time
+}
+
+// StartVoting creates the voting if the conditions of the votings table are true and returns its id.
+// The contract of the subject is called with its params when threshold voters accept it before deadline
+func StartVoting(sc *SmartContract, subject map[string]interface{}, voters []interface{},
+	threshold, deadline int64) (int64, error) {
+
+	name, _ := subject[`contract`].(string)
+	if len(name) == 0 || VMGetContract(sc.VM, name, uint32(sc.TxSmart.EcosystemID)) == nil {
+		return 0, errVotingSubject
+	}
+	if params, ok := subject[`params`]; ok && params != nil {
+		if _, ok := params.(map[string]interface{}); !ok {
+			return 0, errVotingSubject
+		}
+	}
+	keys := make([]int64, 0, len(voters))
+	unique := make(map[int64]bool, len(voters))
+	for _, item := range voters {
+		key, err := Int(item)
+		if err != nil || key == 0 || unique[key] {
+			return 0, errVotingVoters
+		}
+		unique[key] = true
+		keys = append(keys, key)
+	}
+	if threshold < 1 || threshold > int64(len(keys)) {
+		return 0, errVotingThreshold
+	}
+	if deadline <= sc.currentTime() {
+		return 0, errVotingDeadline
+	}
+	if err := sc.tableConditions(votingsTable); err != nil {
+		return 0, err
+	}
+
+	subjectData, err := json.Marshal(votingSubject{Contract: script.StateName(uint32(sc.TxSmart.EcosystemID), name),
+		Params: subjectParams(subject)})
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling voting subject")
+		return 0, err
+	}
+	votersData, err := json.Marshal(keys)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling voters")
+		return 0, err
+	}
+	_, id, err := sc.selectiveLoggingAndUpd([]string{`subject`, `voters`, `threshold`, `deadline`, `creator`,
+		`timestamp date_created`}, []interface{}{string(subjectData), string(votersData), threshold, deadline,
+		sc.TxSmart.KeyID, sc.TxSmart.Time}, sc.tablePrefix()+`_`+votingsTable, nil, nil, !sc.VDE && sc.Rollback, false)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(id, 10, 64)
+}
+
+func subjectParams(subject map[string]interface{}) map[string]interface{} {
+	if params, ok := subject[`params`].(map[string]interface{}); ok {
+		return params
+	}
+	return map[string]interface{}{}
+}
+
+// Vote adds the decision of the key to the voting. The subject of the voting is executed when
+// the number of accepting votes reaches the threshold, the voting is rejected when it can't be reached
+func Vote(sc *SmartContract, rt *script.RunTime, id int64, decision bool) error {
+	voting := &model.Voting{}
+	found, err := voting.SetTablePrefix(sc.TxSmart.EcosystemID).Get(sc.DbTransaction, id)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "voting_id": id}).Error("getting voting")
+		return err
+	}
+	if !found {
+		return errNotFound
+	}
+	if voting.Status != model.VotingActive || voting.Deadline <= sc.currentTime() {
+		return errVotingClosed
+	}
+
+	var voters []int64
+	votes := make(map[string]bool)
+	if err = json.Unmarshal([]byte(voting.Voters), &voters); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling voters")
+		return err
+	}
+	if err = json.Unmarshal([]byte(voting.Votes), &votes); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling votes")
+		return err
+	}
+	var isVoter bool
+	for _, key := range voters {
+		isVoter = isVoter || key == sc.TxSmart.KeyID
+	}
+	if !isVoter {
+		return errVotingNotVoter
+	}
+	key := strconv.FormatInt(sc.TxSmart.KeyID, 10)
+	if _, ok := votes[key]; ok {
+		return errVotingVoted
+	}
+	votes[key] = decision
+
+	var accepted, rejected int64
+	for _, item := range votes {
+		if item {
+			accepted++
+		} else {
+			rejected++
+		}
+	}
+	status := int64(model.VotingActive)
+	var result string
+	if accepted >= voting.Threshold {
+		status = model.VotingAccepted
+		if result, err = sc.executeVoting(rt, voting.Subject); err != nil {
+			return err
+		}
+	} else if rejected > int64(len(voters))-voting.Threshold {
+		status = model.VotingRejected
+	}
+
+	votesData, err := json.Marshal(votes)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling votes")
+		return err
+	}
+	_, _, err = sc.selectiveLoggingAndUpd([]string{`votes`, `status`, `result`},
+		[]interface{}{string(votesData), status, result}, sc.tablePrefix()+`_`+votingsTable,
+		[]string{`id`}, []string{strconv.FormatInt(id, 10)}, !sc.VDE && sc.Rollback, false)
+	return err
+}
+
+// executeVoting calls the contract of the accepted voting
+func (sc *SmartContract) executeVoting(rt *script.RunTime, data string) (string, error) {
+	var subject votingSubject
+	dec := json.NewDecoder(bytes.NewBufferString(data))
+	dec.UseNumber()
+	if err := dec.Decode(&subject); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling voting subject")
+		return ``, err
+	}
+	contract := VMGetContract(sc.VM, subject.Contract, uint32(sc.TxSmart.EcosystemID))
+	if contract == nil {
+		return ``, errContractNotFound
+	}
+	params := make(map[string]interface{}, len(subject.Params))
+	if fields := contract.Block.Info.(*script.ContractInfo).Tx; fields != nil {
+		for _, field := range *fields {
+			val, ok := subject.Params[field.Name]
+			if !ok {
+				continue
+			}
+			par, err := votingParam(field.Type.String(), val)
+			if err != nil {
+				log.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "field": field.Name}).Error("converting voting param")
+				return ``, err
+			}
+			params[field.Name] = par
+		}
+	}
+	result, err := script.ExContract(rt, uint32(sc.TxSmart.EcosystemID), subject.Contract, params)
+	if err != nil || result == nil {
+		return ``, err
+	}
+	return fmt.Sprint(result), nil
+}
+
+// votingParam converts the value of the subject to the type of the data field of the contract
+func votingParam(fieldType string, val interface{}) (interface{}, error) {
+	str := fmt.Sprint(val)
+	switch fieldType {
+	case `int64`:
+		return strconv.ParseInt(str, 10, 64)
+	case `float64`:
+		return strconv.ParseFloat(str, 64)
+	case script.Decimal:
+		return decimal.NewFromString(str)
+	case `string`:
+		return str, nil
+	}
+	return val, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"fmt"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// writeFuncs are the embedded functions which write to the table of the first parameter
+var writeFuncs = []struct {
+	name   string
+	action string
+}{{`DBInsert`, `insert`}, {`DBUpdate`, `update`}, {`DBUpdateExt`, `update`}}
+
+// TableWrite is the write of the contract to the table which name is the string constant of the source
+type TableWrite struct {
+	Table  string `json:"table"`
+	Action string `json:"action"`
+}
+
+// ContractWrites returns the writes of the contract which tables are known from its source.
+// The writes to the tables which names are calculated at runtime aren't returned
+func (sc *SmartContract) ContractWrites() ([]TableWrite, error) {
+	owner := sc.TxContract.Block.Info.(*script.ContractInfo).Owner
+	row, err := model.GetOneRowTransaction(sc.DbTransaction, fmt.Sprintf(`SELECT value FROM "%d_contracts" WHERE id = ?`,
+		owner.StateID), owner.TableID).String()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "contract_id": owner.TableID}).Error("getting contract source")
+		return nil, err
+	}
+	writes := make([]TableWrite, 0)
+	known := make(map[TableWrite]bool)
+	for _, item := range writeFuncs {
+		tables, err := script.CallFirstStrings([]rune(row[`value`]), item.name)
+		if err != nil {
+			return nil, err
+		}
+		for _, table := range tables {
+			write := TableWrite{Table: getDefTableName(sc, table), Action: item.action}
+			if !known[write] {
+				known[write] = true
+				writes = append(writes, write)
+			}
+		}
+	}
+	return writes, nil
+}
@@ -10,6 +10,15 @@ import (
 const (
 	Count = ".count"
 	Time  = ".time"
+	Depth = ".depth"
+	Size  = ".size"
+
+	// ReorgCounterName is the name of the metric of blockchain reorganizations
+	ReorgCounterName = "blockchain.reorg"
+	// ForkCounterName is the name of the metric of the own blocks which aren't confirmed by the quorum
+	ForkCounterName = "confirmations.fork"
+	// ArchiveCounterName is the name of the metric of the index of the row states of the archive node
+	ArchiveCounterName = "archive.rows"
 )
 
 var Client statsd.Statter
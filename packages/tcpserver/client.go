@@ -0,0 +1,131 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package tcpserver
+
+import (
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Request sends the request to the host and reads the first response.
+// The request is repeated without frames if the host doesn't support the framed protocol
+func Request(host string, reqType uint16, req, resp interface{}) (MessageConn, error) {
+	conn, err := utils.TCPConn(host)
+	if err != nil {
+		return nil, err
+	}
+	c := NewConn(conn, reqType)
+	if err = c.WriteMessage(req); err == nil {
+		if err = c.ReadMessage(resp); err == nil {
+			return c, nil
+		}
+	}
+	conn.Close()
+	if err != ErrLegacyPeer {
+		log.WithFields(log.Fields{"type": consts.ProtocolError, "error": err, "host": host, "request_type": reqType}).Error("framed request")
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{"host": host, "request_type": reqType}).Debug("falling back to legacy protocol")
+	if conn, err = utils.TCPConn(host); err != nil {
+		return nil, err
+	}
+	lc := &legacyConn{conn}
+	if err = SendRequestType(int64(reqType), conn); err == nil {
+		if err = lc.WriteMessage(req); err == nil {
+			if err = lc.ReadMessage(resp); err == nil {
+				return lc, nil
+			}
+		}
+	}
+	conn.Close()
+	log.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host, "request_type": reqType}).Error("legacy request")
+	return nil, err
+}
+
+// GetConfirmation returns the hash of the block on the host
+func GetConfirmation(host string, blockID int64) (*ConfirmResponse, error) {
+	resp := &ConfirmResponse{}
+	conn, err := Request(host, RequestTypeConfirmation, &ConfirmRequest{BlockID: uint32(blockID)}, resp)
+	if err != nil {
+		return nil, err
+	}
+	conn.Close()
+	return resp, nil
+}
+
+// SendTransactions sends the full transactions to the host
+func SendTransactions(host string, data []byte) error {
+	conn, err := Request(host, RequestTypeNotFullNode, &DisRequest{Data: data}, &DisTrResponse{})
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// SendHashes sends the hashes of the block and transactions to the host,
+// getTxs returns the transactions for the hashes that are requested by the host
+func SendHashes(host string, data []byte, getTxs func(need []byte) ([]byte, error)) error {
+	resp := &DisHashResponse{}
+	conn, err := Request(host, RequestTypeFullNode, &DisRequest{Data: data}, resp)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if len(resp.Data) == 0 {
+		return nil
+	}
+	txs, err := getTxs(resp.Data)
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(&DisRequest{Data: txs})
+}
+
+// GetBlocksBody is retrieving `blocksCount` blocks bodies starting with blockID and puts them in the channel
+func GetBlocksBody(host string, blockID int64, blocksCount int32, reverseOrder bool) (chan []byte, error) {
+	req := &GetBodiesRequest{BlockID: uint32(blockID), ReverseOrder: reverseOrder}
+	resp := &GetBodyResponse{}
+	conn, err := Request(host, RequestTypeBlockCollection, req, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	rawBlocksCh := make(chan []byte, blocksCount)
+	go func() {
+		defer func() {
+			close(rawBlocksCh)
+			conn.Close()
+		}()
+
+		for len(resp.Data) > 0 {
+			rawBlocksCh <- resp.Data
+
+			resp = &GetBodyResponse{}
+			if err := conn.ReadMessage(resp); err != nil {
+				if _, ok := conn.(*Conn); ok {
+					log.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Error("reading block data")
+				}
+				return
+			}
+		}
+	}()
+	return rawBlocksCh, nil
+}
@@ -0,0 +1,258 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package tcpserver
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// FrameMarker starts every frame, it doesn't match any legacy request type
+	FrameMarker = 0x4746
+	// ProtocolVersion is the latest version of the framed protocol
+	ProtocolVersion = 1
+	// MaxPayloadSize is the max size of the frame payload
+	MaxPayloadSize = 10485760
+
+	frameHeaderSize = 9
+	maxStopCertSize = 65536
+)
+
+var (
+	// ErrLegacyPeer is returned if the host closes the connection without the framed response
+	ErrLegacyPeer = errors.New("host doesn't support framed protocol")
+
+	errFrameMarker   = errors.New("bad frame marker")
+	errFrameVersion  = errors.New("unsupported protocol version")
+	errFrameType     = errors.New("unexpected frame type")
+	errFrameSize     = errors.New("frame payload exceeds limit")
+	errFrameTrailing = errors.New("frame payload has trailing bytes")
+)
+
+// FrameHeader precedes the payload of every frame
+type FrameHeader struct {
+	Marker  uint16
+	Version uint8
+	Type    uint16
+	Length  uint32
+}
+
+type frameLimit struct {
+	request  uint32
+	response uint32
+}
+
+// frameLimits contains max payload sizes of requests and responses for each type
+var frameLimits = map[uint16]frameLimit{
+	RequestTypeFullNode:        {MaxPayloadSize, MaxPayloadSize},
+	RequestTypeNotFullNode:     {MaxPayloadSize, 0},
+	RequestTypeStopNetwork:     {4 + maxStopCertSize, 4 + consts.HashSize},
+	RequestTypeConfirmation:    {4, 1 + consts.HashSize},
+	RequestTypeBlockCollection: {5, MaxPayloadSize},
+	RequestTypeMaxBlock:        {0, 4},
+}
+
+// ReadFrame reads the frame and checks its header before the payload is read
+func ReadFrame(r io.Reader, response bool) (*FrameHeader, []byte, error) {
+	head := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, nil, err
+	}
+
+	h := &FrameHeader{}
+	if err := ReadRequest(h, bytes.NewReader(head)); err != nil {
+		return nil, nil, err
+	}
+	if h.Marker != FrameMarker {
+		return nil, nil, errFrameMarker
+	}
+	if h.Version == 0 {
+		return nil, nil, errFrameVersion
+	}
+	limit, ok := frameLimits[h.Type]
+	if !ok {
+		return nil, nil, errFrameType
+	}
+	max := limit.request
+	if response {
+		max = limit.response
+	}
+	if h.Length > max {
+		log.WithFields(log.Fields{"type": consts.ParameterExceeded, "request_type": h.Type, "size": h.Length, "max_size": max}).Error("frame payload exceeds limit")
+		return nil, nil, errFrameSize
+	}
+
+	// the buffer grows as the data is actually received
+	size := h.Length
+	if size > readChunkSize {
+		size = readChunkSize
+	}
+	payload := bytes.NewBuffer(make([]byte, 0, size))
+	if _, err := io.CopyN(payload, r, int64(h.Length)); err != nil {
+		return nil, nil, err
+	}
+	return h, payload.Bytes(), nil
+}
+
+// WriteFrame writes the header and the payload of the frame
+func WriteFrame(w io.Writer, h *FrameHeader, payload []byte) error {
+	h.Marker = FrameMarker
+	h.Length = uint32(len(payload))
+
+	var buf bytes.Buffer
+	if err := SendRequest(h, &buf); err != nil {
+		return err
+	}
+	buf.Write(payload)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// MessageConn reads and writes the messages of the request
+type MessageConn interface {
+	net.Conn
+	ReadMessage(msg interface{}) error
+	WriteMessage(msg interface{}) error
+}
+
+// legacyConn sends the messages without frames
+type legacyConn struct {
+	net.Conn
+}
+
+func (c *legacyConn) ReadMessage(msg interface{}) error {
+	return ReadRequest(msg, c.Conn)
+}
+
+func (c *legacyConn) WriteMessage(msg interface{}) error {
+	return SendRequest(msg, c.Conn)
+}
+
+// Conn sends the messages in frames of the same type
+type Conn struct {
+	net.Conn
+	Type    uint16
+	Version uint8
+	Timeout time.Duration
+
+	r          io.Reader
+	server     bool
+	negotiated bool
+	pending    []byte
+}
+
+// NewConn returns the client side of the framed request
+func NewConn(conn net.Conn, reqType uint16) *Conn {
+	return &Conn{
+		Conn:    conn,
+		Type:    reqType,
+		Version: ProtocolVersion,
+		Timeout: consts.READ_TIMEOUT * time.Second,
+		r:       conn,
+	}
+}
+
+// acceptConn reads the first frame of the request whose marker has been already read
+func acceptConn(conn net.Conn) (*Conn, error) {
+	c := &Conn{
+		Conn:    conn,
+		Timeout: consts.READ_TIMEOUT * time.Second,
+		r:       io.MultiReader(bytes.NewReader([]byte{FrameMarker >> 8, FrameMarker & 0xff}), conn),
+		server:  true,
+	}
+	h, payload, err := c.readFrame()
+	if err != nil {
+		return nil, err
+	}
+	c.pending = payload
+	c.Type = h.Type
+	return c, nil
+}
+
+func (c *Conn) readFrame() (*FrameHeader, []byte, error) {
+	c.SetReadDeadline(time.Now().Add(c.Timeout))
+	h, payload, err := ReadFrame(c.r, !c.server)
+	if err != nil {
+		if !c.server && !c.negotiated && (err == io.EOF || errors.Is(err, syscall.ECONNRESET)) {
+			return nil, nil, ErrLegacyPeer
+		}
+		return nil, nil, err
+	}
+
+	if !c.negotiated {
+		// the server answers with the highest version that is supported by both sides
+		if c.server {
+			c.Version = h.Version
+			if c.Version > ProtocolVersion {
+				c.Version = ProtocolVersion
+			}
+		} else {
+			if h.Version > c.Version {
+				return nil, nil, errFrameVersion
+			}
+			c.Version = h.Version
+		}
+		c.negotiated = true
+	} else if h.Version != c.Version {
+		return nil, nil, errFrameVersion
+	}
+	if c.Type != 0 && h.Type != c.Type {
+		return nil, nil, errFrameType
+	}
+	return h, payload, nil
+}
+
+// ReadMessage reads the next frame and decodes its payload to msg
+func (c *Conn) ReadMessage(msg interface{}) error {
+	payload := c.pending
+	if payload == nil {
+		var err error
+		if _, payload, err = c.readFrame(); err != nil {
+			return err
+		}
+	}
+	c.pending = nil
+
+	r := bytes.NewReader(payload)
+	if err := ReadRequest(msg, r); err != nil {
+		return err
+	}
+	if r.Len() > 0 {
+		log.WithFields(log.Fields{"type": consts.ProtocolError, "request_type": c.Type, "len": r.Len()}).Error("frame payload has trailing bytes")
+		return errFrameTrailing
+	}
+	return nil
+}
+
+// WriteMessage writes msg as a single frame
+func (c *Conn) WriteMessage(msg interface{}) error {
+	var payload bytes.Buffer
+	if err := SendRequest(msg, &payload); err != nil {
+		return err
+	}
+	c.SetWriteDeadline(time.Now().Add(consts.WRITE_TIMEOUT * time.Second))
+	return WriteFrame(c.Conn, &FrameHeader{Version: c.Version, Type: c.Type}, payload.Bytes())
+}
@@ -0,0 +1,210 @@
+package tcpserver
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func frameBytes(t *testing.T, h *FrameHeader, payload []byte) []byte {
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteFrame(buf, h, payload))
+	return buf.Bytes()
+}
+
+func TestReadFrame(t *testing.T) {
+	data := frameBytes(t, &FrameHeader{Version: ProtocolVersion, Type: RequestTypeConfirmation}, []byte{0, 0, 0, 5})
+	h, payload, err := ReadFrame(bytes.NewReader(data), false)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(FrameMarker), h.Marker)
+	assert.Equal(t, uint16(RequestTypeConfirmation), h.Type)
+	assert.Equal(t, []byte{0, 0, 0, 5}, payload)
+
+	cases := []struct {
+		data []byte
+		err  error
+	}{
+		{frameBytes(t, &FrameHeader{Version: 0, Type: RequestTypeConfirmation}, nil), errFrameVersion},
+		{frameBytes(t, &FrameHeader{Version: 1, Type: 99}, nil), errFrameType},
+		{frameBytes(t, &FrameHeader{Version: 1, Type: RequestTypeConfirmation}, make([]byte, 5)), errFrameSize},
+		{frameBytes(t, &FrameHeader{Version: 1, Type: RequestTypeMaxBlock}, []byte{1}), errFrameSize},
+		{append([]byte{0, 4}, data[2:]...), errFrameMarker},
+	}
+	for _, v := range cases {
+		_, _, err := ReadFrame(bytes.NewReader(v.data), false)
+		assert.Equal(t, v.err, err)
+	}
+
+	// the announced length is checked before the payload is read
+	huge := []byte{FrameMarker >> 8, FrameMarker & 0xff, 1, 0, RequestTypeBlockCollection, 0xff, 0xff, 0xff, 0xff}
+	_, _, err = ReadFrame(bytes.NewReader(huge), true)
+	assert.Equal(t, errFrameSize, err)
+}
+
+func TestReadBytesLimit(t *testing.T) {
+	// the slice length can't exceed the rest of the payload
+	r := bytes.NewReader([]byte{0, 0x9f, 0xff, 0xff, 1, 2})
+	assert.Error(t, ReadRequest(&GetBodyResponse{}, r))
+}
+
+func TestConnExchange(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		dType := &RequestType{}
+		if err := ReadRequest(dType, server); err != nil {
+			done <- err
+			return
+		}
+		assert.Equal(t, uint16(FrameMarker), dType.Type)
+
+		c, err := acceptConn(server)
+		if err != nil {
+			done <- err
+			return
+		}
+		assert.Equal(t, uint16(RequestTypeBlockCollection), c.Type)
+		assert.Equal(t, uint8(ProtocolVersion), c.Version)
+
+		req := &GetBodiesRequest{}
+		if err = c.ReadMessage(req); err != nil {
+			done <- err
+			return
+		}
+		assert.Equal(t, uint32(10), req.BlockID)
+		assert.True(t, req.ReverseOrder)
+		for _, b := range []string{"first", "second", ""} {
+			if err = c.WriteMessage(&GetBodyResponse{Data: []byte(b)}); err != nil {
+				break
+			}
+		}
+		done <- err
+	}()
+
+	c := NewConn(client, RequestTypeBlockCollection)
+	require.NoError(t, c.WriteMessage(&GetBodiesRequest{BlockID: 10, ReverseOrder: true}))
+	var blocks []string
+	for {
+		resp := &GetBodyResponse{}
+		require.NoError(t, c.ReadMessage(resp))
+		if len(resp.Data) == 0 {
+			break
+		}
+		blocks = append(blocks, string(resp.Data))
+	}
+	require.NoError(t, <-done)
+	assert.Equal(t, []string{"first", "second"}, blocks)
+}
+
+func TestConnNegotiation(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		// the request of the newer client
+		WriteFrame(client, &FrameHeader{Version: ProtocolVersion + 1, Type: RequestTypeMaxBlock}, nil)
+	}()
+	ReadRequest(&RequestType{}, server)
+	c, err := acceptConn(server)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(ProtocolVersion), c.Version)
+	require.NoError(t, c.ReadMessage(&MaxBlockRequest{}))
+
+	// the client rejects the version which is higher than its own
+	cc := NewConn(client, RequestTypeMaxBlock)
+	cc.Version = 1
+	go WriteFrame(server, &FrameHeader{Version: 2, Type: RequestTypeMaxBlock}, []byte{0, 0, 0, 1})
+	assert.Equal(t, errFrameVersion, cc.ReadMessage(&MaxBlockResponse{}))
+}
+
+func TestConnTrailing(t *testing.T) {
+	cases := []struct {
+		h       *FrameHeader
+		payload []byte
+		msg     interface{}
+		err     error
+	}{
+		{&FrameHeader{Version: 1, Type: RequestTypeConfirmation}, make([]byte, 34), &ConfirmResponse{}, errFrameSize},
+		{&FrameHeader{Version: 1, Type: RequestTypeStopNetwork}, []byte{0, 0, 0, 1, 1, 2}, &StopNetworkResponse{}, errFrameTrailing},
+		{&FrameHeader{Version: 1, Type: RequestTypeMaxBlock}, []byte{0, 0, 1}, &MaxBlockResponse{}, nil},
+	}
+	for _, v := range cases {
+		client, server := net.Pipe()
+		go WriteFrame(server, v.h, v.payload)
+		err := NewConn(client, v.h.Type).ReadMessage(v.msg)
+		if v.err == nil {
+			assert.Error(t, err)
+		} else {
+			assert.Equal(t, v.err, err)
+		}
+		client.Close()
+		server.Close()
+	}
+}
+
+func TestRequestLegacy(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	hash := bytes.Repeat([]byte{7}, 32)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			// the legacy server closes the connection of unknown request types
+			dType := &RequestType{}
+			if ReadRequest(dType, conn) == nil && dType.Type == RequestTypeConfirmation {
+				req := &ConfirmRequest{}
+				if ReadRequest(req, conn) == nil && req.BlockID == 3 {
+					SendRequest(&ConfirmResponse{ConfType: 1, Hash: hash}, conn)
+				}
+			}
+			conn.Close()
+		}
+	}()
+
+	resp, err := GetConfirmation(l.Addr().String(), 3)
+	require.NoError(t, err)
+	assert.Equal(t, hash, resp.Hash)
+}
+
+func FuzzReadFrame(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{FrameMarker >> 8, FrameMarker & 0xff, 1, 0, RequestTypeConfirmation, 0, 0, 0, 4, 0, 0, 0, 1})
+	f.Add([]byte{FrameMarker >> 8, FrameMarker & 0xff, 1, 0, RequestTypeFullNode, 0, 0, 0, 8, 0, 0, 0, 4, 1, 2, 3, 4})
+	f.Add([]byte{FrameMarker >> 8, FrameMarker & 0xff, 1, 0, RequestTypeBlockCollection, 0xff, 0xff, 0xff, 0xff})
+	f.Add([]byte{FrameMarker >> 8, FrameMarker & 0xff, 1, 0, RequestTypeStopNetwork, 0, 0, 0, 4, 0xff, 0xff, 0xff, 0xff})
+
+	messages := map[uint16][2]func() interface{}{
+		RequestTypeFullNode:        {func() interface{} { return &DisRequest{} }, func() interface{} { return &DisHashResponse{} }},
+		RequestTypeNotFullNode:     {func() interface{} { return &DisRequest{} }, func() interface{} { return &DisTrResponse{} }},
+		RequestTypeStopNetwork:     {func() interface{} { return &StopNetworkRequest{} }, func() interface{} { return &StopNetworkResponse{} }},
+		RequestTypeConfirmation:    {func() interface{} { return &ConfirmRequest{} }, func() interface{} { return &ConfirmResponse{} }},
+		RequestTypeBlockCollection: {func() interface{} { return &GetBodiesRequest{} }, func() interface{} { return &GetBodyResponse{} }},
+		RequestTypeMaxBlock:        {func() interface{} { return &MaxBlockRequest{} }, func() interface{} { return &MaxBlockResponse{} }},
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for i, response := range []bool{false, true} {
+			h, payload, err := ReadFrame(bytes.NewReader(data), response)
+			if err != nil {
+				continue
+			}
+			limit := frameLimits[h.Type]
+			if (!response && h.Length > limit.request) || (response && h.Length > limit.response) {
+				t.Fatalf("frame of %d bytes exceeds limit", h.Length)
+			}
+			ReadRequest(messages[h.Type][i](), bytes.NewReader(payload))
+		}
+	})
+}
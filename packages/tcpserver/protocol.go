@@ -1,6 +1,7 @@
 package tcpserver
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -24,6 +25,9 @@ const (
 	RequestTypeMaxBlock        = 10
 )
 
+// readChunkSize is the size of the values that are allocated at once
+const readChunkSize = 65536
+
 // RequestType is type of request
 type RequestType struct {
 	Type uint16
@@ -179,11 +183,9 @@ func SendRequest(request interface{}, w io.Writer) error {
 			}
 
 		case reflect.Bool:
-			var bs []byte
+			bs := []byte{0}
 			if t.Bool() {
-				bs = []byte("1")
-			} else {
-				bs = []byte("0")
+				bs[0] = 1
 			}
 			_, err := w.Write(bs)
 			if err != nil {
@@ -204,17 +206,30 @@ func readUint(r io.Reader, byteCount int) (uint64, error) {
 }
 
 func readBytes(r io.Reader, size uint64) ([]byte, error) {
-	var maxSize uint64 = 10485760
-	if size > maxSize { // TODO
+	var maxSize uint64 = MaxPayloadSize
+	if l, ok := r.(interface{ Len() int }); ok && uint64(l.Len()) < maxSize {
+		maxSize = uint64(l.Len())
+	}
+	if size > maxSize {
 		log.WithFields(log.Fields{"size": size, "max_size": maxSize, "type": consts.ParameterExceeded}).Error("bytes size to read exceeds max allowed size")
 		return nil, errors.New("bad size")
 	}
-	value := make([]byte, int(size))
-	_, err := io.ReadFull(r, value)
-	if err != nil {
+	if size <= readChunkSize {
+		value := make([]byte, int(size))
+		_, err := io.ReadFull(r, value)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "type": consts.IOError}).Error("cannot read bytes")
+		}
+		return value, err
+	}
+
+	// large values are allocated progressively as the data is received
+	buf := bytes.NewBuffer(make([]byte, 0, readChunkSize))
+	if _, err := io.CopyN(buf, r, int64(size)); err != nil {
 		log.WithFields(log.Fields{"error": err, "type": consts.IOError}).Error("cannot read bytes")
+		return nil, err
 	}
-	return value, err
+	return buf.Bytes(), nil
 }
 
 func readSliceSize(r io.Reader, tagSize string) (size uint64, err error) {
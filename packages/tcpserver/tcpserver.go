@@ -45,6 +45,8 @@ func HandleTCPRequest(rw net.Conn) {
 		return
 	}
 
+	rw.SetReadDeadline(time.Now().Add(consts.READ_TIMEOUT * time.Second))
+
 	dType := &RequestType{}
 	err := ReadRequest(dType, rw)
 	if err != nil {
@@ -52,6 +54,16 @@ func HandleTCPRequest(rw net.Conn) {
 		return
 	}
 
+	var conn MessageConn = &legacyConn{rw}
+	if dType.Type == FrameMarker {
+		fc, err := acceptConn(rw)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.ProtocolError, "error": err}).Error("reading first frame")
+			return
+		}
+		dType.Type, conn = fc.Type, fc
+	}
+
 	log.WithFields(log.Fields{"request_type": dType.Type}).Debug("tcpserver got request type")
 	var response interface{}
 
@@ -60,18 +72,18 @@ func HandleTCPRequest(rw net.Conn) {
 		if service.IsNodePaused() {
 			return
 		}
-		err = Type1(rw)
+		err = Type1(conn)
 
 	case RequestTypeNotFullNode:
 		if service.IsNodePaused() {
 			return
 		}
-		response, err = Type2(rw)
+		response, err = Type2(conn)
 
 	case RequestTypeStopNetwork:
 		req := &StopNetworkRequest{}
-		if err = ReadRequest(req, rw); err == nil {
-			err = Type3(req, rw)
+		if err = conn.ReadMessage(req); err == nil {
+			err = Type3(req, conn)
 		}
 
 	case RequestTypeConfirmation:
@@ -79,20 +91,22 @@ func HandleTCPRequest(rw net.Conn) {
 			return
 		}
 		req := &ConfirmRequest{}
-		err = ReadRequest(req, rw)
+		err = conn.ReadMessage(req)
 		if err == nil {
 			response, err = Type4(req)
 		}
 
 	case RequestTypeBlockCollection:
 		req := &GetBodiesRequest{}
-		err = ReadRequest(req, rw)
+		err = conn.ReadMessage(req)
 		if err == nil {
-			err = Type7(req, rw)
+			err = Type7(req, conn)
 		}
 
 	case RequestTypeMaxBlock:
-		response, err = Type10()
+		if err = conn.ReadMessage(&MaxBlockRequest{}); err == nil {
+			response, err = Type10()
+		}
 	}
 
 	if err != nil || response == nil {
@@ -100,7 +114,7 @@ func HandleTCPRequest(rw net.Conn) {
 	}
 
 	log.WithFields(log.Fields{"response": response, "request_type": dType.Type}).Debug("tcpserver responded")
-	err = SendRequest(response, rw)
+	err = conn.WriteMessage(response)
 	if err != nil {
 		log.Errorf("tcpserver handle error: %s", err)
 	}
@@ -19,7 +19,6 @@ package tcpserver
 import (
 	"bytes"
 	"errors"
-	"io"
 
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
@@ -35,9 +34,9 @@ import (
 // Type1 get the list of transactions which belong to the sender from 'disseminator' daemon
 // do not load the blocks here because here could be the chain of blocks that are loaded for a long time
 // download the transactions here, because they are small and definitely will be downloaded in 60 sec
-func Type1(rw io.ReadWriter) error {
+func Type1(rw MessageConn) error {
 	r := &DisRequest{}
-	if err := ReadRequest(r, rw); err != nil {
+	if err := rw.ReadMessage(r); err != nil {
 		return err
 	}
 
@@ -85,7 +84,7 @@ func Type1(rw io.ReadWriter) error {
 	}
 
 	// send the list of transactions which we want to get
-	err = SendRequest(&DisHashResponse{Data: needTx}, rw)
+	err = rw.WriteMessage(&DisHashResponse{Data: needTx})
 	if err != nil {
 		return err
 	}
@@ -96,7 +95,7 @@ func Type1(rw io.ReadWriter) error {
 
 	// get this new transactions
 	trs := &DisRequest{}
-	err = ReadRequest(trs, rw)
+	err = rw.ReadMessage(trs)
 	if err != nil {
 		return err
 	}
@@ -162,7 +161,7 @@ func getUnknownTransactions(buf *bytes.Buffer) ([]byte, error) {
 			return nil, utils.ErrInfo(err)
 		}
 		if exists > 0 {
-			log.WithFields(log.Fields{"txHash": newDataTxHash, "type": consts.DuplicateObject}).Warning("tx with this hash already exists in log_tx")
+			log.WithFields(log.Fields{"txHash": newDataTxHash, "type": consts.DuplicateObject}).Debug("tx with this hash already exists in log_tx")
 			continue
 		}
 
@@ -172,7 +171,7 @@ func getUnknownTransactions(buf *bytes.Buffer) ([]byte, error) {
 			return nil, utils.ErrInfo(err)
 		}
 		if exists > 0 {
-			log.WithFields(log.Fields{"txHash": newDataTxHash, "type": consts.DuplicateObject}).Warning("tx with this hash already exists in tx")
+			log.WithFields(log.Fields{"txHash": newDataTxHash, "type": consts.DuplicateObject}).Debug("tx with this hash already exists in tx")
 			continue
 		}
 
@@ -183,7 +182,7 @@ func getUnknownTransactions(buf *bytes.Buffer) ([]byte, error) {
 			return nil, utils.ErrInfo(err)
 		}
 		if exists > 0 {
-			log.WithFields(log.Fields{"txHash": newDataTxHash, "type": consts.DuplicateObject}).Warning("tx with this hash already exists in queue_tx")
+			log.WithFields(log.Fields{"txHash": newDataTxHash, "type": consts.DuplicateObject}).Debug("tx with this hash already exists in queue_tx")
 			continue
 		}
 		needTx = append(needTx, newDataTxHash...)
@@ -223,6 +222,16 @@ func saveNewTransactions(r *DisRequest) error {
 		if err != nil {
 			log.WithFields(log.Fields{"type": consts.CryptoError, "error": err, "value": txBinData}).Fatal("cannot hash bindata")
 		}
+		// the transaction can be sent to the node by the client while it's requested from the other node
+		exists, err := model.TxExists(hash)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "txHash": hash}).Error("checking existing tx")
+			return err
+		}
+		if exists {
+			log.WithFields(log.Fields{"txHash": hash, "type": consts.DuplicateObject}).Debug("tx with this hash already exists")
+			continue
+		}
 
 		queue = append(queue, &model.QueueTx{Hash: hash, Data: txBinData, FromGate: 1})
 	}
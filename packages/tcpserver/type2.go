@@ -21,7 +21,6 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
-	"io"
 
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
@@ -34,9 +33,9 @@ import (
 )
 
 // Type2 serves requests from disseminator
-func Type2(rw io.ReadWriter) (*DisTrResponse, error) {
+func Type2(rw MessageConn) (*DisTrResponse, error) {
 	r := &DisRequest{}
-	if err := ReadRequest(r, rw); err != nil {
+	if err := rw.ReadMessage(r); err != nil {
 		return nil, err
 	}
 
@@ -2,7 +2,6 @@ package tcpserver
 
 import (
 	"errors"
-	"net"
 	"time"
 
 	"github.com/GenesisKernel/go-genesis/packages/conf"
@@ -19,14 +18,14 @@ import (
 var errStopCertAlreadyUsed = errors.New("Stop certificate is already used")
 
 // Type3
-func Type3(req *StopNetworkRequest, w net.Conn) error {
+func Type3(req *StopNetworkRequest, w MessageConn) error {
 	hash, err := processStopNetwork(req.Data)
 	if err != nil {
 		return err
 	}
 
 	res := &StopNetworkResponse{hash}
-	if err = SendRequest(res, w); err != nil {
+	if err = w.WriteMessage(res); err != nil {
 		log.WithFields(log.Fields{"error": err, "type": consts.NetworkError}).Error("sending response")
 		return err
 	}
@@ -56,6 +55,8 @@ func processStopNetwork(b []byte) ([]byte, error) {
 		log.WithFields(log.Fields{"error": err, "type": consts.InvalidObject}).Error("validating cert")
 		return nil, err
 	}
+	info := cert.StopInfo()
+	log.WithFields(log.Fields{"reason": info.Reason, "stop_time": info.Time}).Warn("received stop network certificate")
 
 	var data []byte
 	_, err = converter.BinMarshal(&data,
@@ -17,8 +17,6 @@
 package tcpserver
 
 import (
-	"net"
-
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/model"
 
@@ -30,7 +28,7 @@ const BlocksPerRequest int32 = 1000
 
 // Type7 writes the body of the specified block
 // blocksCollection and queue_parser_blocks daemons send the request through p.GetBlocks()
-func Type7(request *GetBodiesRequest, w net.Conn) error {
+func Type7(request *GetBodiesRequest, w MessageConn) error {
 	block := &model.Block{}
 
 	var blocks []model.Block
@@ -47,14 +45,17 @@ func Type7(request *GetBodiesRequest, w net.Conn) error {
 
 	if len(blocks) == 0 {
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": request.BlockID}).Warn("Requesting nonexistent blocks from block_id")
-		return err
 	}
 
 	for _, b := range blocks {
-		if err := SendRequest(&GetBodyResponse{Data: b.Data}, w); err != nil {
+		if err := w.WriteMessage(&GetBodyResponse{Data: b.Data}); err != nil {
 			return err
 		}
 	}
 
+	// the framed response is finished by the empty body
+	if _, ok := w.(*Conn); ok {
+		return w.WriteMessage(&GetBodyResponse{})
+	}
 	return nil
 }
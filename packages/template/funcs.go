@@ -208,6 +208,14 @@ func moneyTag(par parFunc) string {
 	}
 	if len((*par.Pars)[`Digit`]) > 0 {
 		cents = converter.StrToInt(macro((*par.Pars)[`Digit`], par.Workspace.Vars))
+	} else if !par.Workspace.SmartContract.VDE {
+		// the token of the ecosystem has the decimals which are set by the founder
+		var err error
+		cents, err = model.GetTokenDecimals(par.Workspace.SmartContract.TxSmart.EcosystemID)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting token decimals")
+			return `unknown money_digit`
+		}
 	} else {
 		prefix := (*par.Workspace.Vars)[`ecosystem_id`]
 		sp := &model.StateParameter{}
@@ -655,7 +663,7 @@ func dbfindTag(par parFunc) string {
 		(*par.Workspace.Vars)[par.Node.Attr[`countvar`].(string)] = countStr
 		delete(par.Node.Attr, `countvar`)
 	}
-	list, err := model.GetAll(`select `+fields+` from "`+tblname+`"`+where+order+offset, limit)
+	list, err := model.GetRows(nil, `select `+fields+` from "`+tblname+`"`+where+order+offset, limit)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting all from db")
 		return err.Error()
@@ -687,9 +695,6 @@ func dbfindTag(par parFunc) string {
 			var ival string
 			if i < defcol {
 				ival = item[icol]
-				if ival == `NULL` {
-					ival = ``
-				}
 
 				switch extendedColumns[icol] {
 				case columnTypeBlob:
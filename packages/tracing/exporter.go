@@ -0,0 +1,135 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultServiceName = "go-genesis"
+	exportQueueSize    = 1000
+	exportBatchSize    = 100
+	exportInterval     = time.Second
+	exportTimeout      = 5 * time.Second
+)
+
+// zipkinSpan is the span in the format of Zipkin API v2
+type zipkinSpan struct {
+	TraceID       string            `json:"traceId"`
+	ID            string            `json:"id"`
+	ParentID      string            `json:"parentId,omitempty"`
+	Name          string            `json:"name"`
+	Timestamp     int64             `json:"timestamp"`
+	Duration      int64             `json:"duration"`
+	LocalEndpoint map[string]string `json:"localEndpoint"`
+	Tags          map[string]string `json:"tags,omitempty"`
+}
+
+type exporter struct {
+	endpoint    string
+	serviceName string
+	queue       chan zipkinSpan
+	client      *http.Client
+}
+
+var (
+	exp      *exporter
+	expMutex sync.RWMutex
+)
+
+func getExporter() *exporter {
+	expMutex.RLock()
+	defer expMutex.RUnlock()
+	return exp
+}
+
+// Init starts the exporter of spans if the endpoint is configured
+func Init(cfg conf.TracingConfig) {
+	if len(cfg.Endpoint) == 0 {
+		return
+	}
+	serviceName := cfg.ServiceName
+	if len(serviceName) == 0 {
+		serviceName = defaultServiceName
+	}
+
+	e := &exporter{
+		endpoint:    cfg.Endpoint,
+		serviceName: serviceName,
+		queue:       make(chan zipkinSpan, exportQueueSize),
+		client:      &http.Client{Timeout: exportTimeout},
+	}
+	go e.run()
+
+	expMutex.Lock()
+	exp = e
+	expMutex.Unlock()
+	log.WithFields(log.Fields{"endpoint": cfg.Endpoint}).Info("tracing is enabled")
+}
+
+func (e *exporter) add(s *Span, duration time.Duration) {
+	span := zipkinSpan{
+		TraceID:       s.traceID,
+		ID:            s.id,
+		ParentID:      s.parentID,
+		Name:          s.name,
+		Timestamp:     s.start.UnixNano() / int64(time.Microsecond),
+		Duration:      int64(duration / time.Microsecond),
+		LocalEndpoint: map[string]string{"serviceName": e.serviceName},
+		Tags:          s.tags,
+	}
+	// the block processing must not wait for the tracing backend, spans are dropped if the queue is full
+	select {
+	case e.queue <- span:
+	default:
+	}
+}
+
+func (e *exporter) run() {
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	batch := make([]zipkinSpan, 0, exportBatchSize)
+	for {
+		select {
+		case span := <-e.queue:
+			batch = append(batch, span)
+			if len(batch) < exportBatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+		if err := e.send(batch); err != nil {
+			log.WithFields(log.Fields{"type": consts.NetworkError, "error": err, "endpoint": e.endpoint}).Error("sending spans")
+		}
+		batch = batch[:0]
+	}
+}
+
+func (e *exporter) send(spans []zipkinSpan) error {
+	data, err := json.Marshal(spans)
+	if err != nil {
+		return err
+	}
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("tracing endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
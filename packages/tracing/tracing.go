@@ -0,0 +1,163 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/statsd"
+	"github.com/GenesisKernel/go-genesis/packages/utils/metric"
+)
+
+// Stages of the block processing
+const (
+	StageBlock         = "block"
+	StageParse         = "parse"
+	StageVerify        = "verify"
+	StageExecute       = "execute"
+	StageRollbackWrite = "rollback_write"
+	StageCommit        = "commit"
+)
+
+// TagContract is the tag of the span that contains the name of the executed contract
+const TagContract = "contract"
+
+const (
+	metricStageCount   = "block_stage_count"
+	metricStageTime    = "block_stage_time"
+	metricStageMaxTime = "block_stage_max_time"
+)
+
+// Span represents the timed stage of the block processing
+type Span struct {
+	traceID  string
+	id       string
+	parentID string
+	name     string
+	start    time.Time
+	tags     map[string]string
+}
+
+// StartSpan starts the root span of the new trace
+func StartSpan(name string) *Span {
+	return &Span{
+		traceID: newID(),
+		id:      newID(),
+		name:    name,
+		start:   time.Now(),
+	}
+}
+
+// StartChild starts the span that belongs to the same trace
+func (s *Span) StartChild(name string) *Span {
+	if s == nil {
+		return StartSpan(name)
+	}
+	return &Span{
+		traceID:  s.traceID,
+		id:       newID(),
+		parentID: s.id,
+		name:     name,
+		start:    time.Now(),
+	}
+}
+
+// SetTag sets the value of the tag
+func (s *Span) SetTag(key, value string) {
+	if s == nil {
+		return
+	}
+	if s.tags == nil {
+		s.tags = make(map[string]string)
+	}
+	s.tags[key] = value
+}
+
+// Finish records the duration of the span and sends the span to the exporter
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+	duration := time.Since(s.start)
+	Measure(s.name, duration)
+	if contract, ok := s.tags[TagContract]; ok {
+		Measure(s.name+"."+contract, duration)
+	}
+	if exp := getExporter(); exp != nil {
+		exp.add(s, duration)
+	}
+}
+
+// Measure records the duration of the stage without sending of the span
+func Measure(stage string, duration time.Duration) {
+	if statsd.Client != nil {
+		statsd.Client.TimingDuration(StageCounterName(stage), duration, 1.0)
+	}
+	stats.add(stage, duration)
+}
+
+// StageCounterName returns the name of the statsd metric of the stage
+func StageCounterName(stage string) string {
+	return "block.stage." + stage + statsd.Time
+}
+
+type stageStat struct {
+	count int64
+	total time.Duration
+	max   time.Duration
+}
+
+type stageStats struct {
+	mutex  sync.Mutex
+	stages map[string]*stageStat
+}
+
+var stats = &stageStats{stages: make(map[string]*stageStat)}
+
+func (ss *stageStats) add(stage string, duration time.Duration) {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	st, ok := ss.stages[stage]
+	if !ok {
+		st = &stageStat{}
+		ss.stages[stage] = st
+	}
+	st.count++
+	st.total += duration
+	if duration > st.max {
+		st.max = duration
+	}
+}
+
+// CollectStageMetrics returns the count and the durations of the stages in microseconds
+func CollectStageMetrics() ([]*metric.Value, error) {
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+
+	names := make([]string, 0, len(stats.stages))
+	for name := range stats.stages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := time.Now().Unix()
+	values := make([]*metric.Value, 0, len(names)*3)
+	for _, name := range names {
+		st := stats.stages[name]
+		values = append(values,
+			&metric.Value{Time: now, Metric: metricStageCount, Key: name, Value: st.count},
+			&metric.Value{Time: now, Metric: metricStageTime, Key: name, Value: int64(st.total / time.Microsecond)},
+			&metric.Value{Time: now, Metric: metricStageMaxTime, Key: name, Value: int64(st.max / time.Microsecond)},
+		)
+	}
+	return values, nil
+}
+
+func newID() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
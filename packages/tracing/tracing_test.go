@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectStageMetrics(t *testing.T) {
+	stats = &stageStats{stages: make(map[string]*stageStat)}
+
+	Measure(StageVerify, 3*time.Millisecond)
+	Measure(StageVerify, time.Millisecond)
+
+	span := StartSpan(StageExecute)
+	span.SetTag(TagContract, "NewPage")
+	span.Finish()
+
+	values, err := CollectStageMetrics()
+	assert.NoError(t, err)
+
+	result := make(map[string]int64)
+	for _, v := range values {
+		result[v.Metric+"."+v.Key] = v.Value
+	}
+	assert.Equal(t, int64(2), result["block_stage_count.verify"])
+	assert.Equal(t, int64(4000), result["block_stage_time.verify"])
+	assert.Equal(t, int64(3000), result["block_stage_max_time.verify"])
+	assert.Equal(t, int64(1), result["block_stage_count.execute"])
+	assert.Equal(t, int64(1), result["block_stage_count.execute.NewPage"])
+}
+
+func TestExporter(t *testing.T) {
+	received := make(chan []zipkinSpan, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var spans []zipkinSpan
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&spans))
+		received <- spans
+	}))
+	defer server.Close()
+
+	Init(conf.TracingConfig{Endpoint: server.URL})
+	defer func() { exp = nil }()
+
+	root := StartSpan(StageBlock)
+	child := root.StartChild(StageCommit)
+	child.Finish()
+	root.Finish()
+
+	select {
+	case spans := <-received:
+		assert.Len(t, spans, 2)
+		assert.Equal(t, StageCommit, spans[0].Name)
+		assert.Equal(t, root.traceID, spans[0].TraceID)
+		assert.Equal(t, root.id, spans[0].ParentID)
+		assert.Equal(t, defaultServiceName, spans[1].LocalEndpoint["serviceName"])
+	case <-time.After(3 * exportInterval):
+		t.Fatal("spans weren't sent")
+	}
+}
@@ -72,7 +72,8 @@ func (t *FirstBlockTransaction) Action() error {
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting ecosystem param")
 		return err
 	}
-	amount := decimal.New(consts.FounderAmount, int32(converter.StrToInt64(sp.Value))).String()
+	amount := decimal.New(syspar.SysInt64Def(syspar.FounderAmount, consts.FounderAmount),
+		int32(converter.StrToInt64(sp.Value))).String()
 
 	commission := &model.SystemParameter{Name: `commission_wallet`}
 	if err = commission.SaveArray([][]string{{"1", converter.Int64ToStr(keyID)}}); err != nil {
@@ -65,9 +65,10 @@ func (t *StopNetworkTransaction) Action() error {
 	}
 
 	// Set the node in a pause state
-	service.PauseNodeActivity(service.PauseTypeStopingNetwork)
+	info := t.Cert.StopInfo()
+	service.StopNetwork(info.Reason, info.Time)
 
-	t.Logger.Warn(messageNetworkStopping)
+	t.Logger.WithFields(log.Fields{"reason": info.Reason, "stop_time": info.Time}).Warn(messageNetworkStopping)
 	return ErrNetworkStopping
 }
 
@@ -6,6 +6,7 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/crypto"
 	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/notificator"
 	"github.com/GenesisKernel/go-genesis/packages/utils"
 
 	log "github.com/sirupsen/logrus"
@@ -99,8 +100,8 @@ func MarkTransactionBad(dbTransaction *model.DbTransaction, hash []byte, errText
 		return nil
 	}
 	model.MarkTransactionUsed(dbTransaction, hash)
-	if len(errText) > 255 {
-		errText = errText[:255]
+	if len(errText) > consts.TxErrorSize {
+		errText = errText[:consts.TxErrorSize]
 	}
 
 	// set loglevel as error because default level setups to "error"
@@ -120,6 +121,10 @@ func MarkTransactionBad(dbTransaction *model.DbTransaction, hash []byte, errText
 			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("setting transaction status error")
 			return utils.ErrInfo(err)
 		}
+		// the changes of the db transaction are published by the block after the commit
+		if dbTransaction == nil {
+			notificator.PublishTxStatus(hash)
+		}
 	}
 	err = DeleteQueueTx(dbTransaction, hash)
 	if err != nil {
@@ -195,6 +200,12 @@ func ProcessQueueTransaction(dbTransaction *model.DbTransaction, hash, binaryTx
 	return nil
 }
 
+// processQueueTransaction checks the transaction of the queue, the panic is returned as PanicError
+func processQueueTransaction(dbTransaction *model.DbTransaction, hash, binaryTx []byte) (err error) {
+	defer recoverTx(hash, &err)
+	return ProcessQueueTransaction(dbTransaction, hash, binaryTx, false)
+}
+
 // AllTxParser parses new transactions
 func ProcessTransactionsQueue(dbTransaction *model.DbTransaction) error {
 	all, err := model.GetAllUnverifiedAndUnusedTransactions()
@@ -203,7 +214,12 @@ func ProcessTransactionsQueue(dbTransaction *model.DbTransaction) error {
 		return err
 	}
 	for _, data := range all {
-		err := ProcessQueueTransaction(dbTransaction, data.Hash, data.Data, false)
+		err := processQueueTransaction(dbTransaction, data.Hash, data.Data)
+		if _, ok := err.(*PanicError); ok {
+			// the panicking transaction doesn't stop the queue
+			MarkTransactionBad(dbTransaction, data.Hash, err.Error())
+			continue
+		}
 		if err != nil {
 			return utils.ErrInfo(err)
 		}
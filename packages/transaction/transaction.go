@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
+	"runtime/debug"
 	"strings"
 	"time"
 
+	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
 	"github.com/GenesisKernel/go-genesis/packages/crypto"
 	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/notificator"
 	"github.com/GenesisKernel/go-genesis/packages/script"
 	"github.com/GenesisKernel/go-genesis/packages/smart"
 	"github.com/GenesisKernel/go-genesis/packages/transaction/custom"
@@ -45,6 +48,9 @@ type Transaction struct {
 	tx            custom.TransactionInterface
 	DbTransaction *model.DbTransaction
 	SysUpdate     bool
+	FastSync      bool // rollback records aren't written in the fast sync mode
+	Notifications *notificator.Batch
+	VerifiedKey   []byte // the public key which the signature has been checked with before playing
 
 	SmartContract smart.SmartContract
 }
@@ -253,8 +259,8 @@ func (t *Transaction) fillTxData(fieldInfos []*script.FieldInfo, input []byte, f
 
 func (t *Transaction) parseFromContract(buf *bytes.Buffer) error {
 	smartTx := tx.SmartContract{}
-	if err := msgpack.Unmarshal(buf.Bytes(), &smartTx); err != nil {
-		log.WithFields(log.Fields{"tx_hash": t.TxHash, "error": err, "type": consts.UnmarshallingError}).Error("unmarshalling smart tx msgpack")
+	if err := smartTx.Unmarshal(buf.Bytes()); err != nil {
+		log.WithFields(log.Fields{"tx_hash": t.TxHash, "error": err, "type": consts.UnmarshallingError}).Error("unmarshalling smart tx")
 		return err
 	}
 	t.TxPtr = nil
@@ -321,6 +327,25 @@ func (t *Transaction) Check(checkTime int64, checkForDupTr bool) error {
 		return utils.ErrInfo(fmt.Errorf("incorrect transaction time"))
 	}
 
+	if t.TxSmart != nil {
+		if err = t.TxSmart.CheckReplay(checkTime, syspar.SysBool(syspar.StrictTxReplay)); err != nil {
+			logger.WithFields(log.Fields{"type": consts.InvalidObject, "expiration": t.TxSmart.Expiration, "nonce": t.TxSmart.Nonce, "error": err}).Error("checking replay protection of transaction")
+			return err
+		}
+		if err = t.TxSmart.CheckSignVersion(!syspar.SysBool(syspar.DisableForSignV1)); err != nil {
+			logger.WithFields(log.Fields{"type": consts.InvalidObject, "sign_version": t.TxSmart.SignVersion, "error": err}).Error("checking version of signed data")
+			return err
+		}
+		if err = t.TxSmart.CheckSigners(); err != nil {
+			logger.WithFields(log.Fields{"type": consts.InvalidObject, "signers": t.TxSmart.Signers, "error": err}).Error("checking signers of transaction")
+			return err
+		}
+		if err = t.TxSmart.CheckAmounts(); err != nil {
+			logger.WithFields(log.Fields{"type": consts.InvalidObject, "max_sum": t.TxSmart.MaxSum, "payover": t.TxSmart.PayOver, "error": err}).Error("checking amounts of transaction")
+			return err
+		}
+	}
+
 	if t.TxContract == nil {
 		if t.BlockData != nil && t.BlockData.BlockID != 1 {
 			if t.TxKeyID == 0 {
@@ -347,6 +372,31 @@ func (t *Transaction) Play() (string, error) {
 	return "", t.tx.Action()
 }
 
+// PanicError is the panic of the processing of the transaction
+type PanicError struct {
+	Value interface{}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf(`transaction panic: %v`, e.Value)
+}
+
+// recoverTx converts the panic of the processing of the transaction to PanicError,
+// the stack of the panic is written to the log
+func recoverTx(hash []byte, err *error) {
+	if r := recover(); r != nil {
+		log.WithFields(log.Fields{"type": consts.PanicRecoveredError, "error": r, "tx_hash": hex.EncodeToString(hash),
+			"stack": string(debug.Stack())}).Error("transaction has panicked")
+		*err = &PanicError{Value: r}
+	}
+}
+
+// PlayRecover executes the transaction like Play, the panic of the execution is returned as PanicError
+func (t *Transaction) PlayRecover() (msg string, err error) {
+	defer recoverTx(t.TxHash, &err)
+	return t.Play()
+}
+
 // AccessRights checks the access right by executing the condition value
 func (t *Transaction) AccessRights(condition string, iscondition bool) error {
 	logger := t.GetLogger()
@@ -382,7 +432,7 @@ func (t *Transaction) AccessRights(condition string, iscondition bool) error {
 func (t *Transaction) CallContract(flags int) (resultContract string, err error) {
 	sc := smart.SmartContract{
 		VDE:           false,
-		Rollback:      true,
+		Rollback:      !t.FastSync,
 		SysUpdate:     false,
 		VM:            smart.GetVM(),
 		TxSmart:       *t.TxSmart,
@@ -393,13 +443,45 @@ func (t *Transaction) CallContract(flags int) (resultContract string, err error)
 		BlockData:     t.BlockData,
 		TxHash:        t.TxHash,
 		PublicKeys:    t.PublicKeys,
+		VerifiedKey:   t.VerifiedKey,
 		DbTransaction: t.DbTransaction,
+		Notifications: t.Notifications,
 	}
 	resultContract, err = sc.CallContract(flags)
 	t.SysUpdate = sc.SysUpdate
 	return
 }
 
+// GetSignItem returns the signature of the contract transaction for the checking by utils.CheckSignBatch.
+// The public key is taken from the keys table or from the header of the transaction. It returns nil
+// if the key can be known only when the contract is called. The signatures of the co-signers
+// are checked by the contract
+func (t *Transaction) GetSignItem() (*utils.SignItem, error) {
+	if t.TxSmart == nil || t.TxSmart.SignedBy != 0 || len(t.TxSmart.Signers) > 0 {
+		return nil, nil
+	}
+	public := t.TxSmart.PublicKey
+	if string(public) == `null` {
+		public = nil
+	}
+	key := &model.Key{}
+	key.SetTablePrefix(t.TxSmart.EcosystemID)
+	_, err := key.Get(t.TxSmart.KeyID)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting wallet")
+		return nil, err
+	}
+	// the wrong public key is reported by the contract
+	if public, err = utils.SignerPublicKey(t.TxSmart.KeyID, key.PublicKey, public); err != nil {
+		return nil, nil
+	}
+	forSign, _ := t.TxData[`forsign`].(string)
+	if len(public) == 0 || len(forSign) == 0 {
+		return nil, nil
+	}
+	return &utils.SignItem{PublicKeys: [][]byte{public}, ForSign: forSign, Signs: t.TxSmart.BinSignatures}, nil
+}
+
 // CleanCache cleans cache of transaction parsers
 func CleanCache() {
 	txCache.Clean()
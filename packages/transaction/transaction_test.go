@@ -0,0 +1,31 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/utils/tx"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type panicTx struct{}
+
+func (panicTx) Init() error        { return nil }
+func (panicTx) Validate() error    { return nil }
+func (panicTx) Rollback() error    { return nil }
+func (panicTx) Header() *tx.Header { return nil }
+
+func (panicTx) Action() error {
+	var row map[string]interface{}
+	_ = row["amount"].(string)
+	return nil
+}
+
+func TestPlayRecover(t *testing.T) {
+	p := &Transaction{TxHash: []byte{1, 2, 3}, tx: panicTx{}}
+	msg, err := p.PlayRecover()
+	assert.Empty(t, msg)
+	require.IsType(t, &PanicError{}, err)
+	assert.Contains(t, err.Error(), `transaction panic: interface conversion`)
+}
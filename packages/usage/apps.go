@@ -0,0 +1,174 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package usage
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+)
+
+const (
+	// appParts is the number of the parts of the sliding window, the executions leave the window by parts
+	appParts = 10
+	// defaultAppWindow is the sliding window in seconds if it isn't defined in the config
+	defaultAppWindow = 60
+)
+
+// AppUsage is the execution of the contracts of VDE by the application in the sliding window
+type AppUsage struct {
+	AppID   int64 `json:"app_id"`
+	Calls   int64 `json:"calls"`
+	Time    int64 `json:"time"`
+	Fuel    int64 `json:"fuel"`
+	MaxTime int64 `json:"max_time"`
+	MaxFuel int64 `json:"max_fuel"`
+	Window  int64 `json:"window"`
+	// Reset is the time when the usage is below the quotas, it is 0 if the quotas aren't exceeded
+	Reset int64 `json:"reset"`
+}
+
+// AppQuotaError is returned if the application has exceeded the quota of the time or the fuel
+type AppQuotaError struct {
+	AppID int64
+	Quota string
+	Reset int64
+}
+
+func (e *AppQuotaError) Error() string {
+	return fmt.Sprintf(`%s quota of application %d is exceeded until %d`, e.Quota, e.AppID, e.Reset)
+}
+
+// appPart is the usage of the application in the part of the window
+type appPart struct {
+	index int64
+	calls int64
+	time  int64
+	fuel  int64
+}
+
+var (
+	appMutex sync.Mutex
+	apps     = make(map[int64]*[appParts]appPart)
+)
+
+// appPartSeconds returns the length of the part of the window
+func appPartSeconds() int64 {
+	window := conf.Config.VDEQuota.Window
+	if window <= 0 {
+		window = defaultAppWindow
+	}
+	if window < appParts {
+		return 1
+	}
+	return window / appParts
+}
+
+// CountApp adds the execution of the contract by the application
+func CountApp(appID int64, duration time.Duration, fuel int64, now time.Time) {
+	index := now.Unix() / appPartSeconds()
+
+	appMutex.Lock()
+	defer appMutex.Unlock()
+	parts, ok := apps[appID]
+	if !ok {
+		parts = new([appParts]appPart)
+		apps[appID] = parts
+	}
+	part := &parts[index%appParts]
+	if part.index != index {
+		*part = appPart{index: index}
+	}
+	part.calls++
+	part.time += int64(duration / time.Millisecond)
+	part.fuel += fuel
+}
+
+// GetApp returns the usage of the application in the window which ends now
+func GetApp(appID int64, now time.Time) AppUsage {
+	seconds := appPartSeconds()
+	index := now.Unix() / seconds
+	ret := AppUsage{AppID: appID, MaxTime: conf.Config.VDEQuota.MaxTime, MaxFuel: conf.Config.VDEQuota.MaxFuel,
+		Window: seconds * appParts}
+
+	appMutex.Lock()
+	var list []appPart
+	if parts, ok := apps[appID]; ok {
+		for _, part := range parts {
+			if part.index > index-appParts && part.index <= index {
+				list = append(list, part)
+			}
+		}
+	}
+	appMutex.Unlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].index < list[j].index })
+	for _, part := range list {
+		ret.Calls += part.calls
+		ret.Time += part.time
+		ret.Fuel += part.fuel
+	}
+	// the old parts leave the window until the usage is below the quotas
+	usedTime, usedFuel := ret.Time, ret.Fuel
+	for _, part := range list {
+		if !exceeded(usedTime, ret.MaxTime) && !exceeded(usedFuel, ret.MaxFuel) {
+			break
+		}
+		usedTime -= part.time
+		usedFuel -= part.fuel
+		ret.Reset = (part.index + appParts) * seconds
+	}
+	return ret
+}
+
+// GetApps returns the usage of the applications which have the executions in the window
+func GetApps(now time.Time) []AppUsage {
+	appMutex.Lock()
+	ids := make([]int64, 0, len(apps))
+	for id := range apps {
+		ids = append(ids, id)
+	}
+	appMutex.Unlock()
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	ret := make([]AppUsage, 0, len(ids))
+	for _, id := range ids {
+		if item := GetApp(id, now); item.Calls > 0 {
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}
+
+// CheckApp returns AppQuotaError if the application has exceeded the quotas in the window
+func CheckApp(appID int64, now time.Time) error {
+	item := GetApp(appID, now)
+	switch {
+	case exceeded(item.Time, item.MaxTime):
+		return &AppQuotaError{AppID: appID, Quota: `time`, Reset: item.Reset}
+	case exceeded(item.Fuel, item.MaxFuel):
+		return &AppQuotaError{AppID: appID, Quota: `fuel`, Reset: item.Reset}
+	}
+	return nil
+}
+
+func exceeded(used, quota int64) bool {
+	return quota > 0 && used >= quota
+}
@@ -0,0 +1,54 @@
+package usage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppQuota(t *testing.T) {
+	saved := conf.Config.VDEQuota
+	defer func() { conf.Config.VDEQuota = saved }()
+	conf.Config.VDEQuota = conf.VDEQuotaConfig{Window: 60, MaxTime: 100, MaxFuel: 1000}
+	apps = make(map[int64]*[appParts]appPart)
+
+	now := time.Unix(1530000000, 0)
+	CountApp(1, 40*time.Millisecond, 300, now)
+	assert.NoError(t, CheckApp(1, now))
+	later := now.Add(12 * time.Second)
+	CountApp(1, 70*time.Millisecond, 100, later)
+
+	// the quota is reset when the first execution leaves the window
+	err := CheckApp(1, later)
+	assert.Equal(t, &AppQuotaError{AppID: 1, Quota: `time`, Reset: now.Unix() + 60}, err)
+	assert.EqualError(t, err, `time quota of application 1 is exceeded until 1530000060`)
+	assert.Equal(t, AppUsage{AppID: 1, Calls: 2, Time: 110, Fuel: 400, MaxTime: 100, MaxFuel: 1000,
+		Window: 60, Reset: now.Unix() + 60}, GetApp(1, later))
+	assert.NoError(t, CheckApp(2, later))
+
+	reset := now.Add(60 * time.Second)
+	assert.NoError(t, CheckApp(1, reset))
+	assert.Equal(t, AppUsage{AppID: 1, Calls: 1, Time: 70, Fuel: 100, MaxTime: 100, MaxFuel: 1000,
+		Window: 60}, GetApp(1, reset))
+
+	// the part of the window is reused
+	CountApp(1, 10*time.Millisecond, 1, reset)
+	assert.Equal(t, int64(80), GetApp(1, reset).Time)
+
+	CountApp(3, 0, 1000, later)
+	assert.Equal(t, &AppQuotaError{AppID: 3, Quota: `fuel`, Reset: later.Unix() + 60}, CheckApp(3, later))
+	list := GetApps(later)
+	if assert.Len(t, list, 2) {
+		assert.Equal(t, int64(1), list[0].AppID)
+		assert.Equal(t, int64(3), list[1].AppID)
+	}
+	assert.Empty(t, GetApps(now.Add(200*time.Second)))
+
+	// the quotas aren't checked if they are 0
+	conf.Config.VDEQuota = conf.VDEQuotaConfig{}
+	assert.NoError(t, CheckApp(3, later))
+	assert.Equal(t, int64(defaultAppWindow), GetApp(3, later).Window)
+}
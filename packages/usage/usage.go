@@ -0,0 +1,216 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package usage counts the API calls of the ecosystems and checks their daily quotas.
+//
+// The calls are counted in memory by the atomic counters which are written to the api_usage
+// table by Flush, so the requests don't write to the database. The counters which haven't been
+// flushed are lost if the node is stopped.
+//
+// The execution of the contracts of VDE is counted by the applications in the sliding window
+// which is kept only in memory, the applications which exceed the quotas are rejected until
+// their old executions leave the window.
+package usage
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DaySeconds is the length of the period of the quota
+const DaySeconds = 24 * 60 * 60
+
+type counterKey struct {
+	day       int64
+	ecosystem int64
+	route     string
+}
+
+// dayCalls is the number of the calls of the ecosystem in the day
+type dayCalls struct {
+	day   int64
+	calls int64
+}
+
+var (
+	mutex sync.RWMutex
+	// counters are the calls which haven't been flushed
+	counters = make(map[counterKey]*int64)
+	// totals are the calls of the ecosystems with the quotas in the current day
+	totals = make(map[int64]*dayCalls)
+
+	// loadCalls returns the flushed calls of the ecosystem in the day
+	loadCalls = model.GetAPIUsageCalls
+	// saveCalls writes the counters to the database
+	saveCalls = model.AddAPIUsage
+)
+
+// Day returns the start of the day of the time in UTC
+func Day(t time.Time) int64 {
+	now := t.Unix()
+	return now - now%DaySeconds
+}
+
+// Quota returns the daily quota of the API calls of the ecosystem, 0 is unlimited.
+// The anonymous calls which have the ecosystem 0 aren't limited
+func Quota(ecosystem int64) int64 {
+	if ecosystem == 0 {
+		return 0
+	}
+	if quota, ok := conf.Config.APIUsage.Quotas[strconv.FormatInt(ecosystem, 10)]; ok {
+		return quota
+	}
+	return conf.Config.APIUsage.DailyQuota
+}
+
+// Count counts the call of the route by the ecosystem. It returns false if the ecosystem
+// has exhausted the quota, the call isn't counted in this case. The second value is the time
+// when the quota is reset
+func Count(ecosystem int64, route string, now time.Time) (bool, int64) {
+	day := Day(now)
+	reset := day + DaySeconds
+	if quota := Quota(ecosystem); quota > 0 {
+		total := getTotal(ecosystem, day)
+		if atomic.AddInt64(&total.calls, 1) > quota {
+			atomic.AddInt64(&total.calls, -1)
+			return false, reset
+		}
+	}
+	key := counterKey{day: day, ecosystem: ecosystem, route: route}
+
+	// the counter is changed under the read lock, so Flush doesn't lose the calls
+	mutex.RLock()
+	if counter, ok := counters[key]; ok {
+		atomic.AddInt64(counter, 1)
+		mutex.RUnlock()
+		return true, reset
+	}
+	mutex.RUnlock()
+
+	mutex.Lock()
+	counter, ok := counters[key]
+	if !ok {
+		counter = new(int64)
+		counters[key] = counter
+	}
+	atomic.AddInt64(counter, 1)
+	mutex.Unlock()
+	return true, reset
+}
+
+// getTotal returns the calls of the ecosystem in the day, they are loaded from the database
+// at the first call in the day
+func getTotal(ecosystem, day int64) *dayCalls {
+	mutex.RLock()
+	total, ok := totals[ecosystem]
+	mutex.RUnlock()
+	if ok && total.day == day {
+		return total
+	}
+
+	calls, err := loadCalls(ecosystem, day)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "ecosystem": ecosystem}).Error("getting api usage")
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if total, ok = totals[ecosystem]; ok && total.day == day {
+		return total
+	}
+	total = &dayCalls{day: day, calls: calls}
+	totals[ecosystem] = total
+	return total
+}
+
+// Flush writes the counters to the database. The counters are kept if they can't be written
+func Flush() error {
+	mutex.Lock()
+	pending := counters
+	counters = make(map[counterKey]*int64)
+	mutex.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+	err := saveCalls(items(pending, func(counterKey) bool { return true }))
+	if err != nil {
+		mutex.Lock()
+		for key, calls := range pending {
+			if counter, ok := counters[key]; ok {
+				*counter += *calls
+			} else {
+				counters[key] = calls
+			}
+		}
+		mutex.Unlock()
+	}
+	return err
+}
+
+// Get returns the calls of the ecosystem from the day to the day inclusive including
+// the calls which haven't been flushed
+func Get(ecosystem, from, to int64) ([]model.APIUsage, error) {
+	list, err := model.GetAPIUsage(ecosystem, from, to)
+	if err != nil {
+		return nil, err
+	}
+	mutex.RLock()
+	pending := items(counters, func(key counterKey) bool {
+		return key.ecosystem == ecosystem && key.day >= from && key.day <= to
+	})
+	mutex.RUnlock()
+
+	for _, item := range pending {
+		found := false
+		for i := range list {
+			if list[i].Day == item.Day && list[i].Route == item.Route {
+				list[i].Calls += item.Calls
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Day != list[j].Day {
+			return list[i].Day < list[j].Day
+		}
+		return list[i].Route < list[j].Route
+	})
+	return list, nil
+}
+
+func items(list map[counterKey]*int64, filter func(counterKey) bool) []model.APIUsage {
+	ret := make([]model.APIUsage, 0, len(list))
+	for key, calls := range list {
+		if filter(key) {
+			ret = append(ret, model.APIUsage{Day: key.day, Ecosystem: key.ecosystem,
+				Route: key.route, Calls: atomic.LoadInt64(calls)})
+		}
+	}
+	return ret
+}
@@ -0,0 +1,86 @@
+package usage
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCount(t *testing.T) {
+	var (
+		saved   []model.APIUsage
+		saveErr error
+	)
+	loadCalls = func(ecosystem, day int64) (int64, error) {
+		if ecosystem == 2 {
+			return 8, nil
+		}
+		return 0, nil
+	}
+	saveCalls = func(items []model.APIUsage) error {
+		if saveErr == nil {
+			saved = append(saved, items...)
+		}
+		return saveErr
+	}
+	conf.Config.APIUsage = conf.APIUsageConfig{DailyQuota: 10, Quotas: map[string]int64{"3": 0}}
+	defer func() {
+		loadCalls, saveCalls = model.GetAPIUsageCalls, model.AddAPIUsage
+		conf.Config.APIUsage = conf.APIUsageConfig{}
+	}()
+
+	now := time.Date(2018, 5, 10, 15, 30, 0, 0, time.UTC)
+	day := time.Date(2018, 5, 10, 0, 0, 0, 0, time.UTC).Unix()
+	assert.Equal(t, day, Day(now))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for _, ecosystem := range []int64{0, 1, 2, 3} {
+				Count(ecosystem, fmt.Sprintf("route%d", i%2), now)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	ok, reset := Count(1, "route0", now)
+	assert.False(t, ok)
+	assert.Equal(t, day+DaySeconds, reset)
+	ok, _ = Count(1, "route0", now.Add(12*time.Hour))
+	assert.True(t, ok)
+
+	saveErr = fmt.Errorf("db is down")
+	assert.Error(t, Flush())
+	saveErr = nil
+	Count(0, "route0", now)
+	require.NoError(t, Flush())
+
+	calls := make(map[string]int64)
+	for _, item := range saved {
+		calls[fmt.Sprintf("%d %d", item.Day, item.Ecosystem)] += item.Calls
+		if item.Ecosystem == 0 {
+			calls[item.Route] += item.Calls
+		}
+	}
+	// the anonymous calls and the unlimited ecosystem aren't limited, the ecosystem 2
+	// has used 8 calls before the start
+	assert.Equal(t, int64(11), calls["route0"])
+	assert.Equal(t, int64(10), calls["route1"])
+	assert.Equal(t, int64(10), calls[fmt.Sprintf("%d 1", day)])
+	assert.Equal(t, int64(2), calls[fmt.Sprintf("%d 2", day)])
+	assert.Equal(t, int64(20), calls[fmt.Sprintf("%d 3", day)])
+	assert.Equal(t, int64(1), calls[fmt.Sprintf("%d 1", day+DaySeconds)])
+
+	saved = nil
+	require.NoError(t, Flush())
+	assert.Empty(t, saved)
+}
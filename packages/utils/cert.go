@@ -1,16 +1,34 @@
 package utils
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
 	"encoding/pem"
 	"errors"
+	"math/big"
+	"time"
 )
 
 var (
 	errParseCert     = errors.New("Failed to parse certificate")
 	errParseRootCert = errors.New("Failed to parse root certificate")
+	errCertKey       = errors.New("Key doesn't match the public key of the root certificate")
 )
 
+// oidDescription is the description attribute of the subject which contains the reason of the network stopping
+var oidDescription = asn1.ObjectIdentifier{2, 5, 4, 13}
+
+// StopInfo is the reason and the time of the network stopping which are embedded in the stop certificate
+type StopInfo struct {
+	Reason string
+	Time   time.Time
+}
+
 type Cert struct {
 	cert *x509.Certificate
 }
@@ -28,6 +46,18 @@ func (c *Cert) Validate(pem []byte) error {
 	return nil
 }
 
+// StopInfo returns the reason and the time of the network stopping. The reason is empty
+// if the certificate isn't created by CreateStopCert
+func (c *Cert) StopInfo() *StopInfo {
+	info := &StopInfo{Time: c.cert.NotBefore}
+	for _, name := range c.cert.Subject.Names {
+		if reason, ok := name.Value.(string); ok && name.Type.Equal(oidDescription) {
+			info.Reason = reason
+		}
+	}
+	return info
+}
+
 func (c *Cert) EqualBytes(bs ...[]byte) bool {
 	for _, b := range bs {
 		other, err := parseCert(b)
@@ -65,3 +95,70 @@ func ParseCert(b []byte) (c *Cert, err error) {
 
 	return &Cert{cert}, nil
 }
+
+// CreateRootCert returns the PEM of the self-signed certificate of the ECDSA private key,
+// it's included in the first block to validate the stop certificates
+func CreateRootCert(key []byte, name string, validity time.Duration) ([]byte, error) {
+	priv := ecdsaPrivateKey(key)
+	now := time.Now()
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             now,
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return createCert(template, template, &priv.PublicKey, priv)
+}
+
+// CreateStopCert returns the PEM of the stop certificate with the reason of the network stopping
+// which is signed by the ECDSA private key of the root certificate
+func CreateStopCert(root, key []byte, reason string, validity time.Duration) ([]byte, error) {
+	parent, err := parseCert(root)
+	if err != nil {
+		return nil, errParseRootCert
+	}
+	priv := ecdsaPrivateKey(key)
+	if pub, ok := parent.PublicKey.(*ecdsa.PublicKey); !ok || pub.X.Cmp(priv.X) != 0 || pub.Y.Cmp(priv.Y) != 0 {
+		return nil, errCertKey
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName: "stop network",
+			ExtraNames: []pkix.AttributeTypeAndValue{{Type: oidDescription, Value: reason}},
+		},
+		NotBefore: now,
+		NotAfter:  now.Add(validity),
+		KeyUsage:  x509.KeyUsageDigitalSignature,
+	}
+	// the key of the stop certificate isn't used for signing, so it's thrown away
+	leaf, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return createCert(template, parent, &leaf.PublicKey, priv)
+}
+
+func createCert(template, parent *x509.Certificate, pub interface{}, priv crypto.Signer) ([]byte, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	template.SerialNumber = serial
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pub, priv)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
+
+func ecdsaPrivateKey(key []byte) *ecdsa.PrivateKey {
+	priv := new(ecdsa.PrivateKey)
+	priv.Curve = elliptic.P256()
+	priv.D = new(big.Int).SetBytes(key)
+	priv.X, priv.Y = priv.Curve.ScalarBaseMult(key)
+	return priv
+}
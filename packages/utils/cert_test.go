@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStopCert(t *testing.T) {
+	priv, _, err := crypto.GenBytesKeys()
+	require.NoError(t, err)
+	root, err := CreateRootCert(priv, "founder", time.Hour)
+	require.NoError(t, err)
+
+	b, err := CreateStopCert(root, priv, "emergency upgrade", time.Hour)
+	require.NoError(t, err)
+	cert, err := ParseCert(b)
+	require.NoError(t, err)
+	require.NoError(t, cert.Validate(root))
+	info := cert.StopInfo()
+	assert.Equal(t, "emergency upgrade", info.Reason)
+	assert.WithinDuration(t, time.Now(), info.Time, time.Minute)
+
+	other, _, err := crypto.GenBytesKeys()
+	require.NoError(t, err)
+	_, err = CreateStopCert(root, other, "reason", time.Hour)
+	assert.Equal(t, errCertKey, err)
+
+	otherRoot, err := CreateRootCert(other, "other", time.Hour)
+	require.NoError(t, err)
+	assert.Error(t, cert.Validate(otherRoot))
+}
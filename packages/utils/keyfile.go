@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// nodeKey keeps the decrypted node private key, so the passphrase is asked only once
+// and the key isn't decrypted again until the key file is changed
+var nodeKey struct {
+	sync.Mutex
+	passphrase string
+	encrypted  []byte
+	key        []byte
+}
+
+// GetKeyPassphrase returns the passphrase of the encrypted key from the environment variable
+// or the file of the config. Otherwise it asks the passphrase in the terminal,
+// the new passphrase is asked twice if confirm is true
+func GetKeyPassphrase(confirm bool) (string, error) {
+	var pass string
+	switch src := conf.Config.KeyPass; {
+	case len(src.Env) > 0:
+		pass = os.Getenv(src.Env)
+	case len(src.File) > 0:
+		data, err := ioutil.ReadFile(src.File)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": src.File}).Error("reading passphrase file")
+			return ``, err
+		}
+		pass = strings.TrimRight(string(data), "\r\n")
+	default:
+		var err error
+		if pass, err = readPassphrase(`Passphrase: `); err != nil {
+			return ``, err
+		}
+		if confirm {
+			repeat, err := readPassphrase(`Repeat passphrase: `)
+			if err != nil {
+				return ``, err
+			}
+			if subtle.ConstantTimeCompare([]byte(repeat), []byte(pass)) != 1 {
+				return ``, fmt.Errorf("passphrases don't match")
+			}
+		}
+	}
+	if len(pass) == 0 {
+		return ``, fmt.Errorf("passphrase of the key is empty")
+	}
+	return pass, nil
+}
+
+func readPassphrase(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return ``, fmt.Errorf("passphrase of the key isn't specified in the config and stdin isn't a terminal")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	pass, err := terminal.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading passphrase")
+		return ``, err
+	}
+	defer crypto.WipeKey(pass)
+	return string(pass), nil
+}
+
+// readNodePrivateKey returns the hex node private key. The encrypted key file is decrypted transparently
+func readNodePrivateKey() ([]byte, error) {
+	data, err := ioutil.ReadFile(filepath.Join(conf.Config.KeysDir, consts.NodePrivateKeyFilename))
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading node private key from file")
+		return nil, err
+	}
+	if !crypto.IsEncryptedKey(data) {
+		return data, nil
+	}
+
+	nodeKey.Lock()
+	defer nodeKey.Unlock()
+	if nodeKey.key != nil && subtle.ConstantTimeCompare(nodeKey.encrypted, data) == 1 {
+		return nodeKey.key, nil
+	}
+	if len(nodeKey.passphrase) == 0 {
+		if nodeKey.passphrase, err = GetKeyPassphrase(false); err != nil {
+			return nil, err
+		}
+	}
+	key, err := crypto.DecryptKey(data, nodeKey.passphrase)
+	if err != nil {
+		nodeKey.passphrase = ``
+		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("decrypting node private key")
+		return nil, err
+	}
+	// the key of the previous file isn't used anymore
+	crypto.WipeKey(nodeKey.key)
+	nodeKey.encrypted, nodeKey.key = data, key
+	return key, nil
+}
+
+// LoadNodeKeys checks the node private key at the start of the node, so the wrong passphrase
+// of the encrypted key stops the node before any daemon is started
+func LoadNodeKeys() error {
+	path := filepath.Join(conf.Config.KeysDir, consts.NodePrivateKeyFilename)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	if _, _, err := GetNodeKeys(); err != nil {
+		return fmt.Errorf("loading node private key %s: %s", path, err)
+	}
+	return nil
+}
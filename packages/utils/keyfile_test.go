@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedNodeKey(t *testing.T) {
+	dir, err := ioutil.TempDir(``, `keys`)
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(keysDir string, keyPass conf.KeyPassConfig) {
+		conf.Config.KeysDir, conf.Config.KeyPass = keysDir, keyPass
+	}(conf.Config.KeysDir, conf.Config.KeyPass)
+
+	priv, pub, err := crypto.GenHexKeys()
+	require.NoError(t, err)
+	data, err := crypto.EncryptKey([]byte(priv), `secret`)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, consts.NodePrivateKeyFilename), data, 0600))
+
+	conf.Config.KeysDir = dir
+	conf.Config.KeyPass = conf.KeyPassConfig{Env: `GENESIS_TEST_KEYPASS`}
+	os.Setenv(`GENESIS_TEST_KEYPASS`, `wrong`)
+	defer os.Unsetenv(`GENESIS_TEST_KEYPASS`)
+	assert.Error(t, LoadNodeKeys())
+
+	os.Setenv(`GENESIS_TEST_KEYPASS`, `secret`)
+	require.NoError(t, LoadNodeKeys())
+	nodePriv, nodePub, err := GetNodeKeys()
+	require.NoError(t, err)
+	assert.Equal(t, priv, nodePriv)
+	assert.Equal(t, pub, nodePub)
+}
@@ -3,12 +3,17 @@ package metric
 // CollectorFunc represents function for collects values of metrics
 type CollectorFunc func() ([]*Value, error)
 
+// PreviousFunc returns the value of the metric which has been stored in the same time bucket
+// by the previous collection or nil
+type PreviousFunc func(v *Value) (*Value, error)
+
 // Value represents value of metrics
 type Value struct {
 	Time   int64
 	Metric string
 	Key    string
 	Value  int64
+	Delta  int64
 }
 
 // ToMap returns values as map
@@ -18,12 +23,28 @@ func (v *Value) ToMap() map[string]interface{} {
 		"metric": v.Metric,
 		"key":    v.Key,
 		"value":  v.Value,
+		"delta":  v.Delta,
 	}
 }
 
+// Growth returns the growth of the counter since the previous value. The counter is reset
+// if it is less than the previous value, so all the current value is the growth
+func Growth(prev, cur int64) int64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
 // Collector represents struct that works with the collection of metrics
 type Collector struct {
-	funcs []CollectorFunc
+	funcs    []CollectorFunc
+	previous PreviousFunc
+}
+
+// SetPrevious sets the function which returns the previous values, they are used for the deltas
+func (c *Collector) SetPrevious(fn PreviousFunc) {
+	c.previous = fn
 }
 
 // Values returns values of all metrics
@@ -36,12 +57,29 @@ func (c *Collector) Values() []interface{} {
 		}
 
 		for _, v := range result {
+			if err = c.setDelta(v); err != nil {
+				continue
+			}
 			values = append(values, v.ToMap())
 		}
 	}
 	return values
 }
 
+// setDelta accumulates the growth of the value in the time bucket
+func (c *Collector) setDelta(v *Value) error {
+	v.Delta = v.Value
+	if c.previous == nil {
+		return nil
+	}
+	prev, err := c.previous(v)
+	if err != nil || prev == nil {
+		return err
+	}
+	v.Delta = prev.Delta + Growth(prev.Value, v.Value)
+	return nil
+}
+
 // NewCollector creates new collector
 func NewCollector(funcs ...CollectorFunc) *Collector {
 	c := &Collector{}
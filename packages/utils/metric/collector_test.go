@@ -23,7 +23,7 @@ func MockCollectorFunc(v int64, err error) CollectorFunc {
 
 func TestValue(t *testing.T) {
 	value := MockValue(100)
-	result := map[string]interface{}{"time": int64(1), "metric": "test_metric", "key": "ecosystem_1", "value": int64(100)}
+	result := map[string]interface{}{"time": int64(1), "metric": "test_metric", "key": "ecosystem_1", "value": int64(100), "delta": int64(0)}
 	assert.Equal(t, result, value.ToMap())
 }
 
@@ -35,8 +35,37 @@ func TestCollector(t *testing.T) {
 	)
 
 	result := []interface{}{
-		map[string]interface{}{"time": int64(1), "metric": "test_metric", "key": "ecosystem_1", "value": int64(100)},
-		map[string]interface{}{"time": int64(1), "metric": "test_metric", "key": "ecosystem_1", "value": int64(200)},
+		map[string]interface{}{"time": int64(1), "metric": "test_metric", "key": "ecosystem_1", "value": int64(100), "delta": int64(100)},
+		map[string]interface{}{"time": int64(1), "metric": "test_metric", "key": "ecosystem_1", "value": int64(200), "delta": int64(200)},
 	}
 	assert.Equal(t, result, c.Values())
 }
+
+func TestCollectorDelta(t *testing.T) {
+	prev := &Value{Time: 1, Metric: "test_metric", Key: "ecosystem_1", Value: 150, Delta: 150}
+	c := NewCollector(
+		MockCollectorFunc(200, nil),
+		// the counter is reset by the restart
+		MockCollectorFunc(30, nil),
+	)
+	c.SetPrevious(func(v *Value) (*Value, error) {
+		return prev, nil
+	})
+
+	result := []interface{}{
+		map[string]interface{}{"time": int64(1), "metric": "test_metric", "key": "ecosystem_1", "value": int64(200), "delta": int64(200)},
+		map[string]interface{}{"time": int64(1), "metric": "test_metric", "key": "ecosystem_1", "value": int64(30), "delta": int64(180)},
+	}
+	assert.Equal(t, result, c.Values())
+
+	c.SetPrevious(func(v *Value) (*Value, error) {
+		return nil, errors.New("Test")
+	})
+	assert.Empty(t, c.Values())
+}
+
+func TestGrowth(t *testing.T) {
+	assert.Equal(t, int64(5), Growth(10, 15))
+	assert.Equal(t, int64(0), Growth(10, 10))
+	assert.Equal(t, int64(3), Growth(10, 3))
+}
@@ -16,6 +16,20 @@ const (
 	metricEcosystemTx      = "ecosystem_tx"
 )
 
+// PreviousValue returns the stored value of the metric in the same time bucket
+func PreviousValue(v *Value) (*Value, error) {
+	m := &model.Metric{}
+	found, err := m.Get(v.Time, v.Metric, v.Key)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "type": consts.DBError}).Error("get previous value of metric")
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	return &Value{Time: m.Time, Metric: m.Metric, Key: m.Key, Value: m.Value, Delta: m.Delta}, nil
+}
+
 // CollectMetricDataForEcosystemTables returns metrics for some tables of ecosystems
 func CollectMetricDataForEcosystemTables() (metricValues []*Value, err error) {
 	stateIDs, err := model.GetAllSystemStatesIDs()
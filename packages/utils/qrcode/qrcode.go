@@ -0,0 +1,488 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+const (
+	minVersion = 1
+	maxVersion = 40
+
+	// quietZone is the width of the light border around the symbol in modules
+	quietZone = 4
+
+	modeByte = 0x4
+)
+
+// ErrTooLong is returned if the content doesn't fit into the biggest QR code
+var ErrTooLong = errors.New("content is too long for QR code")
+
+// the error correction level M, the index is the version
+var (
+	eccCodewordsPerBlock = [maxVersion + 1]int{0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22,
+		24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28,
+		28, 28}
+	eccBlocks = [maxVersion + 1]int{0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16,
+		17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49}
+)
+
+// Code is the QR code symbol with the error correction level M
+type Code struct {
+	// Size is the count of the modules on the side of the symbol
+	Size     int
+	version  int
+	modules  [][]bool
+	function [][]bool
+}
+
+// Encode returns the smallest QR code which contains the content in the byte mode
+func Encode(content []byte) (*Code, error) {
+	version := minVersion
+	for ; ; version++ {
+		if version > maxVersion {
+			return nil, ErrTooLong
+		}
+		if 4+charCountBits(version)+len(content)*8 <= dataCodewords(version)*8 {
+			break
+		}
+	}
+	c := &Code{Size: version*4 + 17, version: version}
+	c.modules = newMatrix(c.Size)
+	c.function = newMatrix(c.Size)
+	c.drawFunctionPatterns()
+	c.drawCodewords(addECC(version, encodeData(version, content)))
+
+	mask, minPenalty := 0, -1
+	for i := 0; i < 8; i++ {
+		c.applyMask(i)
+		c.drawFormatBits(i)
+		if penalty := c.penalty(); minPenalty < 0 || penalty < minPenalty {
+			mask, minPenalty = i, penalty
+		}
+		c.applyMask(i)
+	}
+	c.applyMask(mask)
+	c.drawFormatBits(mask)
+	return c, nil
+}
+
+// Dark returns true if the module in the column x and the row y is dark
+func (c *Code) Dark(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.Size && y < c.Size && c.modules[y][x]
+}
+
+// Image returns the image of the symbol with the quiet zone. The image is scaled to the biggest
+// integer count of pixels per module which fits into size, it isn't less than one pixel
+func (c *Code) Image(size int) image.Image {
+	scale := size / (c.Size + quietZone*2)
+	if scale < 1 {
+		scale = 1
+	}
+	side := (c.Size + quietZone*2) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.modules[y][x] {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex((x+quietZone)*scale+dx, (y+quietZone)*scale+dy, 1)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// PNG returns the image of the symbol in PNG format
+func (c *Code) PNG(size int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.Image(size)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func newMatrix(size int) [][]bool {
+	matrix := make([][]bool, size)
+	for i := range matrix {
+		matrix[i] = make([]bool, size)
+	}
+	return matrix
+}
+
+func charCountBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// rawCodewords returns the count of the codewords which can be stored in the symbol
+func rawCodewords(version int) int {
+	modules := (16*version+128)*version + 64
+	if version >= 2 {
+		align := version/7 + 2
+		modules -= (25*align-10)*align - 55
+		if version >= 7 {
+			modules -= 36
+		}
+	}
+	return modules / 8
+}
+
+func dataCodewords(version int) int {
+	return rawCodewords(version) - eccCodewordsPerBlock[version]*eccBlocks[version]
+}
+
+// encodeData returns the data codewords with the mode, the length, the terminator and the padding
+func encodeData(version int, content []byte) []byte {
+	var (
+		out   []byte
+		cur   byte
+		count uint
+	)
+	appendBits := func(value, length int) {
+		for i := length - 1; i >= 0; i-- {
+			cur = cur<<1 | byte(value>>uint(i)&1)
+			if count++; count == 8 {
+				out, cur, count = append(out, cur), 0, 0
+			}
+		}
+	}
+	capacity := dataCodewords(version)
+	appendBits(modeByte, 4)
+	appendBits(len(content), charCountBits(version))
+	for _, b := range content {
+		appendBits(int(b), 8)
+	}
+	for i := 0; i < 4 && len(out) < capacity; i++ {
+		appendBits(0, 1)
+	}
+	if count > 0 {
+		appendBits(0, int(8-count))
+	}
+	for pad := byte(0xEC); len(out) < capacity; pad ^= 0xEC ^ 0x11 {
+		out = append(out, pad)
+	}
+	return out
+}
+
+// addECC splits the data into the blocks, adds the error correction codewords and interleaves them
+func addECC(version int, data []byte) []byte {
+	blocks, eccLen := eccBlocks[version], eccCodewordsPerBlock[version]
+	raw := rawCodewords(version)
+	shortBlocks, shortLen := blocks-raw%blocks, raw/blocks
+	divisor := rsDivisor(eccLen)
+
+	var split [][]byte
+	for i, k := 0, 0; i < blocks; i++ {
+		dataLen := shortLen - eccLen
+		if i >= shortBlocks {
+			dataLen++
+		}
+		block := append([]byte{}, data[k:k+dataLen]...)
+		k += dataLen
+		ecc := rsRemainder(block, divisor)
+		// the short blocks are padded to interleave the codewords by the same index
+		if i < shortBlocks {
+			block = append(block, 0)
+		}
+		split = append(split, append(block, ecc...))
+	}
+	out := make([]byte, 0, raw)
+	for i := 0; i <= shortLen; i++ {
+		for j, block := range split {
+			if i != shortLen-eccLen || j >= shortBlocks {
+				out = append(out, block[i])
+			}
+		}
+	}
+	return out
+}
+
+// rsMultiply multiplies the numbers in GF(2^8) with the polynomial 0x11D
+func rsMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>uint(i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = rsMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = rsMultiply(root, 0x02)
+	}
+	return result
+}
+
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, d := range divisor {
+			result[i] ^= rsMultiply(d, factor)
+		}
+	}
+	return result
+}
+
+func (c *Code) setFunction(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.function[y][x] = true
+}
+
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.Size; i++ {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+	for _, pos := range [][2]int{{3, 3}, {c.Size - 4, 3}, {3, c.Size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := pos[0]+dx, pos[1]+dy
+				if x >= 0 && y >= 0 && x < c.Size && y < c.Size {
+					dist := max(abs(dx), abs(dy))
+					c.setFunction(x, y, dist != 2 && dist != 4)
+				}
+			}
+		}
+	}
+	align := c.alignmentPositions()
+	last := len(align) - 1
+	for i, x := range align {
+		for j, y := range align {
+			// the alignment patterns don't overlap the finder patterns
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+	// the format bits are reserved here and drawn after the choice of the mask
+	c.drawFormatBits(0)
+	c.drawVersion()
+}
+
+func (c *Code) alignmentPositions() []int {
+	if c.version == 1 {
+		return nil
+	}
+	count := c.version/7 + 2
+	step := (c.version*4 + count*2 + 1) / (count*2 - 2) * 2
+	if c.version == 32 {
+		step = 26
+	}
+	result := make([]int, count)
+	result[0] = 6
+	for i, pos := count-1, c.Size-7; i > 0; i, pos = i-1, pos-step {
+		result[i] = pos
+	}
+	return result
+}
+
+// formatBits returns the format information of the level M and the mask with the error correction
+func formatBits(mask int) int {
+	data := mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func (c *Code) drawFormatBits(mask int) {
+	bits := formatBits(mask)
+	bit := func(i int) bool {
+		return bits>>uint(i)&1 != 0
+	}
+	for i := 0; i <= 5; i++ {
+		c.setFunction(8, i, bit(i))
+	}
+	c.setFunction(8, 7, bit(6))
+	c.setFunction(8, 8, bit(7))
+	c.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		c.setFunction(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.Size-15+i, bit(i))
+	}
+	c.setFunction(8, c.Size-8, true)
+}
+
+// versionBits returns the version information with the error correction
+func versionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	return version<<12 | rem
+}
+
+func (c *Code) drawVersion() {
+	if c.version < 7 {
+		return
+	}
+	bits := versionBits(c.version)
+	for i := 0; i < 18; i++ {
+		dark := bits>>uint(i)&1 != 0
+		a, b := c.Size-11+i%3, i/3
+		c.setFunction(a, b, dark)
+		c.setFunction(b, a, dark)
+	}
+}
+
+// drawCodewords places the bits of the codewords in the zigzag order skipping the function patterns
+func (c *Code) drawCodewords(data []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if !c.function[y][x] && i < len(data)*8 {
+					c.modules[y][x] = data[i>>3]>>uint(7-i&7)&1 != 0
+					i++
+				}
+			}
+		}
+	}
+}
+
+func maskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// applyMask inverts the data modules by the mask, the second call restores them
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.function[y][x] && maskBit(mask, x, y) {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty returns the score of the symbol, the mask with the lowest score is used
+func (c *Code) penalty() int {
+	var result, dark int
+	finder := []bool{true, false, true, true, true, false, true}
+	for i := 0; i < c.Size; i++ {
+		for _, get := range []func(int) bool{
+			func(j int) bool { return c.modules[i][j] },
+			func(j int) bool { return c.modules[j][i] },
+		} {
+			run := 1
+			for j := 1; j <= c.Size; j++ {
+				if j < c.Size && get(j) == get(j-1) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					result += run - 2
+				}
+				run = 1
+			}
+			// the pattern like the finder with four light modules before or after
+			for j := 0; j+len(finder) <= c.Size; j++ {
+				match := true
+				for k, v := range finder {
+					if get(j+k) != v {
+						match = false
+						break
+					}
+				}
+				if match && (c.isLight(get, j-4, j) || c.isLight(get, j+len(finder), j+len(finder)+4)) {
+					result += 40
+				}
+			}
+		}
+	}
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x > 0 && y > 0 && c.modules[y][x] == c.modules[y-1][x] &&
+				c.modules[y][x] == c.modules[y][x-1] && c.modules[y][x] == c.modules[y-1][x-1] {
+				result += 3
+			}
+		}
+	}
+	total := c.Size * c.Size
+	return result + (abs(dark*20-total*10)+total-1)/total*10 - 10
+}
+
+// isLight returns true if the modules from the index to the index before end are light,
+// the modules outside the symbol are light
+func (c *Code) isLight(get func(int) bool, from, to int) bool {
+	for i := from; i < to; i++ {
+		if i >= 0 && i < c.Size && get(i) {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func max(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
@@ -0,0 +1,90 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// the data codewords of HELLO WORLD in the version 1-M
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	assert.Equal(t, []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}, rsRemainder(data, rsDivisor(10)))
+}
+
+func TestInfoBits(t *testing.T) {
+	assert.Equal(t, 0x5412, formatBits(0))
+	assert.Equal(t, 0x5125, formatBits(1))
+	assert.Equal(t, 0x07C94, versionBits(7))
+	assert.Equal(t, 0x28C69, versionBits(40))
+}
+
+func TestCapacity(t *testing.T) {
+	for version, codewords := range map[int]int{1: 16, 10: 216, 40: 2334} {
+		assert.Equal(t, codewords, dataCodewords(version))
+	}
+	for length, version := range map[int]int{14: 1, 15: 2, 2331: 40} {
+		c, err := Encode([]byte(strings.Repeat("a", length)))
+		if assert.NoError(t, err) {
+			assert.Equal(t, version, c.version)
+			assert.Equal(t, version*4+17, c.Size)
+		}
+	}
+	_, err := Encode([]byte(strings.Repeat("a", 2332)))
+	assert.Equal(t, ErrTooLong, err)
+}
+
+func TestEncode(t *testing.T) {
+	c, err := Encode([]byte("genesis:0000-0000-0000-0000-0001?amount=10"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 3, c.version)
+	// the finder patterns, the timing pattern and the dark module
+	for _, pos := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+		assert.True(t, c.Dark(pos[0], pos[1]))
+		assert.False(t, c.Dark(pos[0]+1, pos[1]+1))
+		assert.True(t, c.Dark(pos[0]+2, pos[1]+2))
+	}
+	for i := 8; i < c.Size-8; i++ {
+		assert.Equal(t, i%2 == 0, c.Dark(i, 6))
+		assert.Equal(t, i%2 == 0, c.Dark(6, i))
+	}
+	assert.True(t, c.Dark(8, c.Size-8))
+
+	// the format bits are the same in both copies
+	var first, second int
+	for i := 0; i <= 5; i++ {
+		first |= bit(c.Dark(8, i)) << uint(i)
+	}
+	first |= bit(c.Dark(8, 7))<<6 | bit(c.Dark(8, 8))<<7 | bit(c.Dark(7, 8))<<8
+	for i := 9; i < 15; i++ {
+		first |= bit(c.Dark(14-i, 8)) << uint(i)
+	}
+	for i := 0; i < 8; i++ {
+		second |= bit(c.Dark(c.Size-1-i, 8)) << uint(i)
+	}
+	for i := 8; i < 15; i++ {
+		second |= bit(c.Dark(8, c.Size-15+i)) << uint(i)
+	}
+	assert.Equal(t, first, second)
+	assert.Equal(t, formatBits(first>>10^0x5412>>10), first)
+
+	out, err := c.PNG(200)
+	if assert.NoError(t, err) {
+		img, err := png.Decode(bytes.NewReader(out))
+		assert.NoError(t, err)
+		// 37 modules with the quiet zone are scaled by 5 pixels
+		assert.Equal(t, 185, img.Bounds().Dx())
+	}
+}
+
+func bit(dark bool) int {
+	if dark {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrDiffKeys is returned if the public key doesn't belong to the key id
+	ErrDiffKeys = errors.New(`Contract and user public keys are different`)
+	// ErrNotEnoughSigns is returned if less than the threshold of the keys have signed the data
+	ErrNotEnoughSigns = errors.New(`Not enough signatures`)
+)
+
+// SignerPublicKey returns the public key which checks the signatures of keyID. The key stored
+// in the keys table is used if it exists, because the address of the key differs from keyID
+// after the key has been changed by @1ChangeKey. Otherwise the public key of the request is used
+// and its address must be keyID. The public key of the request can be the stored key or the key
+// of keyID address, so the replaced key is passed but doesn't check the signatures anymore
+func SignerPublicKey(keyID int64, stored, public []byte) ([]byte, error) {
+	if len(public) > 0 && !bytes.Equal(public, stored) && crypto.Address(public) != keyID {
+		return nil, ErrDiffKeys
+	}
+	if len(stored) > 0 {
+		return stored, nil
+	}
+	return public, nil
+}
+
+// SignItem is the signature which is checked by CheckSignBatch. The fields are the parameters of CheckSign
+type SignItem struct {
+	PublicKeys     [][]byte
+	ForSign        string
+	Signs          []byte
+	NodeKeyOrLogin bool
+}
+
+// SignBatchError is returned by CheckSignBatch if any signature is incorrect
+type SignBatchError struct {
+	Index int // the index of the first incorrect signature
+	Err   error
+}
+
+func (e *SignBatchError) Error() string {
+	return fmt.Sprintf("incorrect signature of item %d: %v", e.Index, e.Err)
+}
+
+// CheckSignBatch checks the signatures of the items by the pool of workers and returns the result
+// of each item. If any signature is incorrect then SignBatchError is returned. The items after
+// the first incorrect one can be skipped, their results are false
+func CheckSignBatch(items []SignItem) ([]bool, error) {
+	results := make([]bool, len(items))
+	if len(items) == 0 {
+		return results, nil
+	}
+	errs := make([]error, len(items))
+	workers := runtime.NumCPU()
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		next   = int64(-1)
+		failed = int64(len(items))
+	)
+	worker := func() {
+		defer wg.Done()
+		for {
+			// the items are taken in order, so all items before the failed one are checked
+			i := atomic.AddInt64(&next, 1)
+			if i >= int64(len(items)) || i > atomic.LoadInt64(&failed) {
+				return
+			}
+			item := items[i]
+			results[i], errs[i] = CheckSign(item.PublicKeys, item.ForSign, item.Signs, item.NodeKeyOrLogin)
+			if results[i] {
+				continue
+			}
+			for {
+				cur := atomic.LoadInt64(&failed)
+				if i >= cur || atomic.CompareAndSwapInt64(&failed, cur, i) {
+					break
+				}
+			}
+		}
+	}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go worker()
+	}
+	wg.Wait()
+
+	if failed == int64(len(items)) {
+		return results, nil
+	}
+	err := errs[failed]
+	if err == nil {
+		err = crypto.ErrIncorrectSign
+	}
+	log.WithFields(log.Fields{"type": consts.CryptoError, "index": failed, "error": err}).Debug("checking batch of signatures")
+	return results, &SignBatchError{Index: int(failed), Err: err}
+}
+
+// CheckMultiSign checks the signatures of the co-signers. The signs have the length-prefixed signature
+// of every public key, the empty signature means that the key hasn't signed the data. The incorrect
+// signature is the error, at least threshold of the keys must sign the data.
+// It returns the flags of the keys which have signed the data
+func CheckMultiSign(publicKeys [][]byte, forSign string, signs []byte, threshold int) ([]bool, error) {
+	signed := make([]bool, len(publicKeys))
+	var count int
+	for i, public := range publicKeys {
+		if len(signs) == 0 {
+			return nil, fmt.Errorf("no sign of key %d", i)
+		}
+		length, err := converter.DecodeLength(&signs)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err}).Error("decoding signs length")
+			return nil, err
+		}
+		if length < 0 || length > int64(len(signs)) {
+			log.WithFields(log.Fields{"type": consts.SizeDoesNotMatch, "length": length}).Error("wrong length of sign")
+			return nil, fmt.Errorf("wrong length %d of sign %d", length, i)
+		}
+		if length == 0 {
+			continue
+		}
+		sign := converter.BytesShift(&signs, length)
+		if len(public) == 0 {
+			return nil, fmt.Errorf("key %d can't sign", i)
+		}
+		if signed[i], err = crypto.CheckSign(public, forSign, sign); !signed[i] {
+			if err == nil {
+				err = crypto.ErrIncorrectSign
+			}
+			return nil, err
+		}
+		count++
+	}
+	if len(signs) > 0 {
+		return nil, fmt.Errorf("%d extra bytes after signs", len(signs))
+	}
+	if count < threshold || count == 0 {
+		return nil, ErrNotEnoughSigns
+	}
+	return signed, nil
+}
@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signItems(t testing.TB, count int) []SignItem {
+	items := make([]SignItem, count)
+	for i := range items {
+		version := crypto.SchemeECDSA
+		if i%4 == 3 {
+			version = crypto.SchemeEd25519
+		}
+		priv, pub, err := crypto.GenSchemeKeys(version)
+		require.NoError(t, err)
+		forSign := fmt.Sprintf("%d,forsign", i)
+		sign, err := crypto.Sign(hex.EncodeToString(priv), forSign)
+		require.NoError(t, err)
+		items[i] = SignItem{PublicKeys: [][]byte{pub}, ForSign: forSign,
+			Signs: converter.EncodeLengthPlusData(sign)}
+	}
+	return items
+}
+
+func TestCheckSignBatch(t *testing.T) {
+	items := signItems(t, 20)
+	results, err := CheckSignBatch(items)
+	require.NoError(t, err)
+	for i, ok := range results {
+		assert.True(t, ok, i)
+	}
+
+	items[7].ForSign += `1`
+	items[12].ForSign += `1`
+	results, err = CheckSignBatch(items)
+	require.IsType(t, &SignBatchError{}, err)
+	assert.Equal(t, 7, err.(*SignBatchError).Index)
+	assert.False(t, results[7])
+	for i := 0; i < 7; i++ {
+		assert.True(t, results[i], i)
+	}
+
+	results, err = CheckSignBatch(nil)
+	assert.NoError(t, err)
+	assert.Len(t, results, 0)
+}
+
+func TestCheckSignMalformed(t *testing.T) {
+	item := signItems(t, 1)[0]
+	// the wrong encoded length of the signs and the wrong key return the error
+	for _, signs := range [][]byte{{0x85, 1}, {0x81}, {0x7f, 1, 2}} {
+		ok, err := CheckSign(item.PublicKeys, item.ForSign, signs, false)
+		assert.False(t, ok)
+		assert.Error(t, err)
+	}
+	ok, err := CheckSign([][]byte{{crypto.SchemeEd25519, 1}}, item.ForSign, item.Signs, false)
+	assert.False(t, ok)
+	assert.Error(t, err)
+}
+
+func TestCheckMultiSign(t *testing.T) {
+	items := signItems(t, 3)
+	const forSign = `multi,forsign`
+	publicKeys := make([][]byte, len(items))
+	signs := make([][]byte, len(items))
+	for i := range items {
+		priv, pub, err := crypto.GenSchemeKeys(crypto.SchemeEd25519)
+		require.NoError(t, err)
+		publicKeys[i] = pub
+		signs[i], err = crypto.Sign(hex.EncodeToString(priv), forSign)
+		require.NoError(t, err)
+	}
+	join := func(list ...[]byte) (ret []byte) {
+		for _, sign := range list {
+			ret = append(ret, converter.EncodeLengthPlusData(sign)...)
+		}
+		return
+	}
+
+	signed, err := CheckMultiSign(publicKeys, forSign, join(signs[0], nil, signs[2]), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, true}, signed)
+
+	_, err = CheckMultiSign(publicKeys, forSign, join(signs[0], nil, nil), 2)
+	assert.Equal(t, ErrNotEnoughSigns, err)
+	// the signature of one key can't be used by another key
+	_, err = CheckMultiSign(publicKeys, forSign, join(signs[0], signs[0], nil), 2)
+	assert.Error(t, err)
+	_, err = CheckMultiSign(publicKeys, forSign+`1`, join(signs...), 1)
+	assert.Error(t, err)
+	// every key must have the signature and the extra data are the error
+	_, err = CheckMultiSign(publicKeys, forSign, join(signs[0], signs[1]), 2)
+	assert.Error(t, err)
+	_, err = CheckMultiSign(publicKeys, forSign, append(join(signs...), 0), 2)
+	assert.Error(t, err)
+	_, err = CheckMultiSign([][]byte{nil, publicKeys[1]}, forSign, join(signs[0], signs[1]), 1)
+	assert.Error(t, err)
+}
+
+func BenchmarkCheckSign(b *testing.B) {
+	items := signItems(b, 64)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, item := range items {
+			if ok, err := CheckSign(item.PublicKeys, item.ForSign, item.Signs, item.NodeKeyOrLogin); !ok || err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkCheckSignBatch(b *testing.B) {
+	items := signItems(b, 64)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := CheckSignBatch(items); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSignerPublicKey(t *testing.T) {
+	_, oldPub, err := crypto.GenSchemeKeys(crypto.SchemeECDSA)
+	require.NoError(t, err)
+	newPriv, newPub, err := crypto.GenSchemeKeys(crypto.SchemeEd25519)
+	require.NoError(t, err)
+	keyID := crypto.Address(oldPub)
+	require.NotEqual(t, keyID, crypto.Address(newPub))
+
+	// the key isn't stored, the public key of the address is used
+	public, err := SignerPublicKey(keyID, nil, oldPub)
+	require.NoError(t, err)
+	assert.Equal(t, oldPub, public)
+	_, err = SignerPublicKey(keyID, nil, newPub)
+	assert.Equal(t, ErrDiffKeys, err)
+
+	// the key has been changed, the stored key is used by the key id instead of the address
+	for _, request := range [][]byte{nil, newPub, oldPub} {
+		public, err = SignerPublicKey(keyID, newPub, request)
+		require.NoError(t, err)
+		assert.Equal(t, newPub, public)
+	}
+	_, otherPub, err := crypto.GenSchemeKeys(crypto.SchemeECDSA)
+	require.NoError(t, err)
+	_, err = SignerPublicKey(keyID, newPub, otherPub)
+	assert.Equal(t, ErrDiffKeys, err)
+
+	// only the new key checks the signatures of the key id
+	sign, err := crypto.Sign(hex.EncodeToString(newPriv), `forsign`)
+	require.NoError(t, err)
+	ok, err := CheckSign([][]byte{public}, `forsign`, sign, true)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, _ = CheckSign([][]byte{oldPub}, `forsign`, sign, true)
+	assert.False(t, ok)
+}
@@ -0,0 +1,76 @@
+package tx
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// MaxAmountDigits is the maximum number of the digits of the integer part of MaxSum and PayOver,
+	// it is the precision of the money columns
+	MaxAmountDigits = 30
+	// MaxAmountScale is the maximum number of the digits of the fractional part of MaxSum and PayOver
+	MaxAmountScale = 18
+)
+
+// amountFormat allows the plain non-negative decimals only, there are no signs, exponents and
+// separators except the point
+var amountFormat = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// AmountError is returned if MaxSum or PayOver of the transaction isn't a valid decimal
+type AmountError struct {
+	Field  string
+	Value  string
+	Reason string
+}
+
+func (e *AmountError) Error() string {
+	return fmt.Sprintf(`%s %q is not valid: %s`, e.Field, e.Value, e.Reason)
+}
+
+// CheckAmount checks the value of the decimal field of the transaction header, the empty value is allowed
+func CheckAmount(field, value string) error {
+	if len(value) == 0 {
+		return nil
+	}
+	if !amountFormat.MatchString(value) {
+		return &AmountError{Field: field, Value: value, Reason: `must be a non-negative decimal number`}
+	}
+	integer, fraction := value, ``
+	if i := strings.IndexByte(value, '.'); i >= 0 {
+		integer, fraction = value[:i], value[i+1:]
+	}
+	if len(strings.TrimLeft(integer, `0`)) > MaxAmountDigits {
+		return &AmountError{Field: field, Value: value,
+			Reason: fmt.Sprintf(`integer part is longer than %d digits`, MaxAmountDigits)}
+	}
+	if len(fraction) > MaxAmountScale {
+		return &AmountError{Field: field, Value: value,
+			Reason: fmt.Sprintf(`fractional part is longer than %d digits`, MaxAmountScale)}
+	}
+	if _, err := decimal.NewFromString(value); err != nil {
+		return &AmountError{Field: field, Value: value, Reason: err.Error()}
+	}
+	return nil
+}
+
+// CheckAmounts checks MaxSum and PayOver of the transaction
+func (s *SmartContract) CheckAmounts() error {
+	if err := CheckAmount(`max_sum`, s.MaxSum); err != nil {
+		return err
+	}
+	return CheckAmount(`payover`, s.PayOver)
+}
+
+// PayOverValue returns PayOver as decimal. The transaction is checked by CheckAmounts before
+// it is played, so the empty value is zero
+func (s *SmartContract) PayOverValue() decimal.Decimal {
+	value, err := decimal.NewFromString(s.PayOver)
+	if err != nil {
+		return decimal.Zero
+	}
+	return value
+}
@@ -0,0 +1,45 @@
+package tx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAmount(t *testing.T) {
+	for _, value := range []string{``, `0`, `10`, `10.5`, `0.000000000000000001`, strings.Repeat(`9`, MaxAmountDigits),
+		`000` + strings.Repeat(`1`, MaxAmountDigits)} {
+		assert.NoError(t, CheckAmount(`max_sum`, value), value)
+	}
+	for _, value := range []string{`10,5`, `1 000`, `1e999`, `1E2`, `-1`, `+1`, `.5`, `5.`, `0x10`, `NaN`, `Inf`,
+		`1.` + strings.Repeat(`0`, MaxAmountScale+1), strings.Repeat(`9`, MaxAmountDigits+1)} {
+		err := CheckAmount(`payover`, value)
+		require.Error(t, err, value)
+		amountErr, ok := err.(*AmountError)
+		require.True(t, ok, value)
+		assert.Equal(t, `payover`, amountErr.Field)
+		assert.Equal(t, value, amountErr.Value)
+	}
+}
+
+func TestCheckAmounts(t *testing.T) {
+	smartTx := testSmart
+	require.NoError(t, smartTx.CheckAmounts())
+	assert.True(t, smartTx.PayOverValue().Equal(decimal.New(5, -1)))
+
+	smartTx.MaxSum = `1e999`
+	err := smartTx.CheckAmounts()
+	require.IsType(t, &AmountError{}, err)
+	assert.Equal(t, `max_sum`, err.(*AmountError).Field)
+
+	smartTx.MaxSum, smartTx.PayOver = ``, `10,5`
+	err = smartTx.CheckAmounts()
+	require.IsType(t, &AmountError{}, err)
+	assert.Equal(t, `payover`, err.(*AmountError).Field)
+
+	smartTx.PayOver = ``
+	assert.True(t, smartTx.PayOverValue().Equal(decimal.Zero))
+}
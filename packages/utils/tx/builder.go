@@ -9,11 +9,20 @@ import (
 	"github.com/GenesisKernel/go-genesis/packages/crypto"
 	"github.com/GenesisKernel/go-genesis/packages/model"
 	log "github.com/sirupsen/logrus"
-	"gopkg.in/vmihailenco/msgpack.v2"
 )
 
-// BuildTransaction creates transaction
+// NodeTxExpiration is the lifetime in seconds of the transactions which are signed by the node
+const NodeTxExpiration = 600
+
+// BuildTransaction creates transaction, the transaction expires in NodeTxExpiration seconds
+// if the expiration isn't defined and is signed in the format of ForSignVersion by default
 func BuildTransaction(smartTx SmartContract, privKey, pubKey string, params ...string) error {
+	if smartTx.Expiration == 0 {
+		smartTx.Expiration = smartTx.Time + NodeTxExpiration
+	}
+	if smartTx.SignVersion == 0 {
+		smartTx.SignVersion = ForSignVersion
+	}
 	signPrms := []string{smartTx.ForSign()}
 	signPrms = append(signPrms, params...)
 	signature, err := crypto.Sign(
@@ -31,9 +40,9 @@ func BuildTransaction(smartTx SmartContract, privKey, pubKey string, params ...s
 		return err
 	}
 
-	data, err := msgpack.Marshal(smartTx)
+	data, err := smartTx.Marshal()
 	if err != nil {
-		log.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling smart contract to msgpack")
+		log.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling smart contract")
 		return err
 	}
 	data = append([]byte{128}, data...)
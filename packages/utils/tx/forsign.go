@@ -0,0 +1,74 @@
+package tx
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+)
+
+// The formats of the signed data. ForSignV1 is the list of the values in the fixed order,
+// ForSignV2 is the version number and the sorted key=value pairs of all fields
+const (
+	ForSignV1 = 1
+	ForSignV2 = 2
+	// ForSignVersion is the format of the signed data of the new transactions
+	ForSignVersion = ForSignV2
+)
+
+var (
+	// ErrSignVersion is returned if the format of the signed data is unknown
+	ErrSignVersion = errors.New(`unknown version of signed data`)
+	// ErrSignVersionV1 is returned if the positional format of the signed data is disabled
+	ErrSignVersionV1 = errors.New(`signed data of version 1 is disabled`)
+)
+
+// CheckSignVersion checks the format of the signed data of the transaction
+func (s *SmartContract) CheckSignVersion(allowV1 bool) error {
+	switch s.SignVersion {
+	case 0, ForSignV1:
+		if !allowV1 {
+			return ErrSignVersionV1
+		}
+	case ForSignV2:
+	default:
+		return ErrSignVersion
+	}
+	return nil
+}
+
+// forSignV2 returns the canonical format, every field is present even if it's empty
+func (s SmartContract) forSignV2() string {
+	values := url.Values{}
+	for key, val := range map[string]int64{
+		`type`: int64(s.Type), `time`: s.Time, `ecosystem_id`: s.EcosystemID, `key_id`: s.KeyID,
+		`role_id`: s.RoleID, `network_id`: s.NetworkID, `node_position`: s.NodePosition,
+		`token_ecosystem`: s.TokenEcosystem, `signed_by`: s.SignedBy, `expiration`: s.Expiration,
+		`nonce`: s.Nonce, `threshold`: s.Threshold,
+	} {
+		values.Set(key, converter.Int64ToStr(val))
+	}
+	values.Set(`request_id`, s.RequestID)
+	values.Set(`max_sum`, s.MaxSum)
+	values.Set(`payover`, s.PayOver)
+	values.Set(`signers`, joinSigners(s.Signers))
+	return fmt.Sprintf(`%d;%s`, s.SignVersion, values.Encode())
+}
+
+// ParamsForSign returns the signed data of the additional signature of the contract parameters
+// in the format of version
+func ParamsForSign(version int64, time string, keyID int64, names, values []string) string {
+	if version >= ForSignV2 {
+		ret := url.Values{`time`: {time}, `key_id`: {converter.Int64ToStr(keyID)}}
+		for i, name := range names {
+			ret.Set(`param_`+name, values[i])
+		}
+		return fmt.Sprintf(`%d;%s`, version, ret.Encode())
+	}
+	ret := fmt.Sprintf(`%s,%d`, time, uint64(keyID))
+	for _, val := range values {
+		ret += `,` + val
+	}
+	return ret
+}
@@ -2,13 +2,15 @@ package tx
 
 // Header is contain header data
 type Header struct {
-	Type          int
-	Time          int64
-	EcosystemID   int64
-	KeyID         int64
-	RoleID        int64
-	NetworkID     int64
-	NodePosition  int64
-	PublicKey     []byte
-	BinSignatures []byte
+	Type          int    `bin:"1,int"`
+	Time          int64  `bin:"2,int"`
+	EcosystemID   int64  `bin:"3,int"`
+	KeyID         int64  `bin:"4,int"`
+	RoleID        int64  `bin:"5,int"`
+	NetworkID     int64  `bin:"6,int"`
+	NodePosition  int64  `bin:"7,int"`
+	PublicKey     []byte `bin:"15,bytes"`
+	BinSignatures []byte `bin:"16,bytes"`
+	// SignVersion is the format of the signed data, 0 is the same as ForSignV1
+	SignVersion int64 `bin:"13,int,v4"`
 }
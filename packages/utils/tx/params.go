@@ -0,0 +1,152 @@
+package tx
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+// The maximum sizes of the public key and the signature which are used by EstimateSize
+const (
+	estimatePublicKeySize = 64
+	estimateSignSize      = 72
+	estimateRequestIDSize = 36
+)
+
+// ContractFields returns the parameters of the contract by the full name like @1MoneyTransfer,
+// it's defined by the smart package
+var ContractFields func(name string) ([]*script.FieldInfo, bool)
+
+// EncodeParams serializes the values of the contract parameters in the order of fields.
+// The array parameter has the count in the key name[] and the items in the keys name[i].
+// readFile returns the uploaded file of the file parameter, if it's nil the file parameters are skipped
+func EncodeParams(fields []*script.FieldInfo, values map[string]string,
+	readFile func(name string) (*File, error)) ([]byte, error) {
+	idata := []byte{}
+	for _, fitem := range fields {
+		if readFile != nil && fitem.ContainsTag(script.TagFile) {
+			file, err := readFile(fitem.Name)
+			if err != nil {
+				log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading file of contract parameter")
+				return nil, err
+			}
+			serialFile, err := msgpack.Marshal(file)
+			if err != nil {
+				log.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling file to msgpack")
+				return nil, err
+			}
+			idata = append(append(idata, converter.EncodeLength(int64(len(serialFile)))...), serialFile...)
+			continue
+		}
+
+		val := strings.TrimSpace(values[fitem.Name])
+		if strings.Contains(fitem.Tags, `address`) {
+			val = converter.Int64ToStr(converter.StringToAddress(val))
+		}
+		switch fitem.Type.String() {
+		case `[]interface {}`:
+			var list []string
+			if count := converter.StrToInt(values[fitem.Name+`[]`]); count > 0 {
+				for i := 0; i < count; i++ {
+					list = append(list, values[fmt.Sprintf(`%s[%d]`, fitem.Name, i)])
+				}
+			}
+			if len(list) == 0 && len(val) > 0 {
+				list = append(list, val)
+			}
+			idata = append(idata, converter.EncodeLength(int64(len(list)))...)
+			for _, ilist := range list {
+				blist := []byte(ilist)
+				idata = append(append(idata, converter.EncodeLength(int64(len(blist)))...), blist...)
+			}
+		case `uint64`:
+			converter.BinMarshal(&idata, converter.StrToUint64(val))
+		case `int64`:
+			converter.EncodeLenInt64(&idata, converter.StrToInt64(val))
+		case `float64`:
+			converter.BinMarshal(&idata, converter.StrToFloat64(val))
+		case `string`, script.Decimal:
+			idata = append(append(idata, converter.EncodeLength(int64(len(val)))...), []byte(val)...)
+		case `[]uint8`:
+			bytes, err := hex.DecodeString(val)
+			if err != nil {
+				log.WithFields(log.Fields{"type": consts.ConversionError, "error": err, "value": val}).Error("decoding value from hex")
+				return nil, err
+			}
+			idata = append(append(idata, converter.EncodeLength(int64(len(bytes)))...), bytes...)
+		}
+	}
+	return idata, nil
+}
+
+// EstimateSize returns the size of the contract transaction which the node gets, it's compared with
+// max_tx_size. params has the values of the contract parameters, the money is in the minimal units and
+// the arrays are []interface{} or []string. The header, the public key and the signature are counted
+// by their maximum sizes. -1 is returned if the contract is unknown or the parameters are malformed
+func EstimateSize(contract string, params map[string]interface{}, files map[string][]byte) int64 {
+	if ContractFields == nil {
+		return -1
+	}
+	fields, ok := ContractFields(contract)
+	if !ok {
+		return -1
+	}
+	values := make(map[string]string, len(params))
+	for name, val := range params {
+		var list []string
+		switch v := val.(type) {
+		case []string:
+			list = v
+		case []interface{}:
+			for _, item := range v {
+				list = append(list, fmt.Sprint(item))
+			}
+		default:
+			values[name] = fmt.Sprint(val)
+			continue
+		}
+		values[name+`[]`] = converter.IntToStr(len(list))
+		for i, item := range list {
+			values[fmt.Sprintf(`%s[%d]`, name, i)] = item
+		}
+	}
+	data, err := EncodeParams(fields, values, func(name string) (*File, error) {
+		content, ok := files[name]
+		if !ok {
+			return nil, nil
+		}
+		hash := md5.Sum(content)
+		return &File{FileHeader: FileHeader{Hash: hex.EncodeToString(hash[:]),
+			MimeType: http.DetectContentType(content)}, Data: content}, nil
+	})
+	if err != nil {
+		return -1
+	}
+	smartTx := SmartContract{
+		Header: Header{Type: -1, Time: -1, EcosystemID: -1, KeyID: -1, RoleID: -1, NetworkID: -1, NodePosition: -1,
+			PublicKey: make([]byte, estimatePublicKeySize), SignVersion: -1,
+			BinSignatures: converter.EncodeLengthPlusData(make([]byte, estimateSignSize))},
+		RequestID:      strings.Repeat(`0`, estimateRequestIDSize),
+		TokenEcosystem: -1,
+		SignedBy:       -1,
+		Expiration:     -1,
+		Nonce:          -1,
+		Threshold:      -1,
+		Data:           data,
+	}
+	bin, err := smartTx.Marshal()
+	if err != nil {
+		return -1
+	}
+	// the transaction is sent with the leading byte of its type
+	return int64(len(bin)) + 1
+}
@@ -0,0 +1,67 @@
+package tx
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/script"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testFields = []*script.FieldInfo{
+	{Name: `Name`, Type: reflect.TypeOf(``)},
+	{Name: `Amount`, Type: reflect.TypeOf(int64(0))},
+	{Name: `Recipient`, Type: reflect.TypeOf(``), Tags: `address`},
+	{Name: `List`, Type: reflect.TypeOf([]interface{}{})},
+	{Name: `Bin`, Type: reflect.TypeOf([]byte{})},
+	{Name: `Image`, Type: reflect.TypeOf(map[string]interface{}{}), Tags: `file`},
+}
+
+func TestEstimateSize(t *testing.T) {
+	defer func(orig func(string) ([]*script.FieldInfo, bool)) { ContractFields = orig }(ContractFields)
+	ContractFields = func(name string) ([]*script.FieldInfo, bool) {
+		return testFields, name == `@1Test`
+	}
+	image := []byte(`GIF89a image`)
+	params := map[string]interface{}{`Name`: `name`, `Amount`: 1000, `Recipient`: `1234-5678-9012-3456-7890`,
+		`List`: []interface{}{`a`, 2}, `Bin`: `0a0b`}
+	size := EstimateSize(`@1Test`, params, map[string][]byte{`Image`: image})
+
+	// the same parameters are sent by the prepare and contract requests
+	values := map[string]string{`Name`: `name`, `Amount`: `1000`, `Recipient`: `1234-5678-9012-3456-7890`,
+		`List[]`: `2`, `List[0]`: `a`, `List[1]`: `2`, `Bin`: `0a0b`}
+	data, err := EncodeParams(testFields, values, func(name string) (*File, error) {
+		return &File{FileHeader: FileHeader{Hash: `d41d8cd98f00b204e9800998ecf8427e`, MimeType: `image/gif`},
+			Data: image}, nil
+	})
+	require.NoError(t, err)
+	smartTx := testSmart
+	smartTx.PublicKey, smartTx.BinSignatures = make([]byte, 64), converter.EncodeLengthPlusData(make([]byte, 71))
+	smartTx.RequestID, smartTx.Data = `3f2504e0-4f89-11d3-9a0c-0305e82c3301`, data
+	bin, err := smartTx.Marshal()
+	require.NoError(t, err)
+	actual := int64(len(bin)) + 1
+	// the integers of the header are counted by 9 bytes
+	assert.True(t, size >= actual, `%d < %d`, size, actual)
+	assert.True(t, size-actual < 128, `%d - %d`, size, actual)
+
+	assert.Equal(t, int64(-1), EstimateSize(`@1Unknown`, params, nil))
+	params[`Bin`] = `wrong`
+	assert.Equal(t, int64(-1), EstimateSize(`@1Test`, params, nil))
+}
+
+func TestEncodeParams(t *testing.T) {
+	values := map[string]string{`Name`: ` name `, `Amount`: `5`, `List`: `single`}
+	data, err := EncodeParams(testFields, values, nil)
+	require.NoError(t, err)
+	expected := []byte{4, 'n', 'a', 'm', 'e', 1, 5, 1, '0', 1, 6, 's', 'i', 'n', 'g', 'l', 'e', 0}
+	assert.Equal(t, expected, data)
+
+	// the file parameters are serialized only with the files
+	withFiles, err := EncodeParams(testFields, values, func(name string) (*File, error) { return nil, nil })
+	require.NoError(t, err)
+	assert.Equal(t, append(expected, 1, 0xc0), withFiles)
+}
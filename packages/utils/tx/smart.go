@@ -1,20 +1,176 @@
 package tx
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 
-// SmartContract is storing smart contract data
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+// SmartContractVersion is the version of the binary format of SmartContract.
+// The version 0 is msgpack of the struct, the version 1 has the fixed order of the fields,
+// the version 2 has also Expiration and Nonce, the version 3 has also Threshold and Signers,
+// the version 4 has also SignVersion
+const SmartContractVersion = 4
+
+// MaxSigners is the maximum number of the co-signers of the transaction
+const MaxSigners = 16
+
+var (
+	// ErrExpired is returned if the expiration time of the transaction has passed
+	ErrExpired = errors.New(`transaction is expired`)
+	// ErrNoReplayProtection is returned in the strict mode if the transaction has no expiration or nonce
+	ErrNoReplayProtection = errors.New(`transaction must have expiration and nonce`)
+	// ErrSigners is returned if the list of the co-signers or the threshold is wrong
+	ErrSigners = errors.New(`wrong signers of transaction`)
+	// ErrSignersAddress is returned if the key id isn't the address of the co-signers
+	ErrSignersAddress = errors.New(`key id isn't the address of the signers`)
+)
+
+// SmartContract is storing smart contract data, the bin tags define the binary format of the versions
 type SmartContract struct {
 	Header
-	RequestID      string
-	TokenEcosystem int64
-	MaxSum         string
-	PayOver        string
-	SignedBy       int64
-	Data           []byte
+	RequestID      string `bin:"17,bytes"`
+	TokenEcosystem int64  `bin:"8,int"`
+	MaxSum         string `bin:"18,bytes"`
+	PayOver        string `bin:"19,bytes"`
+	SignedBy       int64  `bin:"9,int"`
+	Expiration     int64  `bin:"10,int,v2"`
+	Nonce          int64  `bin:"11,int,v2"`
+	// Threshold of Signers must sign the transaction of the key id which is the address of them,
+	// BinSignatures has the signature of every item of Signers, the empty signature is allowed
+	Threshold int64   `bin:"12,int,v3"`
+	Signers   []int64 `bin:"14,ints,v3"`
+	Data      []byte  `bin:"20,bytes"`
 }
 
-// ForSign is converting SmartContract to string
+// ForSign is converting SmartContract to string in the format of SignVersion
 func (s SmartContract) ForSign() string {
-	return fmt.Sprintf("%s,%d,%d,%d,%d,%d,%s,%s,%d", s.RequestID, s.Type, s.Time, s.KeyID, s.EcosystemID,
+	if s.SignVersion >= ForSignV2 {
+		return s.forSignV2()
+	}
+	return s.forSignV1()
+}
+
+// forSignV1 returns the positional format. Expiration, Nonce and Signers are added only if they
+// are defined, so the signatures of the old transactions remain the same
+func (s SmartContract) forSignV1() string {
+	ret := fmt.Sprintf("%s,%d,%d,%d,%d,%d,%s,%s,%d", s.RequestID, s.Type, s.Time, s.KeyID, s.EcosystemID,
 		s.TokenEcosystem, s.MaxSum, s.PayOver, s.SignedBy)
+	if s.Expiration != 0 || s.Nonce != 0 {
+		ret += fmt.Sprintf(",%d,%d", s.Expiration, s.Nonce)
+	}
+	if len(s.Signers) > 0 {
+		ret += fmt.Sprintf(",%d,%s", s.Threshold, joinSigners(s.Signers))
+	}
+	return ret
+}
+
+// joinSigners returns the sorted list of the key ids which are separated by semicolon
+func joinSigners(signers []int64) string {
+	sorted := append([]int64{}, signers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	list := make([]string, len(sorted))
+	for i, id := range sorted {
+		list[i] = converter.Int64ToStr(id)
+	}
+	return strings.Join(list, `;`)
+}
+
+// SignersAddress returns the key id of the account which is managed by threshold of signers.
+// The order of signers doesn't matter
+func SignersAddress(threshold int64, signers []int64) int64 {
+	return crypto.Address([]byte(fmt.Sprintf("signers,%d,%s", threshold, joinSigners(signers))))
+}
+
+// CheckSigners checks the list of the co-signers of the transaction. The transaction is signed either
+// by the key or by the co-signers, the co-signers can't be combined with SignedBy
+func (s *SmartContract) CheckSigners() error {
+	if len(s.Signers) == 0 {
+		if s.Threshold != 0 {
+			return ErrSigners
+		}
+		return nil
+	}
+	if s.SignedBy != 0 || len(s.Signers) > MaxSigners || s.Threshold < 1 ||
+		s.Threshold > int64(len(s.Signers)) {
+		return ErrSigners
+	}
+	unique := make(map[int64]bool, len(s.Signers))
+	for _, id := range s.Signers {
+		if id == 0 || unique[id] {
+			return ErrSigners
+		}
+		unique[id] = true
+	}
+	if s.KeyID != SignersAddress(s.Threshold, s.Signers) {
+		return ErrSignersAddress
+	}
+	return nil
+}
+
+// CheckReplay checks the expiration of the transaction at checkTime. In the strict mode the expiration
+// is required and the nonce is required if the transaction is signed by the sender,
+// the transactions which are signed by the node on behalf of the key have no nonce
+func (s *SmartContract) CheckReplay(checkTime int64, strict bool) error {
+	if s.Expiration > 0 && checkTime > s.Expiration {
+		return ErrExpired
+	}
+	if strict && (s.Expiration <= 0 || (s.SignedBy == 0 && s.Nonce <= 0)) {
+		return ErrNoReplayProtection
+	}
+	return nil
+}
+
+// Marshal returns the binary data of the transaction in the format of SmartContractVersion
+func (s *SmartContract) Marshal() ([]byte, error) {
+	return s.marshalVersion(SmartContractVersion)
+}
+
+// marshalVersion encodes the fields in the order of their bin tags, see converter.MarshalLayout
+func (s *SmartContract) marshalVersion(version byte) ([]byte, error) {
+	payload, err := converter.MarshalLayout(s, version)
+	if err != nil {
+		return nil, err
+	}
+	return converter.EncodeVersioned(version, payload), nil
+}
+
+// Unmarshal parses the binary data of the transaction, the data of the version 0 are accepted
+func (s *SmartContract) Unmarshal(data []byte) error {
+	return converter.VersionDecoders{
+		0: s.unmarshalMsgpack,
+		1: func(payload []byte) error { return s.unmarshalFields(1, payload) },
+		2: func(payload []byte) error { return s.unmarshalFields(2, payload) },
+		3: func(payload []byte) error { return s.unmarshalFields(3, payload) },
+		4: func(payload []byte) error { return s.unmarshalFields(4, payload) },
+	}.Decode(data)
+}
+
+// unmarshalMsgpack parses the data of the version 0. The panic of the malformed data is returned as the error
+func (s *SmartContract) unmarshalMsgpack(payload []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf(`malformed msgpack of smart contract: %v`, r)
+		}
+	}()
+	return msgpack.Unmarshal(payload, s)
+}
+
+// unmarshalFields parses the data of the versions which have the fixed order of the fields
+func (s *SmartContract) unmarshalFields(version byte, payload []byte) error {
+	var ret SmartContract
+	if err := converter.UnmarshalLayout(payload, &ret, version); err != nil {
+		return err
+	}
+	if len(ret.Signers) > MaxSigners {
+		return ErrSigners
+	}
+	*s = ret
+	return nil
 }
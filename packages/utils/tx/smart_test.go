@@ -0,0 +1,238 @@
+package tx
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+var testSmart = SmartContract{
+	Header: Header{
+		Type:          5,
+		Time:          1530000000,
+		EcosystemID:   2,
+		KeyID:         -7097400992759152070,
+		RoleID:        3,
+		NetworkID:     1,
+		PublicKey:     []byte{1, 2, 3},
+		BinSignatures: make([]byte, 200),
+	},
+	RequestID:      `request`,
+	TokenEcosystem: 1,
+	MaxSum:         `1000`,
+	PayOver:        `0.5`,
+	SignedBy:       100,
+	Expiration:     1530000600,
+	Nonce:          12,
+	Data:           []byte(`data`),
+}
+
+func TestSmartContractForSign(t *testing.T) {
+	old := testSmart
+	old.Expiration, old.Nonce = 0, 0
+	assert.Equal(t, `request,5,1530000000,-7097400992759152070,2,1,1000,0.5,100`, old.ForSign())
+	assert.Equal(t, old.ForSign()+`,1530000600,12`, testSmart.ForSign())
+
+}
+
+var update = flag.Bool(`update`, false, `update the golden files`)
+
+// checkGolden compares the value with the golden file, the file is rewritten with -update
+func checkGolden(t *testing.T, name, value string) {
+	path := filepath.Join(`testdata`, name+`.golden`)
+	if *update {
+		require.NoError(t, ioutil.WriteFile(path, []byte(value), 0644))
+	}
+	golden, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), value, name)
+}
+
+func TestForSignGolden(t *testing.T) {
+	multi := testSmart
+	multi.SignedBy, multi.Threshold, multi.Signers = 0, 2, []int64{7, -5, 3}
+	for _, version := range []int64{ForSignV1, ForSignV2} {
+		v := testSmart
+		v.SignVersion = version
+		name := `forsign_v` + converter.Int64ToStr(version)
+		checkGolden(t, name, v.ForSign())
+		multi.SignVersion = version
+		checkGolden(t, name+`_signers`, multi.ForSign())
+		checkGolden(t, name+`_params`, ParamsForSign(version, `1530000000`, testSmart.KeyID,
+			[]string{`Amount`, `Recipient`}, []string{`100`, `a=b&c`}))
+	}
+	// the version 0 is signed like the version 1
+	assert.Equal(t, testSmart.forSignV1(), testSmart.ForSign())
+}
+
+func mustMarshal(tb testing.TB, s SmartContract, version byte) []byte {
+	data, err := s.marshalVersion(version)
+	require.NoError(tb, err)
+	return data
+}
+
+// TestMarshalGolden checks that the binary format of every version remains the same
+func TestMarshalGolden(t *testing.T) {
+	multi := testSmart
+	multi.Threshold, multi.Signers, multi.SignVersion = 2, []int64{7, -5, 3}, ForSignV2
+	for version := byte(1); version <= SmartContractVersion; version++ {
+		checkGolden(t, fmt.Sprintf(`marshal_v%d`, version), hex.EncodeToString(mustMarshal(t, multi, version)))
+	}
+	var empty SmartContract
+	checkGolden(t, `marshal_empty`, hex.EncodeToString(mustMarshal(t, empty, SmartContractVersion)))
+}
+
+func TestCheckSignVersion(t *testing.T) {
+	for _, item := range []struct {
+		version int64
+		allowV1 bool
+		err     error
+	}{
+		{0, true, nil}, {ForSignV1, true, nil}, {ForSignV2, true, nil}, {ForSignV2, false, nil},
+		{0, false, ErrSignVersionV1}, {ForSignV1, false, ErrSignVersionV1},
+		{3, true, ErrSignVersion}, {-1, true, ErrSignVersion},
+	} {
+		s := testSmart
+		s.SignVersion = item.version
+		assert.Equal(t, item.err, s.CheckSignVersion(item.allowV1), item)
+	}
+}
+
+func TestSmartContractCheckReplay(t *testing.T) {
+	old := testSmart
+	old.Expiration, old.Nonce = 0, 0
+	for _, strict := range []bool{false, true} {
+		assert.NoError(t, testSmart.CheckReplay(testSmart.Expiration, strict))
+		assert.Equal(t, ErrExpired, testSmart.CheckReplay(testSmart.Expiration+1, strict))
+	}
+	assert.NoError(t, old.CheckReplay(testSmart.Expiration+1, false))
+	assert.Equal(t, ErrNoReplayProtection, old.CheckReplay(testSmart.Time, true))
+
+	// the node signs the transactions without the nonce
+	node := testSmart
+	node.Nonce = 0
+	assert.NoError(t, node.CheckReplay(testSmart.Time, true))
+	node.SignedBy = 0
+	assert.Equal(t, ErrNoReplayProtection, node.CheckReplay(testSmart.Time, true))
+}
+
+func TestSmartContractMarshal(t *testing.T) {
+	data, err := testSmart.Marshal()
+	require.NoError(t, err)
+	var ret SmartContract
+	require.NoError(t, ret.Unmarshal(data))
+	assert.Equal(t, testSmart, ret)
+
+	again, err := ret.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, data, again)
+
+	// the transactions of the version 0 are accepted
+	old, err := msgpack.Marshal(testSmart)
+	require.NoError(t, err)
+	ret = SmartContract{}
+	require.NoError(t, ret.Unmarshal(old))
+	assert.Equal(t, testSmart, ret)
+
+	// the transactions of the version 1 have no expiration and nonce
+	v1 := testSmart
+	v1.Expiration, v1.Nonce = 0, 0
+	ret = SmartContract{}
+	require.NoError(t, ret.Unmarshal(mustMarshal(t, v1, 1)))
+	assert.Equal(t, v1, ret)
+	ret = SmartContract{}
+	require.NoError(t, ret.Unmarshal(mustMarshal(t, testSmart, 2)))
+	assert.Equal(t, testSmart, ret)
+
+	multi := testSmart
+	multi.Threshold, multi.Signers = 2, []int64{7, -5, 3}
+	data, err = multi.Marshal()
+	require.NoError(t, err)
+	ret = SmartContract{}
+	require.NoError(t, ret.Unmarshal(data))
+	assert.Equal(t, multi, ret)
+
+	v4 := multi
+	v4.SignVersion = ForSignV2
+	data, err = v4.Marshal()
+	require.NoError(t, err)
+	ret = SmartContract{}
+	require.NoError(t, ret.Unmarshal(data))
+	assert.Equal(t, v4, ret)
+	ret = SmartContract{}
+	require.NoError(t, ret.Unmarshal(mustMarshal(t, multi, 3)))
+	assert.Equal(t, multi, ret)
+
+	assert.Error(t, ret.Unmarshal(data[:len(data)-1]))
+	assert.Error(t, ret.Unmarshal(append(data[:2:2], data[3:]...)))
+}
+
+func TestSmartContractSigners(t *testing.T) {
+	multi := testSmart
+	multi.SignedBy, multi.Threshold, multi.Signers = 0, 2, []int64{7, -5, 3}
+	multi.KeyID = SignersAddress(2, []int64{3, 7, -5})
+	require.NoError(t, multi.CheckSigners())
+	assert.True(t, strings.HasSuffix(multi.ForSign(), `,2,-5;3;7`))
+
+	// the order of the signers doesn't change the signed data and the address
+	other := multi
+	other.Signers = []int64{3, 7, -5}
+	assert.Equal(t, multi.ForSign(), other.ForSign())
+	assert.NoError(t, other.CheckSigners())
+
+	for _, item := range []struct {
+		threshold int64
+		signers   []int64
+	}{
+		{0, []int64{1, 2}}, {3, []int64{1, 2}}, {1, []int64{1, 1}}, {1, []int64{0, 2}},
+		{1, make([]int64, MaxSigners+1)}, {1, nil},
+	} {
+		wrong := multi
+		wrong.Threshold, wrong.Signers = item.threshold, item.signers
+		wrong.KeyID = SignersAddress(item.threshold, item.signers)
+		if len(item.signers) == 0 {
+			wrong.KeyID = multi.KeyID
+		}
+		assert.Equal(t, ErrSigners, wrong.CheckSigners(), item)
+	}
+	wrong := multi
+	wrong.Threshold = 3
+	assert.Equal(t, ErrSignersAddress, wrong.CheckSigners())
+	wrong = multi
+	wrong.SignedBy = 1
+	assert.Equal(t, ErrSigners, wrong.CheckSigners())
+	assert.NoError(t, testSmart.CheckSigners())
+}
+
+// FuzzSmartContractUnmarshal checks that the malformed transactions return the error instead of panic
+func FuzzSmartContractUnmarshal(f *testing.F) {
+	data, _ := testSmart.Marshal()
+	f.Add(data)
+	old, _ := msgpack.Marshal(testSmart)
+	f.Add(old)
+	f.Add(mustMarshal(f, testSmart, 1))
+	f.Add(mustMarshal(f, testSmart, 2))
+	f.Add(mustMarshal(f, testSmart, 3))
+	f.Add([]byte{0xc1, 1, 0})
+	f.Add([]byte{0xdf, 0xff, 0xff, 0xff, 0xff})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var s SmartContract
+		if err := s.Unmarshal(data); err == nil && len(data) > 1 && data[0] == 0xc1 &&
+			data[1] == SmartContractVersion {
+			// every transaction of the current version has only one encoding
+			ret, err := s.Marshal()
+			require.NoError(t, err)
+			assert.Equal(t, data, ret)
+		}
+	})
+}
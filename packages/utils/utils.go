@@ -35,7 +35,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/GenesisKernel/go-genesis/packages/conf"
 	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
 	"github.com/GenesisKernel/go-genesis/packages/consts"
 	"github.com/GenesisKernel/go-genesis/packages/converter"
@@ -48,16 +47,16 @@ import (
 	"github.com/theckman/go-flock"
 )
 
-// BlockData is a structure of the block's header
+// BlockData is a structure of the block's header, the bin tags define the binary format of the header
 type BlockData struct {
-	BlockID      int64
-	Time         int64
-	EcosystemID  int64
-	KeyID        int64
-	NodePosition int64
-	Sign         []byte
+	BlockID      int64  `bin:"2,fixed4"`
+	Time         int64  `bin:"3,fixed4"`
+	EcosystemID  int64  `bin:"4,fixed4"`
+	KeyID        int64  `bin:"5,int"`
+	NodePosition int64  `bin:"6,fixed1"`
+	Sign         []byte `bin:"7,bytes"`
 	Hash         []byte
-	Version      int
+	Version      int `bin:"1,fixed2"`
 }
 
 func (b BlockData) String() string {
@@ -246,10 +245,11 @@ func CopyFileContents(src, dst string) error {
 }
 
 // CheckSign checks the signature
-func CheckSign(publicKeys [][]byte, forSign string, signs []byte, nodeKeyOrLogin bool) (bool, error) {
+func CheckSign(publicKeys [][]byte, forSign string, signs []byte, nodeKeyOrLogin bool) (ok bool, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.WithFields(log.Fields{"type": consts.PanicRecoveredError, "error": r}).Error("recovered panic in check sign")
+			ok, err = false, fmt.Errorf("check sign: %v", r)
 		}
 	}()
 
@@ -273,7 +273,7 @@ func CheckSign(publicKeys [][]byte, forSign string, signs []byte, nodeKeyOrLogin
 	} else {
 		length, err := converter.DecodeLength(&signs)
 		if err != nil {
-			log.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err}).Fatal("decoding signs length")
+			log.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err}).Error("decoding signs length")
 			return false, err
 		}
 		if length > 0 {
@@ -365,82 +365,6 @@ func GetCurrentDir() string {
 	return dir
 }
 
-// GetBlocksBody is retrieving `blocksCount` blocks bodies starting with blockID and puts them in the channel
-func GetBlocksBody(host string, blockID int64, blocksCount int32, dataTypeBlockBody int64, reverseOrder bool) (chan []byte, error) {
-	conn, err := TCPConn(host)
-	if err != nil {
-		return nil, ErrInfo(err)
-	}
-
-	// send the type of data
-	_, err = conn.Write(converter.DecToBin(dataTypeBlockBody, 2))
-	if err != nil {
-		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("writing data type block body to connection")
-		return nil, ErrInfo(err)
-	}
-
-	// send the number of a block
-	_, err = conn.Write(converter.DecToBin(blockID, 4))
-	if err != nil {
-		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("writing data type block body to connection")
-		return nil, ErrInfo(err)
-	}
-
-	rvBytes := make([]byte, 1)
-	if reverseOrder {
-		rvBytes[0] = 1
-	} else {
-		rvBytes[0] = 0
-	}
-
-	// send reverse flag
-	_, err = conn.Write(rvBytes)
-	if err != nil {
-		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("writing reverse flag to connection")
-		return nil, ErrInfo(err)
-	}
-
-	rawBlocksCh := make(chan []byte, blocksCount)
-	go func() {
-		defer func() {
-			close(rawBlocksCh)
-			conn.Close()
-		}()
-
-		for {
-			// receive the data size as a response that server wants to transfer
-			buf := make([]byte, 4)
-			_, err = conn.Read(buf)
-			if err != nil {
-				if err != io.EOF {
-					log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading block data size from connection")
-				}
-				return
-			}
-			dataSize := converter.BinToDec(buf)
-			var binaryBlock []byte
-
-			// data size must be less than 10mb
-			if dataSize >= 10485760 && dataSize == 0 {
-				log.Error("null block")
-				return
-			}
-
-			binaryBlock = make([]byte, dataSize)
-
-			_, err = io.ReadFull(conn, binaryBlock)
-			if err != nil {
-				log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading block data from connection")
-				return
-			}
-
-			rawBlocksCh <- binaryBlock
-		}
-	}()
-	return rawBlocksCh, nil
-
-}
-
 // ShellExecute runs cmdline
 func ShellExecute(cmdline string) {
 	time.Sleep(500 * time.Millisecond)
@@ -474,9 +398,8 @@ func GetParent() string {
 
 // GetNodeKeys returns node private key and public key
 func GetNodeKeys() (string, string, error) {
-	nprivkey, err := ioutil.ReadFile(filepath.Join(conf.Config.KeysDir, consts.NodePrivateKeyFilename))
+	nprivkey, err := readNodePrivateKey()
 	if err != nil {
-		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading node private key from file")
 		return "", "", err
 	}
 	key, err := hex.DecodeString(string(nprivkey))
@@ -484,6 +407,7 @@ func GetNodeKeys() (string, string, error) {
 		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding private key from hex")
 		return "", "", err
 	}
+	defer crypto.WipeKey(key)
 	npubkey, err := crypto.PrivateToPublic(key)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("converting node private key to public")
@@ -0,0 +1,148 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package vdesync
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/crypto"
+	"github.com/GenesisKernel/go-genesis/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	headerAuthPrefix = "Bearer "
+	requestTimeout   = 30 * time.Second
+)
+
+var errEmptyNodeKey = errors.New(`empty node private key`)
+
+type authResult struct {
+	UID   string `json:"uid,omitempty"`
+	Token string `json:"token,omitempty"`
+}
+
+// changes is the answer of the sync API of the source
+type changes struct {
+	VDE    bool                `json:"vde"`
+	Cursor int64               `json:"cursor"`
+	List   []map[string]string `json:"list"`
+}
+
+// client requests the API of the source node, the requests are signed with the node key
+type client struct {
+	url       string
+	ecosystem int64
+	token     string
+	http      *http.Client
+}
+
+func newClient(apiURL string, ecosystem int64) *client {
+	return &client{url: strings.TrimSuffix(apiURL, `/`) + `/`, ecosystem: ecosystem,
+		http: &http.Client{Timeout: requestTimeout}}
+}
+
+func (c *client) login() error {
+	var ret authResult
+	if err := c.request(`GET`, `getuid`, nil, &ret); err != nil {
+		return err
+	}
+	if len(ret.UID) == 0 {
+		return fmt.Errorf(`getuid has returned empty uid`)
+	}
+	c.token = ret.Token
+	privateKey, publicKey, err := utils.GetNodeKeys()
+	if err != nil || len(privateKey) == 0 {
+		if err == nil {
+			log.WithFields(log.Fields{"type": consts.EmptyObject}).Error("node private key is empty")
+			err = errEmptyNodeKey
+		}
+		return err
+	}
+	sign, err := crypto.Sign(privateKey, ret.UID)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("signing node uid")
+		return err
+	}
+	form := url.Values{"pubkey": {publicKey}, "signature": {hex.EncodeToString(sign)},
+		"ecosystem": {fmt.Sprint(c.ecosystem)}}
+	var logret authResult
+	if err = c.request(`POST`, `login`, &form, &logret); err != nil {
+		return err
+	}
+	c.token = logret.Token
+	return nil
+}
+
+// changes returns the rows of the table changed after the cursor
+func (c *client) changes(table string, cursor int64) (*changes, error) {
+	if len(c.token) == 0 {
+		if err := c.login(); err != nil {
+			return nil, err
+		}
+	}
+	var ret changes
+	err := c.request(`GET`, fmt.Sprintf(`sync/%s?from=%d`, url.PathEscape(table), cursor), nil, &ret)
+	return &ret, err
+}
+
+func (c *client) request(method, path string, form *url.Values, v interface{}) error {
+	var ioform io.Reader
+	if form != nil {
+		ioform = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequest(method, c.url+path, ioform)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err}).Error("new sync request")
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if len(c.token) > 0 {
+		req.Header.Set("Authorization", headerAuthPrefix+c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err}).Error("sync request")
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading sync answer")
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.WithFields(log.Fields{"type": consts.NetworkError, "status": resp.StatusCode}).Error("sync status code")
+		return fmt.Errorf(`%d %s`, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling sync answer")
+		return err
+	}
+	return nil
+}
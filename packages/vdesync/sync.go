@@ -0,0 +1,194 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package vdesync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// SourceChain is the type of the source which is the table of the blockchain
+	SourceChain = `chain`
+	// SourceVDE is the type of the source which is the table of the remote VDE
+	SourceVDE = `vde`
+
+	nullValue = `NULL`
+)
+
+var regColumn = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// ParseColumns parses the mapping of the source columns to the columns of the target table
+func ParseColumns(input string) (map[string]string, error) {
+	var columns map[string]string
+	if err := json.Unmarshal([]byte(input), &columns); err != nil {
+		return nil, fmt.Errorf(`columns must be a JSON object of the source and the target columns`)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf(`columns are empty`)
+	}
+	for source, target := range columns {
+		if !regColumn.MatchString(source) || !regColumn.MatchString(target) {
+			return nil, fmt.Errorf(`wrong column %s: %s`, source, target)
+		}
+		if source == `id` || target == `id` {
+			return nil, fmt.Errorf(`id column is synchronized implicitly`)
+		}
+	}
+	return columns, nil
+}
+
+// Synchronize pulls the changes of the active sources of the ecosystem which period has passed
+func Synchronize(ecosystem int64) error {
+	source := &model.SyncSource{}
+	source.SetTablePrefix(ecosystem)
+	if !model.IsTable(source.TableName()) {
+		return nil
+	}
+	sources, err := source.GetSourcesToSync(time.Now())
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting sync sources")
+		return err
+	}
+	for i := range sources {
+		syncSource(ecosystem, &sources[i])
+	}
+	return nil
+}
+
+// syncSource records the error of the source, the successful result is saved with the synchronized rows
+func syncSource(ecosystem int64, source *model.SyncSource) {
+	err := pull(ecosystem, source)
+	if err == nil {
+		return
+	}
+	log.WithFields(log.Fields{"type": consts.SyncProcess, "error": err, "source": source.Name}).Error("synchronizing source")
+	if err = source.SetResult(nil, source.Cursor, err.Error(), time.Now()); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("saving result of sync source")
+	}
+}
+
+func pull(ecosystem int64, source *model.SyncSource) error {
+	columns, err := ParseColumns(source.Columns)
+	if err != nil {
+		return err
+	}
+	ret, err := newClient(source.URL, source.SourceEcosystem).changes(source.SourceTable, source.Cursor)
+	if err != nil {
+		return err
+	}
+	if ret.VDE != (source.SourceType == SourceVDE) {
+		return fmt.Errorf(`source is not %s`, source.SourceType)
+	}
+	dbTx, err := model.StartTransaction()
+	if err != nil {
+		return err
+	}
+	target := fmt.Sprintf(`%d_%s`, ecosystem, source.TargetTable)
+	for _, row := range ret.List {
+		if err = applyRow(dbTx, ecosystem, source, target, columns, row); err != nil {
+			dbTx.Rollback()
+			return err
+		}
+	}
+	if err = source.SetResult(dbTx, ret.Cursor, ``, time.Now()); err != nil {
+		dbTx.Rollback()
+		return err
+	}
+	return dbTx.Commit()
+}
+
+// applyRow writes the row of the source to the target table. The row which is changed locally
+// since the last synchronization is the conflict
+func applyRow(dbTx *model.DbTransaction, ecosystem int64, source *model.SyncSource, target string,
+	columns, row map[string]string) error {
+	id, err := strconv.ParseInt(row[`id`], 10, 64)
+	if err != nil {
+		return fmt.Errorf(`wrong id of the row %s`, row[`id`])
+	}
+	values := mapRow(columns, row)
+	names := targetColumns(columns)
+	local, exists, err := model.GetSyncedRow(dbTx, target, id, names)
+	if err != nil {
+		return err
+	}
+	synced := &model.SyncRow{}
+	synced.SetTablePrefix(ecosystem)
+	found, err := synced.Get(dbTx, source.ID, id)
+	if err != nil {
+		return err
+	}
+	synced.SourceID, synced.RowID = source.ID, id
+	if exists && (!found || synced.Hash != rowHash(names, local)) && source.Conflict == model.SyncConflictSkip {
+		synced.Flagged = 1
+		return synced.Save(dbTx)
+	}
+	if err = model.SaveSyncedRow(dbTx, target, id, values, exists); err != nil {
+		return err
+	}
+	if local, _, err = model.GetSyncedRow(dbTx, target, id, names); err != nil {
+		return err
+	}
+	synced.Hash = rowHash(names, local)
+	synced.Flagged = 0
+	return synced.Save(dbTx)
+}
+
+// mapRow returns the values of the target columns
+func mapRow(columns, row map[string]string) map[string]interface{} {
+	values := make(map[string]interface{}, len(columns))
+	for source, target := range columns {
+		value, ok := row[source]
+		if !ok || value == nullValue {
+			values[target] = nil
+			continue
+		}
+		values[target] = value
+	}
+	return values
+}
+
+func targetColumns(columns map[string]string) []string {
+	names := make([]string, 0, len(columns))
+	for _, target := range columns {
+		names = append(names, target)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rowHash returns the hash of the values of the columns of the local row
+func rowHash(names []string, row map[string]string) string {
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = row[name]
+	}
+	hash := sha256.Sum256([]byte(strings.Join(values, "\x00")))
+	return hex.EncodeToString(hash[:])
+}
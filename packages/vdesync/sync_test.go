@@ -0,0 +1,52 @@
+// Copyright 2016 The go-daylight Authors
+// This file is part of the go-daylight library.
+//
+// The go-daylight library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-daylight library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-daylight library. If not, see <http://www.gnu.org/licenses/>.
+
+package vdesync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns(`{"amount":"balance","pub":"key"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"amount": "balance", "pub": "key"}, columns)
+
+	for _, input := range []string{``, `[]`, `{}`, `{"id":"key"}`, `{"amount":"id"}`, `{"Amount":"balance"}`,
+		`{"amount":"bal ance"}`} {
+		_, err = ParseColumns(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestMapRow(t *testing.T) {
+	columns := map[string]string{"amount": "balance", "pub": "key", "name": "title"}
+	values := mapRow(columns, map[string]string{"id": "5", "amount": "10", "pub": nullValue, "other": "x"})
+	assert.Equal(t, map[string]interface{}{"balance": "10", "key": nil, "title": nil}, values)
+	assert.Equal(t, []string{"balance", "key", "title"}, targetColumns(columns))
+}
+
+func TestRowHash(t *testing.T) {
+	names := []string{"balance", "key"}
+	hash := rowHash(names, map[string]string{"balance": "10", "key": "abc"})
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, rowHash(names, map[string]string{"balance": "10", "key": "abc", "other": "1"}))
+	assert.NotEqual(t, hash, rowHash(names, map[string]string{"balance": "11", "key": "abc"}))
+	assert.NotEqual(t, rowHash(names, map[string]string{"balance": "1", "key": "0abc"}),
+		rowHash(names, map[string]string{"balance": "10", "key": "abc"}))
+}
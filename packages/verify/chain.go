@@ -0,0 +1,110 @@
+// Package verify checks the integrity of the blockchain which is stored in the database of the node
+package verify
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/GenesisKernel/go-genesis/packages/block"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// batchSize is the number of the blocks which are read at once
+const batchSize = 1000
+
+// ProgressFunc is called after every checked block with the id of the block and the id of the last block
+type ProgressFunc func(blockID, last int64)
+
+// Divergence is the first block which doesn't match the blockchain and the reason
+type Divergence struct {
+	BlockID int64
+	Reason  string
+}
+
+// Result is the result of the verification of block_chain
+type Result struct {
+	Checked    int64
+	Last       int64
+	Divergence *Divergence
+}
+
+// Chain recomputes the hashes of all blocks of block_chain, checks the links to the parent blocks
+// and the signatures of the blocks by the full nodes which were active at that time.
+// The contracts must be loaded to parse the transactions of the blocks
+func Chain(progress ProgressFunc) (*Result, error) {
+	last := &model.Block{}
+	if _, err := last.GetMaxBlock(); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting max block")
+		return nil, err
+	}
+	nodes, err := loadNodeHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Last: last.ID}
+	prev := &utils.BlockData{}
+	for {
+		blocks, err := (&model.Block{}).GetBlocksFrom(prev.BlockID, "asc", batchSize)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting blocks")
+			return nil, err
+		}
+		if len(blocks) == 0 {
+			return result, nil
+		}
+		for i := range blocks {
+			item := &blocks[i]
+			if reason := checkBlock(item, prev, nodes); len(reason) > 0 {
+				result.Divergence = &Divergence{BlockID: item.ID, Reason: reason}
+				return result, nil
+			}
+			result.Checked++
+			prev = &utils.BlockData{BlockID: item.ID, Hash: item.Hash}
+			if progress != nil {
+				progress(item.ID, last.ID)
+			}
+		}
+	}
+}
+
+// checkBlock returns the reason if the block doesn't match its parent
+func checkBlock(item *model.Block, prev *utils.BlockData, nodes *nodeHistory) string {
+	if item.ID != prev.BlockID+1 {
+		return fmt.Sprintf("block %d is missing", prev.BlockID+1)
+	}
+	b, err := block.UnmarshallBlock(bytes.NewBuffer(item.Data), item.ID == 1)
+	if err != nil {
+		return fmt.Sprintf("malformed block data: %s", err)
+	}
+	if b.Header.BlockID != item.ID {
+		return fmt.Sprintf("block data has id %d", b.Header.BlockID)
+	}
+	if b.Header.Time != item.Time || b.Header.EcosystemID != item.EcosystemID ||
+		b.Header.KeyID != item.KeyID || b.Header.NodePosition != item.NodePosition {
+		return "columns of block_chain don't match the block header"
+	}
+	b.PrevHeader = prev
+	hash, err := b.Hash()
+	if err != nil {
+		return fmt.Sprintf("hashing block: %s", err)
+	}
+	if !bytes.Equal(hash, item.Hash) {
+		return fmt.Sprintf("stored hash %x doesn't match the hash %x linked to block %d", item.Hash, hash, prev.BlockID)
+	}
+	if item.ID == 1 {
+		return ""
+	}
+	publicKey, err := nodes.publicKey(prev.BlockID, b.Header.NodePosition)
+	if err != nil {
+		return err.Error()
+	}
+	if ok, err := utils.CheckSign([][]byte{publicKey}, b.ForSign(), b.Header.Sign, true); !ok || err != nil {
+		return fmt.Sprintf("invalid signature of node %d", b.Header.NodePosition)
+	}
+	return ""
+}
@@ -0,0 +1,189 @@
+package verify
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/GenesisKernel/go-genesis/packages/block"
+	"github.com/GenesisKernel/go-genesis/packages/conf"
+	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+	"github.com/GenesisKernel/go-genesis/packages/rollback"
+	"github.com/GenesisKernel/go-genesis/packages/smart"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maintenanceDB is the database which is connected while the copy is created and dropped
+const maintenanceDB = "postgres"
+
+// copySuffix is added to the name of the database of the node to get the name of the copy
+const copySuffix = "_verify"
+
+// localTables are the tables of the node which aren't the result of the blocks
+var localTables = map[string]bool{
+	"rollback_tx": true, "install": true, "my_node_keys": true, "stop_daemons": true,
+	"transactions": true, "transactions_status": true, "queue_tx": true, "queue_blocks": true,
+	"info_block": true, "confirmations": true, "migration_history": true, "api_usage": true,
+	"1_metrics": true, "1_bad_blocks": true, "1_node_ban_logs": true, "idempotency_keys": true,
+}
+
+// TableHash is the number of the rows and the hash of the rows of the table
+type TableHash struct {
+	Count int64
+	Hash  string
+}
+
+// DeepResult is the result of the replay of the last blocks
+type DeepResult struct {
+	From, To   int64
+	Divergence *Divergence
+	// Tables are the tables which differ after the replay
+	Tables []string
+}
+
+// TableHashes returns the hashes of the tables which are changed by the blocks
+func TableHashes() (map[string]TableHash, error) {
+	names, err := model.GetTableNames()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting table names")
+		return nil, err
+	}
+	ret := make(map[string]TableHash, len(names))
+	for _, name := range names {
+		if localTables[name] {
+			continue
+		}
+		var item TableHash
+		if item.Count, item.Hash, err = model.GetTableHash(name); err != nil {
+			return nil, err
+		}
+		ret[name] = item
+	}
+	return ret, nil
+}
+
+// Deep rollbacks the last count blocks in the throwaway copy of the database, plays them again and
+// compares the tables with the database of the node. The node must be stopped, the database db is
+// connected again when Deep returns
+func Deep(db conf.DBConfig, count int64, progress ProgressFunc) (ret *DeepResult, err error) {
+	last := &model.Block{}
+	if _, err = last.GetMaxBlock(); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting max block")
+		return nil, err
+	}
+	if count >= last.ID {
+		count = last.ID - 1
+	}
+	ret = &DeepResult{From: last.ID - count + 1, To: last.ID}
+	if count <= 0 {
+		return ret, nil
+	}
+	blocks, err := (&model.Block{}).GetBlocksFrom(last.ID-count, "asc", 0)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting blocks")
+		return nil, err
+	}
+	origin, err := TableHashes()
+	if err != nil {
+		return nil, err
+	}
+
+	copyName := db.Name + copySuffix
+	if err = connect(db, maintenanceDB); err != nil {
+		return nil, err
+	}
+	if err = model.DropDatabase(copyName); err == nil {
+		err = model.CreateDatabaseFromTemplate(copyName, db.Name)
+	}
+	if err != nil {
+		connect(db, db.Name)
+		return nil, err
+	}
+	defer func() {
+		if errDrop := dropCopy(db, copyName); errDrop != nil && err == nil {
+			err = errDrop
+		}
+	}()
+
+	if err = connect(db, copyName); err != nil {
+		return nil, err
+	}
+	if ret.Divergence, err = replay(blocks, progress); err != nil || ret.Divergence != nil {
+		return ret, err
+	}
+	replayed, err := TableHashes()
+	if err != nil {
+		return nil, err
+	}
+	ret.Tables = compareHashes(origin, replayed)
+	return ret, nil
+}
+
+// replay rollbacks the blocks in the connected database and plays them again
+func replay(blocks []model.Block, progress ProgressFunc) (*Divergence, error) {
+	if err := syspar.SysUpdate(nil); err != nil {
+		return nil, err
+	}
+	if err := block.SetSysparBlockID(); err != nil {
+		return nil, err
+	}
+	if data, ok := block.GetDataFromFirstBlock(); ok {
+		syspar.SetFirstBlockData(data)
+	}
+	if err := smart.LoadContracts(nil); err != nil {
+		return nil, err
+	}
+	from := blocks[0].ID - 1
+	if reached, err := rollback.Blocks(from, nil, log.WithFields(log.Fields{"target": from})); err != nil {
+		return &Divergence{BlockID: reached, Reason: fmt.Sprintf("rollback: %s", err)}, nil
+	}
+	last := blocks[len(blocks)-1].ID
+	for _, item := range blocks {
+		if err := block.InsertBlockWOForks(item.Data, false, false); err != nil {
+			return &Divergence{BlockID: item.ID, Reason: fmt.Sprintf("replay: %s", err)}, nil
+		}
+		if progress != nil {
+			progress(item.ID, last)
+		}
+	}
+	return nil, nil
+}
+
+// compareHashes returns the sorted names of the tables which differ
+func compareHashes(origin, replayed map[string]TableHash) []string {
+	ret := make([]string, 0)
+	for name, item := range origin {
+		if other, ok := replayed[name]; !ok || other != item {
+			ret = append(ret, name)
+		}
+	}
+	for name := range replayed {
+		if _, ok := origin[name]; !ok {
+			ret = append(ret, name)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// connect closes the current connection and connects to the database name
+func connect(db conf.DBConfig, name string) error {
+	if err := model.GormClose(); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("closing db connection")
+	}
+	return model.GormInit(db.Host, db.Port, db.User, db.Password, name)
+}
+
+// dropCopy drops the copy of the database and connects to the database of the node
+func dropCopy(db conf.DBConfig, copyName string) error {
+	err := connect(db, maintenanceDB)
+	if err == nil {
+		err = model.DropDatabase(copyName)
+	}
+	if errConn := connect(db, db.Name); errConn != nil && err == nil {
+		err = errConn
+	}
+	return err
+}
@@ -0,0 +1,131 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GenesisKernel/go-genesis/packages/block"
+	"github.com/GenesisKernel/go-genesis/packages/conf/syspar"
+	"github.com/GenesisKernel/go-genesis/packages/consts"
+	"github.com/GenesisKernel/go-genesis/packages/converter"
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const systemParametersTable = "1_system_parameters"
+
+// nodeChange is the state of the full_nodes parameter before the block which has changed it
+type nodeChange struct {
+	blockID int64
+	state   map[string]string
+}
+
+// nodeHistory restores the list of the full nodes at any block from the current state of the parameter
+// and its rollback records
+type nodeHistory struct {
+	current  map[string]string
+	changes  []nodeChange
+	firstKey []byte
+	keys     map[string][][]byte
+}
+
+// newNodeHistory returns the history of full_nodes, rollbacks are ordered by id desc.
+// firstKey is the key of the node of the first block which is used when the list is empty
+func newNodeHistory(current map[string]string, rollbacks []model.RollbackTx, firstKey []byte) (*nodeHistory, error) {
+	h := &nodeHistory{current: current, firstKey: firstKey, keys: make(map[string][][]byte)}
+	state := copyState(current)
+	for i, item := range rollbacks {
+		data, err := model.UnmarshalRollbackData(item.Data)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err, "block_id": item.BlockID}).Error("unmarshalling rollback of full nodes")
+			return nil, err
+		}
+		for key, val := range data {
+			state[key] = val
+		}
+		if i == len(rollbacks)-1 || rollbacks[i+1].BlockID != item.BlockID {
+			h.changes = append(h.changes, nodeChange{blockID: item.BlockID, state: copyState(state)})
+		}
+	}
+	sort.Slice(h.changes, func(i, j int) bool { return h.changes[i].blockID < h.changes[j].blockID })
+	return h, nil
+}
+
+// loadNodeHistory reads the history of full_nodes from the database
+func loadNodeHistory() (*nodeHistory, error) {
+	par := &model.SystemParameter{}
+	found, err := par.Get(syspar.FullNodes)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting full nodes parameter")
+		return nil, err
+	}
+	var rollbacks []model.RollbackTx
+	if found {
+		list, err := (&model.RollbackTx{}).GetRollbackTxsByTableIDAndTableName(converter.Int64ToStr(par.ID),
+			systemParametersTable, -1)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting rollbacks of full nodes")
+			return nil, err
+		}
+		rollbacks = *list
+	}
+	var firstKey []byte
+	if data, ok := block.GetDataFromFirstBlock(); ok {
+		firstKey = data.NodePublicKey
+	}
+	return newNodeHistory(par.ToMap(), rollbacks, firstKey)
+}
+
+// publicKeys returns the public keys of the nodes by position after the processing of the block
+func (h *nodeHistory) publicKeys(blockID int64) ([][]byte, error) {
+	i := sort.Search(len(h.changes), func(i int) bool { return h.changes[i].blockID > blockID })
+	state := h.current
+	if i < len(h.changes) {
+		state = h.changes[i].state
+	}
+	value := state["value"]
+	if activation := converter.StrToInt64(state["activation_block"]); activation > 0 && activation <= blockID {
+		value = state["pending_value"]
+	}
+	if keys, ok := h.keys[value]; ok {
+		return keys, nil
+	}
+	keys := make([][]byte, 0)
+	if list := strings.TrimSpace(value); len(list) > 0 {
+		nodes := make([]*syspar.FullNode, 0)
+		if err := json.Unmarshal([]byte(list), &nodes); err != nil {
+			return nil, fmt.Errorf("full nodes after block %d: %s", blockID, err)
+		}
+		for _, node := range nodes {
+			keys = append(keys, node.PublicKey)
+		}
+	}
+	if len(keys) == 0 && len(h.firstKey) > 0 {
+		keys = append(keys, h.firstKey)
+	}
+	h.keys[value] = keys
+	return keys, nil
+}
+
+// publicKey returns the public key of the node which signs the block after blockID
+func (h *nodeHistory) publicKey(blockID, position int64) ([]byte, error) {
+	keys, err := h.publicKeys(blockID)
+	if err != nil {
+		return nil, err
+	}
+	if position < 0 || position >= int64(len(keys)) {
+		return nil, fmt.Errorf("node position %d is out of %d full nodes", position, len(keys))
+	}
+	return keys[position], nil
+}
+
+func copyState(state map[string]string) map[string]string {
+	ret := make(map[string]string, len(state))
+	for key, val := range state {
+		ret[key] = val
+	}
+	return ret
+}
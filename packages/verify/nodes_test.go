@@ -0,0 +1,60 @@
+package verify
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/GenesisKernel/go-genesis/packages/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testNodes(keys ...string) string {
+	list := make([]string, len(keys))
+	for i, key := range keys {
+		list[i] = fmt.Sprintf(`{"tcp_address":"127.0.0.%d","api_address":"https://127.0.0.%[1]d","key_id":"%[1]d","public_key":"%s"}`,
+			i+1, strings.Repeat(key, 128))
+	}
+	return `[` + strings.Join(list, `,`) + `]`
+}
+
+func TestNodeHistory(t *testing.T) {
+	nodesA, nodesAB, nodesC := testNodes("a"), testNodes("a", "b"), testNodes("c")
+	// block 5 added the node b, block 9 scheduled the node c at block 12
+	current := map[string]string{"value": nodesAB, "pending_value": nodesC, "activation_block": "12"}
+	rollbacks := []model.RollbackTx{
+		{BlockID: 9, Data: `{"pending_value":"","activation_block":"0"}`},
+		{BlockID: 5, Data: fmt.Sprintf(`{"value":%q}`, nodesA)},
+		{BlockID: 5, Data: `{"value":""}`},
+	}
+	firstKey := []byte("first")
+	h, err := newNodeHistory(current, rollbacks, firstKey)
+	require.NoError(t, err)
+
+	for _, item := range []struct {
+		blockID int64
+		keys    []string
+	}{
+		{1, []string{"first"}},
+		{4, []string{"first"}},
+		{5, []string{"aaaa", "bbbb"}},
+		{11, []string{"aaaa", "bbbb"}},
+		{12, []string{"cccc"}},
+		{100, []string{"cccc"}},
+	} {
+		keys, err := h.publicKeys(item.blockID)
+		require.NoError(t, err)
+		list := make([]string, len(keys))
+		for i, key := range keys {
+			if list[i] = string(key); len(key) != len(firstKey) {
+				list[i] = fmt.Sprintf("%x", key[:2])
+			}
+		}
+		assert.Equal(t, item.keys, list, "block %d", item.blockID)
+	}
+
+	_, err = h.publicKey(5, 2)
+	assert.EqualError(t, err, "node position 2 is out of 2 full nodes")
+}